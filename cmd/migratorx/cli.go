@@ -0,0 +1,643 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"migratorx/internal/access"
+	"migratorx/internal/cdc"
+	"migratorx/internal/checks"
+	"migratorx/internal/remediation"
+	"migratorx/internal/state"
+	"migratorx/internal/workflow"
+)
+
+// runFunc executes a leaf command with its positional arguments.
+type runFunc func(ctx context.Context, env *env, args []string) Output
+
+// command is a node in the CLI tree. Leaf commands define Setup, which registers
+// command-specific flags and returns the function that runs the command.
+// Global flags are registered on every leaf so they never need to be redeclared.
+// Role is the access role a leaf requires when the plan enables access control;
+// it defaults to viewer.
+type command struct {
+	Name        string
+	Summary     string
+	Args        []string
+	Role        access.Role
+	Setup       func(fs *flag.FlagSet) runFunc
+	Subcommands []*command
+}
+
+// identityTokenEnv names the environment variable holding the caller's identity
+// token. Tokens are read from the environment so they stay out of process lists.
+const identityTokenEnv = "MIGRATORX_IDENTITY_TOKEN"
+
+// decisionSecretEnv names the environment variable holding the secret shared
+// with promotion.decision's service, used to verify its signed answers.
+const decisionSecretEnv = "MIGRATORX_DECISION_SECRET"
+
+// globalOptions are flags shared by every command.
+type globalOptions struct {
+	PlanPath      string
+	StatePath     string
+	RunID         string
+	AcceptPlan    bool
+	Format        string
+	LogLevel      string
+	ManifestPath  string
+	Record        string
+	Deterministic bool
+	Environment   string
+	ReportComment string
+	Output        string
+}
+
+func (g *globalOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&g.PlanPath, "plan", "migration.yaml", "path to migration plan YAML")
+	fs.StringVar(&g.StatePath, "state", defaultStatePath(), "path to state file")
+	fs.StringVar(&g.RunID, "run-id", state.DefaultRunID, "run ID used to scope state")
+	fs.BoolVar(&g.AcceptPlan, "accept-plan-change", false, "accept a plan that differs from the one pinned when the run started")
+	fs.StringVar(&g.Format, "format", formatJSON, "output format: json or text")
+	fs.StringVar(&g.LogLevel, "log-level", "info", "log level: debug, info, warn, error")
+	fs.StringVar(&g.ManifestPath, "manifest", "", "write a run manifest JSON to this path")
+	fs.StringVar(&g.Record, "record", "", "write every inspector response to this fixture directory")
+	fs.BoolVar(&g.Deterministic, "deterministic", false, "strip timestamps, normalize durations and sort findings for golden-file diffs")
+	fs.StringVar(&g.Environment, "env", "", "environment to target when the plan declares environments")
+	fs.StringVar(&g.ReportComment, "report-comment", "", "post the summary and gate decision as a comment on this GitHub PR or GitLab MR URL")
+	fs.StringVar(&g.Output, "output", "", "also write the report to this file, directory, s3:// URL or http(s) endpoint")
+}
+
+func (g *globalOptions) validate() error {
+	switch g.Format {
+	case formatJSON, formatText:
+	default:
+		return fmt.Errorf("unsupported --format %q (expected json or text)", g.Format)
+	}
+	switch g.LogLevel {
+	case "debug", "info", "warn", "error":
+	default:
+		return fmt.Errorf("unsupported --log-level %q (expected debug, info, warn or error)", g.LogLevel)
+	}
+	return nil
+}
+
+// env carries resolved global options into a running command. Remediation
+// starts as the built-in catalog and is extended by the plan once loaded.
+// Recorder is nil unless --record is set. Topology is the loaded plan's, used
+// to label findings with regions.
+type env struct {
+	Globals     globalOptions
+	Logger      *log.Logger
+	Stdout      io.Writer
+	Stderr      io.Writer
+	Manifest    *runManifest
+	PlanHash    string
+	Role        access.Role
+	Token       string
+	Remediation remediation.Catalog
+	Recorder    *fixtureRecorder
+	Durations   *workflow.DurationMonitor
+	Topology    workflow.Topology
+	States      []*state.FileState
+}
+
+// runContext identifies this run and its operator to external systems: the
+// authorized identity, or the local user when the plan is not
+// access-controlled.
+func (e *env) runContext() cdc.RunContext {
+	operator := ""
+	if e.Manifest.Identity != nil {
+		operator = e.Manifest.Identity.Name
+	} else if u, err := user.Current(); err == nil {
+		operator = u.Username
+	}
+	return cdc.RunContext{RunID: e.Globals.RunID, Operator: operator}
+}
+
+// httpClient returns a client for calls to system that carry the run context
+// and are logged and recorded in the run manifest.
+func (e *env) httpClient(system string) *http.Client {
+	return e.runContext().HTTPClient(system, func(call cdc.OutboundCall) {
+		status := strconv.Itoa(call.Status)
+		if call.Error != "" {
+			status = call.Error
+		}
+		e.Logger.Printf("%s %s %s as %s: %s", call.System, call.Method, call.URL, call.ClientID, status)
+		e.Manifest.recordCall(call)
+	})
+}
+
+// loadPlan loads the plan named by --plan, records it in the run manifest and
+// authorizes the caller.
+func (e *env) loadPlan() (workflow.MigrationPlan, error) {
+	plan, err := e.readPlan()
+	if err != nil {
+		return plan, err
+	}
+	if err := e.authorize(plan); err != nil {
+		return plan, err
+	}
+	return plan, nil
+}
+
+// readPlan is loadPlan without authorization, for commands that report on the
+// caller's credentials themselves.
+func (e *env) readPlan() (workflow.MigrationPlan, error) {
+	plan, err := workflow.LoadPlan(e.Globals.PlanPath)
+	if err != nil {
+		return plan, err
+	}
+	if plan, err = plan.ForEnvironment(e.Globals.Environment); err != nil {
+		return plan, err
+	}
+	hash, err := workflow.HashPlanFile(e.Globals.PlanPath)
+	if err != nil {
+		return plan, err
+	}
+	e.PlanHash = hash
+	e.Manifest.recordPlan(plan, hash)
+	e.Remediation = e.Remediation.With(plan.Remediation)
+	e.Topology = plan.Topology
+	return plan, nil
+}
+
+// authorize enforces the plan's access policy for the command's role. Plans
+// without access.tokens_file are not access-controlled.
+func (e *env) authorize(plan workflow.MigrationPlan) error {
+	path := plan.Access.TokensFile
+	if path == "" {
+		return nil
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(e.Globals.PlanPath), path)
+	}
+	policy, err := access.LoadPolicy(path)
+	if err != nil {
+		return fmt.Errorf("failed to load access policy: %w", err)
+	}
+	id, err := policy.Authorize(e.Token, e.Role)
+	if err != nil {
+		return err
+	}
+	e.Manifest.Identity = &id
+	e.Logger.Printf("authorized %s (%s) for %s", id.Name, id.Role, e.Manifest.Command)
+	return nil
+}
+
+// holdForTraffic consults the plan's traffic calendar before a mutating step
+// starts. It returns the gate's findings and whether the step must not start
+// because a known peak is under way or due within the lead time: deferred
+// with a WARN, or blocked when traffic_calendar.on_peak is "block".
+func (e *env) holdForTraffic(ctx context.Context, plan workflow.MigrationPlan) ([]OutputFinding, bool) {
+	calendar := plan.TrafficCalendar
+	if calendar.Source == "" {
+		return nil, false
+	}
+	gate := &workflow.TrafficCalendarGate{
+		Source:   &workflow.CalendarSource{Location: calendar.Source, Client: e.httpClient("traffic_calendar")},
+		LeadTime: calendar.LeadTime,
+		Block:    calendar.Blocks(),
+	}
+	e.Manifest.recordCheck(gate.Name(), gate.Parameters())
+	findings, err := gate.Run(ctx, checks.Input{})
+	if err != nil {
+		return blockOutput(err).Findings, true
+	}
+	output := []OutputFinding{}
+	hold := false
+	for _, f := range findings {
+		output = append(output, OutputFinding{Severity: f.Severity.String(), Code: f.Code, Message: f.Message, Meta: f.Meta})
+		hold = hold || f.Code == workflow.CodeTrafficPeakAhead
+	}
+	return output, hold
+}
+
+// fileState opens the state file at path and remembers it, so execute can
+// report writes that failed after the command's run returned.
+func (e *env) fileState(path string) (*state.FileState, error) {
+	fs, err := state.NewFileState(path)
+	if err != nil {
+		return nil, err
+	}
+	e.States = append(e.States, fs)
+	return fs, nil
+}
+
+// stateWriteFindings blocks on every state file that dropped a write: a
+// missing checkpoint would make a resumed run repeat the step.
+func (e *env) stateWriteFindings() []OutputFinding {
+	findings := []OutputFinding{}
+	for _, fs := range e.States {
+		if err := fs.Err(); err != nil {
+			findings = append(findings, OutputFinding{
+				Severity: "BLOCK",
+				Code:     codeStateWriteFailed,
+				Message:  err.Error(),
+				Meta:     map[string]interface{}{"state": fs.Path()},
+			})
+		}
+	}
+	return findings
+}
+
+// openState opens the --state file scoped to the plan's migration and --run-id.
+// It returns a WARN finding when the file was created by a different plan, and
+// pins the plan hash for the run: a changed plan yields a BLOCK finding unless
+// --accept-plan-change is set. Mutating commands get a WARN when the run was
+// already marked completed. With --env, the environment's prerequisite must
+// have a recorded successful run of the same plan. Callers must stop when a
+// BLOCK is returned.
+func (e *env) openState(plan workflow.MigrationPlan) (*state.Scope, []OutputFinding, error) {
+	fs, err := e.fileState(e.Globals.StatePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	scope, err := state.NewScope(fs, plan.StateName(), e.Globals.RunID)
+	if err != nil {
+		return nil, nil, err
+	}
+	warnings := []OutputFinding{}
+	if owner := state.ClaimOwner(fs, plan.Migration); owner != plan.Migration {
+		warnings = append(warnings, OutputFinding{
+			Severity: "WARN",
+			Code:     codeStateForeignPlan,
+			Message:  fmt.Sprintf("state file %q was created by plan %q; using a separate scope for %q", e.Globals.StatePath, owner, plan.Migration),
+			Meta:     map[string]interface{}{"state": e.Globals.StatePath, "owner": owner, "migration": plan.Migration},
+		})
+	}
+	if completedAt, ok := state.RunCompleted(scope); ok && e.Role != access.RoleViewer {
+		warnings = append(warnings, OutputFinding{
+			Severity: "WARN",
+			Code:     codeStateRunCompleted,
+			Message:  fmt.Sprintf("run %q completed at %s; its checkpoints will be reused and completed actions skipped", e.Globals.RunID, completedAt),
+			Meta:     map[string]interface{}{"run_id": e.Globals.RunID, "completed_at": completedAt, "state": e.Globals.StatePath},
+		})
+	}
+	if pinned, changed := state.PinPlan(scope, e.PlanHash, e.Globals.AcceptPlan); changed {
+		meta := map[string]interface{}{"pinned_hash": pinned, "plan_hash": e.PlanHash, "run_id": e.Globals.RunID}
+		if !e.Globals.AcceptPlan {
+			warnings = append(warnings, OutputFinding{
+				Severity: "BLOCK",
+				Code:     codePlanChanged,
+				Message:  "plan changed since this run started; re-run with --accept-plan-change to continue with the modified plan",
+				Meta:     meta,
+			})
+		} else {
+			warnings = append(warnings, OutputFinding{
+				Severity: "WARN",
+				Code:     codePlanChanged,
+				Message:  "plan changed since this run started; accepted via --accept-plan-change",
+				Meta:     meta,
+			})
+		}
+	}
+	if plan.Topology.ResolveDNS {
+		warnings = append(warnings, e.checkResolution(plan, scope)...)
+	}
+	if e.Role != access.RoleViewer && e.Durations == nil {
+		e.watchDuration(fs, plan)
+	}
+	if plan.SelectedEnvironment != "" {
+		selected, _ := plan.Environment(plan.SelectedEnvironment)
+		gate := &workflow.EnvironmentGate{Backend: fs, Migration: plan.Migration, Environment: selected.Name, Requires: selected.Requires, PlanHash: e.PlanHash}
+		findings, err := gate.Run(context.Background(), checks.Input{})
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, f := range findings {
+			if f.Severity == checks.SeverityBlock {
+				warnings = append(warnings, OutputFinding{Severity: f.Severity.String(), Code: f.Code, Message: f.Message, Meta: f.Meta})
+			}
+		}
+	}
+	return scope, warnings, nil
+}
+
+// resolveTimeout bounds resolving the topology when a command opens state.
+const resolveTimeout = 5 * time.Second
+
+// checkResolution resolves every topology member and compares it with the
+// IPs pinned when the run first resolved it. Changed and failed resolutions
+// are returned as warnings; the rest are only logged.
+func (e *env) checkResolution(plan workflow.MigrationPlan, st workflow.State) []OutputFinding {
+	gate := &workflow.ResolutionGate{Topology: plan.Topology, Resolver: net.DefaultResolver, State: st}
+	e.Manifest.recordCheck(gate.Name(), gate.Parameters())
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+	findings, err := gate.Run(ctx, checks.Input{})
+	if err != nil {
+		return blockOutput(err).Findings
+	}
+	warnings := []OutputFinding{}
+	for _, f := range findings {
+		if host, _ := f.Meta["host"].(string); host != "" {
+			if ips, ok := f.Meta["ips"].([]string); ok {
+				e.Manifest.recordResolution(host, ips)
+			}
+		}
+		if f.Severity == checks.SeverityInfo {
+			e.Logger.Print(f.Message)
+			continue
+		}
+		warnings = append(warnings, OutputFinding{Severity: f.Severity.String(), Code: f.Code, Message: f.Message, Meta: f.Meta})
+	}
+	return warnings
+}
+
+// watchDuration times the running command against its durations in earlier
+// runs and notifies when it runs well past them. execute finishes it.
+func (e *env) watchDuration(fs *state.FileState, plan workflow.MigrationPlan) {
+	anomaly := plan.Notifications.DurationAnomaly
+	e.Durations = &workflow.DurationMonitor{
+		Migration:  plan.Migration,
+		History:    state.StepDurations{Backend: fs, Migration: plan.Migration},
+		Notifier:   &commandNotifier{argv: plan.Notifications.Command},
+		Factor:     anomaly.Factor,
+		MinSamples: anomaly.MinSamples,
+		Logger:     e.Logger,
+	}
+	e.Durations.Start(context.Background(), e.Manifest.Command)
+}
+
+// finishDuration stops timing the command. Only unblocked runs are recorded.
+// When the anomaly notification fired, the output carries a WARN so the run's
+// report shows it too.
+func (e *env) finishDuration(output Output) Output {
+	if e.Durations == nil {
+		return output
+	}
+	elapsed, anomalous := e.Durations.Finish(e.Manifest.Command, output.Summary.Block == 0)
+	if !anomalous {
+		return output
+	}
+	output.Summary.Warn++
+	output.Findings = append(output.Findings, OutputFinding{
+		Severity: "WARN",
+		Code:     codeDurationAnomaly,
+		Message:  fmt.Sprintf("%s took %s, well past its duration in earlier runs", e.Manifest.Command, elapsed.Round(time.Second)),
+		Meta:     map[string]interface{}{"step": e.Manifest.Command, "elapsed": elapsed.String()},
+	})
+	return output
+}
+
+// newLogger returns the progress logger for a log level. Progress messages are
+// informational, so warn and error levels silence them.
+func newLogger(level string, deterministic bool, w io.Writer) *log.Logger {
+	switch {
+	case level == "warn" || level == "error":
+		return log.New(io.Discard, "", 0)
+	case deterministic:
+		return log.New(w, "", 0)
+	default:
+		return log.New(w, "", log.LstdFlags)
+	}
+}
+
+// execute resolves argv against the command tree, runs the selected command and
+// writes its output. It returns the process exit code.
+func execute(ctx context.Context, root *command, argv []string, stdout io.Writer, stderr io.Writer) int {
+	if len(argv) > 0 && (argv[0] == "help" || argv[0] == "-h" || argv[0] == "--help") {
+		cmd, path, _ := resolve(root, argv[1:])
+		printHelp(stdout, cmd, path)
+		return exitOK
+	}
+
+	cmd, path, rest := resolve(root, argv)
+	if cmd.Setup == nil {
+		if len(rest) > 0 {
+			fmt.Fprintf(stderr, "error: unknown command %q\n\n", strings.Join(append(path, rest[0]), " "))
+		}
+		printHelp(stderr, cmd, path)
+		return exitUsage
+	}
+
+	var globals globalOptions
+	fs := flag.NewFlagSet(strings.Join(path, " "), flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	globals.register(fs)
+	run := cmd.Setup(fs)
+
+	args, err := parseArgs(fs, rest)
+	if errors.Is(err, flag.ErrHelp) {
+		printHelp(stdout, cmd, path)
+		return exitOK
+	}
+	if err == nil && len(args) != len(cmd.Args) {
+		err = fmt.Errorf("expected %d argument(s) %s, got %d", len(cmd.Args), formatArgs(cmd.Args), len(args))
+	}
+	if err == nil {
+		err = globals.validate()
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n\n", err)
+		printHelp(stderr, cmd, path)
+		return exitUsage
+	}
+
+	e := &env{
+		Globals:     globals,
+		Logger:      newLogger(globals.LogLevel, globals.Deterministic, stderr),
+		Stdout:      stdout,
+		Stderr:      stderr,
+		Manifest:    newRunManifest(strings.Join(path[1:], " "), args, globals.PlanPath, globals.Deterministic),
+		Role:        cmd.Role,
+		Token:       os.Getenv(identityTokenEnv),
+		Remediation: remediation.Default().With(cliRemediation),
+		Recorder:    newFixtureRecorder(globals.Record),
+	}
+	if e.Role == "" {
+		e.Role = access.RoleViewer
+	}
+	output := e.finishDuration(run(ctx, e, args))
+	output = prependFindings(output, e.stateWriteFindings())
+	interrupted := ctx.Err() != nil
+	if interrupted {
+		// Checkpoints are written as each step completes, so only reporting
+		// is left; it gets a fresh context because ctx is already canceled.
+		output = prependFindings(output, []OutputFinding{interruptedFinding(globals.StatePath)})
+		ctx = context.Background()
+	}
+	if globals.ReportComment != "" {
+		output = reportComment(ctx, e, output)
+	}
+	output = withRegions(output, e.Topology)
+	output = withRemediation(output, e.Remediation)
+	if globals.Deterministic {
+		output = deterministicOutput(output)
+	}
+	if globals.Output != "" {
+		output = writeReport(ctx, e, output)
+	}
+	if err := writeOutput(stdout, output, globals.Format); err != nil {
+		fmt.Fprintf(stderr, "error: failed to encode output: %v\n", err)
+		return exitFailed
+	}
+	if globals.ManifestPath != "" {
+		if err := e.Manifest.write(globals.ManifestPath, output); err != nil {
+			fmt.Fprintf(stderr, "error: failed to write manifest: %v\n", err)
+			return exitFailed
+		}
+	}
+	if interrupted {
+		return exitInterrupted
+	}
+	return exitCode(output)
+}
+
+// resolve walks argv down the command tree, returning the deepest matching
+// command, its path and the remaining arguments.
+func resolve(root *command, argv []string) (*command, []string, []string) {
+	cmd := root
+	path := []string{root.Name}
+	for len(argv) > 0 && len(cmd.Subcommands) > 0 {
+		next := cmd.subcommand(argv[0])
+		if next == nil {
+			break
+		}
+		cmd = next
+		path = append(path, next.Name)
+		argv = argv[1:]
+	}
+	return cmd, path, argv
+}
+
+func (c *command) subcommand(name string) *command {
+	for _, sub := range c.Subcommands {
+		if sub.Name == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+// parseArgs parses flags interspersed with positional arguments, so flags may
+// follow positionals (e.g. "upgrade replica r1 --plan p.yaml"). A literal
+// true/false following a boolean flag is consumed as that flag's value.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	positional := []string{}
+	for len(args) > 0 {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if len(rest) == 0 {
+			break
+		}
+		if consumed := len(args) - len(rest); consumed > 0 {
+			if name, ok := boolFlagName(fs, args[consumed-1]); ok {
+				if v, err := strconv.ParseBool(rest[0]); err == nil {
+					if err := fs.Set(name, strconv.FormatBool(v)); err != nil {
+						return nil, err
+					}
+					args = rest[1:]
+					continue
+				}
+			}
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+	return positional, nil
+}
+
+func boolFlagName(fs *flag.FlagSet, arg string) (string, bool) {
+	if !strings.HasPrefix(arg, "-") || strings.Contains(arg, "=") {
+		return "", false
+	}
+	name := strings.TrimLeft(arg, "-")
+	f := fs.Lookup(name)
+	if f == nil {
+		return "", false
+	}
+	bf, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return name, ok && bf.IsBoolFlag()
+}
+
+// stringList is a repeatable string flag that also accepts comma-separated values.
+type stringList []string
+
+func (s *stringList) String() string { return strings.Join(*s, ",") }
+
+func (s *stringList) Set(value string) error {
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			*s = append(*s, part)
+		}
+	}
+	return nil
+}
+
+func formatArgs(args []string) string {
+	names := make([]string, 0, len(args))
+	for _, a := range args {
+		names = append(names, "<"+a+">")
+	}
+	return strings.Join(names, " ")
+}
+
+// printHelp writes usage for a command: subcommands for inner nodes, flags for leaves.
+func printHelp(w io.Writer, cmd *command, path []string) {
+	usage := strings.Join(path, " ")
+	if len(cmd.Args) > 0 {
+		usage += " " + formatArgs(cmd.Args)
+	}
+	if len(cmd.Subcommands) > 0 {
+		usage += " <command>"
+	} else {
+		usage += " [flags]"
+	}
+	fmt.Fprintf(w, "Usage:\n  %s\n", usage)
+	if cmd.Summary != "" {
+		fmt.Fprintf(w, "\n%s\n", cmd.Summary)
+	}
+
+	if len(cmd.Subcommands) > 0 {
+		fmt.Fprintln(w, "\nCommands:")
+		for _, sub := range cmd.Subcommands {
+			fmt.Fprintf(w, "  %-12s %s\n", sub.Name, sub.Summary)
+		}
+		fmt.Fprintf(w, "\nRun \"%s <command> --help\" for command flags.\n", strings.Join(path, " "))
+		return
+	}
+
+	var globals globalOptions
+	globalSet := flag.NewFlagSet("globals", flag.ContinueOnError)
+	globals.register(globalSet)
+	local := flag.NewFlagSet(usage, flag.ContinueOnError)
+	cmd.Setup(local)
+	if hasFlags(local) {
+		fmt.Fprintln(w, "\nFlags:")
+		printFlags(w, local)
+	}
+	fmt.Fprintln(w, "\nGlobal Flags:")
+	printFlags(w, globalSet)
+}
+
+func hasFlags(fs *flag.FlagSet) bool {
+	found := false
+	fs.VisitAll(func(*flag.Flag) { found = true })
+	return found
+}
+
+func printFlags(w io.Writer, fs *flag.FlagSet) {
+	fs.VisitAll(func(f *flag.Flag) {
+		def := ""
+		if f.DefValue != "" {
+			def = fmt.Sprintf(" (default %q)", f.DefValue)
+		}
+		fmt.Fprintf(w, "  --%-16s %s%s\n", f.Name, f.Usage, def)
+	})
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"migratorx/internal/mysql"
+)
+
+// setupConfigRender renders a cleaned option file for the target version
+// from the current configuration.
+func setupConfigRender(fs *flag.FlagSet) runFunc {
+	target := fs.String("target", "", "target MySQL version, e.g. 8.0 or 8.4")
+	source := fs.String("source", "", "current MySQL version; only removals and default changes after it are applied")
+	optionFile := fs.String("option-file", "", "current option file (my.cnf), following !include directives")
+	variablesPath := fs.String("variables", "", "current variables as a JSON object of name to value, e.g. exported from a live server")
+	out := fs.String("out", "", "write the rendered option file here instead of into the finding meta")
+	return func(ctx context.Context, env *env, args []string) Output {
+		if *target == "" {
+			return blockOutput(fmt.Errorf("--target is required"))
+		}
+		if (*optionFile == "") == (*variablesPath == "") {
+			return blockOutput(fmt.Errorf("exactly one of --option-file or --variables is required"))
+		}
+		var variables []mysql.ConfiguredVariable
+		var err error
+		if *optionFile != "" {
+			variables, err = (&mysql.OptionFileInspector{Paths: []string{*optionFile}}).ConfiguredVariables(ctx, "")
+		} else {
+			variables, err = readVariablesFile(*variablesPath)
+		}
+		if err != nil {
+			return blockOutput(err)
+		}
+
+		rendered := mysql.RenderConfiguration(variables, *source, *target)
+		env.Manifest.recordCheck("config_render", map[string]interface{}{"source": *source, "target": *target, "option_file": *optionFile, "variables": *variablesPath})
+		meta := map[string]interface{}{"target": *target, "kept": rendered.Kept, "dropped": rendered.Dropped}
+		if *out != "" {
+			if err := os.WriteFile(*out, []byte(rendered.Text), 0o644); err != nil {
+				return blockOutput(err)
+			}
+			meta["out"] = *out
+		} else {
+			meta["config"] = rendered.Text
+		}
+		message := fmt.Sprintf("rendered %d variables for MySQL %s; dropped %d removed variables", rendered.Kept, *target, len(rendered.Dropped))
+		return Output{Summary: Summary{Info: 1}, Findings: []OutputFinding{{Severity: "INFO", Code: codeConfigRendered, Message: message, Meta: meta}}}
+	}
+}
+
+// readVariablesFile reads {"<name>": "<value>"} in name order.
+func readVariablesFile(path string) ([]mysql.ConfiguredVariable, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]string{}
+	if err := json.Unmarshal(b, &values); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	variables := make([]mysql.ConfiguredVariable, 0, len(names))
+	for _, name := range names {
+		variables = append(variables, mysql.ConfiguredVariable{Name: name, Value: values[name], Source: path})
+	}
+	return variables, nil
+}
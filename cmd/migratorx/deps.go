@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"migratorx/internal/failure"
+	"migratorx/internal/report"
+	"migratorx/internal/workflow"
+)
+
+// Dependency kinds.
+const (
+	depMySQL           = "mysql"
+	depKafka           = "kafka"
+	depKafkaConnect    = "kafka_connect"
+	depDecisionService = "decision_service"
+	depTrafficCalendar = "traffic_calendar"
+	depHAProxy         = "haproxy"
+	depProxySQL        = "proxysql"
+	depReportComment   = "report_comment"
+	depReportOutput    = "report_output"
+	depCommand         = "command"
+)
+
+// allCommands stands for every command in a dependency's command list, for
+// the global --output and --report-comment.
+const allCommands = "*"
+
+// Commands that contact the plan's endpoints.
+var (
+	schemaCommands   = []string{"preflight", "validate replica", "validate primary", "promote prepare"}
+	drainCommands    = []string{"upgrade replica", "upgrade replicas", "upgrade undrain"}
+	mutatingCommands = []string{"upgrade replica", "upgrade replicas", "upgrade undrain", "cdc restart", "promote prepare", "promote execute"}
+)
+
+// dependency is an external system a command may contact. Command
+// dependencies are local processes migratorx starts; what they contact in
+// turn is outside its view.
+type dependency struct {
+	Kind     string
+	Endpoint string
+	Sources  []string
+	Commands []string
+}
+
+// dependencies collects endpoints in the order they are found, merging
+// repeats.
+type dependencies struct {
+	list []*dependency
+}
+
+func (d *dependencies) add(kind string, endpoint string, source string, commands ...string) {
+	for _, existing := range d.list {
+		if existing.Kind == kind && existing.Endpoint == endpoint {
+			existing.Sources = appendUnique(existing.Sources, source)
+			for _, command := range commands {
+				existing.Commands = appendUnique(existing.Commands, command)
+			}
+			return
+		}
+	}
+	d.list = append(d.list, &dependency{Kind: kind, Endpoint: endpoint, Sources: []string{source}, Commands: append([]string{}, commands...)})
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// depsInputs are the endpoint flags other commands take, given to deps
+// report so it describes the invocation under review.
+type depsInputs struct {
+	SchemaDSN  string
+	ConnectURL string
+	Brokers    []string
+	MySQLPort  int
+}
+
+// setupDepsReport lists every external endpoint migratorx would contact for
+// the plan without contacting any, so a security review can check its reach
+// before it runs in a locked-down network. migratorx sends no telemetry: the
+// endpoints listed, and the local commands the plan configures, are all it
+// talks to.
+func setupDepsReport(fs *flag.FlagSet) runFunc {
+	forCommand := fs.String("for", "", "only list what this command contacts, e.g. \"upgrade replicas\" (default every command)")
+	in := depsInputs{}
+	fs.StringVar(&in.SchemaDSN, "schema-dsn", "", "the --schema-dsn the commands will be given")
+	fs.StringVar(&in.ConnectURL, "connect-url", "", "the --connect-url the commands will be given")
+	var brokers stringList
+	fs.Var(&brokers, "kafka-brokers", "the --kafka-brokers doctor will be given; repeatable or comma-separated")
+	fs.IntVar(&in.MySQLPort, "mysql-port", defaultMySQLPort, "MySQL port for plan hosts that do not name one")
+	return func(ctx context.Context, env *env, args []string) Output {
+		plan, err := env.loadPlan()
+		if err != nil {
+			return blockOutput(err)
+		}
+		command := strings.Join(strings.Fields(*forCommand), " ")
+		if command != "" && !isCommand(command) {
+			return blockOutput(failure.Config("--for %q is not a migratorx command", *forCommand))
+		}
+		in.Brokers = brokers
+		deps := planDependencies(env, plan, in)
+		env.Manifest.recordCheck("deps_report", map[string]interface{}{"for": command, "schema_dsn": in.SchemaDSN != "", "connect_url": in.ConnectURL != "", "brokers": len(in.Brokers)})
+		return dependencyOutput(deps, command)
+	}
+}
+
+// isCommand reports whether path names a runnable command.
+func isCommand(path string) bool {
+	fields := strings.Fields(path)
+	cmd, resolved, _ := resolve(rootCommand(), fields)
+	return cmd.Setup != nil && len(resolved) == len(fields)+1
+}
+
+var dsnAddress = regexp.MustCompile(`tcp\(([^)]*)\)`)
+
+// planDependencies lists what the plan and endpoint flags make migratorx
+// contact, and from which commands.
+func planDependencies(env *env, plan workflow.MigrationPlan, in depsInputs) []*dependency {
+	deps := &dependencies{}
+	for _, host := range doctorHosts(plan) {
+		deps.add(depMySQL, hostAddress(plan.Topology.Address(host), in.MySQLPort), hostSource(plan, host), "doctor")
+	}
+	if in.SchemaDSN != "" {
+		for _, host := range append([]string{plan.Topology.Primary}, plan.Topology.Replicas...) {
+			endpoint := plan.Topology.Address(host)
+			if m := dsnAddress.FindStringSubmatch(strings.ReplaceAll(in.SchemaDSN, "{host}", endpoint)); m != nil {
+				endpoint = m[1]
+			}
+			deps.add(depMySQL, endpoint, "--schema-dsn", schemaCommands...)
+		}
+	}
+	if in.ConnectURL != "" {
+		commands := []string{"doctor", "cdc restart"}
+		if len(plan.CDC.AutoRemediation) > 0 {
+			commands = append(commands, "cdc check")
+		}
+		deps.add(depKafkaConnect, redactURL(in.ConnectURL), "--connect-url", commands...)
+	}
+	for _, broker := range in.Brokers {
+		deps.add(depKafka, broker, "--kafka-brokers", "doctor")
+	}
+	if plan.Promotion.Decision.URL != "" {
+		deps.add(depDecisionService, redactURL(plan.Promotion.Decision.URL), "promotion.decision.url", "promote prepare")
+	}
+	if source := plan.TrafficCalendar.Source; strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		deps.add(depTrafficCalendar, redactURL(source), "traffic_calendar.source", "upgrade replica", "upgrade replicas", "promote prepare")
+	}
+	for _, d := range plan.Drain {
+		switch d.Type {
+		case "haproxy":
+			deps.add(depHAProxy, d.Address, "drain."+d.Name, drainCommands...)
+		case "proxysql":
+			deps.add(depProxySQL, d.Address, "drain."+d.Name, drainCommands...)
+		case "command":
+			for _, argv := range [][]string{d.DrainCommand, d.UndrainCommand} {
+				if len(argv) > 0 {
+					deps.add(depCommand, argv[0], "drain."+d.Name, drainCommands...)
+				}
+			}
+		}
+	}
+	if len(plan.Notifications.Command) > 0 {
+		deps.add(depCommand, plan.Notifications.Command[0], "notifications.command", mutatingCommands...)
+	}
+	if len(plan.CDC.SignalCommand) > 0 {
+		deps.add(depCommand, plan.CDC.SignalCommand[0], "cdc.signal_command", "cdc check")
+	}
+	if env.Globals.ReportComment != "" {
+		if target, err := report.ParseTarget(env.Globals.ReportComment); err == nil {
+			deps.add(depReportComment, target.APIBase, "--report-comment", allCommands)
+		}
+	}
+	if env.Globals.Output != "" {
+		if sink, err := env.reportSink(); err == nil {
+			switch s := sink.(type) {
+			case *report.S3Sink:
+				deps.add(depReportOutput, s.Endpoint(), "--output", allCommands)
+			case *report.HTTPSink:
+				deps.add(depReportOutput, redactURL(s.URL), "--output", allCommands)
+			}
+		}
+	}
+	return deps.list
+}
+
+// hostSource names the plan key a host comes from.
+func hostSource(plan workflow.MigrationPlan, host string) string {
+	switch {
+	case host == plan.Topology.Primary:
+		return "topology.primary"
+	case host == plan.PostValidation.Endpoint && !contains(plan.Topology.Replicas, host):
+		return "post_validation.endpoint"
+	}
+	return "topology.replicas"
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// redactURL hides credentials in a URL.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	return u.Redacted()
+}
+
+// dependencyOutput reports each dependency of command (all when empty) as an
+// INFO finding after a summary line.
+func dependencyOutput(deps []*dependency, command string) Output {
+	findings := []OutputFinding{}
+	for _, d := range deps {
+		if command != "" && !contains(d.Commands, command) && !contains(d.Commands, allCommands) {
+			continue
+		}
+		message := fmt.Sprintf("%s %s (%s), contacted by %s", d.Kind, d.Endpoint, strings.Join(d.Sources, ", "), describeCommands(d.Commands))
+		if d.Kind == depCommand {
+			message = fmt.Sprintf("runs local command %s (%s) from %s; what it contacts is not visible to migratorx", d.Endpoint, strings.Join(d.Sources, ", "), describeCommands(d.Commands))
+		}
+		findings = append(findings, OutputFinding{
+			Severity: "INFO",
+			Code:     codeDepsEndpoint,
+			Message:  message,
+			Meta:     map[string]interface{}{"kind": d.Kind, "endpoint": d.Endpoint, "sources": d.Sources, "commands": d.Commands},
+		})
+	}
+	scope := "migratorx"
+	if command != "" {
+		scope = "migratorx " + command
+	}
+	summary := fmt.Sprintf("%s contacts %d external endpoints or local commands and sends no telemetry", scope, len(findings))
+	if len(findings) == 0 {
+		summary = fmt.Sprintf("%s contacts no external endpoints with this plan and these flags; it runs fully offline", scope)
+	}
+	return prependFindings(Output{}, append([]OutputFinding{{
+		Severity: "INFO",
+		Code:     codeDepsReport,
+		Message:  summary,
+		Meta:     map[string]interface{}{"command": command, "dependencies": len(findings)},
+	}}, findings...))
+}
+
+func describeCommands(commands []string) string {
+	if contains(commands, allCommands) {
+		return "every command"
+	}
+	return strings.Join(commands, ", ")
+}
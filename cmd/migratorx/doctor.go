@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"migratorx/internal/checks"
+	"migratorx/internal/state"
+	"migratorx/internal/workflow"
+)
+
+// Doctor defaults.
+const (
+	defaultDoctorTimeout = 5 * time.Second
+	defaultMySQLPort     = 3306
+)
+
+// setupDoctor verifies the operator's environment before anyone starts the
+// real preflight: every plan host and the Kafka brokers accept TCP
+// connections, Kafka Connect answers, the --state file is writable, the
+// identity token is valid and the local clock agrees with Connect's.
+func setupDoctor(fs *flag.FlagSet) runFunc {
+	mysqlPort := fs.Int("mysql-port", defaultMySQLPort, "MySQL port for plan hosts that do not name one")
+	connectURL := fs.String("connect-url", "", "Kafka Connect REST URL; credentials may be given as user:password@ in the URL")
+	var brokers stringList
+	fs.Var(&brokers, "kafka-brokers", "Kafka bootstrap broker (host:port); repeatable or comma-separated")
+	timeout := fs.Duration("timeout", defaultDoctorTimeout, "timeout for each connection attempt")
+	maxSkew := fs.Duration("max-clock-skew", 0, "largest tolerated difference between the local clock and Kafka Connect's (default thresholds.max_clock_skew, or 2s)")
+	return func(ctx context.Context, env *env, args []string) Output {
+		plan, err := env.readPlan()
+		if err != nil {
+			return blockOutput(err)
+		}
+		findings := doctorCredentials(env, plan)
+		findings = append(findings, doctorState(env.Globals.StatePath)...)
+
+		addresses := []string{}
+		for _, host := range doctorHosts(plan) {
+			addresses = append(addresses, hostAddress(plan.Topology.Address(host), *mysqlPort))
+		}
+		findings = append(findings, dialAll(ctx, "host", codeDoctorHostUnreachable, addresses, *timeout)...)
+
+		if *connectURL == "" {
+			findings = append(findings, OutputFinding{Severity: "INFO", Message: "Kafka Connect not checked: --connect-url not set"})
+		} else {
+			skew := *maxSkew
+			if skew <= 0 {
+				skew = plan.Thresholds.MaxClockSkew
+			}
+			if skew <= 0 {
+				skew = checks.DefaultMaxClockSkew
+			}
+			findings = append(findings, doctorConnect(ctx, env.httpClient("kafka_connect"), *connectURL, *timeout, skew)...)
+		}
+		if len(brokers) == 0 {
+			findings = append(findings, OutputFinding{Severity: "INFO", Message: "Kafka brokers not checked: --kafka-brokers not set"})
+		} else {
+			findings = append(findings, dialAll(ctx, "broker", codeDoctorKafkaUnreachable, brokers, *timeout)...)
+		}
+
+		env.Manifest.recordCheck("doctor", map[string]interface{}{"hosts": len(addresses), "connect": *connectURL != "", "brokers": len(brokers), "timeout": timeout.String()})
+		return prependFindings(Output{}, findings)
+	}
+}
+
+// doctorHosts lists the plan's primary, replicas and application endpoint
+// once each.
+func doctorHosts(plan workflow.MigrationPlan) []string {
+	hosts := []string{}
+	seen := map[string]bool{}
+	for _, host := range append(append([]string{plan.Topology.Primary}, plan.Topology.Replicas...), plan.PostValidation.Endpoint) {
+		if host == "" || seen[host] {
+			continue
+		}
+		seen[host] = true
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
+
+// hostAddress adds port to host unless it already names one.
+func hostAddress(host string, port int) string {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// dialAll opens a TCP connection to each address concurrently. Reachable
+// addresses are INFO, unreachable ones BLOCK with code.
+func dialAll(ctx context.Context, kind string, code string, addresses []string, timeout time.Duration) []OutputFinding {
+	findings := make([]OutputFinding, len(addresses))
+	var wg sync.WaitGroup
+	for i, address := range addresses {
+		wg.Add(1)
+		go func(i int, address string) {
+			defer wg.Done()
+			dialer := net.Dialer{Timeout: timeout}
+			conn, err := dialer.DialContext(ctx, "tcp", address)
+			if err != nil {
+				findings[i] = OutputFinding{
+					Severity: "BLOCK",
+					Code:     code,
+					Message:  fmt.Sprintf("%s %s is unreachable: %v", kind, address, err),
+					Meta:     map[string]interface{}{kind: address},
+				}
+				return
+			}
+			conn.Close()
+			findings[i] = OutputFinding{Severity: "INFO", Message: fmt.Sprintf("%s %s accepts connections", kind, address), Meta: map[string]interface{}{kind: address}}
+		}(i, address)
+	}
+	wg.Wait()
+	return findings
+}
+
+// doctorCredentials checks the identity token against the plan's access
+// policy. It reports rather than aborts, so the other checks still run.
+func doctorCredentials(env *env, plan workflow.MigrationPlan) []OutputFinding {
+	if plan.Access.TokensFile == "" {
+		return []OutputFinding{{Severity: "INFO", Message: "identity token not checked: plan is not access-controlled"}}
+	}
+	if err := env.authorize(plan); err != nil {
+		return []OutputFinding{{
+			Severity: "BLOCK",
+			Code:     codeDoctorCredentials,
+			Message:  fmt.Sprintf("identity token is not valid: %v", err),
+			Meta:     map[string]interface{}{"credential": identityTokenEnv},
+		}}
+	}
+	id := env.Manifest.Identity
+	return []OutputFinding{{
+		Severity: "INFO",
+		Message:  fmt.Sprintf("identity token belongs to %s with role %s", id.Name, id.Role),
+		Meta:     map[string]interface{}{"credential": identityTokenEnv, "identity": id.Name, "role": string(id.Role)},
+	}}
+}
+
+// doctorState checks that the state file can be read and that its directory
+// takes the temporary file every checkpoint write goes through.
+func doctorState(path string) []OutputFinding {
+	block := func(err error) []OutputFinding {
+		return []OutputFinding{{
+			Severity: "BLOCK",
+			Code:     codeDoctorStateUnwritable,
+			Message:  fmt.Sprintf("state file %s is not usable: %v", path, err),
+			Meta:     map[string]interface{}{"state": path},
+		}}
+	}
+	if _, err := state.NewFileState(path); err != nil {
+		return block(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return block(err)
+	}
+	probe, err := os.CreateTemp(filepath.Dir(path), ".doctor-*")
+	if err != nil {
+		return block(err)
+	}
+	probe.Close()
+	if err := os.Remove(probe.Name()); err != nil {
+		return block(err)
+	}
+	return []OutputFinding{{Severity: "INFO", Message: fmt.Sprintf("state file %s is writable", path), Meta: map[string]interface{}{"state": path}}}
+}
+
+// doctorConnect calls Kafka Connect's root endpoint, which reports its
+// version, and compares the response's Date header with the local clock.
+func doctorConnect(ctx context.Context, client *http.Client, endpoint string, timeout time.Duration, maxSkew time.Duration) []OutputFinding {
+	display := endpoint
+	if u, err := url.Parse(endpoint); err == nil {
+		display = u.Redacted()
+	}
+	unreachable := func(format string, args ...interface{}) []OutputFinding {
+		return []OutputFinding{{
+			Severity: "BLOCK",
+			Code:     codeDoctorConnectUnreachable,
+			Message:  fmt.Sprintf("Kafka Connect at %s ", display) + fmt.Sprintf(format, args...),
+			Meta:     map[string]interface{}{"endpoint": display},
+		}}
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(endpoint, "/")+"/", nil)
+	if err != nil {
+		return unreachable("is not a valid URL: %v", err)
+	}
+	sent := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return unreachable("is unreachable: %v", err)
+	}
+	defer resp.Body.Close()
+	received := time.Now()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return []OutputFinding{{
+			Severity: "BLOCK",
+			Code:     codeDoctorCredentials,
+			Message:  fmt.Sprintf("Kafka Connect at %s rejected the credentials (%s)", display, resp.Status),
+			Meta:     map[string]interface{}{"credential": "connect", "endpoint": display, "status": resp.StatusCode},
+		}}
+	case resp.StatusCode != http.StatusOK:
+		return unreachable("returned %s", resp.Status)
+	}
+	var info struct {
+		Version string `json:"version"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return unreachable("returned an unexpected response: %v", err)
+	}
+	findings := []OutputFinding{{Severity: "INFO", Message: fmt.Sprintf("Kafka Connect %s answers at %s", info.Version, display), Meta: map[string]interface{}{"endpoint": display, "version": info.Version}}}
+	return append(findings, clockSkewFinding(resp.Header.Get("Date"), sent, received, maxSkew, display)...)
+}
+
+// clockSkewFinding compares a server's HTTP Date header with the local clock
+// at the midpoint of the request. Date has one-second resolution, so skew up
+// to a second plus the round trip is not reported.
+func clockSkewFinding(date string, sent time.Time, received time.Time, maxSkew time.Duration, endpoint string) []OutputFinding {
+	if date == "" {
+		return []OutputFinding{{Severity: "INFO", Message: "clock skew not checked: Kafka Connect sent no Date header", Meta: map[string]interface{}{"endpoint": endpoint}}}
+	}
+	remote, err := http.ParseTime(date)
+	if err != nil {
+		return []OutputFinding{{Severity: "INFO", Message: fmt.Sprintf("clock skew not checked: unparsable Date header %q", date), Meta: map[string]interface{}{"endpoint": endpoint}}}
+	}
+	local := sent.Add(received.Sub(sent) / 2)
+	skew := local.Sub(remote)
+	if skew < 0 {
+		skew = -skew
+	}
+	tolerance := maxSkew + time.Second + received.Sub(sent)
+	if skew > tolerance {
+		return []OutputFinding{{
+			Severity: "WARN",
+			Code:     codeDoctorClockSkew,
+			Message:  fmt.Sprintf("local clock differs from Kafka Connect at %s by %s (max %s); lag and event timestamps will be off", endpoint, skew.Round(time.Second), maxSkew),
+			Meta:     map[string]interface{}{"endpoint": endpoint, "skew": skew.Round(time.Second).String(), "max_clock_skew": maxSkew.String()},
+		}}
+	}
+	return []OutputFinding{{Severity: "INFO", Message: fmt.Sprintf("local clock agrees with Kafka Connect within %s", maxSkew), Meta: map[string]interface{}{"endpoint": endpoint}}}
+}
@@ -3,10 +3,18 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"migratorx/internal/workflow"
 )
 
 type cliOutput struct {
@@ -38,7 +46,8 @@ func TestCLI_EndToEndExamplePlan(t *testing.T) {
 		{"upgrade", "replica", "mysql-replica-1", "--plan", planPath, "--state", statePath, "--simulate", "--io-running", "true", "--sql-running", "true"},
 		{"validate", "replica", "mysql-replica-1", "--plan", planPath, "--schema-primary", schemaPrimary, "--schema-replica", schemaReplica},
 		{"cdc", "check", "--plan", planPath, "--cdc-status", cdcStatus},
-		{"promote", "--plan", planPath, "--confirm", "PROMOTE", "--phrase", "PROMOTE", "--schema-primary", schemaPrimary, "--schema-replica", schemaReplica, "--cdc-status", cdcStatus},
+		{"promote", "prepare", "--plan", planPath, "--state", statePath, "--confirm", "PROMOTE", "--phrase", "PROMOTE", "--schema-primary", schemaPrimary, "--schema-replica", schemaReplica, "--cdc-status", cdcStatus, "--simulate"},
+		{"promote", "execute", "--plan", planPath, "--state", statePath, "--confirm", "PROMOTE", "--simulate"},
 		{"validate", "primary", "--plan", planPath, "--schema-primary", schemaPrimary, "--schema-replica", schemaReplica},
 	}
 
@@ -50,6 +59,1479 @@ func TestCLI_EndToEndExamplePlan(t *testing.T) {
 	}
 }
 
+func TestCLI_WritesRunManifest(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	schema := filepath.Join(temp, "schema.json")
+	cdcStatus := filepath.Join(temp, "cdc_status.json")
+	manifestPath := filepath.Join(temp, "out", "manifest.json")
+
+	writeFile(t, planPath, examplePlanYAML())
+	writeFile(t, schema, exampleSchemaJSON())
+	writeFile(t, cdcStatus, exampleCDCStatusJSON())
+
+	runCLI(t, root, "preflight", "--plan", planPath, "--schema-primary", schema, "--schema-replica", schema, "--cdc-status", cdcStatus, "--manifest", manifestPath)
+
+	b, err := os.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatalf("expected manifest file: %v", err)
+	}
+	var manifest struct {
+		Command  string `json:"command"`
+		PlanHash string `json:"plan_hash"`
+		Topology struct {
+			Hosts []struct {
+				Name    string `json:"name"`
+				Address string `json:"address"`
+			} `json:"hosts"`
+			Note string `json:"note"`
+		} `json:"topology"`
+		Versions struct {
+			Observed map[string]string `json:"observed"`
+			Note     string            `json:"note"`
+		} `json:"versions"`
+		Checks []struct {
+			Name       string                 `json:"name"`
+			Parameters map[string]interface{} `json:"parameters"`
+		} `json:"checks"`
+	}
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		t.Fatalf("failed to parse manifest: %v", err)
+	}
+	if manifest.Command != "preflight" || len(manifest.PlanHash) != 64 {
+		t.Fatalf("unexpected manifest header: %+v", manifest)
+	}
+	if len(manifest.Checks) != 3 || manifest.Checks[0].Parameters["replica_host"] != "mysql-replica-1" || manifest.Checks[1].Name != "reserved_words_80" {
+		t.Fatalf("unexpected manifest checks: %+v", manifest.Checks)
+	}
+	// Preflight reads schema files only: nothing was resolved or reported a
+	// version, and the manifest says so rather than echoing the plan.
+	if len(manifest.Topology.Hosts) != 2 || manifest.Topology.Hosts[0].Address != "mysql-primary" || manifest.Topology.Note == "" {
+		t.Fatalf("unexpected manifest topology: %+v", manifest.Topology)
+	}
+	if len(manifest.Versions.Observed) != 0 || manifest.Versions.Note == "" {
+		t.Fatalf("expected no observed versions, got %+v", manifest.Versions)
+	}
+}
+
+func TestCLI_BlocksModifiedPlanUnlessAccepted(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	statePath := filepath.Join(temp, "state.json")
+	writeFile(t, planPath, examplePlanYAML())
+
+	upgrade := []string{"upgrade", "replica", "mysql-replica-1", "--plan", planPath, "--state", statePath, "--simulate"}
+	if out, raw := runCLI(t, root, upgrade...); out.Summary.Block != 0 {
+		t.Fatalf("initial run returned BLOCK\noutput: %s", raw)
+	}
+
+	writeFile(t, planPath, examplePlanYAML()+"promotion:\n  allow_warn_codes: [SCHEMA_COLUMN_DEFAULT_DIFFERS]\n")
+	if out, raw := runCLI(t, root, upgrade...); out.Summary.Block != 1 {
+		t.Fatalf("expected BLOCK for modified plan\noutput: %s", raw)
+	}
+	if out, raw := runCLI(t, root, append(upgrade, "--accept-plan-change")...); out.Summary.Block != 0 {
+		t.Fatalf("expected accepted plan change to proceed\noutput: %s", raw)
+	}
+}
+
+func TestCLI_WarnsWhenTopologyResolutionChangesMidRun(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	statePath := filepath.Join(temp, "state.json")
+	plan := strings.Replace(examplePlanYAML(), "    - mysql-replica-1\n", "    - mysql-replica-1\n  resolve_dns: true\n  aliases:\n    mysql-primary: 127.0.0.1\n    mysql-replica-1: 127.0.0.2\n", 1)
+	writeFile(t, planPath, plan)
+
+	upgrade := []string{"upgrade", "replica", "mysql-replica-1", "--plan", planPath, "--state", statePath, "--simulate"}
+	if out, raw := runCLI(t, root, upgrade...); out.Summary.Block != 0 || strings.Contains(raw, "HOST_RESOLUTION") {
+		t.Fatalf("expected the first run to pin resolutions quietly\noutput: %s", raw)
+	}
+
+	writeFile(t, planPath, strings.Replace(plan, "127.0.0.2", "127.0.0.3", 1))
+	out, raw := runCLI(t, root, append(upgrade, "--accept-plan-change")...)
+	if !strings.Contains(raw, "HOST_RESOLUTION_CHANGED") || !strings.Contains(raw, "127.0.0.3") || out.Summary.Warn == 0 {
+		t.Fatalf("expected the replica's new address to warn\noutput: %s", raw)
+	}
+}
+
+func TestCLI_UpgradeHoldsReadCapacityFloor(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	statePath := filepath.Join(temp, "state.json")
+	plan := strings.Replace(examplePlanYAML(), "    - mysql-replica-1\n", "    - mysql-replica-1\n    - mysql-replica-2\n", 1)
+	writeFile(t, planPath, plan+"read_pool:\n  min_capacity: 2\n  weights:\n    mysql-replica-2: 1.5\n")
+
+	upgrade := []string{"upgrade", "replica", "mysql-replica-1", "--plan", planPath, "--state", statePath, "--simulate"}
+	out, raw := runCLI(t, root, upgrade...)
+	if out.Summary.Block != 1 || !strings.Contains(raw, "read capacity would drop to 1.5") {
+		t.Fatalf("expected the upgrade to be refused below the read pool floor\noutput: %s", raw)
+	}
+
+	writeFile(t, planPath, plan+"read_pool:\n  min_capacity: 2\n  weights:\n    mysql-replica-2: 2\n")
+	if out, raw := runCLI(t, root, append(upgrade, "--accept-plan-change")...); out.Summary.Block != 0 {
+		t.Fatalf("expected the upgrade to proceed with enough read capacity\noutput: %s", raw)
+	}
+}
+
+func TestCLI_UpgradeConsultsTrafficCalendar(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	statePath := filepath.Join(temp, "state.json")
+	calendar := filepath.Join(temp, "calendar.json")
+	start := time.Now().Add(20 * time.Minute).UTC().Format(time.RFC3339)
+	end := time.Now().Add(3 * time.Hour).UTC().Format(time.RFC3339)
+	writeFile(t, calendar, `{"peaks": [{"name": "launch", "start": "`+start+`", "end": "`+end+`"}]}`)
+	writeFile(t, planPath, examplePlanYAML()+"traffic_calendar:\n  source: "+calendar+"\n")
+
+	upgrade := []string{"upgrade", "replica", "mysql-replica-1", "--plan", planPath, "--state", statePath, "--simulate"}
+	out, raw := runCLI(t, root, upgrade...)
+	if out.Summary.Block != 0 || out.Summary.Warn != 1 || !strings.Contains(raw, "TRAFFIC_PEAK_AHEAD") || strings.Contains(raw, "replication stopped") {
+		t.Fatalf("expected the upgrade to be deferred before the peak\noutput: %s", raw)
+	}
+
+	writeFile(t, planPath, examplePlanYAML()+"traffic_calendar:\n  source: "+calendar+"\n  on_peak: block\n")
+	if out, raw := runCLI(t, root, append(upgrade, "--accept-plan-change")...); out.Summary.Block != 1 || !strings.Contains(raw, "TRAFFIC_PEAK_AHEAD") {
+		t.Fatalf("expected on_peak: block to block the upgrade\noutput: %s", raw)
+	}
+
+	writeFile(t, planPath, examplePlanYAML()+"traffic_calendar:\n  source: "+calendar+"\n  lead_time: 10m\n")
+	if out, raw := runCLI(t, root, append(upgrade, "--accept-plan-change")...); out.Summary.Block != 0 || !strings.Contains(raw, "TRAFFIC_CALENDAR_CLEAR") {
+		t.Fatalf("expected the upgrade to start when the peak is beyond the lead time\noutput: %s", raw)
+	}
+}
+
+func TestCLI_UpgradeReconcilesStoppedReplication(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	statePath := filepath.Join(temp, "state.json")
+	writeFile(t, planPath, examplePlanYAML())
+
+	out, raw := runCLI(t, root, "upgrade", "replica", "mysql-replica-1", "--plan", planPath, "--state", statePath, "--simulate", "--io-running", "false", "--sql-running", "false", "--reconcile")
+	if out.Summary.Warn != 0 || out.Summary.Block != 0 {
+		t.Fatalf("expected the stopped replica to be reconciled without warnings\noutput: %s", raw)
+	}
+	if !strings.Contains(raw, "reconciled: replication appears stopped") || strings.Contains(raw, `"replication stopped"`) {
+		t.Fatalf("expected the stop checkpoint to be reconciled instead of stopping again\noutput: %s", raw)
+	}
+}
+
+func TestCLI_UpgradeWarnsAboutChangesCausedByUpgrade(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	statePath := filepath.Join(temp, "state.json")
+	snapshots := filepath.Join(temp, "snapshots.json")
+	writeFile(t, planPath, examplePlanYAML())
+	writeFile(t, snapshots, `{"mysql-replica-1": [
+  {"Variables": {"sql_mode": "STRICT_TRANS_TABLES,NO_AUTO_CREATE_USER"}, "Objects": {"app": {"tables": 4, "triggers": 2}}},
+  {"Variables": {"sql_mode": "STRICT_TRANS_TABLES"}, "Objects": {"app": {"tables": 4}}}
+]}`)
+
+	out, raw := runCLI(t, root, "upgrade", "replica", "mysql-replica-1", "--plan", planPath, "--state", statePath, "--simulate", "--server-snapshots", snapshots)
+	if out.Summary.Warn != 1 || !strings.Contains(raw, "upgrade dropped 2 triggers in app") {
+		t.Fatalf("expected the dropped triggers to warn and the removed sql_mode flag to be ignored\noutput: %s", raw)
+	}
+}
+
+func TestCLI_PromoteRequiresSignedDecision(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+	t.Setenv(decisionSecretEnv, "s3cret")
+
+	decision := "rejected"
+	var posted workflow.DecisionRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&posted)
+		json.NewEncoder(w).Encode(workflow.DecisionResponse{Decision: decision, Reason: "change freeze", Challenge: posted.Challenge, Signature: workflow.SignDecision([]byte("s3cret"), posted.Challenge, decision)})
+	}))
+	defer server.Close()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	schema := filepath.Join(temp, "schema.json")
+	cdcStatus := filepath.Join(temp, "cdc_status.json")
+	statePath := filepath.Join(temp, "state.json")
+	writeFile(t, planPath, examplePlanYAML()+"promotion:\n  decision:\n    url: "+server.URL+"\n    timeout: 5s\n")
+	writeFile(t, schema, exampleSchemaJSON())
+	writeFile(t, cdcStatus, exampleCDCStatusJSON())
+
+	prepare := []string{"promote", "prepare", "--plan", planPath, "--state", statePath, "--confirm", "PROMOTE", "--schema-primary", schema, "--schema-replica", schema, "--cdc-status", cdcStatus, "--simulate"}
+	out, raw := runCLI(t, root, prepare...)
+	if out.Summary.Block == 0 || !strings.Contains(raw, "PROMOTION_DECISION_REJECTED") || !strings.Contains(raw, "change freeze") {
+		t.Fatalf("expected the rejection to block promotion\noutput: %s", raw)
+	}
+	if posted.Migration == "" || len(posted.Findings) == 0 {
+		t.Fatalf("expected the gate findings to be posted, got %+v", posted)
+	}
+
+	decision = "approved"
+	if out, raw := runCLI(t, root, prepare...); out.Summary.Block != 0 || !strings.Contains(raw, "PROMOTION_DECISION_APPROVED") {
+		t.Fatalf("expected an approved decision to let promotion proceed\noutput: %s", raw)
+	}
+}
+
+func TestCLI_FreezesDDLFromUpgradeToPromotion(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	schema := filepath.Join(temp, "schema.json")
+	drifted := filepath.Join(temp, "drifted.json")
+	cdcStatus := filepath.Join(temp, "cdc_status.json")
+	statePath := filepath.Join(temp, "state.json")
+	writeFile(t, planPath, examplePlanYAML()+"ddl_freeze:\n  enabled: true\n")
+	writeFile(t, schema, exampleSchemaJSON())
+	writeFile(t, drifted, strings.Replace(exampleSchemaJSON(), "varchar(255)", "varchar(320)", 1))
+	writeFile(t, cdcStatus, exampleCDCStatusJSON())
+
+	upgrade := []string{"upgrade", "replica", "mysql-replica-1", "--plan", planPath, "--state", statePath, "--simulate"}
+	if out, raw := runCLI(t, root, append(upgrade, "--schema-primary", schema)...); out.Summary.Block != 0 || !strings.Contains(raw, "DDL frozen on primary") {
+		t.Fatalf("expected the upgrade to freeze DDL first\noutput: %s", raw)
+	}
+
+	prepare := []string{"promote", "prepare", "--plan", planPath, "--state", statePath, "--confirm", "PROMOTE", "--schema-replica", schema, "--cdc-status", cdcStatus, "--simulate"}
+	out, raw := runCLI(t, root, append(prepare, "--schema-primary", drifted)...)
+	if out.Summary.Block == 0 || !strings.Contains(raw, "DDL_DRIFT_DETECTED") {
+		t.Fatalf("expected DDL during the freeze to block promotion\noutput: %s", raw)
+	}
+	if out, raw := runCLI(t, root, append(prepare, "--schema-primary", schema)...); out.Summary.Block != 0 || !strings.Contains(raw, "DDL_DRIFT_NONE") {
+		t.Fatalf("expected an unchanged primary to pass the drift check\noutput: %s", raw)
+	}
+	if out, raw := runCLI(t, root, "promote", "execute", "--plan", planPath, "--state", statePath, "--confirm", "PROMOTE", "--simulate"); out.Summary.Block != 0 || !strings.Contains(raw, "DDL freeze released on primary") {
+		t.Fatalf("expected promotion to release the freeze\noutput: %s", raw)
+	}
+}
+
+func TestCLI_PromoteRecordsOffsetSnapshot(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	schema := filepath.Join(temp, "schema.json")
+	cdcStatus := filepath.Join(temp, "cdc_status.json")
+	offsets := filepath.Join(temp, "offsets.json")
+	statePath := filepath.Join(temp, "state.json")
+
+	writeFile(t, planPath, examplePlanYAML())
+	writeFile(t, schema, exampleSchemaJSON())
+	writeFile(t, cdcStatus, exampleCDCStatusJSON())
+	writeFile(t, offsets, `{"Connector": {"File": "mysql-bin.000042", "Position": 1500, "GTIDSet": "uuid:1-102"}, "Primary": {"File": "mysql-bin.000042", "Position": 1500, "GTIDSet": "uuid:1-102"}}`)
+
+	out, raw := runCLI(t, root, "promote", "prepare", "--plan", planPath, "--state", statePath, "--confirm", "PROMOTE", "--schema-primary", schema, "--schema-replica", schema, "--cdc-status", cdcStatus, "--cdc-offsets", offsets, "--simulate")
+	if out.Summary.Block != 0 {
+		t.Fatalf("promote prepare returned BLOCK\noutput: %s", raw)
+	}
+	b, err := os.ReadFile(statePath)
+	if err != nil {
+		t.Fatalf("failed to read state: %v", err)
+	}
+	if !bytes.Contains(b, []byte("promotion:cdc_offsets")) || !bytes.Contains(b, []byte("uuid:1-102")) {
+		t.Fatalf("expected offset snapshot in state:\n%s", b)
+	}
+}
+
+func TestCLI_PromoteExecuteRequiresPrepare(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	schema := filepath.Join(temp, "schema.json")
+	cdcStatus := filepath.Join(temp, "cdc_status.json")
+	statePath := filepath.Join(temp, "state.json")
+	writeFile(t, planPath, examplePlanYAML())
+	writeFile(t, schema, exampleSchemaJSON())
+	writeFile(t, cdcStatus, exampleCDCStatusJSON())
+
+	out, raw := runCLI(t, root, "promote", "execute", "--plan", planPath, "--state", statePath, "--confirm", "PROMOTE", "--simulate")
+	if out.Summary.Block == 0 || !strings.Contains(raw, "run promote prepare first") {
+		t.Fatalf("expected execute without prepare to block\noutput: %s", raw)
+	}
+
+	out, raw = runCLI(t, root, "promote", "prepare", "--plan", planPath, "--state", statePath, "--confirm", "PROMOTE", "--schema-primary", schema, "--schema-replica", schema, "--cdc-status", cdcStatus, "--simulate")
+	if out.Summary.Block != 0 || !strings.Contains(raw, "cutover_plan") {
+		t.Fatalf("expected prepare to print the cutover plan\noutput: %s", raw)
+	}
+
+	out, raw = runCLI(t, root, "promote", "execute", "--plan", planPath, "--state", statePath, "--simulate")
+	if out.Summary.Block == 0 || !strings.Contains(raw, "PROMOTION_CONFIRMATION_REQUIRED") {
+		t.Fatalf("expected execute to require confirmation\noutput: %s", raw)
+	}
+	out, raw = runCLI(t, root, "promote", "execute", "--plan", planPath, "--state", statePath, "--confirm", "PROMOTE", "--simulate")
+	if out.Summary.Block != 0 || !strings.Contains(raw, "replica promoted to primary") {
+		t.Fatalf("expected execute to promote after prepare\noutput: %s", raw)
+	}
+}
+
+func TestCLI_PromoteExecuteRefusesAfterBlockedReprepare(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	schema := filepath.Join(temp, "schema.json")
+	emptySchema := filepath.Join(temp, "empty.json")
+	cdcStatus := filepath.Join(temp, "cdc_status.json")
+	statePath := filepath.Join(temp, "state.json")
+	writeFile(t, planPath, examplePlanYAML()+"promotion:\n  freshness_window: 1h\n")
+	writeFile(t, schema, exampleSchemaJSON())
+	writeFile(t, emptySchema, `{"Tables": []}`)
+	writeFile(t, cdcStatus, exampleCDCStatusJSON())
+
+	prepare := []string{"promote", "prepare", "--plan", planPath, "--state", statePath, "--confirm", "PROMOTE", "--schema-primary", schema, "--cdc-status", cdcStatus, "--simulate"}
+	if out, raw := runCLI(t, root, append(prepare, "--schema-replica", schema)...); out.Summary.Block != 0 {
+		t.Fatalf("expected the first prepare to pass\noutput: %s", raw)
+	}
+	if out, raw := runCLI(t, root, append(prepare, "--schema-replica", emptySchema)...); out.Summary.Block == 0 {
+		t.Fatalf("expected the re-prepare to block on schema drift\noutput: %s", raw)
+	}
+	out, raw := runCLI(t, root, "promote", "execute", "--plan", planPath, "--state", statePath, "--confirm", "PROMOTE", "--simulate")
+	if out.Summary.Block == 0 || strings.Contains(raw, "replica promoted to primary") {
+		t.Fatalf("expected execute to refuse after the blocked re-prepare\noutput: %s", raw)
+	}
+}
+
+func TestCLI_BinlogRetentionExtendedUntilPostValidationEnds(t *testing.T) {
+	root := repoRoot(t)
+	for _, tc := range []struct {
+		name    string
+		replica string
+		want    string
+	}{
+		{name: "completed", replica: `"email"`, want: "marked complete"},
+		{name: "rolled back", replica: `"mail"`, want: "writes re-enabled on original primary"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			temp := t.TempDir()
+			planPath := filepath.Join(temp, "migration.yaml")
+			schema := filepath.Join(temp, "schema.json")
+			replicaSchema := filepath.Join(temp, "replica.json")
+			cdcStatus := filepath.Join(temp, "cdc_status.json")
+			retention := filepath.Join(temp, "retention.json")
+			statePath := filepath.Join(temp, "state.json")
+			writeFile(t, planPath, examplePlanYAML()+
+				"\nthresholds:\n  maintenance_window: 2h\n"+
+				"\nbinlog_retention:\n  extend: true\n"+
+				"\npost_validation:\n  on_block: auto_rollback\n")
+			writeFile(t, schema, exampleSchemaJSON())
+			writeFile(t, replicaSchema, strings.Replace(exampleSchemaJSON(), `"email"`, tc.replica, 1))
+			writeFile(t, cdcStatus, exampleCDCStatusJSON())
+			writeFile(t, retention, `{"ExpireSeconds": 3600}`)
+
+			out, raw := runCLI(t, root, "upgrade", "replica", "mysql-replica-1", "--plan", planPath, "--state", statePath, "--simulate", "--binlog-retention", retention)
+			if out.Summary.Block != 0 || !strings.Contains(raw, "binlog retention extended on primary") || !strings.Contains(raw, `"target_seconds": 14400`) {
+				t.Fatalf("expected the upgrade to extend retention to twice the window\noutput: %s", raw)
+			}
+			if out, raw := runCLI(t, root, "promote", "prepare", "--plan", planPath, "--state", statePath, "--confirm", "PROMOTE", "--schema-primary", schema, "--schema-replica", schema, "--cdc-status", cdcStatus, "--simulate"); out.Summary.Block != 0 {
+				t.Fatalf("prepare returned BLOCK\noutput: %s", raw)
+			}
+			if out, raw := runCLI(t, root, "promote", "execute", "--plan", planPath, "--state", statePath, "--confirm", "PROMOTE", "--simulate"); out.Summary.Block != 0 {
+				t.Fatalf("execute returned BLOCK\noutput: %s", raw)
+			}
+
+			_, raw = runCLI(t, root, "validate", "primary", "--plan", planPath, "--state", statePath, "--schema-primary", schema, "--schema-replica", replicaSchema, "--binlog-retention", retention, "--simulate")
+			if !strings.Contains(raw, tc.want) || !strings.Contains(raw, "binlog retention restored on primary") {
+				t.Fatalf("expected post-validation to restore retention\noutput: %s", raw)
+			}
+		})
+	}
+}
+
+func TestCLI_ValidatePrimaryAutoRollsBackOnBlock(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	schema := filepath.Join(temp, "schema.json")
+	drifted := filepath.Join(temp, "drifted.json")
+	cdcStatus := filepath.Join(temp, "cdc_status.json")
+	statePath := filepath.Join(temp, "state.json")
+	notification := filepath.Join(temp, "notification.json")
+	writeFile(t, planPath, examplePlanYAML()+
+		"\npost_validation:\n  on_block: auto_rollback\n"+
+		"\nnotifications:\n  command: [\"sh\", \"-c\", \"cat > "+notification+"\"]\n")
+	writeFile(t, schema, exampleSchemaJSON())
+	writeFile(t, drifted, strings.Replace(exampleSchemaJSON(), `"email"`, `"mail"`, 1))
+	writeFile(t, cdcStatus, exampleCDCStatusJSON())
+
+	out, raw := runCLI(t, root, "promote", "prepare", "--plan", planPath, "--state", statePath, "--confirm", "PROMOTE", "--schema-primary", schema, "--schema-replica", schema, "--cdc-status", cdcStatus, "--simulate")
+	if out.Summary.Block != 0 {
+		t.Fatalf("prepare returned BLOCK\noutput: %s", raw)
+	}
+	out, raw = runCLI(t, root, "promote", "execute", "--plan", planPath, "--state", statePath, "--confirm", "PROMOTE", "--simulate")
+	if out.Summary.Block != 0 {
+		t.Fatalf("execute returned BLOCK\noutput: %s", raw)
+	}
+
+	out, raw = runCLI(t, root, "validate", "primary", "--plan", planPath, "--state", statePath, "--schema-primary", schema, "--schema-replica", drifted, "--simulate")
+	if out.Summary.Block == 0 || !strings.Contains(raw, "writes re-enabled on original primary") {
+		t.Fatalf("expected blocked post-validation to roll back\noutput: %s", raw)
+	}
+	data, err := os.ReadFile(notification)
+	if err != nil {
+		t.Fatalf("expected notification command to run: %v", err)
+	}
+	if !strings.Contains(string(data), `"event":"auto_rollback"`) {
+		t.Fatalf("unexpected notification payload: %s", data)
+	}
+}
+
+func TestCLI_ProductionRequiresSuccessfulStagingRun(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	schema := filepath.Join(temp, "schema.json")
+	cdcStatus := filepath.Join(temp, "cdc_status.json")
+	statePath := filepath.Join(temp, "state.json")
+	writeFile(t, planPath, examplePlanYAML()+
+		"\nenvironments:\n"+
+		"  - name: staging\n"+
+		"    topology:\n      primary: staging-primary\n      replicas: [staging-replica-1]\n"+
+		"  - name: production\n"+
+		"    requires: staging\n"+
+		"    topology:\n      primary: mysql-primary\n      replicas: [mysql-replica-1]\n")
+	writeFile(t, schema, exampleSchemaJSON())
+	writeFile(t, cdcStatus, exampleCDCStatusJSON())
+
+	out, raw := runCLI(t, root, "upgrade", "replica", "mysql-replica-1", "--plan", planPath, "--state", statePath, "--env", "production", "--simulate")
+	if out.Summary.Block == 0 || !strings.Contains(raw, "ENVIRONMENT_PREREQUISITE_MISSING") {
+		t.Fatalf("expected production to be gated on staging\noutput: %s", raw)
+	}
+
+	out, raw = runCLI(t, root, "promote", "prepare", "--plan", planPath, "--state", statePath, "--env", "staging", "--confirm", "PROMOTE", "--schema-primary", schema, "--schema-replica", schema, "--cdc-status", cdcStatus, "--simulate")
+	if out.Summary.Block != 0 {
+		t.Fatalf("staging prepare returned BLOCK\noutput: %s", raw)
+	}
+	out, raw = runCLI(t, root, "promote", "execute", "--plan", planPath, "--state", statePath, "--env", "staging", "--confirm", "PROMOTE", "--simulate")
+	if out.Summary.Block != 0 {
+		t.Fatalf("staging execute returned BLOCK\noutput: %s", raw)
+	}
+	out, raw = runCLI(t, root, "validate", "primary", "--plan", planPath, "--state", statePath, "--env", "staging", "--schema-primary", schema, "--schema-replica", schema)
+	if out.Summary.Block != 0 || !strings.Contains(raw, `"recorded": true`) {
+		t.Fatalf("expected staging run to be recorded\noutput: %s", raw)
+	}
+
+	out, raw = runCLI(t, root, "upgrade", "replica", "mysql-replica-1", "--plan", planPath, "--state", statePath, "--env", "production", "--simulate")
+	if out.Summary.Block != 0 {
+		t.Fatalf("expected production to proceed after staging\noutput: %s", raw)
+	}
+}
+
+func TestCLI_TrendFlagsNewBlockCodes(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	schema := filepath.Join(temp, "schema.json")
+	drifted := filepath.Join(temp, "drifted.json")
+	cdcStatus := filepath.Join(temp, "cdc_status.json")
+	statePath := filepath.Join(temp, "state.json")
+	writeFile(t, planPath, examplePlanYAML())
+	writeFile(t, schema, exampleSchemaJSON())
+	writeFile(t, drifted, strings.Replace(exampleSchemaJSON(), `"email"`, `"mail"`, 1))
+	writeFile(t, cdcStatus, exampleCDCStatusJSON())
+
+	out, raw := runCLI(t, root, "trend", "--plan", planPath, "--state", statePath)
+	if !strings.Contains(raw, "TREND_INSUFFICIENT_DATA") {
+		t.Fatalf("expected insufficient data without runs\noutput: %s", raw)
+	}
+	runCLI(t, root, "preflight", "--plan", planPath, "--state", statePath, "--trend", "--run-id", "r1", "--schema-primary", schema, "--schema-replica", schema, "--cdc-status", cdcStatus)
+	runCLI(t, root, "preflight", "--plan", planPath, "--state", statePath, "--trend", "--run-id", "r2", "--schema-primary", schema, "--schema-replica", drifted, "--cdc-status", cdcStatus)
+
+	out, raw = runCLI(t, root, "trend", "--plan", planPath, "--state", statePath)
+	if out.Summary.Block == 0 || !strings.Contains(raw, "TREND_BLOCK_REGRESSION") || !strings.Contains(raw, "TREND_GROWING") {
+		t.Fatalf("expected a growing trend with a regression\noutput: %s", raw)
+	}
+}
+
+func TestCLI_FleetReportAggregatesManifests(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	schema := filepath.Join(temp, "schema.json")
+	drifted := filepath.Join(temp, "drifted.json")
+	cdcStatus := filepath.Join(temp, "cdc_status.json")
+	manifests := filepath.Join(temp, "manifests")
+	writeFile(t, schema, exampleSchemaJSON())
+	writeFile(t, drifted, strings.Replace(exampleSchemaJSON(), `"email"`, `"mail"`, 1))
+	writeFile(t, cdcStatus, exampleCDCStatusJSON())
+	for _, shard := range []struct{ name, replicaSchema string }{{"shard_01", schema}, {"shard_02", drifted}} {
+		planPath := filepath.Join(temp, shard.name+".yaml")
+		writeFile(t, planPath, strings.Replace(examplePlanYAML(), "mysql_57_to_80", shard.name, 1))
+		runCLI(t, root, "preflight", "--plan", planPath, "--schema-primary", schema, "--schema-replica", shard.replicaSchema, "--cdc-status", cdcStatus, "--manifest", filepath.Join(manifests, shard.name+".json"))
+	}
+
+	htmlPath := filepath.Join(temp, "fleet.html")
+	out, raw := runCLI(t, root, "fleet", "report", "--manifests", manifests, "--html", htmlPath)
+	if out.Summary.Info != 1 || out.Summary.Block != 1 {
+		t.Fatalf("expected one row per shard with one blocked\noutput: %s", raw)
+	}
+	ready, blocked := strings.Index(raw, "shard_01: ready"), strings.Index(raw, "shard_02: not ready")
+	if ready < 0 || blocked < 0 || ready > blocked {
+		t.Fatalf("expected ready shards first\noutput: %s", raw)
+	}
+	if !strings.Contains(raw, `"cdc": "healthy"`) {
+		t.Fatalf("expected CDC health in the matrix\noutput: %s", raw)
+	}
+	page, err := os.ReadFile(htmlPath)
+	if err != nil {
+		t.Fatalf("expected HTML report: %v", err)
+	}
+	if !strings.Contains(string(page), "<td>shard_02</td><td>BLOCKED</td>") {
+		t.Fatalf("unexpected HTML report:\n%s", page)
+	}
+}
+
+func TestCLI_SimulateRunsPlanAgainstFixtures(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	fixtures := filepath.Join(temp, "fixtures")
+	writeFile(t, planPath, examplePlanYAML())
+	for _, dir := range []string{"schema", "cdc", "replication"} {
+		if err := os.MkdirAll(filepath.Join(fixtures, dir), 0o755); err != nil {
+			t.Fatalf("failed to create fixture dir: %v", err)
+		}
+	}
+	writeFile(t, filepath.Join(fixtures, "schema", "mysql-primary.json"), exampleSchemaJSON())
+	writeFile(t, filepath.Join(fixtures, "schema", "mysql-replica-1.json"), exampleSchemaJSON())
+	writeFile(t, filepath.Join(fixtures, "cdc", "status.json"), exampleCDCStatusJSON())
+	writeFile(t, filepath.Join(fixtures, "replication", "mysql-replica-1.json"), `[{"IOThreadRunning": true, "SQLThreadRunning": true}]`)
+
+	out, raw := runCLI(t, root, "simulate", "--plan", planPath, "--fixtures", fixtures)
+	if out.Summary.Block != 0 {
+		t.Fatalf("simulate returned BLOCK\noutput: %s", raw)
+	}
+	for _, step := range []string{"preflight", "upgrade_replica", "promote", "post_validation"} {
+		if !strings.Contains(raw, `"step": "`+step+`"`) {
+			t.Fatalf("expected findings for step %s\noutput: %s", step, raw)
+		}
+	}
+
+	manifest := filepath.Join(temp, "manifest.json")
+	_, first := runCLI(t, root, "simulate", "--plan", planPath, "--fixtures", fixtures, "--deterministic", "--manifest", manifest)
+	_, second := runCLI(t, root, "simulate", "--plan", planPath, "--fixtures", fixtures, "--deterministic")
+	if first != second {
+		t.Fatalf("expected identical deterministic output\nfirst: %s\nsecond: %s", first, second)
+	}
+	if b, err := os.ReadFile(manifest); err != nil || bytes.Contains(b, []byte("started_at")) {
+		t.Fatalf("expected deterministic manifest without started_at: %v\n%s", err, b)
+	}
+
+	writeFile(t, filepath.Join(fixtures, "schema", "mysql-replica-1.json"), `{"Tables": []}`)
+	out, raw = runCLI(t, root, "simulate", "--plan", planPath, "--fixtures", fixtures)
+	if out.Summary.Block == 0 || !strings.Contains(raw, "step skipped: preflight blocked") {
+		t.Fatalf("expected schema drift to block and halt the rehearsal\noutput: %s", raw)
+	}
+}
+
+func TestCLI_SimulateVerifiesStepIdempotence(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	fixtures := filepath.Join(temp, "fixtures")
+	writeFile(t, planPath, examplePlanYAML())
+	for _, dir := range []string{"schema", "cdc", "replication"} {
+		if err := os.MkdirAll(filepath.Join(fixtures, dir), 0o755); err != nil {
+			t.Fatalf("failed to create fixture dir: %v", err)
+		}
+	}
+	writeFile(t, filepath.Join(fixtures, "schema", "mysql-primary.json"), exampleSchemaJSON())
+	writeFile(t, filepath.Join(fixtures, "schema", "mysql-replica-1.json"), exampleSchemaJSON())
+	writeFile(t, filepath.Join(fixtures, "cdc", "status.json"), exampleCDCStatusJSON())
+	writeFile(t, filepath.Join(fixtures, "replication", "mysql-replica-1.json"), `[{"IOThreadRunning": true, "SQLThreadRunning": true}]`)
+
+	out, raw := runCLI(t, root, "simulate", "--plan", planPath, "--fixtures", fixtures, "--verify-idempotence")
+	if out.Summary.Block != 0 || out.Summary.Info != 6 || strings.Count(raw, `"code": "STEP_IDEMPOTENT"`) != 6 {
+		t.Fatalf("expected every step to verify as idempotent\noutput: %s", raw)
+	}
+	if !strings.Contains(raw, `"first_run_actions": 3`) {
+		t.Fatalf("expected the upgrade to record stop, upgrade and start actions\noutput: %s", raw)
+	}
+}
+
+func TestCLI_RecordedFixturesReplayInSimulate(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	schema := filepath.Join(temp, "schema.json")
+	cdcStatus := filepath.Join(temp, "cdc_status.json")
+	statePath := filepath.Join(temp, "state.json")
+	fixtures := filepath.Join(temp, "recorded")
+	writeFile(t, planPath, examplePlanYAML())
+	writeFile(t, schema, exampleSchemaJSON())
+	writeFile(t, cdcStatus, exampleCDCStatusJSON())
+
+	runCLI(t, root, "preflight", "--plan", planPath, "--schema-primary", schema, "--schema-replica", schema, "--cdc-status", cdcStatus, "--record", fixtures)
+	runCLI(t, root, "upgrade", "replica", "mysql-replica-1", "--plan", planPath, "--state", statePath, "--simulate", "--record", fixtures)
+	for _, path := range []string{"schema/mysql-primary.json", "schema/mysql-replica-1.json", "cdc/status.json", "replication/mysql-replica-1.json"} {
+		if _, err := os.Stat(filepath.Join(fixtures, path)); err != nil {
+			t.Fatalf("expected recorded fixture %s: %v", path, err)
+		}
+	}
+
+	out, raw := runCLI(t, root, "simulate", "--plan", planPath, "--fixtures", fixtures)
+	if out.Summary.Block != 0 {
+		t.Fatalf("expected recorded fixtures to replay cleanly\noutput: %s", raw)
+	}
+}
+
+func TestCLI_PreflightReportsThresholdMargins(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	schema := filepath.Join(temp, "schema.json")
+	cdcStatus := filepath.Join(temp, "cdc_status.json")
+	replication := filepath.Join(temp, "replication.json")
+	writeFile(t, planPath, examplePlanYAML()+"thresholds:\n  max_lag: 10s\n  max_cdc_latency: 5s\n")
+	writeFile(t, schema, exampleSchemaJSON())
+	writeFile(t, cdcStatus, `{"Name": "mysql-prod", "ConnectorState": "RUNNING", "Tasks": [{"ID": 0, "State": "RUNNING"}], "MillisBehindSource": 7000}`)
+	writeFile(t, replication, `[{"IOThreadRunning": true, "SQLThreadRunning": true, "Channels": [{"Name": "", "ApplierLag": 2000000000, "LagKnown": true}]}]`)
+
+	out, raw := runCLI(t, root, "preflight", "--plan", planPath, "--schema-primary", schema, "--schema-replica", schema, "--cdc-status", cdcStatus, "--replication-status", replication)
+	if out.Summary.Warn != 1 || !strings.Contains(raw, "CDC_LATENCY_EXCEEDED") || !strings.Contains(raw, "REPLICA_LAG_OK") {
+		t.Fatalf("expected CDC latency WARN and lag within threshold\noutput: %s", raw)
+	}
+	if !strings.Contains(raw, `"margin": -2`) || !strings.Contains(raw, `"margin": 8`) {
+		t.Fatalf("expected threshold margins in meta\noutput: %s", raw)
+	}
+}
+
+func TestCLI_PreflightTreatsCrossRegionReplicasDifferently(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	schema := filepath.Join(temp, "schema.json")
+	cdcStatus := filepath.Join(temp, "cdc_status.json")
+	replication := filepath.Join(temp, "replication.json")
+	topology := "" +
+		"    - mysql-replica-eu\n" +
+		"    - mysql-replica-1\n" +
+		"  regions:\n" +
+		"    mysql-primary: us-east-1\n" +
+		"    mysql-replica-1: us-east-1\n" +
+		"    mysql-replica-eu: eu-west-1\n"
+	plan := strings.Replace(examplePlanYAML(), "    - mysql-replica-1\n", topology, 1) + "thresholds:\n  max_lag: 10s\n  max_cross_region_lag: 1m\n"
+	writeFile(t, planPath, plan)
+	writeFile(t, schema, exampleSchemaJSON())
+	writeFile(t, cdcStatus, exampleCDCStatusJSON())
+	writeFile(t, replication, `[{"IOThreadRunning": true, "SQLThreadRunning": true, "Channels": [{"Name": "", "ApplierLag": 20000000000, "LagKnown": true}]}]`)
+
+	_, raw := runCLI(t, root, "preflight", "--plan", planPath, "--schema-primary", schema, "--schema-replica", schema, "--cdc-status", cdcStatus, "--replication-status", replication)
+	if !strings.Contains(raw, "REPLICA_LAG_EXCEEDED") || !strings.Contains(raw, `"replica": "mysql-replica-1"`) || !strings.Contains(raw, `"mysql-replica-1": "us-east-1"`) {
+		t.Fatalf("expected the untagged cross-region replica to be passed over for the in-region one\noutput: %s", raw)
+	}
+
+	writeFile(t, planPath, strings.Replace(plan, "  regions:\n", "  promotable: [mysql-replica-eu]\n  regions:\n", 1))
+	_, raw = runCLI(t, root, "preflight", "--plan", planPath, "--schema-primary", schema, "--schema-replica", schema, "--cdc-status", cdcStatus, "--replication-status", replication)
+	if !strings.Contains(raw, "REPLICA_LAG_OK") || !strings.Contains(raw, `"name": "max_cross_region_lag"`) || !strings.Contains(raw, `"cross_region": true`) || !strings.Contains(raw, `"mysql-replica-eu": "eu-west-1"`) {
+		t.Fatalf("expected the tagged cross-region replica to be held to max_cross_region_lag\noutput: %s", raw)
+	}
+}
+
+func TestCLI_PreflightBlocksReplicationWithoutTLS(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	schema := filepath.Join(temp, "schema.json")
+	cdcStatus := filepath.Join(temp, "cdc_status.json")
+	tls := filepath.Join(temp, "tls.json")
+	writeFile(t, planPath, examplePlanYAML()+"replication:\n  require_tls: true\n")
+	writeFile(t, schema, exampleSchemaJSON())
+	writeFile(t, cdcStatus, exampleCDCStatusJSON())
+	writeFile(t, tls, `{"Channels": [{"Channel": "", "SSLAllowed": "NO"}], "Server": {"TLSVersion": "TLSv1.2,TLSv1.3", "CertNotAfter": "2099-01-01T00:00:00Z"}}`)
+
+	out, raw := runCLI(t, root, "preflight", "--plan", planPath, "--schema-primary", schema, "--schema-replica", schema, "--cdc-status", cdcStatus, "--replication-tls", tls)
+	if out.Summary.Block != 1 || !strings.Contains(raw, "REPLICATION_TLS_DISABLED") {
+		t.Fatalf("expected plain replication channel to block\noutput: %s", raw)
+	}
+}
+
+func TestCLI_PreflightBlocksIncompatibleClients(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	schema := filepath.Join(temp, "schema.json")
+	cdcStatus := filepath.Join(temp, "cdc_status.json")
+	security := filepath.Join(temp, "security.json")
+	writeFile(t, planPath, examplePlanYAML()+"clients:\n  - name: billing\n    driver: mysql-connector-java 5.1\n    auth_plugins: [mysql_native_password]\n")
+	writeFile(t, schema, exampleSchemaJSON())
+	writeFile(t, cdcStatus, exampleCDCStatusJSON())
+	writeFile(t, security, `{"TLSVersions": "TLSv1.2,TLSv1.3", "DefaultAuthPlugin": "caching_sha2_password"}`)
+
+	out, raw := runCLI(t, root, "preflight", "--plan", planPath, "--schema-primary", schema, "--schema-replica", schema, "--cdc-status", cdcStatus, "--security-settings", security)
+	if out.Summary.Block != 1 || !strings.Contains(raw, "CLIENT_AUTH_PLUGIN_UNSUPPORTED") {
+		t.Fatalf("expected legacy connector to block\noutput: %s", raw)
+	}
+}
+
+func TestCLI_CDCCheckBlocksOutdatedConnector(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	cdcStatus := filepath.Join(temp, "cdc_status.json")
+	plugins := filepath.Join(temp, "plugins.json")
+	writeFile(t, planPath, examplePlanYAML())
+	writeFile(t, cdcStatus, exampleCDCStatusJSON())
+	writeFile(t, plugins, `[{"class": "io.debezium.connector.mysql.MySqlConnector", "type": "source", "version": "0.9.5.Final"}]`)
+
+	out, raw := runCLI(t, root, "cdc", "check", "--plan", planPath, "--cdc-status", cdcStatus, "--cdc-plugins", plugins)
+	if out.Summary.Block != 1 || !strings.Contains(raw, "CDC_CONNECTOR_UPGRADE_REQUIRED") {
+		t.Fatalf("expected outdated connector to block\noutput: %s", raw)
+	}
+}
+
+func TestCLI_CDCCheckVerifiesKafkaACLsAndQuotas(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	cdcStatus := filepath.Join(temp, "cdc_status.json")
+	access := filepath.Join(temp, "kafka_access.json")
+	cdc := "  connector: mysql-prod\n  principal: User:debezium\n  topic_prefix: dbserver1\n  schema_history_topic: schema-changes.inventory\n  peak_byte_rate: 10485760\n"
+	writeFile(t, planPath, strings.Replace(examplePlanYAML(), "  connector: mysql-prod\n", cdc, 1))
+	writeFile(t, cdcStatus, exampleCDCStatusJSON())
+	writeFile(t, access, `{
+  "ACLs": [
+    {"Principal": "User:debezium", "ResourceType": "TOPIC", "ResourceName": "dbserver1", "PatternType": "PREFIXED", "Operation": "ALL", "Permission": "ALLOW"},
+    {"Principal": "User:debezium", "ResourceType": "TOPIC", "ResourceName": "schema-changes.inventory", "PatternType": "LITERAL", "Operation": "WRITE", "Permission": "ALLOW"},
+    {"Principal": "User:debezium", "ResourceType": "TOPIC", "ResourceName": "schema-changes.inventory", "PatternType": "LITERAL", "Operation": "DESCRIBE", "Permission": "ALLOW"}
+  ],
+  "Quotas": [{"Entity": "user=debezium", "ProducerByteRate": 1048576}]
+}`)
+
+	out, raw := runCLI(t, root, "cdc", "check", "--plan", planPath, "--cdc-status", cdcStatus, "--kafka-access", access)
+	if out.Summary.Block != 1 || out.Summary.Warn != 1 || !strings.Contains(raw, "CDC_KAFKA_ACL_MISSING") || !strings.Contains(raw, "CDC_KAFKA_QUOTA_THROTTLES") {
+		t.Fatalf("expected missing READ on schema history to block and the quota to warn\noutput: %s", raw)
+	}
+}
+
+func TestCLI_PreflightBlocksCDCServerIDCollision(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	schema := filepath.Join(temp, "schema.json")
+	cdcStatus := filepath.Join(temp, "cdc_status.json")
+	identity := filepath.Join(temp, "identity.json")
+	writeFile(t, planPath, strings.Replace(examplePlanYAML(), "  connector: mysql-prod\n", "  connector: mysql-prod\n  server_id: 2\n", 1))
+	writeFile(t, schema, exampleSchemaJSON())
+	writeFile(t, cdcStatus, exampleCDCStatusJSON())
+	writeFile(t, identity, `{"mysql-primary": {"ServerID": 1, "ServerUUID": "aaaaaaaa-0000-0000-0000-000000000001"}, "mysql-replica-1": {"ServerID": 2, "ServerUUID": "aaaaaaaa-0000-0000-0000-000000000002"}}`)
+
+	out, raw := runCLI(t, root, "preflight", "--plan", planPath, "--schema-primary", schema, "--schema-replica", schema, "--cdc-status", cdcStatus, "--server-identity", identity)
+	if out.Summary.Block != 1 || !strings.Contains(raw, "CDC_SERVER_ID_COLLISION") {
+		t.Fatalf("expected CDC server id collision to block\noutput: %s", raw)
+	}
+}
+
+func TestCLI_PreflightStoresArtifactsNextToState(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	schema := filepath.Join(temp, "schema.json")
+	statePath := filepath.Join(temp, "state.json")
+	writeFile(t, planPath, examplePlanYAML())
+	writeFile(t, schema, exampleSchemaJSON())
+
+	_, raw := runCLI(t, root, "preflight", "--plan", planPath, "--schema-primary", schema, "--schema-replica", schema, "--state", statePath, "--artifacts")
+	dump := filepath.Join(statePath+".artifacts", "mysql_57_to_80", "default", "schema_parity", "primary-schema.json")
+	if _, err := os.Stat(dump); err != nil {
+		t.Fatalf("expected schema dump at %s: %v\noutput: %s", dump, err, raw)
+	}
+	if !strings.Contains(raw, "primary-schema.json") {
+		t.Fatalf("expected findings to reference the artifact\noutput: %s", raw)
+	}
+}
+
+func TestCLI_PreflightWritesReportToOutputDirectory(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	schema := filepath.Join(temp, "schema.json")
+	reports := filepath.Join(temp, "evidence") + "/"
+	writeFile(t, planPath, examplePlanYAML())
+	writeFile(t, schema, exampleSchemaJSON())
+
+	_, raw := runCLI(t, root, "preflight", "--plan", planPath, "--schema-primary", schema, "--schema-replica", schema, "--state", filepath.Join(temp, "state.json"), "--run-id", "r1", "--deterministic", "--output", reports)
+	if !strings.Contains(raw, codeReportWritten) {
+		t.Fatalf("expected a report written finding first\noutput: %s", raw)
+	}
+	written, err := os.ReadFile(filepath.Join(reports, "r1", "preflight.json"))
+	if err != nil {
+		t.Fatalf("expected a report named by run ID and step: %v\noutput: %s", err, raw)
+	}
+	if !strings.Contains(string(written), "SCHEMA_PARITY_OK") || strings.Contains(string(written), codeReportWritten) {
+		t.Fatalf("expected the report to hold the run's findings\nreport: %s", written)
+	}
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	out, raw := runCLI(t, root, "preflight", "--plan", planPath, "--schema-primary", schema, "--schema-replica", schema, "--state", filepath.Join(temp, "state.json"), "--output", "s3://evidence/")
+	if !strings.Contains(raw, codeReportWriteFailed) || !strings.Contains(raw, "no AWS credentials") || out.Summary.Warn == 0 {
+		t.Fatalf("expected an S3 upload without credentials to warn\noutput: %s", raw)
+	}
+}
+
+func TestCLI_PreflightSkipsChecksWithoutInputsUnlessStrict(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	schema := filepath.Join(temp, "schema.json")
+	writeFile(t, planPath, examplePlanYAML())
+	writeFile(t, schema, exampleSchemaJSON())
+
+	out, raw := runCLI(t, root, "preflight", "--plan", planPath, "--schema-primary", schema, "--schema-replica", schema)
+	if out.Summary.Block != 0 || out.Summary.Warn != 1 || !strings.Contains(raw, "check skipped: input not provided (--cdc-status)") {
+		t.Fatalf("expected the CDC check to be skipped with a WARN\noutput: %s", raw)
+	}
+	out, raw = runCLI(t, root, "preflight", "--plan", planPath, "--schema-primary", schema, "--schema-replica", schema, "--strict")
+	if out.Summary.Block != 1 || !strings.Contains(raw, "cdc status file path is required") {
+		t.Fatalf("expected --strict to block on the missing input\noutput: %s", raw)
+	}
+}
+
+func TestCLI_PreflightWarnsOnAutoIncrementBehind(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	schema := filepath.Join(temp, "schema.json")
+	counters := filepath.Join(temp, "auto_increments.json")
+	writeFile(t, planPath, examplePlanYAML())
+	writeFile(t, schema, exampleSchemaJSON())
+	writeFile(t, counters, `{"mysql-primary": {"orders": 5001}, "mysql-replica-1": {"orders": 4800}}`)
+
+	out, raw := runCLI(t, root, "preflight", "--plan", planPath, "--schema-primary", schema, "--schema-replica", schema, "--auto-increments", counters)
+	if !strings.Contains(raw, "AUTO_INCREMENT_BEHIND") || out.Summary.Warn == 0 {
+		t.Fatalf("expected the replica's lower counter to warn\noutput: %s", raw)
+	}
+}
+
+func TestCLI_PreflightWarnsOnFulltextSettingMismatch(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	schema := filepath.Join(temp, "schema.json")
+	settings := filepath.Join(temp, "fulltext.json")
+	writeFile(t, planPath, examplePlanYAML())
+	writeFile(t, schema, `{"Tables": [{"Name": "articles", "Engine": "InnoDB", "Columns": [{"Name": "id", "Type": "bigint"}, {"Name": "body", "Type": "text"}], "PrimaryKey": ["id"], "Indexes": [{"Name": "ft_body", "Type": "FULLTEXT", "Columns": ["body"]}]}]}`)
+	writeFile(t, settings, `{"mysql-primary": {"Variables": {"innodb_ft_min_token_size": "2"}}, "mysql-replica-1": {"Variables": {"innodb_ft_min_token_size": "3"}}}`)
+
+	out, raw := runCLI(t, root, "preflight", "--plan", planPath, "--schema-primary", schema, "--schema-replica", schema, "--fulltext", settings)
+	if !strings.Contains(raw, "FULLTEXT_SETTING_MISMATCH") || !strings.Contains(raw, "articles") || out.Summary.Warn == 0 {
+		t.Fatalf("expected the differing token size to warn for articles\noutput: %s", raw)
+	}
+}
+
+func TestCLI_PreflightWarnsOnDefaultCollationChange(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	schema := filepath.Join(temp, "schema.json")
+	collations := filepath.Join(temp, "collations.json")
+	writeFile(t, planPath, examplePlanYAML())
+	writeFile(t, schema, `{"Tables": [{"Name": "users", "Columns": [{"Name": "email", "Type": "varchar(255)", "Charset": "latin1", "Collation": "latin1_swedish_ci"}], "PrimaryKey": ["email"]}]}`)
+	writeFile(t, collations, `{"mysql-primary": {"ServerCharset": "latin1", "ServerCollation": "latin1_swedish_ci", "Databases": {"shop": "latin1_swedish_ci"}}, "mysql-replica-1": {"ServerCharset": "utf8mb4", "ServerCollation": "utf8mb4_0900_ai_ci"}}`)
+
+	out, raw := runCLI(t, root, "preflight", "--plan", planPath, "--schema-primary", schema, "--schema-replica", schema, "--collations", collations)
+	if !strings.Contains(raw, "COLLATION_DEFAULT_CHANGED") || !strings.Contains(raw, "COLLATION_JOIN_AT_RISK") || out.Summary.Warn == 0 {
+		t.Fatalf("expected the changed default to warn for users\noutput: %s", raw)
+	}
+}
+
+func TestCLI_PreflightReadsSchemasLive(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	address, queries := fakeMySQLServer(t, liveSchemaResults()...)
+	_, port, _ := net.SplitHostPort(address)
+	planPath := filepath.Join(temp, "migration.yaml")
+	writeFile(t, planPath, liveSchemaPlanYAML())
+
+	out, raw := runCLI(t, root, "preflight", "--plan", planPath, "--schema-dsn", "migratorx@tcp({host}:"+port+")/")
+	if strings.Contains(raw, "database/sql driver") || out.Summary.Block != 0 {
+		t.Fatalf("expected the live schema checks to connect and pass\noutput: %s", raw)
+	}
+	if !strings.Contains(raw, "SCHEMA_PARITY_OK") || !strings.Contains(raw, "AUTO_INCREMENT_PARITY_OK") {
+		t.Fatalf("expected schema and counter parity read from the server\noutput: %s", raw)
+	}
+	read := false
+	for _, q := range queries() {
+		read = read || strings.Contains(q, "information_schema.COLUMNS")
+	}
+	if !read {
+		t.Fatalf("expected the schemas to be read from the server, got queries %v", queries())
+	}
+}
+
+func TestCLI_ValidateReplicaComparesDataLive(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	address, queries := fakeMySQLServer(t, append([]fakeMySQLResult{
+		{match: "SELECT COLUMN_NAME FROM information_schema.COLUMNS", columns: []string{"COLUMN_NAME"}, rows: [][]interface{}{{"id"}, {"total"}}},
+		{match: "SELECT COLUMN_NAME FROM information_schema.STATISTICS", columns: []string{"COLUMN_NAME"}, rows: [][]interface{}{{"id"}}},
+		{match: "DESC LIMIT 1", columns: []string{"id"}, rows: [][]interface{}{{"5"}}},
+		{match: "SELECT COUNT(*), COALESCE(BIT_XOR(", columns: []string{"rows", "checksum"}, rows: [][]interface{}{{"5", "3735928559"}}},
+		{match: "ORDER BY RAND()", columns: []string{"id"}, rows: [][]interface{}{{"1"}, {"2"}}},
+		{match: "IN ((", columns: []string{"id", "checksum"}, rows: [][]interface{}{{"1", "11"}, {"2", "22"}}},
+	}, liveSchemaResults()...)...)
+	_, port, _ := net.SplitHostPort(address)
+	planPath := filepath.Join(temp, "migration.yaml")
+	writeFile(t, planPath, liveSchemaPlanYAML()+"\ndata_parity:\n  tables:\n    - name: shop.orders\n    - name: shop.events\n      mode: sample\n      sample_size: 2\n")
+	dsn := "migratorx@tcp({host}:" + port + ")/?interpolateParams=true"
+
+	out, raw := runCLI(t, root, "validate", "replica", "mysql-replica-1", "--plan", planPath, "--schema-dsn", dsn)
+	if out.Summary.Block != 0 || !strings.Contains(raw, "DATA_PARITY_OK") || !strings.Contains(raw, "DATA_PARITY_SAMPLE_OK") {
+		t.Fatalf("expected both tables compared live\noutput: %s", raw)
+	}
+	chunked := false
+	for _, q := range queries() {
+		chunked = chunked || strings.Contains(q, "FROM `shop`.`orders` WHERE (`id`) <= ('5')")
+	}
+	if !chunked {
+		t.Fatalf("expected shop.orders checksummed by primary-key range, got queries %v", queries())
+	}
+
+	out, raw = runCLI(t, root, "validate", "replica", "mysql-replica-1", "--plan", planPath, "--schema-primary", filepath.Join(temp, "missing.json"), "--schema-replica", filepath.Join(temp, "missing.json"))
+	if !strings.Contains(raw, "CHECK_SKIPPED_INPUT_MISSING") || !strings.Contains(raw, "--schema-dsn") {
+		t.Fatalf("expected data parity skipped without --schema-dsn\noutput: %s", raw)
+	}
+}
+
+func TestCLI_UpgradeStatisticsAnalyzesUpgradedReplica(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	address, queries := fakeMySQLServer(t,
+		fakeMySQLResult{match: "FROM mysql.innodb_table_stats", columns: []string{"database_name", "table_name", "age"}, rows: [][]interface{}{{"shop", "orders", "5"}}},
+	)
+	_, port, _ := net.SplitHostPort(address)
+	planPath := filepath.Join(temp, "migration.yaml")
+	statePath := filepath.Join(temp, "state.json")
+	writeFile(t, planPath, liveSchemaPlanYAML()+"\nstatistics:\n  tables: [shop.orders]\n")
+	statistics := []string{"upgrade", "statistics", "mysql-replica-1", "--plan", planPath, "--state", statePath, "--admin-dsn", "migratorx@tcp({host}:" + port + ")/"}
+
+	if out, raw := runCLI(t, root, statistics...); out.Summary.Block != 1 || !strings.Contains(raw, "has not finished its upgrade") {
+		t.Fatalf("expected a replica that was not upgraded to be refused\noutput: %s", raw)
+	}
+	if out, raw := runCLI(t, root, "upgrade", "replica", "mysql-replica-1", "--plan", planPath, "--state", statePath, "--simulate"); out.Summary.Block != 0 {
+		t.Fatalf("upgrade returned BLOCK\noutput: %s", raw)
+	}
+	out, raw := runCLI(t, root, statistics...)
+	if out.Summary.Block != 0 || out.Summary.Warn != 0 || !strings.Contains(raw, "statistics rebuilt on 1 tables") || !strings.Contains(raw, "statistics are fresh on 1 tables") {
+		t.Fatalf("expected the upgraded replica's statistics to be rebuilt and fresh\noutput: %s", raw)
+	}
+	analyzed := false
+	for _, q := range queries() {
+		analyzed = analyzed || q == "ANALYZE NO_WRITE_TO_BINLOG TABLE `shop`.`orders`"
+	}
+	if !analyzed {
+		t.Fatalf("expected shop.orders to be analyzed, got queries %v", queries())
+	}
+}
+
+func TestCLI_ValidatePrimaryWatchesOldPrimaryDuringSoak(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	writeFile(t, planPath, liveSchemaPlanYAML()+"\npost_validation:\n  soak_duration: 300ms\n  soak_interval: 100ms\n")
+	for _, tc := range []struct {
+		readOnly string
+		code     string
+		block    int
+	}{
+		{readOnly: "1", code: "OLD_PRIMARY_SOAK_OK"},
+		{readOnly: "0", code: "OLD_PRIMARY_WRITABLE", block: 1},
+	} {
+		address, queries := fakeMySQLServer(t, append([]fakeMySQLResult{
+			{match: "@@GLOBAL.read_only", columns: []string{"read_only", "super_read_only"}, rows: [][]interface{}{{tc.readOnly, tc.readOnly}}},
+			{match: "SHOW MASTER STATUS", columns: []string{"File", "Position", "Binlog_Do_DB", "Binlog_Ignore_DB", "Executed_Gtid_Set"}, rows: [][]interface{}{{"binlog.000042", "154", "", "", ""}}},
+		}, liveSchemaResults()...)...)
+		_, port, _ := net.SplitHostPort(address)
+		out, raw := runCLI(t, root, "validate", "primary", "--plan", planPath, "--schema-dsn", "migratorx@tcp({host}:"+port+")/")
+		if out.Summary.Block != tc.block || !strings.Contains(raw, tc.code) {
+			t.Fatalf("expected %s with %d blocks when read_only=%s\noutput: %s", tc.code, tc.block, tc.readOnly, raw)
+		}
+		polls := 0
+		for _, q := range queries() {
+			if strings.Contains(q, "SHOW MASTER STATUS") {
+				polls++
+			}
+		}
+		if tc.block == 0 && polls < 3 {
+			t.Fatalf("expected the old primary to be polled through the soak, got queries %v", queries())
+		}
+	}
+}
+
+func TestCLI_ValidatePrimaryProbesWritePath(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	schema := filepath.Join(temp, "schema.json")
+	writeFile(t, planPath, examplePlanYAML()+"\npost_validation:\n  endpoint: 127.0.0.1\n  probe_table: app.heartbeat\n")
+	writeFile(t, schema, exampleSchemaJSON())
+
+	out, raw := runCLI(t, root, "validate", "primary", "--plan", planPath, "--schema-primary", schema, "--schema-replica", schema)
+	if out.Summary.Block != 0 || !strings.Contains(raw, "HEARTBEAT_SKIPPED") {
+		t.Fatalf("expected the probe to be skipped without --admin-dsn\noutput: %s", raw)
+	}
+
+	for _, tc := range []struct {
+		servedBy string
+		code     string
+		block    int
+	}{
+		{servedBy: "mysql-replica-1", code: "HEARTBEAT_OK"},
+		{servedBy: "mysql-primary", code: "HEARTBEAT_WRONG_PRIMARY", block: 1},
+	} {
+		address, queries := fakeMySQLServer(t,
+			fakeMySQLResult{match: "@@hostname", columns: []string{"@@hostname"}, rows: [][]interface{}{{tc.servedBy}}},
+			fakeMySQLResult{match: "SELECT COUNT(*) FROM app.heartbeat", columns: []string{"COUNT(*)"}, rows: [][]interface{}{{"1"}}},
+		)
+		_, port, _ := net.SplitHostPort(address)
+		out, raw := runCLI(t, root, "validate", "primary", "--plan", planPath, "--schema-primary", schema, "--schema-replica", schema, "--admin-dsn", "migratorx@tcp({host}:"+port+")/?interpolateParams=true")
+		if out.Summary.Block != tc.block || !strings.Contains(raw, tc.code) {
+			t.Fatalf("expected %s with %d blocks when %s serves the write\noutput: %s", tc.code, tc.block, tc.servedBy, raw)
+		}
+		wrote := false
+		for _, q := range queries() {
+			wrote = wrote || strings.HasPrefix(q, "INSERT INTO app.heartbeat")
+		}
+		if !wrote {
+			t.Fatalf("expected a probe row written through the endpoint, got queries %v", queries())
+		}
+	}
+}
+
+func TestCLI_PreflightFailFastStopsAtFirstBlock(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	schema := filepath.Join(temp, "schema.json")
+	emptySchema := filepath.Join(temp, "empty.json")
+	writeFile(t, planPath, examplePlanYAML())
+	writeFile(t, schema, exampleSchemaJSON())
+	writeFile(t, emptySchema, `{"Tables": []}`)
+
+	out, raw := runCLI(t, root, "preflight", "--plan", planPath, "--schema-primary", schema, "--schema-replica", emptySchema, "--fail-fast")
+	if out.Summary.Block != 1 || !strings.Contains(raw, "CHECK_NOT_RUN") || strings.Contains(raw, "CHECK_SKIPPED_INPUT_MISSING") {
+		t.Fatalf("expected fail-fast to stop before the CDC check\noutput: %s", raw)
+	}
+}
+
+func TestCLI_PreflightBlocksShortBinlogRetention(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	schema := filepath.Join(temp, "schema.json")
+	cdcStatus := filepath.Join(temp, "cdc_status.json")
+	retention := filepath.Join(temp, "retention.json")
+	writeFile(t, planPath, examplePlanYAML()+"\nthresholds:\n  maintenance_window: 6h\n")
+	writeFile(t, schema, exampleSchemaJSON())
+	writeFile(t, cdcStatus, exampleCDCStatusJSON())
+	writeFile(t, retention, `{"ExpireSeconds": 14400, "GTIDPurged": "uuid:1-100"}`)
+
+	out, raw := runCLI(t, root, "preflight", "--plan", planPath, "--schema-primary", schema, "--schema-replica", schema, "--cdc-status", cdcStatus, "--binlog-retention", retention)
+	if out.Summary.Block != 1 || !strings.Contains(raw, "BINLOG_RETENTION_TOO_SHORT") {
+		t.Fatalf("expected short retention to block\noutput: %s", raw)
+	}
+}
+
+func TestCLI_PreflightWarnsAboutClockSkew(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	schema := filepath.Join(temp, "schema.json")
+	cdcStatus := filepath.Join(temp, "cdc_status.json")
+	clocks := filepath.Join(temp, "clocks.json")
+	plan := strings.Replace(examplePlanYAML(), "  connector: mysql-prod\n", "  connector: mysql-prod\n  connect_workers: [\"http://connect-1:8083\"]\n", 1)
+	writeFile(t, planPath, plan+"\nthresholds:\n  max_clock_skew: 1s\n")
+	writeFile(t, schema, exampleSchemaJSON())
+	writeFile(t, cdcStatus, exampleCDCStatusJSON())
+	writeFile(t, clocks, `{"mysql-primary": "200ms", "mysql-replica-1": "-100ms", "http://connect-1:8083": "4s"}`)
+
+	out, raw := runCLI(t, root, "preflight", "--plan", planPath, "--schema-primary", schema, "--schema-replica", schema, "--cdc-status", cdcStatus, "--clock-offsets", clocks)
+	if out.Summary.Warn != 1 || !strings.Contains(raw, "CLOCK_SKEW_EXCEEDED") || !strings.Contains(raw, `"ahead": "http://connect-1:8083"`) {
+		t.Fatalf("expected the Connect worker's clock to warn\noutput: %s", raw)
+	}
+}
+
+func TestCLI_UpgradeDrainsUntilUndrain(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	statePath := filepath.Join(temp, "state.json")
+	writeFile(t, planPath, examplePlanYAML()+"drain:\n  - type: command\n    name: lb\n    drain_command: [lbctl, drain, \"{replica}\"]\n    undrain_command: [lbctl, ready, \"{replica}\"]\n")
+
+	_, raw := runCLI(t, root, "upgrade", "replica", "mysql-replica-1", "--plan", planPath, "--state", statePath, "--simulate")
+	if !strings.Contains(raw, "replica drained via lb") {
+		t.Fatalf("expected replica to be drained before upgrade\noutput: %s", raw)
+	}
+	_, raw = runCLI(t, root, "upgrade", "undrain", "mysql-replica-1", "--plan", planPath, "--state", statePath, "--simulate")
+	if !strings.Contains(raw, "replica returned to traffic via lb") {
+		t.Fatalf("expected undrain to reverse the drain\noutput: %s", raw)
+	}
+	_, raw = runCLI(t, root, "upgrade", "undrain", "mysql-replica-1", "--plan", planPath, "--state", statePath, "--simulate")
+	if !strings.Contains(raw, "replica is not drained") {
+		t.Fatalf("expected repeated undrain to be a no-op\noutput: %s", raw)
+	}
+}
+
+func TestCLI_CDCRestartWaitsForRunning(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	statePath := filepath.Join(temp, "state.json")
+	writeFile(t, planPath, examplePlanYAML())
+
+	manifestPath := filepath.Join(temp, "manifest.json")
+	restarts := []string{}
+	userAgents := map[string]bool{}
+	connect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents[r.UserAgent()] = true
+		if r.Method == http.MethodPost {
+			restarts = append(restarts, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		fmt.Fprint(w, `{"name":"mysql-prod","connector":{"state":"RUNNING","worker_id":"w1:8083"},"tasks":[{"id":1,"state":"RUNNING","worker_id":"w1:8083"}]}`)
+	}))
+	defer connect.Close()
+
+	out, raw := runCLI(t, root, "cdc", "restart", "--plan", planPath, "--state", statePath, "--connect-url", connect.URL, "--task", "1", "--poll-interval", "10ms", "--run-id", "cutover-1", "--manifest", manifestPath)
+	if out.Summary.Block != 0 || !strings.Contains(raw, "task 1 of connector mysql-prod restarted and is RUNNING") {
+		t.Fatalf("expected task restart to complete\noutput: %s", raw)
+	}
+	if len(restarts) != 1 || restarts[0] != "/connectors/mysql-prod/tasks/1/restart" {
+		t.Fatalf("unexpected restart calls: %v", restarts)
+	}
+	b, err := os.ReadFile(statePath)
+	if err != nil || !strings.Contains(string(b), "cdc:mysql-prod:restarted") {
+		t.Fatalf("expected restart checkpoint in state, got %s (%v)", b, err)
+	}
+
+	if len(userAgents) != 1 {
+		t.Fatalf("expected every call to carry one client id, got %v", userAgents)
+	}
+	var manifest struct {
+		Calls []struct {
+			System   string `json:"system"`
+			Method   string `json:"method"`
+			ClientID string `json:"client_id"`
+			Status   int    `json:"status"`
+		} `json:"calls"`
+	}
+	b, err = os.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatalf("expected manifest file: %v", err)
+	}
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		t.Fatalf("decode manifest: %v", err)
+	}
+	if len(manifest.Calls) < 2 || manifest.Calls[0].Method != http.MethodPost || !strings.HasPrefix(manifest.Calls[0].ClientID, "migratorx.cutover-1.") || !userAgents[manifest.Calls[0].ClientID] {
+		t.Fatalf("expected the restart and status calls in the manifest, got %+v", manifest.Calls)
+	}
+}
+
+func TestCLI_CDCCheckAutoRemediatesFailedTask(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	statePath := filepath.Join(temp, "state.json")
+	statusPath := filepath.Join(temp, "cdc-status.json")
+	plan := strings.Replace(examplePlanYAML(), "  connector: mysql-prod\n", "  connector: mysql-prod\n  auto_remediation:\n    - code: CDC_TASK_NOT_RUNNING\n      action: restart_task\n      max_attempts: 1\n      retryable_traces: [TimeoutException]\n", 1)
+	writeFile(t, planPath, plan)
+	writeFile(t, statusPath, `{"Name":"mysql-prod","ConnectorState":"RUNNING","Tasks":[{"ID":0,"State":"FAILED","Trace":"org.apache.kafka.common.errors.TimeoutException: Topic not present"}]}`)
+
+	restarts := 0
+	connect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		restarts++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer connect.Close()
+
+	args := []string{"cdc", "check", "--plan", planPath, "--state", statePath, "--cdc-status", statusPath, "--auto-remediate", "--connect-url", connect.URL}
+	out, raw := runCLI(t, root, args...)
+	if out.Summary.Block != 0 || !strings.Contains(raw, "CDC_AUTO_REMEDIATED") {
+		t.Fatalf("expected the failed task to be restarted\noutput: %s", raw)
+	}
+	out, raw = runCLI(t, root, args...)
+	if out.Summary.Block == 0 || !strings.Contains(raw, "CDC_AUTO_REMEDIATION_EXHAUSTED") {
+		t.Fatalf("expected escalation once attempts are used up\noutput: %s", raw)
+	}
+	if restarts != 1 {
+		t.Fatalf("expected one restart, got %d", restarts)
+	}
+}
+
+func TestCLI_CDCCheckComparesMessageSchemasAcrossUpgrade(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	statePath := filepath.Join(temp, "state.json")
+	cdcStatus := filepath.Join(temp, "cdc_status.json")
+	before := filepath.Join(temp, "messages-before.json")
+	after := filepath.Join(temp, "messages-after.json")
+	writeFile(t, planPath, strings.Replace(examplePlanYAML(), "  connector: mysql-prod\n", "  connector: mysql-prod\n  key_tables: [shop.orders]\n", 1))
+	writeFile(t, cdcStatus, exampleCDCStatusJSON())
+	message := `{"shop.orders": {"schema": {"type": "struct", "fields": [{"type": "struct", "field": "after", "fields": [
+		{"type": "int32", "field": "id"},
+		{"type": "int64", "name": "io.debezium.time.Timestamp", "field": "created_at"}]}]}, "payload": {}}}`
+	writeFile(t, before, message)
+	writeFile(t, after, strings.Replace(message, "io.debezium.time.Timestamp", "io.debezium.time.MicroTimestamp", 1))
+
+	_, raw := runCLI(t, root, "cdc", "check", "--plan", planPath, "--state", statePath, "--cdc-status", cdcStatus, "--message-schemas", before)
+	if !strings.Contains(raw, "CDC_MESSAGE_SCHEMA_BASELINE") {
+		t.Fatalf("expected the first run to capture a baseline\noutput: %s", raw)
+	}
+	out, raw := runCLI(t, root, "cdc", "check", "--plan", planPath, "--state", statePath, "--cdc-status", cdcStatus, "--message-schemas", after)
+	if out.Summary.Warn == 0 || !strings.Contains(raw, "CDC_MESSAGE_SCHEMA_CHANGED") || !strings.Contains(raw, "io.debezium.time.MicroTimestamp") {
+		t.Fatalf("expected the temporal precision change to warn\noutput: %s", raw)
+	}
+	_, raw = runCLI(t, root, "cdc", "check", "--plan", planPath, "--state", statePath, "--cdc-status", cdcStatus, "--message-schemas", after, "--recapture-message-schemas")
+	if !strings.Contains(raw, "CDC_MESSAGE_SCHEMA_BASELINE") {
+		t.Fatalf("expected --recapture-message-schemas to replace the baseline\noutput: %s", raw)
+	}
+}
+
+func TestCLI_CDCCheckComparesThroughputWithBaseline(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	statePath := filepath.Join(temp, "state.json")
+	cdcStatus := filepath.Join(temp, "cdc_status.json")
+	before := filepath.Join(temp, "metrics-before.json")
+	during := filepath.Join(temp, "metrics-during.json")
+	writeFile(t, planPath, strings.Replace(examplePlanYAML(), "  connector: mysql-prod\n", "  connector: mysql-prod\n  max_throughput_drop: 0.3\n", 1))
+	writeFile(t, cdcStatus, exampleCDCStatusJSON())
+	writeFile(t, before, `[
+		{"time": "2026-10-18T12:00:00Z", "total_events_seen": 10000, "queue_total_capacity": 8192, "queue_remaining_capacity": 8000},
+		{"time": "2026-10-18T12:00:10Z", "total_events_seen": 20000, "queue_total_capacity": 8192, "queue_remaining_capacity": 8000}]`)
+	writeFile(t, during, `[
+		{"time": "2026-10-18T14:00:00Z", "total_events_seen": 90000, "queue_total_capacity": 8192, "queue_remaining_capacity": 2000},
+		{"time": "2026-10-18T14:00:10Z", "total_events_seen": 94000, "queue_total_capacity": 8192, "queue_remaining_capacity": 1000}]`)
+
+	_, raw := runCLI(t, root, "cdc", "check", "--plan", planPath, "--state", statePath, "--cdc-status", cdcStatus, "--connector-metrics", before)
+	if !strings.Contains(raw, "CDC_THROUGHPUT_BASELINE") || !strings.Contains(raw, "1000.0 events/s") {
+		t.Fatalf("expected the first run to capture a baseline\noutput: %s", raw)
+	}
+	out, raw := runCLI(t, root, "cdc", "check", "--plan", planPath, "--state", statePath, "--cdc-status", cdcStatus, "--connector-metrics", during)
+	if out.Summary.Warn == 0 || !strings.Contains(raw, "CDC_THROUGHPUT_DEGRADED") || !strings.Contains(raw, "400.0 events/s") {
+		t.Fatalf("expected the slower connector to warn\noutput: %s", raw)
+	}
+	_, raw = runCLI(t, root, "cdc", "check", "--plan", planPath, "--state", statePath, "--cdc-status", cdcStatus, "--connector-metrics", during, "--recapture-throughput")
+	if !strings.Contains(raw, "CDC_THROUGHPUT_BASELINE") {
+		t.Fatalf("expected --recapture-throughput to replace the baseline\noutput: %s", raw)
+	}
+}
+
+func TestCLI_StateGCArchivesCompletedRun(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	schemaPath := filepath.Join(temp, "schema.json")
+	cdcStatus := filepath.Join(temp, "cdc_status.json")
+	statePath := filepath.Join(temp, "state.json")
+	writeFile(t, planPath, examplePlanYAML())
+	writeFile(t, schemaPath, exampleSchemaJSON())
+	writeFile(t, cdcStatus, exampleCDCStatusJSON())
+
+	runCLI(t, root, "upgrade", "replica", "mysql-replica-1", "--plan", planPath, "--state", statePath, "--simulate")
+	runCLI(t, root, "promote", "prepare", "--plan", planPath, "--state", statePath, "--confirm", "PROMOTE", "--phrase", "PROMOTE", "--schema-primary", schemaPath, "--schema-replica", schemaPath, "--cdc-status", cdcStatus, "--simulate")
+	runCLI(t, root, "promote", "execute", "--plan", planPath, "--state", statePath, "--confirm", "PROMOTE", "--simulate")
+	_, raw := runCLI(t, root, "validate", "primary", "--plan", planPath, "--state", statePath, "--schema-primary", schemaPath, "--schema-replica", schemaPath)
+	if !strings.Contains(raw, "STATE_RUN_COMPLETED_RECORDED") {
+		t.Fatalf("expected validate primary to mark the run complete\noutput: %s", raw)
+	}
+	_, raw = runCLI(t, root, "upgrade", "replica", "mysql-replica-1", "--plan", planPath, "--state", statePath, "--simulate")
+	if !strings.Contains(raw, `"code": "STATE_RUN_COMPLETED"`) {
+		t.Fatalf("expected a warning when reusing a completed run\noutput: %s", raw)
+	}
+
+	_, raw = runCLI(t, root, "state", "gc", "--plan", planPath, "--state", statePath, "--older-than", "0s")
+	if !strings.Contains(raw, "STATE_RUN_ARCHIVED") {
+		t.Fatalf("expected the completed run to be archived\noutput: %s", raw)
+	}
+	if _, err := os.Stat(statePath + ".archive.json"); err != nil {
+		t.Fatalf("expected an archive next to the state file: %v", err)
+	}
+	_, raw = runCLI(t, root, "upgrade", "replica", "mysql-replica-1", "--plan", planPath, "--state", statePath, "--simulate")
+	if strings.Contains(raw, "STATE_RUN_COMPLETED") || strings.Contains(raw, "already") {
+		t.Fatalf("expected a fresh run after gc\noutput: %s", raw)
+	}
+}
+
+func TestCLI_PromoteExecuteBlocksOnStaleGateResults(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	schemaPath := filepath.Join(temp, "schema.json")
+	cdcStatus := filepath.Join(temp, "cdc_status.json")
+	statePath := filepath.Join(temp, "state.json")
+	writeFile(t, planPath, examplePlanYAML()+"promotion:\n  freshness_window: 1s\n")
+	writeFile(t, schemaPath, exampleSchemaJSON())
+	writeFile(t, cdcStatus, exampleCDCStatusJSON())
+
+	_, raw := runCLI(t, root, "promote", "prepare", "--plan", planPath, "--state", statePath, "--confirm", "PROMOTE", "--schema-primary", schemaPath, "--schema-replica", schemaPath, "--cdc-status", cdcStatus, "--simulate")
+	if !strings.Contains(raw, "PROMOTION_RESULTS_FRESH") {
+		t.Fatalf("expected prepare to report fresh gate results\noutput: %s", raw)
+	}
+	time.Sleep(1500 * time.Millisecond)
+	out, raw := runCLI(t, root, "promote", "execute", "--plan", planPath, "--state", statePath, "--confirm", "PROMOTE", "--simulate")
+	if out.Summary.Block == 0 || !strings.Contains(raw, "PROMOTION_RESULTS_STALE") || strings.Contains(raw, "switched") {
+		t.Fatalf("expected execute to refuse stale gate results\noutput: %s", raw)
+	}
+}
+
+func TestCLI_UpgradeReplicasUpgradesEveryReplica(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	statePath := filepath.Join(temp, "state.json")
+	statusDir := filepath.Join(temp, "replication")
+	if err := os.Mkdir(statusDir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	plan := strings.Replace(examplePlanYAML(), "    - mysql-replica-1\n", "    - mysql-replica-1\n    - mysql-replica-2\n", 1)
+	writeFile(t, planPath, plan+"thresholds:\n  max_lag: 30s\n  min_serving_replicas: 1\n")
+	status := `[{"IOThreadRunning": true, "SQLThreadRunning": true, "Channels": [{"ApplierLag": 1000000000, "LagKnown": true}]}]`
+	writeFile(t, filepath.Join(statusDir, "mysql-replica-1.json"), status)
+	writeFile(t, filepath.Join(statusDir, "mysql-replica-2.json"), status)
+
+	out, raw := runCLI(t, root, "upgrade", "replicas", "--plan", planPath, "--state", statePath, "--replication-status-dir", statusDir, "--simulate")
+	if out.Summary.Block != 0 || strings.Count(raw, `"upgrade completed"`) != 2 {
+		t.Fatalf("expected both replicas to be upgraded one at a time\noutput: %s", raw)
+	}
+}
+
+func TestCLI_PreflightWarnsAboutUnknownConnectedApplications(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	connections := filepath.Join(temp, "connections.json")
+	writeFile(t, planPath, examplePlanYAML()+"clients:\n  - name: billing\n    users: [billing]\n")
+	writeFile(t, connections, `{
+  "mysql-primary": [{"User": "billing", "Host": "10.0.0.5", "Program": "billing-api", "Connections": 8}, {"User": "reports", "Host": "10.0.9.1", "Connections": 1}],
+  "mysql-replica-1": [{"User": "billing", "Host": "10.0.0.6", "Program": "billing-api", "Connections": 2}]
+}`)
+
+	out, raw := runCLI(t, root, "preflight", "--plan", planPath, "--client-connections", connections, "--only-check", "connected_applications")
+	if out.Summary.Warn != 1 || !strings.Contains(raw, "unknown client reports@10.0.9.1") || !strings.Contains(raw, "CONNECTED_APPS_INVENTORY") {
+		t.Fatalf("expected the unlisted reports user to warn\noutput: %s", raw)
+	}
+}
+
+func TestCLI_PreflightBlocksProxySQLWritesToReaders(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	proxysql := filepath.Join(temp, "proxysql.json")
+	writeFile(t, planPath, examplePlanYAML())
+	writeFile(t, proxysql, `{
+  "Servers": [{"Hostgroup": 10, "Hostname": "mysql-primary", "Port": 3306, "Status": "ONLINE"}, {"Hostgroup": 20, "Hostname": "mysql-replica-1", "Port": 3306, "Status": "ONLINE"}],
+  "QueryRules": [{"RuleID": 1, "Active": true, "MatchDigest": "^SELECT", "DestinationHostgroup": 20}, {"RuleID": 2, "Active": true, "MatchDigest": "^(SELECT|UPDATE)", "DestinationHostgroup": 20}],
+  "ReplicationHostgroups": [{"WriterHostgroup": 10, "ReaderHostgroup": 20}],
+  "Users": [{"Username": "app", "DefaultHostgroup": 10}]
+}`)
+
+	out, raw := runCLI(t, root, "preflight", "--plan", planPath, "--proxysql-config", proxysql, "--only-check", "proxysql_routing")
+	if out.Summary.Block != 1 || !strings.Contains(raw, "PROXYSQL_WRITE_RULE_TO_READER") || !strings.Contains(raw, "query rule 2") {
+		t.Fatalf("expected the UPDATE rule routed to readers to block\noutput: %s", raw)
+	}
+}
+
+func TestCLI_PreflightBlocksOnColdBufferPool(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	samples := filepath.Join(temp, "buffer_pool.json")
+	writeFile(t, planPath, examplePlanYAML()+"warmup:\n  min_hit_ratio: 0.99\n  sample_interval: 10ms\n")
+	writeFile(t, samples, `[{"ReadRequests": 1000, "DiskReads": 400}, {"ReadRequests": 3000, "DiskReads": 900}]`)
+
+	out, raw := runCLI(t, root, "preflight", "--plan", planPath, "--buffer-pool-stats", samples, "--only-check", "buffer_pool_hit_ratio")
+	if out.Summary.Block != 1 || !strings.Contains(raw, "BUFFER_POOL_COLD") || !strings.Contains(raw, "0.7500") {
+		t.Fatalf("expected a 75%% hit ratio to block cutover\noutput: %s", raw)
+	}
+}
+
+func TestCLI_PreflightBlocksOnRemovedVariables(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	planPath := filepath.Join(temp, "migration.yaml")
+	optionFile := filepath.Join(temp, "my.cnf")
+	writeFile(t, planPath, examplePlanYAML())
+	writeFile(t, optionFile, "[mysqld]\nquery_cache_size = 64M\ninnodb_buffer_pool_size = 8G\n")
+
+	out, raw := runCLI(t, root, "preflight", "--plan", planPath, "--option-file", optionFile, "--only-check", "removed_variables")
+	if out.Summary.Block != 1 || !strings.Contains(raw, "CONFIG_VARIABLE_REMOVED") || !strings.Contains(raw, "query_cache_size") {
+		t.Fatalf("expected query_cache_size to block the upgrade\noutput: %s", raw)
+	}
+}
+
+func TestCLI_ConfigRenderDropsRemovedVariables(t *testing.T) {
+	root := repoRoot(t)
+	temp := t.TempDir()
+
+	variables := filepath.Join(temp, "variables.json")
+	rendered := filepath.Join(temp, "my.cnf")
+	writeFile(t, variables, `{"query_cache_size": "64M", "innodb_buffer_pool_size": "8G"}`)
+
+	out, raw := runCLI(t, root, "config", "render", "--target", "8.0", "--source", "5.7", "--variables", variables, "--out", rendered)
+	if out.Summary.Info != 1 || !strings.Contains(raw, "CONFIG_RENDERED") {
+		t.Fatalf("expected the configuration to be rendered\noutput: %s", raw)
+	}
+	b, err := os.ReadFile(rendered)
+	if err != nil {
+		t.Fatalf("read rendered configuration: %v", err)
+	}
+	if text := string(b); !strings.Contains(text, "# removed in 8.0: query_cache_size = 64M") || !strings.Contains(text, "\ninnodb_buffer_pool_size = 8G\n") {
+		t.Fatalf("unexpected rendered configuration:\n%s", text)
+	}
+}
+
 func runCLI(t *testing.T, root string, args ...string) (cliOutput, string) {
 	cmdArgs := append([]string{"run", "./cmd/migratorx"}, args...)
 	cmd := exec.Command("go", cmdArgs...)
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeMySQLResult answers queries containing match with a text result set.
+// A nil row value is NULL.
+type fakeMySQLResult struct {
+	match   string
+	columns []string
+	rows    [][]interface{}
+}
+
+// fakeMySQLServer speaks enough of the MySQL client/server protocol for the
+// linked driver to connect over TCP and run text-protocol queries: it accepts
+// any credentials, answers statements other than SELECT/SHOW with OK, and
+// SELECT/SHOW with the first matching result, or an empty one. Statements with
+// arguments need interpolateParams=true in the DSN, as prepared statements are
+// not supported. It returns the listen address and the queries received so far.
+func fakeMySQLServer(t *testing.T, results ...fakeMySQLResult) (string, func() []string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	var mu sync.Mutex
+	queries := []string{}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveFakeMySQL(conn, results, func(q string) {
+				mu.Lock()
+				queries = append(queries, q)
+				mu.Unlock()
+			})
+		}
+	}()
+	return ln.Addr().String(), func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string{}, queries...)
+	}
+}
+
+// liveSchemaResults answers the live schema inspections with one matching
+// shop.orders table on every host.
+func liveSchemaResults() []fakeMySQLResult {
+	return []fakeMySQLResult{
+		{match: "AUTO_INCREMENT IS NOT NULL", columns: []string{"TABLE_SCHEMA", "TABLE_NAME", "AUTO_INCREMENT"}, rows: [][]interface{}{{"shop", "orders", "101"}}},
+		{match: "COALESCE(ENGINE, '')", columns: []string{"TABLE_SCHEMA", "TABLE_NAME", "ENGINE"}, rows: [][]interface{}{{"shop", "orders", "InnoDB"}}},
+		{match: "FROM information_schema.COLUMNS\nWHERE TABLE_SCHEMA", columns: []string{"TABLE_SCHEMA", "TABLE_NAME", "COLUMN_NAME", "COLUMN_TYPE", "IS_NULLABLE", "COLUMN_DEFAULT", "CHARACTER_SET_NAME", "COLLATION_NAME", "EXTRA", "GENERATION_EXPRESSION"}, rows: [][]interface{}{{"shop", "orders", "id", "bigint", "NO", nil, "", "", "", ""}}},
+	}
+}
+
+// liveSchemaPlanYAML is the example plan with both hosts aliased to the local
+// fake server.
+func liveSchemaPlanYAML() string {
+	return strings.Replace(examplePlanYAML(), "    - mysql-replica-1\n", "    - mysql-replica-1\n  aliases:\n    mysql-primary: 127.0.0.1\n    mysql-replica-1: 127.0.0.1\n", 1)
+}
+
+const (
+	fakeClientLongPassword     = 0x00000001
+	fakeClientLongFlag         = 0x00000004
+	fakeClientConnectWithDB    = 0x00000008
+	fakeClientProtocol41       = 0x00000200
+	fakeClientTransactions     = 0x00002000
+	fakeClientSecureConn       = 0x00008000
+	fakeClientPluginAuth       = 0x00080000
+	fakeServerCapabilities     = fakeClientLongPassword | fakeClientLongFlag | fakeClientConnectWithDB | fakeClientProtocol41 | fakeClientTransactions | fakeClientSecureConn | fakeClientPluginAuth
+	fakeServerStatusAutocommit = 0x0002
+)
+
+func serveFakeMySQL(conn net.Conn, results []fakeMySQLResult, record func(string)) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	w := &fakePacketWriter{w: conn}
+
+	handshake := []byte{10}
+	handshake = append(handshake, "8.0.36-fake\x00"...)
+	handshake = append(handshake, 1, 0, 0, 0)
+	handshake = append(handshake, "abcdefgh"...)
+	handshake = append(handshake, 0)
+	handshake = binary.LittleEndian.AppendUint16(handshake, uint16(fakeServerCapabilities&0xffff))
+	handshake = append(handshake, 0x21)
+	handshake = binary.LittleEndian.AppendUint16(handshake, fakeServerStatusAutocommit)
+	handshake = binary.LittleEndian.AppendUint16(handshake, uint16(fakeServerCapabilities>>16))
+	handshake = append(handshake, 21)
+	handshake = append(handshake, make([]byte, 10)...)
+	handshake = append(handshake, "ijklmnopqrst\x00"...)
+	handshake = append(handshake, "mysql_native_password\x00"...)
+	w.write(handshake)
+	if _, _, err := readFakePacket(r); err != nil {
+		return
+	}
+	w.seq = 2
+	w.ok()
+
+	for {
+		payload, _, err := readFakePacket(r)
+		if err != nil || len(payload) == 0 {
+			return
+		}
+		w.seq = 1
+		switch payload[0] {
+		case 0x01: // COM_QUIT
+			return
+		case 0x03: // COM_QUERY
+			query := string(payload[1:])
+			record(query)
+			keyword := strings.ToUpper(strings.Fields(query + " x")[0])
+			if keyword != "SELECT" && keyword != "SHOW" {
+				w.ok()
+				continue
+			}
+			result := fakeMySQLResult{columns: []string{"value"}}
+			for _, candidate := range results {
+				if strings.Contains(query, candidate.match) {
+					result = candidate
+					break
+				}
+			}
+			w.resultSet(result)
+		case 0x0e: // COM_PING
+			w.ok()
+		default:
+			w.write([]byte("\xff\x2f\x04#HY000fake server does not support this command"))
+		}
+	}
+}
+
+func readFakePacket(r io.Reader) ([]byte, byte, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, 0, err
+	}
+	n := int(header[0]) | int(header[1])<<8 | int(header[2])<<16
+	payload := make([]byte, n)
+	_, err := io.ReadFull(r, payload)
+	return payload, header[3], err
+}
+
+type fakePacketWriter struct {
+	w   io.Writer
+	seq byte
+}
+
+func (p *fakePacketWriter) write(payload []byte) {
+	n := len(payload)
+	p.w.Write(append([]byte{byte(n), byte(n >> 8), byte(n >> 16), p.seq}, payload...))
+	p.seq++
+}
+
+func (p *fakePacketWriter) ok() {
+	p.write([]byte{0x00, 0, 0, fakeServerStatusAutocommit, 0, 0, 0})
+}
+
+func (p *fakePacketWriter) eof() {
+	p.write([]byte{0xfe, 0, 0, fakeServerStatusAutocommit, 0})
+}
+
+func (p *fakePacketWriter) resultSet(result fakeMySQLResult) {
+	p.write([]byte{byte(len(result.columns))})
+	for _, name := range result.columns {
+		def := []byte{}
+		for _, s := range []string{"def", "", "", "", name, name} {
+			def = appendLenEncString(def, s)
+		}
+		def = append(def, 0x0c, 0x21, 0)
+		def = binary.LittleEndian.AppendUint32(def, 1024)
+		def = append(def, 0xfd, 0, 0, 0, 0, 0)
+		p.write(def)
+	}
+	p.eof()
+	for _, row := range result.rows {
+		packet := []byte{}
+		for _, v := range row {
+			if v == nil {
+				packet = append(packet, 0xfb)
+				continue
+			}
+			packet = appendLenEncString(packet, v.(string))
+		}
+		p.write(packet)
+	}
+	p.eof()
+}
+
+func appendLenEncString(b []byte, s string) []byte {
+	return append(append(b, byte(len(s))), s...)
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"migratorx/internal/cdc"
+	"migratorx/internal/checks"
+	"migratorx/internal/mysql"
+)
+
+// Fixture directory layout shared by simulate and the recorder:
+//
+//	schema/<host>.json       checks.Schema
+//	cdc/status.json          cdc.ConnectorStatus
+//	cdc/offsets.json         offsetsFile (optional)
+//	replication/<host>.json  []mysql.ReplicationStatus, replayed in order
+func fixtureSchemaPath(dir string, host string) string {
+	return filepath.Join(dir, "schema", host+".json")
+}
+
+func fixtureCDCStatusPath(dir string) string  { return filepath.Join(dir, "cdc", "status.json") }
+func fixtureCDCOffsetsPath(dir string) string { return filepath.Join(dir, "cdc", "offsets.json") }
+
+func fixtureReplicationPath(dir string, host string) string {
+	return filepath.Join(dir, "replication", host+".json")
+}
+
+// fixtureInputs points the file-backed inspectors at a fixture directory.
+// Offsets are optional, as with --cdc-offsets.
+func fixtureInputs(dir string, primaryHost string, replicaHost string) inputFlags {
+	in := inputFlags{
+		PrimarySchema: fixtureSchemaPath(dir, primaryHost),
+		ReplicaSchema: fixtureSchemaPath(dir, replicaHost),
+		CDCStatus:     fixtureCDCStatusPath(dir),
+	}
+	if _, err := os.Stat(fixtureCDCOffsetsPath(dir)); err == nil {
+		in.CDCOffsets = fixtureCDCOffsetsPath(dir)
+	}
+	if _, err := os.Stat(fixtureReplicationPath(dir, replicaHost)); err == nil {
+		in.ReplicationStatus = fixtureReplicationPath(dir, replicaHost)
+	}
+	return in
+}
+
+// timelineReplicaInspector replays recorded replication statuses from the
+// file path returns for a host. Each ReplicationStatus call advances one
+// entry; the last entry repeats.
+type timelineReplicaInspector struct {
+	path    func(host string) string
+	primary string
+
+	mu  sync.Mutex
+	pos map[string]int
+}
+
+func (t *timelineReplicaInspector) IsPrimary(ctx context.Context, host string) (bool, error) {
+	return host == t.primary, nil
+}
+
+func (t *timelineReplicaInspector) ReplicationStatus(ctx context.Context, replica string) (mysql.ReplicationStatus, error) {
+	path := t.path(replica)
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return mysql.ReplicationStatus{}, err
+	}
+	var timeline []mysql.ReplicationStatus
+	if err := json.Unmarshal(b, &timeline); err != nil {
+		return mysql.ReplicationStatus{}, fmt.Errorf("%s: %v", path, err)
+	}
+	if len(timeline) == 0 {
+		return mysql.ReplicationStatus{}, fmt.Errorf("%s: replication timeline is empty", path)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.pos == nil {
+		t.pos = map[string]int{}
+	}
+	i := t.pos[replica]
+	if i >= len(timeline) {
+		i = len(timeline) - 1
+	}
+	t.pos[replica] = i + 1
+	return timeline[i], nil
+}
+
+// fixtureRecorder writes inspector responses to a fixture directory in the
+// layout simulate replays. A nil recorder leaves inspectors unwrapped.
+type fixtureRecorder struct {
+	dir string
+	mu  sync.Mutex
+}
+
+func newFixtureRecorder(dir string) *fixtureRecorder {
+	if dir == "" {
+		return nil
+	}
+	return &fixtureRecorder{dir: dir}
+}
+
+func (r *fixtureRecorder) schemaInspector(inner checks.SchemaInspector) checks.SchemaInspector {
+	if r == nil {
+		return inner
+	}
+	return &recordingSchemaInspector{inner: inner, rec: r}
+}
+
+func (r *fixtureRecorder) debeziumInspector(inner cdc.DebeziumInspector) cdc.DebeziumInspector {
+	if r == nil {
+		return inner
+	}
+	return &recordingDebeziumInspector{inner: inner, rec: r}
+}
+
+func (r *fixtureRecorder) offsetInspector(inner cdc.OffsetInspector) cdc.OffsetInspector {
+	if r == nil {
+		return inner
+	}
+	return &recordingOffsetInspector{inner: inner, rec: r}
+}
+
+func (r *fixtureRecorder) replicaInspector(inner mysql.ReplicaInspector) mysql.ReplicaInspector {
+	if r == nil {
+		return inner
+	}
+	return &recordingReplicaInspector{inner: inner, rec: r}
+}
+
+func (r *fixtureRecorder) write(path string, v interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to record fixture: %v", err)
+	}
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to record fixture: %v", err)
+	}
+	if err := os.WriteFile(path, append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to record fixture: %v", err)
+	}
+	return nil
+}
+
+// update reads the JSON fixture at path into v (leaving v untouched when the
+// file does not exist yet), applies fn and writes the result back.
+func (r *fixtureRecorder) update(path string, v interface{}, fn func()) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(b, v); err != nil {
+			return fmt.Errorf("failed to record fixture: %s: %v", path, err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return fmt.Errorf("failed to record fixture: %v", err)
+	}
+	fn()
+	return r.write(path, v)
+}
+
+type recordingSchemaInspector struct {
+	inner checks.SchemaInspector
+	rec   *fixtureRecorder
+}
+
+func (i *recordingSchemaInspector) Schema(ctx context.Context, host string) (checks.Schema, error) {
+	schema, err := i.inner.Schema(ctx, host)
+	if err != nil {
+		return schema, err
+	}
+	return schema, i.rec.write(fixtureSchemaPath(i.rec.dir, host), schema)
+}
+
+type recordingDebeziumInspector struct {
+	inner cdc.DebeziumInspector
+	rec   *fixtureRecorder
+}
+
+func (i *recordingDebeziumInspector) ConnectorStatus(ctx context.Context, connector string) (cdc.ConnectorStatus, error) {
+	status, err := i.inner.ConnectorStatus(ctx, connector)
+	if err != nil {
+		return status, err
+	}
+	return status, i.rec.write(fixtureCDCStatusPath(i.rec.dir), status)
+}
+
+// recordingOffsetInspector merges connector offsets and primary coordinates
+// into the single offsets fixture.
+type recordingOffsetInspector struct {
+	inner cdc.OffsetInspector
+	rec   *fixtureRecorder
+}
+
+func (i *recordingOffsetInspector) ConnectorOffsets(ctx context.Context, connector string) (cdc.BinlogPosition, error) {
+	pos, err := i.inner.ConnectorOffsets(ctx, connector)
+	if err != nil {
+		return pos, err
+	}
+	var f offsetsFile
+	return pos, i.rec.update(fixtureCDCOffsetsPath(i.rec.dir), &f, func() { f.Connector = &pos })
+}
+
+func (i *recordingOffsetInspector) PrimaryCoordinates(ctx context.Context, host string) (cdc.BinlogPosition, error) {
+	pos, err := i.inner.PrimaryCoordinates(ctx, host)
+	if err != nil {
+		return pos, err
+	}
+	var f offsetsFile
+	return pos, i.rec.update(fixtureCDCOffsetsPath(i.rec.dir), &f, func() { f.Primary = &pos })
+}
+
+// recordingReplicaInspector appends each replication status to the host's
+// timeline, so repeated runs build up the sequence simulate replays.
+type recordingReplicaInspector struct {
+	inner mysql.ReplicaInspector
+	rec   *fixtureRecorder
+}
+
+func (i *recordingReplicaInspector) IsPrimary(ctx context.Context, host string) (bool, error) {
+	return i.inner.IsPrimary(ctx, host)
+}
+
+func (i *recordingReplicaInspector) ReplicationStatus(ctx context.Context, replica string) (mysql.ReplicationStatus, error) {
+	status, err := i.inner.ReplicationStatus(ctx, replica)
+	if err != nil {
+		return status, err
+	}
+	timeline := []mysql.ReplicationStatus{}
+	return status, i.rec.update(fixtureReplicationPath(i.rec.dir, replica), &timeline, func() { timeline = append(timeline, status) })
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"migratorx/internal/cdc"
+	"migratorx/internal/mysql"
+)
+
+// Cluster readiness in the fleet report.
+const (
+	fleetReady   = "READY"
+	fleetWarn    = "WARN"
+	fleetBlocked = "BLOCKED"
+)
+
+// CDC health in the fleet report.
+const (
+	fleetCDCHealthy   = "healthy"
+	fleetCDCUnhealthy = "unhealthy"
+	fleetCDCUnknown   = "unknown"
+)
+
+// fleetRow is one cluster's readiness, taken from its latest preflight manifest.
+type fleetRow struct {
+	Cluster       string   `json:"cluster"`
+	Status        string   `json:"status"`
+	ChecksRun     int      `json:"checks_run"`
+	ChecksPassing int      `json:"checks_passing"`
+	Block         int      `json:"block"`
+	Warn          int      `json:"warn"`
+	LagSeconds    *float64 `json:"lag_seconds,omitempty"`
+	CDC           string   `json:"cdc"`
+	BlockCodes    []string `json:"block_codes,omitempty"`
+	StartedAt     string   `json:"started_at,omitempty"`
+	Manifest      string   `json:"manifest"`
+}
+
+// fleetSorts orders rows; every order falls back to the cluster name.
+var fleetSorts = map[string]func(a, b fleetRow) bool{
+	"status":  func(a, b fleetRow) bool { return fleetStatusRank(a.Status) < fleetStatusRank(b.Status) },
+	"cluster": func(a, b fleetRow) bool { return false },
+	"block":   func(a, b fleetRow) bool { return a.Block > b.Block },
+	"warn":    func(a, b fleetRow) bool { return a.Warn > b.Warn },
+	"lag":     func(a, b fleetRow) bool { return fleetLag(a) > fleetLag(b) },
+}
+
+func fleetStatusRank(status string) int {
+	switch status {
+	case fleetReady:
+		return 0
+	case fleetWarn:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Lag renders the measured replica lag, or nothing when it was not measured.
+func (r fleetRow) Lag() string {
+	if r.LagSeconds == nil {
+		return ""
+	}
+	return fmt.Sprintf("%.1f", *r.LagSeconds)
+}
+
+func fleetLag(r fleetRow) float64 {
+	if r.LagSeconds == nil {
+		return -1
+	}
+	return *r.LagSeconds
+}
+
+func setupFleetReport(fs *flag.FlagSet) runFunc {
+	dir := fs.String("manifests", "", "directory of preflight run manifests (--manifest output), one or more per cluster")
+	sortBy := fs.String("sort", "status", "row order: status, cluster, block, warn or lag")
+	htmlPath := fs.String("html", "", "also write the matrix as a sortable HTML page to this path")
+	return func(ctx context.Context, env *env, args []string) Output {
+		less, ok := fleetSorts[*sortBy]
+		if !ok {
+			return blockOutput(fmt.Errorf("unsupported --sort %q (expected status, cluster, block, warn or lag)", *sortBy))
+		}
+		if *dir == "" {
+			return blockOutput(fmt.Errorf("--manifests is required"))
+		}
+		rows, err := loadFleetRows(*dir)
+		if err != nil {
+			return blockOutput(err)
+		}
+		sort.SliceStable(rows, func(i, j int) bool {
+			if less(rows[i], rows[j]) {
+				return true
+			}
+			if less(rows[j], rows[i]) {
+				return false
+			}
+			return rows[i].Cluster < rows[j].Cluster
+		})
+		env.Manifest.recordCheck("fleet_report", map[string]interface{}{"manifests": *dir, "clusters": len(rows), "sort": *sortBy})
+		if *htmlPath != "" {
+			if err := writeFleetHTML(*htmlPath, rows); err != nil {
+				return blockOutput(err)
+			}
+		}
+		return fleetOutput(rows, *dir)
+	}
+}
+
+// loadFleetRows reads every preflight manifest under dir and keeps the latest
+// one per cluster (migration and environment).
+func loadFleetRows(dir string) ([]fleetRow, error) {
+	latest := map[string]fleetRow{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".json" {
+			return err
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var m runManifest
+		if err := json.Unmarshal(b, &m); err != nil || m.Command != "preflight" || m.Migration == "" {
+			return nil
+		}
+		row := newFleetRow(m, path)
+		if prev, ok := latest[row.Cluster]; !ok || row.StartedAt >= prev.StartedAt {
+			latest[row.Cluster] = row
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]fleetRow, 0, len(latest))
+	for _, row := range latest {
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func newFleetRow(m runManifest, path string) fleetRow {
+	row := fleetRow{Cluster: m.Migration, Block: m.Summary.Block, Warn: m.Summary.Warn, CDC: fleetCDCUnknown, Manifest: path}
+	if m.Environment != "" {
+		row.Cluster += "@" + m.Environment
+	}
+	if m.StartedAt != nil {
+		row.StartedAt = m.StartedAt.UTC().Format("2006-01-02T15:04:05Z")
+	}
+	for _, c := range m.Checks {
+		if c.Summary == nil {
+			continue
+		}
+		row.ChecksRun++
+		if c.Summary.Warn == 0 && c.Summary.Block == 0 {
+			row.ChecksPassing++
+		}
+	}
+	codes := map[string]bool{}
+	for _, f := range m.Findings {
+		if f.Severity == "BLOCK" && f.Code != "" && !codes[f.Code] {
+			codes[f.Code] = true
+			row.BlockCodes = append(row.BlockCodes, f.Code)
+		}
+		switch {
+		case f.Code == mysql.CodeReplicaLagOK || f.Code == mysql.CodeReplicaLagExceeded:
+			if threshold, ok := f.Meta["threshold"].(map[string]interface{}); ok {
+				if measured, ok := threshold["measured"].(float64); ok {
+					row.LagSeconds = &measured
+				}
+			}
+		case f.Code == cdc.CodeHealthy && row.CDC == fleetCDCUnknown:
+			row.CDC = fleetCDCHealthy
+		case strings.HasPrefix(f.Code, "CDC_") && f.Severity != "INFO":
+			row.CDC = fleetCDCUnhealthy
+		}
+	}
+	sort.Strings(row.BlockCodes)
+	switch {
+	case row.Block > 0:
+		row.Status = fleetBlocked
+	case row.Warn > 0:
+		row.Status = fleetWarn
+	default:
+		row.Status = fleetReady
+	}
+	return row
+}
+
+// fleetOutput reports one finding per cluster, at the cluster's severity, with
+// the matrix row as meta.
+func fleetOutput(rows []fleetRow, dir string) Output {
+	if len(rows) == 0 {
+		return prependFindings(Output{}, []OutputFinding{{
+			Severity: "WARN",
+			Code:     codeFleetNoManifests,
+			Message:  fmt.Sprintf("no preflight manifests found under %s", dir),
+			Meta:     map[string]interface{}{"manifests": dir},
+		}})
+	}
+	output := Output{Findings: []OutputFinding{}}
+	for _, row := range rows {
+		var meta map[string]interface{}
+		b, _ := json.Marshal(row)
+		_ = json.Unmarshal(b, &meta)
+		f := OutputFinding{Severity: "INFO", Code: codeFleetClusterReady, Meta: meta}
+		f.Message = fmt.Sprintf("%s: ready (%d/%d checks passing, CDC %s)", row.Cluster, row.ChecksPassing, row.ChecksRun, row.CDC)
+		switch row.Status {
+		case fleetBlocked:
+			f.Severity, f.Code = "BLOCK", codeFleetClusterBlock
+			f.Message = fmt.Sprintf("%s: not ready, %d BLOCK (%s)", row.Cluster, row.Block, strings.Join(row.BlockCodes, ", "))
+			output.Summary.Block++
+		case fleetWarn:
+			f.Severity, f.Code = "WARN", codeFleetClusterWarn
+			f.Message = fmt.Sprintf("%s: %d WARN to review before promoting", row.Cluster, row.Warn)
+			output.Summary.Warn++
+		default:
+			output.Summary.Info++
+		}
+		output.Findings = append(output.Findings, f)
+	}
+	return output
+}
+
+var fleetHTML = template.Must(template.New("fleet").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>migratorx fleet readiness</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+th { cursor: pointer; background: #f4f4f4; }
+.READY { background: #e6f4e6; } .WARN { background: #fff6d6; } .BLOCKED { background: #fbe0e0; }
+</style>
+</head>
+<body>
+<h1>Fleet readiness</h1>
+<p>{{len .}} cluster(s). Click a column to sort.</p>
+<table id="fleet">
+<thead><tr><th>Cluster</th><th>Status</th><th>Checks passing</th><th>BLOCK</th><th>WARN</th><th>Lag (s)</th><th>CDC</th><th>Blocking codes</th><th>Run started</th></tr></thead>
+<tbody>
+{{range .}}<tr class="{{.Status}}"><td>{{.Cluster}}</td><td>{{.Status}}</td><td>{{.ChecksPassing}}/{{.ChecksRun}}</td><td>{{.Block}}</td><td>{{.Warn}}</td><td>{{.Lag}}</td><td>{{.CDC}}</td><td>{{range $i, $c := .BlockCodes}}{{if $i}}, {{end}}{{$c}}{{end}}</td><td>{{.StartedAt}}</td></tr>
+{{end}}</tbody>
+</table>
+<script>
+document.querySelectorAll("#fleet th").forEach(function (th, col) {
+  th.addEventListener("click", function () {
+    var body = document.querySelector("#fleet tbody");
+    var asc = th.dataset.asc !== "true";
+    th.dataset.asc = asc;
+    Array.from(body.rows).sort(function (a, b) {
+      var x = a.cells[col].textContent, y = b.cells[col].textContent;
+      var nx = parseFloat(x), ny = parseFloat(y);
+      var cmp = isNaN(nx) || isNaN(ny) ? x.localeCompare(y) : nx - ny;
+      return asc ? cmp : -cmp;
+    }).forEach(function (row) { body.appendChild(row); });
+  });
+});
+</script>
+</body>
+</html>
+`))
+
+func writeFleetHTML(path string, rows []fleetRow) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := fleetHTML.Execute(f, rows); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,671 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"migratorx/internal/cdc"
+	"migratorx/internal/checks"
+	"migratorx/internal/mysql"
+	"migratorx/internal/workflow"
+)
+
+type schemaFileInspector struct {
+	primaryPath string
+	replicaPath string
+	primaryHost string
+	replicaHost string
+}
+
+func (s *schemaFileInspector) Schema(ctx context.Context, host string) (checks.Schema, error) {
+	var path string
+	if host == "" {
+		return checks.Schema{}, errors.New("host is required")
+	}
+	if s.primaryHost != "" && host == s.primaryHost {
+		path = s.primaryPath
+	} else if s.replicaHost != "" && host == s.replicaHost {
+		path = s.replicaPath
+	} else if s.primaryHost == "" && s.replicaHost == "" {
+		path = s.primaryPath
+	}
+	if path == "" {
+		return checks.Schema{}, fmt.Errorf("schema file path required for host %q", host)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return checks.Schema{}, err
+	}
+	var schema checks.Schema
+	if err := json.Unmarshal(b, &schema); err != nil {
+		return checks.Schema{}, err
+	}
+	return schema, nil
+}
+
+type debeziumFileInspector struct {
+	path string
+}
+
+func (d *debeziumFileInspector) ConnectorStatus(ctx context.Context, connector string) (cdc.ConnectorStatus, error) {
+	if d.path == "" {
+		return cdc.ConnectorStatus{}, fmt.Errorf("cdc status file path is required")
+	}
+	b, err := os.ReadFile(d.path)
+	if err != nil {
+		return cdc.ConnectorStatus{}, err
+	}
+	var status cdc.ConnectorStatus
+	if err := json.Unmarshal(b, &status); err != nil {
+		return cdc.ConnectorStatus{}, err
+	}
+	if status.Name == "" {
+		status.Name = connector
+	}
+	return status, nil
+}
+
+// messageSchemasFileInspector reads {"db.table": <message>} from a JSON file,
+// where each message is a Debezium change event with its schema, as written
+// by the JSON converter with schemas.enable.
+type messageSchemasFileInspector struct {
+	path string
+}
+
+func (m *messageSchemasFileInspector) load() (map[string]json.RawMessage, error) {
+	b, err := os.ReadFile(m.path)
+	if err != nil {
+		return nil, err
+	}
+	var messages map[string]json.RawMessage
+	err = json.Unmarshal(b, &messages)
+	return messages, err
+}
+
+func (m *messageSchemasFileInspector) ValueSchema(ctx context.Context, table string) (cdc.MessageSchema, error) {
+	messages, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+	message, ok := messages[table]
+	if !ok {
+		return nil, fmt.Errorf("no message for %s in %s", table, m.path)
+	}
+	return cdc.ParseValueSchema(message)
+}
+
+// tables lists the tables in the file, sorted.
+func (m *messageSchemasFileInspector) tables() ([]string, error) {
+	messages, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+	tables := make([]string, 0, len(messages))
+	for table := range messages {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	return tables, nil
+}
+
+// throughputFileInspector reads a JSON list of cdc.ThroughputSample, the
+// connector's streaming metrics sampled a few seconds apart.
+type throughputFileInspector struct {
+	path string
+}
+
+func (t *throughputFileInspector) ThroughputSamples(ctx context.Context, connector string) ([]cdc.ThroughputSample, error) {
+	b, err := os.ReadFile(t.path)
+	if err != nil {
+		return nil, err
+	}
+	var samples []cdc.ThroughputSample
+	if err := json.Unmarshal(b, &samples); err != nil {
+		return nil, fmt.Errorf("%s: %v", t.path, err)
+	}
+	return samples, nil
+}
+
+// offsetsFileInspector reads {"Connector": {...}, "Primary": {...}} binlog
+// positions from a JSON file.
+type offsetsFileInspector struct {
+	path string
+}
+
+type offsetsFile struct {
+	Connector *cdc.BinlogPosition
+	Primary   *cdc.BinlogPosition
+}
+
+func (o *offsetsFileInspector) load() (offsetsFile, error) {
+	var f offsetsFile
+	b, err := os.ReadFile(o.path)
+	if err != nil {
+		return f, err
+	}
+	err = json.Unmarshal(b, &f)
+	return f, err
+}
+
+func (o *offsetsFileInspector) ConnectorOffsets(ctx context.Context, connector string) (cdc.BinlogPosition, error) {
+	f, err := o.load()
+	if err != nil {
+		return cdc.BinlogPosition{}, err
+	}
+	if f.Connector == nil {
+		return cdc.BinlogPosition{}, fmt.Errorf("connector offsets missing from %s", o.path)
+	}
+	return *f.Connector, nil
+}
+
+func (o *offsetsFileInspector) PrimaryCoordinates(ctx context.Context, host string) (cdc.BinlogPosition, error) {
+	f, err := o.load()
+	if err != nil {
+		return cdc.BinlogPosition{}, err
+	}
+	if f.Primary == nil {
+		return cdc.BinlogPosition{}, fmt.Errorf("primary coordinates missing from %s", o.path)
+	}
+	return *f.Primary, nil
+}
+
+// tlsFileInspector reads {"Channels": [...], "Server": {...}} TLS settings
+// from a JSON file.
+type tlsFileInspector struct {
+	path string
+}
+
+type tlsFile struct {
+	Channels []mysql.ChannelTLS
+	Server   mysql.ServerTLS
+}
+
+func (t *tlsFileInspector) load() (tlsFile, error) {
+	var f tlsFile
+	b, err := os.ReadFile(t.path)
+	if err != nil {
+		return f, err
+	}
+	err = json.Unmarshal(b, &f)
+	return f, err
+}
+
+func (t *tlsFileInspector) ChannelTLS(ctx context.Context, replica string) ([]mysql.ChannelTLS, error) {
+	f, err := t.load()
+	return f.Channels, err
+}
+
+func (t *tlsFileInspector) ServerTLS(ctx context.Context, host string) (mysql.ServerTLS, error) {
+	f, err := t.load()
+	return f.Server, err
+}
+
+// securityFileInspector reads mysql.SecuritySettings from a JSON file.
+type securityFileInspector struct {
+	path string
+}
+
+func (s *securityFileInspector) SecuritySettings(ctx context.Context, host string) (mysql.SecuritySettings, error) {
+	var settings mysql.SecuritySettings
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		return settings, err
+	}
+	err = json.Unmarshal(b, &settings)
+	return settings, err
+}
+
+// identityFileInspector reads {"<host>": {"ServerID": ..., "ServerUUID": ...}}
+// from a JSON file.
+type identityFileInspector struct {
+	path string
+}
+
+func (i *identityFileInspector) ServerIdentity(ctx context.Context, host string) (mysql.ServerIdentity, error) {
+	identities := map[string]mysql.ServerIdentity{}
+	b, err := os.ReadFile(i.path)
+	if err != nil {
+		return mysql.ServerIdentity{}, err
+	}
+	if err := json.Unmarshal(b, &identities); err != nil {
+		return mysql.ServerIdentity{}, err
+	}
+	identity, ok := identities[host]
+	if !ok {
+		return mysql.ServerIdentity{}, fmt.Errorf("%s has no identity for %s", i.path, host)
+	}
+	return identity, nil
+}
+
+// autoIncrementFileInspector reads {"<host>": {"<table>": <next value>}}
+// from a JSON file.
+type autoIncrementFileInspector struct {
+	path string
+}
+
+func (a *autoIncrementFileInspector) AutoIncrements(ctx context.Context, host string) (map[string]uint64, error) {
+	counters := map[string]map[string]uint64{}
+	b, err := os.ReadFile(a.path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &counters); err != nil {
+		return nil, err
+	}
+	hostCounters, ok := counters[host]
+	if !ok {
+		return nil, fmt.Errorf("%s has no AUTO_INCREMENT counters for %s", a.path, host)
+	}
+	return hostCounters, nil
+}
+
+// fulltextFileInspector reads {"<host>": {"Variables": {...}, "Parsers":
+// [...]}} from a JSON file.
+type fulltextFileInspector struct {
+	path string
+}
+
+func (f *fulltextFileInspector) FulltextSettings(ctx context.Context, host string) (mysql.FulltextSettings, error) {
+	settings := map[string]mysql.FulltextSettings{}
+	b, err := os.ReadFile(f.path)
+	if err != nil {
+		return mysql.FulltextSettings{}, err
+	}
+	if err := json.Unmarshal(b, &settings); err != nil {
+		return mysql.FulltextSettings{}, err
+	}
+	hostSettings, ok := settings[host]
+	if !ok {
+		return mysql.FulltextSettings{}, fmt.Errorf("%s has no full-text settings for %s", f.path, host)
+	}
+	return hostSettings, nil
+}
+
+// collationsFileInspector reads {"<host>": {"ServerCharset": ...,
+// "ServerCollation": ..., "Databases": {"<database>": "<collation>"}}} from a
+// JSON file.
+type collationsFileInspector struct {
+	path string
+}
+
+func (f *collationsFileInspector) CollationDefaults(ctx context.Context, host string) (mysql.CollationDefaults, error) {
+	defaults := map[string]mysql.CollationDefaults{}
+	b, err := os.ReadFile(f.path)
+	if err != nil {
+		return mysql.CollationDefaults{}, err
+	}
+	if err := json.Unmarshal(b, &defaults); err != nil {
+		return mysql.CollationDefaults{}, err
+	}
+	hostDefaults, ok := defaults[host]
+	if !ok {
+		return mysql.CollationDefaults{}, fmt.Errorf("%s has no default collations for %s", f.path, host)
+	}
+	return hostDefaults, nil
+}
+
+// clockOffsetsFileInspector reads {"<host>": "<offset>"} from a JSON file,
+// each offset being how far the host's clock runs ahead of the operator's
+// (e.g. "1.5s" or "-300ms").
+type clockOffsetsFileInspector struct {
+	path string
+}
+
+func (c *clockOffsetsFileInspector) Clock(ctx context.Context, host string) (time.Time, error) {
+	offsets := map[string]string{}
+	b, err := os.ReadFile(c.path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if err := json.Unmarshal(b, &offsets); err != nil {
+		return time.Time{}, err
+	}
+	raw, ok := offsets[host]
+	if !ok {
+		return time.Time{}, fmt.Errorf("%s has no clock offset for %s", c.path, host)
+	}
+	offset, err := time.ParseDuration(raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s: invalid clock offset for %s: %w", c.path, host, err)
+	}
+	return time.Now().Add(offset), nil
+}
+
+// connectionsFileInspector reads {"<host>": [{"User": ..., "Host": ...,
+// "Program": ..., "Connections": ...}]} from a JSON file. Hosts missing from
+// the file are reported as unreadable.
+type connectionsFileInspector struct {
+	path string
+}
+
+func (c *connectionsFileInspector) ClientConnections(ctx context.Context, host string) ([]mysql.ClientConnection, error) {
+	inventory := map[string][]mysql.ClientConnection{}
+	b, err := os.ReadFile(c.path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &inventory); err != nil {
+		return nil, err
+	}
+	conns, ok := inventory[host]
+	if !ok {
+		return nil, fmt.Errorf("%s has no connections for %s", c.path, host)
+	}
+	return conns, nil
+}
+
+// upgradeStatusFileInspector reads {"<replica>": {"Version": ...,
+// "ServerUpgrade": ..., "Detail": ...}} from a JSON file.
+// versionRecorder records the version each replica reports in the run
+// manifest.
+type versionRecorder struct {
+	inner    mysql.UpgradeStatusInspector
+	manifest *runManifest
+}
+
+func (v *versionRecorder) UpgradeStatus(ctx context.Context, replica string) (mysql.UpgradeStatus, error) {
+	status, err := v.inner.UpgradeStatus(ctx, replica)
+	if err == nil && status.Version != "" {
+		v.manifest.recordVersion(replica, status.Version)
+	}
+	return status, err
+}
+
+type upgradeStatusFileInspector struct {
+	path string
+}
+
+func (u *upgradeStatusFileInspector) UpgradeStatus(ctx context.Context, replica string) (mysql.UpgradeStatus, error) {
+	statuses := map[string]mysql.UpgradeStatus{}
+	b, err := os.ReadFile(u.path)
+	if err != nil {
+		return mysql.UpgradeStatus{}, err
+	}
+	if err := json.Unmarshal(b, &statuses); err != nil {
+		return mysql.UpgradeStatus{}, err
+	}
+	status, ok := statuses[replica]
+	if !ok {
+		return mysql.UpgradeStatus{}, fmt.Errorf("%s has no upgrade status for %s", u.path, replica)
+	}
+	return status, nil
+}
+
+// proxysqlFileInspector reads mysql.ProxySQLConfig from a JSON file, e.g. an
+// export of ProxySQL's runtime_ admin tables.
+type proxysqlFileInspector struct {
+	path string
+}
+
+func (p *proxysqlFileInspector) ProxySQLConfig(ctx context.Context) (mysql.ProxySQLConfig, error) {
+	var cfg mysql.ProxySQLConfig
+	b, err := os.ReadFile(p.path)
+	if err != nil {
+		return cfg, err
+	}
+	err = json.Unmarshal(b, &cfg)
+	return cfg, err
+}
+
+// bufferPoolFileInspector replays a JSON list of mysql.BufferPoolStats
+// samples, one per call; the last sample repeats.
+type bufferPoolFileInspector struct {
+	path string
+
+	mu  sync.Mutex
+	pos int
+}
+
+func (b *bufferPoolFileInspector) BufferPoolStats(ctx context.Context, host string) (mysql.BufferPoolStats, error) {
+	var samples []mysql.BufferPoolStats
+	raw, err := os.ReadFile(b.path)
+	if err != nil {
+		return mysql.BufferPoolStats{}, err
+	}
+	if err := json.Unmarshal(raw, &samples); err != nil {
+		return mysql.BufferPoolStats{}, fmt.Errorf("%s: %v", b.path, err)
+	}
+	if len(samples) == 0 {
+		return mysql.BufferPoolStats{}, fmt.Errorf("%s: no buffer pool samples", b.path)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	i := b.pos
+	if i >= len(samples) {
+		i = len(samples) - 1
+	}
+	b.pos = i + 1
+	return samples[i], nil
+}
+
+// snapshotsFileInspector replays {"<host>": [<mysql.ServerSnapshot>, ...]}
+// from a JSON file, one snapshot per call and host; the last one repeats.
+type snapshotsFileInspector struct {
+	path string
+
+	mu  sync.Mutex
+	pos map[string]int
+}
+
+func (s *snapshotsFileInspector) ServerSnapshot(ctx context.Context, host string) (mysql.ServerSnapshot, error) {
+	snapshots := map[string][]mysql.ServerSnapshot{}
+	raw, err := os.ReadFile(s.path)
+	if err != nil {
+		return mysql.ServerSnapshot{}, err
+	}
+	if err := json.Unmarshal(raw, &snapshots); err != nil {
+		return mysql.ServerSnapshot{}, fmt.Errorf("%s: %v", s.path, err)
+	}
+	timeline := snapshots[host]
+	if len(timeline) == 0 {
+		return mysql.ServerSnapshot{}, fmt.Errorf("%s has no snapshots for %s", s.path, host)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pos == nil {
+		s.pos = map[string]int{}
+	}
+	i := s.pos[host]
+	if i >= len(timeline) {
+		i = len(timeline) - 1
+	}
+	s.pos[host] = i + 1
+	return timeline[i], nil
+}
+
+// retentionFileInspector reads mysql.BinlogRetention from a JSON file.
+type retentionFileInspector struct {
+	path string
+}
+
+func (r *retentionFileInspector) BinlogRetention(ctx context.Context, host string) (mysql.BinlogRetention, error) {
+	var retention mysql.BinlogRetention
+	b, err := os.ReadFile(r.path)
+	if err != nil {
+		return retention, err
+	}
+	err = json.Unmarshal(b, &retention)
+	return retention, err
+}
+
+// kafkaAccessFileInspector reads {"ACLs": [...], "Quotas": [...]} from a JSON
+// file, as listed for the connector's principal.
+type kafkaAccessFileInspector struct {
+	path string
+}
+
+func (k *kafkaAccessFileInspector) read() (acls []cdc.KafkaACL, quotas []cdc.KafkaQuota, err error) {
+	var access struct {
+		ACLs   []cdc.KafkaACL
+		Quotas []cdc.KafkaQuota
+	}
+	b, err := os.ReadFile(k.path)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = json.Unmarshal(b, &access)
+	return access.ACLs, access.Quotas, err
+}
+
+func (k *kafkaAccessFileInspector) ACLs(ctx context.Context, principal string) ([]cdc.KafkaACL, error) {
+	acls, _, err := k.read()
+	return acls, err
+}
+
+func (k *kafkaAccessFileInspector) Quotas(ctx context.Context, principal string) ([]cdc.KafkaQuota, error) {
+	_, quotas, err := k.read()
+	return quotas, err
+}
+
+// pluginsFileInspector reads a saved Kafka Connect GET /connector-plugins response.
+type pluginsFileInspector struct {
+	path string
+}
+
+func (p *pluginsFileInspector) ConnectorPlugins(ctx context.Context) ([]cdc.ConnectorPlugin, error) {
+	var plugins []cdc.ConnectorPlugin
+	b, err := os.ReadFile(p.path)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(b, &plugins)
+	return plugins, err
+}
+
+type staticReplicaInspector struct {
+	isPrimary bool
+	status    mysql.ReplicationStatus
+}
+
+func (s *staticReplicaInspector) IsPrimary(ctx context.Context, host string) (bool, error) {
+	return s.isPrimary, nil
+}
+
+func (s *staticReplicaInspector) ReplicationStatus(ctx context.Context, replica string) (mysql.ReplicationStatus, error) {
+	return s.status, nil
+}
+
+// resume reports both replication threads running from now on.
+func (s *staticReplicaInspector) resume() {
+	s.status.IOThreadRunning = true
+	s.status.SQLThreadRunning = true
+}
+
+type notConfiguredActions struct{}
+
+func (n *notConfiguredActions) StopReplication(ctx context.Context, replica string) error {
+	return fmt.Errorf("replica actions not configured; use --simulate or provide implementation")
+}
+
+func (n *notConfiguredActions) RunUpgrade(ctx context.Context, replica string) error {
+	return fmt.Errorf("replica actions not configured; use --simulate or provide implementation")
+}
+
+func (n *notConfiguredActions) StartReplication(ctx context.Context, replica string) error {
+	return fmt.Errorf("replica actions not configured; use --simulate or provide implementation")
+}
+
+func (n *notConfiguredActions) FreezeWrites(ctx context.Context, primary string) error {
+	return fmt.Errorf("promotion actions not configured; use --simulate or provide implementation")
+}
+
+func (n *notConfiguredActions) CaughtUp(ctx context.Context, primary string, replica string) (bool, error) {
+	return false, fmt.Errorf("promotion actions not configured; use --simulate or provide implementation")
+}
+
+func (n *notConfiguredActions) SwitchPrimary(ctx context.Context, primary string, replica string) error {
+	return fmt.Errorf("promotion actions not configured; use --simulate or provide implementation")
+}
+
+func (n *notConfiguredActions) UnfreezeWrites(ctx context.Context, primary string) error {
+	return fmt.Errorf("promotion actions not configured; use --simulate or provide implementation")
+}
+
+// simulatedActions succeeds without touching MySQL. onStart, when set, is
+// called by StartReplication so a static inspector can report the resumed
+// threads. actions, when set, records each change it stands in for.
+type simulatedActions struct {
+	onStart func()
+	actions *workflow.ActionLog
+}
+
+func (s *simulatedActions) StopReplication(ctx context.Context, replica string) error {
+	s.actions.Record("stop_replication " + replica)
+	return nil
+}
+
+func (s *simulatedActions) RunUpgrade(ctx context.Context, replica string) error {
+	s.actions.Record("run_upgrade " + replica)
+	return nil
+}
+
+func (s *simulatedActions) StartReplication(ctx context.Context, replica string) error {
+	s.actions.Record("start_replication " + replica)
+	if s.onStart != nil {
+		s.onStart()
+	}
+	return nil
+}
+
+func (s *simulatedActions) FreezeWrites(ctx context.Context, primary string) error {
+	s.actions.Record("freeze_writes " + primary)
+	return nil
+}
+
+func (s *simulatedActions) CaughtUp(ctx context.Context, primary string, replica string) (bool, error) {
+	return true, nil
+}
+
+func (s *simulatedActions) SwitchPrimary(ctx context.Context, primary string, replica string) error {
+	s.actions.Record("switch_primary " + primary + " -> " + replica)
+	return nil
+}
+
+func (s *simulatedActions) UnfreezeWrites(ctx context.Context, primary string) error {
+	s.actions.Record("unfreeze_writes " + primary)
+	return nil
+}
+
+type simulatedDrainer struct {
+	name    string
+	actions *workflow.ActionLog
+}
+
+func (s *simulatedDrainer) Name() string { return s.name }
+
+func (s *simulatedDrainer) Drain(ctx context.Context, replica string) error {
+	s.actions.Record("drain " + s.name + " " + replica)
+	return nil
+}
+
+func (s *simulatedDrainer) Undrain(ctx context.Context, replica string) error {
+	s.actions.Record("undrain " + s.name + " " + replica)
+	return nil
+}
+
+// simulatedDDLGuard stands in for the lock table guard under --simulate.
+type simulatedDDLGuard struct{}
+
+func (s *simulatedDDLGuard) Name() string { return "lock_table" }
+
+func (s *simulatedDDLGuard) Acquire(ctx context.Context, primary string, owner string) error {
+	return nil
+}
+
+func (s *simulatedDDLGuard) Release(ctx context.Context, primary string, owner string) error {
+	return nil
+}
+
+// simulatedExpirySetter stands in for the binlog expiry setter under
+// --simulate.
+type simulatedExpirySetter struct{}
+
+func (s *simulatedExpirySetter) SetBinlogExpireSeconds(ctx context.Context, host string, seconds int64) error {
+	return nil
+}
@@ -2,431 +2,1647 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
-	"errors"
 	"flag"
 	"fmt"
-	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 
+	"migratorx/internal/access"
 	"migratorx/internal/cdc"
 	"migratorx/internal/checks"
+	"migratorx/internal/failure"
 	"migratorx/internal/mysql"
 	"migratorx/internal/state"
 	"migratorx/internal/workflow"
+
+	// Registers the "mysql" database/sql driver --schema-dsn connects with.
+	_ "github.com/go-sql-driver/mysql"
 )
 
-type Output struct {
-	Summary  Summary         `json:"summary"`
-	Findings []OutputFinding `json:"findings"`
+// main cancels the command's context on SIGINT or SIGTERM (Ctrl+C or Ctrl+Break
+// on Windows) so it can stop between steps and still report. Once the first
+// signal arrives the default handling is restored, so a second one exits
+// immediately.
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	code := execute(ctx, rootCommand(), os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
 }
 
-type Summary struct {
-	Info  int `json:"info"`
-	Warn  int `json:"warn"`
-	Block int `json:"block"`
+// rootCommand defines the CLI command tree.
+func rootCommand() *command {
+	return &command{
+		Name:    "migratorx",
+		Summary: "Safety-first orchestration for MySQL major version upgrades.",
+		Subcommands: []*command{
+			{Name: "plan", Summary: "Validate a migration plan", Setup: setupPlan},
+			{Name: "doctor", Summary: "Check connectivity, state, credentials and clocks before preflight", Setup: setupDoctor},
+			{Name: "deps", Summary: "Review what migratorx connects to", Subcommands: []*command{
+				{Name: "report", Summary: "List every external endpoint and local command a plan makes migratorx contact", Setup: setupDepsReport},
+			}},
+			{Name: "preflight", Summary: "Run preflight checks against the plan topology", Setup: setupPreflight},
+			{Name: "trend", Summary: "Show whether preflight risk is shrinking or growing across runs", Setup: setupTrend},
+			{Name: "upgrade", Summary: "Run upgrade workflows", Subcommands: []*command{
+				{Name: "replica", Summary: "Upgrade a single replica", Args: []string{"name"}, Role: access.RoleOperator, Setup: setupUpgradeReplica},
+				{Name: "replicas", Summary: "Upgrade every plan replica, pausing while the topology is degraded", Role: access.RoleOperator, Setup: setupUpgradeReplicas},
+				{Name: "statistics", Summary: "Rebuild optimizer statistics and histograms on an upgraded replica", Args: []string{"name"}, Role: access.RoleOperator, Setup: setupUpgradeStatistics},
+				{Name: "undrain", Summary: "Return a validated replica to read traffic", Args: []string{"name"}, Role: access.RoleOperator, Setup: setupUpgradeUndrain},
+			}},
+			{Name: "validate", Summary: "Validate schema parity", Subcommands: []*command{
+				{Name: "replica", Summary: "Validate a replica against the primary", Args: []string{"name"}, Setup: setupValidateReplica},
+				{Name: "primary", Summary: "Validate the primary after promotion", Setup: setupValidatePrimary},
+			}},
+			{Name: "cdc", Summary: "Inspect CDC pipelines", Subcommands: []*command{
+				{Name: "check", Summary: "Check Debezium connector health", Setup: setupCDCCheck},
+				{Name: "restart", Summary: "Restart the connector or one task and wait until it is RUNNING", Role: access.RoleOperator, Setup: setupCDCRestart},
+			}},
+			{Name: "fleet", Summary: "Summarize readiness across clusters", Subcommands: []*command{
+				{Name: "report", Summary: "Aggregate preflight manifests into a per-cluster readiness matrix", Setup: setupFleetReport},
+			}},
+			{Name: "config", Summary: "Work with server configuration", Subcommands: []*command{
+				{Name: "render", Summary: "Render a cleaned option file for the target version", Setup: setupConfigRender},
+			}},
+			{Name: "state", Summary: "Manage the --state file", Subcommands: []*command{
+				{Name: "gc", Summary: "Archive and remove checkpoints of completed runs", Role: access.RoleOperator, Setup: setupStateGC},
+			}},
+			{Name: "simulate", Summary: "Rehearse the whole plan against recorded fixtures", Setup: setupSimulate},
+			{Name: "promote", Summary: "Promote the validated replica in two phases", Subcommands: []*command{
+				{Name: "prepare", Summary: "Run the gate, freeze writes and print the cutover plan", Role: access.RoleApprover, Setup: setupPromotePrepare},
+				{Name: "execute", Summary: "Switch the primary after a reviewed prepare", Role: access.RoleApprover, Setup: setupPromoteExecute},
+			}},
+		},
+	}
 }
 
-type OutputFinding struct {
-	Severity string                 `json:"severity"`
-	Message  string                 `json:"message"`
-	Meta     map[string]interface{} `json:"meta,omitempty"`
+// inputFlags are the file-backed inspector inputs shared by inspection commands.
+type inputFlags struct {
+	PrimarySchema     string
+	ReplicaSchema     string
+	SchemaDSN         string
+	AdminDSN          string
+	ProxySQLAdminDSN  string
+	SchemaDatabase    string
+	AutoIncrements    string
+	Fulltext          string
+	Collations        string
+	CDCStatus         string
+	CDCPlugins        string
+	KafkaAccess       string
+	CDCOffsets        string
+	ReplicationStatus string
+	ReplicationTLS    string
+	ServerIdentity    string
+	BinlogRetention   string
+	ClockOffsets      string
+	Security          string
+	Connections       string
+	ProxySQL          string
+	BufferPool        string
+	Datadir           string
+	OptionFile        string
 }
 
-func main() {
-	if len(os.Args) < 2 {
-		printUsageAndExit()
-	}
-
-	cmd := os.Args[1]
-	switch cmd {
-	case "plan":
-		handlePlan(os.Args[2:])
-	case "preflight":
-		handlePreflight(os.Args[2:])
-	case "upgrade":
-		handleUpgrade(os.Args[2:])
-	case "validate":
-		handleValidate(os.Args[2:])
-	case "cdc":
-		handleCDC(os.Args[2:])
-	case "promote":
-		handlePromote(os.Args[2:])
-	default:
-		printUsageAndExit()
-	}
+func (in *inputFlags) registerSchema(fs *flag.FlagSet) {
+	fs.StringVar(&in.PrimarySchema, "schema-primary", "", "path to primary schema JSON")
+	fs.StringVar(&in.ReplicaSchema, "schema-replica", "", "path to replica schema JSON")
+	fs.StringVar(&in.SchemaDSN, "schema-dsn", "", "read both schemas live from information_schema with this MySQL DSN, {host} standing for each host (instead of --schema-primary/--schema-replica)")
+	fs.StringVar(&in.SchemaDatabase, "schema-database", "", "with --schema-dsn, compare only this database")
+	fs.StringVar(&in.AutoIncrements, "auto-increments", "", "path to per-host AUTO_INCREMENT counters JSON (read live with --schema-dsn otherwise)")
+	fs.StringVar(&in.Fulltext, "fulltext", "", "path to per-host full-text variables and parser plugins JSON (read live with --schema-dsn otherwise)")
+	fs.StringVar(&in.Collations, "collations", "", "path to per-host server and database default collations JSON (read live with --schema-dsn otherwise)")
 }
 
-func handlePlan(args []string) {
-	fs := flag.NewFlagSet("plan", flag.ExitOnError)
-	planPath := fs.String("plan", "migration.yaml", "path to migration plan YAML")
-	_ = fs.Parse(args)
+func (in *inputFlags) registerAdmin(fs *flag.FlagSet) {
+	fs.StringVar(&in.AdminDSN, "admin-dsn", "", "MySQL DSN, {host} standing for each host, for steps that write (the post_validation.endpoint heartbeat probe, the ddl_freeze lock table, binlog_retention.extend, upgrade statistics, warmup.enabled)")
+}
 
-	plan, err := workflow.LoadPlan(*planPath)
-	if err != nil {
-		writeOutput(Output{Summary: Summary{Block: 1}, Findings: []OutputFinding{{Severity: "BLOCK", Message: err.Error()}}})
-		return
-	}
-	writeOutput(Output{Summary: Summary{Info: 1}, Findings: []OutputFinding{{Severity: "INFO", Message: fmt.Sprintf("plan %q is valid", plan.Migration)}}})
+func (in *inputFlags) registerDrain(fs *flag.FlagSet) {
+	fs.StringVar(&in.ProxySQLAdminDSN, "proxysql-admin-dsn", "", "ProxySQL admin interface DSN, {host} standing for each proxysql drain address, e.g. admin:secret@tcp({host})/?interpolateParams=true")
+}
+
+func (in *inputFlags) registerCDC(fs *flag.FlagSet) {
+	fs.StringVar(&in.CDCStatus, "cdc-status", "", "path to Debezium status JSON")
+	fs.StringVar(&in.CDCPlugins, "cdc-plugins", "", "path to Kafka Connect GET /connector-plugins JSON")
+	fs.StringVar(&in.KafkaAccess, "kafka-access", "", "path to the connector principal's Kafka ACLs and quotas JSON")
+}
+
+func (in *inputFlags) registerReplication(fs *flag.FlagSet) {
+	fs.StringVar(&in.ReplicationStatus, "replication-status", "", "path to replica replication status timeline JSON")
+	fs.StringVar(&in.ReplicationTLS, "replication-tls", "", "path to replica channel and server TLS settings JSON")
+	fs.StringVar(&in.ServerIdentity, "server-identity", "", "path to per-host server_id and server_uuid JSON")
+	in.registerBinlogRetention(fs)
+	fs.StringVar(&in.ClockOffsets, "clock-offsets", "", "path to per-host clock offsets from the operator host JSON (primary, replicas and cdc.connect_workers)")
+}
+
+func (in *inputFlags) registerBinlogRetention(fs *flag.FlagSet) {
+	fs.StringVar(&in.BinlogRetention, "binlog-retention", "", "path to the primary's binlog expiry, oldest binlog time and gtid_purged JSON")
+}
+
+func (in *inputFlags) registerSecurity(fs *flag.FlagSet) {
+	fs.StringVar(&in.Security, "security-settings", "", "path to the replica's TLS and authentication settings JSON")
+	fs.StringVar(&in.Connections, "client-connections", "", "path to per-host connected client (user, host, program) JSON")
+}
+
+func (in *inputFlags) registerRouting(fs *flag.FlagSet) {
+	fs.StringVar(&in.ProxySQL, "proxysql-config", "", "path to ProxySQL runtime servers, query rules, replication hostgroups and users JSON")
+}
+
+func (in *inputFlags) registerWarmup(fs *flag.FlagSet) {
+	fs.StringVar(&in.BufferPool, "buffer-pool-stats", "", "path to the replica's buffer pool status samples JSON, taken warmup.sample_interval apart")
 }
 
-func handlePreflight(args []string) {
-	fs := flag.NewFlagSet("preflight", flag.ExitOnError)
-	planPath := fs.String("plan", "migration.yaml", "path to migration plan YAML")
-	primarySchema := fs.String("schema-primary", "", "path to primary schema JSON")
-	replicaSchema := fs.String("schema-replica", "", "path to replica schema JSON")
-	cdcStatus := fs.String("cdc-status", "", "path to Debezium status JSON")
-	_ = fs.Parse(args)
+func (in *inputFlags) registerDatadir(fs *flag.FlagSet) {
+	fs.StringVar(&in.Datadir, "datadir", "", "replica datadir to scan when migratorx runs on the replica host")
+	fs.StringVar(&in.OptionFile, "option-file", "", "replica option file (my.cnf) to audit for removed variables, following !include directives")
+}
+
+func (in *inputFlags) registerOffsets(fs *flag.FlagSet) {
+	fs.StringVar(&in.CDCOffsets, "cdc-offsets", "", "path to connector offsets and primary binlog coordinates JSON")
+}
 
-	plan, err := workflow.LoadPlan(*planPath)
+// filterFlags narrow which checks run and which findings are printed.
+type filterFlags struct {
+	MinSeverity string
+	Only        stringList
+	Skip        stringList
+}
+
+func (f *filterFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.MinSeverity, "min-severity", "info", "only print findings at or above this severity: info, warn, block")
+	fs.Var(&f.Only, "only-check", "run only the named check (repeatable)")
+	fs.Var(&f.Skip, "skip-check", "skip the named check (repeatable)")
+}
+
+// apply filters checks and returns a function that filters output findings.
+func (f *filterFlags) apply(checksList []checks.PreflightCheck) ([]checks.PreflightCheck, func(Output) Output, error) {
+	min, err := checks.ParseSeverity(f.MinSeverity)
 	if err != nil {
-		writeOutput(Output{Summary: Summary{Block: 1}, Findings: []OutputFinding{{Severity: "BLOCK", Message: err.Error()}}})
-		return
+		return nil, nil, err
 	}
+	filtered, err := checks.FilterChecks(checksList, f.Only, f.Skip)
+	if err != nil {
+		return nil, nil, err
+	}
+	return filtered, func(output Output) Output { return filterOutput(output, min) }, nil
+}
 
-	replicaHost, repErr := selectReplica(plan)
-	if repErr != nil {
-		writeOutput(Output{Summary: Summary{Block: 1}, Findings: []OutputFinding{{Severity: "BLOCK", Message: repErr.Error()}}})
-		return
+func setupPlan(fs *flag.FlagSet) runFunc {
+	return func(ctx context.Context, env *env, args []string) Output {
+		plan, err := env.loadPlan()
+		if err != nil {
+			return blockOutput(err)
+		}
+		return Output{Summary: Summary{Info: 1}, Findings: []OutputFinding{{Severity: "INFO", Message: fmt.Sprintf("plan %q is valid", plan.Migration)}}}
 	}
-	checksList := buildChecks(*primarySchema, *replicaSchema, *cdcStatus, plan.Topology.Primary, replicaHost, plan)
-	runner := checks.NewRunner(checksList, log.Default())
-	summary, results, err := runner.Run(context.Background(), planInput(plan, replicaHost))
-	if err != nil {
-		writeOutput(Output{Summary: Summary{Block: 1}, Findings: []OutputFinding{{Severity: "BLOCK", Message: err.Error()}}})
-		return
+}
+
+func setupPreflight(fs *flag.FlagSet) runFunc {
+	in := &inputFlags{}
+	in.registerSchema(fs)
+	in.registerCDC(fs)
+	in.registerReplication(fs)
+	in.registerSecurity(fs)
+	in.registerRouting(fs)
+	in.registerWarmup(fs)
+	in.registerDatadir(fs)
+	filters := &filterFlags{}
+	filters.register(fs)
+	trend := fs.Bool("trend", false, "append this run's finding counts to the trend in --state")
+	artifacts := fs.Bool("artifacts", false, "store check artifacts (schema dumps and other evidence) next to --state")
+	failFast := fs.Bool("fail-fast", false, "stop at the first check that reports a BLOCK instead of running every check")
+	strict := fs.Bool("strict", false, "treat a check whose input file was not provided as a check error (BLOCK) instead of skipping it with a WARN")
+	return func(ctx context.Context, env *env, args []string) Output {
+		plan, err := env.loadPlan()
+		if err != nil {
+			return blockOutput(err)
+		}
+		replicaHost, err := selectReplica(plan)
+		if err != nil {
+			return blockOutput(err)
+		}
+		checksList := buildChecks(env.Recorder, *in, plan.Topology.Primary, replicaHost, plan)
+		if !*strict {
+			checksList = skipMissingInputs(checksList, *in)
+		}
+		checksList, filterFindings, err := filters.apply(checksList)
+		if err != nil {
+			return blockOutput(err)
+		}
+		runner := checks.NewRunner(checksList, env.Logger)
+		runner.FailFast = *failFast
+		if *artifacts {
+			runner.Artifacts = &state.DirArtifactStore{Dir: state.ArtifactDir(env.Globals.StatePath, plan.StateName(), env.Globals.RunID)}
+		}
+		summary, results, err := runner.Run(ctx, planInput(plan, replicaHost))
+		env.Manifest.recordChecks(checksList)
+		if err != nil {
+			return blockOutput(err)
+		}
+		env.Manifest.recordResults(results)
+		if *trend {
+			st, err := env.fileState(env.Globals.StatePath)
+			if err != nil {
+				return blockOutput(err)
+			}
+			state.AppendTrend(st, plan.StateName(), workflow.NewTrendPoint(env.Globals.RunID, env.PlanHash, time.Now().UTC().Format(time.RFC3339), results))
+		}
+		return filterFindings(convertCheckResults(summary, results))
 	}
-	writeOutput(convertCheckResults(summary, results))
 }
 
-func handleUpgrade(args []string) {
-	if len(args) < 2 || args[0] != "replica" {
-		printUsageAndExit()
+// setupTrend reports the preflight trend recorded with preflight --trend.
+func setupTrend(fs *flag.FlagSet) runFunc {
+	last := fs.Int("last", 0, "only consider the newest N recorded runs (0 for all)")
+	return func(ctx context.Context, env *env, args []string) Output {
+		plan, err := env.loadPlan()
+		if err != nil {
+			return blockOutput(err)
+		}
+		st, err := env.fileState(env.Globals.StatePath)
+		if err != nil {
+			return blockOutput(err)
+		}
+		points := state.Trend(st, plan.StateName())
+		if *last > 0 && len(points) > *last {
+			points = points[len(points)-*last:]
+		}
+		env.Manifest.recordCheck("findings_trend", map[string]interface{}{"runs": len(points), "last": *last})
+		return convertCheckFindings(workflow.AnalyzeTrend(points))
 	}
-	fs := flag.NewFlagSet("upgrade replica", flag.ExitOnError)
-	planPath := fs.String("plan", "migration.yaml", "path to migration plan YAML")
-	statePath := fs.String("state", defaultStatePath(), "path to state file")
+}
+
+// setupStateGC archives the checkpoints of the plan's runs that validate
+// primary marked complete, so a later migration reusing the run ID starts
+// clean.
+func setupStateGC(fs *flag.FlagSet) runFunc {
+	olderThan := fs.Duration("older-than", 30*24*time.Hour, "only collect runs completed at least this long ago")
+	archivePath := fs.String("archive", "", "state file receiving the removed checkpoints (default <state>.archive.json)")
+	dryRun := fs.Bool("dry-run", false, "list the runs that would be collected without changing the state file")
+	return func(ctx context.Context, env *env, args []string) Output {
+		plan, err := env.loadPlan()
+		if err != nil {
+			return blockOutput(err)
+		}
+		st, err := env.fileState(env.Globals.StatePath)
+		if err != nil {
+			return blockOutput(err)
+		}
+		if *archivePath == "" {
+			*archivePath = env.Globals.StatePath + ".archive.json"
+		}
+		var runs []state.RunInfo
+		if *dryRun {
+			runs = state.Collect(st, plan.StateName(), *olderThan, time.Now().UTC())
+		} else {
+			archive, err := env.fileState(*archivePath)
+			if err != nil {
+				return blockOutput(err)
+			}
+			runs = state.GC(st, archive, plan.StateName(), *olderThan, time.Now().UTC())
+		}
+		env.Manifest.recordCheck("state_gc", map[string]interface{}{"older_than": olderThan.String(), "archive": *archivePath, "dry_run": *dryRun, "runs": len(runs)})
+
+		output := Output{Findings: []OutputFinding{}}
+		for _, run := range runs {
+			if run.Err != nil {
+				output.Findings = append(output.Findings, OutputFinding{
+					Severity: "BLOCK",
+					Code:     codeStateArchiveFailed,
+					Message:  fmt.Sprintf("%v; its checkpoints were kept in %s", run.Err, env.Globals.StatePath),
+					Meta:     map[string]interface{}{"migration": run.Migration, "run_id": run.RunID, "completed_at": run.CompletedAt, "keys": len(run.Keys), "archive": *archivePath},
+				})
+				continue
+			}
+			message := fmt.Sprintf("archived run %q completed at %s (%d keys)", run.RunID, run.CompletedAt, len(run.Keys))
+			if *dryRun {
+				message = fmt.Sprintf("would archive run %q completed at %s (%d keys)", run.RunID, run.CompletedAt, len(run.Keys))
+			}
+			output.Findings = append(output.Findings, OutputFinding{
+				Severity: "INFO",
+				Code:     codeStateRunArchived,
+				Message:  message,
+				Meta:     map[string]interface{}{"migration": run.Migration, "run_id": run.RunID, "completed_at": run.CompletedAt, "keys": len(run.Keys), "archive": *archivePath, "dry_run": *dryRun},
+			})
+		}
+		if len(runs) == 0 {
+			output.Findings = append(output.Findings, OutputFinding{
+				Severity: "INFO",
+				Code:     codeStateNothingToGC,
+				Message:  fmt.Sprintf("no runs of %q completed more than %s ago", plan.StateName(), olderThan.String()),
+				Meta:     map[string]interface{}{"migration": plan.StateName(), "older_than": olderThan.String()},
+			})
+		}
+		return prependFindings(Output{Findings: []OutputFinding{}}, output.Findings)
+	}
+}
+
+func setupUpgradeReplica(fs *flag.FlagSet) runFunc {
 	simulate := fs.Bool("simulate", false, "simulate actions without touching MySQL")
 	ioRunning := fs.Bool("io-running", true, "replica IO thread running")
 	sqlRunning := fs.Bool("sql-running", true, "replica SQL thread running")
-	_ = fs.Parse(args[2:])
-	replica := args[1]
+	upgradeStatus := fs.String("upgrade-status", "", "JSON file of per-replica upgrade status, verified before the upgrade is checkpointed")
+	resumeTimeout := fs.Duration("resume-timeout", mysql.DefaultResumeTimeout, "how long to wait for replication to run and catch up after it is started")
+	reconcile := fs.Bool("reconcile", false, "adjust checkpoints that disagree with the observed replication status instead of warning")
+	snapshots := fs.String("server-snapshots", "", "JSON file of per-replica variable and schema object snapshots taken before and after the upgrade")
+	in := &inputFlags{}
+	in.registerSchema(fs)
+	in.registerAdmin(fs)
+	in.registerBinlogRetention(fs)
+	in.registerDrain(fs)
+	return func(ctx context.Context, env *env, args []string) Output {
+		replica := args[0]
+		plan, err := env.loadPlan()
+		if err != nil {
+			return blockOutput(err)
+		}
+		st, stateFindings, err := env.openState(plan)
+		if err != nil {
+			return blockOutput(err)
+		}
+		if hasBlockFinding(stateFindings) {
+			return prependFindings(Output{}, stateFindings)
+		}
 
-	plan, err := workflow.LoadPlan(*planPath)
-	if err != nil {
-		writeOutput(Output{Summary: Summary{Block: 1}, Findings: []OutputFinding{{Severity: "BLOCK", Message: err.Error()}}})
-		return
-	}
+		static := &staticReplicaInspector{isPrimary: replica == plan.Topology.Primary, status: mysql.ReplicationStatus{IOThreadRunning: *ioRunning, SQLThreadRunning: *sqlRunning}}
+		inspector := env.Recorder.replicaInspector(static)
+		actions := mysql.ReplicaActions(&notConfiguredActions{})
+		if *simulate {
+			actions = &simulatedActions{onStart: static.resume}
+		}
 
-	st, err := state.NewFileState(*statePath)
-	if err != nil {
-		writeOutput(Output{Summary: Summary{Block: 1}, Findings: []OutputFinding{{Severity: "BLOCK", Message: err.Error()}}})
-		return
+		orchestrator := mysql.NewUpgradeOrchestrator(inspector, actions, st, plan.Topology.Primary, env.Logger)
+		if orchestrator.Drainers, err = buildDrainers(plan, *in, st, *simulate); err != nil {
+			return blockOutput(err)
+		}
+		orchestrator.ResumeTimeout = *resumeTimeout
+		orchestrator.Reconcile = *reconcile
+		orchestrator.ResumeMaxLag = plan.LagLimit(replica)
+		orchestrator.Capacity = buildReadCapacity(plan)
+		orchestrator.Pool = plan.Topology.Replicas
+		setUpgradeVerifier(env, orchestrator, plan, *upgradeStatus)
+		if *snapshots != "" {
+			orchestrator.Snapshots = &snapshotsFileInspector{path: *snapshots}
+		}
+		if !orchestrator.Started(replica) {
+			calendarFindings, hold := env.holdForTraffic(ctx, plan)
+			stateFindings = append(stateFindings, calendarFindings...)
+			if hold {
+				return prependFindings(Output{}, stateFindings)
+			}
+		}
+		if plan.DDLFreeze.Enabled {
+			frozen := freezeDDL(ctx, env, *in, plan, st, replica, *simulate)
+			stateFindings = append(stateFindings, frozen.Findings...)
+			if frozen.Summary.Block > 0 {
+				return prependFindings(Output{}, stateFindings)
+			}
+		}
+		if plan.BinlogRetention.Extend {
+			extended := extendBinlogRetention(ctx, env, *in, plan, st, *simulate)
+			stateFindings = append(stateFindings, extended.Findings...)
+			if extended.Summary.Block > 0 {
+				return prependFindings(Output{}, stateFindings)
+			}
+		}
+		summary, findings, err := orchestrator.Run(ctx, replica)
+		env.Manifest.recordCheck("replica_upgrade", map[string]interface{}{"replica": replica, "simulate": *simulate, "upgrade_status": *upgradeStatus, "reconcile": *reconcile, "server_snapshots": *snapshots})
+		if err != nil {
+			return blockOutput(err)
+		}
+		return prependFindings(convertMySQLFindings(summary, findings), stateFindings)
 	}
+}
 
-	inspector := &staticReplicaInspector{isPrimary: replica == plan.Topology.Primary, status: mysql.ReplicationStatus{IOThreadRunning: *ioRunning, SQLThreadRunning: *sqlRunning}}
-	actions := mysql.ReplicaActions(&notConfiguredActions{})
-	if *simulate {
-		actions = &simulatedActions{}
-	}
+// setupUpgradeReplicas upgrades the plan's replicas in order, up to
+// --concurrency at a time, and holds further upgrades while remaining replicas
+// lag beyond thresholds.max_lag or fewer than thresholds.min_serving_replicas
+// would keep serving reads, or their read_pool weights fall below
+// read_pool.min_capacity during peak hours.
+func setupUpgradeReplicas(fs *flag.FlagSet) runFunc {
+	simulate := fs.Bool("simulate", false, "simulate actions without touching MySQL")
+	concurrency := fs.Int("concurrency", 1, "maximum number of replicas upgraded at once")
+	maxPause := fs.Duration("max-pause", 30*time.Minute, "block when upgrades stay paused this long (0 waits indefinitely)")
+	statusDir := fs.String("replication-status-dir", "", "directory of <replica>.json replication status timelines watched between upgrades")
+	upgradeStatus := fs.String("upgrade-status", "", "JSON file of per-replica upgrade status, verified before each upgrade is checkpointed")
+	resumeTimeout := fs.Duration("resume-timeout", mysql.DefaultResumeTimeout, "how long to wait for each replica's replication to run and catch up after it is started")
+	reconcile := fs.Bool("reconcile", false, "adjust checkpoints that disagree with each replica's observed replication status instead of warning")
+	snapshots := fs.String("server-snapshots", "", "JSON file of per-replica variable and schema object snapshots taken before and after each upgrade")
+	in := &inputFlags{}
+	in.registerSchema(fs)
+	in.registerAdmin(fs)
+	in.registerBinlogRetention(fs)
+	in.registerDrain(fs)
+	return func(ctx context.Context, env *env, args []string) Output {
+		plan, err := env.loadPlan()
+		if err != nil {
+			return blockOutput(err)
+		}
+		if *concurrency < 1 {
+			return blockOutput(failure.Config("--concurrency must be at least 1"))
+		}
+		st, stateFindings, err := env.openState(plan)
+		if err != nil {
+			return blockOutput(err)
+		}
+		if hasBlockFinding(stateFindings) {
+			return prependFindings(Output{}, stateFindings)
+		}
 
-	orchestrator := mysql.NewUpgradeOrchestrator(inspector, actions, st, plan.Topology.Primary, log.Default())
-	summary, findings, err := orchestrator.Run(context.Background(), replica)
-	if err != nil {
-		writeOutput(Output{Summary: Summary{Block: 1}, Findings: []OutputFinding{{Severity: "BLOCK", Message: err.Error()}}})
-		return
+		inspector := env.Recorder.replicaInspector(&staticReplicaInspector{status: mysql.ReplicationStatus{IOThreadRunning: true, SQLThreadRunning: true}})
+		actions := mysql.ReplicaActions(&notConfiguredActions{})
+		if *simulate {
+			actions = &simulatedActions{}
+		}
+		orchestrator := mysql.NewUpgradeOrchestrator(inspector, actions, st, plan.Topology.Primary, env.Logger)
+		if orchestrator.Drainers, err = buildDrainers(plan, *in, st, *simulate); err != nil {
+			return blockOutput(err)
+		}
+		orchestrator.ResumeTimeout = *resumeTimeout
+		orchestrator.Reconcile = *reconcile
+		orchestrator.ResumeMaxLag = plan.Thresholds.MaxLag
+		orchestrator.ReplicaResumeMaxLag = plan.CrossRegionLagLimits()
+		setUpgradeVerifier(env, orchestrator, plan, *upgradeStatus)
+		if *snapshots != "" {
+			orchestrator.Snapshots = &snapshotsFileInspector{path: *snapshots}
+		}
+		rolling := &mysql.RollingUpgrade{
+			Orchestrator: orchestrator,
+			Guard:        mysql.UpgradeGuard{Primary: plan.Topology.Primary, MaxLag: plan.Thresholds.MaxLag, ReplicaMaxLag: plan.CrossRegionLagLimits(), MaxThreadsRunning: plan.Thresholds.MaxPrimaryThreadsRunning, MinServingReplicas: plan.Thresholds.MinServingReplicas},
+			Concurrency:  *concurrency,
+			MaxPause:     *maxPause,
+		}
+		rolling.Guard.Capacity = buildReadCapacity(plan)
+		if *statusDir != "" {
+			rolling.Guard.Inspector = env.Recorder.replicaInspector(&timelineReplicaInspector{path: func(host string) string { return filepath.Join(*statusDir, host+".json") }, primary: plan.Topology.Primary})
+		}
+		if !anyUpgradeStarted(orchestrator, plan.Topology.Replicas) {
+			calendarFindings, hold := env.holdForTraffic(ctx, plan)
+			stateFindings = append(stateFindings, calendarFindings...)
+			if hold {
+				return prependFindings(Output{}, stateFindings)
+			}
+		}
+		if plan.DDLFreeze.Enabled {
+			frozen := freezeDDL(ctx, env, *in, plan, st, "", *simulate)
+			stateFindings = append(stateFindings, frozen.Findings...)
+			if frozen.Summary.Block > 0 {
+				return prependFindings(Output{}, stateFindings)
+			}
+		}
+		if plan.BinlogRetention.Extend {
+			extended := extendBinlogRetention(ctx, env, *in, plan, st, *simulate)
+			stateFindings = append(stateFindings, extended.Findings...)
+			if extended.Summary.Block > 0 {
+				return prependFindings(Output{}, stateFindings)
+			}
+		}
+		summary, findings, err := rolling.Run(ctx, plan.Topology.Replicas)
+		env.Manifest.recordCheck("rolling_upgrade", map[string]interface{}{"replicas": plan.Topology.Replicas, "concurrency": *concurrency, "max_pause": maxPause.String(), "simulate": *simulate, "upgrade_status": *upgradeStatus, "reconcile": *reconcile, "server_snapshots": *snapshots})
+		if err != nil {
+			return blockOutput(err)
+		}
+		return prependFindings(convertMySQLFindings(summary, findings), stateFindings)
 	}
-	writeOutput(convertMySQLFindings(summary, findings))
 }
 
-func handleValidate(args []string) {
-	if len(args) < 2 {
-		printUsageAndExit()
-	}
-	fs := flag.NewFlagSet("validate", flag.ExitOnError)
-	planPath := fs.String("plan", "migration.yaml", "path to migration plan YAML")
-	primarySchema := fs.String("schema-primary", "", "path to primary schema JSON")
-	replicaSchema := fs.String("schema-replica", "", "path to replica schema JSON")
-	if args[0] == "replica" {
-		if len(args) < 2 {
-			printUsageAndExit()
+// anyUpgradeStarted reports whether an upgrade of one of replicas is under
+// way; such a run is finished rather than held for the traffic calendar.
+func anyUpgradeStarted(orchestrator *mysql.UpgradeOrchestrator, replicas []string) bool {
+	for _, replica := range replicas {
+		if orchestrator.Started(replica) {
+			return true
 		}
-		_ = fs.Parse(args[2:])
-	} else {
-		_ = fs.Parse(args[1:])
 	}
+	return false
+}
 
-	plan, err := workflow.LoadPlan(*planPath)
-	if err != nil {
-		writeOutput(Output{Summary: Summary{Block: 1}, Findings: []OutputFinding{{Severity: "BLOCK", Message: err.Error()}}})
+// buildReadCapacity returns the plan's read pool floor, or nil when the plan
+// sets none.
+func buildReadCapacity(plan workflow.MigrationPlan) *mysql.ReadCapacity {
+	if plan.ReadPool.MinCapacity <= 0 {
+		return nil
+	}
+	return &mysql.ReadCapacity{Weights: plan.ReadPool.Weights, MinCapacity: plan.ReadPool.MinCapacity, Peak: plan.ReadPool.InPeak}
+}
+
+// setUpgradeVerifier makes the orchestrator confirm each replica reports the
+// plan's target version before checkpointing its upgrade. Reported versions
+// are recorded in the run manifest.
+func setUpgradeVerifier(env *env, orchestrator *mysql.UpgradeOrchestrator, plan workflow.MigrationPlan, path string) {
+	if path == "" {
 		return
 	}
+	orchestrator.Verifier = &versionRecorder{inner: &upgradeStatusFileInspector{path: path}, manifest: env.Manifest}
+	orchestrator.TargetVersion = plan.TargetVersion
+}
 
-	switch args[0] {
-	case "replica":
-		if len(args) < 2 {
-			printUsageAndExit()
+// setupUpgradeStatistics runs ANALYZE TABLE and builds the plan's histograms
+// on a replica whose upgrade completed, before it is undrained, and verifies
+// statistics freshness.
+func setupUpgradeStatistics(fs *flag.FlagSet) runFunc {
+	in := &inputFlags{}
+	in.registerAdmin(fs)
+	return func(ctx context.Context, env *env, args []string) Output {
+		replica := args[0]
+		plan, err := env.loadPlan()
+		if err != nil {
+			return blockOutput(err)
 		}
-		check := buildSchemaParityCheck(*primarySchema, *replicaSchema, plan.Topology.Primary, args[1])
-		findings, err := check.Run(context.Background(), planInput(plan, args[1]))
+		if in.AdminDSN == "" {
+			return blockOutput(failure.Config("upgrade statistics needs --admin-dsn to analyze the replica's tables"))
+		}
+		st, stateFindings, err := env.openState(plan)
 		if err != nil {
-			writeOutput(Output{Summary: Summary{Block: 1}, Findings: []OutputFinding{{Severity: "BLOCK", Message: err.Error()}}})
-			return
+			return blockOutput(err)
+		}
+		if hasBlockFinding(stateFindings) {
+			return prependFindings(Output{}, stateFindings)
 		}
-		writeOutput(convertCheckFindings(findings))
-	case "primary":
-		replicaHost, repErr := selectReplica(plan)
-		if repErr != nil {
-			writeOutput(Output{Summary: Summary{Block: 1}, Findings: []OutputFinding{{Severity: "BLOCK", Message: repErr.Error()}}})
-			return
+		if !mysql.NewUpgradeOrchestrator(nil, nil, st, plan.Topology.Primary, env.Logger).Upgraded(replica) {
+			return prependFindings(Output{Summary: Summary{Block: 1}, Findings: []OutputFinding{{
+				Severity: "BLOCK",
+				Message:  fmt.Sprintf("%s has not finished its upgrade; run upgrade replica first", replica),
+				Meta:     map[string]interface{}{"replica": replica},
+			}}}, stateFindings)
 		}
-		check := buildSchemaParityCheck(*primarySchema, *replicaSchema, plan.Topology.Primary, replicaHost)
-		findings, err := check.Run(context.Background(), planInput(plan, replicaHost))
+		refresh := &mysql.StatisticsRefresh{Connect: adminConnector(*in, plan), State: st, Config: plan.Statistics, Logger: env.Logger}
+		summary, findings, err := refresh.Run(ctx, replica)
+		env.Manifest.recordCheck("statistics_refresh", map[string]interface{}{"replica": replica, "tables": len(plan.Statistics.Tables), "histograms": len(plan.Statistics.Histograms), "max_age": plan.Statistics.MaxAge.String()})
 		if err != nil {
-			writeOutput(Output{Summary: Summary{Block: 1}, Findings: []OutputFinding{{Severity: "BLOCK", Message: err.Error()}}})
-			return
+			return blockOutput(err)
 		}
-		writeOutput(convertCheckFindings(findings))
-	default:
-		printUsageAndExit()
+		return prependFindings(convertMySQLFindings(summary, findings), stateFindings)
 	}
 }
 
-func handleCDC(args []string) {
-	if len(args) < 1 || args[0] != "check" {
-		printUsageAndExit()
+func setupUpgradeUndrain(fs *flag.FlagSet) runFunc {
+	simulate := fs.Bool("simulate", false, "simulate drainers without touching load balancers")
+	in := &inputFlags{}
+	in.registerDrain(fs)
+	return func(ctx context.Context, env *env, args []string) Output {
+		plan, err := env.loadPlan()
+		if err != nil {
+			return blockOutput(err)
+		}
+		st, stateFindings, err := env.openState(plan)
+		if err != nil {
+			return blockOutput(err)
+		}
+		if hasBlockFinding(stateFindings) {
+			return prependFindings(Output{}, stateFindings)
+		}
+		orchestrator := mysql.NewUpgradeOrchestrator(nil, nil, st, plan.Topology.Primary, env.Logger)
+		if orchestrator.Drainers, err = buildDrainers(plan, *in, st, *simulate); err != nil {
+			return blockOutput(err)
+		}
+		summary, findings, err := orchestrator.Undrain(ctx, args[0])
+		env.Manifest.recordCheck("replica_undrain", map[string]interface{}{"replica": args[0], "simulate": *simulate})
+		if err != nil {
+			return blockOutput(err)
+		}
+		return prependFindings(convertMySQLFindings(summary, findings), stateFindings)
 	}
-	fs := flag.NewFlagSet("cdc check", flag.ExitOnError)
-	planPath := fs.String("plan", "migration.yaml", "path to migration plan YAML")
-	cdcStatus := fs.String("cdc-status", "", "path to Debezium status JSON")
-	_ = fs.Parse(args[1:])
+}
 
-	plan, err := workflow.LoadPlan(*planPath)
-	if err != nil {
-		writeOutput(Output{Summary: Summary{Block: 1}, Findings: []OutputFinding{{Severity: "BLOCK", Message: err.Error()}}})
-		return
+func setupValidateReplica(fs *flag.FlagSet) runFunc {
+	in := &inputFlags{}
+	in.registerSchema(fs)
+	return func(ctx context.Context, env *env, args []string) Output {
+		plan, err := env.loadPlan()
+		if err != nil {
+			return blockOutput(err)
+		}
+		output := runSchemaParity(ctx, env, *in, plan, args[0])
+		if len(plan.DataParity.Tables) == 0 {
+			return output
+		}
+		return prependFindings(runDataParity(ctx, env, *in, plan, args[0]), output.Findings)
 	}
+}
 
-	check := buildDebeziumCheck(*cdcStatus, plan.CDC.Connector)
-	findings, err := check.Run(context.Background(), planInput(plan, ""))
-	if err != nil {
-		writeOutput(Output{Summary: Summary{Block: 1}, Findings: []OutputFinding{{Severity: "BLOCK", Message: err.Error()}}})
-		return
+func setupValidatePrimary(fs *flag.FlagSet) runFunc {
+	in := &inputFlags{}
+	in.registerSchema(fs)
+	in.registerAdmin(fs)
+	in.registerBinlogRetention(fs)
+	simulate := fs.Bool("simulate", false, "simulate rollback actions when post_validation.on_block is auto_rollback, and the binlog_retention restore")
+	return func(ctx context.Context, env *env, args []string) Output {
+		plan, err := env.loadPlan()
+		if err != nil {
+			return blockOutput(err)
+		}
+		replicaHost, err := selectReplica(plan)
+		if err != nil {
+			return blockOutput(err)
+		}
+		validation := prependFindings(runHeartbeat(ctx, env, *in, plan, replicaHost), runSchemaParity(ctx, env, *in, plan, replicaHost).Findings)
+		validation = prependFindings(runOldPrimarySoak(ctx, env, *in, plan, replicaHost), validation.Findings)
+		output := autoRollback(ctx, env, plan, replicaHost, *simulate, validation)
+		// A halted validation keeps the extended retention while operators
+		// investigate; completion and rollback both end the window.
+		if plan.BinlogRetention.Extend && (validation.Summary.Block == 0 || plan.PostValidation.OnBlock == workflow.OnBlockAutoRollback) {
+			output = prependFindings(restoreBinlogRetention(ctx, env, *in, plan, *simulate), output.Findings)
+		}
+		return recordEnvironmentRun(env, plan, replicaHost, output)
 	}
-	writeOutput(convertCheckFindings(findings))
 }
 
-func handlePromote(args []string) {
-	fs := flag.NewFlagSet("promote", flag.ExitOnError)
-	planPath := fs.String("plan", "migration.yaml", "path to migration plan YAML")
-	primarySchema := fs.String("schema-primary", "", "path to primary schema JSON")
-	replicaSchema := fs.String("schema-replica", "", "path to replica schema JSON")
-	cdcStatus := fs.String("cdc-status", "", "path to Debezium status JSON")
-	confirm := fs.String("confirm", "", "confirmation phrase")
-	phrase := fs.String("phrase", "PROMOTE", "required confirmation phrase")
-	_ = fs.Parse(args)
+// recordEnvironmentRun marks the run as completed once post-validation passes
+// on a promoted replica, so state gc can archive its checkpoints. With --env it
+// also records the fully successful run in the selected environment, which is
+// the prerequisite later environments gate on.
+func recordEnvironmentRun(env *env, plan workflow.MigrationPlan, replicaHost string, output Output) Output {
+	if output.Summary.Block > 0 {
+		return output
+	}
+	if plan.SelectedEnvironment == "" {
+		// Nothing to complete without an upgrade recorded in the state file.
+		if _, err := os.Stat(env.Globals.StatePath); err != nil {
+			return output
+		}
+	}
+	st, stateFindings, err := env.openState(plan)
+	if err != nil {
+		return prependFindings(blockOutput(err), output.Findings)
+	}
+	if hasBlockFinding(stateFindings) {
+		return prependFindings(output, stateFindings)
+	}
+	meta := map[string]interface{}{"environment": plan.SelectedEnvironment, "replica": replicaHost, "run_id": env.Globals.RunID}
+	if !mysql.NewPromotionOrchestrator(nil, st, plan.Topology.Primary, env.Logger).Promoted(replicaHost) {
+		if plan.SelectedEnvironment == "" {
+			return prependFindings(output, stateFindings)
+		}
+		return prependFindings(output, append(stateFindings, OutputFinding{
+			Severity: "INFO",
+			Code:     codeEnvironmentRun,
+			Message:  fmt.Sprintf("%s is not promoted; run not recorded as complete in %q", replicaHost, plan.SelectedEnvironment),
+			Meta:     meta,
+		}))
+	}
+	completedAt := time.Now().UTC().Format(time.RFC3339)
+	state.MarkRunCompleted(st, completedAt)
+	if plan.SelectedEnvironment == "" {
+		return prependFindings(output, append(stateFindings, OutputFinding{
+			Severity: "INFO",
+			Code:     codeRunCompleted,
+			Message:  fmt.Sprintf("run %q marked complete; state gc can archive its checkpoints", env.Globals.RunID),
+			Meta:     map[string]interface{}{"replica": replicaHost, "run_id": env.Globals.RunID, "completed_at": completedAt},
+		}))
+	}
+	run := state.EnvironmentRun{RunID: env.Globals.RunID, PlanHash: env.PlanHash, CompletedAt: completedAt}
+	state.RecordEnvironmentRun(st.Backend(), plan.Migration, plan.SelectedEnvironment, run)
+	meta["recorded"] = true
+	return prependFindings(output, append(stateFindings, OutputFinding{
+		Severity: "INFO",
+		Code:     codeEnvironmentRun,
+		Message:  fmt.Sprintf("recorded a fully successful run in %q", plan.SelectedEnvironment),
+		Meta:     meta,
+	}))
+}
 
-	plan, err := workflow.LoadPlan(*planPath)
+// runOldPrimarySoak watches the demoted primary through --schema-dsn for
+// post_validation.soak_duration, blocking on the first write. Plans without a
+// soak duration skip it.
+func runOldPrimarySoak(ctx context.Context, env *env, in inputFlags, plan workflow.MigrationPlan, replicaHost string) Output {
+	if plan.PostValidation.SoakDuration <= 0 {
+		return Output{}
+	}
+	check := skipMissingInputs([]checks.PreflightCheck{&mysql.OldPrimarySoakCheck{
+		Inspector:    &mysql.ServerWriteStateInspector{Connect: liveConnector(in, plan, replicaHost)},
+		Host:         plan.Topology.Primary,
+		Duration:     plan.PostValidation.SoakDuration,
+		PollInterval: plan.PostValidation.SoakInterval,
+		Migration:    plan.Migration,
+		Notifier:     &commandNotifier{argv: plan.Notifications.Command},
+	}}, in)[0]
+	findings, err := check.Run(ctx, planInput(plan, replicaHost))
+	env.Manifest.recordChecks([]checks.PreflightCheck{check})
 	if err != nil {
-		writeOutput(Output{Summary: Summary{Block: 1}, Findings: []OutputFinding{{Severity: "BLOCK", Message: err.Error()}}})
-		return
+		return blockOutput(err)
 	}
+	return convertCheckFindings(findings)
+}
 
-	replicaHost, repErr := selectReplica(plan)
-	if repErr != nil {
-		writeOutput(Output{Summary: Summary{Block: 1}, Findings: []OutputFinding{{Severity: "BLOCK", Message: repErr.Error()}}})
-		return
+// runHeartbeat writes, reads back and deletes a row through
+// post_validation.endpoint with --admin-dsn, blocking unless the write lands on
+// the promoted replica. Plans without an endpoint skip the probe.
+func runHeartbeat(ctx context.Context, env *env, in inputFlags, plan workflow.MigrationPlan, replicaHost string) Output {
+	endpoint := plan.PostValidation.Endpoint
+	if endpoint == "" {
+		return Output{}
 	}
-	checksList := buildChecks(*primarySchema, *replicaSchema, *cdcStatus, plan.Topology.Primary, replicaHost, plan)
-	gate := workflow.PromotionGate{Checks: checksList, ConfirmationPhrase: *phrase}
-	summary, findings, err := gate.Run(context.Background(), planInput(plan, replicaHost), *confirm)
+	if in.AdminDSN == "" {
+		return Output{Summary: Summary{Warn: 1}, Findings: []OutputFinding{{
+			Severity: "WARN",
+			Code:     mysql.CodeHeartbeatSkipped,
+			Message:  fmt.Sprintf("heartbeat probe via %q skipped: --admin-dsn is not set", endpoint),
+			Meta:     map[string]interface{}{"endpoint": endpoint},
+		}}}
+	}
+	probe := &mysql.HeartbeatProbe{
+		Prober:          &mysql.SQLHeartbeatProber{Connect: mysql.AdminDSNConnector(mysqlDriverName, in.AdminDSN)},
+		Endpoint:        endpoint,
+		Table:           plan.PostValidation.ProbeTable,
+		ExpectedPrimary: replicaHost,
+		Logger:          env.Logger,
+	}
+	summary, findings, err := probe.Run(ctx)
 	if err != nil {
-		writeOutput(Output{Summary: Summary{Block: 1}, Findings: []OutputFinding{{Severity: "BLOCK", Message: err.Error()}}})
-		return
+		return blockOutput(err)
 	}
-	writeOutput(convertCheckSummary(summary, findings))
+	return convertMySQLFindings(summary, findings)
 }
 
-func buildChecks(primarySchema string, replicaSchema string, cdcStatus string, primaryHost string, replicaHost string, plan workflow.MigrationPlan) []checks.PreflightCheck {
-	checksList := []checks.PreflightCheck{}
-	checksList = append(checksList, buildSchemaParityCheck(primarySchema, replicaSchema, primaryHost, replicaHost))
-	checksList = append(checksList, buildDebeziumCheck(cdcStatus, plan.CDC.Connector))
-	return checksList
+// runDataParity compares the plan's data_parity tables live. With a state file
+// from the upgrade, chunk progress is checkpointed there so an interrupted
+// comparison resumes.
+func runDataParity(ctx context.Context, env *env, in inputFlags, plan workflow.MigrationPlan, replicaHost string) Output {
+	var checkpoints checks.Checkpointer
+	var stateFindings []OutputFinding
+	if _, err := os.Stat(env.Globals.StatePath); err == nil {
+		st, findings, err := env.openState(plan)
+		if err != nil {
+			return blockOutput(err)
+		}
+		if hasBlockFinding(findings) {
+			return prependFindings(Output{}, findings)
+		}
+		checkpoints, stateFindings = st, findings
+	}
+	check := skipMissingInputs([]checks.PreflightCheck{buildDataParityCheck(in, liveConnector(in, plan, replicaHost), plan, plan.Topology.Primary, replicaHost, checkpoints)}, in)[0]
+	findings, err := check.Run(ctx, planInput(plan, replicaHost))
+	env.Manifest.recordChecks([]checks.PreflightCheck{check})
+	if err != nil {
+		return prependFindings(blockOutput(err), stateFindings)
+	}
+	return prependFindings(convertCheckFindings(findings), stateFindings)
 }
 
-func buildSchemaParityCheck(primarySchema string, replicaSchema string, primaryHost string, replicaHost string) checks.PreflightCheck {
-	return &checks.SchemaParityCheck{
-		Inspector:   &schemaFileInspector{primaryPath: primarySchema, replicaPath: replicaSchema, primaryHost: primaryHost, replicaHost: replicaHost},
+// buildDataParityCheck compares the plan's data_parity tables through live,
+// which is nil without --schema-dsn.
+func buildDataParityCheck(in inputFlags, live mysql.Connector, plan workflow.MigrationPlan, primaryHost string, replicaHost string, checkpoints checks.Checkpointer) checks.PreflightCheck {
+	check := &checks.DataParityCheck{
+		Options:     plan.DataParity.Options(),
+		Checkpoints: checkpoints,
 		PrimaryHost: primaryHost,
 		ReplicaHost: replicaHost,
+		Tables:      plan.DataParity.TableNames(),
+	}
+	if live != nil {
+		inspector := &mysql.ChecksumDataInspector{Connect: live, Database: in.SchemaDatabase}
+		check.Inspector = inspector
+		check.Sampler = inspector
 	}
+	return check
 }
 
-func buildDebeziumCheck(statusPath string, connector string) checks.PreflightCheck {
-	return &cdc.DebeziumHealthCheck{
-		Inspector: &debeziumFileInspector{path: statusPath},
-		Connector: connector,
+func runSchemaParity(ctx context.Context, env *env, in inputFlags, plan workflow.MigrationPlan, replicaHost string) Output {
+	check := buildSchemaParityCheck(env.Recorder, in, liveConnector(in, plan, replicaHost), plan.Topology.Primary, replicaHost)
+	findings, err := check.Run(ctx, planInput(plan, replicaHost))
+	env.Manifest.recordChecks([]checks.PreflightCheck{check})
+	if err != nil {
+		return blockOutput(err)
 	}
+	return convertCheckFindings(findings)
 }
 
-func convertCheckResults(summary checks.Summary, results []checks.Result) Output {
-	findings := []OutputFinding{}
-	for _, r := range results {
-		for _, f := range r.Findings {
-			findings = append(findings, OutputFinding{Severity: f.Severity.String(), Message: f.Message, Meta: f.Meta})
+func setupCDCCheck(fs *flag.FlagSet) runFunc {
+	in := &inputFlags{}
+	in.registerCDC(fs)
+	autoRemediate := fs.Bool("auto-remediate", false, "apply the plan's cdc.auto_remediation policies to the findings; requires the operator role")
+	connectURL := fs.String("connect-url", "", "Kafka Connect REST URL used by restart_task remediations")
+	messageSchemas := fs.String("message-schemas", "", "JSON file with the latest Debezium message (with schema) per table, {\"db.table\": message}; compared with the baseline kept in --state")
+	recapture := fs.Bool("recapture-message-schemas", false, "replace the stored message schema baseline with --message-schemas")
+	connectorMetrics := fs.String("connector-metrics", "", "JSON list of connector streaming metric samples, [{\"time\", \"total_events_seen\", \"queue_total_capacity\", \"queue_remaining_capacity\"}]; compared with the throughput baseline kept in --state")
+	recaptureThroughput := fs.Bool("recapture-throughput", false, "replace the stored throughput baseline with --connector-metrics")
+	return func(ctx context.Context, env *env, args []string) Output {
+		if *autoRemediate {
+			env.Role = access.RoleOperator
 		}
+		plan, err := env.loadPlan()
+		if err != nil {
+			return blockOutput(err)
+		}
+		checksList := append([]checks.PreflightCheck{buildDebeziumCheck(env.Recorder, in.CDCStatus, plan)}, cdcInputChecks(*in, plan)...)
+		var baselines *state.FileState
+		if *messageSchemas != "" || *connectorMetrics != "" {
+			if baselines, err = env.fileState(env.Globals.StatePath); err != nil {
+				return blockOutput(err)
+			}
+		}
+		if *messageSchemas != "" {
+			check, err := buildMessageSchemaCheck(baselines, *messageSchemas, plan, *recapture)
+			if err != nil {
+				return blockOutput(err)
+			}
+			checksList = append(checksList, check)
+		}
+		if *connectorMetrics != "" {
+			check, err := buildThroughputCheck(baselines, *connectorMetrics, plan, *recaptureThroughput)
+			if err != nil {
+				return blockOutput(err)
+			}
+			checksList = append(checksList, check)
+		}
+		findings := []checks.Finding{}
+		for _, check := range checksList {
+			checkFindings, err := check.Run(ctx, planInput(plan, ""))
+			if err != nil {
+				return blockOutput(err)
+			}
+			findings = append(findings, checkFindings...)
+		}
+		env.Manifest.recordChecks(checksList)
+		for _, f := range findings {
+			switch f.Code {
+			case cdc.CodeMessageSchemaBaseline:
+				baselines.Set(messageSchemaKey(plan.StateName()), f.Meta["schemas"])
+			case cdc.CodeThroughputBaseline:
+				baselines.Set(throughputKey(plan.StateName()), f.Meta["throughput"])
+			}
+		}
+		if !*autoRemediate || len(plan.CDC.AutoRemediation) == 0 {
+			return convertCheckFindings(findings)
+		}
+
+		st, stateFindings, err := env.openState(plan)
+		if err != nil {
+			return blockOutput(err)
+		}
+		if hasBlockFinding(stateFindings) {
+			return prependFindings(convertCheckFindings(findings), stateFindings)
+		}
+		remediator := &cdc.AutoRemediator{Policies: plan.CDC.AutoRemediation, Connector: plan.CDC.Connector, State: st, Logger: env.Logger}
+		if *connectURL != "" {
+			remediator.Restarter = &cdc.ConnectClient{URL: *connectURL, Client: env.httpClient("kafka_connect")}
+		}
+		if len(plan.CDC.SignalCommand) > 0 {
+			remediator.Snapshots = &cdc.CommandSnapshotSignaler{Command: plan.CDC.SignalCommand}
+		}
+		findings, err = remediator.Apply(ctx, findings)
+		env.Manifest.recordCheck("cdc_auto_remediation", map[string]interface{}{"connector": plan.CDC.Connector, "policies": len(plan.CDC.AutoRemediation)})
+		if err != nil {
+			return blockOutput(err)
+		}
+		return prependFindings(convertCheckFindings(findings), stateFindings)
 	}
-	return Output{Summary: Summary{Info: summary.Info, Warn: summary.Warn, Block: summary.Block}, Findings: findings}
 }
 
-func convertCheckSummary(summary checks.Summary, findings []checks.Finding) Output {
-	return Output{Summary: Summary{Info: summary.Info, Warn: summary.Warn, Block: summary.Block}, Findings: convertCheckFindings(findings).Findings}
+// setupCDCRestart restarts the plan's connector through the Kafka Connect REST
+// API, so the restart is checkpointed and recorded in the run manifest
+// rather than done with curl.
+func setupCDCRestart(fs *flag.FlagSet) runFunc {
+	connectURL := fs.String("connect-url", "", "Kafka Connect REST URL")
+	task := fs.Int("task", cdc.AllTasks, "restart only this task id (default the connector and all its tasks)")
+	timeout := fs.Duration("timeout", cdc.DefaultRestartTimeout, "how long to wait for RUNNING")
+	pollInterval := fs.Duration("poll-interval", cdc.DefaultRestartPollInterval, "how often to poll the connector status")
+	return func(ctx context.Context, env *env, args []string) Output {
+		if *connectURL == "" {
+			return blockOutput(failure.Config("--connect-url is required"))
+		}
+		if *task < cdc.AllTasks {
+			return blockOutput(failure.Config("--task must not be negative"))
+		}
+		plan, err := env.loadPlan()
+		if err != nil {
+			return blockOutput(err)
+		}
+		st, stateFindings, err := env.openState(plan)
+		if err != nil {
+			return blockOutput(err)
+		}
+		if hasBlockFinding(stateFindings) {
+			return prependFindings(Output{}, stateFindings)
+		}
+		client := &cdc.ConnectClient{URL: *connectURL, Client: env.httpClient("kafka_connect")}
+		orchestrator := &cdc.RestartOrchestrator{Restarter: client, Inspector: env.Recorder.debeziumInspector(client), State: st, Timeout: *timeout, PollInterval: *pollInterval, Logger: env.Logger}
+		findings, err := orchestrator.Restart(ctx, plan.CDC.Connector, *task)
+		env.Manifest.recordCheck("cdc_restart", map[string]interface{}{"connector": plan.CDC.Connector, "task": *task, "timeout": timeout.String()})
+		if err != nil {
+			return blockOutput(err)
+		}
+		return prependFindings(convertCheckFindings(findings), stateFindings)
+	}
 }
 
-func convertCheckFindings(findings []checks.Finding) Output {
-	outs := []OutputFinding{}
-	var summary Summary
-	for _, f := range findings {
-		outs = append(outs, OutputFinding{Severity: f.Severity.String(), Message: f.Message, Meta: f.Meta})
-		switch f.Severity {
-		case checks.SeverityInfo:
-			summary.Info++
-		case checks.SeverityWarn:
-			summary.Warn++
-		case checks.SeverityBlock:
-			summary.Block++
+func setupPromotePrepare(fs *flag.FlagSet) runFunc {
+	in := &inputFlags{}
+	in.registerSchema(fs)
+	in.registerCDC(fs)
+	in.registerReplication(fs)
+	in.registerSecurity(fs)
+	in.registerRouting(fs)
+	in.registerWarmup(fs)
+	in.registerDatadir(fs)
+	in.registerOffsets(fs)
+	in.registerAdmin(fs)
+	filters := &filterFlags{}
+	filters.register(fs)
+	confirm := fs.String("confirm", "", "confirmation phrase")
+	phrase := fs.String("phrase", "PROMOTE", "required confirmation phrase")
+	simulate := fs.Bool("simulate", false, "simulate promotion actions")
+	return func(ctx context.Context, env *env, args []string) Output {
+		plan, err := env.loadPlan()
+		if err != nil {
+			return blockOutput(err)
+		}
+		replicaHost, err := selectReplica(plan)
+		if err != nil {
+			return blockOutput(err)
+		}
+		st, stateFindings, err := env.openState(plan)
+		if err != nil {
+			return blockOutput(err)
+		}
+		if hasBlockFinding(stateFindings) {
+			return prependFindings(Output{}, stateFindings)
+		}
+		calendarFindings, hold := env.holdForTraffic(ctx, plan)
+		stateFindings = append(stateFindings, calendarFindings...)
+		if hold {
+			return prependFindings(Output{}, stateFindings)
+		}
+		// Warm the candidate first so the gate's buffer_pool_hit_ratio
+		// check measures the cache cutover will meet.
+		if plan.Warmup.Enabled {
+			warmed := warmBufferPool(ctx, env, *in, plan, st, replicaHost, *simulate)
+			stateFindings = append(stateFindings, warmed.Findings...)
+			if warmed.Summary.Block > 0 {
+				return prependFindings(Output{}, stateFindings)
+			}
+		}
+		checksList := buildChecks(env.Recorder, *in, plan.Topology.Primary, replicaHost, plan)
+		if plan.DDLFreeze.Enabled {
+			schema := schemaInputInspector(*in, liveConnector(*in, plan, replicaHost), plan.Topology.Primary, replicaHost)
+			checksList = append(checksList, &mysql.DDLDriftCheck{Schema: env.Recorder.schemaInspector(schema), State: st, PrimaryHost: plan.Topology.Primary})
+		}
+		checksList, filterFindings, err := filters.apply(checksList)
+		if err != nil {
+			return blockOutput(err)
+		}
+		gate := workflow.PromotionGate{Checks: checksList, RequiredCheckNames: plan.RequiredCheckNames(), AllowedWarnCodes: plan.Promotion.AllowWarnCodes, MaxWarnCount: plan.Thresholds.MaxWarnCount, FreshnessWindow: plan.Promotion.FreshnessWindow, ConfirmationPhrase: *phrase, State: st, Logger: env.Logger}
+		if decision := plan.Promotion.Decision; decision.URL != "" {
+			gate.Decision = &workflow.DecisionService{URL: decision.URL, Secret: []byte(os.Getenv(decisionSecretEnv)), Timeout: decision.Timeout, Client: env.httpClient("decision_service")}
+			gate.Migration = plan.Migration
+		}
+		summary, findings, err := gate.Run(ctx, planInput(plan, replicaHost), *confirm)
+		env.Manifest.recordChecks(checksList)
+		if err != nil {
+			return blockOutput(err)
+		}
+		for _, f := range findings {
+			if f.Code == cdc.CodeOffsetSnapshot {
+				// Execute rolls CDC back to these offsets; a gate that
+				// passed without them recorded must not let it run.
+				if err := workflow.PutArtifact(st, workflow.ArtifactCDCOffsets, f.Meta); err != nil {
+					return blockOutput(err)
+				}
+				if gtidSet, _ := f.Meta["primary_gtid_set"].(string); gtidSet != "" {
+					if err := workflow.SetCapturedGTIDSet(st, plan.Topology.Primary, gtidSet); err != nil {
+						return blockOutput(err)
+					}
+				}
+			}
+		}
+		output := prependFindings(convertCheckSummary(summary, findings), stateFindings)
+		orchestrator := mysql.NewPromotionOrchestrator(promotionActions(*simulate), st, plan.Topology.Primary, env.Logger)
+		orchestrator.CutoverBudget = plan.Thresholds.MaxCutoverDuration
+		if summary.Block > 0 {
+			// An earlier prepare must not let execute cut over past this
+			// blocked gate.
+			orchestrator.Unprepare(replicaHost)
+			return filterFindings(output)
 		}
+		if err := workflow.SetPromotionCandidate(st, replicaHost); err != nil {
+			return blockOutput(err)
+		}
+
+		prepSummary, prepFindings, err := orchestrator.Prepare(ctx, replicaHost)
+		env.Manifest.recordCheck("promotion_prepare", map[string]interface{}{"replica": replicaHost, "simulate": *simulate})
+		if err != nil {
+			return blockOutput(err)
+		}
+		return filterFindings(prependFindings(convertMySQLFindings(prepSummary, prepFindings), output.Findings))
 	}
-	return Output{Summary: summary, Findings: outs}
 }
 
-func convertMySQLFindings(summary mysql.Summary, findings []mysql.Finding) Output {
-	outs := []OutputFinding{}
-	for _, f := range findings {
-		outs = append(outs, OutputFinding{Severity: f.Severity.String(), Message: f.Message, Meta: f.Meta})
+func setupPromoteExecute(fs *flag.FlagSet) runFunc {
+	confirm := fs.String("confirm", "", "confirmation phrase")
+	phrase := fs.String("phrase", "PROMOTE", "required confirmation phrase")
+	simulate := fs.Bool("simulate", false, "simulate promotion actions")
+	in := &inputFlags{}
+	in.registerAdmin(fs)
+	return func(ctx context.Context, env *env, args []string) Output {
+		plan, err := env.loadPlan()
+		if err != nil {
+			return blockOutput(err)
+		}
+		replicaHost, err := selectReplica(plan)
+		if err != nil {
+			return blockOutput(err)
+		}
+		st, stateFindings, err := env.openState(plan)
+		if err != nil {
+			return blockOutput(err)
+		}
+		if hasBlockFinding(stateFindings) {
+			return prependFindings(Output{}, stateFindings)
+		}
+		if block := workflow.RequireConfirmation(*phrase, *confirm); block != nil {
+			return prependFindings(convertCheckFindings([]checks.Finding{*block}), stateFindings)
+		}
+		// Cut over the replica prepare gated, while the plan still allows it.
+		if candidate, ok := workflow.PromotionCandidate(st); ok && contains(plan.Topology.PromotionCandidates(), candidate) {
+			replicaHost = candidate
+		}
+		// The gate ran at prepare time; refuse to cut over on results that
+		// have aged past the plan's freshness window since.
+		if window := plan.Promotion.FreshnessWindow; window > 0 {
+			freshness := workflow.GateFreshness(st, plan.RequiredCheckNames(), window, time.Now())
+			stateFindings = append(stateFindings, convertCheckFindings([]checks.Finding{freshness}).Findings...)
+			if freshness.Severity == checks.SeverityBlock {
+				return prependFindings(Output{}, stateFindings)
+			}
+		}
+		orchestrator := mysql.NewPromotionOrchestrator(promotionActions(*simulate), st, plan.Topology.Primary, env.Logger)
+		orchestrator.CutoverBudget = plan.Thresholds.MaxCutoverDuration
+		summary, findings, err := orchestrator.Execute(ctx, replicaHost)
+		env.Manifest.recordCheck("promotion_execute", map[string]interface{}{"replica": replicaHost, "simulate": *simulate})
+		if err != nil {
+			return blockOutput(err)
+		}
+		output := prependFindings(convertMySQLFindings(summary, findings), stateFindings)
+		if !plan.DDLFreeze.Enabled || summary.Block > 0 {
+			return output
+		}
+		return prependFindings(thawDDL(ctx, env, *in, plan, st, *simulate), output.Findings)
 	}
-	return Output{Summary: Summary{Info: summary.Info, Warn: summary.Warn, Block: summary.Block}, Findings: outs}
 }
 
-func writeOutput(output Output) {
-	b, err := json.MarshalIndent(output, "", "  ")
+// freezeDDL takes the plan's DDL freeze on the primary before replicas are
+// upgraded, recording the schema baseline from --schema-dsn or
+// --schema-primary. The lock table is written with --admin-dsn; --simulate
+// records the freeze without touching MySQL.
+func freezeDDL(ctx context.Context, env *env, in inputFlags, plan workflow.MigrationPlan, st workflow.State, replicaHost string, simulate bool) Output {
+	if in.SchemaDSN == "" && in.PrimarySchema == "" {
+		return blockOutput(failure.Config("ddl_freeze needs --schema-dsn or --schema-primary for the schema baseline"))
+	}
+	freeze, err := ddlFreeze(env, in, plan, st, simulate)
+	if err != nil {
+		return blockOutput(err)
+	}
+	freeze.Schema = env.Recorder.schemaInspector(schemaInputInspector(in, liveConnector(in, plan, replicaHost), plan.Topology.Primary, replicaHost))
+	summary, findings, err := freeze.Freeze(ctx)
+	env.Manifest.recordCheck("ddl_freeze", map[string]interface{}{"primary": plan.Topology.Primary, "lock_table": plan.DDLFreeze.LockTable, "simulate": simulate})
 	if err != nil {
-		log.Fatalf("failed to encode output: %v", err)
+		return blockOutput(err)
 	}
-	_, _ = os.Stdout.Write(b)
-	_, _ = os.Stdout.Write([]byte("\n"))
+	return convertMySQLFindings(summary, findings)
 }
 
-func printUsageAndExit() {
-	fmt.Fprintln(os.Stderr, "Usage:")
-	fmt.Fprintln(os.Stderr, "  migratorx plan --plan migration.yaml")
-	fmt.Fprintln(os.Stderr, "  migratorx preflight --plan migration.yaml [--schema-primary path --schema-replica path --cdc-status path]")
-	fmt.Fprintln(os.Stderr, "  migratorx upgrade replica <name> --plan migration.yaml [--state path --simulate --io-running --sql-running]")
-	fmt.Fprintln(os.Stderr, "  migratorx validate replica <name> --plan migration.yaml --schema-primary path --schema-replica path")
-	fmt.Fprintln(os.Stderr, "  migratorx validate primary --plan migration.yaml --schema-primary path --schema-replica path")
-	fmt.Fprintln(os.Stderr, "  migratorx cdc check --plan migration.yaml --cdc-status path")
-	fmt.Fprintln(os.Stderr, "  migratorx promote --plan migration.yaml --confirm PROMOTE [--phrase PROMOTE] --schema-primary path --schema-replica path --cdc-status path")
-	os.Exit(1)
+// thawDDL releases the plan's DDL freeze once promotion has cut over.
+func thawDDL(ctx context.Context, env *env, in inputFlags, plan workflow.MigrationPlan, st workflow.State, simulate bool) Output {
+	freeze, err := ddlFreeze(env, in, plan, st, simulate)
+	if err != nil {
+		return blockOutput(err)
+	}
+	summary, findings, err := freeze.Thaw(ctx)
+	env.Manifest.recordCheck("ddl_thaw", map[string]interface{}{"primary": plan.Topology.Primary, "lock_table": plan.DDLFreeze.LockTable, "simulate": simulate})
+	if err != nil {
+		return blockOutput(err)
+	}
+	return convertMySQLFindings(summary, findings)
 }
 
-func defaultStatePath() string {
-	return filepath.Join(".", ".migratorx", "state.json")
+// ddlFreeze returns the plan's freeze without a schema inspector. The
+// simulated guard keeps the lock table guard's name.
+func ddlFreeze(env *env, in inputFlags, plan workflow.MigrationPlan, st workflow.State, simulate bool) (*mysql.DDLFreeze, error) {
+	var guard mysql.DDLGuard = &simulatedDDLGuard{}
+	if !simulate {
+		if in.AdminDSN == "" {
+			return nil, failure.Config("ddl_freeze needs --admin-dsn to write the lock table on the primary")
+		}
+		guard = &mysql.LockTableGuard{Connect: adminConnector(in, plan), Table: plan.DDLFreeze.LockTable}
+	}
+	return &mysql.DDLFreeze{Guard: guard, State: st, Primary: plan.Topology.Primary, Owner: "migratorx:" + plan.Migration, Logger: env.Logger}, nil
 }
 
-type schemaFileInspector struct {
-	primaryPath string
-	replicaPath string
-	primaryHost string
-	replicaHost string
+// warmBufferPool preloads the promotion candidate's buffer pool with
+// --admin-dsn. --simulate reports the warmup without running it.
+func warmBufferPool(ctx context.Context, env *env, in inputFlags, plan workflow.MigrationPlan, st workflow.State, replicaHost string, simulate bool) Output {
+	if simulate {
+		return Output{Summary: Summary{Info: 1}, Findings: []OutputFinding{{
+			Severity: "INFO",
+			Message:  fmt.Sprintf("buffer pool warmup of %s simulated", replicaHost),
+			Meta:     map[string]interface{}{"replica": replicaHost, "queries": len(plan.Warmup.Queries)},
+		}}}
+	}
+	if in.AdminDSN == "" {
+		return blockOutput(failure.Config("warmup.enabled needs --admin-dsn to warm the candidate's buffer pool"))
+	}
+	warmup := &mysql.BufferPoolWarmup{Connect: adminConnector(in, plan), State: st, Config: plan.Warmup, Logger: env.Logger}
+	summary, findings, err := warmup.Run(ctx, replicaHost)
+	env.Manifest.recordCheck("buffer_pool_warmup", map[string]interface{}{"replica": replicaHost, "queries": len(plan.Warmup.Queries), "timeout": plan.Warmup.Timeout.String()})
+	if err != nil {
+		return blockOutput(err)
+	}
+	return convertMySQLFindings(summary, findings)
 }
 
-func (s *schemaFileInspector) Schema(ctx context.Context, host string) (checks.Schema, error) {
-	var path string
-	if host == "" {
-		return checks.Schema{}, errors.New("host is required")
+// extendBinlogRetention raises the primary's binlog expiry for the upgrade
+// window, checkpointing the original value.
+func extendBinlogRetention(ctx context.Context, env *env, in inputFlags, plan workflow.MigrationPlan, st workflow.State, simulate bool) Output {
+	extension, err := binlogRetentionExtension(env, in, plan, st, simulate)
+	if err != nil {
+		return blockOutput(err)
 	}
-	if s.primaryHost != "" && host == s.primaryHost {
-		path = s.primaryPath
-	} else if s.replicaHost != "" && host == s.replicaHost {
-		path = s.replicaPath
-	} else if s.primaryHost == "" && s.replicaHost == "" {
-		path = s.primaryPath
+	summary, findings, err := extension.Extend(ctx)
+	env.Manifest.recordCheck("binlog_retention_extend", map[string]interface{}{"primary": plan.Topology.Primary, "window": plan.Thresholds.MaintenanceWindow.String(), "margin": plan.BinlogRetention.Margin, "simulate": simulate})
+	if err != nil {
+		return blockOutput(err)
 	}
-	if path == "" {
-		return checks.Schema{}, fmt.Errorf("schema file path required for host %q", host)
+	return convertMySQLFindings(summary, findings)
+}
+
+// restoreBinlogRetention reverts the expiry checkpointed by
+// extendBinlogRetention. Without a state file nothing was extended.
+func restoreBinlogRetention(ctx context.Context, env *env, in inputFlags, plan workflow.MigrationPlan, simulate bool) Output {
+	if _, err := os.Stat(env.Globals.StatePath); err != nil {
+		return Output{}
+	}
+	st, stateFindings, err := env.openState(plan)
+	if err != nil {
+		return blockOutput(err)
+	}
+	if hasBlockFinding(stateFindings) {
+		return prependFindings(Output{}, stateFindings)
+	}
+	extension, err := binlogRetentionExtension(env, in, plan, st, simulate)
+	if err != nil {
+		return blockOutput(err)
 	}
-	b, err := os.ReadFile(path)
+	summary, findings, err := extension.Revert(ctx)
+	env.Manifest.recordCheck("binlog_retention_revert", map[string]interface{}{"primary": plan.Topology.Primary, "simulate": simulate})
 	if err != nil {
-		return checks.Schema{}, err
+		return blockOutput(err)
+	}
+	return convertMySQLFindings(summary, findings)
+}
+
+// binlogRetentionExtension reads and sets the primary's expiry with
+// --admin-dsn. --simulate reads it from --binlog-retention and changes
+// nothing.
+func binlogRetentionExtension(env *env, in inputFlags, plan workflow.MigrationPlan, st workflow.State, simulate bool) (*mysql.BinlogRetentionExtension, error) {
+	extension := &mysql.BinlogRetentionExtension{State: st, Primary: plan.Topology.Primary, Window: plan.Thresholds.MaintenanceWindow, Margin: plan.BinlogRetention.Margin, Logger: env.Logger}
+	switch {
+	case simulate && in.BinlogRetention == "":
+		return nil, failure.Config("binlog_retention.extend needs --binlog-retention with --simulate")
+	case simulate:
+		extension.Inspector = &retentionFileInspector{path: in.BinlogRetention}
+		extension.Setter = &simulatedExpirySetter{}
+	case in.AdminDSN == "":
+		return nil, failure.Config("binlog_retention.extend needs --admin-dsn to change the primary's binlog expiry")
+	default:
+		admin := adminConnector(in, plan)
+		extension.Inspector = &mysql.ServerBinlogRetentionInspector{Connect: admin}
+		extension.Setter = &mysql.ServerBinlogExpirySetter{Connect: admin}
+	}
+	return extension, nil
+}
+
+// adminConnector opens topology members with --admin-dsn at their address,
+// following topology.aliases.
+func adminConnector(in inputFlags, plan workflow.MigrationPlan) mysql.Connector {
+	admin := mysql.AdminDSNConnector(mysqlDriverName, in.AdminDSN)
+	return func(ctx context.Context, host string) (mysql.Querier, error) {
+		return admin(ctx, plan.Topology.Address(host))
+	}
+}
+
+func promotionActions(simulate bool) mysql.PromotionActions {
+	if simulate {
+		return &simulatedActions{}
 	}
-	var schema checks.Schema
-	if err := json.Unmarshal(b, &schema); err != nil {
-		return checks.Schema{}, err
+	return &notConfiguredActions{}
+}
+
+// buildDrainers turns the plan's drain section into drainers. Simulated
+// drainers keep the same names so checkpoints match a real run. ProxySQL
+// drainers keep weights in st and find replicas in mysql_servers under their
+// topology alias.
+func buildDrainers(plan workflow.MigrationPlan, in inputFlags, st workflow.State, simulate bool) ([]mysql.Drainer, error) {
+	drainers := []mysql.Drainer{}
+	var proxysql mysql.Connector
+	for i, d := range plan.Drain {
+		var drainer mysql.Drainer
+		switch d.Type {
+		case "haproxy":
+			drainer = &mysql.HAProxyDrainer{Address: d.Address, Backend: d.Backend, Server: d.Server}
+		case "proxysql":
+			if simulate {
+				drainer = &mysql.ProxySQLDrainer{}
+				break
+			}
+			if in.ProxySQLAdminDSN == "" {
+				return nil, failure.Config("drain[%d]: proxysql needs --proxysql-admin-dsn to reach the admin interface at %s", i, d.Address)
+			}
+			if proxysql == nil {
+				proxysql = mysql.AdminDSNConnector(mysqlDriverName, in.ProxySQLAdminDSN)
+			}
+			drainer = &mysql.ProxySQLDrainer{Admin: &connectorQuerier{connect: proxysql, host: d.Address}, State: st, Hostnames: plan.Topology.Aliases}
+		case "command":
+			drainer = &mysql.CommandDrainer{Label: d.Name, DrainCommand: d.DrainCommand, UndrainCommand: d.UndrainCommand}
+		default:
+			continue
+		}
+		if simulate {
+			drainer = &simulatedDrainer{name: drainer.Name()}
+		}
+		drainers = append(drainers, drainer)
 	}
-	return schema, nil
+	return drainers, nil
 }
 
-type debeziumFileInspector struct {
-	path string
+// connectorQuerier opens host through connect on first use, so a drainer
+// built for every run connects only when a replica is actually drained.
+type connectorQuerier struct {
+	connect mysql.Connector
+	host    string
 }
 
-func (d *debeziumFileInspector) ConnectorStatus(ctx context.Context, connector string) (cdc.ConnectorStatus, error) {
-	if d.path == "" {
-		return cdc.ConnectorStatus{}, fmt.Errorf("cdc status file path is required")
+func (q *connectorQuerier) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	conn, err := q.connect(ctx, q.host)
+	if err != nil {
+		return nil, err
 	}
-	b, err := os.ReadFile(d.path)
+	return conn.QueryContext(ctx, query, args...)
+}
+
+func (q *connectorQuerier) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	conn, err := q.connect(ctx, q.host)
 	if err != nil {
-		return cdc.ConnectorStatus{}, err
+		return nil, err
 	}
-	var status cdc.ConnectorStatus
-	if err := json.Unmarshal(b, &status); err != nil {
-		return cdc.ConnectorStatus{}, err
+	return conn.ExecContext(ctx, query, args...)
+}
+
+// cdcInputChecks returns the optional CDC checks whose input files are set.
+func cdcInputChecks(in inputFlags, plan workflow.MigrationPlan) []checks.PreflightCheck {
+	checksList := []checks.PreflightCheck{}
+	if in.CDCPlugins != "" {
+		checksList = append(checksList, &cdc.ConnectorVersionCheck{Inspector: &pluginsFileInspector{path: in.CDCPlugins}})
 	}
-	if status.Name == "" {
-		status.Name = connector
+	if in.KafkaAccess != "" {
+		checksList = append(checksList, &cdc.KafkaAccessCheck{
+			Inspector:          &kafkaAccessFileInspector{path: in.KafkaAccess},
+			Principal:          plan.CDC.Principal,
+			TopicPrefix:        plan.CDC.TopicPrefix,
+			SchemaHistoryTopic: plan.CDC.SchemaHistoryTopic,
+			PeakByteRate:       plan.CDC.PeakByteRate,
+		})
 	}
-	return status, nil
+	return checksList
 }
 
-type staticReplicaInspector struct {
-	isPrimary bool
-	status    mysql.ReplicationStatus
+// buildChecks assembles the file-backed checks; rec, when set, records every
+// inspector response as a fixture.
+func buildChecks(rec *fixtureRecorder, in inputFlags, primaryHost string, replicaHost string, plan workflow.MigrationPlan) []checks.PreflightCheck {
+	checksList := []checks.PreflightCheck{}
+	live := liveConnector(in, plan, replicaHost)
+	checksList = append(checksList, buildSchemaParityCheck(rec, in, live, primaryHost, replicaHost))
+	if in.PrimarySchema != "" || in.SchemaDSN != "" {
+		checksList = append(checksList, &checks.ReservedWordCheck{
+			Inspector: rec.schemaInspector(schemaInputInspector(in, live, primaryHost, replicaHost)),
+			Host:      primaryHost,
+		})
+	}
+	if in.AutoIncrements != "" || in.SchemaDSN != "" {
+		var inspector mysql.AutoIncrementInspector = &autoIncrementFileInspector{path: in.AutoIncrements}
+		if in.AutoIncrements == "" {
+			inspector = &mysql.InformationSchemaInspector{Connect: live, Database: in.SchemaDatabase}
+		}
+		checksList = append(checksList, &mysql.AutoIncrementCheck{Inspector: inspector, Primary: primaryHost, Replica: replicaHost})
+	}
+	if in.Fulltext != "" || in.SchemaDSN != "" {
+		var inspector mysql.FulltextInspector = &fulltextFileInspector{path: in.Fulltext}
+		if in.Fulltext == "" {
+			inspector = &mysql.ServerFulltextInspector{Connect: live}
+		}
+		checksList = append(checksList, &mysql.FulltextCheck{
+			Inspector:       inspector,
+			SchemaInspector: rec.schemaInspector(schemaInputInspector(in, live, primaryHost, replicaHost)),
+			Primary:         primaryHost,
+			Replica:         replicaHost,
+		})
+	}
+	if in.Collations != "" || in.SchemaDSN != "" {
+		var inspector mysql.CollationDefaultsInspector = &collationsFileInspector{path: in.Collations}
+		if in.Collations == "" {
+			inspector = &mysql.ServerCollationInspector{Connect: live}
+		}
+		checksList = append(checksList, &mysql.CollationDefaultsCheck{
+			Inspector:       inspector,
+			SchemaInspector: rec.schemaInspector(schemaInputInspector(in, live, primaryHost, replicaHost)),
+			Primary:         primaryHost,
+			Replica:         replicaHost,
+		})
+	}
+	if len(plan.DataParity.Tables) > 0 {
+		checksList = append(checksList, buildDataParityCheck(in, live, plan, primaryHost, replicaHost, nil))
+	}
+	checksList = append(checksList, buildDebeziumCheck(rec, in.CDCStatus, plan))
+	checksList = append(checksList, cdcInputChecks(in, plan)...)
+	if in.ReplicationStatus != "" && plan.LagLimit(replicaHost) > 0 {
+		inspector := &timelineReplicaInspector{path: func(string) string { return in.ReplicationStatus }, primary: primaryHost}
+		lagCheck := &mysql.ReplicaLagCheck{
+			Inspector: rec.replicaInspector(inspector),
+			Replica:   replicaHost,
+			MaxLag:    plan.LagLimit(replicaHost),
+		}
+		if plan.Topology.CrossRegion(replicaHost) && plan.Thresholds.MaxCrossRegionLag > 0 {
+			lagCheck.ThresholdName = "max_cross_region_lag"
+		}
+		checksList = append(checksList, lagCheck)
+	}
+	if in.ReplicationTLS != "" {
+		checksList = append(checksList, &mysql.ReplicationTLSCheck{
+			Inspector:  &tlsFileInspector{path: in.ReplicationTLS},
+			Replica:    replicaHost,
+			RequireTLS: plan.Replication.RequireTLS,
+		})
+	}
+	if in.Security != "" && len(plan.Clients) > 0 {
+		checksList = append(checksList, &mysql.ClientCompatibilityCheck{
+			Inspector: &securityFileInspector{path: in.Security},
+			Host:      replicaHost,
+			Clients:   plan.Clients,
+		})
+	}
+	if in.Connections != "" {
+		checksList = append(checksList, &mysql.ConnectedApplicationsCheck{
+			Inspector: &connectionsFileInspector{path: in.Connections},
+			Hosts:     append([]string{primaryHost}, plan.Topology.Replicas...),
+			Clients:   plan.Clients,
+		})
+	}
+	if in.ProxySQL != "" {
+		checksList = append(checksList, &mysql.ProxySQLRoutingCheck{
+			Inspector: &proxysqlFileInspector{path: in.ProxySQL},
+			Primary:   primaryHost,
+			Target:    replicaHost,
+		})
+	}
+	if in.BufferPool != "" && plan.Warmup.MinHitRatio > 0 {
+		checksList = append(checksList, &mysql.BufferPoolHitRatioCheck{
+			Inspector:      &bufferPoolFileInspector{path: in.BufferPool},
+			Replica:        replicaHost,
+			MinHitRatio:    plan.Warmup.MinHitRatio,
+			SampleInterval: plan.Warmup.SampleInterval,
+		})
+	}
+	if in.BinlogRetention != "" && plan.Thresholds.MaintenanceWindow > 0 {
+		checksList = append(checksList, &mysql.BinlogRetentionCheck{
+			Inspector: &retentionFileInspector{path: in.BinlogRetention},
+			Host:      primaryHost,
+			Window:    plan.Thresholds.MaintenanceWindow,
+		})
+	}
+	if in.ServerIdentity != "" {
+		checksList = append(checksList, &mysql.ServerIdentityCheck{
+			Inspector:   &identityFileInspector{path: in.ServerIdentity},
+			Hosts:       append([]string{primaryHost}, plan.Topology.Replicas...),
+			Expected:    plan.ServerIdentity,
+			CDCServerID: plan.CDC.ServerID,
+		})
+	}
+	if in.ClockOffsets != "" {
+		checksList = append(checksList, &checks.ClockSkewCheck{
+			Inspector: &clockOffsetsFileInspector{path: in.ClockOffsets},
+			Hosts:     append(append([]string{primaryHost}, plan.Topology.Replicas...), plan.CDC.ConnectWorkers...),
+			MaxSkew:   plan.Thresholds.MaxClockSkew,
+		})
+	}
+	if in.OptionFile != "" {
+		checksList = append(checksList, &mysql.RemovedVariablesCheck{
+			Inspector: &mysql.OptionFileInspector{Paths: []string{in.OptionFile}},
+			Host:      replicaHost,
+		})
+	}
+	if in.Datadir != "" {
+		checksList = append(checksList,
+			&mysql.OrphanTableCheck{Inspector: &mysql.DatadirOrphanInspector{Datadir: in.Datadir}, Host: replicaHost},
+			&mysql.DictionaryReadinessCheck{Datadir: in.Datadir, Host: replicaHost},
+		)
+	}
+	if in.CDCOffsets != "" {
+		checksList = append(checksList, &cdc.OffsetSnapshotCheck{
+			Inspector:   rec.offsetInspector(&offsetsFileInspector{path: in.CDCOffsets}),
+			Connector:   plan.CDC.Connector,
+			PrimaryHost: primaryHost,
+		})
+	}
+	return checksList
 }
 
-func (s *staticReplicaInspector) IsPrimary(ctx context.Context, host string) (bool, error) {
-	return s.isPrimary, nil
+// skipMissingInputs replaces checks that buildChecks always adds, but whose
+// input files were not provided, with a check that reports a WARN instead of
+// failing on the missing path.
+func skipMissingInputs(checksList []checks.PreflightCheck, in inputFlags) []checks.PreflightCheck {
+	required := map[string][]struct{ flag, value string }{
+		"schema_parity":       {{"--schema-primary", in.PrimarySchema}, {"--schema-replica", in.ReplicaSchema}},
+		"cdc_debezium_health": {{"--cdc-status", in.CDCStatus}},
+		"data_parity":         {{"--schema-dsn", in.SchemaDSN}},
+		"old_primary_soak":    {{"--schema-dsn", in.SchemaDSN}},
+	}
+	if in.SchemaDSN != "" {
+		delete(required, "schema_parity")
+	}
+	out := make([]checks.PreflightCheck, 0, len(checksList))
+	for _, c := range checksList {
+		missing := []string{}
+		for _, input := range required[c.Name()] {
+			if input.value == "" {
+				missing = append(missing, input.flag)
+			}
+		}
+		if len(missing) == 0 {
+			out = append(out, c)
+			continue
+		}
+		name := c.Name()
+		out = append(out, checks.NewReadOnlyCheck(name, func(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+			return []checks.Finding{{
+				Severity: checks.SeverityWarn,
+				Code:     codeCheckSkipped,
+				Message:  fmt.Sprintf("check skipped: input not provided (%s)", strings.Join(missing, ", ")),
+				Meta:     map[string]interface{}{"check": name, "missing": missing},
+			}}, nil
+		}))
+	}
+	return out
 }
 
-func (s *staticReplicaInspector) ReplicationStatus(ctx context.Context, replica string) (mysql.ReplicationStatus, error) {
-	return s.status, nil
+// mysqlDriverName is the database/sql driver --schema-dsn opens, registered
+// by the github.com/go-sql-driver/mysql import.
+const mysqlDriverName = "mysql"
+
+// liveConnector opens topology members with --schema-dsn at their address,
+// following topology.aliases. Every check of a run shares its sessions, and
+// each host's session is throttled by the plan's inspection limits; with
+// max_replica_lag set, statements pause while replicaHost lags. It returns nil
+// without --schema-dsn.
+func liveConnector(in inputFlags, plan workflow.MigrationPlan, replicaHost string) mysql.Connector {
+	if in.SchemaDSN == "" {
+		return nil
+	}
+	dsn := mysql.DSNConnector(mysqlDriverName, in.SchemaDSN)
+	connect := func(ctx context.Context, host string) (mysql.Querier, error) {
+		return dsn(ctx, plan.Topology.Address(host))
+	}
+	var lag func(ctx context.Context) (time.Duration, error)
+	if plan.Inspection.MaxReplicaLag > 0 && replicaHost != "" {
+		inspector := &mysql.PerformanceSchemaInspector{Connect: connect}
+		lag = func(ctx context.Context) (time.Duration, error) {
+			status, err := inspector.ReplicationStatus(ctx, replicaHost)
+			if err != nil {
+				return 0, err
+			}
+			lag, ok := status.MaxApplierLag()
+			if !ok {
+				return 0, fmt.Errorf("replica %q does not report applier lag", replicaHost)
+			}
+			return lag, nil
+		}
+	}
+	throttle := mysql.ThrottleFromPlan(plan.Inspection, lag, nil)
+	var mu sync.Mutex
+	sessions := map[string]mysql.Querier{}
+	return func(ctx context.Context, host string) (mysql.Querier, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if q, ok := sessions[host]; ok {
+			return q, nil
+		}
+		q, err := connect(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		sessions[host] = mysql.NewThrottledQuerier(q, throttle)
+		return sessions[host], nil
+	}
 }
 
-type notConfiguredActions struct{}
+// buildSchemaParityCheck reads both schemas from information_schema when
+// --schema-dsn is set, and from the --schema-primary/--schema-replica files
+// otherwise.
+func buildSchemaParityCheck(rec *fixtureRecorder, in inputFlags, live mysql.Connector, primaryHost string, replicaHost string) checks.PreflightCheck {
+	return &checks.SchemaParityCheck{
+		Inspector:   rec.schemaInspector(schemaInputInspector(in, live, primaryHost, replicaHost)),
+		PrimaryHost: primaryHost,
+		ReplicaHost: replicaHost,
+	}
+}
 
-func (n *notConfiguredActions) StopReplication(ctx context.Context, replica string) error {
-	return fmt.Errorf("replica actions not configured; use --simulate or provide implementation")
+// schemaInputInspector reads schemas live through the liveConnector with
+// --schema-dsn, or from the --schema-primary/--schema-replica files.
+func schemaInputInspector(in inputFlags, live mysql.Connector, primaryHost string, replicaHost string) checks.SchemaInspector {
+	if in.SchemaDSN != "" {
+		return &mysql.InformationSchemaInspector{Connect: live, Database: in.SchemaDatabase}
+	}
+	return &schemaFileInspector{primaryPath: in.PrimarySchema, replicaPath: in.ReplicaSchema, primaryHost: primaryHost, replicaHost: replicaHost}
 }
 
-func (n *notConfiguredActions) RunUpgrade(ctx context.Context, replica string) error {
-	return fmt.Errorf("replica actions not configured; use --simulate or provide implementation")
+func buildDebeziumCheck(rec *fixtureRecorder, statusPath string, plan workflow.MigrationPlan) checks.PreflightCheck {
+	return &cdc.DebeziumHealthCheck{
+		Inspector:  rec.debeziumInspector(&debeziumFileInspector{path: statusPath}),
+		Connector:  plan.CDC.Connector,
+		MaxLatency: plan.Thresholds.MaxCDCLatency,
+	}
 }
 
-func (n *notConfiguredActions) StartReplication(ctx context.Context, replica string) error {
-	return fmt.Errorf("replica actions not configured; use --simulate or provide implementation")
+// buildMessageSchemaCheck compares the message schemas in path with the
+// baseline stored for the migration, or captures one when there is none or
+// recapture is set. It checks the plan's cdc.key_tables, or every table in
+// the file.
+func buildMessageSchemaCheck(backend state.Backend, path string, plan workflow.MigrationPlan, recapture bool) (checks.PreflightCheck, error) {
+	inspector := &messageSchemasFileInspector{path: path}
+	tables := plan.CDC.KeyTables
+	if len(tables) == 0 {
+		var err error
+		if tables, err = inspector.tables(); err != nil {
+			return nil, failure.Config("read --message-schemas: %v", err)
+		}
+	}
+	check := &cdc.MessageSchemaCheck{Inspector: inspector, Tables: tables}
+	if stored, ok := backend.Get(messageSchemaKey(plan.StateName())); ok && !recapture {
+		// Values read back from the state file are generic JSON.
+		b, err := json.Marshal(stored)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(b, &check.Baseline); err != nil {
+			return nil, failure.Config("stored message schema baseline is unreadable; re-run with --recapture-message-schemas: %v", err)
+		}
+	}
+	return check, nil
 }
 
-type simulatedActions struct{}
+// messageSchemaKey stores the CDC message schemas captured before the upgrade.
+func messageSchemaKey(migration string) string {
+	return fmt.Sprintf("migration:%s:cdc_message_schemas", migration)
+}
 
-func (s *simulatedActions) StopReplication(ctx context.Context, replica string) error  { return nil }
-func (s *simulatedActions) RunUpgrade(ctx context.Context, replica string) error       { return nil }
-func (s *simulatedActions) StartReplication(ctx context.Context, replica string) error { return nil }
+// buildThroughputCheck compares the connector metrics in path with the
+// throughput baseline stored for the migration, or captures one when there is
+// none or recapture is set.
+func buildThroughputCheck(backend state.Backend, path string, plan workflow.MigrationPlan, recapture bool) (checks.PreflightCheck, error) {
+	check := &cdc.ThroughputCheck{Inspector: &throughputFileInspector{path: path}, Connector: plan.CDC.Connector, MaxDrop: plan.CDC.MaxThroughputDrop}
+	if stored, ok := backend.Get(throughputKey(plan.StateName())); ok && !recapture {
+		b, err := json.Marshal(stored)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(b, &check.Baseline); err != nil {
+			return nil, failure.Config("stored throughput baseline is unreadable; re-run with --recapture-throughput: %v", err)
+		}
+	}
+	return check, nil
+}
+
+// throughputKey stores the connector throughput captured before the replica
+// upgrade.
+func throughputKey(migration string) string {
+	return fmt.Sprintf("migration:%s:cdc_throughput", migration)
+}
 
+func defaultStatePath() string {
+	return filepath.Join(".", ".migratorx", "state.json")
+}
+
+// selectReplica returns the replica to validate and promote: the first one
+// the topology allows to be promoted.
 func selectReplica(plan workflow.MigrationPlan) (string, error) {
 	if len(plan.Topology.Replicas) == 0 {
 		return "", fmt.Errorf("no replicas defined in plan")
 	}
-	return plan.Topology.Replicas[0], nil
+	candidates := plan.Topology.PromotionCandidates()
+	if len(candidates) == 0 {
+		return "", failure.Config("every replica is outside the primary's region %q; tag the one to promote in topology.promotable", plan.Topology.Region(plan.Topology.Primary))
+	}
+	return candidates[0], nil
 }
 
 func planInput(plan workflow.MigrationPlan, replicaHost string) checks.Input {
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"migratorx/internal/access"
+	"migratorx/internal/cdc"
+	"migratorx/internal/checks"
+	"migratorx/internal/workflow"
+)
+
+// runManifest is a reproducible record of what a command evaluated.
+type runManifest struct {
+	Command     string            `json:"command"`
+	Args        []string          `json:"args,omitempty"`
+	StartedAt   *time.Time        `json:"started_at,omitempty"`
+	PlanPath    string            `json:"plan_path"`
+	PlanHash    string            `json:"plan_hash,omitempty"`
+	Identity    *access.Identity  `json:"identity,omitempty"`
+	Migration   string            `json:"migration,omitempty"`
+	Environment string            `json:"environment,omitempty"`
+	Topology    *manifestTopology `json:"topology,omitempty"`
+	Versions    *manifestVersions `json:"versions,omitempty"`
+	Checks      []manifestCheck   `json:"checks"`
+	Calls       []manifestCall    `json:"calls,omitempty"`
+	Summary     Summary           `json:"summary"`
+	Findings    []OutputFinding   `json:"findings"`
+
+	deterministic bool
+	// mu guards Calls and Versions, recorded from concurrent probes and
+	// replica upgrades.
+	mu *sync.Mutex
+}
+
+// manifestTopology is the topology as the run reached it: each member, the
+// address it was dialed at and, when the run resolved DNS, the IPs.
+type manifestTopology struct {
+	Hosts []manifestHost `json:"hosts"`
+	Note  string         `json:"note,omitempty"`
+}
+
+type manifestHost struct {
+	Name    string   `json:"name"`
+	Role    string   `json:"role"`
+	Address string   `json:"address"`
+	Aliased bool     `json:"aliased,omitempty"`
+	IPs     []string `json:"ips,omitempty"`
+}
+
+// manifestVersions maps each host to the server version it reported.
+type manifestVersions struct {
+	Observed map[string]string `json:"observed"`
+	Note     string            `json:"note,omitempty"`
+}
+
+// Manifest notes for what a command did not observe.
+const (
+	noteNotResolved    = "host names were not resolved; set topology.resolve_dns to record their IPs"
+	noteNoVersionsRead = "no server versions were read by this command"
+)
+
+// manifestCall audits one outbound API call. ClientID is what the external
+// system logged for it.
+type manifestCall struct {
+	At       *time.Time `json:"at,omitempty"`
+	System   string     `json:"system"`
+	Method   string     `json:"method"`
+	URL      string     `json:"url"`
+	ClientID string     `json:"client_id"`
+	Status   int        `json:"status,omitempty"`
+	Error    string     `json:"error,omitempty"`
+	Duration string     `json:"duration,omitempty"`
+}
+
+type manifestCheck struct {
+	Name       string                 `json:"name"`
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+	Summary    *Summary               `json:"summary,omitempty"`
+	Artifacts  []checks.ArtifactRef   `json:"artifacts,omitempty"`
+}
+
+// newRunManifest starts a manifest; deterministic manifests omit the start time.
+func newRunManifest(command string, args []string, planPath string, deterministic bool) *runManifest {
+	m := &runManifest{Command: command, Args: args, PlanPath: planPath, Checks: []manifestCheck{}, deterministic: deterministic, mu: &sync.Mutex{}}
+	if !deterministic {
+		now := time.Now().UTC()
+		m.StartedAt = &now
+	}
+	return m
+}
+
+func (m *runManifest) recordPlan(plan workflow.MigrationPlan, hash string) {
+	m.PlanHash = hash
+	m.Migration = plan.Migration
+	m.Environment = plan.SelectedEnvironment
+	m.Topology = &manifestTopology{Hosts: []manifestHost{}}
+	for _, host := range plan.Topology.Members() {
+		role := "replica"
+		if host == plan.Topology.Primary {
+			role = "primary"
+		}
+		address := plan.Topology.Address(host)
+		m.Topology.Hosts = append(m.Topology.Hosts, manifestHost{Name: host, Role: role, Address: address, Aliased: address != host})
+	}
+	m.Versions = &manifestVersions{Observed: map[string]string{}}
+}
+
+// recordResolution records the IPs host resolved to.
+func (m *runManifest) recordResolution(host string, ips []string) {
+	if m.Topology == nil {
+		return
+	}
+	for i := range m.Topology.Hosts {
+		if m.Topology.Hosts[i].Name == host {
+			m.Topology.Hosts[i].IPs = ips
+		}
+	}
+}
+
+// recordVersion records the server version host reported.
+func (m *runManifest) recordVersion(host string, version string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Versions == nil {
+		m.Versions = &manifestVersions{Observed: map[string]string{}}
+	}
+	m.Versions.Observed[host] = version
+}
+
+func (m *runManifest) recordChecks(checksList []checks.PreflightCheck) {
+	for _, c := range checksList {
+		entry := manifestCheck{Name: c.Name()}
+		if p, ok := c.(checks.ParameterizedCheck); ok {
+			entry.Parameters = p.Parameters()
+		}
+		m.Checks = append(m.Checks, entry)
+	}
+}
+
+// recordResults attaches each check's finding counts and stored artifacts to
+// its recorded entry.
+func (m *runManifest) recordResults(results []checks.Result) {
+	for _, r := range results {
+		var summary Summary
+		for _, f := range r.Findings {
+			switch f.Severity {
+			case checks.SeverityInfo:
+				summary.Info++
+			case checks.SeverityWarn:
+				summary.Warn++
+			case checks.SeverityBlock:
+				summary.Block++
+			}
+		}
+		for i := range m.Checks {
+			if m.Checks[i].Name == r.CheckName {
+				counts := summary
+				m.Checks[i].Summary = &counts
+				m.Checks[i].Artifacts = r.Artifacts
+			}
+		}
+	}
+}
+
+func (m *runManifest) recordCheck(name string, params map[string]interface{}) {
+	m.Checks = append(m.Checks, manifestCheck{Name: name, Parameters: params})
+}
+
+// recordCall audits an outbound call on a manifest from newRunManifest. Calls
+// may come from concurrent probes. Deterministic manifests omit the time and
+// duration.
+func (m *runManifest) recordCall(call cdc.OutboundCall) {
+	entry := manifestCall{System: call.System, Method: call.Method, URL: call.URL, ClientID: call.ClientID, Status: call.Status, Error: call.Error}
+	if !m.deterministic {
+		at := call.At
+		entry.At = &at
+		entry.Duration = call.Duration.String()
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Calls = append(m.Calls, entry)
+}
+
+func (m *runManifest) write(path string, output Output) error {
+	m.Summary = output.Summary
+	m.Findings = output.Findings
+	if m.Topology != nil {
+		m.Topology.Note = noteNotResolved
+		for _, host := range m.Topology.Hosts {
+			if len(host.IPs) > 0 {
+				m.Topology.Note = ""
+			}
+		}
+	}
+	if m.Versions != nil && len(m.Versions.Observed) == 0 {
+		m.Versions.Note = noteNoVersionsRead
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"migratorx/internal/access"
+	"migratorx/internal/mysql"
+	"migratorx/internal/workflow"
+)
+
+// commandNotifier runs the plan's notifications.command with each
+// notification as JSON on stdin.
+type commandNotifier struct {
+	argv []string
+}
+
+func (n *commandNotifier) Notify(ctx context.Context, note workflow.Notification) error {
+	if len(n.argv) == 0 {
+		return nil
+	}
+	payload, err := json.Marshal(note)
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, n.argv[0], n.argv[1:]...)
+	cmd.Stdin = bytes.NewReader(payload)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s: %v: %s", n.argv[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// notify sends a notification and reports a failed delivery as a WARN finding;
+// a notification never changes the outcome of the action it reports.
+func notify(ctx context.Context, notifier workflow.Notifier, note workflow.Notification) []OutputFinding {
+	if err := notifier.Notify(ctx, note); err != nil {
+		return []OutputFinding{{
+			Severity: "WARN",
+			Code:     codeNotificationFailed,
+			Message:  fmt.Sprintf("notification %s failed: %v", note.Event, err),
+			Meta:     map[string]interface{}{"event": note.Event},
+		}}
+	}
+	return nil
+}
+
+// autoRollback runs the promotion rollback after post-validation blocked and
+// the plan opts in with post_validation.on_block: auto_rollback. The rollback
+// is an approver action, so the caller's token is re-authorized for it.
+func autoRollback(ctx context.Context, env *env, plan workflow.MigrationPlan, replicaHost string, simulate bool, validation Output) Output {
+	if validation.Summary.Block == 0 || plan.PostValidation.OnBlock != workflow.OnBlockAutoRollback {
+		return validation
+	}
+	env.Role = access.RoleApprover
+	if err := env.authorize(plan); err != nil {
+		return prependFindings(blockOutput(fmt.Errorf("auto rollback not authorized: %w", err)), validation.Findings)
+	}
+	st, stateFindings, err := env.openState(plan)
+	if err != nil {
+		return prependFindings(blockOutput(err), validation.Findings)
+	}
+	if hasBlockFinding(stateFindings) {
+		return prependFindings(Output{}, append(validation.Findings, stateFindings...))
+	}
+	env.Logger.Printf("post-validation blocked; rolling back promotion of %s", replicaHost)
+	orchestrator := mysql.NewPromotionOrchestrator(promotionActions(simulate), st, plan.Topology.Primary, env.Logger)
+	summary, findings, err := orchestrator.Rollback(ctx, replicaHost)
+	env.Manifest.recordCheck("promotion_rollback", map[string]interface{}{"replica": replicaHost, "simulate": simulate, "trigger": "post_validation"})
+	if err != nil {
+		return prependFindings(blockOutput(err), append(validation.Findings, stateFindings...))
+	}
+	output := prependFindings(convertMySQLFindings(summary, findings), append(validation.Findings, stateFindings...))
+	note := workflow.Notification{
+		Migration: plan.Migration,
+		Event:     workflow.EventAutoRollback,
+		Message:   fmt.Sprintf("post-validation blocked after promoting %s; promotion rolled back to %s", replicaHost, plan.Topology.Primary),
+		Meta:      map[string]interface{}{"replica": replicaHost, "primary": plan.Topology.Primary, "blocking": validation.Summary.Block, "simulate": simulate},
+	}
+	return prependFindings(output, notify(ctx, &commandNotifier{argv: plan.Notifications.Command}, note))
+}
@@ -0,0 +1,429 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"time"
+
+	"migratorx/internal/checks"
+	"migratorx/internal/failure"
+	"migratorx/internal/mysql"
+	"migratorx/internal/remediation"
+	"migratorx/internal/workflow"
+)
+
+const (
+	formatJSON = "json"
+	formatText = "text"
+)
+
+// Finding codes emitted by the CLI itself.
+const (
+	codeStateForeignPlan   = "STATE_FOREIGN_PLAN"
+	codePlanChanged        = "STATE_PLAN_CHANGED"
+	codeNotificationFailed = "NOTIFICATION_FAILED"
+	codeEnvironmentRun     = "ENVIRONMENT_RUN_RECORDED"
+	codeReportComment      = "REPORT_COMMENT_POSTED"
+	codeReportFailed       = "REPORT_COMMENT_FAILED"
+	codeReportWritten      = "REPORT_WRITTEN"
+	codeReportWriteFailed  = "REPORT_WRITE_FAILED"
+	codeRunInterrupted     = "RUN_INTERRUPTED"
+	codeFleetNoManifests   = "FLEET_NO_MANIFESTS"
+	codeFleetClusterReady  = "FLEET_CLUSTER_READY"
+	codeFleetClusterWarn   = "FLEET_CLUSTER_WARN"
+	codeFleetClusterBlock  = "FLEET_CLUSTER_BLOCKED"
+	codeCheckSkipped       = "CHECK_SKIPPED_INPUT_MISSING"
+	codeRunCompleted       = "STATE_RUN_COMPLETED_RECORDED"
+	codeStateRunCompleted  = "STATE_RUN_COMPLETED"
+	codeStateRunArchived   = "STATE_RUN_ARCHIVED"
+	codeStateArchiveFailed = "STATE_ARCHIVE_FAILED"
+	codeStateNothingToGC   = "STATE_GC_NOTHING_TO_COLLECT"
+	codeStateWriteFailed   = "STATE_WRITE_FAILED"
+	codeConfigRendered     = "CONFIG_RENDERED"
+	codeDepsReport         = "DEPS_REPORT"
+	codeDepsEndpoint       = "DEPS_ENDPOINT"
+	codeDurationAnomaly    = "STEP_DURATION_ANOMALY"
+	codeStepIdempotent     = "STEP_IDEMPOTENT"
+	codeStepNotIdempotent  = "STEP_NOT_IDEMPOTENT"
+
+	codeDoctorHostUnreachable    = "DOCTOR_HOST_UNREACHABLE"
+	codeDoctorConnectUnreachable = "DOCTOR_CONNECT_UNREACHABLE"
+	codeDoctorKafkaUnreachable   = "DOCTOR_KAFKA_UNREACHABLE"
+	codeDoctorStateUnwritable    = "DOCTOR_STATE_UNWRITABLE"
+	codeDoctorCredentials        = "DOCTOR_CREDENTIALS_INVALID"
+	codeDoctorClockSkew          = "DOCTOR_CLOCK_SKEW"
+)
+
+// cliRemediation extends the remediation catalog with the CLI's own codes.
+var cliRemediation = map[string]string{
+	codeStateForeignPlan:         "Use a separate --state file per migration, or confirm the shared file is intended.",
+	codeNotificationFailed:       "Check notifications.command in the plan and notify on-call manually; the reported action already happened.",
+	codeReportFailed:             "Check the --report-comment URL and that GITHUB_TOKEN or GITLAB_TOKEN can comment on it; the run itself is unaffected.",
+	codeRunInterrupted:           "Re-run the same command with the same --state file; completed steps are skipped and the run resumes where it stopped.",
+	codeReportWriteFailed:        "Check the --output destination and, for s3:// URLs, the AWS credentials and region in the environment; the run itself is unaffected.",
+	codeFleetNoManifests:         "Run preflight with --manifest for each cluster and point --manifests at the directory holding them.",
+	codeFleetClusterWarn:         "Open the cluster's manifest and review its WARN findings before promoting.",
+	codeFleetClusterBlock:        "Open the cluster's manifest and resolve its BLOCK findings; re-run preflight with --manifest to refresh the report.",
+	codeStepNotIdempotent:        "Make the step check its State checkpoint (or the target's current state) before each action, so a resumed run skips what already happened; the meta lists the repeated actions.",
+	codeDurationAnomaly:          "Compare the step's progress log against earlier runs; the notification fired while it was still running.",
+	codeDoctorHostUnreachable:    "Check DNS, firewall rules and --mysql-port from the operator host to the listed MySQL host.",
+	codeDoctorConnectUnreachable: "Check --connect-url and that the Kafka Connect REST listener is reachable from the operator host.",
+	codeDoctorKafkaUnreachable:   "Check --kafka-brokers and the firewall between the operator host and the brokers.",
+	codeDoctorStateUnwritable:    "Point --state at a file in a directory the operator can write, or fix its permissions.",
+	codeDoctorCredentials:        "Refresh the credential named in meta.credential; for the identity token, ask an admin for a token in the plan's tokens file.",
+	codeDoctorClockSkew:          "Sync the operator host and Connect workers with NTP; skew distorts lag and event timestamps.",
+	codeCheckSkipped:             "Pass the listed input files to cover this check; use --strict to make missing inputs block.",
+	codeStateArchiveFailed:       "Check that the --archive file's directory is writable and that no migratorx command holds its .lock file, then re-run state gc; nothing was removed for this run.",
+	codeStateRunCompleted:        "Start a new migration with a fresh --run-id, or archive the finished run with \"migratorx state gc\".",
+	codeStateWriteFailed:         "Check that the --state directory is writable and that no stuck migratorx command holds the .lock file, then re-run the command; its checkpoints were not saved.",
+	codePlanChanged:              "Review the plan diff; re-run with --accept-plan-change if the change is intended, or restore the original plan.",
+}
+
+type Output struct {
+	Summary  Summary         `json:"summary"`
+	Findings []OutputFinding `json:"findings"`
+}
+
+type Summary struct {
+	Info  int `json:"info"`
+	Warn  int `json:"warn"`
+	Block int `json:"block"`
+}
+
+type OutputFinding struct {
+	Severity    string                 `json:"severity"`
+	Code        string                 `json:"code,omitempty"`
+	Message     string                 `json:"message"`
+	Remediation string                 `json:"remediation,omitempty"`
+	Meta        map[string]interface{} `json:"meta,omitempty"`
+}
+
+// blockOutput renders an error as a single BLOCK finding. A typed failure is
+// named in the finding's "cause" meta, which also selects the exit code.
+func blockOutput(err error) Output {
+	finding := OutputFinding{Severity: "BLOCK", Message: err.Error()}
+	if kind := failure.KindOf(err); kind != "" {
+		finding.Meta = map[string]interface{}{"cause": string(kind)}
+		if target := failure.Target(err); target != "" {
+			finding.Meta["target"] = target
+		}
+	}
+	return Output{Summary: Summary{Block: 1}, Findings: []OutputFinding{finding}}
+}
+
+// interruptedFinding reports a run stopped by SIGINT or SIGTERM before it
+// finished.
+func interruptedFinding(statePath string) OutputFinding {
+	return OutputFinding{
+		Severity: "BLOCK",
+		Code:     codeRunInterrupted,
+		Message:  fmt.Sprintf("run interrupted before it finished; completed steps are checkpointed in %q", statePath),
+		Meta:     map[string]interface{}{"state": statePath},
+	}
+}
+
+// Exit codes. A command that ran and reported findings exits 0 even when a
+// finding blocks; non-zero codes mean it could not finish.
+const (
+	exitOK        = 0
+	exitFailed    = 1
+	exitUsage     = 2
+	exitConfig    = 3
+	exitInspector = 4
+	exitAction    = 5
+	// exitInterrupted follows the shell convention for a process stopped by
+	// SIGINT (128+2).
+	exitInterrupted = 130
+)
+
+// exitCode maps the cause of the first BLOCK finding that has one to an exit
+// code: misconfiguration, a target system that could not be read, or one that
+// could not be changed.
+func exitCode(output Output) int {
+	for _, f := range output.Findings {
+		if f.Severity != "BLOCK" {
+			continue
+		}
+		cause, _ := f.Meta["cause"].(string)
+		switch failure.Kind(cause) {
+		case failure.KindConfig:
+			return exitConfig
+		case failure.KindInspector:
+			return exitInspector
+		case failure.KindAction:
+			return exitAction
+		}
+	}
+	return exitOK
+}
+
+func convertCheckResults(summary checks.Summary, results []checks.Result) Output {
+	findings := []OutputFinding{}
+	for _, r := range results {
+		for _, f := range r.Findings {
+			findings = append(findings, OutputFinding{Severity: f.Severity.String(), Code: f.Code, Message: f.Message, Meta: f.Meta})
+		}
+	}
+	return Output{Summary: Summary{Info: summary.Info, Warn: summary.Warn, Block: summary.Block}, Findings: findings}
+}
+
+func convertCheckSummary(summary checks.Summary, findings []checks.Finding) Output {
+	return Output{Summary: Summary{Info: summary.Info, Warn: summary.Warn, Block: summary.Block}, Findings: convertCheckFindings(findings).Findings}
+}
+
+func convertCheckFindings(findings []checks.Finding) Output {
+	outs := []OutputFinding{}
+	var summary Summary
+	for _, f := range findings {
+		outs = append(outs, OutputFinding{Severity: f.Severity.String(), Code: f.Code, Message: f.Message, Meta: f.Meta})
+		switch f.Severity {
+		case checks.SeverityInfo:
+			summary.Info++
+		case checks.SeverityWarn:
+			summary.Warn++
+		case checks.SeverityBlock:
+			summary.Block++
+		}
+	}
+	return Output{Summary: summary, Findings: outs}
+}
+
+func convertMySQLFindings(summary mysql.Summary, findings []mysql.Finding) Output {
+	outs := []OutputFinding{}
+	for _, f := range findings {
+		outs = append(outs, OutputFinding{Severity: f.Severity.String(), Code: f.Code, Message: f.Message, Meta: f.Meta})
+	}
+	return Output{Summary: Summary{Info: summary.Info, Warn: summary.Warn, Block: summary.Block}, Findings: outs}
+}
+
+// prependFindings adds findings ahead of a command's output and counts them.
+func prependFindings(output Output, extra []OutputFinding) Output {
+	if len(extra) == 0 {
+		return output
+	}
+	for _, f := range extra {
+		switch f.Severity {
+		case "INFO":
+			output.Summary.Info++
+		case "WARN":
+			output.Summary.Warn++
+		case "BLOCK":
+			output.Summary.Block++
+		}
+	}
+	output.Findings = append(append([]OutputFinding{}, extra...), output.Findings...)
+	return output
+}
+
+// withRemediation attaches catalog remediation text to findings by code.
+func withRemediation(output Output, catalog remediation.Catalog) Output {
+	for i := range output.Findings {
+		if output.Findings[i].Remediation == "" {
+			output.Findings[i].Remediation = catalog.Lookup(output.Findings[i].Code)
+		}
+	}
+	return output
+}
+
+// withRegions adds the region of every labeled topology host a finding's meta
+// names, as "regions" ({host: region}), and sets "cross_region" when one of
+// them is a cross-region replica, so findings can be routed per region.
+// Plans without topology.regions leave findings unchanged.
+func withRegions(output Output, topology workflow.Topology) Output {
+	if len(topology.Regions) == 0 {
+		return output
+	}
+	for i, f := range output.Findings {
+		regions := map[string]string{}
+		cross := false
+		for _, host := range metaHosts(f.Meta) {
+			if region := topology.Region(host); region != "" {
+				regions[host] = region
+				cross = cross || topology.CrossRegion(host)
+			}
+		}
+		if len(regions) == 0 {
+			continue
+		}
+		if _, ok := f.Meta["regions"]; !ok {
+			output.Findings[i].Meta["regions"] = regions
+		}
+		if _, ok := f.Meta["cross_region"]; !ok {
+			output.Findings[i].Meta["cross_region"] = cross
+		}
+	}
+	return output
+}
+
+// metaHosts returns every string, or string in a list, among meta's values;
+// withRegions keeps those that are labeled hosts.
+func metaHosts(meta map[string]interface{}) []string {
+	hosts := []string{}
+	for _, v := range meta {
+		switch v := v.(type) {
+		case string:
+			hosts = append(hosts, v)
+		case []string:
+			hosts = append(hosts, v...)
+		case []interface{}:
+			for _, item := range v {
+				if s, ok := item.(string); ok {
+					hosts = append(hosts, s)
+				}
+			}
+		}
+	}
+	return hosts
+}
+
+func hasBlockFinding(findings []OutputFinding) bool {
+	for _, f := range findings {
+		if f.Severity == "BLOCK" {
+			return true
+		}
+	}
+	return false
+}
+
+// filterOutput drops findings below min from the printed list and recounts
+// the summary from what is left, so it never reports findings the reader
+// cannot see. BLOCK findings are never dropped, so the outcome is unchanged.
+func filterOutput(output Output, min checks.Severity) Output {
+	kept := []OutputFinding{}
+	for _, f := range output.Findings {
+		sev, err := checks.ParseSeverity(f.Severity)
+		if err != nil || sev >= min {
+			kept = append(kept, f)
+		}
+	}
+	return prependFindings(Output{Findings: []OutputFinding{}}, kept)
+}
+
+var (
+	timestampPattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`)
+	durationPattern  = regexp.MustCompile(`\b\d+(\.\d+)?(ns|us|µs|ms|s|m|h)(\d+(\.\d+)?(ns|us|µs|ms|s|m|h))*\b`)
+)
+
+const (
+	normalizedTimestamp = "<timestamp>"
+	normalizedDuration  = "<duration>"
+)
+
+// deterministicOutput makes output diffable against golden files: timestamps
+// and durations in messages and meta are replaced by placeholders, and
+// findings are sorted by severity (BLOCK first), code, message and meta.
+func deterministicOutput(output Output) Output {
+	type keyed struct {
+		finding OutputFinding
+		meta    string
+	}
+	findings := make([]keyed, 0, len(output.Findings))
+	for _, f := range output.Findings {
+		f.Message = normalizeText(f.Message)
+		if f.Meta != nil {
+			f.Meta = normalizeValue(f.Meta).(map[string]interface{})
+		}
+		b, _ := json.Marshal(f.Meta)
+		findings = append(findings, keyed{finding: f, meta: string(b)})
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		ra, rb := severityRank(a.finding.Severity), severityRank(b.finding.Severity)
+		switch {
+		case ra != rb:
+			return ra > rb
+		case a.finding.Code != b.finding.Code:
+			return a.finding.Code < b.finding.Code
+		case a.finding.Message != b.finding.Message:
+			return a.finding.Message < b.finding.Message
+		default:
+			return a.meta < b.meta
+		}
+	})
+	sorted := make([]OutputFinding, 0, len(findings))
+	for _, k := range findings {
+		sorted = append(sorted, k.finding)
+	}
+	output.Findings = sorted
+	return output
+}
+
+func severityRank(severity string) int {
+	sev, err := checks.ParseSeverity(severity)
+	if err != nil {
+		return -1
+	}
+	return int(sev)
+}
+
+func normalizeText(s string) string {
+	s = timestampPattern.ReplaceAllString(s, normalizedTimestamp)
+	return durationPattern.ReplaceAllString(s, normalizedDuration)
+}
+
+func normalizeValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case time.Time:
+		return normalizedTimestamp
+	case time.Duration:
+		return normalizedDuration
+	case string:
+		return normalizeText(val)
+	case []string:
+		out := make([]string, len(val))
+		for i, s := range val {
+			out[i] = normalizeText(s)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			out[i] = normalizeValue(item)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			out[k] = normalizeValue(item)
+		}
+		return out
+	case []map[string]interface{}:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			out[i] = normalizeValue(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// writeOutput renders output as indented JSON or as one line per finding
+// (plus an indented remediation line when known) followed by the summary line.
+func writeOutput(w io.Writer, output Output, format string) error {
+	if format == formatText {
+		for _, f := range output.Findings {
+			if _, err := fmt.Fprintf(w, "[%s] %s\n", f.Severity, f.Message); err != nil {
+				return err
+			}
+			if f.Remediation != "" {
+				if _, err := fmt.Fprintf(w, "    remediation: %s\n", f.Remediation); err != nil {
+					return err
+				}
+			}
+		}
+		_, err := fmt.Fprintf(w, "Summary: %d INFO / %d WARN / %d BLOCK\n", output.Summary.Info, output.Summary.Warn, output.Summary.Block)
+		return err
+	}
+
+	b, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	_, err = w.Write(b)
+	return err
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"migratorx/internal/report"
+)
+
+// Environment variables holding the review system tokens for --report-comment.
+const (
+	githubTokenEnv = "GITHUB_TOKEN"
+	gitlabTokenEnv = "GITLAB_TOKEN"
+)
+
+// reportComment posts the command's summary and gate decision to the pull or
+// merge request named by --report-comment. Like notifications, a failed post
+// is a WARN finding and never changes the outcome it reports.
+func reportComment(ctx context.Context, e *env, output Output) Output {
+	target, err := report.ParseTarget(e.Globals.ReportComment)
+	if err == nil {
+		tokenEnv := githubTokenEnv
+		if target.Provider == report.ProviderGitLab {
+			tokenEnv = gitlabTokenEnv
+		}
+		client := &report.CommentClient{Token: os.Getenv(tokenEnv)}
+		var updated bool
+		updated, err = client.Upsert(ctx, target, commentMarker(e), renderComment(e, output))
+		if err == nil {
+			verb := "posted"
+			if updated {
+				verb = "updated"
+			}
+			return prependFindings(output, []OutputFinding{{
+				Severity: "INFO",
+				Code:     codeReportComment,
+				Message:  fmt.Sprintf("%s run summary on %s", verb, target.URL),
+				Meta:     map[string]interface{}{"url": target.URL, "updated": updated},
+			}})
+		}
+	}
+	return prependFindings(output, []OutputFinding{{
+		Severity: "WARN",
+		Code:     codeReportFailed,
+		Message:  fmt.Sprintf("posting run summary to %s failed: %v", e.Globals.ReportComment, err),
+		Meta:     map[string]interface{}{"url": e.Globals.ReportComment},
+	}})
+}
+
+// commentMarker identifies the comment a command owns on a PR/MR, so each
+// command keeps one comment per migration and environment that re-runs update.
+func commentMarker(e *env) string {
+	marker := "migratorx:" + e.Manifest.Migration + ":" + strings.ReplaceAll(e.Manifest.Command, " ", "-")
+	if e.Globals.Environment != "" {
+		marker += "@" + e.Globals.Environment
+	}
+	return marker
+}
+
+// gateDecision condenses a summary into the decision shown in comments.
+func gateDecision(summary Summary) string {
+	switch {
+	case summary.Block > 0:
+		return "BLOCKED"
+	case summary.Warn > 0:
+		return "PASSED WITH WARNINGS"
+	default:
+		return "PASSED"
+	}
+}
+
+// renderComment renders the summary table, gate decision and every WARN and
+// BLOCK finding as Markdown.
+func renderComment(e *env, output Output) string {
+	var b strings.Builder
+	title := "migratorx " + e.Manifest.Command
+	if e.Manifest.Migration != "" {
+		title += ": " + e.Manifest.Migration
+	}
+	if e.Globals.Environment != "" {
+		title += " (" + e.Globals.Environment + ")"
+	}
+	fmt.Fprintf(&b, "### %s\n\n", title)
+	fmt.Fprintf(&b, "**Gate decision: %s**\n\n", gateDecision(output.Summary))
+	b.WriteString("| Severity | Count |\n| --- | ---: |\n")
+	fmt.Fprintf(&b, "| BLOCK | %d |\n| WARN | %d |\n| INFO | %d |\n", output.Summary.Block, output.Summary.Warn, output.Summary.Info)
+	rows := []string{}
+	for _, f := range output.Findings {
+		if f.Severity != "BLOCK" && f.Severity != "WARN" {
+			continue
+		}
+		rows = append(rows, fmt.Sprintf("| %s | %s | %s |", f.Severity, markdownCell(f.Code), markdownCell(f.Message)))
+	}
+	if len(rows) > 0 {
+		b.WriteString("\n| Severity | Code | Finding |\n| --- | --- | --- |\n")
+		b.WriteString(strings.Join(rows, "\n"))
+		b.WriteString("\n")
+	}
+	if e.PlanHash != "" {
+		fmt.Fprintf(&b, "\nPlan `%s`, run `%s`.\n", e.PlanHash, e.Globals.RunID)
+	}
+	return b.String()
+}
+
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+// writeReport stores the rendered output at --output, named by run ID and
+// step when the destination is a directory, S3 prefix or HTTP endpoint. Like
+// comments, a failed write is a WARN finding and never changes the outcome
+// it reports.
+func writeReport(ctx context.Context, e *env, output Output) Output {
+	sink, err := e.reportSink()
+	if err == nil {
+		var rendered bytes.Buffer
+		if err = writeOutput(&rendered, output, e.Globals.Format); err == nil {
+			ext, contentType := "json", "application/json"
+			if e.Globals.Format == formatText {
+				ext, contentType = "txt", "text/plain; charset=utf-8"
+			}
+			var at time.Time
+			if e.Manifest.StartedAt != nil {
+				at = *e.Manifest.StartedAt
+			}
+			var location string
+			location, err = sink.Put(ctx, report.ReportName(e.Globals.RunID, e.Manifest.Command, ext, at), contentType, rendered.Bytes())
+			if err == nil {
+				return prependFindings(output, []OutputFinding{{
+					Severity: "INFO",
+					Code:     codeReportWritten,
+					Message:  fmt.Sprintf("wrote report to %s", location),
+					Meta:     map[string]interface{}{"location": location},
+				}})
+			}
+		}
+	}
+	return prependFindings(output, []OutputFinding{{
+		Severity: "WARN",
+		Code:     codeReportWriteFailed,
+		Message:  fmt.Sprintf("writing report to %s failed: %v", e.Globals.Output, err),
+		Meta:     map[string]interface{}{"destination": e.Globals.Output},
+	}})
+}
+
+// reportSink resolves --output, taking S3 settings from the standard AWS
+// environment variables.
+func (e *env) reportSink() (report.Sink, error) {
+	return report.ParseSink(e.Globals.Output, report.SinkOptions{
+		HTTP: e.httpClient("output"),
+		S3: report.S3Config{
+			Region:          firstEnv("AWS_REGION", "AWS_DEFAULT_REGION"),
+			Endpoint:        firstEnv("AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL"),
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		},
+	})
+}
+
+// firstEnv returns the first of names set in the environment.
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"migratorx/internal/checks"
+	"migratorx/internal/mysql"
+	"migratorx/internal/workflow"
+)
+
+// simulationPhrase confirms the promotion gate during a rehearsal; nothing is
+// promoted, so the explicit operator confirmation is not required.
+const simulationPhrase = "SIMULATE"
+
+// stepRunner runs one plan step of a simulation against fixtures.
+type stepRunner func(ctx context.Context, env *env, sim *simulation) Output
+
+// simulation carries what a rehearsal shares between steps. State lives in
+// memory so checkpoints behave as in a real run without touching --state.
+// Actions records every change the simulated actions and drainers stand in
+// for.
+type simulation struct {
+	plan      workflow.MigrationPlan
+	replica   string
+	in        inputFlags
+	state     workflow.State
+	inspector mysql.ReplicaInspector
+	actions   *workflow.ActionLog
+}
+
+var simulationSteps = map[string]stepRunner{
+	"preflight":        simulatePreflight,
+	"upgrade_replica":  simulateUpgradeReplica,
+	"validate_replica": simulateSchemaParity,
+	"cdc_check":        simulateCDCCheck,
+	"promote":          simulatePromote,
+	"post_validation":  simulatePostValidation,
+}
+
+// mutatingSimulationSteps change target systems in a real run.
+var mutatingSimulationSteps = map[string]bool{"upgrade_replica": true, "promote": true, "post_validation": true}
+
+func setupSimulate(fs *flag.FlagSet) runFunc {
+	fixtures := fs.String("fixtures", "", "directory of recorded inspector outputs")
+	verify := fs.Bool("verify-idempotence", false, "run each plan step twice and block unless the second run performs no actions and matches the first run's findings")
+	return func(ctx context.Context, env *env, args []string) Output {
+		if *fixtures == "" {
+			return blockOutput(fmt.Errorf("--fixtures is required"))
+		}
+		plan, err := env.loadPlan()
+		if err != nil {
+			return blockOutput(err)
+		}
+		replica, err := selectReplica(plan)
+		if err != nil {
+			return blockOutput(err)
+		}
+		sim := &simulation{
+			plan:      plan,
+			replica:   replica,
+			in:        fixtureInputs(*fixtures, plan.Topology.Primary, replica),
+			state:     workflow.NewMemoryState(),
+			inspector: &timelineReplicaInspector{path: func(host string) string { return fixtureReplicationPath(*fixtures, host) }, primary: plan.Topology.Primary},
+			actions:   &workflow.ActionLog{},
+		}
+		if *verify {
+			return verifyIdempotence(ctx, env, sim)
+		}
+
+		output := Output{Findings: []OutputFinding{}}
+		blockedAt := ""
+		for _, step := range plan.Steps {
+			var result Output
+			if blockedAt != "" {
+				result = Output{Summary: Summary{Info: 1}, Findings: []OutputFinding{{Severity: "INFO", Message: fmt.Sprintf("step skipped: %s blocked", blockedAt)}}}
+			} else {
+				env.Logger.Printf("simulating step %s", step)
+				if run, ok := simulationSteps[step]; ok {
+					result = run(ctx, env, sim)
+				} else {
+					result = blockOutput(fmt.Errorf("step %q cannot be simulated", step))
+				}
+				if result.Summary.Block > 0 {
+					blockedAt = step
+				}
+			}
+			output = appendStepOutput(output, step, result)
+		}
+		return output
+	}
+}
+
+// appendStepOutput merges a step's output, tagging each finding with the step.
+func appendStepOutput(output Output, step string, result Output) Output {
+	output.Summary.Info += result.Summary.Info
+	output.Summary.Warn += result.Summary.Warn
+	output.Summary.Block += result.Summary.Block
+	for _, f := range result.Findings {
+		meta := map[string]interface{}{"step": step}
+		for k, v := range f.Meta {
+			meta[k] = v
+		}
+		f.Meta = meta
+		output.Findings = append(output.Findings, f)
+	}
+	return output
+}
+
+// simulationStep adapts a simulation step to workflow.Step for the
+// idempotence harness. Steps share the simulation's state rather than the
+// one they are given, which the harness sets to the same value.
+type simulationStep struct {
+	name string
+	run  stepRunner
+	env  *env
+	sim  *simulation
+}
+
+func (s *simulationStep) Name() string     { return s.name }
+func (s *simulationStep) Idempotent() bool { return true }
+func (s *simulationStep) Mutates() bool    { return mutatingSimulationSteps[s.name] }
+
+func (s *simulationStep) Run(ctx context.Context, st workflow.State) (workflow.StepResult, error) {
+	output := s.run(ctx, s.env, s.sim)
+	findings := make([]workflow.Finding, 0, len(output.Findings))
+	for _, f := range output.Findings {
+		findings = append(findings, workflow.Finding{Severity: parseSeverity(f.Severity), Message: f.Message, Meta: f.Meta})
+	}
+	return workflow.StepResult{Findings: findings}, nil
+}
+
+func parseSeverity(severity string) workflow.Severity {
+	switch severity {
+	case "BLOCK":
+		return workflow.SeverityBlock
+	case "WARN":
+		return workflow.SeverityWarn
+	}
+	return workflow.SeverityInfo
+}
+
+// verifyIdempotence reruns every plan step against the fixtures and reports
+// whether each holds to the idempotence contract: a step resumed after a
+// crash must not repeat an action it already took.
+func verifyIdempotence(ctx context.Context, env *env, sim *simulation) Output {
+	steps := []workflow.Step{}
+	for _, name := range sim.plan.Steps {
+		run, ok := simulationSteps[name]
+		if !ok {
+			return blockOutput(fmt.Errorf("step %q cannot be simulated", name))
+		}
+		steps = append(steps, &simulationStep{name: name, run: run, env: env, sim: sim})
+	}
+	harness := &workflow.IdempotenceHarness{Steps: steps, State: sim.state, Actions: sim.actions, Logger: env.Logger}
+	results, err := harness.Run(ctx)
+	env.Manifest.recordCheck("idempotence", map[string]interface{}{"steps": sim.plan.Steps})
+	if err != nil {
+		return blockOutput(err)
+	}
+	findings := []OutputFinding{}
+	for _, f := range results {
+		code := codeStepIdempotent
+		if f.Severity == workflow.SeverityBlock {
+			code = codeStepNotIdempotent
+		}
+		findings = append(findings, OutputFinding{Severity: f.Severity.String(), Code: code, Message: f.Message, Meta: f.Meta})
+	}
+	return prependFindings(Output{}, findings)
+}
+
+func simulatePreflight(ctx context.Context, env *env, sim *simulation) Output {
+	checksList := buildChecks(env.Recorder, sim.in, sim.plan.Topology.Primary, sim.replica, sim.plan)
+	runner := checks.NewRunner(checksList, env.Logger)
+	summary, results, err := runner.Run(ctx, planInput(sim.plan, sim.replica))
+	env.Manifest.recordChecks(checksList)
+	if err != nil {
+		return blockOutput(err)
+	}
+	return convertCheckResults(summary, results)
+}
+
+func simulateUpgradeReplica(ctx context.Context, env *env, sim *simulation) Output {
+	orchestrator := mysql.NewUpgradeOrchestrator(env.Recorder.replicaInspector(sim.inspector), &simulatedActions{actions: sim.actions}, sim.state, sim.plan.Topology.Primary, env.Logger)
+	orchestrator.Drainers = sim.drainers()
+	// Recorded timelines advance per read, so replay them without waiting.
+	orchestrator.ResumeMaxLag = sim.plan.LagLimit(sim.replica)
+	orchestrator.ResumePollInterval = time.Millisecond
+	orchestrator.ResumeTimeout = time.Second
+	summary, findings, err := orchestrator.Run(ctx, sim.replica)
+	env.Manifest.recordCheck("replica_upgrade", map[string]interface{}{"replica": sim.replica, "simulate": true})
+	if err != nil {
+		return blockOutput(err)
+	}
+	return convertMySQLFindings(summary, findings)
+}
+
+func simulateSchemaParity(ctx context.Context, env *env, sim *simulation) Output {
+	return runSchemaParity(ctx, env, sim.in, sim.plan, sim.replica)
+}
+
+// simulatePostValidation rehearses the post_validation.on_block policy: a
+// blocking parity result rolls back the simulated promotion. Notifications are
+// not sent during a rehearsal.
+func simulatePostValidation(ctx context.Context, env *env, sim *simulation) Output {
+	output := simulateSchemaParity(ctx, env, sim)
+	if output.Summary.Block == 0 || sim.plan.PostValidation.OnBlock != workflow.OnBlockAutoRollback {
+		return output
+	}
+	orchestrator := mysql.NewPromotionOrchestrator(&simulatedActions{actions: sim.actions}, sim.state, sim.plan.Topology.Primary, env.Logger)
+	summary, findings, err := orchestrator.Rollback(ctx, sim.replica)
+	env.Manifest.recordCheck("promotion_rollback", map[string]interface{}{"replica": sim.replica, "simulate": true, "trigger": "post_validation"})
+	if err != nil {
+		return prependFindings(blockOutput(err), output.Findings)
+	}
+	return prependFindings(convertMySQLFindings(summary, findings), output.Findings)
+}
+
+func simulateCDCCheck(ctx context.Context, env *env, sim *simulation) Output {
+	check := buildDebeziumCheck(env.Recorder, sim.in.CDCStatus, sim.plan)
+	findings, err := check.Run(ctx, planInput(sim.plan, sim.replica))
+	env.Manifest.recordChecks([]checks.PreflightCheck{check})
+	if err != nil {
+		return blockOutput(err)
+	}
+	return convertCheckFindings(findings)
+}
+
+func simulatePromote(ctx context.Context, env *env, sim *simulation) Output {
+	checksList := buildChecks(env.Recorder, sim.in, sim.plan.Topology.Primary, sim.replica, sim.plan)
+	gate := workflow.PromotionGate{Checks: checksList, RequiredCheckNames: sim.plan.RequiredCheckNames(), AllowedWarnCodes: sim.plan.Promotion.AllowWarnCodes, MaxWarnCount: sim.plan.Thresholds.MaxWarnCount, ConfirmationPhrase: simulationPhrase, Logger: env.Logger}
+	summary, findings, err := gate.Run(ctx, planInput(sim.plan, sim.replica), simulationPhrase)
+	env.Manifest.recordChecks(checksList)
+	if err != nil {
+		return blockOutput(err)
+	}
+	output := convertCheckSummary(summary, findings)
+	if summary.Block > 0 {
+		return output
+	}
+
+	orchestrator := mysql.NewPromotionOrchestrator(&simulatedActions{actions: sim.actions}, sim.state, sim.plan.Topology.Primary, env.Logger)
+	orchestrator.CutoverBudget = sim.plan.Thresholds.MaxCutoverDuration
+	for _, phase := range []func(context.Context, string) (mysql.Summary, []mysql.Finding, error){orchestrator.Prepare, orchestrator.Execute} {
+		phaseSummary, phaseFindings, err := phase(ctx, sim.replica)
+		if err != nil {
+			return blockOutput(err)
+		}
+		output = prependFindings(convertMySQLFindings(phaseSummary, phaseFindings), output.Findings)
+		if phaseSummary.Block > 0 {
+			break
+		}
+	}
+	return output
+}
+
+// drainers returns the plan's drainers, simulated and recording to the
+// simulation's action log.
+func (sim *simulation) drainers() []mysql.Drainer {
+	// Simulated drainers need no inputs, so this cannot fail.
+	drainers, _ := buildDrainers(sim.plan, inputFlags{}, nil, true)
+	for _, d := range drainers {
+		if simulated, ok := d.(*simulatedDrainer); ok {
+			simulated.actions = sim.actions
+		}
+	}
+	return drainers
+}
@@ -3,3 +3,5 @@ module migratorx
 go 1.20
 
 require gopkg.in/yaml.v3 v3.0.1
+
+require github.com/go-sql-driver/mysql v1.7.1
@@ -0,0 +1,114 @@
+// Package access implements role-based authorization for migratorx commands.
+package access
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Role is an authorization level. Roles are ordered: an approver may do
+// anything an operator may, and an operator anything a viewer may.
+type Role string
+
+const (
+	RoleViewer   Role = "viewer"
+	RoleOperator Role = "operator"
+	RoleApprover Role = "approver"
+)
+
+var roleRank = map[Role]int{RoleViewer: 1, RoleOperator: 2, RoleApprover: 3}
+
+// ParseRole parses a role name case-insensitively.
+func ParseRole(s string) (Role, error) {
+	r := Role(strings.ToLower(strings.TrimSpace(s)))
+	if _, ok := roleRank[r]; !ok {
+		return "", fmt.Errorf("unknown role %q (expected viewer, operator or approver)", s)
+	}
+	return r, nil
+}
+
+// Allows reports whether r grants at least the required role.
+func (r Role) Allows(required Role) bool {
+	return roleRank[r] >= roleRank[required] && roleRank[r] > 0
+}
+
+// Identity is the caller a token resolved to.
+type Identity struct {
+	Name string `json:"name"`
+	Role Role   `json:"role"`
+}
+
+// TokenEntry maps a token, stored as its hex SHA-256, to an identity.
+type TokenEntry struct {
+	Name   string `yaml:"name"`
+	SHA256 string `yaml:"sha256"`
+	Role   string `yaml:"role"`
+}
+
+// Policy is the set of tokens allowed to run commands.
+type Policy struct {
+	Tokens []TokenEntry `yaml:"tokens"`
+}
+
+// LoadPolicy reads and validates a YAML tokens file.
+func LoadPolicy(path string) (Policy, error) {
+	var p Policy
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return p, err
+	}
+	if err := yaml.Unmarshal(b, &p); err != nil {
+		return p, err
+	}
+	if err := p.Validate(); err != nil {
+		return p, err
+	}
+	return p, nil
+}
+
+// Validate checks every entry has a name, a SHA-256 digest and a known role.
+func (p Policy) Validate() error {
+	var problems []string
+	for i, t := range p.Tokens {
+		if strings.TrimSpace(t.Name) == "" {
+			problems = append(problems, fmt.Sprintf("tokens[%d].name is required", i))
+		}
+		if b, err := hex.DecodeString(t.SHA256); err != nil || len(b) != sha256.Size {
+			problems = append(problems, fmt.Sprintf("tokens[%d].sha256 must be a hex SHA-256 digest", i))
+		}
+		if _, err := ParseRole(t.Role); err != nil {
+			problems = append(problems, fmt.Sprintf("tokens[%d].role: %v", i, err))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("access policy validation failed: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Authorize resolves token to an identity and checks it holds the required role.
+func (p Policy) Authorize(token string, required Role) (Identity, error) {
+	if token == "" {
+		return Identity{}, fmt.Errorf("access denied: an identity token is required (%s role)", required)
+	}
+	sum := sha256.Sum256([]byte(token))
+	digest := hex.EncodeToString(sum[:])
+	for _, t := range p.Tokens {
+		if subtle.ConstantTimeCompare([]byte(strings.ToLower(t.SHA256)), []byte(digest)) != 1 {
+			continue
+		}
+		role, _ := ParseRole(t.Role)
+		id := Identity{Name: t.Name, Role: role}
+		if !role.Allows(required) {
+			return id, fmt.Errorf("access denied: %s has role %s; %s required", id.Name, role, required)
+		}
+		return id, nil
+	}
+	return Identity{}, fmt.Errorf("access denied: identity token is not recognized")
+}
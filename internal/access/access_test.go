@@ -0,0 +1,63 @@
+package access
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func digest(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestRoleAllows(t *testing.T) {
+	if !RoleApprover.Allows(RoleOperator) || !RoleOperator.Allows(RoleViewer) {
+		t.Fatalf("expected higher roles to include lower roles")
+	}
+	if RoleViewer.Allows(RoleOperator) || RoleOperator.Allows(RoleApprover) {
+		t.Fatalf("expected lower roles to be denied higher roles")
+	}
+	if Role("").Allows(RoleViewer) {
+		t.Fatalf("expected empty role to be denied")
+	}
+}
+
+func TestPolicyAuthorize(t *testing.T) {
+	p := Policy{Tokens: []TokenEntry{
+		{Name: "ci", SHA256: digest("ci-token"), Role: "viewer"},
+		{Name: "oncall", SHA256: digest("oncall-token"), Role: "approver"},
+	}}
+
+	if _, err := p.Authorize("ci-token", RoleViewer); err != nil {
+		t.Fatalf("expected viewer access, got %v", err)
+	}
+	if _, err := p.Authorize("ci-token", RoleOperator); err == nil || !strings.Contains(err.Error(), "operator required") {
+		t.Fatalf("expected viewer to be denied operator, got %v", err)
+	}
+	id, err := p.Authorize("oncall-token", RoleApprover)
+	if err != nil || id.Name != "oncall" || id.Role != RoleApprover {
+		t.Fatalf("expected approver identity, got %+v, %v", id, err)
+	}
+	if _, err := p.Authorize("", RoleViewer); err == nil {
+		t.Fatalf("expected missing token to be denied")
+	}
+	if _, err := p.Authorize("bogus", RoleViewer); err == nil {
+		t.Fatalf("expected unknown token to be denied")
+	}
+}
+
+func TestLoadPolicyValidates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.yaml")
+	content := "tokens:\n  - name: ci\n    sha256: abc\n    role: admin\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	_, err := LoadPolicy(path)
+	if err == nil || !strings.Contains(err.Error(), "sha256") || !strings.Contains(err.Error(), "unknown role") {
+		t.Fatalf("expected validation errors, got %v", err)
+	}
+}
@@ -0,0 +1,224 @@
+package cdc
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+
+	"migratorx/internal/checks"
+	"migratorx/internal/failure"
+	"migratorx/internal/workflow"
+)
+
+// DefaultRemediationAttempts bounds a remediation policy without MaxAttempts.
+const DefaultRemediationAttempts = 3
+
+// SnapshotSignaler triggers a Debezium incremental snapshot of tables.
+type SnapshotSignaler interface {
+	IncrementalSnapshot(ctx context.Context, connector string, tables []string) error
+}
+
+// CommandSnapshotSignaler runs Command to send the execute-snapshot signal,
+// e.g. a script that inserts into the connector's signal table. {connector}
+// and {tables} (comma-separated) are substituted in each argument.
+type CommandSnapshotSignaler struct {
+	Command []string
+}
+
+func (s *CommandSnapshotSignaler) IncrementalSnapshot(ctx context.Context, connector string, tables []string) error {
+	if len(s.Command) == 0 {
+		return fmt.Errorf("signal command is empty")
+	}
+	joined := strings.Join(tables, ",")
+	args := make([]string, len(s.Command))
+	for i, a := range s.Command {
+		args[i] = strings.NewReplacer("{connector}", connector, "{tables}", joined).Replace(a)
+	}
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Env = append(os.Environ(), "MIGRATORX_CONNECTOR="+connector, "MIGRATORX_TABLES="+joined)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s: %v: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// AutoRemediator applies the plan's remediation policies to CDC findings. A
+// finding a policy remediates becomes a WARN (CDC_AUTO_REMEDIATED), since the
+// next check shows whether it worked. Attempts are counted per finding target
+// in State and reset once the finding clears; a finding still present after
+// MaxAttempts escalates to a BLOCK (CDC_AUTO_REMEDIATION_EXHAUSTED).
+type AutoRemediator struct {
+	Policies  []workflow.CDCRemediationPolicy
+	Connector string
+	Restarter ConnectorRestarter
+	Snapshots SnapshotSignaler
+	State     workflow.State
+	Logger    *log.Logger
+}
+
+// Apply remediates findings and returns them with remediated and exhausted
+// findings replaced. A policy whose action has no executor is an error.
+func (r *AutoRemediator) Apply(ctx context.Context, findings []checks.Finding) ([]checks.Finding, error) {
+	for _, p := range r.Policies {
+		switch {
+		case p.Action == workflow.RemediationRestartTask && r.Restarter == nil:
+			return nil, failure.Config("auto remediation of %s restarts tasks but no Kafka Connect URL is configured", p.Code)
+		case p.Action == workflow.RemediationIncrementalSnapshot && r.Snapshots == nil:
+			return nil, failure.Config("auto remediation of %s triggers snapshots but no signal command is configured", p.Code)
+		}
+	}
+
+	previous := r.attempts()
+	attempts := map[string]interface{}{}
+	out := make([]checks.Finding, 0, len(findings))
+	for _, f := range findings {
+		policy, target, ok := r.match(f)
+		if !ok {
+			out = append(out, f)
+			continue
+		}
+		key := f.Code + ":" + target
+		attempt := toInt(previous[key]) + 1
+		limit := policy.MaxAttempts
+		if limit == 0 {
+			limit = DefaultRemediationAttempts
+		}
+		meta := map[string]interface{}{}
+		for k, v := range f.Meta {
+			meta[k] = v
+		}
+		meta["original_code"] = f.Code
+		meta["action"] = policy.Action
+		meta["max_attempts"] = limit
+		if attempt > limit {
+			attempts[key] = limit
+			out = append(out, checks.Finding{
+				Severity: checks.SeverityBlock,
+				Code:     CodeAutoRemediationExhausted,
+				Message:  fmt.Sprintf("%s; %s did not clear it after %d attempts", f.Message, policy.Action, limit),
+				Meta:     meta,
+			})
+			continue
+		}
+		attempts[key] = attempt
+		meta["attempt"] = attempt
+		r.logger().Printf("auto remediation: %s for %s (attempt %d of %d)", policy.Action, key, attempt, limit)
+		if err := r.remediate(ctx, policy, f); err != nil {
+			meta["remediation_error"] = err.Error()
+			f.Meta = meta
+			f.Message = fmt.Sprintf("%s; %s failed (attempt %d of %d): %v", f.Message, policy.Action, attempt, limit, err)
+			out = append(out, f)
+			continue
+		}
+		out = append(out, checks.Finding{
+			Severity: checks.SeverityWarn,
+			Code:     CodeAutoRemediated,
+			Message:  fmt.Sprintf("%s; applied %s (attempt %d of %d), re-run the check to confirm", f.Message, policy.Action, attempt, limit),
+			Meta:     meta,
+		})
+	}
+	if r.State != nil {
+		r.State.Set(autoRemediationKey(r.Connector), attempts)
+	}
+	return out, nil
+}
+
+// match returns the policy that applies to f and the target it acts on: the
+// task id for restarts, the missing tables for snapshots.
+func (r *AutoRemediator) match(f checks.Finding) (workflow.CDCRemediationPolicy, string, bool) {
+	for _, p := range r.Policies {
+		if p.Code != f.Code {
+			continue
+		}
+		switch p.Action {
+		case workflow.RemediationRestartTask:
+			id, ok := f.Meta["task_id"]
+			if !ok || !retryable(p.RetryableTraces, fmt.Sprint(f.Meta["trace"])) {
+				continue
+			}
+			return p, fmt.Sprintf("task:%v", id), true
+		case workflow.RemediationIncrementalSnapshot:
+			tables := stringsOf(f.Meta["missing_tables"])
+			if len(tables) == 0 {
+				continue
+			}
+			return p, "tables:" + strings.Join(tables, ","), true
+		}
+	}
+	return workflow.CDCRemediationPolicy{}, "", false
+}
+
+func (r *AutoRemediator) remediate(ctx context.Context, p workflow.CDCRemediationPolicy, f checks.Finding) error {
+	switch p.Action {
+	case workflow.RemediationRestartTask:
+		return r.Restarter.RestartTask(ctx, r.Connector, toInt(f.Meta["task_id"]))
+	case workflow.RemediationIncrementalSnapshot:
+		return r.Snapshots.IncrementalSnapshot(ctx, r.Connector, stringsOf(f.Meta["missing_tables"]))
+	}
+	return fmt.Errorf("unsupported remediation action %q", p.Action)
+}
+
+func (r *AutoRemediator) attempts() map[string]interface{} {
+	if r.State == nil {
+		return nil
+	}
+	value, _ := r.State.Get(autoRemediationKey(r.Connector))
+	attempts, _ := value.(map[string]interface{})
+	return attempts
+}
+
+func (r *AutoRemediator) logger() *log.Logger {
+	if r.Logger == nil {
+		return log.Default()
+	}
+	return r.Logger
+}
+
+// retryable reports whether trace contains one of patterns; no patterns means
+// every trace is retryable.
+func retryable(patterns []string, trace string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if strings.Contains(trace, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// toInt reads a count or id that may have been round-tripped through JSON.
+func toInt(v interface{}) int {
+	switch n := v.(type) {
+	case int:
+		return n
+	case int64:
+		return int(n)
+	case float64:
+		return int(n)
+	}
+	return 0
+}
+
+func stringsOf(v interface{}) []string {
+	switch s := v.(type) {
+	case []string:
+		return s
+	case []interface{}:
+		out := make([]string, 0, len(s))
+		for _, item := range s {
+			out = append(out, fmt.Sprint(item))
+		}
+		return out
+	}
+	return nil
+}
+
+func autoRemediationKey(connector string) string {
+	return fmt.Sprintf("cdc:%s:auto_remediation", connector)
+}
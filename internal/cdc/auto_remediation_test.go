@@ -0,0 +1,144 @@
+package cdc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"migratorx/internal/checks"
+	"migratorx/internal/failure"
+	"migratorx/internal/workflow"
+)
+
+type fakeRestarter struct {
+	tasks []int
+	err   error
+}
+
+func (f *fakeRestarter) RestartConnector(ctx context.Context, connector string) error { return f.err }
+
+func (f *fakeRestarter) RestartTask(ctx context.Context, connector string, task int) error {
+	f.tasks = append(f.tasks, task)
+	return f.err
+}
+
+type fakeSignaler struct {
+	tables []string
+}
+
+func (f *fakeSignaler) IncrementalSnapshot(ctx context.Context, connector string, tables []string) error {
+	f.tables = append(f.tables, tables...)
+	return nil
+}
+
+func failedTask(trace string) checks.Finding {
+	return checks.Finding{
+		Severity: checks.SeverityBlock,
+		Code:     CodeTaskNotRunning,
+		Message:  `connector "mysql-prod" task 0 is FAILED`,
+		Meta:     map[string]interface{}{"connector": "mysql-prod", "task_id": 0, "state": "FAILED", "trace": trace},
+	}
+}
+
+func TestAutoRemediator_RestartsRetryableTaskUntilExhausted(t *testing.T) {
+	restarter := &fakeRestarter{}
+	r := &AutoRemediator{
+		Policies:  []workflow.CDCRemediationPolicy{{Code: CodeTaskNotRunning, Action: workflow.RemediationRestartTask, MaxAttempts: 2, RetryableTraces: []string{"TimeoutException"}}},
+		Connector: "mysql-prod",
+		Restarter: restarter,
+		State:     memState{},
+	}
+	trace := "org.apache.kafka.common.errors.TimeoutException: Topic not present in metadata"
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		findings, err := r.Apply(context.Background(), []checks.Finding{failedTask(trace)})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if findings[0].Code != CodeAutoRemediated || findings[0].Severity != checks.SeverityWarn || findings[0].Meta["attempt"] != attempt {
+			t.Fatalf("attempt %d: expected remediated WARN, got %+v", attempt, findings[0])
+		}
+	}
+	findings, err := r.Apply(context.Background(), []checks.Finding{failedTask(trace)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if findings[0].Code != CodeAutoRemediationExhausted || findings[0].Severity != checks.SeverityBlock {
+		t.Fatalf("expected escalation to BLOCK, got %+v", findings[0])
+	}
+	if len(restarter.tasks) != 2 {
+		t.Fatalf("expected two restarts, got %v", restarter.tasks)
+	}
+
+	// Once the task runs again the count resets.
+	if _, err := r.Apply(context.Background(), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	findings, _ = r.Apply(context.Background(), []checks.Finding{failedTask(trace)})
+	if findings[0].Code != CodeAutoRemediated || findings[0].Meta["attempt"] != 1 {
+		t.Fatalf("expected attempts to reset, got %+v", findings[0])
+	}
+}
+
+func TestAutoRemediator_LeavesNonRetryableTraceBlocked(t *testing.T) {
+	restarter := &fakeRestarter{}
+	r := &AutoRemediator{
+		Policies:  []workflow.CDCRemediationPolicy{{Code: CodeTaskNotRunning, Action: workflow.RemediationRestartTask, RetryableTraces: []string{"TimeoutException"}}},
+		Connector: "mysql-prod",
+		Restarter: restarter,
+	}
+	findings, err := r.Apply(context.Background(), []checks.Finding{failedTask("io.debezium.DebeziumException: binlog position no longer available")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if findings[0].Code != CodeTaskNotRunning || len(restarter.tasks) != 0 {
+		t.Fatalf("expected finding untouched and no restart, got %+v", findings)
+	}
+}
+
+func TestAutoRemediator_FailedRestartStaysBlocked(t *testing.T) {
+	r := &AutoRemediator{
+		Policies:  []workflow.CDCRemediationPolicy{{Code: CodeTaskNotRunning, Action: workflow.RemediationRestartTask}},
+		Connector: "mysql-prod",
+		Restarter: &fakeRestarter{err: errors.New("409 Conflict")},
+	}
+	findings, err := r.Apply(context.Background(), []checks.Finding{failedTask("")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if findings[0].Severity != checks.SeverityBlock || findings[0].Meta["remediation_error"] == nil {
+		t.Fatalf("expected BLOCK with remediation error, got %+v", findings[0])
+	}
+}
+
+func TestAutoRemediator_SnapshotsCoverageGap(t *testing.T) {
+	signaler := &fakeSignaler{}
+	r := &AutoRemediator{
+		Policies:  []workflow.CDCRemediationPolicy{{Code: CodeSchemaHistoryCoverageGap, Action: workflow.RemediationIncrementalSnapshot, MaxAttempts: 1}},
+		Connector: "mysql-prod",
+		Snapshots: signaler,
+		State:     memState{},
+	}
+	gap := checks.Finding{Severity: checks.SeverityBlock, Code: CodeSchemaHistoryCoverageGap, Message: "schema history missing tables: shop.orders", Meta: map[string]interface{}{"missing_tables": []string{"shop.orders"}}}
+	findings, err := r.Apply(context.Background(), []checks.Finding{gap})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if findings[0].Code != CodeAutoRemediated || len(signaler.tables) != 1 || signaler.tables[0] != "shop.orders" {
+		t.Fatalf("expected incremental snapshot of shop.orders, got %+v (%v)", findings, signaler.tables)
+	}
+}
+
+func TestAutoRemediator_MissingExecutorIsConfigError(t *testing.T) {
+	r := &AutoRemediator{Policies: []workflow.CDCRemediationPolicy{{Code: CodeTaskNotRunning, Action: workflow.RemediationRestartTask}}}
+	if _, err := r.Apply(context.Background(), nil); failure.KindOf(err) != failure.KindConfig {
+		t.Fatalf("expected config error, got %v", err)
+	}
+}
+
+func TestCommandSnapshotSignaler_SubstitutesTables(t *testing.T) {
+	s := &CommandSnapshotSignaler{Command: []string{"sh", "-c", `test "$0" = "mysql-prod shop.orders,shop.items"`, "{connector} {tables}"}}
+	if err := s.IncrementalSnapshot(context.Background(), "mysql-prod", []string{"shop.orders", "shop.items"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
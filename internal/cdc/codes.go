@@ -0,0 +1,40 @@
+package cdc
+
+// Finding codes emitted by CDC checks.
+const (
+	CodeStatusUnavailable           = "CDC_STATUS_UNAVAILABLE"
+	CodeConnectorNotRunning         = "CDC_CONNECTOR_NOT_RUNNING"
+	CodeTaskNotRunning              = "CDC_TASK_NOT_RUNNING"
+	CodeRestartLoop                 = "CDC_RESTART_LOOP"
+	CodeHealthy                     = "CDC_HEALTHY"
+	CodeSchemaHistoryUnavailable    = "CDC_SCHEMA_HISTORY_UNAVAILABLE"
+	CodeSchemaHistoryMissing        = "CDC_SCHEMA_HISTORY_MISSING"
+	CodeSchemaHistoryUnreadable     = "CDC_SCHEMA_HISTORY_UNREADABLE"
+	CodeSchemaHistoryCoverageGap    = "CDC_SCHEMA_HISTORY_COVERAGE_GAP"
+	CodeSchemaHistoryHealthy        = "CDC_SCHEMA_HISTORY_HEALTHY"
+	CodeOffsetSnapshot              = "CDC_OFFSET_SNAPSHOT"
+	CodeOffsetsUnavailable          = "CDC_OFFSETS_UNAVAILABLE"
+	CodeLatencyOK                   = "CDC_LATENCY_OK"
+	CodeLatencyExceeded             = "CDC_LATENCY_EXCEEDED"
+	CodeLatencyUnknown              = "CDC_LATENCY_UNKNOWN"
+	CodeConnectorVersionOK          = "CDC_CONNECTOR_VERSION_OK"
+	CodeConnectorVersionUnknown     = "CDC_CONNECTOR_VERSION_UNKNOWN"
+	CodeConnectorUpgradeRequired    = "CDC_CONNECTOR_UPGRADE_REQUIRED"
+	CodeConnectorUpgradeRecommended = "CDC_CONNECTOR_UPGRADE_RECOMMENDED"
+	CodeKafkaAccessOK               = "CDC_KAFKA_ACCESS_OK"
+	CodeKafkaAccessUnknown          = "CDC_KAFKA_ACCESS_UNKNOWN"
+	CodeKafkaACLMissing             = "CDC_KAFKA_ACL_MISSING"
+	CodeKafkaQuotaThrottles         = "CDC_KAFKA_QUOTA_THROTTLES"
+	CodeRestarted                   = "CDC_RESTARTED"
+	CodeRestartTimeout              = "CDC_RESTART_TIMEOUT"
+	CodeAutoRemediated              = "CDC_AUTO_REMEDIATED"
+	CodeAutoRemediationExhausted    = "CDC_AUTO_REMEDIATION_EXHAUSTED"
+	CodeMessageSchemaBaseline       = "CDC_MESSAGE_SCHEMA_BASELINE"
+	CodeMessageSchemaUnchanged      = "CDC_MESSAGE_SCHEMA_UNCHANGED"
+	CodeMessageSchemaChanged        = "CDC_MESSAGE_SCHEMA_CHANGED"
+	CodeMessageSchemaUnknown        = "CDC_MESSAGE_SCHEMA_UNKNOWN"
+	CodeThroughputBaseline          = "CDC_THROUGHPUT_BASELINE"
+	CodeThroughputOK                = "CDC_THROUGHPUT_OK"
+	CodeThroughputDegraded          = "CDC_THROUGHPUT_DEGRADED"
+	CodeThroughputUnknown           = "CDC_THROUGHPUT_UNKNOWN"
+)
@@ -0,0 +1,36 @@
+package cdc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ConnectClockInspector implements checks.ClockInspector for Kafka Connect
+// workers, reading the Date header of the worker's REST root. Hosts are worker
+// URLs. Date has one-second resolution, so skew below a second goes unseen.
+type ConnectClockInspector struct {
+	Client *http.Client
+}
+
+func (i *ConnectClockInspector) Clock(ctx context.Context, worker string) (time.Time, error) {
+	client := i.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, worker, nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return time.Time{}, err
+	}
+	resp.Body.Close()
+	date := resp.Header.Get("Date")
+	if date == "" {
+		return time.Time{}, fmt.Errorf("worker %s sent no Date header", worker)
+	}
+	return http.ParseTime(date)
+}
@@ -0,0 +1,26 @@
+package cdc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestConnectClockInspector_ReadsDateHeader(t *testing.T) {
+	want := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	worker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", want.Format(http.TimeFormat))
+		w.Write([]byte(`{"version":"3.6.1"}`))
+	}))
+	defer worker.Close()
+
+	clock, err := (&ConnectClockInspector{}).Clock(context.Background(), worker.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !clock.Equal(want) {
+		t.Fatalf("expected %s, got %s", want, clock)
+	}
+}
@@ -0,0 +1,292 @@
+package cdc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"migratorx/internal/checks"
+	"migratorx/internal/failure"
+	"migratorx/internal/workflow"
+)
+
+// Restart defaults.
+const (
+	DefaultRestartTimeout      = 2 * time.Minute
+	DefaultRestartPollInterval = 2 * time.Second
+)
+
+// ConnectClient calls the Kafka Connect REST API at URL. It implements
+// DebeziumInspector and ConnectorRestarter.
+type ConnectClient struct {
+	URL    string
+	Client *http.Client
+}
+
+type connectStatusResponse struct {
+	Name      string `json:"name"`
+	Connector struct {
+		State    string `json:"state"`
+		WorkerID string `json:"worker_id"`
+	} `json:"connector"`
+	Tasks []struct {
+		ID       int    `json:"id"`
+		State    string `json:"state"`
+		WorkerID string `json:"worker_id"`
+		Trace    string `json:"trace"`
+	} `json:"tasks"`
+}
+
+func (c *ConnectClient) ConnectorStatus(ctx context.Context, connector string) (ConnectorStatus, error) {
+	body, err := c.do(ctx, http.MethodGet, "/connectors/"+url.PathEscape(connector)+"/status")
+	if err != nil {
+		return ConnectorStatus{}, err
+	}
+	var resp connectStatusResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return ConnectorStatus{}, fmt.Errorf("decode status of %s: %w", connector, err)
+	}
+	status := ConnectorStatus{Name: resp.Name, ConnectorState: resp.Connector.State, ConnectorWorker: resp.Connector.WorkerID}
+	for _, task := range resp.Tasks {
+		status.Tasks = append(status.Tasks, TaskStatus{ID: task.ID, State: task.State, Worker: task.WorkerID, Trace: task.Trace})
+	}
+	return status, nil
+}
+
+// RestartConnector restarts the connector and all of its tasks. Workers
+// before Kafka 3.0 ignore includeTasks and restart only the connector.
+func (c *ConnectClient) RestartConnector(ctx context.Context, connector string) error {
+	_, err := c.do(ctx, http.MethodPost, "/connectors/"+url.PathEscape(connector)+"/restart?includeTasks=true")
+	return err
+}
+
+func (c *ConnectClient) RestartTask(ctx context.Context, connector string, task int) error {
+	_, err := c.do(ctx, http.MethodPost, fmt.Sprintf("/connectors/%s/tasks/%d/restart", url.PathEscape(connector), task))
+	return err
+}
+
+func (c *ConnectClient) do(ctx context.Context, method string, path string) ([]byte, error) {
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(c.URL, "/")+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		var connectErr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(body, &connectErr) == nil && connectErr.Message != "" {
+			return nil, fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, connectErr.Message)
+		}
+		return nil, fmt.Errorf("%s %s: %s", method, path, resp.Status)
+	}
+	return body, nil
+}
+
+// ConnectorRestarter restarts a connector or one of its tasks.
+type ConnectorRestarter interface {
+	RestartConnector(ctx context.Context, connector string) error
+	RestartTask(ctx context.Context, connector string, task int) error
+}
+
+// RestartOrchestrator restarts a connector, or one task with Task set, and
+// waits until it reports RUNNING. The request is checkpointed before it is
+// sent, so a run that timed out resumes waiting instead of restarting again;
+// the checkpoint is cleared once the connector is running.
+type RestartOrchestrator struct {
+	Restarter    ConnectorRestarter
+	Inspector    DebeziumInspector
+	State        workflow.State
+	Timeout      time.Duration
+	PollInterval time.Duration
+	Logger       *log.Logger
+}
+
+// AllTasks restarts the connector with all of its tasks.
+const AllTasks = -1
+
+// Restart restarts task of connector, or the whole connector for AllTasks.
+// Failing to send the restart is an error; not reaching RUNNING within
+// Timeout is a BLOCK.
+func (o *RestartOrchestrator) Restart(ctx context.Context, connector string, task int) ([]checks.Finding, error) {
+	if o.Restarter == nil || o.Inspector == nil {
+		return nil, fmt.Errorf("connector restarter and inspector are required")
+	}
+	if strings.TrimSpace(connector) == "" {
+		return nil, fmt.Errorf("connector name is required")
+	}
+	target := restartTarget(connector, task)
+	meta := map[string]interface{}{"connector": connector}
+	if task != AllTasks {
+		meta["task"] = task
+	}
+
+	findings := []checks.Finding{}
+	key := restartRequestedKey(connector, task)
+	if requested, ok := o.get(key); ok {
+		o.logger().Printf("%s restart was requested at %v; waiting for it instead of restarting again", target, requested)
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityInfo,
+			Message:  fmt.Sprintf("resuming the wait for the %s restart requested at %v", target, requested),
+			Meta:     meta,
+		})
+	} else {
+		o.set(key, time.Now().UTC().Format(time.RFC3339))
+		o.logger().Printf("restarting %s", target)
+		var err error
+		if task == AllTasks {
+			err = o.Restarter.RestartConnector(ctx, connector)
+		} else {
+			err = o.Restarter.RestartTask(ctx, connector, task)
+		}
+		if err != nil {
+			o.set(key, nil)
+			return nil, failure.Act(connector, "failed to restart "+target, err)
+		}
+	}
+
+	status, running, err := o.waitRunning(ctx, connector, task)
+	if err != nil {
+		return nil, err
+	}
+	if !running {
+		meta["connector_state"] = status.ConnectorState
+		for _, t := range status.Tasks {
+			if t.State != "RUNNING" {
+				meta[fmt.Sprintf("task:%d", t.ID)] = t.State
+				if t.Trace != "" {
+					meta[fmt.Sprintf("trace:%d", t.ID)] = t.Trace
+				}
+			}
+		}
+		return append(findings, checks.Finding{
+			Severity: checks.SeverityBlock,
+			Code:     CodeRestartTimeout,
+			Message:  fmt.Sprintf("%s is not RUNNING %s after the restart", target, o.timeout()),
+			Meta:     meta,
+		}), nil
+	}
+	o.set(key, nil)
+	o.set(restartedKey(connector), time.Now().UTC().Format(time.RFC3339))
+	return append(findings, checks.Finding{
+		Severity: checks.SeverityInfo,
+		Code:     CodeRestarted,
+		Message:  fmt.Sprintf("%s restarted and is RUNNING", target),
+		Meta:     meta,
+	}), nil
+}
+
+// waitRunning polls the connector status until the connector and the
+// restarted tasks are RUNNING, or Timeout passes. The first poll waits one
+// interval, so the status from before the restart is not mistaken for its
+// result. Status read errors are retried until the timeout.
+func (o *RestartOrchestrator) waitRunning(ctx context.Context, connector string, task int) (ConnectorStatus, bool, error) {
+	interval := o.PollInterval
+	if interval <= 0 {
+		interval = DefaultRestartPollInterval
+	}
+	deadline := time.NewTimer(o.timeout())
+	defer deadline.Stop()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var last ConnectorStatus
+	for {
+		select {
+		case <-ctx.Done():
+			return last, false, ctx.Err()
+		case <-deadline.C:
+			return last, false, nil
+		case <-ticker.C:
+		}
+		status, err := o.Inspector.ConnectorStatus(ctx, connector)
+		if err != nil {
+			o.logger().Printf("reading %s status: %v", connector, err)
+			continue
+		}
+		last = status
+		if restartedRunning(status, task) {
+			return status, true, nil
+		}
+	}
+}
+
+// restartedRunning reports whether the connector is RUNNING along with the
+// restarted task, or every task for AllTasks.
+func restartedRunning(status ConnectorStatus, task int) bool {
+	if status.ConnectorState != "RUNNING" {
+		return false
+	}
+	found := false
+	for _, t := range status.Tasks {
+		if task != AllTasks && t.ID != task {
+			continue
+		}
+		found = true
+		if t.State != "RUNNING" {
+			return false
+		}
+	}
+	return found || task == AllTasks
+}
+
+func (o *RestartOrchestrator) timeout() time.Duration {
+	if o.Timeout <= 0 {
+		return DefaultRestartTimeout
+	}
+	return o.Timeout
+}
+
+func (o *RestartOrchestrator) get(key string) (interface{}, bool) {
+	if o.State == nil {
+		return nil, false
+	}
+	value, ok := o.State.Get(key)
+	return value, ok && value != nil
+}
+
+func (o *RestartOrchestrator) set(key string, value interface{}) {
+	if o.State != nil {
+		o.State.Set(key, value)
+	}
+}
+
+func (o *RestartOrchestrator) logger() *log.Logger {
+	if o.Logger == nil {
+		return log.Default()
+	}
+	return o.Logger
+}
+
+func restartTarget(connector string, task int) string {
+	if task == AllTasks {
+		return fmt.Sprintf("connector %s", connector)
+	}
+	return fmt.Sprintf("task %d of connector %s", task, connector)
+}
+
+func restartRequestedKey(connector string, task int) string {
+	if task == AllTasks {
+		return fmt.Sprintf("cdc:%s:restart_requested", connector)
+	}
+	return fmt.Sprintf("cdc:%s:task:%d:restart_requested", connector, task)
+}
+
+func restartedKey(connector string) string { return fmt.Sprintf("cdc:%s:restarted", connector) }
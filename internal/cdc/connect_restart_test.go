@@ -0,0 +1,145 @@
+package cdc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"migratorx/internal/failure"
+)
+
+type memState map[string]interface{}
+
+func (m memState) Get(key string) (interface{}, bool) { v, ok := m[key]; return v, ok }
+func (m memState) Set(key string, value interface{})  { m[key] = value }
+func (m memState) MarkCompleted(step string)          { m[step] = true }
+func (m memState) IsCompleted(step string) bool       { return m[step] == true }
+
+// fakeConnect serves the status and restart endpoints. Tasks report
+// RESTARTING until runningAfter status calls have been made since a restart.
+type fakeConnect struct {
+	mu           sync.Mutex
+	restarts     []string
+	polls        int
+	runningAfter int
+	restartCode  int
+}
+
+func (f *fakeConnect) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.Method == http.MethodPost {
+		if f.restartCode != 0 {
+			w.WriteHeader(f.restartCode)
+			fmt.Fprint(w, `{"error_code":409,"message":"Cannot complete request momentarily due to no known leader URL"}`)
+			return
+		}
+		f.restarts = append(f.restarts, r.URL.RequestURI())
+		f.polls = 0
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	f.polls++
+	state := "RESTARTING"
+	if f.polls > f.runningAfter {
+		state = "RUNNING"
+	}
+	fmt.Fprintf(w, `{"name":"mysql-prod","connector":{"state":"RUNNING","worker_id":"w1:8083"},"tasks":[{"id":0,"state":%q,"worker_id":"w1:8083"}]}`, state)
+}
+
+func TestRestartOrchestrator_RestartsAndWaitsForRunning(t *testing.T) {
+	connect := &fakeConnect{runningAfter: 2}
+	server := httptest.NewServer(connect)
+	defer server.Close()
+	client := &ConnectClient{URL: server.URL}
+	st := memState{}
+	o := &RestartOrchestrator{Restarter: client, Inspector: client, State: st, PollInterval: time.Millisecond, Timeout: time.Second}
+
+	findings, err := o.Restart(context.Background(), "mysql-prod", AllTasks)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Code != CodeRestarted {
+		t.Fatalf("expected restarted finding, got %+v", findings)
+	}
+	if len(connect.restarts) != 1 || connect.restarts[0] != "/connectors/mysql-prod/restart?includeTasks=true" {
+		t.Fatalf("unexpected restart calls: %v", connect.restarts)
+	}
+	if _, ok := o.get(restartRequestedKey("mysql-prod", AllTasks)); ok {
+		t.Fatalf("expected restart request checkpoint to be cleared")
+	}
+	if _, ok := st[restartedKey("mysql-prod")]; !ok {
+		t.Fatalf("expected restarted checkpoint")
+	}
+}
+
+func TestRestartOrchestrator_TimeoutBlocksAndResumesWithoutRestarting(t *testing.T) {
+	connect := &fakeConnect{runningAfter: 1000}
+	server := httptest.NewServer(connect)
+	defer server.Close()
+	client := &ConnectClient{URL: server.URL}
+	st := memState{}
+	o := &RestartOrchestrator{Restarter: client, Inspector: client, State: st, PollInterval: time.Millisecond, Timeout: 20 * time.Millisecond}
+
+	findings, err := o.Restart(context.Background(), "mysql-prod", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	last := findings[len(findings)-1]
+	if last.Code != CodeRestartTimeout || last.Meta["task:0"] != "RESTARTING" {
+		t.Fatalf("expected restart timeout with task state, got %+v", findings)
+	}
+	if connect.restarts[0] != "/connectors/mysql-prod/tasks/0/restart" {
+		t.Fatalf("unexpected restart call: %v", connect.restarts)
+	}
+
+	connect.runningAfter = 0
+	o.Timeout = time.Second
+	findings, err = o.Restart(context.Background(), "mysql-prod", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 2 || findings[1].Code != CodeRestarted {
+		t.Fatalf("expected resumed wait and restarted finding, got %+v", findings)
+	}
+	if len(connect.restarts) != 1 {
+		t.Fatalf("expected the resumed run not to restart again, got %v", connect.restarts)
+	}
+}
+
+func TestRestartOrchestrator_RestartFailureIsActionError(t *testing.T) {
+	server := httptest.NewServer(&fakeConnect{restartCode: http.StatusConflict})
+	defer server.Close()
+	client := &ConnectClient{URL: server.URL}
+	st := memState{}
+	o := &RestartOrchestrator{Restarter: client, Inspector: client, State: st, PollInterval: time.Millisecond}
+
+	_, err := o.Restart(context.Background(), "mysql-prod", AllTasks)
+	if failure.KindOf(err) != failure.KindAction {
+		t.Fatalf("expected action error, got %v", err)
+	}
+	var actionErr *failure.ActionError
+	if !errors.As(err, &actionErr) {
+		t.Fatalf("expected ActionError, got %T", err)
+	}
+	if _, ok := o.get(restartRequestedKey("mysql-prod", AllTasks)); ok {
+		t.Fatalf("expected failed restart not to leave a checkpoint")
+	}
+}
+
+func TestConnectClient_ConnectorStatus(t *testing.T) {
+	server := httptest.NewServer(&fakeConnect{})
+	defer server.Close()
+	status, err := (&ConnectClient{URL: server.URL}).ConnectorStatus(context.Background(), "mysql-prod")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status.ConnectorState != "RUNNING" || len(status.Tasks) != 1 || status.Tasks[0].Worker != "w1:8083" {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+}
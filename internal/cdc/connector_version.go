@@ -0,0 +1,164 @@
+package cdc
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"migratorx/internal/checks"
+)
+
+// MySQLConnectorClass is the Kafka Connect plugin class of the Debezium MySQL
+// connector.
+const MySQLConnectorClass = "io.debezium.connector.mysql.MySqlConnector"
+
+// ConnectorPlugin is one entry of Kafka Connect's GET /connector-plugins.
+type ConnectorPlugin struct {
+	Class   string `json:"class"`
+	Type    string `json:"type,omitempty"`
+	Version string `json:"version"`
+}
+
+// PluginInspector lists the connector plugins installed on the Connect cluster.
+type PluginInspector interface {
+	ConnectorPlugins(ctx context.Context) ([]ConnectorPlugin, error)
+}
+
+// debeziumRequirement is a minimum Debezium release for a MySQL target series.
+type debeziumRequirement struct {
+	Target     string
+	MinVersion string
+	Severity   checks.Severity
+	Reason     string
+}
+
+// debeziumMySQLMatrix lists, per target series, the Debezium releases the
+// connector must be upgraded to before the database. BLOCK rows are hard
+// requirements; WARN rows are releases with known fixes for the target.
+var debeziumMySQLMatrix = []debeziumRequirement{
+	{Target: "8.0", MinVersion: "1.0", Severity: checks.SeverityBlock, Reason: "earlier releases cannot read MySQL 8.0 binlogs or authenticate with caching_sha2_password"},
+	{Target: "8.0", MinVersion: "1.9", Severity: checks.SeverityWarn, Reason: "earlier releases miss MySQL 8.0 DDL parser and binlog client fixes"},
+	{Target: "8.4", MinVersion: "2.7", Severity: checks.SeverityBlock, Reason: "earlier releases use statements MySQL 8.4 removed (SHOW MASTER STATUS)"},
+}
+
+// ConnectorVersionCheck validates the installed Debezium MySQL connector
+// against the built-in matrix for the plan's target version, so a connector
+// that must be upgraded first is caught before the database is.
+type ConnectorVersionCheck struct {
+	Inspector     PluginInspector
+	TargetVersion string
+}
+
+func (c *ConnectorVersionCheck) Name() string   { return "cdc_connector_version" }
+func (c *ConnectorVersionCheck) ReadOnly() bool { return true }
+
+func (c *ConnectorVersionCheck) Parameters() map[string]interface{} {
+	return map[string]interface{}{"target_version": c.TargetVersion}
+}
+
+func (c *ConnectorVersionCheck) Run(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+	if c.Inspector == nil {
+		return nil, fmt.Errorf("plugin inspector is required")
+	}
+	target := c.TargetVersion
+	if target == "" {
+		target = input.PlanTargetVersion
+	}
+	plugins, err := c.Inspector.ConnectorPlugins(ctx)
+	if err != nil {
+		return []checks.Finding{unknownConnectorVersion(fmt.Sprintf("failed to list Connect plugins: %v", err), "")}, nil
+	}
+	version := ""
+	for _, p := range plugins {
+		if p.Class == MySQLConnectorClass {
+			version = p.Version
+			break
+		}
+	}
+	if version == "" {
+		return []checks.Finding{unknownConnectorVersion(fmt.Sprintf("Connect does not report a %s plugin version", MySQLConnectorClass), "")}, nil
+	}
+	installed, ok := parseVersion(version)
+	if !ok {
+		return []checks.Finding{unknownConnectorVersion(fmt.Sprintf("cannot parse Debezium version %q", version), version)}, nil
+	}
+
+	findings := []checks.Finding{}
+	for _, req := range debeziumMySQLMatrix {
+		if !sameSeries(target, req.Target) {
+			continue
+		}
+		min, _ := parseVersion(req.MinVersion)
+		if compareVersions(installed, min) >= 0 {
+			continue
+		}
+		code := CodeConnectorUpgradeRequired
+		verb := "must be upgraded"
+		if req.Severity != checks.SeverityBlock {
+			code = CodeConnectorUpgradeRecommended
+			verb = "should be upgraded"
+		}
+		findings = append(findings, checks.Finding{
+			Severity: req.Severity,
+			Code:     code,
+			Message:  fmt.Sprintf("Debezium %s %s to %s or later before upgrading MySQL to %s: %s", version, verb, req.MinVersion, target, req.Reason),
+			Meta:     map[string]interface{}{"version": version, "min_version": req.MinVersion, "target_version": target},
+		})
+	}
+	if len(findings) > 0 {
+		return findings, nil
+	}
+	return []checks.Finding{{
+		Severity: checks.SeverityInfo,
+		Code:     CodeConnectorVersionOK,
+		Message:  fmt.Sprintf("Debezium %s supports MySQL %s", version, target),
+		Meta:     map[string]interface{}{"version": version, "target_version": target},
+	}}, nil
+}
+
+func unknownConnectorVersion(message string, version string) checks.Finding {
+	meta := map[string]interface{}{"class": MySQLConnectorClass}
+	if version != "" {
+		meta["version"] = version
+	}
+	return checks.Finding{Severity: checks.SeverityWarn, Code: CodeConnectorVersionUnknown, Message: message, Meta: meta}
+}
+
+// sameSeries reports whether version (e.g. "8.0.36") belongs to series ("8.0").
+func sameSeries(version string, series string) bool {
+	return version == series || strings.HasPrefix(version, series+".")
+}
+
+// parseVersion reads the leading numeric components of a release such as
+// "2.5.4.Final" or "1.9.7-SNAPSHOT".
+func parseVersion(v string) ([]int, bool) {
+	parts := []int{}
+	for _, field := range strings.FieldsFunc(v, func(r rune) bool { return r == '.' || r == '-' }) {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+	}
+	return parts, len(parts) > 0
+}
+
+func compareVersions(a []int, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
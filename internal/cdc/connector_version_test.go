@@ -0,0 +1,83 @@
+package cdc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"migratorx/internal/checks"
+)
+
+type fakePluginInspector struct {
+	plugins []ConnectorPlugin
+	err     error
+}
+
+func (f *fakePluginInspector) ConnectorPlugins(ctx context.Context) ([]ConnectorPlugin, error) {
+	return f.plugins, f.err
+}
+
+func mysqlPlugin(version string) *fakePluginInspector {
+	return &fakePluginInspector{plugins: []ConnectorPlugin{
+		{Class: "org.apache.kafka.connect.mirror.MirrorSourceConnector", Version: "3.6.0"},
+		{Class: MySQLConnectorClass, Type: "source", Version: version},
+	}}
+}
+
+func TestConnectorVersionCheck_Matrix(t *testing.T) {
+	cases := []struct {
+		version string
+		target  string
+		codes   []string
+	}{
+		{"2.5.4.Final", "8.0", []string{CodeConnectorVersionOK}},
+		{"1.9.7.Final", "8.0.36", []string{CodeConnectorVersionOK}},
+		{"1.4.2.Final", "8.0", []string{CodeConnectorUpgradeRecommended}},
+		{"0.10.0.Final", "8.0", []string{CodeConnectorUpgradeRequired, CodeConnectorUpgradeRecommended}},
+		{"2.5.4.Final", "8.4", []string{CodeConnectorUpgradeRequired}},
+		{"2.7.0.Final", "8.4.2", []string{CodeConnectorVersionOK}},
+		{"1.0.0.Final", "5.7", []string{CodeConnectorVersionOK}},
+	}
+	for _, tc := range cases {
+		check := &ConnectorVersionCheck{Inspector: mysqlPlugin(tc.version), TargetVersion: tc.target}
+		findings, err := check.Run(context.Background(), checks.Input{})
+		if err != nil {
+			t.Fatalf("%s/%s: unexpected error: %v", tc.version, tc.target, err)
+		}
+		if len(findings) != len(tc.codes) {
+			t.Fatalf("%s/%s: expected %v, got %+v", tc.version, tc.target, tc.codes, findings)
+		}
+		for i, code := range tc.codes {
+			if findings[i].Code != code {
+				t.Fatalf("%s/%s: expected %v, got %+v", tc.version, tc.target, tc.codes, findings)
+			}
+		}
+	}
+}
+
+func TestConnectorVersionCheck_UsesPlanTargetAndBlocks(t *testing.T) {
+	check := &ConnectorVersionCheck{Inspector: mysqlPlugin("0.9.5.Final")}
+	findings, err := check.Run(context.Background(), checks.Input{PlanTargetVersion: "8.0"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if findings[0].Severity != checks.SeverityBlock || findings[0].Meta["min_version"] != "1.0" {
+		t.Fatalf("expected BLOCK requiring 1.0, got %+v", findings)
+	}
+}
+
+func TestConnectorVersionCheck_UnknownVersion(t *testing.T) {
+	for _, inspector := range []*fakePluginInspector{
+		{err: errors.New("connection refused")},
+		{plugins: []ConnectorPlugin{{Class: "other.Connector", Version: "1.0"}}},
+		mysqlPlugin("unknown"),
+	} {
+		findings, err := (&ConnectorVersionCheck{Inspector: inspector, TargetVersion: "8.0"}).Run(context.Background(), checks.Input{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(findings) != 1 || findings[0].Code != CodeConnectorVersionUnknown || findings[0].Severity != checks.SeverityWarn {
+			t.Fatalf("expected WARN unknown version, got %+v", findings)
+		}
+	}
+}
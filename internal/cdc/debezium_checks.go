@@ -9,13 +9,16 @@ import (
 )
 
 // ConnectorStatus models Debezium connector status response (simplified).
+// MillisBehindSource is the streaming MilliSecondsBehindSource metric, when
+// the inspector can read it.
 type ConnectorStatus struct {
-	Name            string
-	ConnectorState  string
-	ConnectorWorker string
-	Tasks           []TaskStatus
-	RestartCount    int
-	LastRestartAt   *time.Time
+	Name               string
+	ConnectorState     string
+	ConnectorWorker    string
+	Tasks              []TaskStatus
+	RestartCount       int
+	LastRestartAt      *time.Time
+	MillisBehindSource *int64
 }
 
 // TaskStatus models a Debezium task status.
@@ -32,16 +35,22 @@ type DebeziumInspector interface {
 }
 
 // DebeziumHealthCheck validates connector/task health and restart stability.
+// When MaxLatency is set it also measures streaming latency against it.
 type DebeziumHealthCheck struct {
 	Inspector          DebeziumInspector
 	Connector          string
 	RestartLoopWindow  time.Duration
 	RestartLoopMax     int
+	MaxLatency         time.Duration
 }
 
 func (c *DebeziumHealthCheck) Name() string   { return "cdc_debezium_health" }
 func (c *DebeziumHealthCheck) ReadOnly() bool { return true }
 
+func (c *DebeziumHealthCheck) Parameters() map[string]interface{} {
+	return map[string]interface{}{"connector": c.Connector, "restart_loop_window": c.RestartLoopWindow.String(), "restart_loop_max": c.RestartLoopMax, "max_latency": c.MaxLatency.String()}
+}
+
 func (c *DebeziumHealthCheck) Run(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
 	if c.Inspector == nil {
 		return nil, fmt.Errorf("debezium inspector is required")
@@ -60,6 +69,7 @@ func (c *DebeziumHealthCheck) Run(ctx context.Context, input checks.Input) ([]ch
 	if err != nil {
 		return []checks.Finding{{
 			Severity: checks.SeverityBlock,
+			Code:     CodeStatusUnavailable,
 			Message:  fmt.Sprintf("failed to read Debezium connector status: %v", err),
 			Meta:     map[string]interface{}{"connector": c.Connector},
 		}}, nil
@@ -69,6 +79,7 @@ func (c *DebeziumHealthCheck) Run(ctx context.Context, input checks.Input) ([]ch
 	if status.ConnectorState != "RUNNING" {
 		findings = append(findings, checks.Finding{
 			Severity: checks.SeverityBlock,
+			Code:     CodeConnectorNotRunning,
 			Message:  fmt.Sprintf("connector %q is %s (expected RUNNING)", status.Name, status.ConnectorState),
 			Meta:     map[string]interface{}{"connector": status.Name, "state": status.ConnectorState},
 		})
@@ -78,6 +89,7 @@ func (c *DebeziumHealthCheck) Run(ctx context.Context, input checks.Input) ([]ch
 		if task.State != "RUNNING" {
 			findings = append(findings, checks.Finding{
 				Severity: checks.SeverityBlock,
+				Code:     CodeTaskNotRunning,
 				Message:  fmt.Sprintf("connector %q task %d is %s", status.Name, task.ID, task.State),
 				Meta:     map[string]interface{}{"connector": status.Name, "task_id": task.ID, "state": task.State, "trace": task.Trace},
 			})
@@ -87,6 +99,7 @@ func (c *DebeziumHealthCheck) Run(ctx context.Context, input checks.Input) ([]ch
 	if isRestartLoop(status, c.RestartLoopWindow, c.RestartLoopMax) {
 		findings = append(findings, checks.Finding{
 			Severity: checks.SeverityBlock,
+			Code:     CodeRestartLoop,
 			Message:  fmt.Sprintf("connector %q appears to be in a restart loop (%d restarts within %s)", status.Name, status.RestartCount, c.RestartLoopWindow),
 			Meta:     map[string]interface{}{"connector": status.Name, "restart_count": status.RestartCount, "window": c.RestartLoopWindow.String()},
 		})
@@ -95,14 +108,46 @@ func (c *DebeziumHealthCheck) Run(ctx context.Context, input checks.Input) ([]ch
 	if len(findings) == 0 {
 		findings = append(findings, checks.Finding{
 			Severity: checks.SeverityInfo,
+			Code:     CodeHealthy,
 			Message:  fmt.Sprintf("connector %q and tasks are RUNNING", status.Name),
 			Meta:     map[string]interface{}{"connector": status.Name},
 		})
 	}
+	if c.MaxLatency > 0 {
+		findings = append(findings, latencyFinding(status, c.MaxLatency))
+	}
 
 	return findings, nil
 }
 
+func latencyFinding(status ConnectorStatus, max time.Duration) checks.Finding {
+	threshold := checks.DurationThreshold("max_cdc_latency", max)
+	if status.MillisBehindSource == nil {
+		return checks.Finding{
+			Severity: checks.SeverityWarn,
+			Code:     CodeLatencyUnknown,
+			Message:  fmt.Sprintf("connector %q does not report MilliSecondsBehindSource; latency cannot be checked against %s", status.Name, max),
+			Meta:     map[string]interface{}{"connector": status.Name},
+		}
+	}
+	measured := float64(*status.MillisBehindSource) / 1000
+	meta := map[string]interface{}{"connector": status.Name, "threshold": threshold.Meta(measured)}
+	if threshold.Exceeded(measured) {
+		return checks.Finding{
+			Severity: checks.SeverityWarn,
+			Code:     CodeLatencyExceeded,
+			Message:  fmt.Sprintf("connector %q is %.1fs behind the source (limit %.1fs)", status.Name, measured, threshold.Limit),
+			Meta:     meta,
+		}
+	}
+	return checks.Finding{
+		Severity: checks.SeverityInfo,
+		Code:     CodeLatencyOK,
+		Message:  fmt.Sprintf("connector %q is %.1fs behind the source (limit %.1fs)", status.Name, measured, threshold.Limit),
+		Meta:     meta,
+	}
+}
+
 func isRestartLoop(status ConnectorStatus, window time.Duration, max int) bool {
 	if status.LastRestartAt == nil {
 		return false
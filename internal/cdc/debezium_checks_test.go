@@ -98,4 +98,33 @@ func hasSeverity(findings []checks.Finding, severity checks.Severity) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
+
+func TestDebeziumHealthCheck_LatencyAgainstThreshold(t *testing.T) {
+	behind := int64(8000)
+	inspector := &fakeDebeziumInspector{status: ConnectorStatus{
+		Name:               "conn",
+		ConnectorState:     "RUNNING",
+		Tasks:              []TaskStatus{{ID: 0, State: "RUNNING"}},
+		MillisBehindSource: &behind,
+	}}
+	check := &DebeziumHealthCheck{Inspector: inspector, Connector: "conn", MaxLatency: 5 * time.Second}
+	findings, err := check.Run(context.Background(), checks.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	last := findings[len(findings)-1]
+	if last.Code != CodeLatencyExceeded || last.Severity != checks.SeverityWarn {
+		t.Fatalf("expected latency WARN, got %+v", findings)
+	}
+	threshold := last.Meta["threshold"].(map[string]interface{})
+	if threshold["measured"] != 8.0 || threshold["margin"] != -3.0 {
+		t.Fatalf("unexpected threshold meta: %+v", threshold)
+	}
+
+	inspector.status.MillisBehindSource = nil
+	findings, _ = check.Run(context.Background(), checks.Input{})
+	if findings[len(findings)-1].Code != CodeLatencyUnknown {
+		t.Fatalf("expected unknown latency WARN, got %+v", findings)
+	}
+}
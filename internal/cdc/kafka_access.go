@@ -0,0 +1,216 @@
+package cdc
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"migratorx/internal/checks"
+)
+
+// KafkaACL is one ACL binding as listed by kafka-acls --list or the
+// AdminClient's describeAcls. PatternType is LITERAL or PREFIXED; Permission
+// is ALLOW or DENY.
+type KafkaACL struct {
+	Principal    string
+	ResourceType string
+	ResourceName string
+	PatternType  string
+	Operation    string
+	Permission   string
+}
+
+// KafkaQuota is a client quota that applies to the principal, as listed by
+// kafka-configs --describe. Zero rates are unset.
+type KafkaQuota struct {
+	Entity            string
+	ProducerByteRate  int64
+	ConsumerByteRate  int64
+	RequestPercentage float64
+}
+
+// KafkaAccessInspector reads the ACLs and quotas that apply to a principal.
+type KafkaAccessInspector interface {
+	ACLs(ctx context.Context, principal string) ([]KafkaACL, error)
+	Quotas(ctx context.Context, principal string) ([]KafkaQuota, error)
+}
+
+// topicOperations are the topic operations the Debezium MySQL connector needs:
+// it produces change events to the data topics, and writes and reads back the
+// schema history topic on restart.
+var (
+	dataTopicOperations          = []string{"DESCRIBE", "WRITE"}
+	schemaHistoryTopicOperations = []string{"DESCRIBE", "READ", "WRITE"}
+)
+
+// KafkaAccessCheck verifies the connector's principal holds the ACLs it needs
+// on the data topics (every topic under TopicPrefix) and the schema history
+// topic, and that no quota throttles it below PeakByteRate. A missing or
+// denied ACL stops the connector and is a BLOCK. A quota only slows it down,
+// so one that would cause lag spikes during the high-churn cutover window is a
+// WARN. Without PeakByteRate, any producer or request quota is a WARN.
+type KafkaAccessCheck struct {
+	Inspector          KafkaAccessInspector
+	Principal          string
+	TopicPrefix        string
+	SchemaHistoryTopic string
+	PeakByteRate       int64
+}
+
+func (c *KafkaAccessCheck) Name() string   { return "cdc_kafka_access" }
+func (c *KafkaAccessCheck) ReadOnly() bool { return true }
+
+func (c *KafkaAccessCheck) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"principal":            c.Principal,
+		"topic_prefix":         c.TopicPrefix,
+		"schema_history_topic": c.SchemaHistoryTopic,
+		"peak_byte_rate":       c.PeakByteRate,
+	}
+}
+
+func (c *KafkaAccessCheck) Run(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+	if c.Inspector == nil {
+		return nil, fmt.Errorf("kafka access inspector is required")
+	}
+	if strings.TrimSpace(c.Principal) == "" {
+		return nil, fmt.Errorf("connector principal is required")
+	}
+	if c.TopicPrefix == "" && c.SchemaHistoryTopic == "" {
+		return nil, fmt.Errorf("a topic prefix or schema history topic is required")
+	}
+
+	findings := []checks.Finding{}
+	acls, err := c.Inspector.ACLs(ctx, c.Principal)
+	if err != nil {
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityBlock,
+			Code:     CodeKafkaAccessUnknown,
+			Message:  fmt.Sprintf("unable to list ACLs for %s: %v", c.Principal, err),
+			Meta:     map[string]interface{}{"principal": c.Principal},
+		})
+	} else {
+		if c.TopicPrefix != "" {
+			findings = append(findings, c.aclFindings(acls, "data", c.TopicPrefix+".", true, dataTopicOperations)...)
+		}
+		if c.SchemaHistoryTopic != "" {
+			findings = append(findings, c.aclFindings(acls, "schema history", c.SchemaHistoryTopic, false, schemaHistoryTopicOperations)...)
+		}
+	}
+
+	quotas, err := c.Inspector.Quotas(ctx, c.Principal)
+	if err != nil {
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityWarn,
+			Code:     CodeKafkaAccessUnknown,
+			Message:  fmt.Sprintf("unable to list quotas for %s: %v", c.Principal, err),
+			Meta:     map[string]interface{}{"principal": c.Principal},
+		})
+	} else {
+		findings = append(findings, c.quotaFindings(quotas)...)
+	}
+
+	if len(findings) == 0 {
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityInfo,
+			Code:     CodeKafkaAccessOK,
+			Message:  fmt.Sprintf("%s has the topic ACLs the connector needs and no throttling quota", c.Principal),
+			Meta:     map[string]interface{}{"principal": c.Principal, "acls": len(acls), "quotas": len(quotas)},
+		})
+	}
+	return findings, nil
+}
+
+// aclFindings reports each operation the principal is denied or not granted
+// on topic. With prefixed, topic stands for every topic starting with it, so
+// only a PREFIXED or wildcard ACL covering the whole prefix grants it.
+func (c *KafkaAccessCheck) aclFindings(acls []KafkaACL, kind string, topic string, prefixed bool, operations []string) []checks.Finding {
+	findings := []checks.Finding{}
+	for _, op := range operations {
+		allowed, denied := false, false
+		for _, acl := range acls {
+			if !aclApplies(acl, c.Principal, topic, prefixed, op) {
+				continue
+			}
+			switch strings.ToUpper(acl.Permission) {
+			case "DENY":
+				denied = true
+			case "ALLOW":
+				allowed = true
+			}
+		}
+		if allowed && !denied {
+			continue
+		}
+		reason := "is not granted"
+		if denied {
+			reason = "is denied"
+		}
+		subject := fmt.Sprintf("topic %q", topic)
+		if prefixed {
+			subject = fmt.Sprintf("topics with prefix %q", topic)
+		}
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityBlock,
+			Code:     CodeKafkaACLMissing,
+			Message:  fmt.Sprintf("%s %s %s on %s %s", c.Principal, reason, op, kind, subject),
+			Meta:     map[string]interface{}{"principal": c.Principal, "topic": topic, "prefixed": prefixed, "operation": op, "denied": denied},
+		})
+	}
+	return findings
+}
+
+// aclApplies reports whether acl governs op on topic for principal, following
+// Kafka's matching: a wildcard principal or resource matches everything, and
+// ALL covers every operation.
+func aclApplies(acl KafkaACL, principal string, topic string, prefixed bool, op string) bool {
+	if !strings.EqualFold(acl.ResourceType, "TOPIC") {
+		return false
+	}
+	if acl.Principal != principal && acl.Principal != "User:*" {
+		return false
+	}
+	if !strings.EqualFold(acl.Operation, op) && !strings.EqualFold(acl.Operation, "ALL") {
+		return false
+	}
+	if acl.ResourceName == "*" {
+		return true
+	}
+	switch strings.ToUpper(acl.PatternType) {
+	case "PREFIXED":
+		return strings.HasPrefix(topic, acl.ResourceName)
+	case "", "LITERAL":
+		return !prefixed && acl.ResourceName == topic
+	}
+	return false
+}
+
+// quotaFindings warns about quotas that would throttle the connector: a
+// producer byte rate below the expected peak, or any request percentage.
+func (c *KafkaAccessCheck) quotaFindings(quotas []KafkaQuota) []checks.Finding {
+	sort.Slice(quotas, func(i, j int) bool { return quotas[i].Entity < quotas[j].Entity })
+	findings := []checks.Finding{}
+	for _, q := range quotas {
+		meta := map[string]interface{}{"principal": c.Principal, "entity": q.Entity, "producer_byte_rate": q.ProducerByteRate, "peak_byte_rate": c.PeakByteRate}
+		if q.ProducerByteRate > 0 && (c.PeakByteRate == 0 || q.ProducerByteRate < c.PeakByteRate) {
+			message := fmt.Sprintf("quota %s limits %s to %d B/s produced", q.Entity, c.Principal, q.ProducerByteRate)
+			if c.PeakByteRate > 0 {
+				message += fmt.Sprintf(", below the expected cutover peak of %d B/s; expect lag spikes", c.PeakByteRate)
+			} else {
+				message += "; set cdc.peak_byte_rate to compare it with the cutover peak"
+			}
+			findings = append(findings, checks.Finding{Severity: checks.SeverityWarn, Code: CodeKafkaQuotaThrottles, Message: message, Meta: meta})
+		}
+		if q.RequestPercentage > 0 {
+			meta["request_percentage"] = q.RequestPercentage
+			findings = append(findings, checks.Finding{
+				Severity: checks.SeverityWarn,
+				Code:     CodeKafkaQuotaThrottles,
+				Message:  fmt.Sprintf("quota %s limits %s to %.0f%% of broker request time; bursts will be throttled", q.Entity, c.Principal, q.RequestPercentage),
+				Meta:     meta,
+			})
+		}
+	}
+	return findings
+}
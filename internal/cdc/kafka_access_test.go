@@ -0,0 +1,95 @@
+package cdc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"migratorx/internal/checks"
+)
+
+type fakeKafkaAccess struct {
+	acls      []KafkaACL
+	quotas    []KafkaQuota
+	aclErr    error
+	quotasErr error
+}
+
+func (f *fakeKafkaAccess) ACLs(ctx context.Context, principal string) ([]KafkaACL, error) {
+	return f.acls, f.aclErr
+}
+
+func (f *fakeKafkaAccess) Quotas(ctx context.Context, principal string) ([]KafkaQuota, error) {
+	return f.quotas, f.quotasErr
+}
+
+func topicACL(name string, pattern string, op string, permission string) KafkaACL {
+	return KafkaACL{Principal: "User:debezium", ResourceType: "TOPIC", ResourceName: name, PatternType: pattern, Operation: op, Permission: permission}
+}
+
+func TestKafkaAccessCheck_GrantedACLsAndNoQuota(t *testing.T) {
+	check := &KafkaAccessCheck{
+		Inspector: &fakeKafkaAccess{acls: []KafkaACL{
+			topicACL("dbserver1", "PREFIXED", "WRITE", "ALLOW"),
+			topicACL("*", "LITERAL", "DESCRIBE", "ALLOW"),
+			topicACL("schema-changes.inventory", "LITERAL", "ALL", "ALLOW"),
+		}},
+		Principal:          "User:debezium",
+		TopicPrefix:        "dbserver1",
+		SchemaHistoryTopic: "schema-changes.inventory",
+	}
+	findings, err := check.Run(context.Background(), checks.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Code != CodeKafkaAccessOK {
+		t.Fatalf("expected access to be OK, got %+v", findings)
+	}
+}
+
+func TestKafkaAccessCheck_MissingAndDeniedACLs(t *testing.T) {
+	check := &KafkaAccessCheck{
+		Inspector: &fakeKafkaAccess{acls: []KafkaACL{
+			// A literal grant on one data topic does not cover the prefix.
+			topicACL("dbserver1.inventory.orders", "LITERAL", "WRITE", "ALLOW"),
+			topicACL("dbserver1", "PREFIXED", "DESCRIBE", "ALLOW"),
+			topicACL("schema-changes.inventory", "LITERAL", "ALL", "ALLOW"),
+			{Principal: "User:*", ResourceType: "TOPIC", ResourceName: "schema-changes", PatternType: "PREFIXED", Operation: "READ", Permission: "DENY"},
+		}},
+		Principal:          "User:debezium",
+		TopicPrefix:        "dbserver1",
+		SchemaHistoryTopic: "schema-changes.inventory",
+	}
+	findings, _ := check.Run(context.Background(), checks.Input{})
+	if len(findings) != 2 {
+		t.Fatalf("expected two missing operations, got %+v", findings)
+	}
+	if findings[0].Meta["operation"] != "WRITE" || findings[0].Meta["prefixed"] != true {
+		t.Fatalf("expected WRITE on the data topic prefix to be missing, got %+v", findings[0])
+	}
+	if findings[1].Meta["operation"] != "READ" || !strings.Contains(findings[1].Message, "is denied") {
+		t.Fatalf("expected READ on schema history to be denied, got %+v", findings[1])
+	}
+}
+
+func TestKafkaAccessCheck_QuotasAndErrors(t *testing.T) {
+	granted := []KafkaACL{topicACL("*", "LITERAL", "ALL", "ALLOW")}
+	check := &KafkaAccessCheck{
+		Inspector:    &fakeKafkaAccess{acls: granted, quotas: []KafkaQuota{{Entity: "user=debezium", ProducerByteRate: 1 << 20}, {Entity: "client-id=connect", ProducerByteRate: 64 << 20}}},
+		Principal:    "User:debezium",
+		TopicPrefix:  "dbserver1",
+		PeakByteRate: 8 << 20,
+	}
+	findings, _ := check.Run(context.Background(), checks.Input{})
+	if len(findings) != 1 || findings[0].Code != CodeKafkaQuotaThrottles || findings[0].Meta["entity"] != "user=debezium" {
+		t.Fatalf("expected only the quota below the peak to warn, got %+v", findings)
+	}
+
+	check.Inspector = &fakeKafkaAccess{aclErr: errors.New("cluster authorization failed"), quotas: []KafkaQuota{{Entity: "user=debezium", RequestPercentage: 50}}}
+	check.PeakByteRate = 0
+	findings, _ = check.Run(context.Background(), checks.Input{})
+	if len(findings) != 2 || findings[0].Code != CodeKafkaAccessUnknown || findings[1].Code != CodeKafkaQuotaThrottles {
+		t.Fatalf("expected unknown ACLs and a request quota warning, got %+v", findings)
+	}
+}
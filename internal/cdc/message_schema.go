@@ -0,0 +1,236 @@
+package cdc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"migratorx/internal/checks"
+)
+
+// MessageField describes one field of a CDC message value schema: its Kafka
+// Connect type, the Debezium logical type Name (e.g.
+// io.debezium.time.MicroTimestamp) and the schema parameters, which carry
+// details like decimal scale.
+type MessageField struct {
+	Type       string            `json:"type"`
+	Name       string            `json:"name,omitempty"`
+	Optional   bool              `json:"optional"`
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// String renders the field the way it appears in findings, e.g.
+// "int64 io.debezium.time.MicroTimestamp" or "bytes
+// org.apache.kafka.connect.data.Decimal(scale=2)".
+func (f MessageField) String() string {
+	s := f.Type
+	if f.Name != "" {
+		s += " " + f.Name
+	}
+	if len(f.Parameters) > 0 {
+		keys := make([]string, 0, len(f.Parameters))
+		for k := range f.Parameters {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		params := make([]string, 0, len(keys))
+		for _, k := range keys {
+			params = append(params, k+"="+f.Parameters[k])
+		}
+		s += "(" + strings.Join(params, ",") + ")"
+	}
+	if f.Optional {
+		s += " optional"
+	}
+	return s
+}
+
+// MessageSchema maps a table's column fields to their description.
+type MessageSchema map[string]MessageField
+
+// MessageSchemaInspector reads the current value schema of a table's CDC
+// messages, e.g. from the latest message on its topic.
+type MessageSchemaInspector interface {
+	ValueSchema(ctx context.Context, table string) (MessageSchema, error)
+}
+
+type connectSchema struct {
+	Type       string            `json:"type"`
+	Name       string            `json:"name"`
+	Optional   bool              `json:"optional"`
+	Field      string            `json:"field"`
+	Parameters map[string]string `json:"parameters"`
+	Fields     []connectSchema   `json:"fields"`
+}
+
+// ParseValueSchema extracts the row schema from a Debezium message written by
+// the JSON converter with schemas enabled. It accepts the whole message
+// ({"schema": ..., "payload": ...}) or just its schema, and either the change
+// event envelope, whose "after" field holds the row, or an unwrapped row.
+func ParseValueSchema(b []byte) (MessageSchema, error) {
+	var message struct {
+		Schema *connectSchema `json:"schema"`
+	}
+	if err := json.Unmarshal(b, &message); err != nil {
+		return nil, err
+	}
+	var schema connectSchema
+	if message.Schema != nil {
+		schema = *message.Schema
+	} else if err := json.Unmarshal(b, &schema); err != nil {
+		return nil, err
+	}
+	if schema.Type != "struct" {
+		return nil, fmt.Errorf("value schema is %q, expected a struct", schema.Type)
+	}
+	row := schema.Fields
+	for _, f := range schema.Fields {
+		if f.Field == "after" && f.Type == "struct" {
+			row = f.Fields
+		}
+	}
+	parsed := MessageSchema{}
+	for _, f := range row {
+		parsed[f.Field] = MessageField{Type: f.Type, Name: f.Name, Optional: f.Optional, Parameters: f.Parameters}
+	}
+	return parsed, nil
+}
+
+// MessageSchemaCheck compares the value schema of each table's CDC messages
+// with the Baseline captured before the upgrade. A field whose type, logical
+// type or parameters changed, that became required, or that disappeared will
+// break consumers deserializing with the old schema and is a WARN. Without a
+// Baseline the check captures one: its INFO finding carries the current
+// schemas in the "schemas" meta for the caller to store.
+type MessageSchemaCheck struct {
+	Inspector MessageSchemaInspector
+	Tables    []string
+	Baseline  map[string]MessageSchema
+}
+
+func (c *MessageSchemaCheck) Name() string   { return "cdc_message_schema" }
+func (c *MessageSchemaCheck) ReadOnly() bool { return true }
+
+func (c *MessageSchemaCheck) Parameters() map[string]interface{} {
+	return map[string]interface{}{"tables": c.Tables, "baseline": c.Baseline != nil}
+}
+
+func (c *MessageSchemaCheck) Run(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+	if c.Inspector == nil {
+		return nil, fmt.Errorf("message schema inspector is required")
+	}
+	if len(c.Tables) == 0 {
+		return nil, fmt.Errorf("at least one table is required")
+	}
+
+	findings := []checks.Finding{}
+	current := map[string]MessageSchema{}
+	for _, table := range c.Tables {
+		schema, err := c.Inspector.ValueSchema(ctx, table)
+		if err != nil {
+			findings = append(findings, checks.Finding{
+				Severity: checks.SeverityWarn,
+				Code:     CodeMessageSchemaUnknown,
+				Message:  fmt.Sprintf("unable to read the CDC message schema of %s: %v", table, err),
+				Meta:     map[string]interface{}{"table": table},
+			})
+			continue
+		}
+		current[table] = schema
+	}
+
+	if c.Baseline == nil {
+		if len(current) > 0 {
+			findings = append(findings, checks.Finding{
+				Severity: checks.SeverityInfo,
+				Code:     CodeMessageSchemaBaseline,
+				Message:  fmt.Sprintf("captured the CDC message schemas of %d tables as the baseline", len(current)),
+				Meta:     map[string]interface{}{"table_count": len(current), "schemas": current},
+			})
+		}
+		return findings, nil
+	}
+
+	unchanged := 0
+	for _, table := range c.Tables {
+		schema, ok := current[table]
+		if !ok {
+			continue
+		}
+		before, ok := c.Baseline[table]
+		if !ok {
+			findings = append(findings, checks.Finding{
+				Severity: checks.SeverityInfo,
+				Message:  fmt.Sprintf("no baseline message schema for %s; nothing to compare", table),
+				Meta:     map[string]interface{}{"table": table},
+			})
+			continue
+		}
+		changes, added := diffMessageSchemas(before, schema)
+		if len(changes) == 0 {
+			unchanged++
+			continue
+		}
+		fields := make([]string, 0, len(changes))
+		for field := range changes {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		details := make([]string, 0, len(fields))
+		for _, field := range fields {
+			details = append(details, fmt.Sprintf("%s: %s", field, changes[field]))
+		}
+		meta := map[string]interface{}{"table": table, "changes": changes}
+		if len(added) > 0 {
+			meta["added"] = added
+		}
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityWarn,
+			Code:     CodeMessageSchemaChanged,
+			Message:  fmt.Sprintf("CDC message schema of %s changed; consumers using the old schema may break: %s", table, strings.Join(details, "; ")),
+			Meta:     meta,
+		})
+	}
+	if unchanged > 0 {
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityInfo,
+			Code:     CodeMessageSchemaUnchanged,
+			Message:  fmt.Sprintf("CDC message schemas of %d tables match the baseline", unchanged),
+			Meta:     map[string]interface{}{"tables": unchanged},
+		})
+	}
+	return findings, nil
+}
+
+// diffMessageSchemas returns the fields that changed or disappeared, as
+// "before -> after", and the fields that were added. An added field is not a
+// breaking change on its own.
+func diffMessageSchemas(before MessageSchema, after MessageSchema) (map[string]string, []string) {
+	changes := map[string]string{}
+	for field, old := range before {
+		now, ok := after[field]
+		switch {
+		case !ok:
+			changes[field] = old.String() + " -> removed"
+		case now.String() != old.String() && !(now.Optional && !old.Optional && sameFieldType(old, now)):
+			changes[field] = old.String() + " -> " + now.String()
+		}
+	}
+	added := []string{}
+	for field := range after {
+		if _, ok := before[field]; !ok {
+			added = append(added, field)
+		}
+	}
+	sort.Strings(added)
+	return changes, added
+}
+
+// sameFieldType reports whether a and b differ at most in optionality; a
+// field becoming optional does not break readers.
+func sameFieldType(a MessageField, b MessageField) bool {
+	a.Optional, b.Optional = false, false
+	return a.String() == b.String()
+}
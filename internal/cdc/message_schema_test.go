@@ -0,0 +1,114 @@
+package cdc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"migratorx/internal/checks"
+)
+
+const ordersMessage = `{
+  "schema": {"type": "struct", "name": "dbserver1.shop.orders.Envelope", "fields": [
+    {"type": "struct", "optional": true, "field": "before", "fields": []},
+    {"type": "struct", "optional": true, "field": "after", "fields": [
+      {"type": "int32", "optional": false, "field": "id"},
+      {"type": "int64", "optional": false, "name": "io.debezium.time.Timestamp", "field": "created_at"},
+      {"type": "bytes", "optional": true, "name": "org.apache.kafka.connect.data.Decimal", "parameters": {"scale": "2"}, "field": "total"}
+    ]},
+    {"type": "string", "optional": false, "field": "op"}
+  ]},
+  "payload": {"op": "c"}
+}`
+
+type fakeMessageSchemaInspector map[string]MessageSchema
+
+func (f fakeMessageSchemaInspector) ValueSchema(ctx context.Context, table string) (MessageSchema, error) {
+	schema, ok := f[table]
+	if !ok {
+		return nil, errors.New("no message on topic")
+	}
+	return schema, nil
+}
+
+func TestParseValueSchema_ReadsRowFromEnvelope(t *testing.T) {
+	schema, err := ParseValueSchema([]byte(ordersMessage))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(schema) != 3 {
+		t.Fatalf("expected the three row fields, got %v", schema)
+	}
+	if got := schema["total"].String(); got != "bytes org.apache.kafka.connect.data.Decimal(scale=2) optional" {
+		t.Fatalf("unexpected total field: %s", got)
+	}
+	if _, err := ParseValueSchema([]byte(`{"type": "string"}`)); err == nil {
+		t.Fatalf("expected a non-struct schema to be rejected")
+	}
+}
+
+func TestMessageSchemaCheck_CapturesBaseline(t *testing.T) {
+	orders, _ := ParseValueSchema([]byte(ordersMessage))
+	check := &MessageSchemaCheck{Inspector: fakeMessageSchemaInspector{"shop.orders": orders}, Tables: []string{"shop.orders"}}
+	findings, err := check.Run(context.Background(), checks.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Code != CodeMessageSchemaBaseline {
+		t.Fatalf("expected baseline finding, got %+v", findings)
+	}
+	if schemas, ok := findings[0].Meta["schemas"].(map[string]MessageSchema); !ok || len(schemas["shop.orders"]) != 3 {
+		t.Fatalf("expected schemas in meta, got %+v", findings[0].Meta)
+	}
+}
+
+func TestMessageSchemaCheck_WarnsOnTemporalPrecisionChange(t *testing.T) {
+	before, _ := ParseValueSchema([]byte(ordersMessage))
+	after, _ := ParseValueSchema([]byte(strings.Replace(ordersMessage, "io.debezium.time.Timestamp", "io.debezium.time.MicroTimestamp", 1)))
+	after["note"] = MessageField{Type: "string", Optional: true}
+	check := &MessageSchemaCheck{
+		Inspector: fakeMessageSchemaInspector{"shop.orders": after, "shop.items": before},
+		Tables:    []string{"shop.orders", "shop.items", "shop.missing"},
+		Baseline:  map[string]MessageSchema{"shop.orders": before, "shop.items": before},
+	}
+	findings, err := check.Run(context.Background(), checks.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	codes := map[string]checks.Finding{}
+	for _, f := range findings {
+		codes[f.Code] = f
+	}
+	changed, ok := codes[CodeMessageSchemaChanged]
+	if !ok || !strings.Contains(changed.Message, "created_at: int64 io.debezium.time.Timestamp -> int64 io.debezium.time.MicroTimestamp") {
+		t.Fatalf("expected temporal precision change, got %+v", findings)
+	}
+	if added, _ := changed.Meta["added"].([]string); len(added) != 1 || added[0] != "note" {
+		t.Fatalf("expected added field in meta, got %+v", changed.Meta)
+	}
+	if _, ok := codes[CodeMessageSchemaUnchanged]; !ok {
+		t.Fatalf("expected shop.items to be unchanged, got %+v", findings)
+	}
+	if codes[CodeMessageSchemaUnknown].Severity != checks.SeverityWarn {
+		t.Fatalf("expected unreadable table to warn, got %+v", findings)
+	}
+}
+
+func TestMessageSchemaCheck_FieldBecomingOptionalIsCompatible(t *testing.T) {
+	before, _ := ParseValueSchema([]byte(ordersMessage))
+	after, _ := ParseValueSchema([]byte(ordersMessage))
+	id := after["id"]
+	id.Optional = true
+	after["id"] = id
+	delete(after, "total")
+	check := &MessageSchemaCheck{Inspector: fakeMessageSchemaInspector{"shop.orders": after}, Tables: []string{"shop.orders"}, Baseline: map[string]MessageSchema{"shop.orders": before}}
+	findings, err := check.Run(context.Background(), checks.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	changes, _ := findings[0].Meta["changes"].(map[string]string)
+	if len(changes) != 1 || !strings.HasSuffix(changes["total"], "-> removed") {
+		t.Fatalf("expected only the removed field to be reported, got %+v", findings)
+	}
+}
@@ -0,0 +1,57 @@
+package cdc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"migratorx/internal/checks"
+)
+
+type fakeOffsetInspector struct {
+	connector  BinlogPosition
+	primary    BinlogPosition
+	primaryErr error
+}
+
+func (f *fakeOffsetInspector) ConnectorOffsets(ctx context.Context, connector string) (BinlogPosition, error) {
+	return f.connector, nil
+}
+
+func (f *fakeOffsetInspector) PrimaryCoordinates(ctx context.Context, host string) (BinlogPosition, error) {
+	if f.primaryErr != nil {
+		return BinlogPosition{}, f.primaryErr
+	}
+	return f.primary, nil
+}
+
+func TestOffsetSnapshotCheck_RecordsCoordinates(t *testing.T) {
+	inspector := &fakeOffsetInspector{
+		connector: BinlogPosition{File: "mysql-bin.000042", Position: 1200, GTIDSet: "uuid:1-100"},
+		primary:   BinlogPosition{File: "mysql-bin.000042", Position: 1500, GTIDSet: "uuid:1-102"},
+	}
+	check := &OffsetSnapshotCheck{Inspector: inspector, Connector: "mysql-prod", PrimaryHost: "mysql-primary"}
+	findings, err := check.Run(context.Background(), checks.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Code != CodeOffsetSnapshot || findings[0].Severity != checks.SeverityInfo {
+		t.Fatalf("expected single INFO snapshot, got %+v", findings)
+	}
+	meta := findings[0].Meta
+	if meta["connector_position"] != int64(1200) || meta["primary_gtid_set"] != "uuid:1-102" || meta["connector_caught_up"] != false {
+		t.Fatalf("unexpected snapshot meta: %+v", meta)
+	}
+}
+
+func TestOffsetSnapshotCheck_UnavailableWarns(t *testing.T) {
+	inspector := &fakeOffsetInspector{primaryErr: errors.New("access denied")}
+	check := &OffsetSnapshotCheck{Inspector: inspector, Connector: "mysql-prod", PrimaryHost: "mysql-primary"}
+	findings, err := check.Run(context.Background(), checks.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Code != CodeOffsetsUnavailable || findings[0].Severity != checks.SeverityWarn {
+		t.Fatalf("expected WARN when offsets are unavailable, got %+v", findings)
+	}
+}
@@ -0,0 +1,99 @@
+package cdc
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Headers that carry the run context on Kafka Connect REST calls.
+const (
+	HeaderRunID    = "X-Migratorx-Run-Id"
+	HeaderOperator = "X-Migratorx-Operator"
+)
+
+// RunContext identifies the migration run and operator behind outbound
+// calls, so Kafka Connect and broker logs can be matched to a run.
+type RunContext struct {
+	RunID    string
+	Operator string
+}
+
+// ClientID identifies the run to external systems. It is the User-Agent of
+// Connect REST calls and is meant as the client.id of Kafka admin clients,
+// which only allow ASCII letters, digits, '.', '_' and '-'.
+func (r RunContext) ClientID() string {
+	return "migratorx." + clientIDPart(r.RunID) + "." + clientIDPart(r.Operator)
+}
+
+func clientIDPart(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return strings.Map(func(c rune) rune {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '_', c == '-':
+			return c
+		}
+		return '_'
+	}, s)
+}
+
+// OutboundCall is one audited call to an external system. URL has any
+// credentials redacted; Status is zero when the call failed.
+type OutboundCall struct {
+	At       time.Time
+	System   string
+	Method   string
+	URL      string
+	ClientID string
+	Status   int
+	Error    string
+	Duration time.Duration
+}
+
+// AuditTransport tags each request with the run context and reports it to
+// Audit once the response headers arrive.
+type AuditTransport struct {
+	Base   http.RoundTripper
+	Run    RunContext
+	System string
+	Audit  func(OutboundCall)
+}
+
+func (t *AuditTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	tagged := req.Clone(req.Context())
+	tagged.Header.Set("User-Agent", t.Run.ClientID())
+	tagged.Header.Set(HeaderRunID, t.Run.RunID)
+	tagged.Header.Set(HeaderOperator, t.Run.Operator)
+
+	start := time.Now()
+	resp, err := base.RoundTrip(tagged)
+	if t.Audit != nil {
+		call := OutboundCall{At: start.UTC(), System: t.System, Method: req.Method, URL: redactURL(req.URL), ClientID: t.Run.ClientID(), Duration: time.Since(start)}
+		if err != nil {
+			call.Error = err.Error()
+		} else {
+			call.Status = resp.StatusCode
+		}
+		t.Audit(call)
+	}
+	return resp, err
+}
+
+// HTTPClient returns a client whose calls to system are tagged and audited.
+func (r RunContext) HTTPClient(system string, audit func(OutboundCall)) *http.Client {
+	return &http.Client{Transport: &AuditTransport{Run: r, System: system, Audit: audit}}
+}
+
+func redactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	return u.Redacted()
+}
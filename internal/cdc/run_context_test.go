@@ -0,0 +1,45 @@
+package cdc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRunContext_ClientIDIsKafkaSafe(t *testing.T) {
+	got := RunContext{RunID: "2026-10-18/cutover", Operator: "alice@example.com"}.ClientID()
+	if got != "migratorx.2026-10-18_cutover.alice_example_com" {
+		t.Fatalf("unexpected client id: %s", got)
+	}
+	if got := (RunContext{}).ClientID(); got != "migratorx.unknown.unknown" {
+		t.Fatalf("unexpected empty client id: %s", got)
+	}
+}
+
+func TestAuditTransport_TagsAndAuditsConnectCalls(t *testing.T) {
+	var userAgent, runID, operator string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent, runID, operator = r.UserAgent(), r.Header.Get(HeaderRunID), r.Header.Get(HeaderOperator)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	calls := []OutboundCall{}
+	run := RunContext{RunID: "run-7", Operator: "alice"}
+	client := &ConnectClient{URL: strings.Replace(server.URL, "http://", "http://admin:secret@", 1), Client: run.HTTPClient("kafka_connect", func(c OutboundCall) { calls = append(calls, c) })}
+	if err := client.RestartTask(context.Background(), "mysql-prod", 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if userAgent != "migratorx.run-7.alice" || runID != "run-7" || operator != "alice" {
+		t.Fatalf("unexpected tags: %q %q %q", userAgent, runID, operator)
+	}
+	if len(calls) != 1 {
+		t.Fatalf("expected one audited call, got %+v", calls)
+	}
+	call := calls[0]
+	if call.System != "kafka_connect" || call.Method != http.MethodPost || call.Status != http.StatusNoContent || strings.Contains(call.URL, "secret") {
+		t.Fatalf("unexpected audited call: %+v", call)
+	}
+}
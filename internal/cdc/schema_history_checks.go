@@ -9,43 +9,73 @@ import (
 )
 
 // KafkaInspector provides read-only access to Kafka topics and coverage metadata.
+// FileSchemaHistoryInspector and RedisSchemaHistoryInspector implement it for
+// the other Debezium schema history stores, with the file path or Redis key in
+// place of the topic.
 type KafkaInspector interface {
 	TopicExists(ctx context.Context, topic string) (bool, error)
 	TopicReadable(ctx context.Context, topic string) (bool, error)
 	SchemaHistoryTables(ctx context.Context, topic string) ([]string, error)
 }
 
-// SchemaHistoryCheck validates Kafka schema history topic health and coverage.
+// SchemaHistoryCheck validates schema history health and coverage. Storage
+// names the store Topic lives in (kafka, file or redis) and defaults to kafka;
+// NewSchemaHistoryCheck fills both in from the connector config.
 type SchemaHistoryCheck struct {
 	Inspector     KafkaInspector
 	Topic         string
+	Storage       string
 	ExpectedTables []string
 }
 
 func (c *SchemaHistoryCheck) Name() string   { return "cdc_schema_history" }
 func (c *SchemaHistoryCheck) ReadOnly() bool { return true }
 
+func (c *SchemaHistoryCheck) Parameters() map[string]interface{} {
+	return map[string]interface{}{"topic": c.Topic, "storage": c.storage(), "expected_tables": c.ExpectedTables}
+}
+
+func (c *SchemaHistoryCheck) storage() string {
+	if c.Storage == "" {
+		return SchemaHistoryKafka
+	}
+	return c.Storage
+}
+
+// subject describes where the schema history lives, for messages.
+func (c *SchemaHistoryCheck) subject() string {
+	switch c.storage() {
+	case SchemaHistoryFile:
+		return fmt.Sprintf("schema history file %q", c.Topic)
+	case SchemaHistoryRedis:
+		return fmt.Sprintf("schema history Redis key %q", c.Topic)
+	}
+	return fmt.Sprintf("schema history topic %q", c.Topic)
+}
+
 func (c *SchemaHistoryCheck) Run(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
 	if c.Inspector == nil {
-		return nil, fmt.Errorf("kafka inspector is required")
+		return nil, fmt.Errorf("schema history inspector is required")
 	}
 	if strings.TrimSpace(c.Topic) == "" {
-		return nil, fmt.Errorf("schema history topic is required")
+		return nil, fmt.Errorf("schema history location is required")
 	}
 
 	exists, err := c.Inspector.TopicExists(ctx, c.Topic)
 	if err != nil {
 		return []checks.Finding{{
 			Severity: checks.SeverityBlock,
-			Message:  fmt.Sprintf("failed to check schema history topic %q: %v", c.Topic, err),
-			Meta:     map[string]interface{}{"topic": c.Topic},
+			Code:     CodeSchemaHistoryUnavailable,
+			Message:  fmt.Sprintf("failed to check %s: %v", c.subject(), err),
+			Meta:     map[string]interface{}{"topic": c.Topic, "storage": c.storage()},
 		}}, nil
 	}
 	if !exists {
 		return []checks.Finding{{
 			Severity: checks.SeverityBlock,
-			Message:  fmt.Sprintf("schema history topic %q is missing", c.Topic),
-			Meta:     map[string]interface{}{"topic": c.Topic},
+			Code:     CodeSchemaHistoryMissing,
+			Message:  fmt.Sprintf("%s is missing", c.subject()),
+			Meta:     map[string]interface{}{"topic": c.Topic, "storage": c.storage()},
 		}}, nil
 	}
 
@@ -53,15 +83,17 @@ func (c *SchemaHistoryCheck) Run(ctx context.Context, input checks.Input) ([]che
 	if err != nil {
 		return []checks.Finding{{
 			Severity: checks.SeverityBlock,
-			Message:  fmt.Sprintf("failed to read schema history topic %q: %v", c.Topic, err),
-			Meta:     map[string]interface{}{"topic": c.Topic},
+			Code:     CodeSchemaHistoryUnavailable,
+			Message:  fmt.Sprintf("failed to read %s: %v", c.subject(), err),
+			Meta:     map[string]interface{}{"topic": c.Topic, "storage": c.storage()},
 		}}, nil
 	}
 	if !readable {
 		return []checks.Finding{{
 			Severity: checks.SeverityBlock,
-			Message:  fmt.Sprintf("schema history topic %q is not readable", c.Topic),
-			Meta:     map[string]interface{}{"topic": c.Topic},
+			Code:     CodeSchemaHistoryUnreadable,
+			Message:  fmt.Sprintf("%s is not readable", c.subject()),
+			Meta:     map[string]interface{}{"topic": c.Topic, "storage": c.storage()},
 		}}, nil
 	}
 
@@ -69,8 +101,9 @@ func (c *SchemaHistoryCheck) Run(ctx context.Context, input checks.Input) ([]che
 	if err != nil {
 		return []checks.Finding{{
 			Severity: checks.SeverityBlock,
+			Code:     CodeSchemaHistoryUnavailable,
 			Message:  fmt.Sprintf("failed to read schema history coverage for %q: %v", c.Topic, err),
-			Meta:     map[string]interface{}{"topic": c.Topic},
+			Meta:     map[string]interface{}{"topic": c.Topic, "storage": c.storage()},
 		}}, nil
 	}
 
@@ -78,15 +111,17 @@ func (c *SchemaHistoryCheck) Run(ctx context.Context, input checks.Input) ([]che
 	if len(missing) > 0 {
 		return []checks.Finding{{
 			Severity: checks.SeverityBlock,
+			Code:     CodeSchemaHistoryCoverageGap,
 			Message:  fmt.Sprintf("schema history missing tables: %s", strings.Join(missing, ", ")),
-			Meta:     map[string]interface{}{"topic": c.Topic, "missing_tables": missing},
+			Meta:     map[string]interface{}{"topic": c.Topic, "storage": c.storage(), "missing_tables": missing},
 		}}, nil
 	}
 
 	return []checks.Finding{{
 		Severity: checks.SeverityInfo,
-		Message:  fmt.Sprintf("schema history topic %q is healthy", c.Topic),
-		Meta:     map[string]interface{}{"topic": c.Topic},
+		Code:     CodeSchemaHistoryHealthy,
+		Message:  fmt.Sprintf("%s is healthy", c.subject()),
+		Meta:     map[string]interface{}{"topic": c.Topic, "storage": c.storage()},
 	}}, nil
 }
 
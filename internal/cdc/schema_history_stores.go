@@ -0,0 +1,192 @@
+package cdc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// Schema history storage kinds.
+const (
+	SchemaHistoryKafka = "kafka"
+	SchemaHistoryFile  = "file"
+	SchemaHistoryRedis = "redis"
+)
+
+// DefaultRedisSchemaHistoryKey is the stream Debezium's Redis schema history
+// writes to when schema.history.internal.redis.key is not set.
+const DefaultRedisSchemaHistoryKey = "metadata:debezium:schema_history"
+
+// schemaHistoryPrefixes are the config prefixes for the schema history store:
+// schema.history.internal since Debezium 2.0, database.history before.
+var schemaHistoryPrefixes = []string{"schema.history.internal", "database.history"}
+
+// SchemaHistoryStorage returns where a Debezium connector keeps its schema
+// history, from the connector config: the storage kind and the topic, file
+// path or Redis key. A connector without a history class uses Kafka, as
+// Debezium does.
+func SchemaHistoryStorage(config map[string]string) (string, string, error) {
+	for _, prefix := range schemaHistoryPrefixes {
+		class, ok := config[prefix]
+		if !ok {
+			if _, hasTopic := config[prefix+".kafka.topic"]; !hasTopic {
+				continue
+			}
+		}
+		name := class[strings.LastIndex(class, ".")+1:]
+		switch {
+		case name == "" || strings.HasPrefix(name, "Kafka"):
+			return SchemaHistoryKafka, config[prefix+".kafka.topic"], nil
+		case strings.HasPrefix(name, "File"):
+			return SchemaHistoryFile, config[prefix+".file.filename"], nil
+		case strings.HasPrefix(name, "Redis"):
+			key := config[prefix+".redis.key"]
+			if key == "" {
+				key = DefaultRedisSchemaHistoryKey
+			}
+			return SchemaHistoryRedis, key, nil
+		}
+		return "", "", fmt.Errorf("unsupported schema history class %q", class)
+	}
+	return SchemaHistoryKafka, "", nil
+}
+
+// SchemaHistoryInspectors holds an inspector for each storage kind a
+// connector may use. Kinds left nil are unsupported.
+type SchemaHistoryInspectors struct {
+	Kafka KafkaInspector
+	File  KafkaInspector
+	Redis KafkaInspector
+}
+
+// NewSchemaHistoryCheck builds a SchemaHistoryCheck for the store named in
+// the connector config, with the inspector for that kind of store.
+func NewSchemaHistoryCheck(config map[string]string, inspectors SchemaHistoryInspectors, expectedTables []string) (*SchemaHistoryCheck, error) {
+	storage, location, err := SchemaHistoryStorage(config)
+	if err != nil {
+		return nil, err
+	}
+	inspector := map[string]KafkaInspector{
+		SchemaHistoryKafka: inspectors.Kafka,
+		SchemaHistoryFile:  inspectors.File,
+		SchemaHistoryRedis: inspectors.Redis,
+	}[storage]
+	if inspector == nil {
+		return nil, fmt.Errorf("no inspector for %s schema history", storage)
+	}
+	return &SchemaHistoryCheck{Inspector: inspector, Topic: location, Storage: storage, ExpectedTables: expectedTables}, nil
+}
+
+// FileSchemaHistoryInspector reads a FileSchemaHistory file, which holds one
+// JSON history record per line. The file must be reachable from the operator
+// host, e.g. on a volume shared with the Connect worker.
+type FileSchemaHistoryInspector struct{}
+
+func (FileSchemaHistoryInspector) TopicExists(ctx context.Context, path string) (bool, error) {
+	_, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (FileSchemaHistoryInspector) TopicReadable(ctx context.Context, path string) (bool, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrPermission) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	f.Close()
+	return true, nil
+}
+
+func (FileSchemaHistoryInspector) SchemaHistoryTables(ctx context.Context, path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	records := []string{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		records = append(records, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return historyRecordTables(records)
+}
+
+// RedisStreamClient is the subset of a Redis client the Redis schema history
+// inspector needs. XRange returns the fields of every entry in the stream.
+type RedisStreamClient interface {
+	Exists(ctx context.Context, key string) (bool, error)
+	XRange(ctx context.Context, key string) ([]map[string]string, error)
+}
+
+// RedisSchemaHistoryInspector reads a RedisSchemaHistory stream, whose entries
+// carry one JSON history record each in the "schema" field.
+type RedisSchemaHistoryInspector struct {
+	Client RedisStreamClient
+}
+
+func (r *RedisSchemaHistoryInspector) TopicExists(ctx context.Context, key string) (bool, error) {
+	return r.Client.Exists(ctx, key)
+}
+
+func (r *RedisSchemaHistoryInspector) TopicReadable(ctx context.Context, key string) (bool, error) {
+	_, err := r.Client.XRange(ctx, key)
+	if err != nil && strings.HasPrefix(err.Error(), "NOPERM") {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *RedisSchemaHistoryInspector) SchemaHistoryTables(ctx context.Context, key string) ([]string, error) {
+	entries, err := r.Client.XRange(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		records = append(records, entry["schema"])
+	}
+	return historyRecordTables(records)
+}
+
+// historyRecordTables returns the tables the history records describe, as
+// db.table, in first-seen order. Table ids in a record look like
+// "db"."table"; records without table changes (pre-1.2 history) are skipped.
+func historyRecordTables(records []string) ([]string, error) {
+	tables := []string{}
+	seen := map[string]bool{}
+	for i, record := range records {
+		if strings.TrimSpace(record) == "" {
+			continue
+		}
+		var parsed struct {
+			TableChanges []struct {
+				ID string `json:"id"`
+			} `json:"tableChanges"`
+		}
+		if err := json.Unmarshal([]byte(record), &parsed); err != nil {
+			return nil, fmt.Errorf("schema history record %d: %w", i+1, err)
+		}
+		for _, change := range parsed.TableChanges {
+			table := strings.ReplaceAll(change.ID, `"`, "")
+			if table != "" && !seen[table] {
+				seen[table] = true
+				tables = append(tables, table)
+			}
+		}
+	}
+	return tables, nil
+}
@@ -0,0 +1,140 @@
+package cdc
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"migratorx/internal/checks"
+)
+
+const (
+	historyRecordOrders    = `{"source":{"server":"mysql-prod"},"databaseName":"shop","ddl":"CREATE TABLE orders (id INT)","tableChanges":[{"type":"CREATE","id":"\"shop\".\"orders\""}]}`
+	historyRecordCustomers = `{"source":{"server":"mysql-prod"},"databaseName":"shop","ddl":"CREATE TABLE customers (id INT)","tableChanges":[{"type":"CREATE","id":"\"shop\".\"customers\""}]}`
+)
+
+type fakeRedisStream struct {
+	entries []map[string]string
+	err     error
+}
+
+func (f *fakeRedisStream) Exists(ctx context.Context, key string) (bool, error) {
+	return f.entries != nil, nil
+}
+
+func (f *fakeRedisStream) XRange(ctx context.Context, key string) ([]map[string]string, error) {
+	return f.entries, f.err
+}
+
+func TestSchemaHistoryStorage_SelectsStoreFromConnectorConfig(t *testing.T) {
+	cases := []struct {
+		name     string
+		config   map[string]string
+		storage  string
+		location string
+	}{
+		{"default", map[string]string{}, SchemaHistoryKafka, ""},
+		{"kafka", map[string]string{"schema.history.internal.kafka.topic": "schema-changes.shop"}, SchemaHistoryKafka, "schema-changes.shop"},
+		{"file", map[string]string{
+			"schema.history.internal":               "io.debezium.storage.file.history.FileSchemaHistory",
+			"schema.history.internal.file.filename": "/data/history.dat",
+		}, SchemaHistoryFile, "/data/history.dat"},
+		{"legacy file", map[string]string{
+			"database.history":               "io.debezium.relational.history.FileDatabaseHistory",
+			"database.history.file.filename": "/data/dbhistory.dat",
+		}, SchemaHistoryFile, "/data/dbhistory.dat"},
+		{"redis default key", map[string]string{"schema.history.internal": "io.debezium.storage.redis.history.RedisSchemaHistory"}, SchemaHistoryRedis, DefaultRedisSchemaHistoryKey},
+		{"redis key", map[string]string{
+			"schema.history.internal":           "io.debezium.storage.redis.history.RedisSchemaHistory",
+			"schema.history.internal.redis.key": "debezium:shop",
+		}, SchemaHistoryRedis, "debezium:shop"},
+	}
+	for _, tc := range cases {
+		storage, location, err := SchemaHistoryStorage(tc.config)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		if storage != tc.storage || location != tc.location {
+			t.Fatalf("%s: expected %s %q, got %s %q", tc.name, tc.storage, tc.location, storage, location)
+		}
+	}
+
+	if _, _, err := SchemaHistoryStorage(map[string]string{"schema.history.internal": "io.debezium.relational.history.MemorySchemaHistory"}); err == nil {
+		t.Fatalf("expected an error for an unsupported history class")
+	}
+}
+
+func TestNewSchemaHistoryCheck_FileStoreCoverageGap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.dat")
+	if err := os.WriteFile(path, []byte(historyRecordOrders+"\n"), 0o644); err != nil {
+		t.Fatalf("write history: %v", err)
+	}
+	config := map[string]string{
+		"schema.history.internal":               "io.debezium.storage.file.history.FileSchemaHistory",
+		"schema.history.internal.file.filename": path,
+	}
+	check, err := NewSchemaHistoryCheck(config, SchemaHistoryInspectors{File: FileSchemaHistoryInspector{}}, []string{"shop.orders", "shop.customers"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	findings, err := check.Run(context.Background(), checks.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if findings[0].Code != CodeSchemaHistoryCoverageGap || !strings.Contains(findings[0].Message, "shop.customers") {
+		t.Fatalf("expected coverage gap for shop.customers, got %+v", findings)
+	}
+
+	if _, err := NewSchemaHistoryCheck(config, SchemaHistoryInspectors{}, nil); err == nil {
+		t.Fatalf("expected an error without a file inspector")
+	}
+}
+
+func TestFileSchemaHistoryInspector_MissingFileBlocks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.dat")
+	check := &SchemaHistoryCheck{Inspector: FileSchemaHistoryInspector{}, Topic: path, Storage: SchemaHistoryFile}
+	findings, err := check.Run(context.Background(), checks.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if findings[0].Code != CodeSchemaHistoryMissing || !strings.Contains(findings[0].Message, "schema history file") {
+		t.Fatalf("expected missing schema history file, got %+v", findings)
+	}
+}
+
+func TestRedisSchemaHistoryInspector_ReadsStream(t *testing.T) {
+	client := &fakeRedisStream{entries: []map[string]string{{"schema": historyRecordOrders}, {"schema": historyRecordCustomers}, {"schema": historyRecordOrders}}}
+	inspector := &RedisSchemaHistoryInspector{Client: client}
+	tables, err := inspector.SchemaHistoryTables(context.Background(), DefaultRedisSchemaHistoryKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(tables, []string{"shop.orders", "shop.customers"}) {
+		t.Fatalf("unexpected tables: %v", tables)
+	}
+
+	check := &SchemaHistoryCheck{Inspector: inspector, Topic: DefaultRedisSchemaHistoryKey, Storage: SchemaHistoryRedis, ExpectedTables: []string{"shop.orders"}}
+	findings, err := check.Run(context.Background(), checks.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if findings[0].Code != CodeSchemaHistoryHealthy || findings[0].Meta["storage"] != SchemaHistoryRedis {
+		t.Fatalf("expected healthy Redis schema history, got %+v", findings)
+	}
+}
+
+func TestRedisSchemaHistoryInspector_NoPermissionIsUnreadable(t *testing.T) {
+	client := &fakeRedisStream{entries: []map[string]string{}, err: errors.New("NOPERM this user has no permissions to run the 'xrange' command")}
+	check := &SchemaHistoryCheck{Inspector: &RedisSchemaHistoryInspector{Client: client}, Topic: DefaultRedisSchemaHistoryKey, Storage: SchemaHistoryRedis}
+	findings, err := check.Run(context.Background(), checks.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if findings[0].Code != CodeSchemaHistoryUnreadable {
+		t.Fatalf("expected unreadable, got %+v", findings)
+	}
+}
@@ -0,0 +1,173 @@
+package cdc
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"migratorx/internal/checks"
+)
+
+// DefaultMaxThroughputDrop is the share of the baseline event rate the
+// connector may lose before ThroughputCheck warns.
+const DefaultMaxThroughputDrop = 0.25
+
+// ThroughputSample is one reading of the connector's streaming metrics (the
+// debezium.mysql:type=connector-metrics,context=streaming MBean, read over
+// JMX or Jolokia): TotalNumberOfEventsSeen and the change event queue's
+// QueueTotalCapacity and QueueRemainingCapacity.
+type ThroughputSample struct {
+	Time                   time.Time `json:"time"`
+	TotalEventsSeen        int64     `json:"total_events_seen"`
+	QueueTotalCapacity     int       `json:"queue_total_capacity"`
+	QueueRemainingCapacity int       `json:"queue_remaining_capacity"`
+}
+
+// ThroughputInspector reads a series of streaming metric samples for a
+// connector, taken some seconds apart.
+type ThroughputInspector interface {
+	ThroughputSamples(ctx context.Context, connector string) ([]ThroughputSample, error)
+}
+
+// Throughput summarizes a series of samples: the event rate between the
+// first and the last, and the mean and peak share of the queue in use.
+type Throughput struct {
+	EventsPerSecond      float64 `json:"events_per_second"`
+	QueueUtilization     float64 `json:"queue_utilization"`
+	PeakQueueUtilization float64 `json:"peak_queue_utilization"`
+	Samples              int     `json:"samples"`
+}
+
+// MeasureThroughput computes the Throughput of samples. It needs at least two
+// samples at different times, and fails if the event counter went backwards,
+// which means the connector restarted between them.
+func MeasureThroughput(samples []ThroughputSample) (Throughput, error) {
+	if len(samples) < 2 {
+		return Throughput{}, fmt.Errorf("need at least two samples, got %d", len(samples))
+	}
+	sorted := append([]ThroughputSample(nil), samples...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+	first, last := sorted[0], sorted[len(sorted)-1]
+	elapsed := last.Time.Sub(first.Time).Seconds()
+	if elapsed <= 0 {
+		return Throughput{}, fmt.Errorf("samples must be taken at different times")
+	}
+	if last.TotalEventsSeen < first.TotalEventsSeen {
+		return Throughput{}, fmt.Errorf("events counter went backwards (%d -> %d); the connector restarted between samples", first.TotalEventsSeen, last.TotalEventsSeen)
+	}
+	t := Throughput{EventsPerSecond: float64(last.TotalEventsSeen-first.TotalEventsSeen) / elapsed, Samples: len(sorted)}
+	queued := 0
+	for _, s := range sorted {
+		if s.QueueTotalCapacity <= 0 {
+			continue
+		}
+		used := float64(s.QueueTotalCapacity-s.QueueRemainingCapacity) / float64(s.QueueTotalCapacity)
+		t.QueueUtilization += used
+		if used > t.PeakQueueUtilization {
+			t.PeakQueueUtilization = used
+		}
+		queued++
+	}
+	if queued > 0 {
+		t.QueueUtilization /= float64(queued)
+	}
+	return t, nil
+}
+
+// ThroughputCheck compares the connector's throughput with the Baseline
+// captured before the replica upgrade. The event rate follows the write
+// load, so a lower rate alone is not a degradation: it warns when the rate
+// dropped by more than MaxDrop (default DefaultMaxThroughputDrop) while the
+// queue is at least as full as before, or when mean queue utilization rose by
+// more than MaxDrop; either means the connector no longer keeps up, e.g.
+// because of the new binlog format or server settings. Without a Baseline the
+// check captures one: its INFO finding carries it in the "throughput" meta for
+// the caller to store.
+type ThroughputCheck struct {
+	Inspector ThroughputInspector
+	Connector string
+	Baseline  *Throughput
+	MaxDrop   float64
+}
+
+func (c *ThroughputCheck) Name() string   { return "cdc_throughput" }
+func (c *ThroughputCheck) ReadOnly() bool { return true }
+
+func (c *ThroughputCheck) Parameters() map[string]interface{} {
+	return map[string]interface{}{"connector": c.Connector, "max_drop": c.maxDrop(), "baseline": c.Baseline != nil}
+}
+
+func (c *ThroughputCheck) maxDrop() float64 {
+	if c.MaxDrop > 0 {
+		return c.MaxDrop
+	}
+	return DefaultMaxThroughputDrop
+}
+
+func (c *ThroughputCheck) Run(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+	if c.Inspector == nil {
+		return nil, fmt.Errorf("throughput inspector is required")
+	}
+	if c.Connector == "" {
+		return nil, fmt.Errorf("connector name is required")
+	}
+	samples, err := c.Inspector.ThroughputSamples(ctx, c.Connector)
+	var now Throughput
+	if err == nil {
+		now, err = MeasureThroughput(samples)
+	}
+	if err != nil {
+		return []checks.Finding{{
+			Severity: checks.SeverityWarn,
+			Code:     CodeThroughputUnknown,
+			Message:  fmt.Sprintf("unable to measure throughput of connector %s: %v", c.Connector, err),
+			Meta:     map[string]interface{}{"connector": c.Connector},
+		}}, nil
+	}
+
+	if c.Baseline == nil {
+		return []checks.Finding{{
+			Severity: checks.SeverityInfo,
+			Code:     CodeThroughputBaseline,
+			Message:  fmt.Sprintf("captured connector %s throughput as the baseline: %.1f events/s, %.0f%% queue utilization", c.Connector, now.EventsPerSecond, now.QueueUtilization*100),
+			Meta:     map[string]interface{}{"connector": c.Connector, "throughput": now},
+		}}, nil
+	}
+
+	base := *c.Baseline
+	meta := map[string]interface{}{"connector": c.Connector, "baseline": base, "current": now, "max_drop": c.maxDrop()}
+	drop := 0.0
+	if base.EventsPerSecond > 0 {
+		drop = 1 - now.EventsPerSecond/base.EventsPerSecond
+	}
+	meta["drop"] = drop
+	queueRise := now.QueueUtilization - base.QueueUtilization
+	summary := fmt.Sprintf("%.1f -> %.1f events/s, queue utilization %.0f%% -> %.0f%%", base.EventsPerSecond, now.EventsPerSecond, base.QueueUtilization*100, now.QueueUtilization*100)
+
+	var reason string
+	switch {
+	case queueRise > c.maxDrop():
+		reason = "its change event queue is backing up"
+	case drop > c.maxDrop() && queueRise >= 0:
+		reason = fmt.Sprintf("its event rate dropped %.0f%% with the queue at least as full", drop*100)
+	}
+	if reason != "" {
+		return []checks.Finding{{
+			Severity: checks.SeverityWarn,
+			Code:     CodeThroughputDegraded,
+			Message:  fmt.Sprintf("connector %s is slower than its baseline; %s (%s)", c.Connector, reason, summary),
+			Meta:     meta,
+		}}, nil
+	}
+	message := fmt.Sprintf("connector %s keeps up with its baseline (%s)", c.Connector, summary)
+	if drop > c.maxDrop() {
+		message = fmt.Sprintf("connector %s sees fewer events than its baseline but its queue is emptier, so the write load is lower (%s)", c.Connector, summary)
+	}
+	return []checks.Finding{{
+		Severity: checks.SeverityInfo,
+		Code:     CodeThroughputOK,
+		Message:  message,
+		Meta:     meta,
+	}}, nil
+}
@@ -0,0 +1,96 @@
+package cdc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"migratorx/internal/checks"
+)
+
+type fakeThroughputInspector struct {
+	samples []ThroughputSample
+	err     error
+}
+
+func (f fakeThroughputInspector) ThroughputSamples(ctx context.Context, connector string) ([]ThroughputSample, error) {
+	return f.samples, f.err
+}
+
+// samplesAt returns samples ten seconds apart seeing rate events per second
+// with the queue used to the given shares.
+func samplesAt(rate int64, used ...float64) []ThroughputSample {
+	start := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	samples := []ThroughputSample{}
+	for i, u := range used {
+		samples = append(samples, ThroughputSample{
+			Time:                   start.Add(time.Duration(i) * 10 * time.Second),
+			TotalEventsSeen:        1000 + rate*10*int64(i),
+			QueueTotalCapacity:     8192,
+			QueueRemainingCapacity: 8192 - int(u*8192),
+		})
+	}
+	return samples
+}
+
+func TestMeasureThroughput(t *testing.T) {
+	got, err := MeasureThroughput(samplesAt(500, 0.25, 0.75, 0.5))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.EventsPerSecond != 500 || got.QueueUtilization != 0.5 || got.PeakQueueUtilization != 0.75 || got.Samples != 3 {
+		t.Fatalf("unexpected throughput: %+v", got)
+	}
+	restarted := samplesAt(500, 0.1, 0.1)
+	restarted[1].TotalEventsSeen = 10
+	if _, err := MeasureThroughput(restarted); err == nil {
+		t.Fatalf("expected a counter reset to be rejected")
+	}
+	if _, err := MeasureThroughput(samplesAt(500, 0.1)); err == nil {
+		t.Fatalf("expected a single sample to be rejected")
+	}
+}
+
+func TestThroughputCheck_CapturesBaseline(t *testing.T) {
+	check := &ThroughputCheck{Inspector: fakeThroughputInspector{samples: samplesAt(500, 0.1, 0.1)}, Connector: "mysql-prod"}
+	findings, err := check.Run(context.Background(), checks.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Code != CodeThroughputBaseline {
+		t.Fatalf("expected baseline finding, got %+v", findings)
+	}
+	if tp, ok := findings[0].Meta["throughput"].(Throughput); !ok || tp.EventsPerSecond != 500 {
+		t.Fatalf("expected throughput in meta, got %+v", findings[0].Meta)
+	}
+}
+
+func TestThroughputCheck_ComparesWithBaseline(t *testing.T) {
+	baseline := &Throughput{EventsPerSecond: 1000, QueueUtilization: 0.2}
+	cases := []struct {
+		name     string
+		samples  []ThroughputSample
+		err      error
+		code     string
+		severity checks.Severity
+	}{
+		{"steady", samplesAt(900, 0.2, 0.25), nil, CodeThroughputOK, checks.SeverityInfo},
+		{"slower with a full queue", samplesAt(500, 0.3, 0.3), nil, CodeThroughputDegraded, checks.SeverityWarn},
+		{"queue backing up", samplesAt(1000, 0.6, 0.7), nil, CodeThroughputDegraded, checks.SeverityWarn},
+		{"less write load", samplesAt(300, 0.05, 0.05), nil, CodeThroughputOK, checks.SeverityInfo},
+		{"unreadable", nil, errors.New("jolokia unreachable"), CodeThroughputUnknown, checks.SeverityWarn},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			check := &ThroughputCheck{Inspector: fakeThroughputInspector{samples: tc.samples, err: tc.err}, Connector: "mysql-prod", Baseline: baseline}
+			findings, err := check.Run(context.Background(), checks.Input{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(findings) != 1 || findings[0].Code != tc.code || findings[0].Severity != tc.severity {
+				t.Fatalf("expected %s %s, got %+v", tc.severity, tc.code, findings)
+			}
+		})
+	}
+}
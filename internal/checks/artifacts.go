@@ -0,0 +1,72 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+)
+
+// Artifact is evidence a check collected while producing its findings, such
+// as a schema dump, an EXPLAIN plan, a checksum table or a connector config.
+type Artifact struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// ArtifactRef points at a stored artifact.
+type ArtifactRef struct {
+	Name        string `json:"name"`
+	ContentType string `json:"content_type,omitempty"`
+	Ref         string `json:"ref"`
+}
+
+// ArtifactCheck is implemented by checks that collect artifacts. When the
+// Runner has an ArtifactStore it calls RunWithArtifacts instead of Run.
+type ArtifactCheck interface {
+	RunWithArtifacts(ctx context.Context, input Input) ([]Finding, []Artifact, error)
+}
+
+// ArtifactStore persists artifacts and returns a reference to each (a path or
+// URL). Names are unique per check.
+type ArtifactStore interface {
+	Put(check string, name string, data []byte) (string, error)
+}
+
+// storeArtifacts persists a check's artifacts and references them from every
+// finding's meta under "artifacts". A failed write is reported as a WARN: the
+// findings still stand without their evidence.
+func storeArtifacts(store ArtifactStore, check string, artifacts []Artifact, findings []Finding) ([]Finding, []ArtifactRef) {
+	refs := []ArtifactRef{}
+	for _, a := range artifacts {
+		ref, err := store.Put(check, a.Name, a.Data)
+		if err != nil {
+			findings = append(findings, Finding{
+				Severity: SeverityWarn,
+				Code:     CodeArtifactStoreFailed,
+				Message:  fmt.Sprintf("failed to store artifact %q for check %q: %v", a.Name, check, err),
+				Meta:     map[string]interface{}{"check": check, "artifact": a.Name},
+			})
+			continue
+		}
+		refs = append(refs, ArtifactRef{Name: a.Name, ContentType: a.ContentType, Ref: ref})
+	}
+	if len(refs) == 0 {
+		return findings, nil
+	}
+	paths := make([]string, len(refs))
+	for i, r := range refs {
+		paths[i] = r.Ref
+	}
+	for i, f := range findings {
+		if f.Code == CodeArtifactStoreFailed {
+			continue
+		}
+		meta := make(map[string]interface{}, len(f.Meta)+1)
+		for k, v := range f.Meta {
+			meta[k] = v
+		}
+		meta["artifacts"] = paths
+		findings[i].Meta = meta
+	}
+	return findings, refs
+}
@@ -0,0 +1,63 @@
+package checks
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type memoryArtifactStore struct {
+	data map[string][]byte
+	err  error
+}
+
+func (m *memoryArtifactStore) Put(check string, name string, data []byte) (string, error) {
+	if m.err != nil {
+		return "", m.err
+	}
+	if m.data == nil {
+		m.data = map[string][]byte{}
+	}
+	ref := check + "/" + name
+	m.data[ref] = data
+	return ref, nil
+}
+
+func TestRunner_StoresArtifactsAndReferencesThemFromFindings(t *testing.T) {
+	inspector := &fakeSchemaInspector{
+		primary: Schema{Tables: []Table{{Name: "t", PrimaryKey: []string{"id"}}}},
+		replica: Schema{Tables: []Table{{Name: "t"}}},
+	}
+	store := &memoryArtifactStore{}
+	runner := NewRunner([]PreflightCheck{&SchemaParityCheck{Inspector: inspector, PrimaryHost: "primary", ReplicaHost: "replica"}}, nil)
+	runner.Artifacts = store
+
+	_, results, err := runner.Run(context.Background(), Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results[0].Artifacts) != 2 || len(store.data["schema_parity/primary-schema.json"]) == 0 {
+		t.Fatalf("expected both schema dumps stored, got %+v", results[0].Artifacts)
+	}
+	refs, _ := results[0].Findings[0].Meta["artifacts"].([]string)
+	if len(refs) != 2 || refs[1] != "schema_parity/replica-schema.json" {
+		t.Fatalf("expected findings to reference the artifacts, got %+v", results[0].Findings[0].Meta)
+	}
+}
+
+func TestRunner_ArtifactStoreFailureWarns(t *testing.T) {
+	inspector := &fakeSchemaInspector{primary: Schema{Tables: []Table{{Name: "t"}}}, replica: Schema{Tables: []Table{{Name: "t"}}}}
+	runner := NewRunner([]PreflightCheck{&SchemaParityCheck{Inspector: inspector, PrimaryHost: "primary", ReplicaHost: "replica"}}, nil)
+	runner.Artifacts = &memoryArtifactStore{err: errors.New("disk full")}
+
+	summary, results, err := runner.Run(context.Background(), Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Warn != 2 || results[0].Findings[1].Code != CodeArtifactStoreFailed {
+		t.Fatalf("expected a WARN per failed artifact, got %+v", results[0].Findings)
+	}
+	if _, ok := results[0].Findings[0].Meta["artifacts"]; ok {
+		t.Fatalf("expected no artifact references when nothing was stored")
+	}
+}
@@ -0,0 +1,124 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DefaultMaxClockSkew is the clock skew ClockSkewCheck tolerates when MaxSkew
+// is not set.
+const DefaultMaxClockSkew = 2 * time.Second
+
+// ClockInspector reads a host's current time.
+type ClockInspector interface {
+	Clock(ctx context.Context, host string) (time.Time, error)
+}
+
+// HostClocks routes each host to the ClockInspector for its kind, so one
+// check can span MySQL servers and Kafka Connect workers.
+type HostClocks map[string]ClockInspector
+
+func (h HostClocks) Clock(ctx context.Context, host string) (time.Time, error) {
+	inspector, ok := h[host]
+	if !ok {
+		return time.Time{}, fmt.Errorf("no clock inspector for %q", host)
+	}
+	return inspector.Clock(ctx, host)
+}
+
+// ClockSkewCheck compares the time on each host with the operator host's.
+// Each reading is taken against the midpoint of the local clock before and
+// after it, so the round trip does not count as skew. Skew corrupts lag
+// measurements and the event timestamps gating logic relies on, so a spread
+// between any two clocks above MaxSkew is a WARN. A host whose clock cannot be
+// read is a WARN too.
+type ClockSkewCheck struct {
+	Inspector ClockInspector
+	Hosts     []string
+	MaxSkew   time.Duration
+	Now       func() time.Time
+}
+
+func (c *ClockSkewCheck) Name() string   { return "clock_skew" }
+func (c *ClockSkewCheck) ReadOnly() bool { return true }
+
+func (c *ClockSkewCheck) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"hosts":    c.Hosts,
+		"max_skew": c.maxSkew().String(),
+	}
+}
+
+func (c *ClockSkewCheck) maxSkew() time.Duration {
+	if c.MaxSkew <= 0 {
+		return DefaultMaxClockSkew
+	}
+	return c.MaxSkew
+}
+
+func (c *ClockSkewCheck) Run(ctx context.Context, input Input) ([]Finding, error) {
+	if c.Inspector == nil {
+		return nil, fmt.Errorf("clock inspector is required")
+	}
+	if len(c.Hosts) == 0 {
+		return nil, fmt.Errorf("at least one host is required")
+	}
+	now := time.Now
+	if c.Now != nil {
+		now = c.Now
+	}
+	maxSkew := c.maxSkew()
+
+	findings := []Finding{}
+	offsets := map[string]time.Duration{}
+	// The operator host is the reference clock at offset zero.
+	earliest, latest := "operator", "operator"
+	for _, host := range c.Hosts {
+		before := now()
+		remote, err := c.Inspector.Clock(ctx, host)
+		after := now()
+		if err != nil {
+			findings = append(findings, Finding{
+				Severity: SeverityWarn,
+				Code:     CodeClockUnknown,
+				Message:  fmt.Sprintf("unable to read the clock on %q: %v", host, err),
+				Meta:     map[string]interface{}{"host": host},
+			})
+			continue
+		}
+		offset := remote.Sub(before.Add(after.Sub(before) / 2))
+		offsets[host] = offset
+		if offset < offsets[earliest] {
+			earliest = host
+		}
+		if offset > offsets[latest] {
+			latest = host
+		}
+	}
+
+	meta := map[string]interface{}{"max_skew": maxSkew.String()}
+	for host, offset := range offsets {
+		meta["offset:"+host] = offset.Round(time.Millisecond).String()
+	}
+	spread := offsets[latest] - offsets[earliest]
+	if spread > maxSkew {
+		meta["ahead"] = latest
+		meta["behind"] = earliest
+		meta["skew"] = spread.Round(time.Millisecond).String()
+		findings = append(findings, Finding{
+			Severity: SeverityWarn,
+			Code:     CodeClockSkew,
+			Message:  fmt.Sprintf("clock on %q is %s ahead of %q (max %s); lag measurements and event timestamps will be off", latest, spread.Round(time.Millisecond), earliest, maxSkew),
+			Meta:     meta,
+		})
+	} else if len(offsets) > 0 {
+		findings = append(findings, Finding{
+			Severity: SeverityInfo,
+			Code:     CodeClockSkewOK,
+			Message:  fmt.Sprintf("clocks on %d hosts and the operator host agree within %s", len(offsets), maxSkew),
+			Meta:     meta,
+		})
+	}
+	return findings, nil
+}
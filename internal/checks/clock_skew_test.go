@@ -0,0 +1,67 @@
+package checks
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeClocks struct {
+	now     time.Time
+	offsets map[string]time.Duration
+}
+
+func (f *fakeClocks) Clock(ctx context.Context, host string) (time.Time, error) {
+	offset, ok := f.offsets[host]
+	if !ok {
+		return time.Time{}, errors.New("connection refused")
+	}
+	return f.now.Add(offset), nil
+}
+
+func TestClockSkewCheck_WarnsAboveMaxSkew(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	clocks := &fakeClocks{now: now, offsets: map[string]time.Duration{"primary": 500 * time.Millisecond, "replica-1": -2 * time.Second, "http://connect-1:8083": time.Second}}
+	check := &ClockSkewCheck{Inspector: clocks, Hosts: []string{"primary", "replica-1", "http://connect-1:8083"}, MaxSkew: 2 * time.Second, Now: func() time.Time { return now }}
+
+	findings, err := check.Run(context.Background(), Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Code != CodeClockSkew {
+		t.Fatalf("expected one skew warning, got %+v", findings)
+	}
+	meta := findings[0].Meta
+	if meta["ahead"] != "http://connect-1:8083" || meta["behind"] != "replica-1" || meta["skew"] != "3s" || meta["offset:primary"] != "500ms" {
+		t.Fatalf("unexpected meta: %+v", meta)
+	}
+}
+
+func TestClockSkewCheck_ComparesAgainstOperatorHost(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	clocks := &fakeClocks{now: now, offsets: map[string]time.Duration{"primary": 3 * time.Second, "replica-1": 3 * time.Second}}
+	check := &ClockSkewCheck{Inspector: clocks, Hosts: []string{"primary", "replica-1"}, Now: func() time.Time { return now }}
+
+	findings, _ := check.Run(context.Background(), Input{})
+	if len(findings) != 1 || findings[0].Code != CodeClockSkew || findings[0].Meta["behind"] != "operator" {
+		t.Fatalf("expected the operator host to be behind the topology, got %+v", findings)
+	}
+}
+
+func TestClockSkewCheck_UnreadableHostAndAgreement(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	clocks := HostClocks{
+		"primary":   &fakeClocks{now: now, offsets: map[string]time.Duration{"primary": 100 * time.Millisecond}},
+		"replica-1": &fakeClocks{now: now, offsets: map[string]time.Duration{"replica-1": -100 * time.Millisecond}},
+	}
+	check := &ClockSkewCheck{Inspector: clocks, Hosts: []string{"primary", "replica-1", "replica-2"}, Now: func() time.Time { return now }}
+
+	findings, err := check.Run(context.Background(), Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 2 || findings[0].Code != CodeClockUnknown || findings[1].Code != CodeClockSkewOK {
+		t.Fatalf("expected an unknown clock and agreement among the rest, got %+v", findings)
+	}
+}
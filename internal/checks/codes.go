@@ -0,0 +1,69 @@
+package checks
+
+// Finding codes are stable identifiers for findings. Messages may change
+// wording; codes are what policies, allowlists and reports key on.
+const (
+	CodeCheckError                = "CHECK_ERROR"
+	CodeCheckMessageMissing       = "CHECK_MESSAGE_MISSING"
+	CodeCheckMetaInvalid          = "CHECK_META_INVALID"
+	CodeReadOnlyViolation         = "READ_ONLY_VIOLATION"
+	CodeArtifactStoreFailed       = "ARTIFACT_STORE_FAILED"
+	CodeCheckNotRun               = "CHECK_NOT_RUN"
+	CodeSchemaParityOK            = "SCHEMA_PARITY_OK"
+	CodeSchemaTableMissing        = "SCHEMA_TABLE_MISSING"
+	CodeSchemaTableExtra          = "SCHEMA_TABLE_EXTRA"
+	CodeSchemaPKMissing           = "SCHEMA_PK_MISSING"
+	CodeSchemaPKExtra             = "SCHEMA_PK_EXTRA"
+	CodeSchemaPKMismatch          = "SCHEMA_PK_MISMATCH"
+	CodeSchemaColumnMissing       = "SCHEMA_COLUMN_MISSING"
+	CodeSchemaColumnExtra         = "SCHEMA_COLUMN_EXTRA"
+	CodeSchemaColumnType          = "SCHEMA_COLUMN_TYPE_MISMATCH"
+	CodeSchemaColumnNullable      = "SCHEMA_COLUMN_NULLABILITY_DIFFERS"
+	CodeSchemaColumnDefault       = "SCHEMA_COLUMN_DEFAULT_DIFFERS"
+	CodeSchemaColumnCollation     = "SCHEMA_COLUMN_COLLATION_DIFFERS"
+	CodeSchemaColumnGenerated     = "SCHEMA_GENERATED_COLUMN_MISMATCH"
+	CodeSchemaPartitionMismatch   = "SCHEMA_PARTITIONING_MISMATCH"
+	CodeSchemaIndexMissing        = "SCHEMA_INDEX_MISSING"
+	CodeSchemaIndexExtra          = "SCHEMA_INDEX_EXTRA"
+	CodeSchemaIndexMismatch       = "SCHEMA_INDEX_MISMATCH"
+	CodeSchemaFKMissing           = "SCHEMA_FK_MISSING"
+	CodeSchemaFKExtra             = "SCHEMA_FK_EXTRA"
+	CodeSchemaFKMismatch          = "SCHEMA_FK_MISMATCH"
+	CodeSchemaViewMissing         = "SCHEMA_VIEW_MISSING"
+	CodeSchemaViewExtra           = "SCHEMA_VIEW_EXTRA"
+	CodeSchemaViewMismatch        = "SCHEMA_VIEW_MISMATCH"
+	CodeSchemaViewColumnMissing   = "SCHEMA_VIEW_COLUMN_MISSING"
+	CodeSchemaRoutineMissing      = "SCHEMA_ROUTINE_MISSING"
+	CodeSchemaRoutineExtra        = "SCHEMA_ROUTINE_EXTRA"
+	CodeSchemaRoutineMismatch     = "SCHEMA_ROUTINE_MISMATCH"
+	CodeSchemaRoutineSecurity     = "SCHEMA_ROUTINE_SECURITY_DIFFERS"
+	CodeSchemaRoutineIncompatible = "SCHEMA_ROUTINE_INCOMPATIBLE"
+	CodeSchemaEventMissing        = "SCHEMA_EVENT_MISSING"
+	CodeSchemaEventExtra          = "SCHEMA_EVENT_EXTRA"
+	CodeSchemaEventMismatch       = "SCHEMA_EVENT_MISMATCH"
+	CodeSchemaEventEnabled        = "SCHEMA_EVENT_ENABLED_ON_REPLICA"
+	CodeCompatVersionUntuned      = "COMPAT_VERSION_UNTUNED"
+	CodeCompatSQLMode             = "COMPAT_SQL_MODE_DEPRECATED"
+	CodeCompatFeature             = "COMPAT_FEATURE_DEPRECATED"
+	CodeCompatPKMissing           = "COMPAT_PK_MISSING"
+	CodeCompatCharset             = "COMPAT_CHARSET_RISK"
+	CodeCompatCollation           = "COMPAT_COLLATION_RISK"
+	CodeCompatPartitionEngine     = "COMPAT_PARTITION_ENGINE_UNSUPPORTED"
+	CodeCompatSpatialSRID         = "COMPAT_SPATIAL_INDEX_SRID_MISSING"
+	CodeCompatSpatialSRIDReady    = "COMPAT_SPATIAL_INDEX_SRID_READY"
+	CodeCompatFulltextRebuild     = "COMPAT_FULLTEXT_REBUILD"
+	CodeCompatGeometryNoSRID      = "COMPAT_GEOMETRY_SRID_UNSET"
+	CodeCompatReservedWord        = "COMPAT_RESERVED_WORD"
+	CodeCompatUTF8MB4Conversion   = "COMPAT_UTF8MB4_CONVERSION"
+	CodeCompatUTF8MB4IndexTooLong = "COMPAT_UTF8MB4_INDEX_TOO_LONG"
+	CodeCompatReservedWordsOK     = "COMPAT_RESERVED_WORDS_OK"
+	CodeCompatOK                  = "COMPAT_OK"
+	CodeDataParityOK              = "DATA_PARITY_OK"
+	CodeDataParityMismatch        = "DATA_PARITY_CHUNK_MISMATCH"
+	CodeDataParityIncomplete      = "DATA_PARITY_INCOMPLETE"
+	CodeDataParitySampleOK        = "DATA_PARITY_SAMPLE_OK"
+	CodeDataParitySampleDiff      = "DATA_PARITY_SAMPLE_MISMATCH"
+	CodeClockSkewOK               = "CLOCK_SKEW_OK"
+	CodeClockSkew                 = "CLOCK_SKEW_EXCEEDED"
+	CodeClockUnknown              = "CLOCK_UNKNOWN"
+)
@@ -0,0 +1,382 @@
+package checks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Chunk sizing defaults for DataParityCheck.
+const (
+	DefaultChunkSize       = 1000
+	DefaultMinChunkSize    = 100
+	DefaultMaxChunkSize    = 100000
+	DefaultTargetChunkTime = 500 * time.Millisecond
+	DefaultSampleSize      = 1000
+	DefaultConfidence      = 0.95
+)
+
+// Parity modes selectable per table.
+const (
+	ParityModeChecksum = "checksum"
+	ParityModeSample   = "sample"
+)
+
+// TableParityOptions selects how one table is compared. Sample mode compares
+// SampleSize random rows and reports, at Confidence, the highest mismatch rate
+// the sample cannot rule out.
+type TableParityOptions struct {
+	Mode       string
+	SampleSize int
+	Confidence float64
+}
+
+// SamplingInspector reads random primary keys and per-row checksums.
+type SamplingInspector interface {
+	SampleKeys(ctx context.Context, host string, table string, n int) ([]string, error)
+	// RowChecksums returns a checksum per key; keys absent on host are omitted.
+	RowChecksums(ctx context.Context, host string, table string, keys []string) (map[string]string, error)
+}
+
+// ChunkSum is the row count and checksum of one primary-key range.
+type ChunkSum struct {
+	Rows     int64
+	Checksum string
+}
+
+// ChecksumInspector computes checksums over primary-key ranges. Keys are
+// opaque, inspector-encoded primary-key values; "" means "before the first row".
+type ChecksumInspector interface {
+	// NextBoundary returns the key size rows after `after` on host, and done=true
+	// when fewer than size rows remain (upper is then the table's last key).
+	NextBoundary(ctx context.Context, host string, table string, after string, size int) (upper string, done bool, err error)
+	// ChunkChecksum summarizes rows with keys in (lower, upper].
+	ChunkChecksum(ctx context.Context, host string, table string, lower string, upper string) (ChunkSum, error)
+}
+
+// Checkpointer persists chunk progress. state.Scope satisfies it.
+type Checkpointer interface {
+	Get(key string) (interface{}, bool)
+	Set(key string, value interface{})
+}
+
+// ChunkRange identifies a mismatched primary-key range.
+type ChunkRange struct {
+	Lower string `json:"lower"`
+	Upper string `json:"upper"`
+}
+
+// ChunkCheckpoint is the persisted progress of one table's checksum.
+type ChunkCheckpoint struct {
+	After      string       `json:"after"`
+	Chunks     int          `json:"chunks"`
+	Rows       int64        `json:"rows"`
+	ChunkSize  int          `json:"chunk_size"`
+	Done       bool         `json:"done"`
+	Mismatches []ChunkRange `json:"mismatches,omitempty"`
+}
+
+// DataParityCheck compares table contents between primary and replica by
+// checksumming primary-key ranges. Chunk boundaries come from the primary and
+// chunk size adapts toward TargetChunkTime. With Checkpoints set, progress is
+// saved after every chunk so an interrupted run resumes where it stopped; a
+// completed table is reported from its checkpoint until the run is reset.
+// Compare while replication is caught up and paused, or in-flight rows show up
+// as mismatches. Tables listed in Options with ParityModeSample are sampled via
+// Sampler instead of fully checksummed.
+type DataParityCheck struct {
+	Inspector       ChecksumInspector
+	Sampler         SamplingInspector
+	Options         map[string]TableParityOptions
+	Checkpoints     Checkpointer
+	PrimaryHost     string
+	ReplicaHost     string
+	Tables          []string
+	ChunkSize       int
+	MinChunkSize    int
+	MaxChunkSize    int
+	TargetChunkTime time.Duration
+}
+
+func (c *DataParityCheck) Name() string   { return "data_parity" }
+func (c *DataParityCheck) ReadOnly() bool { return true }
+
+func (c *DataParityCheck) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"primary_host":      c.PrimaryHost,
+		"replica_host":      c.ReplicaHost,
+		"tables":            c.Tables,
+		"options":           c.Options,
+		"chunk_size":        c.ChunkSize,
+		"target_chunk_time": c.TargetChunkTime.String(),
+	}
+}
+
+func (c *DataParityCheck) Run(ctx context.Context, input Input) ([]Finding, error) {
+	if c.Inspector == nil && c.needsMode(ParityModeChecksum) {
+		return nil, fmt.Errorf("checksum inspector is required")
+	}
+	if c.Sampler == nil && c.needsMode(ParityModeSample) {
+		return nil, fmt.Errorf("sampling inspector is required")
+	}
+	if c.PrimaryHost == "" || c.ReplicaHost == "" {
+		return nil, fmt.Errorf("primary and replica hosts are required")
+	}
+	c.applyDefaults()
+
+	findings := []Finding{}
+	for _, table := range c.Tables {
+		if opts, ok := c.Options[table]; ok && opts.Mode == ParityModeSample {
+			sampled, err := c.sampleTable(ctx, table, opts)
+			if err != nil {
+				return nil, fmt.Errorf("sampled data parity for %s: %w", table, err)
+			}
+			findings = append(findings, sampled...)
+			continue
+		}
+		cp, resumed := c.loadCheckpoint(table)
+		resumedFrom := cp.Chunks
+		if !cp.Done {
+			var err error
+			cp, err = c.checksumTable(ctx, table, cp)
+			if err != nil {
+				if ctx.Err() != nil {
+					findings = append(findings, Finding{
+						Severity: SeverityWarn,
+						Code:     CodeDataParityIncomplete,
+						Message:  fmt.Sprintf("data parity for %s interrupted after %d chunks; re-run to resume", table, cp.Chunks),
+						Meta:     map[string]interface{}{"table": table, "chunks": cp.Chunks, "after": cp.After},
+					})
+					return append(findings, mismatchFindings(table, cp)...), nil
+				}
+				return nil, fmt.Errorf("data parity for %s: %w", table, err)
+			}
+		}
+
+		findings = append(findings, mismatchFindings(table, cp)...)
+		if len(cp.Mismatches) == 0 {
+			meta := map[string]interface{}{"table": table, "chunks": cp.Chunks, "rows": cp.Rows}
+			if resumed {
+				meta["resumed_from_chunk"] = resumedFrom
+			}
+			findings = append(findings, Finding{
+				Severity: SeverityInfo,
+				Code:     CodeDataParityOK,
+				Message:  fmt.Sprintf("data parity verified for %s (%d rows in %d chunks)", table, cp.Rows, cp.Chunks),
+				Meta:     meta,
+			})
+		}
+	}
+	return findings, nil
+}
+
+// needsMode reports whether any table is compared in mode.
+func (c *DataParityCheck) needsMode(mode string) bool {
+	for _, table := range c.Tables {
+		m := c.Options[table].Mode
+		if m == "" {
+			m = ParityModeChecksum
+		}
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// sampleTable compares random rows. With n samples and no mismatch, the true
+// mismatch rate is below 1-(1-confidence)^(1/n) at the given confidence.
+func (c *DataParityCheck) sampleTable(ctx context.Context, table string, opts TableParityOptions) ([]Finding, error) {
+	if opts.SampleSize <= 0 {
+		opts.SampleSize = DefaultSampleSize
+	}
+	if opts.Confidence <= 0 || opts.Confidence >= 1 {
+		opts.Confidence = DefaultConfidence
+	}
+	keys, err := c.Sampler.SampleKeys(ctx, c.PrimaryHost, table, opts.SampleSize)
+	if err != nil {
+		return nil, err
+	}
+	primary, err := c.Sampler.RowChecksums(ctx, c.PrimaryHost, table, keys)
+	if err != nil {
+		return nil, err
+	}
+	replica, err := c.Sampler.RowChecksums(ctx, c.ReplicaHost, table, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	mismatched := []string{}
+	for _, k := range keys {
+		if p, ok := primary[k]; ok && replica[k] != p {
+			mismatched = append(mismatched, k)
+		}
+	}
+	meta := map[string]interface{}{
+		"table":      table,
+		"mode":       ParityModeSample,
+		"sampled":    len(keys),
+		"confidence": opts.Confidence,
+		"mismatched": len(mismatched),
+	}
+	if len(mismatched) > 0 {
+		examples := mismatched
+		if len(examples) > 10 {
+			examples = examples[:10]
+		}
+		meta["observed_mismatch_rate"] = float64(len(mismatched)) / float64(len(keys))
+		meta["example_keys"] = examples
+		return []Finding{{
+			Severity: SeverityBlock,
+			Code:     CodeDataParitySampleDiff,
+			Message:  fmt.Sprintf("sampled data parity for %s found %d of %d rows differing", table, len(mismatched), len(keys)),
+			Meta:     meta,
+		}}, nil
+	}
+
+	if len(keys) == 0 {
+		return []Finding{{
+			Severity: SeverityInfo,
+			Code:     CodeDataParitySampleOK,
+			Message:  fmt.Sprintf("sampled data parity for %s: primary has no rows to sample", table),
+			Meta:     meta,
+		}}, nil
+	}
+	maxRate := 1 - math.Pow(1-opts.Confidence, 1/float64(len(keys)))
+	meta["max_mismatch_rate"] = maxRate
+	return []Finding{{
+		Severity: SeverityInfo,
+		Code:     CodeDataParitySampleOK,
+		Message:  fmt.Sprintf("sampled data parity for %s: %d rows match; mismatch rate below %.4f%% at %.0f%% confidence", table, len(keys), maxRate*100, opts.Confidence*100),
+		Meta:     meta,
+	}}, nil
+}
+
+func (c *DataParityCheck) applyDefaults() {
+	if c.MinChunkSize <= 0 {
+		c.MinChunkSize = DefaultMinChunkSize
+	}
+	if c.MaxChunkSize <= 0 {
+		c.MaxChunkSize = DefaultMaxChunkSize
+	}
+	if c.ChunkSize <= 0 {
+		c.ChunkSize = DefaultChunkSize
+	}
+	if c.TargetChunkTime <= 0 {
+		c.TargetChunkTime = DefaultTargetChunkTime
+	}
+}
+
+// checksumTable walks the table from the checkpoint, saving after each chunk.
+// On error the partial checkpoint is returned alongside it.
+func (c *DataParityCheck) checksumTable(ctx context.Context, table string, cp ChunkCheckpoint) (ChunkCheckpoint, error) {
+	if cp.ChunkSize <= 0 {
+		cp.ChunkSize = c.ChunkSize
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return cp, err
+		}
+		start := time.Now()
+		upper, done, err := c.Inspector.NextBoundary(ctx, c.PrimaryHost, table, cp.After, cp.ChunkSize)
+		if err != nil {
+			return cp, err
+		}
+		if upper == "" || upper == cp.After {
+			cp.Done = true
+			c.saveCheckpoint(table, cp)
+			return cp, nil
+		}
+		primary, err := c.Inspector.ChunkChecksum(ctx, c.PrimaryHost, table, cp.After, upper)
+		if err != nil {
+			return cp, err
+		}
+		replica, err := c.Inspector.ChunkChecksum(ctx, c.ReplicaHost, table, cp.After, upper)
+		if err != nil {
+			return cp, err
+		}
+		if primary != replica {
+			cp.Mismatches = append(cp.Mismatches, ChunkRange{Lower: cp.After, Upper: upper})
+		}
+		cp.After = upper
+		cp.Chunks++
+		cp.Rows += primary.Rows
+		cp.ChunkSize = nextChunkSize(cp.ChunkSize, time.Since(start), c.TargetChunkTime, c.MinChunkSize, c.MaxChunkSize)
+		cp.Done = done
+		c.saveCheckpoint(table, cp)
+		if done {
+			return cp, nil
+		}
+	}
+}
+
+// nextChunkSize scales size toward the target duration, changing by at most 2x
+// per chunk and staying within [min, max].
+func nextChunkSize(size int, elapsed time.Duration, target time.Duration, min int, max int) int {
+	if elapsed <= 0 {
+		elapsed = time.Millisecond
+	}
+	factor := float64(target) / float64(elapsed)
+	if factor > 2 {
+		factor = 2
+	}
+	if factor < 0.5 {
+		factor = 0.5
+	}
+	next := int(float64(size) * factor)
+	if next < min {
+		next = min
+	}
+	if next > max {
+		next = max
+	}
+	return next
+}
+
+func checkpointKey(table string) string {
+	return "data_parity:" + table
+}
+
+func (c *DataParityCheck) loadCheckpoint(table string) (ChunkCheckpoint, bool) {
+	var cp ChunkCheckpoint
+	if c.Checkpoints == nil {
+		return cp, false
+	}
+	v, ok := c.Checkpoints.Get(checkpointKey(table))
+	if !ok {
+		return cp, false
+	}
+	s, ok := v.(string)
+	if !ok || json.Unmarshal([]byte(s), &cp) != nil {
+		return ChunkCheckpoint{}, false
+	}
+	return cp, true
+}
+
+// saveCheckpoint stores the checkpoint as a JSON string so it round-trips
+// through file-backed state unchanged.
+func (c *DataParityCheck) saveCheckpoint(table string, cp ChunkCheckpoint) {
+	if c.Checkpoints == nil {
+		return
+	}
+	b, err := json.Marshal(cp)
+	if err != nil {
+		return
+	}
+	c.Checkpoints.Set(checkpointKey(table), string(b))
+}
+
+func mismatchFindings(table string, cp ChunkCheckpoint) []Finding {
+	findings := []Finding{}
+	for _, r := range cp.Mismatches {
+		findings = append(findings, Finding{
+			Severity: SeverityBlock,
+			Code:     CodeDataParityMismatch,
+			Message:  fmt.Sprintf("data mismatch in %s for primary keys (%s, %s]", table, r.Lower, r.Upper),
+			Meta:     map[string]interface{}{"table": table, "lower": r.Lower, "upper": r.Upper},
+		})
+	}
+	return findings
+}
@@ -0,0 +1,203 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// fakeChecksumInspector models tables with integer keys 1..rows. Keys listed in
+// replicaDiff checksum differently on the replica.
+type fakeChecksumInspector struct {
+	rows        int
+	replicaDiff map[int]bool
+	boundaries  int
+	failAfter   int
+}
+
+func (f *fakeChecksumInspector) NextBoundary(ctx context.Context, host string, table string, after string, size int) (string, bool, error) {
+	f.boundaries++
+	if f.failAfter > 0 && f.boundaries > f.failAfter {
+		return "", false, context.Canceled
+	}
+	start := 0
+	if after != "" {
+		start, _ = strconv.Atoi(after)
+	}
+	upper := start + size
+	if upper >= f.rows {
+		return strconv.Itoa(f.rows), true, nil
+	}
+	return strconv.Itoa(upper), false, nil
+}
+
+func (f *fakeChecksumInspector) ChunkChecksum(ctx context.Context, host string, table string, lower string, upper string) (ChunkSum, error) {
+	lo, _ := strconv.Atoi(lower)
+	hi, _ := strconv.Atoi(upper)
+	sum := 0
+	for k := lo + 1; k <= hi; k++ {
+		sum += k
+		if host == "replica" && f.replicaDiff[k] {
+			sum++
+		}
+	}
+	return ChunkSum{Rows: int64(hi - lo), Checksum: fmt.Sprint(sum)}, nil
+}
+
+type mapCheckpointer map[string]interface{}
+
+func (m mapCheckpointer) Get(key string) (interface{}, bool) { v, ok := m[key]; return v, ok }
+func (m mapCheckpointer) Set(key string, value interface{})  { m[key] = value }
+
+func TestDataParity_MatchingTablesInfo(t *testing.T) {
+	check := &DataParityCheck{Inspector: &fakeChecksumInspector{rows: 2500}, PrimaryHost: "primary", ReplicaHost: "replica", Tables: []string{"users"}, ChunkSize: 1000, MinChunkSize: 1000, MaxChunkSize: 1000}
+	findings, err := check.Run(context.Background(), Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Code != CodeDataParityOK || findings[0].Meta["rows"] != int64(2500) || findings[0].Meta["chunks"] != 3 {
+		t.Fatalf("expected single DATA_PARITY_OK for 2500 rows in 3 chunks, got %+v", findings)
+	}
+}
+
+func TestDataParity_MismatchedChunkBlocks(t *testing.T) {
+	inspector := &fakeChecksumInspector{rows: 3000, replicaDiff: map[int]bool{1500: true}}
+	check := &DataParityCheck{Inspector: inspector, PrimaryHost: "primary", ReplicaHost: "replica", Tables: []string{"orders"}, ChunkSize: 1000, MinChunkSize: 1000, MaxChunkSize: 1000}
+	findings, err := check.Run(context.Background(), Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Code != CodeDataParityMismatch || findings[0].Meta["lower"] != "1000" || findings[0].Meta["upper"] != "2000" {
+		t.Fatalf("expected mismatch in (1000, 2000], got %+v", findings)
+	}
+}
+
+func TestDataParity_ResumesFromCheckpoint(t *testing.T) {
+	checkpoints := mapCheckpointer{}
+	inspector := &fakeChecksumInspector{rows: 5000, failAfter: 2}
+	check := &DataParityCheck{Inspector: inspector, Checkpoints: checkpoints, PrimaryHost: "primary", ReplicaHost: "replica", Tables: []string{"users"}, ChunkSize: 1000, MinChunkSize: 1000, MaxChunkSize: 1000}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	findings, err := check.Run(ctx, Input{})
+	if err != nil || len(findings) != 1 || findings[0].Code != CodeDataParityIncomplete {
+		t.Fatalf("expected DATA_PARITY_INCOMPLETE on cancelled context, got %+v, %v", findings, err)
+	}
+
+	// Interrupt mid-table: the third boundary request fails.
+	_, err = check.Run(context.Background(), Input{})
+	if err == nil {
+		t.Fatalf("expected error when the inspector fails mid-table")
+	}
+	cp, ok := check.loadCheckpoint("users")
+	if !ok || cp.Chunks != 2 || cp.After != "2000" {
+		t.Fatalf("expected checkpoint after 2 chunks, got %+v", cp)
+	}
+
+	inspector.failAfter = 0
+	inspector.boundaries = 0
+	findings, err = check.Run(context.Background(), Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if inspector.boundaries != 3 {
+		t.Fatalf("expected resume to scan the remaining 3 chunks, scanned %d", inspector.boundaries)
+	}
+	if findings[0].Code != CodeDataParityOK || findings[0].Meta["rows"] != int64(5000) || findings[0].Meta["resumed_from_chunk"] != 2 {
+		t.Fatalf("unexpected resumed findings: %+v", findings)
+	}
+
+	inspector.boundaries = 0
+	if _, err := check.Run(context.Background(), Input{}); err != nil || inspector.boundaries != 0 {
+		t.Fatalf("expected completed table to be reported from checkpoint, scanned %d (%v)", inspector.boundaries, err)
+	}
+}
+
+func TestNextChunkSize_AdaptsWithinBounds(t *testing.T) {
+	target := 500 * time.Millisecond
+	if got := nextChunkSize(1000, 100*time.Millisecond, target, 100, 100000); got != 2000 {
+		t.Fatalf("expected fast chunk to double, got %d", got)
+	}
+	if got := nextChunkSize(1000, 5*time.Second, target, 100, 100000); got != 500 {
+		t.Fatalf("expected slow chunk to halve, got %d", got)
+	}
+	if got := nextChunkSize(150, 5*time.Second, target, 100, 100000); got != 100 {
+		t.Fatalf("expected min bound, got %d", got)
+	}
+	if got := nextChunkSize(90000, time.Millisecond, target, 100, 100000); got != 100000 {
+		t.Fatalf("expected max bound, got %d", got)
+	}
+}
+
+type fakeSampler struct {
+	keys    []string
+	replica map[string]string
+}
+
+func (f *fakeSampler) SampleKeys(ctx context.Context, host string, table string, n int) ([]string, error) {
+	if n < len(f.keys) {
+		return f.keys[:n], nil
+	}
+	return f.keys, nil
+}
+
+func (f *fakeSampler) RowChecksums(ctx context.Context, host string, table string, keys []string) (map[string]string, error) {
+	out := map[string]string{}
+	for _, k := range keys {
+		if host == "replica" {
+			if v, ok := f.replica[k]; ok {
+				out[k] = v
+			}
+			continue
+		}
+		out[k] = "sum-" + k
+	}
+	return out, nil
+}
+
+func TestDataParity_SampleModeReportsConfidence(t *testing.T) {
+	sampler := &fakeSampler{replica: map[string]string{}}
+	for i := 1; i <= 300; i++ {
+		k := strconv.Itoa(i)
+		sampler.keys = append(sampler.keys, k)
+		sampler.replica[k] = "sum-" + k
+	}
+	check := &DataParityCheck{
+		Sampler:     sampler,
+		PrimaryHost: "primary",
+		ReplicaHost: "replica",
+		Tables:      []string{"events"},
+		Options:     map[string]TableParityOptions{"events": {Mode: ParityModeSample, SampleSize: 300, Confidence: 0.95}},
+	}
+	findings, err := check.Run(context.Background(), Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Code != CodeDataParitySampleOK {
+		t.Fatalf("expected DATA_PARITY_SAMPLE_OK, got %+v", findings)
+	}
+	rate, _ := findings[0].Meta["max_mismatch_rate"].(float64)
+	if rate < 0.0099 || rate > 0.0101 {
+		t.Fatalf("expected ~1%% max mismatch rate for 300 samples at 95%%, got %v", rate)
+	}
+}
+
+func TestDataParity_SampleModeMismatchBlocks(t *testing.T) {
+	sampler := &fakeSampler{keys: []string{"1", "2", "3"}, replica: map[string]string{"1": "sum-1", "2": "stale"}}
+	check := &DataParityCheck{
+		Sampler:     sampler,
+		PrimaryHost: "primary",
+		ReplicaHost: "replica",
+		Tables:      []string{"events"},
+		Options:     map[string]TableParityOptions{"events": {Mode: ParityModeSample}},
+	}
+	findings, err := check.Run(context.Background(), Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Code != CodeDataParitySampleDiff || findings[0].Meta["mismatched"] != 2 {
+		t.Fatalf("expected 2 sampled mismatches (stale and missing row), got %+v", findings)
+	}
+}
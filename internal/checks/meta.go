@@ -0,0 +1,188 @@
+package checks
+
+import (
+	"fmt"
+	"sort"
+)
+
+// MetaType is the JSON type a well-known Finding.Meta key must hold.
+type MetaType string
+
+const (
+	MetaString  MetaType = "string"
+	MetaNumber  MetaType = "number"
+	MetaBool    MetaType = "bool"
+	MetaStrings MetaType = "list of strings"
+	MetaObject  MetaType = "object"
+)
+
+// Well-known Finding.Meta keys. Dashboards, policies and waivers may rely on
+// these names and types; checks must not reuse them for anything else.
+const (
+	MetaTable      = "table"
+	MetaTables     = "tables"
+	MetaTableCount = "table_count"
+	MetaReplica    = "replica"
+	MetaPrimary    = "primary"
+	MetaHost       = "host"
+	MetaHosts      = "hosts"
+	MetaSchema     = "schema"
+	MetaColumn     = "column"
+	MetaColumns    = "columns"
+	MetaIndex      = "index"
+	MetaForeignKey = "foreign_key"
+	MetaView       = "view"
+	MetaRoutine    = "routine"
+	MetaEvent      = "event"
+	MetaConnector  = "connector"
+	MetaTopic      = "topic"
+	MetaCheck      = "check"
+	MetaCause      = "cause"
+	MetaRunID      = "run_id"
+	MetaMigration  = "migration"
+	MetaLagSeconds = "lag_seconds"
+	MetaThreshold  = "threshold"
+
+	MetaPrimaryDefinition = "primary_definition"
+	MetaReplicaDefinition = "replica_definition"
+)
+
+// MetaKey documents a well-known Finding.Meta key.
+type MetaKey struct {
+	Type        MetaType
+	Description string
+}
+
+// MetaKeys lists the well-known Finding.Meta keys. Keys not listed here are
+// check-specific and carry no guarantee.
+var MetaKeys = map[string]MetaKey{
+	MetaTable:      {MetaString, "table the finding is about, as the snapshot names it"},
+	MetaTables:     {MetaStrings, "tables the finding is about"},
+	MetaTableCount: {MetaNumber, "number of tables considered"},
+	MetaReplica:    {MetaString, "replica host"},
+	MetaPrimary:    {MetaString, "primary host"},
+	MetaHost:       {MetaString, "host the finding was observed on"},
+	MetaHosts:      {MetaStrings, "hosts the check inspected"},
+	MetaSchema:     {MetaString, "database schema name"},
+	MetaColumn:     {MetaString, "column name"},
+	MetaColumns:    {MetaStrings, "column names"},
+	MetaIndex:      {MetaString, "index name"},
+	MetaForeignKey: {MetaString, "foreign key constraint name"},
+	MetaView:       {MetaString, "view name"},
+	MetaRoutine:    {MetaString, "stored procedure or function name"},
+	MetaEvent:      {MetaString, "scheduled event name"},
+	MetaConnector:  {MetaString, "CDC connector name"},
+	MetaTopic:      {MetaString, "Kafka topic"},
+	MetaCheck:      {MetaString, "name of the check the finding is about"},
+	MetaCause:      {MetaString, "failure kind behind a check error"},
+	MetaRunID:      {MetaString, "workflow run ID"},
+	MetaMigration:  {MetaString, "migration name from the plan"},
+	MetaLagSeconds: {MetaNumber, "measured replication lag in seconds"},
+	MetaThreshold:  {MetaObject, "the threshold a measurement was compared against: name, limit, measured, margin, unit"},
+
+	MetaPrimaryDefinition: {MetaString, "on schema drift findings, the primary's side of the compared definition"},
+	MetaReplicaDefinition: {MetaString, "on schema drift findings, the replica's side of the compared definition"},
+}
+
+// MetaRequired lists the well-known keys findings with a code must carry.
+var MetaRequired = map[string][]string{
+	CodeCheckError:                {MetaCheck},
+	CodeCheckMessageMissing:       {MetaCheck},
+	CodeCheckMetaInvalid:          {MetaCheck},
+	CodeReadOnlyViolation:         {MetaCheck},
+	CodeSchemaTableMissing:        {MetaTable},
+	CodeSchemaTableExtra:          {MetaTable},
+	CodeSchemaPKMissing:           {MetaTable},
+	CodeSchemaPKExtra:             {MetaTable},
+	CodeSchemaPKMismatch:          {MetaTable},
+	CodeSchemaColumnMissing:       {MetaTable, MetaColumn},
+	CodeSchemaColumnExtra:         {MetaTable, MetaColumn},
+	CodeSchemaColumnType:          {MetaTable, MetaColumn},
+	CodeSchemaColumnNullable:      {MetaTable, MetaColumn},
+	CodeSchemaColumnDefault:       {MetaTable, MetaColumn},
+	CodeSchemaColumnCollation:     {MetaTable, MetaColumn},
+	CodeSchemaColumnGenerated:     {MetaTable, MetaColumn},
+	CodeSchemaIndexMissing:        {MetaTable, MetaIndex},
+	CodeSchemaIndexExtra:          {MetaTable, MetaIndex},
+	CodeSchemaIndexMismatch:       {MetaTable, MetaIndex},
+	CodeSchemaFKMissing:           {MetaTable, MetaForeignKey},
+	CodeSchemaFKExtra:             {MetaTable, MetaForeignKey},
+	CodeSchemaFKMismatch:          {MetaTable, MetaForeignKey, MetaPrimaryDefinition, MetaReplicaDefinition},
+	CodeSchemaViewMissing:         {MetaView},
+	CodeSchemaViewExtra:           {MetaView},
+	CodeSchemaViewMismatch:        {MetaView, MetaPrimaryDefinition, MetaReplicaDefinition},
+	CodeSchemaViewColumnMissing:   {MetaView, MetaColumns},
+	CodeSchemaRoutineMissing:      {MetaRoutine},
+	CodeSchemaRoutineExtra:        {MetaRoutine},
+	CodeSchemaRoutineMismatch:     {MetaRoutine},
+	CodeSchemaRoutineSecurity:     {MetaRoutine, MetaPrimaryDefinition, MetaReplicaDefinition},
+	CodeSchemaEventMissing:        {MetaEvent},
+	CodeSchemaEventExtra:          {MetaEvent},
+	CodeSchemaEventMismatch:       {MetaEvent, MetaPrimaryDefinition, MetaReplicaDefinition},
+	CodeSchemaEventEnabled:        {MetaEvent},
+	CodeCompatSpatialSRID:         {MetaTable, MetaIndex, MetaColumn},
+	CodeCompatSpatialSRIDReady:    {MetaTable, MetaIndex, MetaColumn, MetaReplica},
+	CodeCompatReservedWord:        {MetaHost},
+	CodeCompatUTF8MB4Conversion:   {MetaTable, MetaColumn},
+	CodeCompatUTF8MB4IndexTooLong: {MetaTable, MetaColumn},
+}
+
+// ValidateMeta returns the ways a finding's meta breaks the well-known key
+// contract: a key holding the wrong type, or a key its code requires missing.
+func ValidateMeta(code string, meta map[string]interface{}) []string {
+	problems := []string{}
+	for _, key := range MetaRequired[code] {
+		if _, ok := meta[key]; !ok {
+			problems = append(problems, fmt.Sprintf("%s requires meta %q", code, key))
+		}
+	}
+	keys := make([]string, 0, len(meta))
+	for key := range meta {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		spec, ok := MetaKeys[key]
+		if !ok || meta[key] == nil {
+			continue
+		}
+		if !hasMetaType(meta[key], spec.Type) {
+			problems = append(problems, fmt.Sprintf("meta %q must be a %s, got %T", key, spec.Type, meta[key]))
+		}
+	}
+	return problems
+}
+
+func hasMetaType(value interface{}, want MetaType) bool {
+	switch want {
+	case MetaString:
+		_, ok := value.(string)
+		return ok
+	case MetaNumber:
+		switch value.(type) {
+		case int, int32, int64, uint, uint32, uint64, float32, float64:
+			return true
+		}
+		return false
+	case MetaBool:
+		_, ok := value.(bool)
+		return ok
+	case MetaStrings:
+		switch value.(type) {
+		case []string:
+			return true
+		case []interface{}:
+			for _, v := range value.([]interface{}) {
+				if _, ok := v.(string); !ok {
+					return false
+				}
+			}
+			return true
+		}
+		return false
+	case MetaObject:
+		_, ok := value.(map[string]interface{})
+		return ok
+	}
+	return false
+}
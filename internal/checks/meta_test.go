@@ -0,0 +1,81 @@
+package checks
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestValidateMeta(t *testing.T) {
+	if problems := ValidateMeta(CodeSchemaColumnMissing, map[string]interface{}{"table": "orders", "column": "id", "note": 3}); len(problems) != 0 {
+		t.Fatalf("expected valid meta, got %v", problems)
+	}
+	problems := ValidateMeta(CodeSchemaColumnMissing, map[string]interface{}{"table": 7, "hosts": []interface{}{"a", 1}})
+	want := []string{`requires meta "column"`, `meta "hosts" must be a list of strings`, `meta "table" must be a string, got int`}
+	if len(problems) != len(want) {
+		t.Fatalf("expected %d problems, got %v", len(want), problems)
+	}
+	for i, w := range want {
+		if !strings.Contains(problems[i], w) {
+			t.Fatalf("problem %d = %q, want it to mention %q", i, problems[i], w)
+		}
+	}
+	if problems := ValidateMeta("SOME_OTHER_CODE", map[string]interface{}{"lag_seconds": 1.5, "threshold": DurationThreshold("max_lag", 0).Meta(1.5)}); len(problems) != 0 {
+		t.Fatalf("expected valid meta, got %v", problems)
+	}
+}
+
+func TestValidateMeta_SchemaParityFindings(t *testing.T) {
+	primary := Schema{
+		Tables: []Table{{Name: "orders", PrimaryKey: []string{"id"},
+			Columns: []Column{{Name: "id", Type: "int"}, {Name: "note", Type: "text"}},
+			Indexes: []Index{{Name: "idx_note", Columns: []string{"note"}}}}},
+		Views:    []View{{Name: "v", Definition: "select 1"}},
+		Routines: []Routine{{Name: "p", Body: "BEGIN END"}},
+	}
+	replica := Schema{
+		Tables: []Table{{Name: "orders", PrimaryKey: []string{"id"}, Columns: []Column{{Name: "id", Type: "bigint"}}}},
+		Views:  []View{{Name: "v", Definition: "select 2"}},
+	}
+	findings := compareSchemas(primary, replica)
+	if len(findings) == 0 {
+		t.Fatalf("expected drift findings")
+	}
+	mismatched := false
+	for _, f := range findings {
+		if problems := ValidateMeta(f.Code, f.Meta); len(problems) != 0 {
+			t.Fatalf("%s: %v", f.Code, problems)
+		}
+		if f.Code == CodeSchemaViewMismatch {
+			mismatched = true
+			if _, ok := f.Meta[MetaPrimary]; ok {
+				t.Fatalf("expected the view definition outside the primary host key, got %+v", f.Meta)
+			}
+		}
+	}
+	if !mismatched {
+		t.Fatalf("expected a view mismatch, got %+v", findings)
+	}
+	if problems := ValidateMeta(CodeSchemaViewMismatch, map[string]interface{}{"view": "v", "primary": "select 1", "replica": "select 2"}); len(problems) != 2 {
+		t.Fatalf("expected the definitions to be required under their own keys, got %v", problems)
+	}
+}
+
+func TestRunner_FlagsInvalidMeta(t *testing.T) {
+	checks := []PreflightCheck{
+		NewReadOnlyCheck("sloppy", func(ctx context.Context, input Input) ([]Finding, error) {
+			return []Finding{{Severity: SeverityWarn, Code: CodeSchemaTableMissing, Message: "table gone", Meta: map[string]interface{}{"tables": 2}}}, nil
+		}),
+	}
+	summary, results, err := NewRunner(checks, nil).Run(context.Background(), Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	findings := results[0].Findings
+	if summary.Warn != 2 || len(findings) != 2 || findings[0].Code != CodeSchemaTableMissing || findings[1].Code != CodeCheckMetaInvalid {
+		t.Fatalf("expected the finding followed by %s, got %+v", CodeCheckMetaInvalid, findings)
+	}
+	if findings[1].Meta["code"] != CodeSchemaTableMissing || len(findings[1].Meta["problems"].([]string)) != 2 {
+		t.Fatalf("unexpected meta: %+v", findings[1].Meta)
+	}
+}
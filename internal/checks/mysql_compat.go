@@ -14,10 +14,19 @@ type MySQLInspector interface {
 
 // MySQLCompatibilityCheck validates MySQL 5.7 → 8.0 compatibility signals.
 // It detects:
-// - sql_mode risk modes (WARN)
-// - charset/collation risks (WARN)
-// - deprecated features (BLOCK)
-// - missing primary keys (BLOCK)
+//   - sql_mode risk modes (WARN)
+//   - charset/collation risks (WARN)
+//   - deprecated features (BLOCK)
+//   - missing primary keys (BLOCK)
+//   - partitioned tables on engines without native partitioning (BLOCK)
+//   - SPATIAL indexes without an SRID and FULLTEXT indexes to rebuild (WARN)
+//   - utf8/utf8mb3 columns, with the index key length converting them to
+//     utf8mb4 would take and candidate ALTER statements (INFO, or WARN when an
+//     index would exceed MaxIndexKeyBytes, DefaultMaxIndexKeyBytes when zero)
+//
+// 5.7 has no SRID column attribute, so SRIDs can only be set once the replica
+// runs 8.0. When Input.ReplicaHost is set, the replica's schema is read too,
+// and a SPATIAL index whose column has an SRID there is reported as ready.
 type MySQLCompatibilityCheck struct {
 	Inspector          MySQLInspector
 	SchemaInspector    SchemaInspector
@@ -26,11 +35,23 @@ type MySQLCompatibilityCheck struct {
 	DeprecatedFeatures []string
 	RiskyCharsets      []string
 	RiskyCollations    []string
+	MaxIndexKeyBytes   int
 }
 
 func (c *MySQLCompatibilityCheck) Name() string   { return "mysql_compat_57_80" }
 func (c *MySQLCompatibilityCheck) ReadOnly() bool { return true }
 
+func (c *MySQLCompatibilityCheck) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"primary_host":         c.PrimaryHost,
+		"deprecated_sql_modes": c.DeprecatedSQLModes,
+		"deprecated_features":  c.DeprecatedFeatures,
+		"risky_charsets":       c.RiskyCharsets,
+		"risky_collations":     c.RiskyCollations,
+		"max_index_key_bytes":  c.maxIndexKeyBytes(),
+	}
+}
+
 func (c *MySQLCompatibilityCheck) Run(ctx context.Context, input Input) ([]Finding, error) {
 	if c.Inspector == nil {
 		return nil, fmt.Errorf("mysql inspector is required")
@@ -48,6 +69,7 @@ func (c *MySQLCompatibilityCheck) Run(ctx context.Context, input Input) ([]Findi
 		if input.PlanSourceVersion != "5.7" || input.PlanTargetVersion != "8.0" {
 			findings = append(findings, Finding{
 				Severity: SeverityWarn,
+				Code:     CodeCompatVersionUntuned,
 				Message:  "compatibility check tuned for 5.7 → 8.0 upgrades",
 				Meta:     map[string]interface{}{"source_version": input.PlanSourceVersion, "target_version": input.PlanTargetVersion},
 			})
@@ -63,6 +85,7 @@ func (c *MySQLCompatibilityCheck) Run(ctx context.Context, input Input) ([]Findi
 		if modeSet[strings.ToUpper(mode)] {
 			findings = append(findings, Finding{
 				Severity: SeverityWarn,
+				Code:     CodeCompatSQLMode,
 				Message:  fmt.Sprintf("sql_mode includes deprecated mode %q for 8.0", mode),
 				Meta:     map[string]interface{}{"mode": mode},
 			})
@@ -78,6 +101,7 @@ func (c *MySQLCompatibilityCheck) Run(ctx context.Context, input Input) ([]Findi
 		if featureSet[strings.ToUpper(feature)] {
 			findings = append(findings, Finding{
 				Severity: SeverityBlock,
+				Code:     CodeCompatFeature,
 				Message:  fmt.Sprintf("deprecated feature detected: %q", feature),
 				Meta:     map[string]interface{}{"feature": feature},
 			})
@@ -88,18 +112,37 @@ func (c *MySQLCompatibilityCheck) Run(ctx context.Context, input Input) ([]Findi
 	if err != nil {
 		return nil, fmt.Errorf("failed to read schema: %v", err)
 	}
+	var replicaTables map[string]Table
+	if input.ReplicaHost != "" && input.ReplicaHost != c.PrimaryHost {
+		// An unreadable replica only means no SRID can be credited to it.
+		if replica, err := c.SchemaInspector.Schema(ctx, input.ReplicaHost); err == nil {
+			replicaTables = tableIndex(replica.Tables)
+		}
+	}
 	for _, table := range schema.Tables {
 		if len(table.PrimaryKey) == 0 {
 			findings = append(findings, Finding{
 				Severity: SeverityBlock,
+				Code:     CodeCompatPKMissing,
 				Message:  fmt.Sprintf("table %q missing primary key (CDC risk)", table.Name),
 				Meta:     map[string]interface{}{"table": table.Name},
 			})
 		}
+		if nonNativePartitioning(table) {
+			findings = append(findings, Finding{
+				Severity: SeverityBlock,
+				Code:     CodeCompatPartitionEngine,
+				Message:  fmt.Sprintf("table %q is partitioned on engine %q; 8.0 only supports native partitioning in InnoDB and NDB", table.Name, table.Engine),
+				Meta:     map[string]interface{}{"table": table.Name, "engine": table.Engine, "method": table.Partitioning.Method},
+			})
+		}
+		findings = append(findings, indexCompatFindings(table, input.ReplicaHost, replicaTables[table.Name])...)
+		findings = append(findings, utf8mb4Findings(table, c.maxIndexKeyBytes())...)
 		for _, col := range table.Columns {
 			if containsInsensitive(c.RiskyCharsets, col.Charset) {
 				findings = append(findings, Finding{
 					Severity: SeverityWarn,
+					Code:     CodeCompatCharset,
 					Message:  fmt.Sprintf("table %q column %q uses risky charset %q", table.Name, col.Name, col.Charset),
 					Meta:     map[string]interface{}{"table": table.Name, "column": col.Name, "charset": col.Charset},
 				})
@@ -107,6 +150,7 @@ func (c *MySQLCompatibilityCheck) Run(ctx context.Context, input Input) ([]Findi
 			if containsInsensitive(c.RiskyCollations, col.Collation) {
 				findings = append(findings, Finding{
 					Severity: SeverityWarn,
+					Code:     CodeCompatCollation,
 					Message:  fmt.Sprintf("table %q column %q uses risky collation %q", table.Name, col.Name, col.Collation),
 					Meta:     map[string]interface{}{"table": table.Name, "column": col.Name, "collation": col.Collation},
 				})
@@ -115,12 +159,99 @@ func (c *MySQLCompatibilityCheck) Run(ctx context.Context, input Input) ([]Findi
 	}
 
 	if len(findings) == 0 {
-		findings = append(findings, Finding{Severity: SeverityInfo, Message: "no MySQL 5.7 → 8.0 compatibility risks detected"})
+		findings = append(findings, Finding{Severity: SeverityInfo, Code: CodeCompatOK, Message: "no MySQL 5.7 → 8.0 compatibility risks detected"})
 	}
 
 	return findings, nil
 }
 
+func (c *MySQLCompatibilityCheck) maxIndexKeyBytes() int {
+	if c.MaxIndexKeyBytes > 0 {
+		return c.MaxIndexKeyBytes
+	}
+	return DefaultMaxIndexKeyBytes
+}
+
+// geometryTypes are the spatial column types.
+var geometryTypes = []string{"geometry", "point", "linestring", "polygon", "multipoint", "multilinestring", "multipolygon", "geometrycollection", "geomcollection"}
+
+// indexCompatFindings reports FULLTEXT and spatial objects that need work for
+// 8.0. The 8.0 optimizer ignores a SPATIAL index unless its column carries an
+// SRID attribute, and InnoDB FULLTEXT indexes should be rebuilt after the
+// dictionary upgrade. Columns that already have an SRID in replicaTable, the
+// same table on the upgraded replica, count as done.
+func indexCompatFindings(table Table, replica string, replicaTable Table) []Finding {
+	findings := []Finding{}
+	columns := columnIndex(table.Columns)
+	replicaColumns := columnIndex(replicaTable.Columns)
+	spatialIndexed := map[string]bool{}
+	for _, idx := range table.Indexes {
+		switch strings.ToUpper(idx.Type) {
+		case "SPATIAL":
+			for _, name := range idx.Columns {
+				spatialIndexed[name] = true
+				col, ok := columns[name]
+				if !ok || col.SRID != nil {
+					continue
+				}
+				meta := map[string]interface{}{"table": table.Name, "index": idx.Name, "column": name, "type": col.Type}
+				if upgraded, ok := replicaColumns[name]; ok && upgraded.SRID != nil {
+					meta["replica"] = replica
+					meta["srid"] = *upgraded.SRID
+					findings = append(findings, Finding{
+						Severity: SeverityInfo,
+						Code:     CodeCompatSpatialSRIDReady,
+						Message:  fmt.Sprintf("table %q SPATIAL index %q: column %q has SRID %d on upgraded replica %q, so 8.0 uses the index there", table.Name, idx.Name, name, *upgraded.SRID, replica),
+						Meta:     meta,
+					})
+					continue
+				}
+				findings = append(findings, Finding{
+					Severity: SeverityWarn,
+					Code:     CodeCompatSpatialSRID,
+					Message:  fmt.Sprintf("table %q SPATIAL index %q is on column %q without an SRID; 8.0 will not use it until the column gets one on the upgraded replica, which also rebuilds the index", table.Name, idx.Name, name),
+					Meta:     meta,
+				})
+			}
+		case "FULLTEXT":
+			findings = append(findings, Finding{
+				Severity: SeverityWarn,
+				Code:     CodeCompatFulltextRebuild,
+				Message:  fmt.Sprintf("table %q has FULLTEXT index %q; rebuild it after the upgrade and re-verify search results", table.Name, idx.Name),
+				Meta:     map[string]interface{}{"table": table.Name, "index": idx.Name, "columns": idx.Columns, "engine": table.Engine},
+			})
+		}
+	}
+	unrestricted := []string{}
+	for _, col := range table.Columns {
+		if upgraded, ok := replicaColumns[col.Name]; ok && upgraded.SRID != nil {
+			continue
+		}
+		if containsInsensitive(geometryTypes, col.Type) && col.SRID == nil && !spatialIndexed[col.Name] {
+			unrestricted = append(unrestricted, col.Name)
+		}
+	}
+	if len(unrestricted) > 0 {
+		findings = append(findings, Finding{
+			Severity: SeverityInfo,
+			Code:     CodeCompatGeometryNoSRID,
+			Message:  fmt.Sprintf("table %q geometry columns %s have no SRID; assign one before adding SPATIAL indexes on 8.0", table.Name, strings.Join(unrestricted, ", ")),
+			Meta:     map[string]interface{}{"table": table.Name, "columns": unrestricted},
+		})
+	}
+	return findings
+}
+
+// nativePartitionEngines are the engines with native partitioning in 8.0; the
+// generic ha_partition handler other 5.7 engines relied on was removed.
+var nativePartitionEngines = []string{"InnoDB", "ndbcluster", "NDB"}
+
+// nonNativePartitioning reports whether table is partitioned through the
+// generic handler, which 8.0 refuses to start with.
+func nonNativePartitioning(table Table) bool {
+	return table.Partitioning != nil && table.Engine != "" && !containsInsensitive(nativePartitionEngines, table.Engine)
+}
+
 func splitCSV(value string) map[string]bool {
 	set := map[string]bool{}
 	for _, part := range strings.Split(value, ",") {
@@ -20,9 +20,13 @@ func (f *fakeMySQLInspector) DeprecatedFeaturesUsed(ctx context.Context, host st
 
 type fakeSchemaInspectorCompat struct {
 	schema Schema
+	hosts  map[string]Schema
 }
 
 func (f *fakeSchemaInspectorCompat) Schema(ctx context.Context, host string) (Schema, error) {
+	if schema, ok := f.hosts[host]; ok {
+		return schema, nil
+	}
 	return f.schema, nil
 }
 
@@ -98,6 +102,119 @@ func TestMySQLCompatibility_CharsetCollationWarns(t *testing.T) {
 	}
 }
 
+func TestMySQLCompatibility_NonNativePartitionEngineBlocks(t *testing.T) {
+	check := &MySQLCompatibilityCheck{
+		Inspector: &fakeMySQLInspector{},
+		SchemaInspector: &fakeSchemaInspectorCompat{schema: Schema{Tables: []Table{
+			{Name: "archive", PrimaryKey: []string{"id"}, Engine: "MyISAM", Partitioning: &Partitioning{Method: "HASH", Expression: "id"}},
+			{Name: "events", PrimaryKey: []string{"id"}, Engine: "InnoDB", Partitioning: &Partitioning{Method: "RANGE", Expression: "id"}},
+		}}},
+		PrimaryHost: "primary",
+	}
+
+	findings, err := check.Run(context.Background(), Input{PlanSourceVersion: "5.7", PlanTargetVersion: "8.0"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Code != CodeCompatPartitionEngine || findings[0].Meta["table"] != "archive" {
+		t.Fatalf("expected only archive to block, got %+v", findings)
+	}
+}
+
+func TestMySQLCompatibility_SpatialAndFulltextIndexes(t *testing.T) {
+	srid := uint32(4326)
+	check := &MySQLCompatibilityCheck{
+		Inspector: &fakeMySQLInspector{},
+		SchemaInspector: &fakeSchemaInspectorCompat{schema: Schema{Tables: []Table{{
+			Name:       "places",
+			PrimaryKey: []string{"id"},
+			Engine:     "InnoDB",
+			Columns: []Column{
+				{Name: "id", Type: "int"},
+				{Name: "location", Type: "point"},
+				{Name: "area", Type: "polygon", SRID: &srid},
+				{Name: "outline", Type: "geometry"},
+				{Name: "body", Type: "text"},
+			},
+			Indexes: []Index{
+				{Name: "sp_location", Type: "SPATIAL", Columns: []string{"location"}},
+				{Name: "sp_area", Type: "SPATIAL", Columns: []string{"area"}},
+				{Name: "ft_body", Type: "FULLTEXT", Columns: []string{"body"}},
+			},
+		}}}},
+		PrimaryHost: "primary",
+	}
+
+	findings, err := check.Run(context.Background(), Input{PlanSourceVersion: "5.7", PlanTargetVersion: "8.0"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := map[string]Finding{}
+	for _, f := range findings {
+		got[f.Code] = f
+	}
+	if len(findings) != 3 {
+		t.Fatalf("expected three findings, got %+v", findings)
+	}
+	if f := got[CodeCompatSpatialSRID]; f.Severity != SeverityWarn || f.Meta["column"] != "location" {
+		t.Fatalf("expected SRID warning for location only, got %+v", findings)
+	}
+	if f := got[CodeCompatFulltextRebuild]; f.Severity != SeverityWarn || f.Meta["index"] != "ft_body" {
+		t.Fatalf("expected FULLTEXT rebuild warning, got %+v", findings)
+	}
+	if f := got[CodeCompatGeometryNoSRID]; f.Severity != SeverityInfo || len(f.Meta["columns"].([]string)) != 1 {
+		t.Fatalf("expected outline listed as unrestricted geometry, got %+v", findings)
+	}
+}
+
+func TestMySQLCompatibility_SRIDSetOnUpgradedReplica(t *testing.T) {
+	srid := uint32(4326)
+	table := func(srid *uint32) Table {
+		return Table{
+			Name:       "places",
+			PrimaryKey: []string{"id"},
+			Engine:     "InnoDB",
+			Columns: []Column{
+				{Name: "id", Type: "int"},
+				{Name: "location", Type: "point", SRID: srid},
+				{Name: "outline", Type: "geometry", SRID: srid},
+			},
+			Indexes: []Index{{Name: "sp_location", Type: "SPATIAL", Columns: []string{"location"}}},
+		}
+	}
+	check := &MySQLCompatibilityCheck{
+		Inspector: &fakeMySQLInspector{},
+		SchemaInspector: &fakeSchemaInspectorCompat{
+			schema: Schema{Tables: []Table{table(nil)}},
+			hosts:  map[string]Schema{"replica": {Tables: []Table{table(&srid)}}},
+		},
+		PrimaryHost: "primary",
+	}
+
+	findings, err := check.Run(context.Background(), Input{ReplicaHost: "replica"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Code != CodeCompatSpatialSRIDReady || findings[0].Severity != SeverityInfo {
+		t.Fatalf("expected the replica's SRID to make the index ready, got %+v", findings)
+	}
+	if findings[0].Meta["srid"] != srid || findings[0].Meta["replica"] != "replica" {
+		t.Fatalf("unexpected meta: %+v", findings[0].Meta)
+	}
+	if problems := ValidateMeta(findings[0].Code, findings[0].Meta); len(problems) > 0 {
+		t.Fatalf("invalid meta: %v", problems)
+	}
+
+	findings, _ = check.Run(context.Background(), Input{ReplicaHost: "primary"})
+	codes := map[string]bool{}
+	for _, f := range findings {
+		codes[f.Code] = true
+	}
+	if !codes[CodeCompatSpatialSRID] || !codes[CodeCompatGeometryNoSRID] {
+		t.Fatalf("expected the 5.7 schema alone to still warn, got %+v", findings)
+	}
+}
+
 func hasSeverityCompat(findings []Finding, severity Severity) bool {
 	for _, f := range findings {
 		if f.Severity == severity {
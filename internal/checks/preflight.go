@@ -2,9 +2,13 @@ package checks
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
+	"time"
+
+	"migratorx/internal/failure"
 )
 
 // Severity indicates the importance of a preflight finding.
@@ -29,9 +33,24 @@ func (s Severity) String() string {
 	}
 }
 
-// Finding is a single preflight result.
+// ParseSeverity parses a case-insensitive severity name (info, warn, block).
+func ParseSeverity(value string) (Severity, error) {
+	switch strings.ToUpper(strings.TrimSpace(value)) {
+	case "INFO":
+		return SeverityInfo, nil
+	case "WARN":
+		return SeverityWarn, nil
+	case "BLOCK":
+		return SeverityBlock, nil
+	default:
+		return SeverityInfo, fmt.Errorf("unknown severity %q (expected info, warn or block)", value)
+	}
+}
+
+// Finding is a single preflight result. Code is a stable identifier (see codes.go).
 type Finding struct {
 	Severity Severity
+	Code     string
 	Message  string
 	Meta     map[string]interface{}
 }
@@ -59,17 +78,34 @@ type PreflightCheck interface {
 	ReadOnly() bool
 }
 
+// ParameterizedCheck is implemented by checks that can describe the parameters
+// they run with, so run manifests can record exactly what was evaluated.
+type ParameterizedCheck interface {
+	Parameters() map[string]interface{}
+}
+
 // Runner executes preflight checks and aggregates findings.
 // It enforces read-only checks and validates that all findings have messages.
+// When Artifacts is set, artifacts collected by ArtifactChecks are stored
+// there; otherwise they are discarded. By default every check runs so the
+// results are a complete risk picture; FailFast stops at the first check that
+// reports a BLOCK, and each remaining check gets a single CodeCheckNotRun
+// INFO finding instead. Now defaults to time.Now.
 type Runner struct {
-	Checks []PreflightCheck
-	Logger *log.Logger
+	Checks    []PreflightCheck
+	Logger    *log.Logger
+	Artifacts ArtifactStore
+	FailFast  bool
+	Now       func() time.Time
 }
 
-// Result captures findings for a single check.
+// Result captures findings and stored artifacts for a single check.
+// CheckedAt is when the check finished; it is zero for checks not run.
 type Result struct {
 	CheckName string
 	Findings  []Finding
+	Artifacts []ArtifactRef
+	CheckedAt time.Time
 }
 
 // NewRunner constructs a preflight Runner.
@@ -81,35 +117,94 @@ func NewRunner(checks []PreflightCheck, logger *log.Logger) *Runner {
 }
 
 // Run executes all checks sequentially and returns a summary and per-check results.
-// Any check error is translated into a BLOCK finding with a clear message.
+// Any check error is translated into a BLOCK finding with a clear message, and
+// a typed failure (see package failure) is named in its "cause" meta; a
+// ReadOnlyViolationError is reported under CodeReadOnlyViolation.
 func (r *Runner) Run(ctx context.Context, input Input) (Summary, []Result, error) {
 	var summary Summary
 	results := make([]Result, 0, len(r.Checks))
 
 	for _, check := range r.Checks {
 		if !check.ReadOnly() {
-			return Summary{}, nil, fmt.Errorf("preflight check %q is not read-only", check.Name())
+			return Summary{}, nil, failure.Config("preflight check %q is not read-only", check.Name())
+		}
+	}
+
+	now := time.Now
+	if r.Now != nil {
+		now = r.Now
+	}
+	stopped := ""
+	for _, check := range r.Checks {
+		if stopped != "" {
+			notRun := []Finding{{
+				Severity: SeverityInfo,
+				Code:     CodeCheckNotRun,
+				Message:  fmt.Sprintf("check not run: fail-fast stopped after %q reported a BLOCK", stopped),
+				Meta:     map[string]interface{}{"check": check.Name(), "stopped_after": stopped},
+			}}
+			applySummary(&summary, notRun)
+			results = append(results, Result{CheckName: check.Name(), Findings: notRun})
+			continue
 		}
 
 		r.Logger.Printf("running preflight check: %s", check.Name())
-		findings, err := check.Run(ctx, input)
-		if err != nil {
+		var findings []Finding
+		var artifacts []Artifact
+		var err error
+		if ac, ok := check.(ArtifactCheck); ok && r.Artifacts != nil {
+			findings, artifacts, err = ac.RunWithArtifacts(ctx, input)
+		} else {
+			findings, err = check.Run(ctx, input)
+		}
+		var violation *ReadOnlyViolationError
+		if errors.As(err, &violation) {
+			findings = append(findings, Finding{
+				Severity: SeverityBlock,
+				Code:     CodeReadOnlyViolation,
+				Message:  fmt.Sprintf("check %q attempted a write during a read-only phase: %v", check.Name(), violation),
+				Meta:     map[string]interface{}{"check": check.Name(), "statement": violation.Statement},
+			})
+		} else if err != nil {
+			meta := map[string]interface{}{"check": check.Name()}
+			if kind := failure.KindOf(err); kind != "" {
+				meta["cause"] = string(kind)
+			}
 			findings = append(findings, Finding{
 				Severity: SeverityBlock,
+				Code:     CodeCheckError,
 				Message:  fmt.Sprintf("check error: %v", err),
-				Meta:     map[string]interface{}{"check": check.Name()},
+				Meta:     meta,
 			})
 		}
 
 		findings = enforceMessages(check.Name(), findings)
+		findings = enforceMeta(check.Name(), findings)
+		var refs []ArtifactRef
+		if len(artifacts) > 0 {
+			findings, refs = storeArtifacts(r.Artifacts, check.Name(), artifacts, findings)
+		}
 		applySummary(&summary, findings)
 
-		results = append(results, Result{CheckName: check.Name(), Findings: findings})
+		results = append(results, Result{CheckName: check.Name(), Findings: findings, Artifacts: refs, CheckedAt: now()})
+		if r.FailFast && hasBlock(findings) {
+			r.Logger.Printf("fail-fast: %s reported a BLOCK; not running the remaining checks", check.Name())
+			stopped = check.Name()
+		}
 	}
 
 	return summary, results, nil
 }
 
+func hasBlock(findings []Finding) bool {
+	for _, f := range findings {
+		if f.Severity == SeverityBlock {
+			return true
+		}
+	}
+	return false
+}
+
 func applySummary(summary *Summary, findings []Finding) {
 	for _, f := range findings {
 		switch f.Severity {
@@ -129,6 +224,7 @@ func enforceMessages(checkName string, findings []Finding) []Finding {
 		if strings.TrimSpace(f.Message) == "" {
 			out = append(out, Finding{
 				Severity: SeverityBlock,
+				Code:     CodeCheckMessageMissing,
 				Message:  fmt.Sprintf("check %q emitted a finding without a message", checkName),
 				Meta:     map[string]interface{}{"check": checkName},
 			})
@@ -139,6 +235,70 @@ func enforceMessages(checkName string, findings []Finding) []Finding {
 	return out
 }
 
+// enforceMeta follows each finding whose meta breaks the well-known key
+// contract with a WARN naming the problems. The finding itself is kept.
+func enforceMeta(checkName string, findings []Finding) []Finding {
+	out := make([]Finding, 0, len(findings))
+	for _, f := range findings {
+		out = append(out, f)
+		if problems := ValidateMeta(f.Code, f.Meta); len(problems) > 0 {
+			out = append(out, Finding{
+				Severity: SeverityWarn,
+				Code:     CodeCheckMetaInvalid,
+				Message:  fmt.Sprintf("check %q emitted %s with invalid meta: %s", checkName, f.Code, strings.Join(problems, "; ")),
+				Meta:     map[string]interface{}{"check": checkName, "code": f.Code, "problems": problems},
+			})
+		}
+	}
+	return out
+}
+
+// FilterChecks narrows checks by name. When only is non-empty, just those checks
+// are kept; checks named in skip are removed. Unknown names are an error so a
+// typo cannot silently drop a check from the run.
+func FilterChecks(checksList []PreflightCheck, only []string, skip []string) ([]PreflightCheck, error) {
+	known := map[string]struct{}{}
+	for _, c := range checksList {
+		known[c.Name()] = struct{}{}
+	}
+	onlySet := map[string]struct{}{}
+	skipSet := map[string]struct{}{}
+	for _, group := range []struct {
+		names []string
+		set   map[string]struct{}
+	}{{only, onlySet}, {skip, skipSet}} {
+		for _, name := range group.names {
+			if _, ok := known[name]; !ok {
+				return nil, fmt.Errorf("unknown check %q", name)
+			}
+			group.set[name] = struct{}{}
+		}
+	}
+
+	out := make([]PreflightCheck, 0, len(checksList))
+	for _, c := range checksList {
+		if _, ok := onlySet[c.Name()]; len(onlySet) > 0 && !ok {
+			continue
+		}
+		if _, ok := skipSet[c.Name()]; ok {
+			continue
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// ReadOnlyViolationError reports a statement rejected by a read-only session.
+// Connection layers return it so ReadOnly() is enforced at runtime.
+type ReadOnlyViolationError struct {
+	Statement string
+	Reason    string
+}
+
+func (e *ReadOnlyViolationError) Error() string {
+	return fmt.Sprintf("statement not allowed in read-only session (%s): %s", e.Reason, e.Statement)
+}
+
 // ReadOnlyCheck is a helper for building read-only checks.
 type ReadOnlyCheck struct {
 	name  string
@@ -4,6 +4,7 @@ import (
 	"context"
 	"strings"
 	"testing"
+	"time"
 )
 
 type mutatingCheck struct{}
@@ -92,4 +93,65 @@ func TestRunner_RequiresMessage(t *testing.T) {
 	if !strings.Contains(results[0].Findings[0].Message, "without a message") {
 		t.Fatalf("unexpected message: %q", results[0].Findings[0].Message)
 	}
-}
\ No newline at end of file
+}
+
+func TestRunner_FailFastStopsAtFirstBlock(t *testing.T) {
+	ran := []string{}
+	check := func(name string, severity Severity) PreflightCheck {
+		return NewReadOnlyCheck(name, func(ctx context.Context, input Input) ([]Finding, error) {
+			ran = append(ran, name)
+			return []Finding{{Severity: severity, Message: name}}, nil
+		})
+	}
+	checksList := []PreflightCheck{check("first", SeverityWarn), check("second", SeverityBlock), check("third", SeverityInfo)}
+
+	summary, results, err := NewRunner(checksList, nil).Run(context.Background(), Input{})
+	if err != nil || len(ran) != 3 || summary.Block != 1 {
+		t.Fatalf("expected every check to run by default, ran %v (%+v, %v)", ran, summary, err)
+	}
+
+	ran = nil
+	checkedAt := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	runner := NewRunner(checksList, nil)
+	runner.FailFast = true
+	runner.Now = func() time.Time { return checkedAt }
+	summary, results, err = runner.Run(context.Background(), Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ran) != 2 || len(results) != 3 || summary != (Summary{Info: 1, Warn: 1, Block: 1}) {
+		t.Fatalf("expected fail-fast to stop after the BLOCK, ran %v (%+v)", ran, summary)
+	}
+	if f := results[2].Findings[0]; f.Code != CodeCheckNotRun || f.Meta["stopped_after"] != "second" {
+		t.Fatalf("expected the skipped check to be reported, got %+v", f)
+	}
+	if !results[1].CheckedAt.Equal(checkedAt) || !results[2].CheckedAt.IsZero() {
+		t.Fatalf("expected only checks that ran to be timestamped, got %v and %v", results[1].CheckedAt, results[2].CheckedAt)
+	}
+}
+
+func TestFilterChecks_OnlyAndSkip(t *testing.T) {
+	noop := func(ctx context.Context, input Input) ([]Finding, error) { return nil, nil }
+	all := []PreflightCheck{NewReadOnlyCheck("a", noop), NewReadOnlyCheck("b", noop), NewReadOnlyCheck("c", noop)}
+
+	only, err := FilterChecks(all, []string{"b"}, nil)
+	if err != nil || len(only) != 1 || only[0].Name() != "b" {
+		t.Fatalf("expected only b, got %v (err=%v)", only, err)
+	}
+	skipped, err := FilterChecks(all, nil, []string{"a"})
+	if err != nil || len(skipped) != 2 || skipped[0].Name() != "b" {
+		t.Fatalf("expected a skipped, got %v (err=%v)", skipped, err)
+	}
+	if _, err := FilterChecks(all, []string{"typo"}, nil); err == nil {
+		t.Fatalf("expected error for unknown check name")
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	if s, err := ParseSeverity("warn"); err != nil || s != SeverityWarn {
+		t.Fatalf("expected WARN, got %v (err=%v)", s, err)
+	}
+	if _, err := ParseSeverity("fatal"); err == nil {
+		t.Fatalf("expected error for unknown severity")
+	}
+}
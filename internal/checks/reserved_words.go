@@ -0,0 +1,105 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// reservedIn80 lists the words 8.0 reserves that 5.7 did not. An unquoted
+// identifier spelled like one parses in 5.7 and is a syntax error in 8.0, so
+// every query, view and routine naming the object breaks on the upgraded
+// replica. ARRAY and MEMBER are reserved from 8.0.17, INTERSECT from 8.0.31.
+var reservedIn80 = map[string]bool{
+	"ARRAY": true, "CUBE": true, "CUME_DIST": true, "DENSE_RANK": true, "EMPTY": true,
+	"EXCEPT": true, "FIRST_VALUE": true, "FUNCTION": true, "GROUPING": true, "GROUPS": true,
+	"INTERSECT": true, "JSON_TABLE": true, "LAG": true, "LAST_VALUE": true, "LATERAL": true,
+	"LEAD": true, "MEMBER": true, "NTH_VALUE": true, "NTILE": true, "OF": true, "OVER": true,
+	"PERCENT_RANK": true, "RANK": true, "RECURSIVE": true, "ROW": true, "ROWS": true,
+	"ROW_NUMBER": true, "SYSTEM": true, "WINDOW": true,
+}
+
+// ReservedIn80 reports whether name, unqualified, is a word 8.0 newly
+// reserves.
+func ReservedIn80(name string) bool {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	return reservedIn80[strings.ToUpper(strings.Trim(name, "`"))]
+}
+
+// ReservedWordCheck scans the table, column, view and routine names of a
+// host's schema for words 8.0 newly reserves. Each one blocks with the
+// object to rename, or to backtick-quote in every statement that names it,
+// before the upgraded replica serves traffic.
+type ReservedWordCheck struct {
+	Inspector SchemaInspector
+	Host      string
+}
+
+func (c *ReservedWordCheck) Name() string   { return "reserved_words_80" }
+func (c *ReservedWordCheck) ReadOnly() bool { return true }
+
+func (c *ReservedWordCheck) Parameters() map[string]interface{} {
+	return map[string]interface{}{"host": c.Host}
+}
+
+func (c *ReservedWordCheck) Run(ctx context.Context, input Input) ([]Finding, error) {
+	if c.Inspector == nil {
+		return nil, fmt.Errorf("schema inspector is required")
+	}
+	if strings.TrimSpace(c.Host) == "" {
+		return nil, fmt.Errorf("host is required")
+	}
+	schema, err := c.Inspector.Schema(ctx, c.Host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema on %s: %w", c.Host, err)
+	}
+
+	findings := []Finding{}
+	conflict := func(object string, label string, name string, meta map[string]interface{}) {
+		word := strings.ToUpper(name)
+		if i := strings.LastIndex(word, "."); i >= 0 {
+			word = word[i+1:]
+		}
+		meta[MetaHost] = c.Host
+		meta["object"] = object
+		meta["word"] = word
+		findings = append(findings, Finding{
+			Severity: SeverityBlock,
+			Code:     CodeCompatReservedWord,
+			Message:  fmt.Sprintf("%s %s is named %s, a reserved word in 8.0; rename it or backtick-quote it in every query, view and routine that names it", object, label, word),
+			Meta:     meta,
+		})
+	}
+	for _, table := range schema.Tables {
+		if ReservedIn80(table.Name) {
+			conflict("table", fmt.Sprintf("%q", table.Name), table.Name, map[string]interface{}{MetaTable: table.Name})
+		}
+		for _, col := range table.Columns {
+			if ReservedIn80(col.Name) {
+				conflict("column", fmt.Sprintf("%q.%q", table.Name, col.Name), col.Name, map[string]interface{}{MetaTable: table.Name, MetaColumn: col.Name})
+			}
+		}
+	}
+	for _, view := range schema.Views {
+		if ReservedIn80(view.Name) {
+			conflict("view", fmt.Sprintf("%q", view.Name), view.Name, map[string]interface{}{MetaView: view.Name})
+		}
+	}
+	for _, routine := range schema.Routines {
+		if ReservedIn80(routine.Name) {
+			kind := strings.ToLower(routineType(routine))
+			conflict(kind, fmt.Sprintf("%q", routine.Name), routine.Name, map[string]interface{}{MetaRoutine: routine.Name, "type": routineType(routine)})
+		}
+	}
+	if len(findings) > 0 {
+		return findings, nil
+	}
+	return []Finding{{
+		Severity: SeverityInfo,
+		Code:     CodeCompatReservedWordsOK,
+		Message:  fmt.Sprintf("no table, column, view or routine on %q is named with a word 8.0 reserves", c.Host),
+		Meta:     map[string]interface{}{MetaHost: c.Host, MetaTableCount: len(schema.Tables)},
+	}}, nil
+}
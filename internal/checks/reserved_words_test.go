@@ -0,0 +1,54 @@
+package checks
+
+import (
+	"context"
+	"testing"
+)
+
+func TestReservedWordCheck_BlocksEachConflictingObject(t *testing.T) {
+	inspector := &fakeSchemaInspector{primary: Schema{
+		Tables: []Table{
+			{Name: "shop.orders", Columns: []Column{{Name: "id"}, {Name: "rank"}, {Name: "Groups"}}},
+			{Name: "shop.system", Columns: []Column{{Name: "id"}, {Name: "ranking"}}},
+		},
+		Views:    []View{{Name: "window"}, {Name: "order_totals"}},
+		Routines: []Routine{{Name: "lead", Type: "FUNCTION"}, {Name: "refresh_totals"}},
+	}}
+	check := &ReservedWordCheck{Inspector: inspector, Host: "primary"}
+	findings, err := check.Run(context.Background(), Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []struct{ object, word string }{
+		{"column", "RANK"}, {"column", "GROUPS"}, {"table", "SYSTEM"}, {"view", "WINDOW"}, {"function", "LEAD"},
+	}
+	if len(findings) != len(want) {
+		t.Fatalf("expected %d conflicts, got %+v", len(want), findings)
+	}
+	for i, w := range want {
+		f := findings[i]
+		if f.Severity != SeverityBlock || f.Code != CodeCompatReservedWord || f.Meta["object"] != w.object || f.Meta["word"] != w.word {
+			t.Fatalf("finding %d: expected %s %s, got %+v", i, w.object, w.word, f)
+		}
+		if problems := ValidateMeta(f.Code, f.Meta); len(problems) > 0 {
+			t.Fatalf("invalid meta: %v", problems)
+		}
+	}
+	if findings[0].Meta[MetaTable] != "shop.orders" || findings[0].Meta[MetaColumn] != "rank" {
+		t.Fatalf("expected the column conflict to name its table, got %+v", findings[0].Meta)
+	}
+	if findings[4].Meta[MetaRoutine] != "lead" || findings[4].Meta["type"] != "FUNCTION" {
+		t.Fatalf("expected the routine conflict to name the function, got %+v", findings[4].Meta)
+	}
+}
+
+func TestReservedWordCheck_CleanSchema(t *testing.T) {
+	inspector := &fakeSchemaInspector{primary: Schema{Tables: []Table{{Name: "orders", Columns: []Column{{Name: "id"}, {Name: "status"}}}}}}
+	findings, err := (&ReservedWordCheck{Inspector: inspector, Host: "primary"}).Run(context.Background(), Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Code != CodeCompatReservedWordsOK || findings[0].Meta[MetaTableCount] != 1 {
+		t.Fatalf("expected a clean result, got %+v", findings)
+	}
+}
@@ -0,0 +1,85 @@
+package checks
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Event describes a scheduled event in a schema snapshot. Status is ENABLED,
+// DISABLED or SLAVESIDE_DISABLED, which MySQL sets on events a replica
+// received through replication. Schedule is "EVERY <n> <unit>" or
+// "AT <time>"; Body is the event definition.
+type Event struct {
+	Name     string
+	Status   string
+	Schedule string
+	Definer  string
+	Body     string
+}
+
+// Enabled reports whether the event fires on the host it was read from.
+func (e Event) Enabled() bool { return strings.EqualFold(e.Status, "ENABLED") }
+
+// compareEvents reports event drift by name and events enabled on the
+// replica. An enabled replica event runs alongside the primary's copy, and
+// after promotion alongside whatever re-enables it on the new primary, so
+// its work is done twice; a missing event stops running at promotion. Both
+// warn, as does a different schedule, definer or body, or an extra event.
+func compareEvents(primary Schema, replica Schema) []Finding {
+	findings := []Finding{}
+	replicaEvents := make(map[string]Event, len(replica.Events))
+	for _, e := range replica.Events {
+		replicaEvents[e.Name] = e
+	}
+	primaryEvents := make(map[string]struct{}, len(primary.Events))
+	for _, p := range primary.Events {
+		primaryEvents[p.Name] = struct{}{}
+		r, ok := replicaEvents[p.Name]
+		if !ok {
+			findings = append(findings, Finding{
+				Severity: SeverityWarn,
+				Code:     CodeSchemaEventMissing,
+				Message:  fmt.Sprintf("event %q missing on replica; it will not run after promotion", p.Name),
+				Meta:     map[string]interface{}{"event": p.Name, "schedule": p.Schedule},
+			})
+			continue
+		}
+		differences := []string{}
+		if !strings.EqualFold(strings.TrimSpace(p.Schedule), strings.TrimSpace(r.Schedule)) {
+			differences = append(differences, "schedule")
+		}
+		if !strings.EqualFold(p.Definer, r.Definer) {
+			differences = append(differences, "definer")
+		}
+		if normalizeRoutineBody(p.Body) != normalizeRoutineBody(r.Body) {
+			differences = append(differences, "body")
+		}
+		if len(differences) > 0 {
+			findings = append(findings, Finding{
+				Severity: SeverityWarn,
+				Code:     CodeSchemaEventMismatch,
+				Message:  fmt.Sprintf("event %q differs (%s)", p.Name, strings.Join(differences, ", ")),
+				Meta:     map[string]interface{}{"event": p.Name, "differences": differences, "primary_definition": p.Schedule, "replica_definition": r.Schedule},
+			})
+		}
+	}
+	for _, r := range replica.Events {
+		if _, ok := primaryEvents[r.Name]; !ok {
+			findings = append(findings, Finding{
+				Severity: SeverityWarn,
+				Code:     CodeSchemaEventExtra,
+				Message:  fmt.Sprintf("extra event %q exists on replica", r.Name),
+				Meta:     map[string]interface{}{"event": r.Name, "status": r.Status},
+			})
+		}
+		if r.Enabled() {
+			findings = append(findings, Finding{
+				Severity: SeverityWarn,
+				Code:     CodeSchemaEventEnabled,
+				Message:  fmt.Sprintf("event %q is enabled on replica; its work runs twice while the primary also runs it, and again after promotion", r.Name),
+				Meta:     map[string]interface{}{"event": r.Name, "status": r.Status, "schedule": r.Schedule},
+			})
+		}
+	}
+	return findings
+}
@@ -0,0 +1,40 @@
+package checks
+
+import "testing"
+
+func TestCompareEvents_WarnsOnEnabledReplicaEvents(t *testing.T) {
+	event := Event{Name: "purge_carts", Status: "ENABLED", Schedule: "EVERY 1 HOUR", Definer: "app@%", Body: "DELETE FROM `carts` WHERE stale = 1"}
+	replicated := event
+	replicated.Status = "SLAVESIDE_DISABLED"
+	replicated.Body = "DELETE FROM carts\nWHERE stale = 1"
+	if findings := compareEvents(Schema{Events: []Event{event}}, Schema{Events: []Event{replicated}}); len(findings) != 0 {
+		t.Fatalf("expected a replicated, disabled event to match, got %+v", findings)
+	}
+
+	findings := compareEvents(Schema{Events: []Event{event}}, Schema{Events: []Event{event}})
+	if len(findings) != 1 || findings[0].Code != CodeSchemaEventEnabled || findings[0].Severity != SeverityWarn || findings[0].Meta["event"] != "purge_carts" {
+		t.Fatalf("expected an enabled-on-replica warning, got %+v", findings)
+	}
+}
+
+func TestCompareEvents_ReportsDrift(t *testing.T) {
+	purge := Event{Name: "purge_carts", Status: "ENABLED", Schedule: "EVERY 1 HOUR"}
+	rollup := Event{Name: "rollup", Status: "ENABLED", Schedule: "EVERY 1 DAY"}
+	slower := Event{Name: "rollup", Status: "DISABLED", Schedule: "EVERY 2 DAY"}
+	extra := Event{Name: "debug", Status: "DISABLED", Schedule: "AT 2026-01-01 00:00:00"}
+	findings := compareEvents(Schema{Events: []Event{purge, rollup}}, Schema{Events: []Event{slower, extra}})
+	want := []string{CodeSchemaEventMissing, CodeSchemaEventMismatch, CodeSchemaEventExtra}
+	if len(findings) != len(want) {
+		t.Fatalf("expected %d findings, got %+v", len(want), findings)
+	}
+	for i, code := range want {
+		if findings[i].Code != code || findings[i].Severity != SeverityWarn {
+			t.Fatalf("finding %d: expected WARN %s, got %+v", i, code, findings[i])
+		}
+	}
+	for _, f := range findings {
+		if problems := ValidateMeta(f.Code, f.Meta); len(problems) != 0 {
+			t.Fatalf("%s: %v", f.Code, problems)
+		}
+	}
+}
@@ -2,29 +2,90 @@ package checks
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 )
 
-// Column describes a table column in a schema snapshot.
+// Column describes a table column in a schema snapshot. SRID is the 8.0
+// SRID attribute of a geometry column, nil when unrestricted. Generated is
+// VIRTUAL or STORED for a generated column, whose Expression is the
+// generation expression as information_schema.COLUMNS reports it.
 type Column struct {
-	Name      string
-	Type      string
-	Nullable  bool
-	Default   *string
-	Charset   string
-	Collation string
+	Name       string
+	Type       string
+	Nullable   bool
+	Default    *string
+	Charset    string
+	Collation  string
+	SRID       *uint32 `json:",omitempty"`
+	Generated  string  `json:",omitempty"`
+	Expression string  `json:",omitempty"`
+}
+
+// Index describes a secondary or primary index. Type is BTREE, HASH,
+// FULLTEXT or SPATIAL as reported by information_schema.STATISTICS. Prefixes,
+// when set, holds the indexed prefix length in characters of each column (0
+// for the whole column), as STATISTICS.SUB_PART reports it.
+type Index struct {
+	Name     string
+	Type     string
+	Unique   bool
+	Columns  []string
+	Prefixes []int `json:",omitempty"`
+}
+
+// ForeignKey describes a foreign key constraint. ReferencedTable is named the
+// way the snapshot names tables. OnDelete and OnUpdate are the referential
+// actions (CASCADE, SET NULL, RESTRICT, NO ACTION); empty means NO ACTION.
+type ForeignKey struct {
+	Name              string
+	Columns           []string
+	ReferencedTable   string
+	ReferencedColumns []string
+	OnDelete          string `json:",omitempty"`
+	OnUpdate          string `json:",omitempty"`
 }
 
-// Table describes a table in a schema snapshot.
+// Table describes a table in a schema snapshot. Fields added after the first
+// release are omitted from JSON when unset so older snapshots keep their
+// fingerprint.
 type Table struct {
-	Name       string
-	Columns    []Column
-	PrimaryKey []string
+	Name         string
+	Columns      []Column
+	PrimaryKey   []string
+	Engine       string        `json:",omitempty"`
+	Partitioning *Partitioning `json:",omitempty"`
+	Indexes      []Index       `json:",omitempty"`
+	ForeignKeys  []ForeignKey  `json:",omitempty"`
+}
+
+// Partitioning describes a partitioned table's scheme, as reported by
+// information_schema.PARTITIONS.
+type Partitioning struct {
+	Method                 string
+	Expression             string
+	SubpartitionMethod     string `json:",omitempty"`
+	SubpartitionExpression string `json:",omitempty"`
+	Partitions             []Partition
+}
+
+// Partition is one partition definition. Description is the VALUES LESS
+// THAN / VALUES IN bound; it is empty for HASH and KEY partitioning.
+type Partition struct {
+	Name        string
+	Description string `json:",omitempty"`
 }
 
 // Schema describes a database schema snapshot.
 type Schema struct {
-	Tables []Table
+	Tables   []Table
+	Views    []View    `json:",omitempty"`
+	Routines []Routine `json:",omitempty"`
+	Events   []Event   `json:",omitempty"`
 }
 
 // SchemaInspector provides read-only schema access for parity checks.
@@ -42,24 +103,53 @@ type SchemaParityCheck struct {
 func (c *SchemaParityCheck) Name() string   { return "schema_parity" }
 func (c *SchemaParityCheck) ReadOnly() bool { return true }
 
+func (c *SchemaParityCheck) Parameters() map[string]interface{} {
+	return map[string]interface{}{"primary_host": c.PrimaryHost, "replica_host": c.ReplicaHost}
+}
+
 func (c *SchemaParityCheck) Run(ctx context.Context, input Input) ([]Finding, error) {
+	findings, _, err := c.RunWithArtifacts(ctx, input)
+	return findings, err
+}
+
+// RunWithArtifacts also returns both schema dumps, so a parity finding can be
+// re-examined after the servers have moved on.
+func (c *SchemaParityCheck) RunWithArtifacts(ctx context.Context, input Input) ([]Finding, []Artifact, error) {
 	if c.Inspector == nil {
-		return nil, fmt.Errorf("schema inspector is required")
+		return nil, nil, fmt.Errorf("schema inspector is required")
 	}
 	if c.PrimaryHost == "" || c.ReplicaHost == "" {
-		return nil, fmt.Errorf("primary and replica hosts are required")
+		return nil, nil, fmt.Errorf("primary and replica hosts are required")
 	}
 
 	primary, err := c.Inspector.Schema(ctx, c.PrimaryHost)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read primary schema: %v", err)
+		return nil, nil, fmt.Errorf("failed to read primary schema: %v", err)
 	}
 	replica, err := c.Inspector.Schema(ctx, c.ReplicaHost)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read replica schema: %v", err)
+		return nil, nil, fmt.Errorf("failed to read replica schema: %v", err)
+	}
+	artifacts := []Artifact{}
+	for _, dump := range []struct {
+		name   string
+		schema Schema
+	}{{"primary-schema.json", primary}, {"replica-schema.json", replica}} {
+		if data, err := json.MarshalIndent(dump.schema, "", "  "); err == nil {
+			artifacts = append(artifacts, Artifact{Name: dump.name, ContentType: "application/json", Data: data})
+		}
 	}
 
-	return compareSchemas(primary, replica), nil
+	findings := compareSchemas(primary, replica)
+	if len(findings) == 0 {
+		findings = append(findings, Finding{
+			Severity: SeverityInfo,
+			Code:     CodeSchemaParityOK,
+			Message:  fmt.Sprintf("schema parity verified for %d tables", len(primary.Tables)),
+			Meta:     map[string]interface{}{"primary": c.PrimaryHost, "replica": c.ReplicaHost},
+		})
+	}
+	return findings, artifacts, nil
 }
 
 func compareSchemas(primary Schema, replica Schema) []Finding {
@@ -72,6 +162,7 @@ func compareSchemas(primary Schema, replica Schema) []Finding {
 		if !ok {
 			findings = append(findings, Finding{
 				Severity: SeverityBlock,
+				Code:     CodeSchemaTableMissing,
 				Message:  fmt.Sprintf("table %q missing on replica", name),
 				Meta:     map[string]interface{}{"table": name},
 			})
@@ -80,18 +171,36 @@ func compareSchemas(primary Schema, replica Schema) []Finding {
 
 		findings = append(findings, comparePrimaryKey(name, pTable.PrimaryKey, rTable.PrimaryKey)...)
 		findings = append(findings, compareColumns(name, pTable.Columns, rTable.Columns)...)
+		findings = append(findings, compareIndexes(name, pTable.Indexes, rTable.Indexes)...)
+		findings = append(findings, compareForeignKeys(name, pTable.ForeignKeys, rTable.ForeignKeys)...)
+		findings = append(findings, comparePartitioning(name, pTable.Partitioning, rTable.Partitioning)...)
 	}
 
-	for name := range replicaTables {
+	for name, rTable := range replicaTables {
 		if _, ok := primaryTables[name]; !ok {
 			findings = append(findings, Finding{
 				Severity: SeverityWarn,
+				Code:     CodeSchemaTableExtra,
 				Message:  fmt.Sprintf("extra table %q exists on replica", name),
 				Meta:     map[string]interface{}{"table": name},
 			})
 		}
+		// The replica is upgraded first, so a generic-handler partitioned
+		// table there stops 8.0 from starting even if the primary's copy is
+		// native (the compatibility check covers the primary).
+		if nonNativePartitioning(rTable) {
+			findings = append(findings, Finding{
+				Severity: SeverityBlock,
+				Code:     CodeCompatPartitionEngine,
+				Message:  fmt.Sprintf("table %q is partitioned on engine %q on replica; 8.0 only supports native partitioning in InnoDB and NDB and will not start", name, rTable.Engine),
+				Meta:     map[string]interface{}{"table": name, "engine": rTable.Engine, "method": rTable.Partitioning.Method, "host": "replica"},
+			})
+		}
 	}
 
+	findings = append(findings, compareViews(primary, replica)...)
+	findings = append(findings, compareRoutines(primary, replica)...)
+	findings = append(findings, compareEvents(primary, replica)...)
 	return findings
 }
 
@@ -102,6 +211,7 @@ func comparePrimaryKey(table string, primaryPK []string, replicaPK []string) []F
 	if len(primaryPK) == 0 && len(replicaPK) > 0 {
 		return []Finding{{
 			Severity: SeverityWarn,
+			Code:     CodeSchemaPKExtra,
 			Message:  fmt.Sprintf("table %q has primary key on replica but not on primary", table),
 			Meta:     map[string]interface{}{"table": table},
 		}}
@@ -109,6 +219,7 @@ func comparePrimaryKey(table string, primaryPK []string, replicaPK []string) []F
 	if len(primaryPK) > 0 && len(replicaPK) == 0 {
 		return []Finding{{
 			Severity: SeverityBlock,
+			Code:     CodeSchemaPKMissing,
 			Message:  fmt.Sprintf("table %q missing primary key on replica", table),
 			Meta:     map[string]interface{}{"table": table},
 		}}
@@ -116,6 +227,7 @@ func comparePrimaryKey(table string, primaryPK []string, replicaPK []string) []F
 	if !equalStrings(primaryPK, replicaPK) {
 		return []Finding{{
 			Severity: SeverityBlock,
+			Code:     CodeSchemaPKMismatch,
 			Message:  fmt.Sprintf("table %q primary key mismatch", table),
 			Meta:     map[string]interface{}{"table": table, "primary_pk": primaryPK, "replica_pk": replicaPK},
 		}}
@@ -133,6 +245,7 @@ func compareColumns(table string, primaryCols []Column, replicaCols []Column) []
 		if !ok {
 			findings = append(findings, Finding{
 				Severity: SeverityBlock,
+				Code:     CodeSchemaColumnMissing,
 				Message:  fmt.Sprintf("table %q column %q missing on replica", table, name),
 				Meta:     map[string]interface{}{"table": table, "column": name},
 			})
@@ -142,6 +255,7 @@ func compareColumns(table string, primaryCols []Column, replicaCols []Column) []
 		if pCol.Type != rCol.Type {
 			findings = append(findings, Finding{
 				Severity: SeverityBlock,
+				Code:     CodeSchemaColumnType,
 				Message:  fmt.Sprintf("table %q column %q type mismatch", table, name),
 				Meta:     map[string]interface{}{"table": table, "column": name, "primary_type": pCol.Type, "replica_type": rCol.Type},
 			})
@@ -149,6 +263,7 @@ func compareColumns(table string, primaryCols []Column, replicaCols []Column) []
 		if pCol.Nullable != rCol.Nullable {
 			findings = append(findings, Finding{
 				Severity: SeverityWarn,
+				Code:     CodeSchemaColumnNullable,
 				Message:  fmt.Sprintf("table %q column %q nullability differs", table, name),
 				Meta:     map[string]interface{}{"table": table, "column": name, "primary_nullable": pCol.Nullable, "replica_nullable": rCol.Nullable},
 			})
@@ -156,6 +271,7 @@ func compareColumns(table string, primaryCols []Column, replicaCols []Column) []
 		if !equalDefaults(pCol.Default, rCol.Default) {
 			findings = append(findings, Finding{
 				Severity: SeverityWarn,
+				Code:     CodeSchemaColumnDefault,
 				Message:  fmt.Sprintf("table %q column %q default differs", table, name),
 				Meta:     map[string]interface{}{"table": table, "column": name, "primary_default": pCol.Default, "replica_default": rCol.Default},
 			})
@@ -163,16 +279,19 @@ func compareColumns(table string, primaryCols []Column, replicaCols []Column) []
 		if pCol.Collation != rCol.Collation {
 			findings = append(findings, Finding{
 				Severity: SeverityWarn,
+				Code:     CodeSchemaColumnCollation,
 				Message:  fmt.Sprintf("table %q column %q collation differs", table, name),
 				Meta:     map[string]interface{}{"table": table, "column": name, "primary_collation": pCol.Collation, "replica_collation": rCol.Collation},
 			})
 		}
+		findings = append(findings, compareGenerated(table, pCol, rCol)...)
 	}
 
 	for name := range replicaIndex {
 		if _, ok := primaryIndex[name]; !ok {
 			findings = append(findings, Finding{
 				Severity: SeverityWarn,
+				Code:     CodeSchemaColumnExtra,
 				Message:  fmt.Sprintf("table %q has extra column %q on replica", table, name),
 				Meta:     map[string]interface{}{"table": table, "column": name},
 			})
@@ -182,6 +301,299 @@ func compareColumns(table string, primaryCols []Column, replicaCols []Column) []
 	return findings
 }
 
+// compareGenerated blocks when a column is generated on only one side, is
+// VIRTUAL on one and STORED on the other, or computes a different
+// expression: the column's values then differ between primary and replica,
+// and 8.0 evaluates some expressions differently from 5.7 (for example
+// string functions under its new default collation).
+func compareGenerated(table string, pCol Column, rCol Column) []Finding {
+	pKind, rKind := strings.ToUpper(pCol.Generated), strings.ToUpper(rCol.Generated)
+	pExpr, rExpr := NormalizeGenerationExpression(pCol.Expression), NormalizeGenerationExpression(rCol.Expression)
+	if pKind == rKind && pExpr == rExpr {
+		return nil
+	}
+	return []Finding{{
+		Severity: SeverityBlock,
+		Code:     CodeSchemaColumnGenerated,
+		Message:  fmt.Sprintf("table %q column %q generated column definition differs (primary %s, replica %s)", table, pCol.Name, describeGenerated(pKind, pExpr), describeGenerated(rKind, rExpr)),
+		Meta: map[string]interface{}{
+			"table": table, "column": pCol.Name,
+			"primary_generated": pKind, "replica_generated": rKind,
+			"primary_expression": pExpr, "replica_expression": rExpr,
+		},
+	}}
+}
+
+func describeGenerated(kind string, expression string) string {
+	if kind == "" {
+		return "not generated"
+	}
+	return fmt.Sprintf("%s AS (%s)", kind, expression)
+}
+
+// NormalizeGenerationExpression returns a generation expression in a form
+// that compares equal across 5.7 and 8.0, as NormalizeViewDefinition does for
+// view bodies, without parentheses enclosing the whole expression.
+func NormalizeGenerationExpression(expression string) string {
+	expr := NormalizeViewDefinition(expression)
+	for strings.HasPrefix(expr, "(") && strings.HasSuffix(expr, ")") && enclosed(expr) {
+		expr = strings.TrimSpace(expr[1 : len(expr)-1])
+	}
+	return expr
+}
+
+// enclosed reports whether expr's first parenthesis closes at its end.
+func enclosed(expr string) bool {
+	depth := 0
+	for i, c := range expr {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i == len(expr)-1
+			}
+		}
+	}
+	return false
+}
+
+// compareIndexes reports secondary index drift by index name. A unique index
+// missing on the replica blocks: after promotion it would accept duplicates
+// the primary rejected. Other differences slow queries down without breaking
+// them and warn, unless they change whether an index is unique. Type is only
+// compared when both snapshots report it.
+func compareIndexes(table string, primaryIdx []Index, replicaIdx []Index) []Finding {
+	findings := []Finding{}
+	replicaByName := make(map[string]Index, len(replicaIdx))
+	for _, idx := range replicaIdx {
+		replicaByName[idx.Name] = idx
+	}
+	primaryByName := make(map[string]struct{}, len(primaryIdx))
+	for _, pIdx := range primaryIdx {
+		primaryByName[pIdx.Name] = struct{}{}
+		rIdx, ok := replicaByName[pIdx.Name]
+		if !ok {
+			severity := SeverityWarn
+			if pIdx.Unique {
+				severity = SeverityBlock
+			}
+			findings = append(findings, Finding{
+				Severity: severity,
+				Code:     CodeSchemaIndexMissing,
+				Message:  fmt.Sprintf("table %q %s %q missing on replica", table, indexKind(pIdx), pIdx.Name),
+				Meta:     map[string]interface{}{"table": table, "index": pIdx.Name, "unique": pIdx.Unique, "columns": pIdx.Columns},
+			})
+			continue
+		}
+
+		differences := []string{}
+		if !equalStrings(pIdx.Columns, rIdx.Columns) {
+			differences = append(differences, "columns")
+		}
+		if pIdx.Unique != rIdx.Unique {
+			differences = append(differences, "uniqueness")
+		}
+		if pIdx.Type != "" && rIdx.Type != "" && !strings.EqualFold(pIdx.Type, rIdx.Type) {
+			differences = append(differences, "type")
+		}
+		if len(differences) == 0 {
+			continue
+		}
+		severity := SeverityWarn
+		if pIdx.Unique || rIdx.Unique {
+			severity = SeverityBlock
+		}
+		findings = append(findings, Finding{
+			Severity: severity,
+			Code:     CodeSchemaIndexMismatch,
+			Message:  fmt.Sprintf("table %q index %q differs (%s)", table, pIdx.Name, strings.Join(differences, ", ")),
+			Meta: map[string]interface{}{
+				"table": table, "index": pIdx.Name, "differences": differences,
+				"primary_columns": pIdx.Columns, "replica_columns": rIdx.Columns,
+				"primary_unique": pIdx.Unique, "replica_unique": rIdx.Unique,
+			},
+		})
+	}
+
+	for _, rIdx := range replicaIdx {
+		if _, ok := primaryByName[rIdx.Name]; !ok {
+			findings = append(findings, Finding{
+				Severity: SeverityWarn,
+				Code:     CodeSchemaIndexExtra,
+				Message:  fmt.Sprintf("table %q has extra %s %q on replica", table, indexKind(rIdx), rIdx.Name),
+				Meta:     map[string]interface{}{"table": table, "index": rIdx.Name, "unique": rIdx.Unique, "columns": rIdx.Columns},
+			})
+		}
+	}
+	return findings
+}
+
+// compareForeignKeys reports foreign key drift by constraint name. Every
+// difference blocks: a constraint the primary does not have rejects or
+// cascades row events differently on the replica, which breaks replication
+// after cutover, and a missing one stops enforcing integrity.
+func compareForeignKeys(table string, primaryFKs []ForeignKey, replicaFKs []ForeignKey) []Finding {
+	findings := []Finding{}
+	replicaByName := make(map[string]ForeignKey, len(replicaFKs))
+	for _, fk := range replicaFKs {
+		replicaByName[fk.Name] = fk
+	}
+	primaryByName := make(map[string]struct{}, len(primaryFKs))
+	for _, pFK := range primaryFKs {
+		primaryByName[pFK.Name] = struct{}{}
+		rFK, ok := replicaByName[pFK.Name]
+		if !ok {
+			findings = append(findings, Finding{
+				Severity: SeverityBlock,
+				Code:     CodeSchemaFKMissing,
+				Message:  fmt.Sprintf("table %q foreign key %q missing on replica", table, pFK.Name),
+				Meta:     map[string]interface{}{"table": table, "foreign_key": pFK.Name, "references": pFK.ReferencedTable},
+			})
+			continue
+		}
+
+		differences := []string{}
+		if !equalStrings(pFK.Columns, rFK.Columns) {
+			differences = append(differences, "columns")
+		}
+		if pFK.ReferencedTable != rFK.ReferencedTable || !equalStrings(pFK.ReferencedColumns, rFK.ReferencedColumns) {
+			differences = append(differences, "references")
+		}
+		if referentialAction(pFK.OnDelete) != referentialAction(rFK.OnDelete) {
+			differences = append(differences, "on delete")
+		}
+		if referentialAction(pFK.OnUpdate) != referentialAction(rFK.OnUpdate) {
+			differences = append(differences, "on update")
+		}
+		if len(differences) == 0 {
+			continue
+		}
+		findings = append(findings, Finding{
+			Severity: SeverityBlock,
+			Code:     CodeSchemaFKMismatch,
+			Message:  fmt.Sprintf("table %q foreign key %q differs (%s)", table, pFK.Name, strings.Join(differences, ", ")),
+			Meta: map[string]interface{}{
+				"table": table, "foreign_key": pFK.Name, "differences": differences,
+				"primary_definition": describeForeignKey(pFK), "replica_definition": describeForeignKey(rFK),
+			},
+		})
+	}
+
+	for _, rFK := range replicaFKs {
+		if _, ok := primaryByName[rFK.Name]; !ok {
+			findings = append(findings, Finding{
+				Severity: SeverityBlock,
+				Code:     CodeSchemaFKExtra,
+				Message:  fmt.Sprintf("table %q has extra foreign key %q on replica", table, rFK.Name),
+				Meta:     map[string]interface{}{"table": table, "foreign_key": rFK.Name, "references": rFK.ReferencedTable},
+			})
+		}
+	}
+	return findings
+}
+
+// referentialAction normalizes an ON DELETE/ON UPDATE rule. InnoDB treats
+// RESTRICT and NO ACTION alike, and an omitted rule means NO ACTION.
+func referentialAction(rule string) string {
+	rule = strings.ToUpper(strings.TrimSpace(rule))
+	if rule == "" || rule == "RESTRICT" {
+		return "NO ACTION"
+	}
+	return rule
+}
+
+// describeForeignKey renders fk as its constraint clause, e.g.
+// "(user_id) REFERENCES users (id) ON DELETE CASCADE ON UPDATE NO ACTION".
+func describeForeignKey(fk ForeignKey) string {
+	return fmt.Sprintf("(%s) REFERENCES %s (%s) ON DELETE %s ON UPDATE %s",
+		strings.Join(fk.Columns, ", "), fk.ReferencedTable, strings.Join(fk.ReferencedColumns, ", "),
+		referentialAction(fk.OnDelete), referentialAction(fk.OnUpdate))
+}
+
+func indexKind(idx Index) string {
+	if idx.Unique {
+		return "unique index"
+	}
+	return "index"
+}
+
+// comparePartitioning reports partition-definition drift. Replication applies
+// row events by partition-independent table names, but a partition scheme that
+// differs between primary and replica changes pruning, maintenance (DROP
+// PARTITION) and data placement after promotion.
+func comparePartitioning(table string, primary *Partitioning, replica *Partitioning) []Finding {
+	if primary == nil && replica == nil {
+		return nil
+	}
+	meta := map[string]interface{}{"table": table}
+	if primary == nil || replica == nil {
+		meta["primary_partitioned"] = primary != nil
+		meta["replica_partitioned"] = replica != nil
+		return []Finding{{
+			Severity: SeverityBlock,
+			Code:     CodeSchemaPartitionMismatch,
+			Message:  fmt.Sprintf("table %q is partitioned on only one of primary and replica", table),
+			Meta:     meta,
+		}}
+	}
+
+	differences := []string{}
+	if !strings.EqualFold(primary.Method, replica.Method) || primary.Expression != replica.Expression {
+		differences = append(differences, "method")
+		meta["primary_method"] = strings.TrimSpace(primary.Method + " " + primary.Expression)
+		meta["replica_method"] = strings.TrimSpace(replica.Method + " " + replica.Expression)
+	}
+	if !strings.EqualFold(primary.SubpartitionMethod, replica.SubpartitionMethod) || primary.SubpartitionExpression != replica.SubpartitionExpression {
+		differences = append(differences, "subpartitioning")
+		meta["primary_subpartitioning"] = strings.TrimSpace(primary.SubpartitionMethod + " " + primary.SubpartitionExpression)
+		meta["replica_subpartitioning"] = strings.TrimSpace(replica.SubpartitionMethod + " " + replica.SubpartitionExpression)
+	}
+	missing, extra, changed := diffPartitions(primary.Partitions, replica.Partitions)
+	if len(missing)+len(extra)+len(changed) > 0 {
+		differences = append(differences, "partitions")
+		meta["missing"] = missing
+		meta["extra"] = extra
+		meta["changed"] = changed
+	}
+	if len(differences) == 0 {
+		return nil
+	}
+	meta["differences"] = differences
+	return []Finding{{
+		Severity: SeverityBlock,
+		Code:     CodeSchemaPartitionMismatch,
+		Message:  fmt.Sprintf("table %q partition definitions differ (%s)", table, strings.Join(differences, ", ")),
+		Meta:     meta,
+	}}
+}
+
+func diffPartitions(primary []Partition, replica []Partition) (missing []string, extra []string, changed []string) {
+	missing, extra, changed = []string{}, []string{}, []string{}
+	replicaIndex := make(map[string]Partition, len(replica))
+	for _, p := range replica {
+		replicaIndex[p.Name] = p
+	}
+	primaryIndex := make(map[string]struct{}, len(primary))
+	for _, p := range primary {
+		primaryIndex[p.Name] = struct{}{}
+		r, ok := replicaIndex[p.Name]
+		switch {
+		case !ok:
+			missing = append(missing, p.Name)
+		case r.Description != p.Description:
+			changed = append(changed, p.Name)
+		}
+	}
+	for _, r := range replica {
+		if _, ok := primaryIndex[r.Name]; !ok {
+			extra = append(extra, r.Name)
+		}
+	}
+	return missing, extra, changed
+}
+
 func tableIndex(tables []Table) map[string]Table {
 	idx := make(map[string]Table, len(tables))
 	for _, t := range tables {
@@ -219,3 +631,13 @@ func equalDefaults(a *string, b *string) bool {
 	}
 	return *a == *b
 }
+
+// SchemaFingerprint returns a stable hash of a schema snapshot. Table order
+// does not affect the result; column order does, since it is part of the DDL.
+func SchemaFingerprint(schema Schema) string {
+	tables := append([]Table{}, schema.Tables...)
+	sort.Slice(tables, func(i, j int) bool { return tables[i].Name < tables[j].Name })
+	b, _ := json.Marshal(tables)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
@@ -2,6 +2,8 @@ package checks
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"testing"
 )
 
@@ -103,6 +105,164 @@ func TestSchemaParity_ExtraReplicaColumnWarns(t *testing.T) {
 	}
 }
 
+func TestSchemaParity_PartitionDriftBlocks(t *testing.T) {
+	partitioned := func(bounds ...string) *Partitioning {
+		p := &Partitioning{Method: "RANGE", Expression: "year(created_at)"}
+		for i, b := range bounds {
+			p.Partitions = append(p.Partitions, Partition{Name: fmt.Sprintf("p%d", i), Description: b})
+		}
+		return p
+	}
+	inspector := &fakeSchemaInspector{
+		primary: Schema{Tables: []Table{{Name: "events", PrimaryKey: []string{"id"}, Partitioning: partitioned("2024", "2025", "2026")}}},
+		replica: Schema{Tables: []Table{{Name: "events", PrimaryKey: []string{"id"}, Partitioning: partitioned("2024", "2026")}}},
+	}
+
+	check := &SchemaParityCheck{Inspector: inspector, PrimaryHost: "primary", ReplicaHost: "replica"}
+	findings, err := check.Run(context.Background(), Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Code != CodeSchemaPartitionMismatch {
+		t.Fatalf("expected partition mismatch, got %+v", findings)
+	}
+	if missing := findings[0].Meta["missing"].([]string); len(missing) != 1 || missing[0] != "p2" {
+		t.Fatalf("expected p2 missing, got %+v", findings[0].Meta)
+	}
+	if changed := findings[0].Meta["changed"].([]string); len(changed) != 1 || changed[0] != "p1" {
+		t.Fatalf("expected p1 bound changed, got %+v", findings[0].Meta)
+	}
+
+	inspector.replica.Tables[0].Partitioning = nil
+	findings, _ = check.Run(context.Background(), Input{})
+	if len(findings) != 1 || findings[0].Code != CodeSchemaPartitionMismatch || findings[0].Meta["replica_partitioned"] != false {
+		t.Fatalf("expected one-sided partitioning to block, got %+v", findings)
+	}
+}
+
+func TestSchemaParity_BlocksNonNativePartitioningOnReplica(t *testing.T) {
+	partitioning := &Partitioning{Method: "HASH", Expression: "id", Partitions: []Partition{{Name: "p0"}, {Name: "p1"}}}
+	inspector := &fakeSchemaInspector{
+		primary: Schema{Tables: []Table{{Name: "logs", Engine: "InnoDB", PrimaryKey: []string{"id"}, Partitioning: partitioning}}},
+		replica: Schema{Tables: []Table{{Name: "logs", Engine: "MyISAM", PrimaryKey: []string{"id"}, Partitioning: partitioning}}},
+	}
+
+	check := &SchemaParityCheck{Inspector: inspector, PrimaryHost: "primary", ReplicaHost: "replica"}
+	findings, err := check.Run(context.Background(), Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Code != CodeCompatPartitionEngine || findings[0].Severity != SeverityBlock || findings[0].Meta["engine"] != "MyISAM" {
+		t.Fatalf("expected the replica's generic partitioning to block, got %+v", findings)
+	}
+}
+
+func TestCompareColumns_GeneratedColumns(t *testing.T) {
+	primary := []Column{{Name: "paid", Type: "tinyint(1)", Generated: "VIRTUAL", Expression: "(`status` = _utf8'paid')"}}
+	same := []Column{{Name: "paid", Type: "tinyint(1)", Generated: "VIRTUAL", Expression: "`status` = _utf8mb4'paid'"}}
+	if findings := compareColumns("orders", primary, same); len(findings) != 0 {
+		t.Fatalf("expected 5.7 and 8.0 renderings of one expression to match, got %+v", findings)
+	}
+
+	for _, replica := range [][]Column{
+		{{Name: "paid", Type: "tinyint(1)", Generated: "STORED", Expression: "`status` = 'paid'"}},
+		{{Name: "paid", Type: "tinyint(1)", Generated: "VIRTUAL", Expression: "`status` = 'settled'"}},
+		{{Name: "paid", Type: "tinyint(1)"}},
+	} {
+		findings := compareColumns("orders", primary, replica)
+		if len(findings) != 1 || findings[0].Code != CodeSchemaColumnGenerated || findings[0].Severity != SeverityBlock {
+			t.Fatalf("expected a generated column mismatch for %+v, got %+v", replica, findings)
+		}
+	}
+	if got := NormalizeGenerationExpression("((a + 1) * (b + 1))"); got != "(a + 1) * (b + 1)" {
+		t.Fatalf("unexpected normalization %q", got)
+	}
+}
+
+func TestSchemaParity_IndexDrift(t *testing.T) {
+	primary := []Index{
+		{Name: "uk_email", Type: "BTREE", Unique: true, Columns: []string{"email"}},
+		{Name: "idx_created", Type: "BTREE", Columns: []string{"created_at"}},
+		{Name: "idx_status", Type: "BTREE", Columns: []string{"status", "created_at"}},
+		{Name: "uk_handle", Type: "BTREE", Unique: true, Columns: []string{"handle"}},
+	}
+	replica := []Index{
+		{Name: "idx_status", Type: "BTREE", Columns: []string{"status"}},
+		{Name: "uk_handle", Type: "BTREE", Columns: []string{"handle"}},
+		{Name: "idx_tmp", Columns: []string{"name"}},
+	}
+	inspector := &fakeSchemaInspector{
+		primary: Schema{Tables: []Table{{Name: "users", PrimaryKey: []string{"id"}, Indexes: primary}}},
+		replica: Schema{Tables: []Table{{Name: "users", PrimaryKey: []string{"id"}, Indexes: replica}}},
+	}
+
+	check := &SchemaParityCheck{Inspector: inspector, PrimaryHost: "primary", ReplicaHost: "replica"}
+	findings, err := check.Run(context.Background(), Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := map[string]Severity{}
+	for _, f := range findings {
+		got[f.Code+" "+f.Meta["index"].(string)] = f.Severity
+	}
+	want := map[string]Severity{
+		CodeSchemaIndexMissing + " uk_email":     SeverityBlock,
+		CodeSchemaIndexMissing + " idx_created":  SeverityWarn,
+		CodeSchemaIndexMismatch + " idx_status":  SeverityWarn,
+		CodeSchemaIndexMismatch + " uk_handle":   SeverityBlock,
+		CodeSchemaIndexExtra + " idx_tmp":        SeverityWarn,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d index findings, got %+v", len(want), findings)
+	}
+	for key, severity := range want {
+		if got[key] != severity {
+			t.Fatalf("expected %s to be %s, got %+v", key, severity, findings)
+		}
+	}
+}
+
+func TestSchemaParity_ForeignKeyDriftBlocks(t *testing.T) {
+	orderUser := ForeignKey{Name: "fk_orders_user", Columns: []string{"user_id"}, ReferencedTable: "users", ReferencedColumns: []string{"id"}, OnDelete: "CASCADE"}
+	orderStore := ForeignKey{Name: "fk_orders_store", Columns: []string{"store_id"}, ReferencedTable: "stores", ReferencedColumns: []string{"id"}, OnDelete: "RESTRICT"}
+	orderCoupon := ForeignKey{Name: "fk_orders_coupon", Columns: []string{"coupon_id"}, ReferencedTable: "coupons", ReferencedColumns: []string{"id"}}
+
+	replicaUser := orderUser
+	replicaUser.OnDelete = "SET NULL"
+	replicaStore := orderStore
+	replicaStore.OnDelete = "NO ACTION"
+	inspector := &fakeSchemaInspector{
+		primary: Schema{Tables: []Table{{Name: "orders", PrimaryKey: []string{"id"}, ForeignKeys: []ForeignKey{orderUser, orderStore, orderCoupon}}}},
+		replica: Schema{Tables: []Table{{Name: "orders", PrimaryKey: []string{"id"}, ForeignKeys: []ForeignKey{replicaUser, replicaStore, {Name: "fk_tmp", Columns: []string{"x"}, ReferencedTable: "t", ReferencedColumns: []string{"id"}}}}}},
+	}
+
+	check := &SchemaParityCheck{Inspector: inspector, PrimaryHost: "primary", ReplicaHost: "replica"}
+	findings, err := check.Run(context.Background(), Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := map[string]Finding{}
+	for _, f := range findings {
+		if f.Severity != SeverityBlock {
+			t.Fatalf("expected foreign key drift to block, got %+v", f)
+		}
+		got[f.Code+" "+f.Meta["foreign_key"].(string)] = f
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected three foreign key findings (RESTRICT equals NO ACTION), got %+v", findings)
+	}
+	mismatch, ok := got[CodeSchemaFKMismatch+" fk_orders_user"]
+	if !ok || mismatch.Meta["replica_definition"] != "(user_id) REFERENCES users (id) ON DELETE SET NULL ON UPDATE NO ACTION" {
+		t.Fatalf("expected the ON DELETE rule change, got %+v", findings)
+	}
+	if _, ok := got[CodeSchemaFKMissing+" fk_orders_coupon"]; !ok {
+		t.Fatalf("expected the missing foreign key, got %+v", findings)
+	}
+	if _, ok := got[CodeSchemaFKExtra+" fk_tmp"]; !ok {
+		t.Fatalf("expected the extra foreign key, got %+v", findings)
+	}
+}
+
 func hasSeverity(findings []Finding, severity Severity) bool {
 	for _, f := range findings {
 		if f.Severity == severity {
@@ -110,4 +270,26 @@ func hasSeverity(findings []Finding, severity Severity) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
+
+func TestSchemaFingerprint_IgnoresTableOrder(t *testing.T) {
+	a := Schema{Tables: []Table{{Name: "a", Columns: []Column{{Name: "id", Type: "int"}}}, {Name: "b"}}}
+	b := Schema{Tables: []Table{{Name: "b"}, {Name: "a", Columns: []Column{{Name: "id", Type: "int"}}}}}
+	if SchemaFingerprint(a) != SchemaFingerprint(b) {
+		t.Fatalf("expected fingerprint to ignore table order")
+	}
+	b.Tables[1].Columns[0].Type = "bigint"
+	if SchemaFingerprint(a) == SchemaFingerprint(b) {
+		t.Fatalf("expected column type change to alter the fingerprint")
+	}
+}
+
+func TestTable_UnpartitionedJSONUnchanged(t *testing.T) {
+	b, err := json.Marshal(Table{Name: "t", PrimaryKey: []string{"id"}})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if got := string(b); got != `{"Name":"t","Columns":null,"PrimaryKey":["id"]}` {
+		t.Fatalf("unpartitioned table JSON changed (would alter stored fingerprints): %s", got)
+	}
+}
@@ -0,0 +1,170 @@
+package checks
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Routine describes a stored procedure or function in a schema snapshot.
+// Type is PROCEDURE or FUNCTION; SecurityType is DEFINER or INVOKER; Body is
+// the routine body as information_schema.ROUTINES reports it.
+type Routine struct {
+	Name         string
+	Type         string
+	Definer      string
+	SecurityType string
+	Body         string
+}
+
+// removedSyntax lists 5.7 constructs that 8.0 no longer parses, so a routine
+// using them fails to compile on its first call after the upgrade.
+var removedSyntax = []struct {
+	pattern *regexp.Regexp
+	reason  string
+}{
+	{regexp.MustCompile("(?i)\\bgroup\\s+by\\s+(?:[\\w.\x60]+(?:\\s+(?:asc|desc))?\\s*,\\s*)*[\\w.\x60]+\\s+(?:asc|desc)\\b"), "GROUP BY ... ASC/DESC"},
+	{regexp.MustCompile(`(?i)\bsql_cache\b`), "SQL_CACHE"},
+	{regexp.MustCompile(`(?i)\b(flush|reset)\s+query\s+cache\b`), "the query cache"},
+	{regexp.MustCompile(`(?i)\bprocedure\s+analyse\b`), "PROCEDURE ANALYSE"},
+	{regexp.MustCompile(`(?i)\b(password|encode|decode|encrypt|des_encrypt|des_decrypt)\s*\(`), "a removed function"},
+	{regexp.MustCompile(`(?i)\b(geomfromtext|geomfromwkb|pointfromtext|astext|aswkt|asbinary|aswkb|glength)\s*\(`), "a spatial function without the ST_ prefix"},
+	{regexp.MustCompile(`(?i)@@(global\.|session\.)?tx_(isolation|read_only)\b`), "tx_isolation/tx_read_only"},
+	{regexp.MustCompile(`(?i)\binformation_schema\s*\.\s*(global|session)_(variables|status)\b`), "information_schema variables/status tables"},
+	{regexp.MustCompile(`\\N\b`), `\N as NULL`},
+}
+
+// stringLiteral matches a quoted SQL string, so text inside literals is not
+// mistaken for syntax.
+var stringLiteral = regexp.MustCompile(`'(?:[^'\\]|\\.|'')*'|"(?:[^"\\]|\\.|"")*"`)
+
+// RemovedSyntax returns the 5.7 constructs in body that 8.0 rejects.
+func RemovedSyntax(body string) []string {
+	code := stringLiteral.ReplaceAllString(body, "''")
+	found := []string{}
+	for _, s := range removedSyntax {
+		if s.pattern.MatchString(code) {
+			found = append(found, s.reason)
+		}
+	}
+	return found
+}
+
+// compareRoutines reports stored routine drift by type and name, and
+// routines on either side whose body uses syntax 8.0 removed. A missing
+// routine, a different DEFINER or SQL SECURITY (which changes the privileges
+// it runs with) and removed syntax block; a different body warns.
+func compareRoutines(primary Schema, replica Schema) []Finding {
+	findings := []Finding{}
+	replicaRoutines := make(map[string]Routine, len(replica.Routines))
+	for _, r := range replica.Routines {
+		replicaRoutines[routineKey(r)] = r
+	}
+	primaryRoutines := make(map[string]struct{}, len(primary.Routines))
+	for _, p := range primary.Routines {
+		primaryRoutines[routineKey(p)] = struct{}{}
+		r, ok := replicaRoutines[routineKey(p)]
+		meta := map[string]interface{}{"routine": p.Name, "type": routineType(p)}
+		if !ok {
+			findings = append(findings, Finding{
+				Severity: SeverityBlock,
+				Code:     CodeSchemaRoutineMissing,
+				Message:  fmt.Sprintf("%s %q missing on replica", strings.ToLower(routineType(p)), p.Name),
+				Meta:     meta,
+			})
+			continue
+		}
+		differences := []string{}
+		if !strings.EqualFold(p.Definer, r.Definer) {
+			differences = append(differences, "definer")
+		}
+		if !strings.EqualFold(securityType(p), securityType(r)) {
+			differences = append(differences, "sql security")
+		}
+		if len(differences) > 0 {
+			meta["differences"] = differences
+			meta["primary_definition"] = fmt.Sprintf("DEFINER=%s SQL SECURITY %s", p.Definer, securityType(p))
+			meta["replica_definition"] = fmt.Sprintf("DEFINER=%s SQL SECURITY %s", r.Definer, securityType(r))
+			findings = append(findings, Finding{
+				Severity: SeverityBlock,
+				Code:     CodeSchemaRoutineSecurity,
+				Message:  fmt.Sprintf("%s %q runs with different privileges on replica (%s)", strings.ToLower(routineType(p)), p.Name, strings.Join(differences, ", ")),
+				Meta:     meta,
+			})
+		}
+		if normalizeRoutineBody(p.Body) != normalizeRoutineBody(r.Body) {
+			findings = append(findings, Finding{
+				Severity: SeverityWarn,
+				Code:     CodeSchemaRoutineMismatch,
+				Message:  fmt.Sprintf("%s %q body differs", strings.ToLower(routineType(p)), p.Name),
+				Meta:     map[string]interface{}{"routine": p.Name, "type": routineType(p)},
+			})
+		}
+	}
+	for _, r := range replica.Routines {
+		if _, ok := primaryRoutines[routineKey(r)]; !ok {
+			findings = append(findings, Finding{
+				Severity: SeverityWarn,
+				Code:     CodeSchemaRoutineExtra,
+				Message:  fmt.Sprintf("extra %s %q exists on replica", strings.ToLower(routineType(r)), r.Name),
+				Meta:     map[string]interface{}{"routine": r.Name, "type": routineType(r)},
+			})
+		}
+	}
+
+	// A body is checked once even when both sides have it.
+	incompatible := map[string]Finding{}
+	for _, side := range []struct {
+		host     string
+		routines []Routine
+	}{{"primary", primary.Routines}, {"replica", replica.Routines}} {
+		for _, r := range side.routines {
+			if _, seen := incompatible[routineKey(r)]; seen {
+				continue
+			}
+			reasons := RemovedSyntax(r.Body)
+			if len(reasons) == 0 {
+				continue
+			}
+			incompatible[routineKey(r)] = Finding{
+				Severity: SeverityBlock,
+				Code:     CodeSchemaRoutineIncompatible,
+				Message:  fmt.Sprintf("%s %q uses syntax removed in 8.0 (%s) and will fail to compile", strings.ToLower(routineType(r)), r.Name, strings.Join(reasons, ", ")),
+				Meta:     map[string]interface{}{"routine": r.Name, "type": routineType(r), "syntax": reasons, "host": side.host},
+			}
+		}
+	}
+	keys := make([]string, 0, len(incompatible))
+	for key := range incompatible {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		findings = append(findings, incompatible[key])
+	}
+	return findings
+}
+
+func routineType(r Routine) string {
+	if r.Type == "" {
+		return "PROCEDURE"
+	}
+	return strings.ToUpper(r.Type)
+}
+
+func routineKey(r Routine) string { return routineType(r) + " " + r.Name }
+
+// securityType defaults to DEFINER, as CREATE PROCEDURE does.
+func securityType(r Routine) string {
+	if r.SecurityType == "" {
+		return "DEFINER"
+	}
+	return strings.ToUpper(r.SecurityType)
+}
+
+func normalizeRoutineBody(body string) string {
+	body = strings.ReplaceAll(body, "`", "")
+	body = charsetIntroducer.ReplaceAllString(strings.ToLower(body), "_utf8'")
+	return strings.TrimSpace(whitespace.ReplaceAllString(body, " "))
+}
@@ -0,0 +1,47 @@
+package checks
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompareRoutines_ComparesSecurityAndBody(t *testing.T) {
+	routine := Routine{Name: "close_order", Type: "PROCEDURE", Definer: "app@%", SecurityType: "DEFINER", Body: "BEGIN UPDATE `orders` SET status = 'closed'; END"}
+	same := routine
+	same.Body = "BEGIN\n  UPDATE orders SET status = 'closed';\nEND"
+	if findings := compareRoutines(Schema{Routines: []Routine{routine}}, Schema{Routines: []Routine{same}}); len(findings) != 0 {
+		t.Fatalf("expected reformatted bodies to match, got %+v", findings)
+	}
+
+	changed := routine
+	changed.SecurityType = "INVOKER"
+	changed.Body = "BEGIN DELETE FROM orders; END"
+	findings := compareRoutines(Schema{Routines: []Routine{routine}}, Schema{Routines: []Routine{changed}})
+	if len(findings) != 2 || findings[0].Code != CodeSchemaRoutineSecurity || findings[0].Severity != SeverityBlock || findings[1].Code != CodeSchemaRoutineMismatch {
+		t.Fatalf("expected a security block and a body warning, got %+v", findings)
+	}
+
+	function := Routine{Name: "close_order", Type: "FUNCTION", Definer: "app@%"}
+	findings = compareRoutines(Schema{Routines: []Routine{routine}}, Schema{Routines: []Routine{function}})
+	if len(findings) != 2 || findings[0].Code != CodeSchemaRoutineMissing || findings[1].Code != CodeSchemaRoutineExtra {
+		t.Fatalf("expected a procedure and a function of one name to be told apart, got %+v", findings)
+	}
+}
+
+func TestCompareRoutines_BlocksRemovedSyntax(t *testing.T) {
+	routine := Routine{Name: "report", Type: "PROCEDURE", Body: "BEGIN SELECT status, COUNT(*) FROM orders GROUP BY status DESC; SELECT PASSWORD('x'); END"}
+	findings := compareRoutines(Schema{Routines: []Routine{routine}}, Schema{Routines: []Routine{routine}})
+	if len(findings) != 1 || findings[0].Code != CodeSchemaRoutineIncompatible || !strings.Contains(findings[0].Message, "GROUP BY ... ASC/DESC") || !strings.Contains(findings[0].Message, "a removed function") {
+		t.Fatalf("expected one finding for the shared body, got %+v", findings)
+	}
+}
+
+func TestRemovedSyntax_IgnoresLiteralsAndOrderBy(t *testing.T) {
+	body := "BEGIN SELECT status FROM orders GROUP BY status ORDER BY status DESC; SELECT 'use SQL_CACHE or PASSWORD(x)'; END"
+	if found := RemovedSyntax(body); len(found) != 0 {
+		t.Fatalf("expected no removed syntax, got %v", found)
+	}
+	if found := RemovedSyntax("SELECT @@tx_isolation, a FROM t GROUP BY a, b ASC"); len(found) != 2 {
+		t.Fatalf("expected tx_isolation and GROUP BY ASC, got %v", found)
+	}
+}
@@ -0,0 +1,165 @@
+package checks
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// View describes a view in a schema snapshot. Definition is the SELECT as
+// information_schema.VIEWS reports it. References lists the base table
+// columns the view reads as "table.column", named the way the snapshot names
+// tables; column references in Definition are added to it when views are
+// compared.
+type View struct {
+	Name       string
+	Definition string
+	References []string `json:",omitempty"`
+}
+
+var (
+	// quotedPath matches a backtick-quoted identifier path such as
+	// `db`.`t`.`c` in a stored view definition.
+	quotedPath = regexp.MustCompile("`[^`]+`(?:\\.`[^`]+`)+")
+	// charsetIntroducer matches the string literal introducers 5.7 and 8.0
+	// spell differently for the same character set.
+	charsetIntroducer = regexp.MustCompile(`_utf8(mb3|mb4)?'`)
+	whitespace        = regexp.MustCompile(`\s+`)
+)
+
+// compareViews reports view drift by name and replica views that read
+// columns the replica's tables no longer have. A missing view or one reading
+// a missing column blocks: queries against it fail after promotion. A body
+// that differs once normalized warns, since 8.0 may rewrite a definition
+// without changing what it returns.
+func compareViews(primary Schema, replica Schema) []Finding {
+	findings := []Finding{}
+	replicaViews := make(map[string]View, len(replica.Views))
+	for _, v := range replica.Views {
+		replicaViews[v.Name] = v
+	}
+	primaryViews := make(map[string]struct{}, len(primary.Views))
+	for _, pView := range primary.Views {
+		primaryViews[pView.Name] = struct{}{}
+		rView, ok := replicaViews[pView.Name]
+		if !ok {
+			findings = append(findings, Finding{
+				Severity: SeverityBlock,
+				Code:     CodeSchemaViewMissing,
+				Message:  fmt.Sprintf("view %q missing on replica", pView.Name),
+				Meta:     map[string]interface{}{"view": pView.Name},
+			})
+			continue
+		}
+		pBody, rBody := NormalizeViewDefinition(pView.Definition), NormalizeViewDefinition(rView.Definition)
+		if pBody != rBody {
+			findings = append(findings, Finding{
+				Severity: SeverityWarn,
+				Code:     CodeSchemaViewMismatch,
+				Message:  fmt.Sprintf("view %q definition differs", pView.Name),
+				Meta:     map[string]interface{}{"view": pView.Name, "primary_definition": pBody, "replica_definition": rBody},
+			})
+		}
+	}
+	for _, rView := range replica.Views {
+		if _, ok := primaryViews[rView.Name]; !ok {
+			findings = append(findings, Finding{
+				Severity: SeverityWarn,
+				Code:     CodeSchemaViewExtra,
+				Message:  fmt.Sprintf("extra view %q exists on replica", rView.Name),
+				Meta:     map[string]interface{}{"view": rView.Name},
+			})
+		}
+	}
+
+	tables := tableIndex(replica.Tables)
+	for _, rView := range replica.Views {
+		missing := []string{}
+		for _, ref := range viewReferences(rView, tables) {
+			dot := strings.LastIndex(ref, ".")
+			table, ok := tables[ref[:dot]]
+			if !ok {
+				continue // reported as a missing table
+			}
+			if _, ok := columnIndex(table.Columns)[ref[dot+1:]]; !ok {
+				missing = append(missing, ref)
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		findings = append(findings, Finding{
+			Severity: SeverityBlock,
+			Code:     CodeSchemaViewColumnMissing,
+			Message:  fmt.Sprintf("view %q on replica reads columns that do not exist: %s", rView.Name, strings.Join(missing, ", ")),
+			Meta:     map[string]interface{}{"view": rView.Name, "columns": missing},
+		})
+	}
+	return findings
+}
+
+// NormalizeViewDefinition returns a view body in a form that compares equal
+// across 5.7 and 8.0: unquoted, lowercased, single-spaced, with column
+// references reduced to table.column and charset introducers unified.
+func NormalizeViewDefinition(definition string) string {
+	body := quotedPath.ReplaceAllStringFunc(definition, func(path string) string {
+		parts := strings.Split(path, ".")
+		if len(parts) > 2 {
+			parts = parts[len(parts)-2:]
+		}
+		return strings.Join(parts, ".")
+	})
+	body = strings.ReplaceAll(body, "`", "")
+	body = charsetIntroducer.ReplaceAllString(strings.ToLower(body), "_utf8'")
+	return strings.TrimSpace(whitespace.ReplaceAllString(body, " "))
+}
+
+// viewReferences returns the view's References plus the table.column
+// references in its definition whose table is in tables, sorted and unique.
+// A definition names columns by table alias; only references through a
+// table's own name can be resolved.
+func viewReferences(view View, tables map[string]Table) []string {
+	seen := map[string]struct{}{}
+	for _, ref := range view.References {
+		if strings.Contains(ref, ".") {
+			seen[ref] = struct{}{}
+		}
+	}
+	for _, path := range quotedPath.FindAllString(view.Definition, -1) {
+		parts := strings.Split(strings.ReplaceAll(path, "`", ""), ".")
+		column := parts[len(parts)-1]
+		for _, table := range candidateTables(parts[:len(parts)-1], tables) {
+			seen[table+"."+column] = struct{}{}
+		}
+	}
+	refs := make([]string, 0, len(seen))
+	for ref := range seen {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+// candidateTables resolves the qualifier of a column reference (table, or
+// db and table) to the snapshot's table name, if it names exactly one table.
+func candidateTables(qualifier []string, tables map[string]Table) []string {
+	name := strings.Join(qualifier, ".")
+	if _, ok := tables[name]; ok {
+		return []string{name}
+	}
+	short := qualifier[len(qualifier)-1]
+	if _, ok := tables[short]; ok {
+		return []string{short}
+	}
+	matches := []string{}
+	for table := range tables {
+		if strings.HasSuffix(table, "."+short) {
+			matches = append(matches, table)
+		}
+	}
+	if len(qualifier) == 1 && len(matches) == 1 {
+		return matches
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package checks
+
+import (
+	"strings"
+	"testing"
+)
+
+func viewSchema(columns []string, views ...View) Schema {
+	cols := []Column{}
+	for _, name := range columns {
+		cols = append(cols, Column{Name: name, Type: "int"})
+	}
+	return Schema{Tables: []Table{{Name: "orders", Columns: cols, PrimaryKey: []string{"id"}}}, Views: views}
+}
+
+func TestCompareViews_NormalizesDefinitions(t *testing.T) {
+	primary := viewSchema([]string{"id", "total"}, View{Name: "order_totals", Definition: "select `shop`.`orders`.`id` AS `id`,`shop`.`orders`.`total` AS `total` from `shop`.`orders` where (`shop`.`orders`.`note` = _utf8'x')"})
+	replica := viewSchema([]string{"id", "total"}, View{Name: "order_totals", Definition: "select `orders`.`id` AS `id`,`orders`.`total`  AS `total` from `shop`.`orders` where (`orders`.`note` = _utf8mb4'x')"})
+	for _, f := range compareViews(primary, replica) {
+		if f.Code == CodeSchemaViewMismatch {
+			t.Fatalf("expected equivalent 5.7 and 8.0 bodies to match, got %+v", f)
+		}
+	}
+
+	replica.Views[0].Definition = "select `orders`.`id` AS `id` from `shop`.`orders`"
+	findings := compareViews(primary, replica)
+	if len(findings) != 1 || findings[0].Code != CodeSchemaViewMismatch || findings[0].Severity != SeverityWarn {
+		t.Fatalf("expected a changed body to warn, got %+v", findings)
+	}
+}
+
+func TestCompareViews_BlocksMissingViewsAndColumns(t *testing.T) {
+	view := View{Name: "order_totals", Definition: "select `orders`.`id` AS `id`,`orders`.`total` AS `total` from `orders`"}
+	primary := viewSchema([]string{"id", "total"}, view)
+
+	findings := compareViews(primary, viewSchema([]string{"id", "total"}, View{Name: "other", Definition: "select 1 AS `1`"}))
+	if len(findings) != 2 || findings[0].Code != CodeSchemaViewMissing || findings[0].Severity != SeverityBlock || findings[1].Code != CodeSchemaViewExtra {
+		t.Fatalf("expected a missing and an extra view, got %+v", findings)
+	}
+
+	findings = compareViews(primary, viewSchema([]string{"id"}, view))
+	if len(findings) != 1 || findings[0].Code != CodeSchemaViewColumnMissing || !strings.Contains(findings[0].Message, "orders.total") {
+		t.Fatalf("expected the dropped column to block, got %+v", findings)
+	}
+
+	aliased := View{Name: "order_totals", Definition: "select `o`.`total` AS `total` from `orders` `o`", References: []string{"orders.total"}}
+	findings = compareViews(viewSchema([]string{"id", "total"}, aliased), viewSchema([]string{"id"}, aliased))
+	if len(findings) != 1 || findings[0].Code != CodeSchemaViewColumnMissing {
+		t.Fatalf("expected recorded references to be checked, got %+v", findings)
+	}
+}
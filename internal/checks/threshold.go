@@ -0,0 +1,34 @@
+package checks
+
+import "time"
+
+// Threshold is a plan-defined limit a check measures against. Findings that
+// evaluate one carry Meta(measured) under the "threshold" meta key so reports
+// show the margin, not just pass/fail.
+type Threshold struct {
+	Name  string
+	Limit float64
+	Unit  string
+}
+
+// DurationThreshold expresses a duration limit in seconds.
+func DurationThreshold(name string, limit time.Duration) Threshold {
+	return Threshold{Name: name, Limit: limit.Seconds(), Unit: "seconds"}
+}
+
+// Exceeded reports whether measured is above the limit.
+func (t Threshold) Exceeded(measured float64) bool {
+	return measured > t.Limit
+}
+
+// Meta describes measured against the threshold. Margin is negative once the
+// limit is exceeded.
+func (t Threshold) Meta(measured float64) map[string]interface{} {
+	return map[string]interface{}{
+		"name":     t.Name,
+		"limit":    t.Limit,
+		"measured": measured,
+		"margin":   t.Limit - measured,
+		"unit":     t.Unit,
+	}
+}
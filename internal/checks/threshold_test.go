@@ -0,0 +1,17 @@
+package checks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestThreshold_MetaReportsMargin(t *testing.T) {
+	th := DurationThreshold("max_lag", 30*time.Second)
+	if th.Exceeded(30) || !th.Exceeded(45) {
+		t.Fatalf("unexpected Exceeded results for limit %v", th.Limit)
+	}
+	meta := th.Meta(45)
+	if meta["limit"] != 30.0 || meta["measured"] != 45.0 || meta["margin"] != -15.0 || meta["unit"] != "seconds" {
+		t.Fatalf("unexpected threshold meta: %+v", meta)
+	}
+}
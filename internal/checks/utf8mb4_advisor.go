@@ -0,0 +1,284 @@
+package checks
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxIndexKeyBytes is the InnoDB index key length limit with the
+// DYNAMIC and COMPRESSED row formats, the default since 5.7.9. Tables using
+// COMPACT or REDUNDANT are limited to 767 bytes per column.
+const DefaultMaxIndexKeyBytes = 3072
+
+// charsetBytes is the maximum bytes per character of the charsets an index
+// key may hold.
+var charsetBytes = map[string]int{
+	"utf8mb4": 4, "utf8mb3": 3, "utf8": 3, "utf16": 4, "utf16le": 4, "utf32": 4, "ucs2": 2,
+	"gbk": 2, "gb18030": 4, "big5": 2, "sjis": 2, "cp932": 2, "ujis": 3, "eucjpms": 3, "euckr": 2,
+}
+
+// fixedKeyBytes are the key widths of fixed-size column types.
+var fixedKeyBytes = map[string]int{
+	"tinyint": 1, "smallint": 2, "mediumint": 3, "int": 4, "integer": 4, "bigint": 8,
+	"float": 4, "double": 8, "real": 8, "date": 3, "time": 3, "year": 1, "timestamp": 4, "datetime": 5,
+	"bit": 8, "enum": 2, "set": 8,
+}
+
+var columnTypePattern = regexp.MustCompile(`^(\w+)(?:\((\d+))?`)
+
+// isUTF8MB3 reports whether charset is the 3-byte utf8 8.0 deprecates.
+func isUTF8MB3(charset string) bool {
+	c := strings.ToLower(charset)
+	return c == "utf8" || c == "utf8mb3"
+}
+
+// utf8mb4Collation maps a utf8mb3 collation to its utf8mb4 counterpart, which
+// compares and sorts the same way for the characters both can store.
+func utf8mb4Collation(collation string) string {
+	c := strings.ToLower(collation)
+	for _, prefix := range []string{"utf8mb3_", "utf8_"} {
+		if strings.HasPrefix(c, prefix) {
+			return "utf8mb4_" + strings.TrimPrefix(c, prefix)
+		}
+	}
+	return "utf8mb4_general_ci"
+}
+
+// keyPart is one column of an index as the advisor sizes it.
+type keyPart struct {
+	column Column
+	chars  int // characters indexed; 0 for non-character columns
+	bytes  int // key bytes before conversion
+	known  bool
+}
+
+// sizeKeyPart estimates the key bytes column takes in an index with the given
+// prefix length (0 for the whole column). Character columns are sized at the
+// charset's maximum bytes per character, as InnoDB does; TEXT columns can only
+// be sized with a prefix.
+func sizeKeyPart(col Column, prefix int) keyPart {
+	m := columnTypePattern.FindStringSubmatch(strings.ToLower(col.Type))
+	if m == nil {
+		return keyPart{column: col}
+	}
+	typ, length := m[1], 0
+	if m[2] != "" {
+		length, _ = strconv.Atoi(m[2])
+	}
+	switch typ {
+	case "char", "varchar", "tinytext", "text", "mediumtext", "longtext":
+		chars := length
+		if prefix > 0 {
+			chars = prefix
+		}
+		if chars == 0 {
+			return keyPart{column: col}
+		}
+		perChar, ok := charsetBytes[strings.ToLower(col.Charset)]
+		if !ok {
+			perChar = 1
+		}
+		return keyPart{column: col, chars: chars, bytes: chars * perChar, known: true}
+	case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob":
+		if prefix > 0 {
+			length = prefix
+		}
+		return keyPart{column: col, bytes: length, known: length > 0}
+	case "decimal", "numeric":
+		return keyPart{column: col, bytes: (length + 1) / 2, known: true}
+	}
+	width, ok := fixedKeyBytes[typ]
+	return keyPart{column: col, bytes: width, known: ok}
+}
+
+// indexImpact is how converting the table's utf8mb3 columns changes an
+// index's key length.
+type indexImpact struct {
+	index  Index
+	parts  []keyPart
+	before int
+	after  int
+	known  bool
+}
+
+// utf8mb4Impact sizes idx before and after every utf8mb3 column in it
+// becomes utf8mb4.
+func utf8mb4Impact(idx Index, columns map[string]Column) indexImpact {
+	impact := indexImpact{index: idx, known: true}
+	for n, name := range idx.Columns {
+		prefix := 0
+		if n < len(idx.Prefixes) {
+			prefix = idx.Prefixes[n]
+		}
+		part := sizeKeyPart(columns[name], prefix)
+		impact.parts = append(impact.parts, part)
+		impact.known = impact.known && part.known
+		impact.before += part.bytes
+		if isUTF8MB3(part.column.Charset) {
+			impact.after += part.chars * 4
+		} else {
+			impact.after += part.bytes
+		}
+	}
+	return impact
+}
+
+// utf8mb4Findings advises on converting each utf8/utf8mb3 column of table to
+// utf8mb4. Every index on the column is sized before and after (4 bytes per
+// character instead of 3). A column whose conversion pushes an index past
+// maxKeyBytes warns, since the ALTER fails; otherwise it is reported as INFO.
+// Meta "indexes" lists each index's bytes before and after; "complete" is
+// false when a column's width is unknown and the sizes are lower bounds.
+// Both carry candidate ALTER statements in meta "alter_statements": the
+// MODIFY that converts the column keeping its type, nullability and default,
+// preceded, for an index that would be too long, by one rebuilding it with a
+// prefix that fits. Unique indexes get no prefix candidate, because a prefix
+// changes what is unique; the column has to be shortened instead.
+func utf8mb4Findings(table Table, maxKeyBytes int) []Finding {
+	columns := columnIndex(table.Columns)
+	indexes := append([]Index{}, table.Indexes...)
+	if len(table.PrimaryKey) > 0 && !hasIndex(indexes, "PRIMARY") {
+		indexes = append(indexes, Index{Name: "PRIMARY", Type: "BTREE", Unique: true, Columns: table.PrimaryKey})
+	}
+	findings := []Finding{}
+	for _, col := range table.Columns {
+		if !isUTF8MB3(col.Charset) {
+			continue
+		}
+		impacts := []map[string]interface{}{}
+		tooLong := []string{}
+		statements := []string{}
+		for _, idx := range indexes {
+			if !containsString(idx.Columns, col.Name) || strings.EqualFold(idx.Type, "FULLTEXT") {
+				continue
+			}
+			impact := utf8mb4Impact(idx, columns)
+			impacts = append(impacts, map[string]interface{}{"index": idx.Name, "unique": idx.Unique, "bytes_before": impact.before, "bytes_after": impact.after, "complete": impact.known})
+			if impact.after <= maxKeyBytes {
+				continue
+			}
+			tooLong = append(tooLong, fmt.Sprintf("%s (%d → %d bytes)", idx.Name, impact.before, impact.after))
+			if stmt, ok := prefixedIndexStatement(table.Name, impact, maxKeyBytes); ok {
+				statements = append(statements, stmt)
+			}
+		}
+		meta := map[string]interface{}{
+			"table":     table.Name,
+			"column":    col.Name,
+			"charset":   col.Charset,
+			"type":      col.Type,
+			"indexes":   impacts,
+			"max_bytes": maxKeyBytes,
+		}
+		if col.Generated != "" {
+			meta["alter_statements"] = statements
+			meta["generated"] = true
+		} else {
+			meta["alter_statements"] = append(statements, modifyToUTF8MB4(table.Name, col))
+		}
+		if len(tooLong) > 0 {
+			findings = append(findings, Finding{
+				Severity: SeverityWarn,
+				Code:     CodeCompatUTF8MB4IndexTooLong,
+				Message:  fmt.Sprintf("table %q column %q: converting %s to utf8mb4 pushes indexes past the %d-byte key limit: %s; shorten the index prefix or the column first", table.Name, col.Name, col.Charset, maxKeyBytes, strings.Join(tooLong, ", ")),
+				Meta:     meta,
+			})
+			continue
+		}
+		message := fmt.Sprintf("table %q column %q can be converted from %s to utf8mb4", table.Name, col.Name, col.Charset)
+		if len(impacts) > 0 {
+			message += fmt.Sprintf("; its %d indexes stay within the %d-byte key limit", len(impacts), maxKeyBytes)
+		}
+		findings = append(findings, Finding{
+			Severity: SeverityInfo,
+			Code:     CodeCompatUTF8MB4Conversion,
+			Message:  message,
+			Meta:     meta,
+		})
+	}
+	return findings
+}
+
+// prefixedIndexStatement rebuilds a non-unique index with the utf8mb3 columns
+// indexed by the longest prefix that keeps the converted key within
+// maxKeyBytes.
+func prefixedIndexStatement(table string, impact indexImpact, maxKeyBytes int) (string, bool) {
+	if impact.index.Unique || !impact.known || strings.EqualFold(impact.index.Type, "SPATIAL") {
+		return "", false
+	}
+	budget, converted := maxKeyBytes, 0
+	for _, part := range impact.parts {
+		if isUTF8MB3(part.column.Charset) {
+			converted++
+		} else {
+			budget -= part.bytes
+		}
+	}
+	if converted == 0 || budget <= 0 {
+		return "", false
+	}
+	prefix := budget / (4 * converted)
+	if prefix == 0 {
+		return "", false
+	}
+	parts := make([]string, len(impact.parts))
+	for n, part := range impact.parts {
+		parts[n] = quoteIdentifier(part.column.Name)
+		if isUTF8MB3(part.column.Charset) && part.chars > prefix {
+			parts[n] += fmt.Sprintf("(%d)", prefix)
+		} else if n < len(impact.index.Prefixes) && impact.index.Prefixes[n] > 0 {
+			parts[n] += fmt.Sprintf("(%d)", impact.index.Prefixes[n])
+		}
+	}
+	name := quoteIdentifier(impact.index.Name)
+	return fmt.Sprintf("ALTER TABLE %s DROP INDEX %s, ADD INDEX %s (%s)", quoteTable(table), name, name, strings.Join(parts, ", ")), true
+}
+
+// modifyToUTF8MB4 converts col to utf8mb4, restating its type, nullability
+// and default so MODIFY keeps them.
+func modifyToUTF8MB4(table string, col Column) string {
+	stmt := fmt.Sprintf("ALTER TABLE %s MODIFY %s %s CHARACTER SET utf8mb4 COLLATE %s", quoteTable(table), quoteIdentifier(col.Name), col.Type, utf8mb4Collation(col.Collation))
+	if col.Nullable {
+		stmt += " NULL"
+	} else {
+		stmt += " NOT NULL"
+	}
+	if col.Default != nil {
+		stmt += " DEFAULT '" + strings.ReplaceAll(strings.ReplaceAll(*col.Default, `\`, `\\`), "'", "''") + "'"
+	}
+	return stmt
+}
+
+func quoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// quoteTable quotes a table named the way snapshots name tables, optionally
+// qualified by its database.
+func quoteTable(name string) string {
+	parts := strings.SplitN(name, ".", 2)
+	for n := range parts {
+		parts[n] = quoteIdentifier(parts[n])
+	}
+	return strings.Join(parts, ".")
+}
+
+func hasIndex(indexes []Index, name string) bool {
+	for _, idx := range indexes {
+		if strings.EqualFold(idx.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
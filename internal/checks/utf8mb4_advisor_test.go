@@ -0,0 +1,99 @@
+package checks
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func utf8mb3Schema() Schema {
+	empty := ""
+	return Schema{Tables: []Table{{
+		Name:       "shop.users",
+		PrimaryKey: []string{"id"},
+		Columns: []Column{
+			{Name: "id", Type: "int"},
+			{Name: "email", Type: "varchar(255)", Charset: "utf8", Collation: "utf8_general_ci", Default: &empty},
+			{Name: "name", Type: "varchar(1000)", Nullable: true, Charset: "utf8mb3", Collation: "utf8mb3_unicode_ci"},
+			{Name: "bio", Type: "text", Nullable: true, Charset: "utf8", Collation: "utf8_general_ci"},
+			{Name: "locale", Type: "varchar(8)", Charset: "utf8mb4", Collation: "utf8mb4_0900_ai_ci"},
+		},
+		Indexes: []Index{
+			{Name: "uniq_email", Type: "BTREE", Unique: true, Columns: []string{"email"}},
+			{Name: "idx_name_id", Type: "BTREE", Columns: []string{"name", "id"}},
+			{Name: "idx_bio", Type: "BTREE", Columns: []string{"bio"}, Prefixes: []int{100}},
+		},
+	}}}
+}
+
+func runUTF8MB4Advisor(t *testing.T, maxKeyBytes int) map[string]Finding {
+	t.Helper()
+	check := &MySQLCompatibilityCheck{
+		Inspector:        &fakeMySQLInspector{},
+		SchemaInspector:  &fakeSchemaInspectorCompat{schema: utf8mb3Schema()},
+		PrimaryHost:      "primary",
+		MaxIndexKeyBytes: maxKeyBytes,
+	}
+	findings, err := check.Run(context.Background(), Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	byColumn := map[string]Finding{}
+	for _, f := range findings {
+		if f.Code != CodeCompatUTF8MB4Conversion && f.Code != CodeCompatUTF8MB4IndexTooLong {
+			continue
+		}
+		if problems := ValidateMeta(f.Code, f.Meta); len(problems) > 0 {
+			t.Fatalf("invalid meta: %v", problems)
+		}
+		byColumn[f.Meta["column"].(string)] = f
+	}
+	return byColumn
+}
+
+func TestUTF8MB4Advisor_EstimatesIndexImpactAndProposesAlters(t *testing.T) {
+	findings := runUTF8MB4Advisor(t, 0)
+	if len(findings) != 3 {
+		t.Fatalf("expected a finding per utf8mb3 column, got %+v", findings)
+	}
+
+	email := findings["email"]
+	if email.Severity != SeverityInfo || email.Code != CodeCompatUTF8MB4Conversion {
+		t.Fatalf("expected email to convert cleanly, got %+v", email)
+	}
+	wantIndexes := []map[string]interface{}{{"index": "uniq_email", "unique": true, "bytes_before": 765, "bytes_after": 1020, "complete": true}}
+	if !reflect.DeepEqual(email.Meta["indexes"], wantIndexes) {
+		t.Fatalf("unexpected email index impact: %v", email.Meta["indexes"])
+	}
+	wantAlter := []string{"ALTER TABLE `shop`.`users` MODIFY `email` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci NOT NULL DEFAULT ''"}
+	if !reflect.DeepEqual(email.Meta["alter_statements"], wantAlter) {
+		t.Fatalf("unexpected email statements: %v", email.Meta["alter_statements"])
+	}
+
+	name := findings["name"]
+	if name.Severity != SeverityWarn || name.Code != CodeCompatUTF8MB4IndexTooLong {
+		t.Fatalf("expected name to push idx_name_id past the limit, got %+v", name)
+	}
+	wantAlter = []string{
+		"ALTER TABLE `shop`.`users` DROP INDEX `idx_name_id`, ADD INDEX `idx_name_id` (`name`(767), `id`)",
+		"ALTER TABLE `shop`.`users` MODIFY `name` varchar(1000) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NULL",
+	}
+	if !reflect.DeepEqual(name.Meta["alter_statements"], wantAlter) {
+		t.Fatalf("unexpected name statements: %v", name.Meta["alter_statements"])
+	}
+
+	bio := findings["bio"]
+	if bio.Severity != SeverityInfo || bio.Meta["indexes"].([]map[string]interface{})[0]["bytes_after"] != 400 {
+		t.Fatalf("expected the bio prefix index to be sized from its prefix, got %+v", bio)
+	}
+}
+
+func TestUTF8MB4Advisor_UniqueIndexGetsNoPrefixCandidate(t *testing.T) {
+	email := runUTF8MB4Advisor(t, 767)["email"]
+	if email.Severity != SeverityWarn || email.Meta["max_bytes"] != 767 {
+		t.Fatalf("expected uniq_email to exceed a 767-byte limit, got %+v", email)
+	}
+	if statements := email.Meta["alter_statements"].([]string); len(statements) != 1 {
+		t.Fatalf("expected only the column conversion for a unique index, got %v", statements)
+	}
+}
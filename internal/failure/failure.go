@@ -0,0 +1,100 @@
+// Package failure classifies why an operation could not complete, so callers
+// can tell misconfiguration from a target system that could not be read or
+// changed. Findings are results; these errors explain why there are none.
+package failure
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kind names a failure class. It is recorded in finding meta under "cause".
+type Kind string
+
+const (
+	KindConfig    Kind = "config"
+	KindInspector Kind = "inspector"
+	KindAction    Kind = "action"
+)
+
+// ConfigError is a problem with the plan, flags or wiring: re-running against
+// the same targets will fail the same way until the configuration changes.
+type ConfigError struct {
+	Err error
+}
+
+func (e *ConfigError) Error() string { return e.Err.Error() }
+func (e *ConfigError) Unwrap() error { return e.Err }
+
+// InspectorError is a failed read from a target system (MySQL, Kafka Connect,
+// an input file standing in for one).
+type InspectorError struct {
+	Op     string
+	Target string
+	Err    error
+}
+
+func (e *InspectorError) Error() string { return fmt.Sprintf("%s: %v", e.Op, e.Err) }
+func (e *InspectorError) Unwrap() error { return e.Err }
+
+// ActionError is a failed change to a target system. The change may have
+// been partially applied; orchestrators checkpoint before acting so a re-run
+// resumes safely.
+type ActionError struct {
+	Op     string
+	Target string
+	Err    error
+}
+
+func (e *ActionError) Error() string { return fmt.Sprintf("%s: %v", e.Op, e.Err) }
+func (e *ActionError) Unwrap() error { return e.Err }
+
+// Config returns a ConfigError with a formatted message.
+func Config(format string, args ...interface{}) error {
+	return &ConfigError{Err: fmt.Errorf(format, args...)}
+}
+
+// Inspect wraps err as an InspectorError. op prefixes the message, e.g.
+// "failed to determine primary status".
+func Inspect(target string, op string, err error) error {
+	return &InspectorError{Op: op, Target: target, Err: err}
+}
+
+// Act wraps err as an ActionError. op prefixes the message, e.g.
+// "failed to stop replication".
+func Act(target string, op string, err error) error {
+	return &ActionError{Op: op, Target: target, Err: err}
+}
+
+// KindOf returns the class of the first typed failure in err's chain, or ""
+// when err is nil or untyped.
+func KindOf(err error) Kind {
+	var config *ConfigError
+	var inspector *InspectorError
+	var action *ActionError
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &config):
+		return KindConfig
+	case errors.As(err, &inspector):
+		return KindInspector
+	case errors.As(err, &action):
+		return KindAction
+	}
+	return ""
+}
+
+// Target returns the host or resource an inspector or action failure
+// concerns, or "".
+func Target(err error) string {
+	var inspector *InspectorError
+	var action *ActionError
+	switch {
+	case errors.As(err, &inspector):
+		return inspector.Target
+	case errors.As(err, &action):
+		return action.Target
+	}
+	return ""
+}
@@ -0,0 +1,38 @@
+package failure
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestKindOf_FollowsWrappedChain(t *testing.T) {
+	cause := errors.New("connection refused")
+	cases := []struct {
+		err    error
+		kind   Kind
+		target string
+	}{
+		{nil, "", ""},
+		{cause, "", ""},
+		{Config("primary is required"), KindConfig, ""},
+		{fmt.Errorf("gate: %w", Inspect("db-replica", "failed to verify replica catch-up", cause)), KindInspector, "db-replica"},
+		{Act("db-primary", "failed to freeze writes on primary", cause), KindAction, "db-primary"},
+	}
+	for _, tc := range cases {
+		if got := KindOf(tc.err); got != tc.kind {
+			t.Fatalf("%v: expected kind %q, got %q", tc.err, tc.kind, got)
+		}
+		if got := Target(tc.err); got != tc.target {
+			t.Fatalf("%v: expected target %q, got %q", tc.err, tc.target, got)
+		}
+	}
+}
+
+func TestActionError_KeepsMessageAndCause(t *testing.T) {
+	cause := errors.New("timeout")
+	err := Act("db-replica", "failed to stop replication", cause)
+	if err.Error() != "failed to stop replication: timeout" || !errors.Is(err, cause) {
+		t.Fatalf("unexpected error %q", err)
+	}
+}
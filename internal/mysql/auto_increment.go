@@ -0,0 +1,121 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"migratorx/internal/checks"
+)
+
+// AutoIncrementInspector reads the next AUTO_INCREMENT value of every table
+// that has one, keyed by table name as the schema snapshot names tables.
+type AutoIncrementInspector interface {
+	AutoIncrements(ctx context.Context, host string) (map[string]uint64, error)
+}
+
+// AutoIncrementCheck compares AUTO_INCREMENT counters between the primary and
+// a replica. Row events carry explicit ids, so a replica's counter only moves
+// with the rows it applies, and 5.7 recomputes it from MAX(id) on restart
+// while 8.0 persists it. A replica counter below the primary's would hand out
+// ids the primary already used once the replica is promoted, so it warns;
+// replication lag alone can cause it, so re-check once the replica caught up.
+type AutoIncrementCheck struct {
+	Inspector AutoIncrementInspector
+	Primary   string
+	Replica   string
+}
+
+func (c *AutoIncrementCheck) Name() string   { return "auto_increment_parity" }
+func (c *AutoIncrementCheck) ReadOnly() bool { return true }
+
+func (c *AutoIncrementCheck) Parameters() map[string]interface{} {
+	return map[string]interface{}{"primary": c.Primary, "replica": c.Replica}
+}
+
+func (c *AutoIncrementCheck) Run(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+	if c.Inspector == nil {
+		return nil, fmt.Errorf("auto increment inspector is required")
+	}
+	if c.Primary == "" || c.Replica == "" {
+		return nil, fmt.Errorf("primary and replica hosts are required")
+	}
+	primary, err := c.Inspector.AutoIncrements(ctx, c.Primary)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read AUTO_INCREMENT counters on %s: %w", c.Primary, err)
+	}
+	replica, err := c.Inspector.AutoIncrements(ctx, c.Replica)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read AUTO_INCREMENT counters on %s: %w", c.Replica, err)
+	}
+
+	tables := make([]string, 0, len(primary))
+	for table := range primary {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	findings := []checks.Finding{}
+	compared := 0
+	for _, table := range tables {
+		replicaNext, ok := replica[table]
+		if !ok {
+			continue // a missing table is reported by schema parity
+		}
+		compared++
+		primaryNext := primary[table]
+		if replicaNext >= primaryNext {
+			continue
+		}
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityWarn,
+			Code:     CodeAutoIncrementBehind,
+			Message:  fmt.Sprintf("table %q AUTO_INCREMENT on replica %q is %d, behind the primary's %d; after promotion new rows may reuse ids", table, c.Replica, replicaNext, primaryNext),
+			Meta:     map[string]interface{}{"table": table, "replica": c.Replica, "primary": c.Primary, "primary_next": primaryNext, "replica_next": replicaNext, "gap": primaryNext - replicaNext},
+		})
+	}
+	if len(findings) > 0 {
+		return findings, nil
+	}
+	return []checks.Finding{{
+		Severity: checks.SeverityInfo,
+		Code:     CodeAutoIncrementOK,
+		Message:  fmt.Sprintf("AUTO_INCREMENT counters on replica %q are level with or ahead of the primary for %d tables", c.Replica, compared),
+		Meta:     map[string]interface{}{"replica": c.Replica, "primary": c.Primary, "table_count": compared},
+	}}, nil
+}
+
+// autoIncrementsQuery reads counters from information_schema.TABLES. On 8.0
+// the value is cached for information_schema_stats_expiry seconds, so a
+// stale replica value errs toward a warning.
+const autoIncrementsQuery = `SELECT TABLE_SCHEMA, TABLE_NAME, AUTO_INCREMENT
+FROM information_schema.TABLES
+WHERE TABLE_TYPE = 'BASE TABLE' AND AUTO_INCREMENT IS NOT NULL AND %s`
+
+func (i *InformationSchemaInspector) AutoIncrements(ctx context.Context, host string) (map[string]uint64, error) {
+	if i.Connect == nil {
+		return nil, fmt.Errorf("schema inspector requires a connector")
+	}
+	q, err := i.Connect(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	filter, args := i.filter()
+	rows, err := q.QueryContext(ctx, fmt.Sprintf(autoIncrementsQuery, filter), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read AUTO_INCREMENT counters: %w", err)
+	}
+	counters := map[string]uint64{}
+	err = scanRows(rows, func(scan func(...interface{}) error) error {
+		var db, table string
+		var next uint64
+		if err := scan(&db, &table, &next); err != nil {
+			return err
+		}
+		counters[i.tableName(db, table)] = next
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read AUTO_INCREMENT counters: %w", err)
+	}
+	return counters, nil
+}
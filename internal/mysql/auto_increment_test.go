@@ -0,0 +1,53 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"migratorx/internal/checks"
+)
+
+type fakeAutoIncrementInspector map[string]map[string]uint64
+
+func (f fakeAutoIncrementInspector) AutoIncrements(ctx context.Context, host string) (map[string]uint64, error) {
+	return f[host], nil
+}
+
+func TestAutoIncrementCheck_WarnsWhenReplicaBehind(t *testing.T) {
+	inspector := fakeAutoIncrementInspector{
+		"mysql-primary":   {"orders": 5001, "users": 120, "audit": 9},
+		"mysql-replica-1": {"orders": 4800, "users": 121},
+	}
+	check := &AutoIncrementCheck{Inspector: inspector, Primary: "mysql-primary", Replica: "mysql-replica-1"}
+	findings, err := check.Run(context.Background(), checks.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Code != CodeAutoIncrementBehind || findings[0].Severity != checks.SeverityWarn {
+		t.Fatalf("expected one behind warning, got %+v", findings)
+	}
+	if findings[0].Meta["table"] != "orders" || findings[0].Meta["gap"] != uint64(201) {
+		t.Fatalf("unexpected meta: %+v", findings[0].Meta)
+	}
+
+	inspector["mysql-replica-1"]["orders"] = 5001
+	findings, _ = check.Run(context.Background(), checks.Input{})
+	if len(findings) != 1 || findings[0].Code != CodeAutoIncrementOK || findings[0].Meta["table_count"] != 2 {
+		t.Fatalf("expected parity across the 2 shared tables, got %+v", findings)
+	}
+}
+
+func TestInformationSchemaInspector_AutoIncrements(t *testing.T) {
+	db := openFakeDB(t, fakeResponse{match: "AUTO_INCREMENT IS NOT NULL", columns: []string{"TABLE_SCHEMA", "TABLE_NAME", "AUTO_INCREMENT"}, rows: [][]driver.Value{
+		{"shop", "orders", int64(5001)},
+	}})
+	inspector := &InformationSchemaInspector{Connect: fakeConnector(db)}
+	counters, err := inspector.AutoIncrements(context.Background(), "mysql-primary")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(counters) != 1 || counters["shop.orders"] != 5001 {
+		t.Fatalf("unexpected counters: %+v", counters)
+	}
+}
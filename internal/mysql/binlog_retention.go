@@ -0,0 +1,211 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"migratorx/internal/checks"
+)
+
+// BinlogRetention is how long a server keeps binary logs and how much history
+// it currently holds. ExpireSeconds is zero when binlogs never expire
+// automatically; EarliestEventAt is nil when the oldest binlog could not be
+// dated.
+type BinlogRetention struct {
+	ExpireSeconds   int64
+	EarliestEventAt *time.Time
+	GTIDPurged      string
+}
+
+// BinlogRetentionInspector reads a server's binlog retention.
+type BinlogRetentionInspector interface {
+	BinlogRetention(ctx context.Context, host string) (BinlogRetention, error)
+}
+
+// BinlogRetentionCheck blocks when the primary purges binlogs sooner than the
+// planned maintenance window, so replicas and CDC paused for the upgrade could
+// not catch up afterwards. Retention under twice the window leaves no margin
+// for an overrun and warns, as does on-disk history shorter than the window.
+type BinlogRetentionCheck struct {
+	Inspector BinlogRetentionInspector
+	Host      string
+	Window    time.Duration
+	Now       func() time.Time
+}
+
+func (c *BinlogRetentionCheck) Name() string   { return "binlog_retention" }
+func (c *BinlogRetentionCheck) ReadOnly() bool { return true }
+
+func (c *BinlogRetentionCheck) Parameters() map[string]interface{} {
+	return map[string]interface{}{"host": c.Host, "maintenance_window": c.Window.String()}
+}
+
+func (c *BinlogRetentionCheck) Run(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+	if c.Inspector == nil {
+		return nil, fmt.Errorf("binlog retention inspector is required")
+	}
+	if c.Window <= 0 {
+		return nil, fmt.Errorf("maintenance window is required")
+	}
+	host := c.Host
+	if host == "" {
+		host = input.PrimaryHost
+	}
+	if host == "" {
+		return nil, fmt.Errorf("host is required")
+	}
+	now := time.Now
+	if c.Now != nil {
+		now = c.Now
+	}
+
+	retention, err := c.Inspector.BinlogRetention(ctx, host)
+	if err != nil {
+		return []checks.Finding{{
+			Severity: checks.SeverityWarn,
+			Code:     CodeBinlogRetentionUnknown,
+			Message:  fmt.Sprintf("unable to read binlog retention on %q: %v", host, err),
+			Meta:     map[string]interface{}{"host": host},
+		}}, nil
+	}
+	expire := time.Duration(retention.ExpireSeconds) * time.Second
+	meta := map[string]interface{}{"host": host, "maintenance_window": c.Window.String(), "expire": expire.String()}
+	if retention.GTIDPurged != "" {
+		meta["gtid_purged"] = retention.GTIDPurged
+	}
+
+	findings := []checks.Finding{}
+	switch {
+	case expire > 0 && expire < c.Window:
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityBlock,
+			Code:     CodeBinlogRetentionTooShort,
+			Message:  fmt.Sprintf("%s purges binlogs after %s, shorter than the %s maintenance window; paused replicas and CDC could not catch up", host, expire, c.Window),
+			Meta:     meta,
+		})
+	case expire > 0 && expire < 2*c.Window:
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityWarn,
+			Code:     CodeBinlogRetentionTight,
+			Message:  fmt.Sprintf("%s purges binlogs after %s, less than twice the %s maintenance window; an overrun could lose history", host, expire, c.Window),
+			Meta:     meta,
+		})
+	}
+	if retention.EarliestEventAt != nil {
+		history := now().Sub(*retention.EarliestEventAt)
+		meta["history"] = history.Round(time.Second).String()
+		if history < c.Window {
+			findings = append(findings, checks.Finding{
+				Severity: checks.SeverityWarn,
+				Code:     CodeBinlogHistoryShort,
+				Message:  fmt.Sprintf("%s holds only %s of binlog history, less than the %s maintenance window; check for manual purges or binlog disk limits", host, history.Round(time.Second), c.Window),
+				Meta:     meta,
+			})
+		}
+	}
+	if len(findings) > 0 {
+		return findings, nil
+	}
+	message := fmt.Sprintf("%s keeps binlogs for %s, enough for the %s maintenance window", host, expire, c.Window)
+	if expire == 0 {
+		message = fmt.Sprintf("%s does not purge binlogs automatically", host)
+	}
+	return []checks.Finding{{Severity: checks.SeverityInfo, Code: CodeBinlogRetentionOK, Message: message, Meta: meta}}, nil
+}
+
+// binlogMagic starts every binary log file.
+var binlogMagic = []byte{0xfe, 'b', 'i', 'n'}
+
+// ReadBinlogStartTime returns the timestamp of the first event in a binary log
+// file, i.e. when the file was started.
+func ReadBinlogStartTime(path string) (time.Time, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer f.Close()
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return time.Time{}, fmt.Errorf("%s: %w", path, err)
+	}
+	for i, b := range binlogMagic {
+		if header[i] != b {
+			return time.Time{}, fmt.Errorf("%s is not a binary log", path)
+		}
+	}
+	return time.Unix(int64(binary.LittleEndian.Uint32(header[4:])), 0).UTC(), nil
+}
+
+const (
+	binlogExpireSecondsQuery = `SELECT @@GLOBAL.binlog_expire_logs_seconds`
+	expireLogsDaysQuery      = `SELECT @@GLOBAL.expire_logs_days`
+	gtidPurgedQuery          = `SELECT @@GLOBAL.gtid_purged`
+	binaryLogsQuery          = `SHOW BINARY LOGS`
+)
+
+// ServerBinlogRetentionInspector implements BinlogRetentionInspector with
+// global variables, falling back from binlog_expire_logs_seconds (8.0) to
+// expire_logs_days (5.7). SQL does not expose event times, so the oldest
+// binlog is dated only when BinlogDir points at the server's binlog directory.
+type ServerBinlogRetentionInspector struct {
+	Connect   Connector
+	BinlogDir string
+}
+
+func (i *ServerBinlogRetentionInspector) BinlogRetention(ctx context.Context, host string) (BinlogRetention, error) {
+	if i.Connect == nil {
+		return BinlogRetention{}, fmt.Errorf("binlog retention inspector requires a connector")
+	}
+	q, err := i.Connect(ctx, host)
+	if err != nil {
+		return BinlogRetention{}, err
+	}
+	var r BinlogRetention
+	if err := queryOne(ctx, q, binlogExpireSecondsQuery, nil, &r.ExpireSeconds); err != nil {
+		var days int64
+		if err := queryOne(ctx, q, expireLogsDaysQuery, nil, &days); err != nil {
+			return BinlogRetention{}, fmt.Errorf("failed to read binlog expiry: %w", err)
+		}
+		r.ExpireSeconds = days * 24 * 60 * 60
+	}
+	var purged sql.NullString
+	if err := queryOne(ctx, q, gtidPurgedQuery, nil, &purged); err == nil {
+		r.GTIDPurged = purged.String
+	}
+	if i.BinlogDir == "" {
+		return r, nil
+	}
+	rows, err := q.QueryContext(ctx, binaryLogsQuery)
+	if err != nil {
+		return BinlogRetention{}, fmt.Errorf("failed to list binary logs: %w", err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return r, rows.Err()
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return BinlogRetention{}, err
+	}
+	values := make([]interface{}, len(columns))
+	var name string
+	values[0] = &name
+	for c := 1; c < len(columns); c++ {
+		values[c] = new(sql.RawBytes)
+	}
+	if err := rows.Scan(values...); err != nil {
+		return BinlogRetention{}, err
+	}
+	started, err := ReadBinlogStartTime(filepath.Join(i.BinlogDir, name))
+	if err != nil {
+		return BinlogRetention{}, err
+	}
+	r.EarliestEventAt = &started
+	return r, nil
+}
@@ -0,0 +1,201 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"migratorx/internal/failure"
+	"migratorx/internal/workflow"
+)
+
+// BinlogExpirySetter changes how long a server keeps binary logs.
+type BinlogExpirySetter interface {
+	SetBinlogExpireSeconds(ctx context.Context, host string, seconds int64) error
+}
+
+// ServerBinlogExpirySetter sets binlog_expire_logs_seconds, falling back to
+// expire_logs_days (rounded up to whole days) on 5.7. SET GLOBAL is used
+// rather than SET PERSIST so a restart returns the server to its configured
+// retention even if the extension is never reverted.
+type ServerBinlogExpirySetter struct {
+	Connect Connector
+}
+
+func (s *ServerBinlogExpirySetter) SetBinlogExpireSeconds(ctx context.Context, host string, seconds int64) error {
+	if s.Connect == nil {
+		return fmt.Errorf("binlog expiry setter requires a connector")
+	}
+	q, err := s.Connect(ctx, host)
+	if err != nil {
+		return err
+	}
+	if _, err := q.ExecContext(ctx, "SET GLOBAL binlog_expire_logs_seconds = ?", seconds); err == nil {
+		return nil
+	}
+	days := (seconds + 24*60*60 - 1) / (24 * 60 * 60)
+	if _, err := q.ExecContext(ctx, "SET GLOBAL expire_logs_days = ?", days); err != nil {
+		return fmt.Errorf("failed to set binlog expiry: %w", err)
+	}
+	return nil
+}
+
+// BinlogRetentionExtension temporarily raises the primary's binlog expiry to
+// Margin times the maintenance window (default 2, matching the tight
+// threshold of BinlogRetentionCheck) so replicas and CDC paused for the
+// upgrade can catch up, and restores the original value afterwards. The
+// original expiry is checkpointed in State, so Extend and Revert are safe to
+// re-run across CLI invocations.
+type BinlogRetentionExtension struct {
+	Inspector BinlogRetentionInspector
+	Setter    BinlogExpirySetter
+	State     workflow.State
+	Primary   string
+	Window    time.Duration
+	Margin    int
+	Logger    *log.Logger
+}
+
+// Extend raises the expiry when it is shorter than the target. A server that
+// never purges binlogs, or already keeps enough, is left alone.
+func (e *BinlogRetentionExtension) Extend(ctx context.Context) (Summary, []Finding, error) {
+	var summary Summary
+	findings := []Finding{}
+	if err := e.validate(); err != nil {
+		return Summary{Block: 1}, []Finding{failureFinding(err)}, nil
+	}
+	target := int64(e.target() / time.Second)
+	meta := map[string]interface{}{"primary": e.Primary, "target_seconds": target}
+	if original, ok := e.original(); ok {
+		meta["original_seconds"] = original
+		findings = append(findings, Finding{Severity: SeverityInfo, Message: "binlog retention already extended on primary", Meta: meta})
+		applySummary(&summary, findings)
+		return summary, findings, nil
+	}
+
+	retention, err := e.Inspector.BinlogRetention(ctx, e.Primary)
+	if err != nil {
+		return appendFailure(summary, findings, failure.Inspect(e.Primary, "failed to read binlog retention", err))
+	}
+	meta["original_seconds"] = retention.ExpireSeconds
+	if retention.ExpireSeconds == 0 || retention.ExpireSeconds >= target {
+		findings = append(findings, Finding{Severity: SeverityInfo, Message: "binlog retention already covers the maintenance window; not extended", Meta: meta})
+		applySummary(&summary, findings)
+		return summary, findings, nil
+	}
+
+	e.logger().Printf("extending binlog retention on %s from %ds to %ds", e.Primary, retention.ExpireSeconds, target)
+	// Checkpoint before mutating so an interrupted run still knows what to
+	// restore.
+	e.State.Set(retentionOriginalKey(e.Primary), strconv.FormatInt(retention.ExpireSeconds, 10))
+	if err := e.Setter.SetBinlogExpireSeconds(ctx, e.Primary, target); err != nil {
+		return appendFailure(summary, findings, failure.Act(e.Primary, "failed to extend binlog retention", err))
+	}
+	// Record what the server actually applied: 5.7 rounds up to whole days.
+	applied := target
+	if after, err := e.Inspector.BinlogRetention(ctx, e.Primary); err == nil {
+		applied = after.ExpireSeconds
+	}
+	e.State.Set(retentionAppliedKey(e.Primary), strconv.FormatInt(applied, 10))
+	meta["applied_seconds"] = applied
+	findings = append(findings, Finding{Severity: SeverityInfo, Message: "binlog retention extended on primary", Meta: meta})
+	applySummary(&summary, findings)
+	return summary, findings, nil
+}
+
+// Revert restores the checkpointed expiry. If the expiry was changed by
+// someone else since Extend, it is left as found and a WARN is reported.
+func (e *BinlogRetentionExtension) Revert(ctx context.Context) (Summary, []Finding, error) {
+	var summary Summary
+	findings := []Finding{}
+	if err := e.validate(); err != nil {
+		return Summary{Block: 1}, []Finding{failureFinding(err)}, nil
+	}
+	target := int64(e.target() / time.Second)
+	meta := map[string]interface{}{"primary": e.Primary, "target_seconds": target}
+	original, ok := e.original()
+	if !ok {
+		findings = append(findings, Finding{Severity: SeverityInfo, Message: "binlog retention is not extended on primary", Meta: meta})
+		applySummary(&summary, findings)
+		return summary, findings, nil
+	}
+	meta["original_seconds"] = original
+
+	retention, err := e.Inspector.BinlogRetention(ctx, e.Primary)
+	if err != nil {
+		return appendFailure(summary, findings, failure.Inspect(e.Primary, "failed to read binlog retention", err))
+	}
+	if applied, ok := e.checkpoint(retentionAppliedKey(e.Primary)); ok && retention.ExpireSeconds != applied {
+		meta["current_seconds"] = retention.ExpireSeconds
+		e.State.Set(retentionOriginalKey(e.Primary), "")
+		findings = append(findings, Finding{Severity: SeverityWarn, Message: "binlog retention was changed since it was extended; leaving the current value", Meta: meta})
+		applySummary(&summary, findings)
+		return summary, findings, nil
+	}
+
+	e.logger().Printf("restoring binlog retention on %s to %ds", e.Primary, original)
+	if err := e.Setter.SetBinlogExpireSeconds(ctx, e.Primary, original); err != nil {
+		return appendFailure(summary, findings, failure.Act(e.Primary, "failed to restore binlog retention", err))
+	}
+	e.State.Set(retentionOriginalKey(e.Primary), "")
+	findings = append(findings, Finding{Severity: SeverityInfo, Message: "binlog retention restored on primary", Meta: meta})
+	applySummary(&summary, findings)
+	return summary, findings, nil
+}
+
+func (e *BinlogRetentionExtension) original() (int64, bool) {
+	return e.checkpoint(retentionOriginalKey(e.Primary))
+}
+
+func (e *BinlogRetentionExtension) checkpoint(key string) (int64, bool) {
+	raw, _ := e.State.Get(key)
+	encoded, _ := raw.(string)
+	if encoded == "" {
+		return 0, false
+	}
+	seconds, err := strconv.ParseInt(encoded, 10, 64)
+	return seconds, err == nil
+}
+
+func (e *BinlogRetentionExtension) target() time.Duration {
+	margin := e.Margin
+	if margin <= 0 {
+		margin = 2
+	}
+	return time.Duration(margin) * e.Window
+}
+
+func (e *BinlogRetentionExtension) validate() error {
+	e.Primary = strings.TrimSpace(e.Primary)
+	switch {
+	case e.Inspector == nil:
+		return failure.Config("binlog retention inspector is required")
+	case e.Setter == nil:
+		return failure.Config("binlog expiry setter is required")
+	case e.State == nil:
+		return failure.Config("state is required")
+	case e.Primary == "":
+		return failure.Config("primary is required")
+	case e.Window <= 0:
+		return failure.Config("maintenance window is required")
+	}
+	return nil
+}
+
+func (e *BinlogRetentionExtension) logger() *log.Logger {
+	if e.Logger == nil {
+		return log.Default()
+	}
+	return e.Logger
+}
+
+func retentionOriginalKey(primary string) string {
+	return fmt.Sprintf("binlog_retention:%s:original", primary)
+}
+
+func retentionAppliedKey(primary string) string {
+	return fmt.Sprintf("binlog_retention:%s:applied", primary)
+}
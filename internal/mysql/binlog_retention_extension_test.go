@@ -0,0 +1,91 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"migratorx/internal/workflow"
+)
+
+// fakeExpiryServer is both the inspector and the setter, so Extend and Revert
+// see their own changes.
+type fakeExpiryServer struct {
+	expire int64
+	sets   []int64
+}
+
+func (f *fakeExpiryServer) BinlogRetention(ctx context.Context, host string) (BinlogRetention, error) {
+	return BinlogRetention{ExpireSeconds: f.expire}, nil
+}
+
+func (f *fakeExpiryServer) SetBinlogExpireSeconds(ctx context.Context, host string, seconds int64) error {
+	f.expire = seconds
+	f.sets = append(f.sets, seconds)
+	return nil
+}
+
+func TestBinlogRetentionExtension_ExtendsAndRevertsOnce(t *testing.T) {
+	server := &fakeExpiryServer{expire: 3600}
+	state := workflow.NewMemoryState()
+	extension := &BinlogRetentionExtension{Inspector: server, Setter: server, State: state, Primary: "db-primary", Window: 4 * time.Hour}
+	for i := 0; i < 2; i++ {
+		summary, _, err := extension.Extend(context.Background())
+		if err != nil || summary.Block != 0 {
+			t.Fatalf("unexpected extend result: %+v %v", summary, err)
+		}
+	}
+	if len(server.sets) != 1 || server.expire != 8*3600 {
+		t.Fatalf("expected one extension to 8h, got %v", server.sets)
+	}
+
+	// A fresh extension sharing the state stands in for a later invocation.
+	extension = &BinlogRetentionExtension{Inspector: server, Setter: server, State: state, Primary: "db-primary", Window: 4 * time.Hour}
+	for i := 0; i < 2; i++ {
+		if _, _, err := extension.Revert(context.Background()); err != nil {
+			t.Fatalf("unexpected revert error: %v", err)
+		}
+	}
+	if len(server.sets) != 2 || server.expire != 3600 {
+		t.Fatalf("expected retention restored once to 1h, got %v", server.sets)
+	}
+}
+
+func TestBinlogRetentionExtension_LeavesSufficientRetention(t *testing.T) {
+	for _, expire := range []int64{0, 30 * 24 * 3600} {
+		server := &fakeExpiryServer{expire: expire}
+		extension := &BinlogRetentionExtension{Inspector: server, Setter: server, State: workflow.NewMemoryState(), Primary: "db-primary", Window: 4 * time.Hour}
+		if _, _, err := extension.Extend(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_, findings, _ := extension.Revert(context.Background())
+		if len(server.sets) != 0 || findings[0].Message != "binlog retention is not extended on primary" {
+			t.Fatalf("expected %d to be left alone, got sets %v and %+v", expire, server.sets, findings)
+		}
+	}
+}
+
+func TestBinlogRetentionExtension_KeepsOperatorChange(t *testing.T) {
+	server := &fakeExpiryServer{expire: 3600}
+	extension := &BinlogRetentionExtension{Inspector: server, Setter: server, State: workflow.NewMemoryState(), Primary: "db-primary", Window: time.Hour}
+	if _, _, err := extension.Extend(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	server.expire = 1800
+	summary, _, err := extension.Revert(context.Background())
+	if err != nil || summary.Warn != 1 || server.expire != 1800 {
+		t.Fatalf("expected a WARN and the operator's value kept, got %+v %v expire=%d", summary, err, server.expire)
+	}
+}
+
+func TestServerBinlogExpirySetter_FallsBackToDays(t *testing.T) {
+	db := openFakeDB(t,
+		fakeResponse{match: "binlog_expire_logs_seconds", err: errors.New("Unknown system variable")},
+		fakeResponse{match: "expire_logs_days"},
+	)
+	setter := &ServerBinlogExpirySetter{Connect: fakeConnector(db)}
+	if err := setter.SetBinlogExpireSeconds(context.Background(), "db-primary", 8*3600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"migratorx/internal/checks"
+)
+
+type fakeRetentionInspector struct {
+	retention BinlogRetention
+	err       error
+}
+
+func (f *fakeRetentionInspector) BinlogRetention(ctx context.Context, host string) (BinlogRetention, error) {
+	return f.retention, f.err
+}
+
+func TestBinlogRetentionCheck(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	weekAgo := now.Add(-7 * 24 * time.Hour)
+	hourAgo := now.Add(-time.Hour)
+	cases := []struct {
+		name      string
+		retention BinlogRetention
+		err       error
+		codes     []string
+	}{
+		{"ample", BinlogRetention{ExpireSeconds: 7 * 24 * 3600, EarliestEventAt: &weekAgo}, nil, []string{CodeBinlogRetentionOK}},
+		{"never expires", BinlogRetention{}, nil, []string{CodeBinlogRetentionOK}},
+		{"too short", BinlogRetention{ExpireSeconds: 2 * 3600}, nil, []string{CodeBinlogRetentionTooShort}},
+		{"tight", BinlogRetention{ExpireSeconds: 6 * 3600}, nil, []string{CodeBinlogRetentionTight}},
+		{"short history", BinlogRetention{ExpireSeconds: 7 * 24 * 3600, EarliestEventAt: &hourAgo}, nil, []string{CodeBinlogHistoryShort}},
+		{"unknown", BinlogRetention{}, errors.New("access denied"), []string{CodeBinlogRetentionUnknown}},
+	}
+	for _, tc := range cases {
+		check := &BinlogRetentionCheck{Inspector: &fakeRetentionInspector{retention: tc.retention, err: tc.err}, Window: 4 * time.Hour, Now: func() time.Time { return now }}
+		findings, err := check.Run(context.Background(), checks.Input{PrimaryHost: "mysql-primary"})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		if len(findings) != len(tc.codes) {
+			t.Fatalf("%s: expected %v, got %+v", tc.name, tc.codes, findings)
+		}
+		for i, code := range tc.codes {
+			if findings[i].Code != code {
+				t.Fatalf("%s: expected %v, got %+v", tc.name, tc.codes, findings)
+			}
+		}
+	}
+}
+
+func writeBinlog(t *testing.T, path string, started time.Time) {
+	b := append([]byte{}, binlogMagic...)
+	ts := make([]byte, 4)
+	binary.LittleEndian.PutUint32(ts, uint32(started.Unix()))
+	b = append(b, ts...)
+	b = append(b, 0x0f, 1, 0, 0, 0)
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		t.Fatalf("write binlog: %v", err)
+	}
+}
+
+func TestServerBinlogRetentionInspector_FallsBackAndDatesOldestBinlog(t *testing.T) {
+	dir := t.TempDir()
+	started := time.Date(2026, 10, 10, 8, 0, 0, 0, time.UTC)
+	writeBinlog(t, filepath.Join(dir, "mysql-bin.000007"), started)
+
+	db := openFakeDB(t,
+		fakeResponse{match: "binlog_expire_logs_seconds", err: errors.New("Unknown system variable")},
+		fakeResponse{match: "expire_logs_days", columns: []string{"expire_logs_days"}, rows: [][]driver.Value{{int64(7)}}},
+		fakeResponse{match: "gtid_purged", columns: []string{"gtid_purged"}, rows: [][]driver.Value{{"uuid:1-100"}}},
+		fakeResponse{match: "SHOW BINARY LOGS", columns: []string{"Log_name", "File_size"}, rows: [][]driver.Value{{"mysql-bin.000007", int64(4096)}, {"mysql-bin.000008", int64(154)}}},
+	)
+	inspector := &ServerBinlogRetentionInspector{Connect: fakeConnector(db), BinlogDir: dir}
+	r, err := inspector.BinlogRetention(context.Background(), "mysql-primary")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.ExpireSeconds != 7*24*3600 || r.GTIDPurged != "uuid:1-100" || r.EarliestEventAt == nil || !r.EarliestEventAt.Equal(started) {
+		t.Fatalf("unexpected retention %+v", r)
+	}
+}
+
+func TestReadBinlogStartTime_RejectsOtherFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("not a binlog"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := ReadBinlogStartTime(path); err == nil {
+		t.Fatalf("expected non-binlog file to be rejected")
+	}
+}
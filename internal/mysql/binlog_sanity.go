@@ -0,0 +1,157 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Binlog sanity probe defaults.
+const (
+	DefaultMarkerTable        = "migratorx.binlog_marker"
+	DefaultMarkerApplyTimeout = 30 * time.Second
+	DefaultMarkerPollInterval = 500 * time.Millisecond
+)
+
+// BinlogEvent describes one event from a server's binary log.
+type BinlogEvent struct {
+	File     string
+	Position int64
+	Type     string
+	GTID     string
+	ServerID uint32
+}
+
+// BinlogSanityProber writes a marker row on the primary and inspects how an
+// upgraded replica applied and re-logged it.
+type BinlogSanityProber interface {
+	WriteMarker(ctx context.Context, primary string, table string, token string) error
+	MarkerApplied(ctx context.Context, replica string, table string, token string) (bool, error)
+	// MarkerEvent finds the replica's own binlog event for the marker write.
+	MarkerEvent(ctx context.Context, replica string, table string, token string) (BinlogEvent, bool, error)
+	DeleteMarker(ctx context.Context, primary string, table string, token string) error
+}
+
+// BinlogSanityProbe proves replication end to end through an upgraded replica:
+// a controlled write on the primary must be applied by the replica and appear
+// in the replica's binlog as a well-formed row event, which chained replicas
+// and CDC consume.
+type BinlogSanityProbe struct {
+	Prober          BinlogSanityProber
+	Primary         string
+	Replica         string
+	Table           string
+	Token           string
+	PrimaryServerID uint32
+	ApplyTimeout    time.Duration
+	PollInterval    time.Duration
+	Logger          *log.Logger
+}
+
+// Run writes the marker and returns structured findings.
+// - BLOCK when the write fails or is not applied within ApplyTimeout
+// - BLOCK when the replica did not log the event or logged it in a non-row format
+// - WARN when the event's origin server ID differs from PrimaryServerID, or cleanup fails
+func (p *BinlogSanityProbe) Run(ctx context.Context) (summary Summary, findings []Finding, err error) {
+	if p.Prober == nil {
+		return Summary{}, nil, fmt.Errorf("binlog sanity prober is required")
+	}
+	if strings.TrimSpace(p.Primary) == "" || strings.TrimSpace(p.Replica) == "" {
+		return Summary{}, nil, fmt.Errorf("primary and replica are required")
+	}
+	if p.Logger == nil {
+		p.Logger = log.Default()
+	}
+	table := p.Table
+	if strings.TrimSpace(table) == "" {
+		table = DefaultMarkerTable
+	}
+	token := p.Token
+	if token == "" {
+		token = fmt.Sprintf("migratorx-%d", time.Now().UnixNano())
+	}
+	timeout := p.ApplyTimeout
+	if timeout <= 0 {
+		timeout = DefaultMarkerApplyTimeout
+	}
+	interval := p.PollInterval
+	if interval <= 0 {
+		interval = DefaultMarkerPollInterval
+	}
+
+	findings = []Finding{}
+	meta := map[string]interface{}{"primary": p.Primary, "replica": p.Replica, "table": table, "token": token}
+
+	p.Logger.Printf("writing binlog marker on %s", p.Primary)
+	if err := p.Prober.WriteMarker(ctx, p.Primary, table, token); err != nil {
+		return appendBlock(summary, findings, fmt.Sprintf("binlog marker write on %q failed: %v", p.Primary, err))
+	}
+	defer func() {
+		if cleanupErr := p.Prober.DeleteMarker(ctx, p.Primary, table, token); cleanupErr != nil {
+			warn := Finding{Severity: SeverityWarn, Message: fmt.Sprintf("binlog marker cleanup on %q failed: %v", p.Primary, cleanupErr), Meta: meta}
+			findings = append(findings, warn)
+			applySummary(&summary, []Finding{warn})
+		}
+	}()
+
+	applied, err := p.waitApplied(ctx, table, token, timeout, interval)
+	if err != nil {
+		return appendBlock(summary, findings, fmt.Sprintf("failed to read binlog marker on %q: %v", p.Replica, err))
+	}
+	if !applied {
+		return appendBlock(summary, findings, fmt.Sprintf("replica %q did not apply the binlog marker within %s", p.Replica, timeout))
+	}
+
+	event, found, err := p.Prober.MarkerEvent(ctx, p.Replica, table, token)
+	if err != nil {
+		return appendBlock(summary, findings, fmt.Sprintf("failed to read binlog events on %q: %v", p.Replica, err))
+	}
+	if !found {
+		block := Finding{Severity: SeverityBlock, Message: fmt.Sprintf("replica %q applied the marker but did not write it to its binlog; enable log_replica_updates for chained replicas and CDC", p.Replica), Meta: meta}
+		findings = append(findings, block)
+		applySummary(&summary, []Finding{block})
+		return summary, findings, nil
+	}
+
+	eventMeta := withMeta(withMeta(withMeta(meta, "binlog_file", event.File), "binlog_position", event.Position), "event_type", event.Type)
+	if event.File == "" || event.Position <= 0 {
+		block := Finding{Severity: SeverityBlock, Message: fmt.Sprintf("replica %q logged the marker without valid binlog coordinates", p.Replica), Meta: eventMeta}
+		findings = append(findings, block)
+		applySummary(&summary, []Finding{block})
+	}
+	if !strings.HasPrefix(event.Type, "Write_rows") {
+		block := Finding{Severity: SeverityBlock, Message: fmt.Sprintf("replica %q logged the marker as %q; row-based events (binlog_format=ROW) are required downstream", p.Replica, event.Type), Meta: eventMeta}
+		findings = append(findings, block)
+		applySummary(&summary, []Finding{block})
+	}
+	if p.PrimaryServerID != 0 && event.ServerID != p.PrimaryServerID {
+		warn := Finding{Severity: SeverityWarn, Message: fmt.Sprintf("marker event on %q carries server_id %d, expected origin %d", p.Replica, event.ServerID, p.PrimaryServerID), Meta: withMeta(eventMeta, "server_id", event.ServerID)}
+		findings = append(findings, warn)
+		applySummary(&summary, []Finding{warn})
+	}
+
+	if !hasBlock(findings) {
+		info := Finding{Severity: SeverityInfo, Message: fmt.Sprintf("replica %q applied and re-logged the marker at %s:%d", p.Replica, event.File, event.Position), Meta: withMeta(eventMeta, "gtid", event.GTID)}
+		findings = append(findings, info)
+		applySummary(&summary, []Finding{info})
+	}
+	return summary, findings, nil
+}
+
+func (p *BinlogSanityProbe) waitApplied(ctx context.Context, table string, token string, timeout time.Duration, interval time.Duration) (bool, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		applied, err := p.Prober.MarkerApplied(ctx, p.Replica, table, token)
+		if err != nil || applied {
+			return applied, err
+		}
+		if time.Now().After(deadline) {
+			return false, nil
+		}
+		if err := sleepContext(ctx, interval); err != nil {
+			return false, err
+		}
+	}
+}
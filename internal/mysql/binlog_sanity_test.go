@@ -0,0 +1,88 @@
+package mysql
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+	"time"
+)
+
+type fakeBinlogProber struct {
+	appliedAfter int
+	polls        int
+	event        BinlogEvent
+	found        bool
+	deleted      bool
+}
+
+func (f *fakeBinlogProber) WriteMarker(ctx context.Context, primary string, table string, token string) error {
+	return nil
+}
+
+func (f *fakeBinlogProber) MarkerApplied(ctx context.Context, replica string, table string, token string) (bool, error) {
+	f.polls++
+	return f.polls > f.appliedAfter, nil
+}
+
+func (f *fakeBinlogProber) MarkerEvent(ctx context.Context, replica string, table string, token string) (BinlogEvent, bool, error) {
+	return f.event, f.found, nil
+}
+
+func (f *fakeBinlogProber) DeleteMarker(ctx context.Context, primary string, table string, token string) error {
+	f.deleted = true
+	return nil
+}
+
+func newBinlogProbe(prober *fakeBinlogProber) *BinlogSanityProbe {
+	return &BinlogSanityProbe{
+		Prober:          prober,
+		Primary:         "mysql-primary",
+		Replica:         "mysql-replica-1",
+		PrimaryServerID: 1,
+		ApplyTimeout:    50 * time.Millisecond,
+		PollInterval:    time.Millisecond,
+		Logger:          log.New(io.Discard, "", 0),
+	}
+}
+
+func TestBinlogSanityProbe_RowEventInfo(t *testing.T) {
+	prober := &fakeBinlogProber{appliedAfter: 2, found: true, event: BinlogEvent{File: "binlog.000003", Position: 4711, Type: "Write_rows", ServerID: 1}}
+	summary, findings, err := newBinlogProbe(prober).Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Block != 0 || summary.Warn != 0 || summary.Info != 1 {
+		t.Fatalf("expected single INFO, got %+v %+v", summary, findings)
+	}
+	if !prober.deleted {
+		t.Fatalf("expected marker cleanup")
+	}
+}
+
+func TestBinlogSanityProbe_NotAppliedBlocks(t *testing.T) {
+	prober := &fakeBinlogProber{appliedAfter: 1 << 30}
+	summary, _, err := newBinlogProbe(prober).Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Block != 1 || !prober.deleted {
+		t.Fatalf("expected BLOCK and cleanup when marker never applies, got %+v", summary)
+	}
+}
+
+func TestBinlogSanityProbe_StatementEventBlocks(t *testing.T) {
+	prober := &fakeBinlogProber{found: true, event: BinlogEvent{File: "binlog.000003", Position: 4711, Type: "Query", ServerID: 1}}
+	summary, _, _ := newBinlogProbe(prober).Run(context.Background())
+	if summary.Block != 1 {
+		t.Fatalf("expected BLOCK for statement-based event, got %+v", summary)
+	}
+}
+
+func TestBinlogSanityProbe_MissingEventBlocks(t *testing.T) {
+	prober := &fakeBinlogProber{found: false}
+	summary, _, _ := newBinlogProbe(prober).Run(context.Background())
+	if summary.Block != 1 {
+		t.Fatalf("expected BLOCK when replica does not re-log the marker, got %+v", summary)
+	}
+}
@@ -0,0 +1,306 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"migratorx/internal/checks"
+	"migratorx/internal/failure"
+	"migratorx/internal/workflow"
+)
+
+// Buffer pool warmup defaults.
+const (
+	DefaultWarmupTimeout            = 30 * time.Minute
+	DefaultHitRatioSampleInterval   = 10 * time.Second
+	defaultBufferPoolLoadPollPeriod = 5 * time.Second
+)
+
+// BufferPoolStats are InnoDB's buffer pool status counters. ReadRequests and
+// DiskReads are cumulative since startup.
+type BufferPoolStats struct {
+	ReadRequests int64
+	DiskReads    int64
+	PagesData    int64
+	PagesTotal   int64
+	LoadStatus   string
+}
+
+// BufferPoolInspector reads buffer pool counters on a host.
+type BufferPoolInspector interface {
+	BufferPoolStats(ctx context.Context, host string) (BufferPoolStats, error)
+}
+
+// BufferPoolWarmup preloads the promotion candidate's buffer pool so the
+// first writes after cutover do not hit a cold cache. By default it loads the
+// pages listed in the replica's buffer pool dump (innodb_buffer_pool_load_now)
+// and waits for Innodb_buffer_pool_load_status to report completion; with
+// Config.Queries it runs those queries instead, in a read-only session. A
+// completed warmup is checkpointed in State.
+type BufferPoolWarmup struct {
+	Connect      Connector
+	State        workflow.State
+	Config       workflow.WarmupConfig
+	PollInterval time.Duration
+	Logger       *log.Logger
+}
+
+// Run warms the replica's buffer pool. A warmup that does not finish within
+// the timeout is a WARN and is retried on the next run.
+func (w *BufferPoolWarmup) Run(ctx context.Context, replica string) (Summary, []Finding, error) {
+	var summary Summary
+	findings := []Finding{}
+	replica = strings.TrimSpace(replica)
+	switch {
+	case replica == "":
+		return Summary{Block: 1}, []Finding{{Severity: SeverityBlock, Message: "replica is required"}}, nil
+	case w.Connect == nil:
+		return Summary{Block: 1}, []Finding{failureFinding(failure.Config("buffer pool warmup requires a connector"))}, nil
+	case w.State == nil:
+		return Summary{Block: 1}, []Finding{failureFinding(failure.Config("state is required"))}, nil
+	}
+	if ok, _ := getBool(w.State, warmedKey(replica)); ok {
+		findings = append(findings, Finding{Severity: SeverityInfo, Message: "buffer pool already warmed", Meta: map[string]interface{}{"replica": replica}})
+		applySummary(&summary, findings)
+		return summary, findings, nil
+	}
+	timeout := w.Config.Timeout
+	if timeout <= 0 {
+		timeout = DefaultWarmupTimeout
+	}
+	warmCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	q, err := w.Connect(ctx, replica)
+	if err != nil {
+		return appendFailure(summary, findings, failure.Inspect(replica, "failed to connect", err))
+	}
+	meta := map[string]interface{}{"replica": replica, "timeout": timeout.String()}
+	start := time.Now()
+	if len(w.Config.Queries) > 0 {
+		meta["method"] = "queries"
+		meta["queries"] = len(w.Config.Queries)
+		err = w.runQueries(warmCtx, q, replica)
+	} else {
+		meta["method"] = "innodb_buffer_pool_load_now"
+		err = w.loadDump(warmCtx, q, replica)
+	}
+	if err != nil {
+		if warmCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+			findings = append(findings, Finding{Severity: SeverityWarn, Message: fmt.Sprintf("buffer pool warmup did not finish within %s; the replica may still be cold at cutover", timeout), Meta: meta})
+			applySummary(&summary, findings)
+			return summary, findings, nil
+		}
+		return appendFailure(summary, findings, failure.Act(replica, "buffer pool warmup failed", err))
+	}
+	setBool(w.State, warmedKey(replica), true)
+	meta["duration"] = time.Since(start).Round(time.Second).String()
+	findings = append(findings, Finding{Severity: SeverityInfo, Message: "buffer pool warmed", Meta: meta})
+	applySummary(&summary, findings)
+	return summary, findings, nil
+}
+
+// runQueries runs the warmup queries and reads every row so the pages they
+// touch are loaded.
+func (w *BufferPoolWarmup) runQueries(ctx context.Context, q Querier, replica string) error {
+	session, err := NewReadOnlySession(ctx, q)
+	if err != nil {
+		return err
+	}
+	for _, query := range w.Config.Queries {
+		w.logger().Printf("warming %s: %s", replica, query)
+		rows, err := session.QueryContext(ctx, query)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadDump starts a load of the buffer pool dump and polls its status.
+func (w *BufferPoolWarmup) loadDump(ctx context.Context, q Querier, replica string) error {
+	w.logger().Printf("loading buffer pool dump on %s", replica)
+	if _, err := q.ExecContext(ctx, "SET GLOBAL innodb_buffer_pool_load_now = ON"); err != nil {
+		return err
+	}
+	poll := w.PollInterval
+	if poll <= 0 {
+		poll = defaultBufferPoolLoadPollPeriod
+	}
+	for {
+		var name, status string
+		if err := queryOne(ctx, q, "SHOW GLOBAL STATUS LIKE 'Innodb_buffer_pool_load_status'", nil, &name, &status); err != nil {
+			return err
+		}
+		lower := strings.ToLower(status)
+		switch {
+		case strings.Contains(lower, "completed"):
+			return nil
+		case strings.Contains(lower, "aborted"), strings.Contains(lower, "cannot"), strings.Contains(lower, "error"):
+			return fmt.Errorf("buffer pool load failed: %s (set warmup.queries if the replica has no buffer pool dump)", status)
+		}
+		if err := sleepContext(ctx, poll); err != nil {
+			return err
+		}
+	}
+}
+
+func (w *BufferPoolWarmup) logger() *log.Logger {
+	if w.Logger == nil {
+		return log.Default()
+	}
+	return w.Logger
+}
+
+func warmedKey(replica string) string { return fmt.Sprintf("buffer_pool_warmup:%s:warmed", replica) }
+
+// BufferPoolHitRatioCheck measures the promotion candidate's buffer pool hit
+// ratio over SampleInterval and blocks cutover while it is below MinHitRatio:
+// a cold cache turns the first minutes after promotion into a latency spike.
+// The ratio is taken from the change in Innodb_buffer_pool_read_requests and
+// Innodb_buffer_pool_reads, so reads made while warming up do not drag it
+// down once the cache is warm.
+type BufferPoolHitRatioCheck struct {
+	Inspector      BufferPoolInspector
+	Replica        string
+	MinHitRatio    float64
+	SampleInterval time.Duration
+}
+
+func (c *BufferPoolHitRatioCheck) Name() string   { return "buffer_pool_hit_ratio" }
+func (c *BufferPoolHitRatioCheck) ReadOnly() bool { return true }
+
+func (c *BufferPoolHitRatioCheck) Parameters() map[string]interface{} {
+	return map[string]interface{}{"replica": c.Replica, "min_hit_ratio": c.MinHitRatio, "sample_interval": c.SampleInterval.String()}
+}
+
+func (c *BufferPoolHitRatioCheck) Run(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+	if c.Inspector == nil {
+		return nil, fmt.Errorf("buffer pool inspector is required")
+	}
+	replica := strings.TrimSpace(c.Replica)
+	if replica == "" {
+		replica = input.ReplicaHost
+	}
+	if replica == "" {
+		return nil, fmt.Errorf("replica is required")
+	}
+	interval := c.SampleInterval
+	if interval <= 0 {
+		interval = DefaultHitRatioSampleInterval
+	}
+
+	unknown := func(err error) []checks.Finding {
+		return []checks.Finding{{
+			Severity: checks.SeverityWarn,
+			Code:     CodeBufferPoolHitRatioUnknown,
+			Message:  fmt.Sprintf("unable to measure the buffer pool hit ratio on %q: %v", replica, err),
+			Meta:     map[string]interface{}{"replica": replica},
+		}}
+	}
+	before, err := c.Inspector.BufferPoolStats(ctx, replica)
+	if err != nil {
+		return unknown(err), nil
+	}
+	if err := sleepContext(ctx, interval); err != nil {
+		return nil, err
+	}
+	after, err := c.Inspector.BufferPoolStats(ctx, replica)
+	if err != nil {
+		return unknown(err), nil
+	}
+
+	requests := after.ReadRequests - before.ReadRequests
+	meta := map[string]interface{}{"replica": replica, "min_hit_ratio": c.MinHitRatio, "sample_interval": interval.String(), "read_requests": requests, "load_status": after.LoadStatus}
+	if after.PagesTotal > 0 {
+		meta["pages_data_ratio"] = float64(after.PagesData) / float64(after.PagesTotal)
+	}
+	if requests <= 0 {
+		return []checks.Finding{{
+			Severity: checks.SeverityWarn,
+			Code:     CodeBufferPoolHitRatioUnknown,
+			Message:  fmt.Sprintf("no buffer pool reads on %q during %s; run the warmup or let traffic reach the replica to measure its hit ratio", replica, interval),
+			Meta:     meta,
+		}}, nil
+	}
+	ratio := 1 - float64(after.DiskReads-before.DiskReads)/float64(requests)
+	if ratio < 0 {
+		ratio = 0
+	}
+	meta["hit_ratio"] = ratio
+	if ratio < c.MinHitRatio {
+		return []checks.Finding{{
+			Severity: checks.SeverityBlock,
+			Code:     CodeBufferPoolCold,
+			Message:  fmt.Sprintf("buffer pool hit ratio on %q is %.4f, below %.4f; warm the buffer pool before cutover", replica, ratio, c.MinHitRatio),
+			Meta:     meta,
+		}}, nil
+	}
+	return []checks.Finding{{
+		Severity: checks.SeverityInfo,
+		Code:     CodeBufferPoolWarm,
+		Message:  fmt.Sprintf("buffer pool hit ratio on %q is %.4f", replica, ratio),
+		Meta:     meta,
+	}}, nil
+}
+
+var bufferPoolStatusNames = []string{
+	"Innodb_buffer_pool_read_requests",
+	"Innodb_buffer_pool_reads",
+	"Innodb_buffer_pool_pages_data",
+	"Innodb_buffer_pool_pages_total",
+	"Innodb_buffer_pool_load_status",
+}
+
+// StatusBufferPoolInspector implements BufferPoolInspector with SHOW GLOBAL
+// STATUS.
+type StatusBufferPoolInspector struct {
+	Connect Connector
+}
+
+func (i *StatusBufferPoolInspector) BufferPoolStats(ctx context.Context, host string) (BufferPoolStats, error) {
+	var stats BufferPoolStats
+	if i.Connect == nil {
+		return stats, fmt.Errorf("buffer pool inspector requires a connector")
+	}
+	q, err := i.Connect(ctx, host)
+	if err != nil {
+		return stats, err
+	}
+	rows, err := q.QueryContext(ctx, "SHOW GLOBAL STATUS WHERE Variable_name IN ('"+strings.Join(bufferPoolStatusNames, "', '")+"')")
+	if err != nil {
+		return stats, fmt.Errorf("failed to read buffer pool status: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			return stats, err
+		}
+		n, _ := strconv.ParseInt(value, 10, 64)
+		switch name {
+		case "Innodb_buffer_pool_read_requests":
+			stats.ReadRequests = n
+		case "Innodb_buffer_pool_reads":
+			stats.DiskReads = n
+		case "Innodb_buffer_pool_pages_data":
+			stats.PagesData = n
+		case "Innodb_buffer_pool_pages_total":
+			stats.PagesTotal = n
+		case "Innodb_buffer_pool_load_status":
+			stats.LoadStatus = value
+		}
+	}
+	return stats, rows.Err()
+}
@@ -0,0 +1,119 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log"
+	"sync"
+	"testing"
+	"time"
+
+	"migratorx/internal/checks"
+	"migratorx/internal/workflow"
+)
+
+type fakeBufferPoolInspector struct {
+	mu      sync.Mutex
+	samples []BufferPoolStats
+	err     error
+}
+
+func (f *fakeBufferPoolInspector) BufferPoolStats(ctx context.Context, host string) (BufferPoolStats, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return BufferPoolStats{}, f.err
+	}
+	sample := f.samples[0]
+	if len(f.samples) > 1 {
+		f.samples = f.samples[1:]
+	}
+	return sample, nil
+}
+
+func TestBufferPoolWarmup_LoadsDumpUntilCompleted(t *testing.T) {
+	db := openFakeDB(t,
+		fakeResponse{match: "innodb_buffer_pool_load_now"},
+		fakeResponse{match: "Innodb_buffer_pool_load_status", columns: []string{"Variable_name", "Value"}, rows: [][]driver.Value{{"Innodb_buffer_pool_load_status", "Buffer pool(s) load completed at 260101 10:00:00"}}},
+	)
+	state := workflow.NewMemoryState()
+	warmup := &BufferPoolWarmup{Connect: fakeConnector(db), State: state, PollInterval: time.Millisecond, Logger: log.New(io.Discard, "", 0)}
+	summary, findings, err := warmup.Run(context.Background(), "replica-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Block != 0 || findings[0].Meta["method"] != "innodb_buffer_pool_load_now" {
+		t.Fatalf("expected the dump to be loaded, got %+v", findings)
+	}
+	if _, findings, _ = warmup.Run(context.Background(), "replica-1"); findings[0].Message != "buffer pool already warmed" {
+		t.Fatalf("expected the checkpoint to skip a second warmup, got %+v", findings)
+	}
+}
+
+func TestBufferPoolWarmup_MissingDumpBlocks(t *testing.T) {
+	db := openFakeDB(t,
+		fakeResponse{match: "innodb_buffer_pool_load_now"},
+		fakeResponse{match: "Innodb_buffer_pool_load_status", columns: []string{"Variable_name", "Value"}, rows: [][]driver.Value{{"Innodb_buffer_pool_load_status", "Cannot open '/var/lib/mysql/ib_buffer_pool' for reading"}}},
+	)
+	warmup := &BufferPoolWarmup{Connect: fakeConnector(db), State: workflow.NewMemoryState(), PollInterval: time.Millisecond, Logger: log.New(io.Discard, "", 0)}
+	summary, findings, _ := warmup.Run(context.Background(), "replica-1")
+	if summary.Block != 1 || findings[0].Meta["cause"] != "action" {
+		t.Fatalf("expected a missing dump to block, got %+v", findings)
+	}
+}
+
+func TestBufferPoolWarmup_QueriesRunReadOnly(t *testing.T) {
+	db := openFakeDB(t,
+		fakeResponse{match: "SET SESSION TRANSACTION READ ONLY"},
+		fakeResponse{match: "SELECT", columns: []string{"c"}, rows: [][]driver.Value{{int64(1)}}},
+	)
+	warmup := &BufferPoolWarmup{Connect: fakeConnector(db), State: workflow.NewMemoryState(), Config: workflow.WarmupConfig{Queries: []string{"SELECT COUNT(*) FROM shop.orders", "DELETE FROM shop.orders"}}, Logger: log.New(io.Discard, "", 0)}
+	summary, findings, _ := warmup.Run(context.Background(), "replica-1")
+	if summary.Block != 1 {
+		t.Fatalf("expected a writing warmup query to be rejected, got %+v", findings)
+	}
+}
+
+func TestBufferPoolHitRatioCheck(t *testing.T) {
+	cases := []struct {
+		name     string
+		samples  []BufferPoolStats
+		err      error
+		severity checks.Severity
+		code     string
+	}{
+		{"warm", []BufferPoolStats{{ReadRequests: 1000, DiskReads: 500}, {ReadRequests: 11000, DiskReads: 510}}, nil, checks.SeverityInfo, CodeBufferPoolWarm},
+		{"cold", []BufferPoolStats{{ReadRequests: 1000, DiskReads: 500}, {ReadRequests: 2000, DiskReads: 900}}, nil, checks.SeverityBlock, CodeBufferPoolCold},
+		{"idle", []BufferPoolStats{{ReadRequests: 1000}}, nil, checks.SeverityWarn, CodeBufferPoolHitRatioUnknown},
+		{"unreadable", nil, errors.New("access denied"), checks.SeverityWarn, CodeBufferPoolHitRatioUnknown},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			check := &BufferPoolHitRatioCheck{Inspector: &fakeBufferPoolInspector{samples: tc.samples, err: tc.err}, Replica: "replica-1", MinHitRatio: 0.99, SampleInterval: time.Millisecond}
+			findings, err := check.Run(context.Background(), checks.Input{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(findings) != 1 || findings[0].Severity != tc.severity || findings[0].Code != tc.code {
+				t.Fatalf("expected %s %s, got %+v", tc.severity, tc.code, findings)
+			}
+		})
+	}
+}
+
+func TestStatusBufferPoolInspector_ReadsCounters(t *testing.T) {
+	db := openFakeDB(t, fakeResponse{match: "SHOW GLOBAL STATUS", columns: []string{"Variable_name", "Value"}, rows: [][]driver.Value{
+		{"Innodb_buffer_pool_read_requests", "9000"},
+		{"Innodb_buffer_pool_reads", "90"},
+		{"Innodb_buffer_pool_load_status", "Buffer pool(s) load completed"},
+	}})
+	stats, err := (&StatusBufferPoolInspector{Connect: fakeConnector(db)}).BufferPoolStats(context.Background(), "replica-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.ReadRequests != 9000 || stats.DiskReads != 90 || stats.LoadStatus != "Buffer pool(s) load completed" {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
@@ -0,0 +1,160 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"migratorx/internal/checks"
+	"migratorx/internal/workflow"
+)
+
+// DefaultAuthPlugin80 is MySQL 8.0's default_authentication_plugin.
+const DefaultAuthPlugin80 = "caching_sha2_password"
+
+// SecuritySettings are the server settings a client handshake depends on.
+// TLSVersions is comma-separated and Ciphers colon-separated, as MySQL
+// reports them; empty means the server default.
+type SecuritySettings struct {
+	TLSVersions       string
+	Ciphers           string
+	DefaultAuthPlugin string
+	FIPSMode          string
+}
+
+// SecuritySettingsInspector reads a server's handshake settings.
+type SecuritySettingsInspector interface {
+	SecuritySettings(ctx context.Context, host string) (SecuritySettings, error)
+}
+
+// ClientCompatibilityCheck blocks when the upgraded server's TLS versions,
+// ciphers or default authentication plugin leave no overlap with what a client
+// in the plan's clients: section supports, i.e. the connector would fail to
+// connect after the upgrade.
+type ClientCompatibilityCheck struct {
+	Inspector SecuritySettingsInspector
+	Host      string
+	Clients   []workflow.ClientConfig
+}
+
+func (c *ClientCompatibilityCheck) Name() string   { return "client_compatibility" }
+func (c *ClientCompatibilityCheck) ReadOnly() bool { return true }
+
+func (c *ClientCompatibilityCheck) Parameters() map[string]interface{} {
+	names := []string{}
+	for _, client := range c.Clients {
+		names = append(names, client.Name)
+	}
+	return map[string]interface{}{"host": c.Host, "clients": names}
+}
+
+func (c *ClientCompatibilityCheck) Run(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+	if c.Inspector == nil {
+		return nil, fmt.Errorf("security settings inspector is required")
+	}
+	host := strings.TrimSpace(c.Host)
+	if host == "" {
+		host = input.ReplicaHost
+	}
+	if host == "" {
+		return nil, fmt.Errorf("host is required")
+	}
+	settings, err := c.Inspector.SecuritySettings(ctx, host)
+	if err != nil {
+		return []checks.Finding{{
+			Severity: checks.SeverityWarn,
+			Code:     CodeClientCompatUnknown,
+			Message:  fmt.Sprintf("unable to read TLS and authentication settings on %q: %v", host, err),
+			Meta:     map[string]interface{}{"host": host},
+		}}, nil
+	}
+	plugin := settings.DefaultAuthPlugin
+	if plugin == "" {
+		plugin = DefaultAuthPlugin80
+	}
+	serverTLS := splitList(settings.TLSVersions, ",")
+	serverCiphers := splitList(settings.Ciphers, ":")
+
+	findings := []checks.Finding{}
+	for _, client := range c.Clients {
+		meta := map[string]interface{}{"host": host, "client": client.Name, "driver": client.Driver, "fips_mode": settings.FIPSMode}
+		if len(serverTLS) > 0 && len(client.TLSVersions) > 0 && !overlaps(serverTLS, client.TLSVersions) {
+			findings = append(findings, checks.Finding{
+				Severity: checks.SeverityBlock,
+				Code:     CodeClientTLSIncompatible,
+				Message:  fmt.Sprintf("client %q supports %s but %q only allows %s", client.Name, strings.Join(client.TLSVersions, ","), host, settings.TLSVersions),
+				Meta:     withMeta(withMeta(meta, "server", serverTLS), "supported", client.TLSVersions),
+			})
+		}
+		if len(serverCiphers) > 0 && len(client.Ciphers) > 0 && !overlaps(serverCiphers, client.Ciphers) {
+			findings = append(findings, checks.Finding{
+				Severity: checks.SeverityBlock,
+				Code:     CodeClientCipherIncompatible,
+				Message:  fmt.Sprintf("client %q shares no TLS cipher with %q", client.Name, host),
+				Meta:     withMeta(withMeta(meta, "server", serverCiphers), "supported", client.Ciphers),
+			})
+		}
+		if len(client.AuthPlugins) > 0 && !containsFold(client.AuthPlugins, plugin) {
+			findings = append(findings, checks.Finding{
+				Severity: checks.SeverityBlock,
+				Code:     CodeClientAuthPluginUnsupported,
+				Message:  fmt.Sprintf("client %q does not support %s, the default authentication plugin on %q", client.Name, plugin, host),
+				Meta:     withMeta(withMeta(meta, "server", plugin), "supported", client.AuthPlugins),
+			})
+		}
+	}
+	if len(findings) == 0 {
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityInfo,
+			Code:     CodeClientCompatOK,
+			Message:  fmt.Sprintf("%d clients can negotiate TLS and authentication with %q", len(c.Clients), host),
+			Meta:     map[string]interface{}{"host": host, "clients": len(c.Clients), "default_authentication_plugin": plugin, "fips_mode": settings.FIPSMode},
+		})
+	}
+	return findings, nil
+}
+
+func splitList(value string, sep string) []string {
+	out := []string{}
+	for _, v := range strings.Split(value, sep) {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func overlaps(a []string, b []string) bool {
+	for _, v := range a {
+		if containsFold(b, v) {
+			return true
+		}
+	}
+	return false
+}
+
+const securitySettingsQuery = `SELECT @@GLOBAL.tls_version, @@GLOBAL.ssl_cipher, @@GLOBAL.default_authentication_plugin, @@GLOBAL.ssl_fips_mode`
+
+// ServerSecurityInspector implements SecuritySettingsInspector with global
+// variables. ssl_fips_mode needs 8.0; use it against the upgraded replica.
+type ServerSecurityInspector struct {
+	Connect Connector
+}
+
+func (i *ServerSecurityInspector) SecuritySettings(ctx context.Context, host string) (SecuritySettings, error) {
+	if i.Connect == nil {
+		return SecuritySettings{}, fmt.Errorf("security settings inspector requires a connector")
+	}
+	q, err := i.Connect(ctx, host)
+	if err != nil {
+		return SecuritySettings{}, err
+	}
+	var s SecuritySettings
+	var ciphers sql.NullString
+	if err := queryOne(ctx, q, securitySettingsQuery, nil, &s.TLSVersions, &ciphers, &s.DefaultAuthPlugin, &s.FIPSMode); err != nil {
+		return SecuritySettings{}, fmt.Errorf("failed to read TLS and authentication settings: %w", err)
+	}
+	s.Ciphers = ciphers.String
+	return s, nil
+}
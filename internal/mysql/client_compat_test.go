@@ -0,0 +1,67 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"migratorx/internal/checks"
+	"migratorx/internal/workflow"
+)
+
+type fakeSecurityInspector struct {
+	settings SecuritySettings
+}
+
+func (f *fakeSecurityInspector) SecuritySettings(ctx context.Context, host string) (SecuritySettings, error) {
+	return f.settings, nil
+}
+
+func TestClientCompatibilityCheck_BlocksLegacyConnector(t *testing.T) {
+	inspector := &fakeSecurityInspector{settings: SecuritySettings{TLSVersions: "TLSv1.2,TLSv1.3", Ciphers: "ECDHE-RSA-AES128-GCM-SHA256:ECDHE-RSA-AES256-GCM-SHA384"}}
+	check := &ClientCompatibilityCheck{Inspector: inspector, Clients: []workflow.ClientConfig{
+		{Name: "billing", Driver: "mysql-connector-java 5.1", TLSVersions: []string{"TLSv1", "TLSv1.1"}, Ciphers: []string{"AES256-SHA"}, AuthPlugins: []string{"mysql_native_password"}},
+		{Name: "api", Driver: "go-sql-driver 1.7", TLSVersions: []string{"TLSv1.2", "TLSv1.3"}, AuthPlugins: []string{"mysql_native_password", "caching_sha2_password"}},
+	}}
+	findings, err := check.Run(context.Background(), checks.Input{ReplicaHost: "mysql-replica-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	codes := map[string]string{}
+	for _, f := range findings {
+		if f.Severity != checks.SeverityBlock {
+			t.Fatalf("expected only BLOCK findings, got %+v", f)
+		}
+		codes[f.Code] = f.Meta["client"].(string)
+	}
+	for _, code := range []string{CodeClientTLSIncompatible, CodeClientCipherIncompatible, CodeClientAuthPluginUnsupported} {
+		if codes[code] != "billing" {
+			t.Fatalf("expected %s for billing, got %+v", code, findings)
+		}
+	}
+	if len(findings) != 3 {
+		t.Fatalf("expected api client to be compatible, got %+v", findings)
+	}
+}
+
+func TestClientCompatibilityCheck_CompatibleClientsPass(t *testing.T) {
+	inspector := &fakeSecurityInspector{settings: SecuritySettings{TLSVersions: "TLSv1.2", DefaultAuthPlugin: "mysql_native_password"}}
+	check := &ClientCompatibilityCheck{Inspector: inspector, Host: "mysql-replica-1", Clients: []workflow.ClientConfig{
+		{Name: "billing", AuthPlugins: []string{"mysql_native_password"}},
+	}}
+	findings, _ := check.Run(context.Background(), checks.Input{})
+	if len(findings) != 1 || findings[0].Code != CodeClientCompatOK {
+		t.Fatalf("expected CLIENT_COMPAT_OK, got %+v", findings)
+	}
+}
+
+func TestServerSecurityInspector_ReadsSettings(t *testing.T) {
+	db := openFakeDB(t,
+		fakeResponse{match: "default_authentication_plugin", columns: []string{"tls_version", "ssl_cipher", "default_authentication_plugin", "ssl_fips_mode"}, rows: [][]driver.Value{{"TLSv1.2,TLSv1.3", nil, "caching_sha2_password", "OFF"}}},
+	)
+	inspector := &ServerSecurityInspector{Connect: fakeConnector(db)}
+	s, err := inspector.SecuritySettings(context.Background(), "mysql-replica-1")
+	if err != nil || s.TLSVersions != "TLSv1.2,TLSv1.3" || s.Ciphers != "" || s.DefaultAuthPlugin != "caching_sha2_password" || s.FIPSMode != "OFF" {
+		t.Fatalf("unexpected settings %+v (%v)", s, err)
+	}
+}
@@ -0,0 +1,87 @@
+package mysql
+
+// Finding codes emitted by MySQL checks.
+const (
+	CodeReplicaTrafficUnavailable        = "REPLICA_TRAFFIC_UNAVAILABLE"
+	CodeReplicaServingReads              = "REPLICA_SERVING_READS"
+	CodeReplicaTrafficDrained            = "REPLICA_TRAFFIC_DRAINED"
+	CodeDDLBaselineMissing               = "DDL_FREEZE_BASELINE_MISSING"
+	CodeDDLDriftNone                     = "DDL_DRIFT_NONE"
+	CodeDDLDriftDetected                 = "DDL_DRIFT_DETECTED"
+	CodeReplicaLagOK                     = "REPLICA_LAG_OK"
+	CodeReplicaLagExceeded               = "REPLICA_LAG_EXCEEDED"
+	CodeReplicaLagUnknown                = "REPLICA_LAG_UNKNOWN"
+	CodeOldPrimarySoakOK                 = "OLD_PRIMARY_SOAK_OK"
+	CodeOldPrimaryWritable               = "OLD_PRIMARY_WRITABLE"
+	CodeOldPrimaryWrites                 = "OLD_PRIMARY_WRITES_DETECTED"
+	CodeOldPrimaryNotSuperReadOnly       = "OLD_PRIMARY_NOT_SUPER_READ_ONLY"
+	CodeOldPrimarySoakUnknown            = "OLD_PRIMARY_SOAK_UNKNOWN"
+	CodeReplicationTLSOK                 = "REPLICATION_TLS_OK"
+	CodeReplicationTLSDisabled           = "REPLICATION_TLS_DISABLED"
+	CodeReplicationTLSUnverified         = "REPLICATION_TLS_UNVERIFIED"
+	CodeReplicationTLSVersionUnsupported = "REPLICATION_TLS_VERSION_UNSUPPORTED"
+	CodeReplicationTLSLegacyVersion      = "REPLICATION_TLS_LEGACY_VERSION"
+	CodeReplicationTLSCertExpired        = "REPLICATION_TLS_CERT_EXPIRED"
+	CodeReplicationTLSCertExpiring       = "REPLICATION_TLS_CERT_EXPIRING"
+	CodeReplicationTLSUnknown            = "REPLICATION_TLS_UNKNOWN"
+	CodeClientCompatOK                   = "CLIENT_COMPAT_OK"
+	CodeClientTLSIncompatible            = "CLIENT_TLS_INCOMPATIBLE"
+	CodeClientCipherIncompatible         = "CLIENT_CIPHER_INCOMPATIBLE"
+	CodeClientAuthPluginUnsupported      = "CLIENT_AUTH_PLUGIN_UNSUPPORTED"
+	CodeClientCompatUnknown              = "CLIENT_COMPAT_UNKNOWN"
+	CodeOrphanTablesNone                 = "ORPHAN_TEMP_TABLES_NONE"
+	CodeOrphanTablesFound                = "ORPHAN_TEMP_TABLES_FOUND"
+	CodeOrphanTablesUnknown              = "ORPHAN_TEMP_TABLES_UNKNOWN"
+	CodeDictionaryReady                  = "DICTIONARY_READY"
+	CodeDictionaryFilePerTableOff        = "DICTIONARY_FILE_PER_TABLE_OFF"
+	CodeDictionarySharedTablespace       = "DICTIONARY_SHARED_TABLESPACE"
+	CodeDictionaryPartitionShared        = "DICTIONARY_PARTITION_IN_SHARED_TABLESPACE"
+	CodeDictionaryOrphanFrm              = "DICTIONARY_ORPHAN_FRM"
+	CodeDictionaryUnknown                = "DICTIONARY_READINESS_UNKNOWN"
+	CodeServerIdentityOK                 = "SERVER_IDENTITY_OK"
+	CodeServerIdentityChanged            = "SERVER_IDENTITY_CHANGED"
+	CodeServerIdentityUnknown            = "SERVER_IDENTITY_UNKNOWN"
+	CodeServerIDUnset                    = "SERVER_ID_UNSET"
+	CodeServerIDDuplicate                = "SERVER_ID_DUPLICATE"
+	CodeServerUUIDDuplicate              = "SERVER_UUID_DUPLICATE"
+	CodeCDCServerIDCollision             = "CDC_SERVER_ID_COLLISION"
+	CodeBinlogRetentionOK                = "BINLOG_RETENTION_OK"
+	CodeBinlogRetentionTooShort          = "BINLOG_RETENTION_TOO_SHORT"
+	CodeBinlogRetentionTight             = "BINLOG_RETENTION_TIGHT"
+	CodeBinlogHistoryShort               = "BINLOG_HISTORY_SHORT"
+	CodeBinlogRetentionUnknown           = "BINLOG_RETENTION_UNKNOWN"
+	CodeConnectedAppsInventory           = "CONNECTED_APPS_INVENTORY"
+	CodeConnectedAppUnknown              = "CONNECTED_APP_UNKNOWN"
+	CodeConnectedAppsUnknown             = "CONNECTED_APPS_UNKNOWN"
+	CodeProxySQLRoutingOK                = "PROXYSQL_ROUTING_OK"
+	CodeProxySQLRoutingUnknown           = "PROXYSQL_ROUTING_UNKNOWN"
+	CodeProxySQLWriterUnknown            = "PROXYSQL_WRITER_HOSTGROUP_UNKNOWN"
+	CodeProxySQLWriteRuleToReader        = "PROXYSQL_WRITE_RULE_TO_READER"
+	CodeProxySQLDefaultNotWriter         = "PROXYSQL_DEFAULT_HOSTGROUP_NOT_WRITER"
+	CodeProxySQLTargetMissing            = "PROXYSQL_TARGET_MISSING"
+	CodeProxySQLNoReclassification       = "PROXYSQL_TARGET_NOT_RECLASSIFIED"
+	CodeProxySQLUnexpectedWriter         = "PROXYSQL_UNEXPECTED_WRITER"
+	CodeBufferPoolWarm                   = "BUFFER_POOL_WARM"
+	CodeBufferPoolCold                   = "BUFFER_POOL_COLD"
+	CodeBufferPoolHitRatioUnknown        = "BUFFER_POOL_HIT_RATIO_UNKNOWN"
+	CodeConfigAuditOK                    = "CONFIG_AUDIT_OK"
+	CodeConfigVariableRemoved            = "CONFIG_VARIABLE_REMOVED"
+	CodeConfigVariableRemovedLoose       = "CONFIG_VARIABLE_REMOVED_LOOSE"
+	CodeConfigAuditUnknown               = "CONFIG_AUDIT_UNKNOWN"
+	CodeAutoIncrementOK                  = "AUTO_INCREMENT_PARITY_OK"
+	CodeAutoIncrementBehind              = "AUTO_INCREMENT_BEHIND"
+	CodeFulltextNone                     = "FULLTEXT_NONE"
+	CodeFulltextOK                       = "FULLTEXT_SETTINGS_OK"
+	CodeFulltextSettingMismatch          = "FULLTEXT_SETTING_MISMATCH"
+	CodeFulltextParserMissing            = "FULLTEXT_PARSER_MISSING"
+	CodeCollationDefaultUnchanged        = "COLLATION_DEFAULT_UNCHANGED"
+	CodeCollationDefaultUnused           = "COLLATION_DEFAULT_UNUSED"
+	CodeCollationDefaultChanged          = "COLLATION_DEFAULT_CHANGED"
+	CodeCollationJoinAtRisk              = "COLLATION_JOIN_AT_RISK"
+	CodeHeartbeatOK                      = "HEARTBEAT_OK"
+	CodeHeartbeatFailed                  = "HEARTBEAT_FAILED"
+	CodeHeartbeatWrongPrimary            = "HEARTBEAT_WRONG_PRIMARY"
+	CodeHeartbeatRowMissing              = "HEARTBEAT_ROW_MISSING"
+	CodeHeartbeatCleanupFailed           = "HEARTBEAT_CLEANUP_FAILED"
+	CodeHeartbeatSkipped                 = "HEARTBEAT_SKIPPED"
+)
@@ -0,0 +1,232 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"migratorx/internal/checks"
+)
+
+const (
+	// mysql57DefaultCollation is collation_server when 5.7 is left unconfigured.
+	mysql57DefaultCollation = "latin1_swedish_ci"
+	// mysql80DefaultCollation is collation_server when 8.0 is left unconfigured.
+	mysql80DefaultCollation = "utf8mb4_0900_ai_ci"
+)
+
+// CollationDefaults are a server's default character set and collation and
+// each database's default collation.
+type CollationDefaults struct {
+	ServerCharset   string
+	ServerCollation string
+	Databases       map[string]string
+}
+
+// CollationDefaultsInspector reads a server's default collations.
+type CollationDefaultsInspector interface {
+	CollationDefaults(ctx context.Context, host string) (CollationDefaults, error)
+}
+
+// CollationDefaultsCheck warns when the upgraded replica's server default
+// collation differs from the primary's, as it does when 8.0 is left at its
+// default utf8mb4_0900_ai_ci and 5.7 ran with latin1_swedish_ci. Existing
+// objects keep their collation, but databases, tables and columns created
+// after promotion without an explicit one get the new default. The check lists
+// the databases and tables using the primary's default, since those were
+// created relying on it, and warns per table about its join columns (primary
+// key, foreign key and referenced columns) in that collation: joining them
+// with columns of new tables fails with "Illegal mix of collations" when both
+// charsets are Unicode, and otherwise converts the column and skips its index.
+// A host that reports no server collation is assumed to run its version's
+// default.
+type CollationDefaultsCheck struct {
+	Inspector       CollationDefaultsInspector
+	SchemaInspector checks.SchemaInspector
+	Primary         string
+	Replica         string
+}
+
+func (c *CollationDefaultsCheck) Name() string   { return "default_collation_change" }
+func (c *CollationDefaultsCheck) ReadOnly() bool { return true }
+
+func (c *CollationDefaultsCheck) Parameters() map[string]interface{} {
+	return map[string]interface{}{"primary": c.Primary, "replica": c.Replica}
+}
+
+func (c *CollationDefaultsCheck) Run(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+	if c.Inspector == nil {
+		return nil, fmt.Errorf("collation defaults inspector is required")
+	}
+	if c.SchemaInspector == nil {
+		return nil, fmt.Errorf("schema inspector is required")
+	}
+	if c.Primary == "" || c.Replica == "" {
+		return nil, fmt.Errorf("primary and replica hosts are required")
+	}
+	primary, err := c.Inspector.CollationDefaults(ctx, c.Primary)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read default collations on %s: %w", c.Primary, err)
+	}
+	replica, err := c.Inspector.CollationDefaults(ctx, c.Replica)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read default collations on %s: %w", c.Replica, err)
+	}
+	oldDefault := strings.ToLower(primary.ServerCollation)
+	if oldDefault == "" {
+		oldDefault = mysql57DefaultCollation
+	}
+	newDefault := strings.ToLower(replica.ServerCollation)
+	if newDefault == "" {
+		newDefault = mysql80DefaultCollation
+	}
+	if oldDefault == newDefault {
+		return []checks.Finding{{
+			Severity: checks.SeverityInfo,
+			Code:     CodeCollationDefaultUnchanged,
+			Message:  fmt.Sprintf("server default collation %s is the same on primary %q and replica %q", oldDefault, c.Primary, c.Replica),
+			Meta:     map[string]interface{}{"primary": c.Primary, "replica": c.Replica, "collation": oldDefault},
+		}}, nil
+	}
+
+	schema, err := c.SchemaInspector.Schema(ctx, c.Primary)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema on %s: %w", c.Primary, err)
+	}
+	databases := []string{}
+	for db, collation := range primary.Databases {
+		if strings.EqualFold(collation, oldDefault) {
+			databases = append(databases, db)
+		}
+	}
+	sort.Strings(databases)
+	tables := []string{}
+	for _, table := range schema.Tables {
+		for _, col := range table.Columns {
+			if strings.EqualFold(col.Collation, oldDefault) {
+				tables = append(tables, table.Name)
+				break
+			}
+		}
+	}
+	sort.Strings(tables)
+	meta := map[string]interface{}{
+		"primary":           c.Primary,
+		"replica":           c.Replica,
+		"primary_collation": oldDefault,
+		"replica_collation": newDefault,
+		"databases":         databases,
+		"tables":            tables,
+		"table_count":       len(tables),
+	}
+	if len(databases) == 0 && len(tables) == 0 {
+		return []checks.Finding{{
+			Severity: checks.SeverityInfo,
+			Code:     CodeCollationDefaultUnused,
+			Message:  fmt.Sprintf("server default collation changes from %s on primary %q to %s on replica %q, but no database or table uses the old default", oldDefault, c.Primary, newDefault, c.Replica),
+			Meta:     meta,
+		}}, nil
+	}
+	findings := []checks.Finding{{
+		Severity: checks.SeverityWarn,
+		Code:     CodeCollationDefaultChanged,
+		Message:  fmt.Sprintf("server default collation changes from %s on primary %q to %s on replica %q; %d databases and %d tables use the old default, and objects created after promotion without an explicit collation get %s", oldDefault, c.Primary, newDefault, c.Replica, len(databases), len(tables), newDefault),
+		Meta:     meta,
+	}}
+	for _, table := range schema.Tables {
+		columns := joinColumnsWithCollation(schema, table, oldDefault)
+		if len(columns) == 0 {
+			continue
+		}
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityWarn,
+			Code:     CodeCollationJoinAtRisk,
+			Message:  fmt.Sprintf("table %q join columns %s use %s; joining them with columns created under %s fails with \"Illegal mix of collations\" or cannot use their index", table.Name, strings.Join(columns, ", "), oldDefault, newDefault),
+			Meta:     map[string]interface{}{"table": table.Name, "columns": columns, "collation": oldDefault, "replica_collation": newDefault},
+		})
+	}
+	return findings, nil
+}
+
+// joinColumnsWithCollation returns table's primary key, foreign key and
+// referenced columns in collation, in column order.
+func joinColumnsWithCollation(schema checks.Schema, table checks.Table, collation string) []string {
+	joins := map[string]bool{}
+	for _, name := range table.PrimaryKey {
+		joins[name] = true
+	}
+	for _, fk := range table.ForeignKeys {
+		for _, name := range fk.Columns {
+			joins[name] = true
+		}
+	}
+	for _, other := range schema.Tables {
+		for _, fk := range other.ForeignKeys {
+			if fk.ReferencedTable != table.Name {
+				continue
+			}
+			for _, name := range fk.ReferencedColumns {
+				joins[name] = true
+			}
+		}
+	}
+	columns := []string{}
+	for _, col := range table.Columns {
+		if joins[col.Name] && strings.EqualFold(col.Collation, collation) {
+			columns = append(columns, col.Name)
+		}
+	}
+	return columns
+}
+
+const (
+	collationServerQuery    = `SELECT @@GLOBAL.character_set_server, @@GLOBAL.collation_server`
+	collationDatabasesQuery = `SELECT SCHEMA_NAME, DEFAULT_COLLATION_NAME FROM information_schema.SCHEMATA
+WHERE SCHEMA_NAME NOT IN (` + systemSchemaList + `)
+ORDER BY SCHEMA_NAME`
+)
+
+// ServerCollationInspector implements CollationDefaultsInspector with the
+// server's global variables and information_schema.SCHEMATA. System schemas
+// are left out.
+type ServerCollationInspector struct {
+	Connect Connector
+}
+
+func (i *ServerCollationInspector) CollationDefaults(ctx context.Context, host string) (CollationDefaults, error) {
+	if i.Connect == nil {
+		return CollationDefaults{}, fmt.Errorf("collation inspector requires a connector")
+	}
+	q, err := i.Connect(ctx, host)
+	if err != nil {
+		return CollationDefaults{}, err
+	}
+	defaults := CollationDefaults{Databases: map[string]string{}}
+	rows, err := q.QueryContext(ctx, collationServerQuery)
+	if err != nil {
+		return CollationDefaults{}, fmt.Errorf("failed to read server collation: %w", err)
+	}
+	err = scanRows(rows, func(scan func(...interface{}) error) error {
+		return scan(&defaults.ServerCharset, &defaults.ServerCollation)
+	})
+	if err != nil {
+		return CollationDefaults{}, fmt.Errorf("failed to read server collation: %w", err)
+	}
+	rows, err = q.QueryContext(ctx, collationDatabasesQuery)
+	if err != nil {
+		return CollationDefaults{}, fmt.Errorf("failed to read database collations: %w", err)
+	}
+	err = scanRows(rows, func(scan func(...interface{}) error) error {
+		var name, collation string
+		if err := scan(&name, &collation); err != nil {
+			return err
+		}
+		defaults.Databases[name] = collation
+		return nil
+	})
+	if err != nil {
+		return CollationDefaults{}, fmt.Errorf("failed to read database collations: %w", err)
+	}
+	return defaults, nil
+}
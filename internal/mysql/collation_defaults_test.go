@@ -0,0 +1,116 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+
+	"migratorx/internal/checks"
+)
+
+type fakeCollationInspector map[string]CollationDefaults
+
+func (f fakeCollationInspector) CollationDefaults(ctx context.Context, host string) (CollationDefaults, error) {
+	return f[host], nil
+}
+
+func collationSchema() checks.Schema {
+	return checks.Schema{Tables: []checks.Table{
+		{
+			Name:       "shop.users",
+			PrimaryKey: []string{"email"},
+			Columns: []checks.Column{
+				{Name: "email", Type: "varchar(255)", Charset: "latin1", Collation: "latin1_swedish_ci"},
+				{Name: "name", Type: "varchar(255)", Charset: "latin1", Collation: "latin1_swedish_ci"},
+			},
+		},
+		{
+			Name:       "shop.orders",
+			PrimaryKey: []string{"id"},
+			Columns: []checks.Column{
+				{Name: "id", Type: "bigint"},
+				{Name: "user_email", Type: "varchar(255)", Charset: "latin1", Collation: "latin1_swedish_ci"},
+				{Name: "note", Type: "text", Charset: "latin1", Collation: "latin1_swedish_ci"},
+			},
+			ForeignKeys: []checks.ForeignKey{{Name: "fk_user", Columns: []string{"user_email"}, ReferencedTable: "shop.users", ReferencedColumns: []string{"email"}}},
+		},
+		{
+			Name:       "shop.events",
+			PrimaryKey: []string{"id"},
+			Columns: []checks.Column{
+				{Name: "id", Type: "bigint"},
+				{Name: "kind", Type: "varchar(32)", Charset: "utf8mb4", Collation: "utf8mb4_general_ci"},
+			},
+		},
+	}}
+}
+
+func TestCollationDefaultsCheck_ListsObjectsAndJoinColumns(t *testing.T) {
+	inspector := fakeCollationInspector{
+		"mysql-primary":   {ServerCharset: "latin1", ServerCollation: "latin1_swedish_ci", Databases: map[string]string{"shop": "latin1_swedish_ci", "audit": "utf8mb4_general_ci"}},
+		"mysql-replica-1": {},
+	}
+	check := &CollationDefaultsCheck{Inspector: inspector, SchemaInspector: &fakeFreezeSchema{schema: collationSchema()}, Primary: "mysql-primary", Replica: "mysql-replica-1"}
+	findings, err := check.Run(context.Background(), checks.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 3 {
+		t.Fatalf("expected a summary and two join warnings, got %+v", findings)
+	}
+	changed := findings[0]
+	if changed.Code != CodeCollationDefaultChanged || changed.Meta["replica_collation"] != "utf8mb4_0900_ai_ci" {
+		t.Fatalf("unexpected summary finding: %+v", changed)
+	}
+	if !reflect.DeepEqual(changed.Meta["databases"], []string{"shop"}) || !reflect.DeepEqual(changed.Meta["tables"], []string{"shop.orders", "shop.users"}) {
+		t.Fatalf("unexpected affected objects: %v %v", changed.Meta["databases"], changed.Meta["tables"])
+	}
+	for i, want := range []struct {
+		table   string
+		columns []string
+	}{{"shop.users", []string{"email"}}, {"shop.orders", []string{"user_email"}}} {
+		f := findings[i+1]
+		if f.Code != CodeCollationJoinAtRisk || f.Meta["table"] != want.table || !reflect.DeepEqual(f.Meta["columns"], want.columns) {
+			t.Fatalf("finding %d: expected %s join columns %v, got %+v", i+1, want.table, want.columns, f)
+		}
+	}
+}
+
+func TestCollationDefaultsCheck_UnchangedOrUnused(t *testing.T) {
+	inspector := fakeCollationInspector{
+		"mysql-primary":   {ServerCollation: "utf8mb4_general_ci"},
+		"mysql-replica-1": {ServerCollation: "utf8mb4_general_ci"},
+	}
+	check := &CollationDefaultsCheck{Inspector: inspector, SchemaInspector: &fakeFreezeSchema{schema: collationSchema()}, Primary: "mysql-primary", Replica: "mysql-replica-1"}
+	findings, err := check.Run(context.Background(), checks.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Code != CodeCollationDefaultUnchanged {
+		t.Fatalf("expected an unchanged default, got %+v", findings)
+	}
+
+	inspector["mysql-replica-1"] = CollationDefaults{ServerCollation: "utf8mb4_0900_ai_ci"}
+	inspector["mysql-primary"] = CollationDefaults{ServerCollation: "utf8mb3_general_ci"}
+	findings, _ = check.Run(context.Background(), checks.Input{})
+	if len(findings) != 1 || findings[0].Code != CodeCollationDefaultUnused || findings[0].Meta["table_count"] != 0 {
+		t.Fatalf("expected no objects on the old default, got %+v", findings)
+	}
+}
+
+func TestServerCollationInspector_ReadsServerAndDatabaseDefaults(t *testing.T) {
+	db := openFakeDB(t,
+		fakeResponse{match: "@@GLOBAL.collation_server", columns: []string{"character_set_server", "collation_server"}, rows: [][]driver.Value{{"latin1", "latin1_swedish_ci"}}},
+		fakeResponse{match: "SCHEMATA", columns: []string{"SCHEMA_NAME", "DEFAULT_COLLATION_NAME"}, rows: [][]driver.Value{{"shop", "latin1_swedish_ci"}}},
+	)
+	inspector := &ServerCollationInspector{Connect: fakeConnector(db)}
+	defaults, err := inspector.CollationDefaults(context.Background(), "mysql-primary")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := CollationDefaults{ServerCharset: "latin1", ServerCollation: "latin1_swedish_ci", Databases: map[string]string{"shop": "latin1_swedish_ci"}}
+	if !reflect.DeepEqual(defaults, want) {
+		t.Fatalf("unexpected defaults: %+v", defaults)
+	}
+}
@@ -0,0 +1,163 @@
+package mysql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// changedDefault is a variable whose compiled-in default changed in a
+// version.
+type changedDefault struct {
+	From string
+	To   string
+}
+
+// changedDefaults maps each version to the notable defaults it changed.
+var changedDefaults = map[string]map[string]changedDefault{
+	"8.0": {
+		"character_set_server":                                    {From: "latin1", To: "utf8mb4"},
+		"collation_server":                                        {From: "latin1_swedish_ci", To: "utf8mb4_0900_ai_ci"},
+		"default_authentication_plugin":                           {From: "mysql_native_password", To: "caching_sha2_password"},
+		"explicit_defaults_for_timestamp":                         {From: "OFF", To: "ON"},
+		"log_bin":                                                 {From: "OFF", To: "ON"},
+		"log_slave_updates":                                       {From: "OFF", To: "ON"},
+		"server_id":                                               {From: "0", To: "1"},
+		"event_scheduler":                                         {From: "OFF", To: "ON"},
+		"binlog_expire_logs_seconds":                              {From: "0", To: "2592000"},
+		"innodb_autoinc_lock_mode":                                {From: "1", To: "2"},
+		"innodb_undo_tablespaces":                                 {From: "0", To: "2"},
+		"innodb_max_dirty_pages_pct":                              {From: "75", To: "90"},
+		"innodb_max_dirty_pages_pct_lwm":                          {From: "0", To: "10"},
+		"innodb_flush_neighbors":                                  {From: "1", To: "0"},
+		"max_allowed_packet":                                      {From: "4M", To: "64M"},
+		"max_error_count":                                         {From: "64", To: "1024"},
+		"master_info_repository":                                  {From: "FILE", To: "TABLE"},
+		"relay_log_info_repository":                               {From: "FILE", To: "TABLE"},
+		"transaction_write_set_extraction":                        {From: "OFF", To: "XXHASH64"},
+		"optimizer_trace_max_mem_size":                            {From: "16K", To: "1M"},
+		"internal_tmp_mem_storage_engine":                         {From: "MEMORY", To: "TempTable"},
+		"performance_schema_consumer_events_transactions_current": {From: "OFF", To: "ON"},
+	},
+	"8.4": {
+		"innodb_adaptive_hash_index":      {From: "ON", To: "OFF"},
+		"innodb_change_buffering":         {From: "all", To: "none"},
+		"innodb_io_capacity":              {From: "200", To: "10000"},
+		"innodb_log_buffer_size":          {From: "16M", To: "64M"},
+		"innodb_use_fdatasync":            {From: "OFF", To: "ON"},
+		"innodb_flush_method":             {From: "fsync", To: "O_DIRECT"},
+		"innodb_numa_interleave":          {From: "OFF", To: "ON"},
+		"innodb_buffer_pool_in_core_file": {From: "ON", To: "OFF"},
+	},
+}
+
+// changedBetween returns the defaults changed after source up to and
+// including target, keeping the earliest From and latest To.
+func changedBetween(source string, target string) map[string]changedDefault {
+	changed := map[string]changedDefault{}
+	for _, version := range configVersions {
+		if source != "" && versionAtLeast(source, version) {
+			continue
+		}
+		if !versionAtLeast(target, version) {
+			break
+		}
+		for name, change := range changedDefaults[version] {
+			if earlier, ok := changed[name]; ok {
+				change.From = earlier.From
+			}
+			changed[name] = change
+		}
+	}
+	return changed
+}
+
+// RenderedConfiguration is a [mysqld] option file for the target version.
+type RenderedConfiguration struct {
+	Text    string
+	Kept    int
+	Dropped []string
+}
+
+// RenderConfiguration renders variables as a [mysqld] option file for the
+// target version. Removed variables are commented out with their
+// replacement, explicitly set variables whose default changed are annotated,
+// and the changed defaults the file does not pin are listed at the end so
+// they can be reviewed. A variable set more than once keeps its last value,
+// as mysqld would.
+func RenderConfiguration(variables []ConfiguredVariable, source string, target string) RenderedConfiguration {
+	type entry struct {
+		variable ConfiguredVariable
+		order    int
+	}
+	latest := map[string]entry{}
+	for i, v := range variables {
+		name := normalizeVariable(v.Name)
+		v.Name = name
+		order := i
+		if existing, ok := latest[name]; ok {
+			order = existing.order
+		}
+		latest[name] = entry{variable: v, order: order}
+	}
+	entries := make([]entry, 0, len(latest))
+	for _, e := range latest {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(a, b int) bool { return entries[a].order < entries[b].order })
+
+	removed := removedBetween(source, target)
+	changed := changedBetween(source, target)
+	var b strings.Builder
+	header := fmt.Sprintf("# Rendered by migratorx for MySQL %s", target)
+	if source != "" {
+		header += fmt.Sprintf(" from a %s configuration", source)
+	}
+	b.WriteString(header + ".\n[mysqld]\n")
+
+	rendered := RenderedConfiguration{Dropped: []string{}}
+	for _, e := range entries {
+		v := e.variable
+		line := v.Name
+		if v.Value != "" {
+			line += " = " + v.Value
+		}
+		if v.Loose {
+			line = "loose-" + line
+		}
+		if name, entry, ok := lookupRemoved(removed, v.Name); ok {
+			note := fmt.Sprintf("# removed in %s", entry.RemovedIn)
+			if entry.Replacement != "" {
+				note += fmt.Sprintf(", replaced by %s (check its values before setting it)", entry.Replacement)
+			}
+			fmt.Fprintf(&b, "%s: %s\n", note, line)
+			rendered.Dropped = append(rendered.Dropped, name)
+			continue
+		}
+		if change, ok := changed[v.Name]; ok {
+			fmt.Fprintf(&b, "# %s default is %s (was %s); keeping the configured value\n", target, change.To, change.From)
+		}
+		b.WriteString(line + "\n")
+		rendered.Kept++
+	}
+
+	unpinned := []string{}
+	for name := range changed {
+		if _, ok := removed[name]; ok {
+			continue
+		}
+		if _, ok := latest[name]; !ok {
+			unpinned = append(unpinned, name)
+		}
+	}
+	sort.Strings(unpinned)
+	if len(unpinned) > 0 {
+		fmt.Fprintf(&b, "\n# Defaults changed in %s for variables this file does not set:\n", target)
+		for _, name := range unpinned {
+			change := changed[name]
+			fmt.Fprintf(&b, "#   %s: %s -> %s\n", name, change.From, change.To)
+		}
+	}
+	rendered.Text = b.String()
+	return rendered
+}
@@ -0,0 +1,83 @@
+package mysql
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"migratorx/internal/checks"
+)
+
+func TestRenderConfiguration_DropsRemovedAndAnnotatesDefaults(t *testing.T) {
+	rendered := RenderConfiguration([]ConfiguredVariable{
+		{Name: "max_connections", Value: "300"},
+		{Name: "query-cache-type", Value: "0"},
+		{Name: "tx_isolation", Value: "READ-COMMITTED"},
+		{Name: "innodb_large_prefix", Value: "ON", Loose: true},
+		{Name: "character_set_server", Value: "latin1"},
+		{Name: "max_connections", Value: "500"},
+	}, "5.7", "8.0")
+
+	if rendered.Kept != 2 {
+		t.Fatalf("expected two kept variables, got %d:\n%s", rendered.Kept, rendered.Text)
+	}
+	if strings.Join(rendered.Dropped, ",") != "query_cache_type,tx_isolation,innodb_large_prefix" {
+		t.Fatalf("unexpected dropped variables: %v", rendered.Dropped)
+	}
+	for _, want := range []string{
+		"[mysqld]\nmax_connections = 500\n",
+		"# removed in 8.0: query_cache_type = 0\n",
+		"# removed in 8.0, replaced by transaction_isolation (check its values before setting it): tx_isolation = READ-COMMITTED\n",
+		"# removed in 8.0: loose-innodb_large_prefix = ON\n",
+		"# 8.0 default is utf8mb4 (was latin1); keeping the configured value\ncharacter_set_server = latin1\n",
+		"#   default_authentication_plugin: mysql_native_password -> caching_sha2_password\n",
+	} {
+		if !strings.Contains(rendered.Text, want) {
+			t.Fatalf("expected %q in rendered configuration:\n%s", want, rendered.Text)
+		}
+	}
+	if strings.Contains(rendered.Text, "max_connections = 300") {
+		t.Fatalf("expected the last max_connections to win:\n%s", rendered.Text)
+	}
+	if strings.Contains(rendered.Text, "#   character_set_server") {
+		t.Fatalf("expected a pinned default not to be listed as unpinned:\n%s", rendered.Text)
+	}
+}
+
+func TestRenderConfiguration_OnlyAppliesChangesAfterSource(t *testing.T) {
+	rendered := RenderConfiguration([]ConfiguredVariable{
+		{Name: "expire_logs_days", Value: "7"},
+		{Name: "innodb_io_capacity", Value: "2000"},
+	}, "8.0", "8.4")
+
+	if strings.Join(rendered.Dropped, ",") != "expire_logs_days" {
+		t.Fatalf("unexpected dropped variables: %v", rendered.Dropped)
+	}
+	if !strings.Contains(rendered.Text, "# 8.4 default is 10000 (was 200)") {
+		t.Fatalf("expected the 8.4 default change to be annotated:\n%s", rendered.Text)
+	}
+	if strings.Contains(rendered.Text, "default_authentication_plugin") {
+		t.Fatalf("expected 8.0 default changes to be skipped for an 8.0 source:\n%s", rendered.Text)
+	}
+}
+
+func TestRemovedVariablesCheck_RendersConfigurationArtifact(t *testing.T) {
+	inspector := &fakeConfigurationInspector{variables: []ConfiguredVariable{
+		{Name: "query_cache_size", Value: "0", Source: "my.cnf:2 [mysqld]"},
+		{Name: "max_connections", Value: "500", Source: "my.cnf:3 [mysqld]"},
+	}}
+	check := &RemovedVariablesCheck{Inspector: inspector, Host: "replica-1"}
+	findings, artifacts, err := check.RunWithArtifacts(context.Background(), checks.Input{PlanSourceVersion: "5.7", PlanTargetVersion: "8.0"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Code != CodeConfigVariableRemoved {
+		t.Fatalf("expected the query cache setting to block, got %+v", findings)
+	}
+	if len(artifacts) != 1 || artifacts[0].Name != "my.cnf" || artifacts[0].ContentType != "text/plain" {
+		t.Fatalf("expected a rendered my.cnf artifact, got %+v", artifacts)
+	}
+	if text := string(artifacts[0].Data); !strings.Contains(text, "# removed in 8.0: query_cache_size = 0") || !strings.Contains(text, "max_connections = 500") {
+		t.Fatalf("unexpected rendered artifact:\n%s", text)
+	}
+}
@@ -0,0 +1,218 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"migratorx/internal/checks"
+	"migratorx/internal/workflow"
+)
+
+// ClientConnection is a group of sessions sharing a user, client host and
+// program_name connection attribute. Program is empty when the driver does
+// not send one.
+type ClientConnection struct {
+	User        string
+	Host        string
+	Program     string
+	Connections int
+}
+
+// ConnectionInventoryInspector lists the client sessions connected to a host.
+type ConnectionInventoryInspector interface {
+	ClientConnections(ctx context.Context, host string) ([]ClientConnection, error)
+}
+
+// ConnectedApplicationsCheck inventories the applications connected to the
+// primary and replicas and warns about any not described in the plan's
+// clients: section. An unknown consumer is one nobody has vetted against the
+// upgraded server, so it may break after cutover.
+type ConnectedApplicationsCheck struct {
+	Inspector ConnectionInventoryInspector
+	Hosts     []string
+	Clients   []workflow.ClientConfig
+}
+
+func (c *ConnectedApplicationsCheck) Name() string   { return "connected_applications" }
+func (c *ConnectedApplicationsCheck) ReadOnly() bool { return true }
+
+func (c *ConnectedApplicationsCheck) Parameters() map[string]interface{} {
+	names := []string{}
+	for _, client := range c.Clients {
+		names = append(names, client.Name)
+	}
+	return map[string]interface{}{"hosts": c.Hosts, "clients": names}
+}
+
+func (c *ConnectedApplicationsCheck) Run(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+	if c.Inspector == nil {
+		return nil, fmt.Errorf("connection inventory inspector is required")
+	}
+	hosts := c.Hosts
+	if len(hosts) == 0 {
+		hosts = []string{input.PrimaryHost, input.ReplicaHost}
+	}
+
+	type consumer struct {
+		conn   ClientConnection
+		client string
+		hosts  []string
+	}
+	consumers := map[ClientConnection]*consumer{}
+	findings := []checks.Finding{}
+	inspected := []string{}
+	for _, host := range hosts {
+		host = strings.TrimSpace(host)
+		if host == "" {
+			continue
+		}
+		conns, err := c.Inspector.ClientConnections(ctx, host)
+		if err != nil {
+			findings = append(findings, checks.Finding{
+				Severity: checks.SeverityWarn,
+				Code:     CodeConnectedAppsUnknown,
+				Message:  fmt.Sprintf("unable to list client connections on %q: %v", host, err),
+				Meta:     map[string]interface{}{"host": host},
+			})
+			continue
+		}
+		inspected = append(inspected, host)
+		for _, conn := range conns {
+			key := ClientConnection{User: conn.User, Host: conn.Host, Program: conn.Program}
+			cons, ok := consumers[key]
+			if !ok {
+				cons = &consumer{conn: key, client: c.match(conn)}
+				consumers[key] = cons
+			}
+			cons.conn.Connections += conn.Connections
+			cons.hosts = append(cons.hosts, host)
+		}
+	}
+	if len(inspected) == 0 {
+		if len(findings) == 0 {
+			return nil, fmt.Errorf("host is required")
+		}
+		return findings, nil
+	}
+
+	sorted := make([]*consumer, 0, len(consumers))
+	for _, cons := range consumers {
+		sorted = append(sorted, cons)
+	}
+	sort.Slice(sorted, func(a, b int) bool {
+		x, y := sorted[a].conn, sorted[b].conn
+		if x.User != y.User {
+			return x.User < y.User
+		}
+		if x.Host != y.Host {
+			return x.Host < y.Host
+		}
+		return x.Program < y.Program
+	})
+
+	inventory := []map[string]interface{}{}
+	unknown := 0
+	for _, cons := range sorted {
+		entry := map[string]interface{}{"user": cons.conn.User, "client_host": cons.conn.Host, "program": cons.conn.Program, "connections": cons.conn.Connections, "hosts": cons.hosts}
+		if cons.client != "" {
+			entry["client"] = cons.client
+			inventory = append(inventory, entry)
+			continue
+		}
+		unknown++
+		inventory = append(inventory, entry)
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityWarn,
+			Code:     CodeConnectedAppUnknown,
+			Message:  fmt.Sprintf("unknown client %s connected to %s (%d connections) is not in the plan's clients: section and may break after cutover", describeConnection(cons.conn), strings.Join(cons.hosts, ", "), cons.conn.Connections),
+			Meta:     entry,
+		})
+	}
+	findings = append(findings, checks.Finding{
+		Severity: checks.SeverityInfo,
+		Code:     CodeConnectedAppsInventory,
+		Message:  fmt.Sprintf("%d distinct clients connected to %s; %d not in the plan", len(sorted), strings.Join(inspected, ", "), unknown),
+		Meta:     map[string]interface{}{"hosts": inspected, "clients": inventory, "unknown": unknown},
+	})
+	return findings, nil
+}
+
+// match returns the name of the first plan client whose users, hosts and
+// programs patterns all accept conn. A client without any pattern matches
+// nothing, so it cannot silently allow every connection.
+func (c *ConnectedApplicationsCheck) match(conn ClientConnection) string {
+	for _, client := range c.Clients {
+		if len(client.Users) == 0 && len(client.Hosts) == 0 && len(client.Programs) == 0 {
+			continue
+		}
+		if matchesAny(client.Users, conn.User) && matchesAny(client.Hosts, conn.Host) && matchesAny(client.Programs, conn.Program) {
+			return client.Name
+		}
+	}
+	return ""
+}
+
+// matchesAny reports whether value matches one of the glob patterns; an empty
+// pattern list accepts any value.
+func matchesAny(patterns []string, value string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if ok, err := path.Match(pattern, value); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+func describeConnection(conn ClientConnection) string {
+	desc := fmt.Sprintf("%s@%s", conn.User, conn.Host)
+	if conn.Program != "" {
+		desc += fmt.Sprintf(" (%s)", conn.Program)
+	}
+	return desc
+}
+
+const clientConnectionsQuery = `SELECT p.USER, SUBSTRING_INDEX(p.HOST, ':', 1), COALESCE(a.ATTR_VALUE, ''), COUNT(*)
+FROM information_schema.PROCESSLIST p
+LEFT JOIN performance_schema.session_connect_attrs a ON a.PROCESSLIST_ID = p.ID AND a.ATTR_NAME = 'program_name'
+WHERE p.COMMAND NOT IN ('Daemon', 'Binlog Dump', 'Binlog Dump GTID')
+  AND p.USER NOT IN ('system user', 'event_scheduler')
+  AND p.ID <> CONNECTION_ID()
+GROUP BY 1, 2, 3`
+
+// ProcesslistInventoryInspector implements ConnectionInventoryInspector with
+// information_schema.PROCESSLIST and the program_name connection attribute
+// from performance_schema.session_connect_attrs. Replication threads and the
+// inspector's own session are excluded.
+type ProcesslistInventoryInspector struct {
+	Connect Connector
+}
+
+func (i *ProcesslistInventoryInspector) ClientConnections(ctx context.Context, host string) ([]ClientConnection, error) {
+	if i.Connect == nil {
+		return nil, fmt.Errorf("connection inventory inspector requires a connector")
+	}
+	q, err := i.Connect(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := q.QueryContext(ctx, clientConnectionsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list client connections: %w", err)
+	}
+	defer rows.Close()
+	conns := []ClientConnection{}
+	for rows.Next() {
+		var conn ClientConnection
+		if err := rows.Scan(&conn.User, &conn.Host, &conn.Program, &conn.Connections); err != nil {
+			return nil, err
+		}
+		conns = append(conns, conn)
+	}
+	return conns, rows.Err()
+}
@@ -0,0 +1,87 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"migratorx/internal/checks"
+	"migratorx/internal/workflow"
+)
+
+type fakeInventoryInspector struct {
+	conns map[string][]ClientConnection
+}
+
+func (f *fakeInventoryInspector) ClientConnections(ctx context.Context, host string) ([]ClientConnection, error) {
+	conns, ok := f.conns[host]
+	if !ok {
+		return nil, errors.New("access denied")
+	}
+	return conns, nil
+}
+
+func TestConnectedApplicationsCheck_WarnsAboutUnknownClients(t *testing.T) {
+	inspector := &fakeInventoryInspector{conns: map[string][]ClientConnection{
+		"primary": {
+			{User: "orders", Host: "10.0.1.15", Program: "orders-api", Connections: 12},
+			{User: "etl", Host: "10.9.0.4", Program: "", Connections: 1},
+		},
+		"replica-1": {
+			{User: "orders", Host: "10.0.1.15", Program: "orders-api", Connections: 3},
+		},
+	}}
+	check := &ConnectedApplicationsCheck{
+		Inspector: inspector,
+		Hosts:     []string{"primary", "replica-1"},
+		Clients: []workflow.ClientConfig{
+			{Name: "no-patterns"},
+			{Name: "orders-api", Users: []string{"orders"}, Hosts: []string{"10.0.1.*"}},
+		},
+	}
+	findings, err := check.Run(context.Background(), checks.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 2 {
+		t.Fatalf("expected one unknown client and an inventory, got %+v", findings)
+	}
+	if f := findings[0]; f.Severity != checks.SeverityWarn || f.Code != CodeConnectedAppUnknown || f.Meta["user"] != "etl" {
+		t.Fatalf("expected the etl user to be unknown, got %+v", f)
+	}
+	inventory := findings[1]
+	if inventory.Code != CodeConnectedAppsInventory || inventory.Meta["unknown"] != 1 {
+		t.Fatalf("unexpected inventory %+v", inventory)
+	}
+	orders := inventory.Meta["clients"].([]map[string]interface{})[1]
+	if orders["client"] != "orders-api" || orders["connections"] != 15 || len(orders["hosts"].([]string)) != 2 {
+		t.Fatalf("expected orders connections to be merged across hosts, got %+v", orders)
+	}
+}
+
+func TestConnectedApplicationsCheck_UnreadableHostWarns(t *testing.T) {
+	check := &ConnectedApplicationsCheck{Inspector: &fakeInventoryInspector{conns: map[string][]ClientConnection{"primary": {}}}, Hosts: []string{"primary", "replica-1"}}
+	findings, err := check.Run(context.Background(), checks.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if findings[0].Code != CodeConnectedAppsUnknown || findings[0].Meta["host"] != "replica-1" {
+		t.Fatalf("expected the unreadable replica to be reported, got %+v", findings)
+	}
+	if findings[1].Code != CodeConnectedAppsInventory {
+		t.Fatalf("expected an inventory of the readable host, got %+v", findings[1])
+	}
+}
+
+func TestProcesslistInventoryInspector_GroupsSessions(t *testing.T) {
+	db := openFakeDB(t, fakeResponse{match: "session_connect_attrs", columns: []string{"USER", "HOST", "PROGRAM", "COUNT"}, rows: [][]driver.Value{{"orders", "10.0.1.15", "orders-api", int64(4)}}})
+	inspector := &ProcesslistInventoryInspector{Connect: fakeConnector(db)}
+	conns, err := inspector.ClientConnections(context.Background(), "primary")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(conns) != 1 || conns[0] != (ClientConnection{User: "orders", Host: "10.0.1.15", Program: "orders-api", Connections: 4}) {
+		t.Fatalf("unexpected connections %+v", conns)
+	}
+}
@@ -0,0 +1,291 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"migratorx/internal/checks"
+)
+
+const (
+	dataColumnsQuery = `SELECT COLUMN_NAME FROM information_schema.COLUMNS
+WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
+ORDER BY ORDINAL_POSITION`
+
+	dataKeyQuery = `SELECT COLUMN_NAME FROM information_schema.STATISTICS
+WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND INDEX_NAME = 'PRIMARY'
+ORDER BY SEQ_IN_INDEX`
+
+	// rowChecksumBatch is how many keys one RowChecksums statement looks up.
+	rowChecksumBatch = 500
+)
+
+// ChecksumDataInspector implements checks.ChecksumInspector and
+// checks.SamplingInspector with SQL, in the manner of pt-table-checksum: a
+// row's checksum is CRC32 over its columns joined with CONCAT_WS plus a NULL
+// bitmap, and a chunk's is the BIT_XOR of its rows' checksums with the row
+// count. Keys are the primary-key values JSON-encoded as an array of strings,
+// compared with row constructors so composite keys walk in index order.
+// Tables without a primary key are rejected. Tables are named db.table, or
+// table within Database. Sampling orders the whole table by RAND(), so it
+// costs a key index scan on the primary.
+type ChecksumDataInspector struct {
+	Connect  Connector
+	Database string
+
+	mu     sync.Mutex
+	layout map[string]dataTable
+}
+
+// dataTable is the quoted name, key columns and row checksum expression of a
+// table on one host.
+type dataTable struct {
+	name     string
+	key      []string
+	checksum string
+}
+
+func (i *ChecksumDataInspector) NextBoundary(ctx context.Context, host string, table string, after string, size int) (string, bool, error) {
+	q, t, err := i.open(ctx, host, table)
+	if err != nil {
+		return "", false, err
+	}
+	where, args, err := t.after(after)
+	if err != nil {
+		return "", false, err
+	}
+	keys := strings.Join(t.key, ", ")
+	upper, err := queryKey(ctx, q, fmt.Sprintf("SELECT %s FROM %s%s ORDER BY %s LIMIT 1 OFFSET %d", keys, t.name, where, keys, size-1), args, len(t.key))
+	if err != nil || upper != "" {
+		return upper, false, err
+	}
+	last, err := queryKey(ctx, q, fmt.Sprintf("SELECT %s FROM %s%s ORDER BY %s LIMIT 1", keys, t.name, where, strings.Join(t.key, " DESC, ")+" DESC"), args, len(t.key))
+	return last, true, err
+}
+
+func (i *ChecksumDataInspector) ChunkChecksum(ctx context.Context, host string, table string, lower string, upper string) (checks.ChunkSum, error) {
+	q, t, err := i.open(ctx, host, table)
+	if err != nil {
+		return checks.ChunkSum{}, err
+	}
+	where, args, err := t.after(lower)
+	if err != nil {
+		return checks.ChunkSum{}, err
+	}
+	upperArgs, err := decodeKey(upper, len(t.key))
+	if err != nil {
+		return checks.ChunkSum{}, err
+	}
+	if where == "" {
+		where = " WHERE "
+	} else {
+		where += " AND "
+	}
+	where += fmt.Sprintf("(%s) <= (%s)", strings.Join(t.key, ", "), placeholders(len(t.key)))
+	var sum checks.ChunkSum
+	query := fmt.Sprintf("SELECT COUNT(*), COALESCE(BIT_XOR(%s), 0) FROM %s%s", t.checksum, t.name, where)
+	if err := queryOne(ctx, q, query, append(args, upperArgs...), &sum.Rows, &sum.Checksum); err != nil {
+		return checks.ChunkSum{}, fmt.Errorf("failed to checksum %s: %w", table, err)
+	}
+	return sum, nil
+}
+
+func (i *ChecksumDataInspector) SampleKeys(ctx context.Context, host string, table string, n int) ([]string, error) {
+	q, t, err := i.open(ctx, host, table)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := q.QueryContext(ctx, fmt.Sprintf("SELECT %s FROM %s ORDER BY RAND() LIMIT %d", strings.Join(t.key, ", "), t.name, n))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample %s: %w", table, err)
+	}
+	keys := []string{}
+	err = scanRows(rows, func(scan func(...interface{}) error) error {
+		key, err := scanKey(scan, len(t.key), nil)
+		keys = append(keys, key)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample %s: %w", table, err)
+	}
+	return keys, nil
+}
+
+func (i *ChecksumDataInspector) RowChecksums(ctx context.Context, host string, table string, keys []string) (map[string]string, error) {
+	q, t, err := i.open(ctx, host, table)
+	if err != nil {
+		return nil, err
+	}
+	sums := map[string]string{}
+	for start := 0; start < len(keys); start += rowChecksumBatch {
+		batch := keys[start:]
+		if len(batch) > rowChecksumBatch {
+			batch = batch[:rowChecksumBatch]
+		}
+		tuples := make([]string, 0, len(batch))
+		args := []interface{}{}
+		for _, key := range batch {
+			values, err := decodeKey(key, len(t.key))
+			if err != nil {
+				return nil, err
+			}
+			tuples = append(tuples, "("+placeholders(len(t.key))+")")
+			args = append(args, values...)
+		}
+		query := fmt.Sprintf("SELECT %s, %s FROM %s WHERE (%s) IN (%s)", strings.Join(t.key, ", "), t.checksum, t.name, strings.Join(t.key, ", "), strings.Join(tuples, ", "))
+		rows, err := q.QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to checksum rows of %s: %w", table, err)
+		}
+		err = scanRows(rows, func(scan func(...interface{}) error) error {
+			var sum string
+			key, err := scanKey(scan, len(t.key), &sum)
+			sums[key] = sum
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to checksum rows of %s: %w", table, err)
+		}
+	}
+	return sums, nil
+}
+
+// open connects to host and reads table's layout there once.
+func (i *ChecksumDataInspector) open(ctx context.Context, host string, table string) (Querier, dataTable, error) {
+	if i.Connect == nil {
+		return nil, dataTable{}, fmt.Errorf("data inspector requires a connector")
+	}
+	q, err := i.Connect(ctx, host)
+	if err != nil {
+		return nil, dataTable{}, err
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if t, ok := i.layout[host+"|"+table]; ok {
+		return q, t, nil
+	}
+	db, name := i.Database, table
+	if dot := strings.Index(table, "."); dot >= 0 {
+		db, name = table[:dot], table[dot+1:]
+	}
+	if db == "" {
+		return nil, dataTable{}, fmt.Errorf("table %q must be schema-qualified (db.table)", table)
+	}
+	columns, err := queryColumnNames(ctx, q, dataColumnsQuery, db, name)
+	if err != nil {
+		return nil, dataTable{}, fmt.Errorf("failed to read columns of %s: %w", table, err)
+	}
+	if len(columns) == 0 {
+		return nil, dataTable{}, fmt.Errorf("table %s not found on %s", table, host)
+	}
+	key, err := queryColumnNames(ctx, q, dataKeyQuery, db, name)
+	if err != nil {
+		return nil, dataTable{}, fmt.Errorf("failed to read the primary key of %s: %w", table, err)
+	}
+	if len(key) == 0 {
+		return nil, dataTable{}, fmt.Errorf("table %s has no primary key to walk", table)
+	}
+	nulls := make([]string, len(columns))
+	for n, column := range columns {
+		nulls[n] = "ISNULL(" + column + ")"
+	}
+	t := dataTable{
+		name:     quoteIdentifier(db) + "." + quoteIdentifier(name),
+		key:      key,
+		checksum: fmt.Sprintf("CRC32(CONCAT_WS('#', %s, CONCAT(%s)))", strings.Join(columns, ", "), strings.Join(nulls, ", ")),
+	}
+	if i.layout == nil {
+		i.layout = map[string]dataTable{}
+	}
+	i.layout[host+"|"+table] = t
+	return q, t, nil
+}
+
+// after returns the WHERE clause selecting keys after key, or none for "".
+func (t dataTable) after(key string) (string, []interface{}, error) {
+	if key == "" {
+		return "", nil, nil
+	}
+	args, err := decodeKey(key, len(t.key))
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf(" WHERE (%s) > (%s)", strings.Join(t.key, ", "), placeholders(len(t.key))), args, nil
+}
+
+func queryColumnNames(ctx context.Context, q Querier, query string, db string, table string) ([]string, error) {
+	rows, err := q.QueryContext(ctx, query, db, table)
+	if err != nil {
+		return nil, err
+	}
+	columns := []string{}
+	err = scanRows(rows, func(scan func(...interface{}) error) error {
+		var name string
+		if err := scan(&name); err != nil {
+			return err
+		}
+		columns = append(columns, quoteIdentifier(name))
+		return nil
+	})
+	return columns, err
+}
+
+// queryKey returns the encoded key of the first row, or "" when there is none.
+func queryKey(ctx context.Context, q Querier, query string, args []interface{}, n int) (string, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return "", err
+	}
+	key := ""
+	err = scanRows(rows, func(scan func(...interface{}) error) error {
+		if key != "" {
+			return nil
+		}
+		var err error
+		key, err = scanKey(scan, n, nil)
+		return err
+	})
+	return key, err
+}
+
+// scanKey scans n key columns, followed by extra when it is set, and encodes
+// the key.
+func scanKey(scan func(...interface{}) error, n int, extra *string) (string, error) {
+	values := make([]sql.NullString, n)
+	dest := make([]interface{}, 0, n+1)
+	for k := range values {
+		dest = append(dest, &values[k])
+	}
+	if extra != nil {
+		dest = append(dest, extra)
+	}
+	if err := scan(dest...); err != nil {
+		return "", err
+	}
+	key := make([]string, n)
+	for k, v := range values {
+		key[k] = v.String
+	}
+	b, err := json.Marshal(key)
+	return string(b), err
+}
+
+func decodeKey(key string, n int) ([]interface{}, error) {
+	var values []string
+	if err := json.Unmarshal([]byte(key), &values); err != nil || len(values) != n {
+		return nil, fmt.Errorf("invalid primary key %q", key)
+	}
+	args := make([]interface{}, n)
+	for k, v := range values {
+		args[k] = v
+	}
+	return args, nil
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
@@ -0,0 +1,83 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"migratorx/internal/checks"
+)
+
+func dataLayoutResponses() []fakeResponse {
+	return []fakeResponse{
+		{match: "FROM information_schema.COLUMNS", columns: []string{"COLUMN_NAME"}, rows: [][]driver.Value{{"order_id"}, {"line"}, {"sku"}}},
+		{match: "INDEX_NAME = 'PRIMARY'", columns: []string{"COLUMN_NAME"}, rows: [][]driver.Value{{"order_id"}, {"line"}}},
+	}
+}
+
+func TestChecksumDataInspector_WalksCompositeKeyInChunks(t *testing.T) {
+	db := openFakeDB(t, append(dataLayoutResponses(),
+		fakeResponse{match: "FROM `shop`.`order_lines` ORDER BY `order_id`, `line` LIMIT 1 OFFSET 1", columns: []string{"order_id", "line"}, rows: [][]driver.Value{{"1", "2"}}},
+		fakeResponse{match: "WHERE (`order_id`, `line`) > (?, ?) ORDER BY `order_id`, `line` LIMIT 1 OFFSET", columns: []string{"order_id", "line"}},
+		fakeResponse{match: "ORDER BY `order_id` DESC, `line` DESC LIMIT 1", columns: []string{"order_id", "line"}, rows: [][]driver.Value{{"2", "1"}}},
+		fakeResponse{match: "SELECT COUNT(*), COALESCE(BIT_XOR(CRC32(CONCAT_WS('#', `order_id`, `line`, `sku`, CONCAT(ISNULL(`order_id`), ISNULL(`line`), ISNULL(`sku`))))), 0)", columns: []string{"n", "sum"}, rows: [][]driver.Value{{int64(2), "3735928559"}}},
+	)...)
+	check := &checks.DataParityCheck{
+		Inspector:   &ChecksumDataInspector{Connect: fakeConnector(db)},
+		PrimaryHost: "db-primary",
+		ReplicaHost: "db-replica",
+		Tables:      []string{"shop.order_lines"},
+		ChunkSize:   2,
+	}
+	findings, err := check.Run(context.Background(), checks.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Code != checks.CodeDataParityOK || findings[0].Meta["chunks"] != 2 || findings[0].Meta["rows"] != int64(4) {
+		t.Fatalf("expected two matching chunks of two rows, got %+v", findings)
+	}
+}
+
+func TestChecksumDataInspector_SampledRowsDiffer(t *testing.T) {
+	primary := openFakeDB(t, append(dataLayoutResponses(),
+		fakeResponse{match: "ORDER BY RAND() LIMIT 2", columns: []string{"order_id", "line"}, rows: [][]driver.Value{{"1", "1"}, {"7", "3"}}},
+		fakeResponse{match: "WHERE (`order_id`, `line`) IN ((?, ?), (?, ?))", columns: []string{"order_id", "line", "sum"}, rows: [][]driver.Value{{"1", "1", "11"}, {"7", "3", "73"}}},
+	)...)
+	replica := openFakeDB(t, append(dataLayoutResponses(),
+		fakeResponse{match: "WHERE (`order_id`, `line`) IN ((?, ?), (?, ?))", columns: []string{"order_id", "line", "sum"}, rows: [][]driver.Value{{"1", "1", "11"}, {"7", "3", "74"}}},
+	)...)
+	dbs := map[string]*sql.DB{"db-primary": primary, "db-replica": replica}
+	inspector := &ChecksumDataInspector{Database: "shop", Connect: func(ctx context.Context, host string) (Querier, error) { return dbs[host], nil }}
+	check := &checks.DataParityCheck{
+		Sampler:     inspector,
+		Options:     map[string]checks.TableParityOptions{"order_lines": {Mode: checks.ParityModeSample, SampleSize: 2}},
+		PrimaryHost: "db-primary",
+		ReplicaHost: "db-replica",
+		Tables:      []string{"order_lines"},
+	}
+	findings, err := check.Run(context.Background(), checks.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Code != checks.CodeDataParitySampleDiff || findings[0].Meta["mismatched"] != 1 {
+		t.Fatalf("expected one differing sampled row, got %+v", findings)
+	}
+	if keys := findings[0].Meta["example_keys"].([]string); len(keys) != 1 || keys[0] != `["7","3"]` {
+		t.Fatalf("expected the differing key as an example, got %v", keys)
+	}
+}
+
+func TestChecksumDataInspector_RequiresPrimaryKey(t *testing.T) {
+	db := openFakeDB(t,
+		fakeResponse{match: "FROM information_schema.COLUMNS", columns: []string{"COLUMN_NAME"}, rows: [][]driver.Value{{"payload"}}},
+		fakeResponse{match: "INDEX_NAME = 'PRIMARY'", columns: []string{"COLUMN_NAME"}},
+	)
+	inspector := &ChecksumDataInspector{Connect: fakeConnector(db)}
+	if _, _, err := inspector.NextBoundary(context.Background(), "db-primary", "shop.audit_log", "", 10); err == nil {
+		t.Fatalf("expected a table without a primary key to be rejected")
+	}
+	if _, err := inspector.SampleKeys(context.Background(), "db-primary", "audit_log", 10); err == nil {
+		t.Fatalf("expected an unqualified table without Database to be rejected")
+	}
+}
@@ -0,0 +1,264 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+
+	"migratorx/internal/checks"
+	"migratorx/internal/failure"
+	"migratorx/internal/workflow"
+)
+
+// DefaultDDLLockTable is the lock table used by LockTableGuard.
+const DefaultDDLLockTable = "migratorx.ddl_freeze"
+
+var lockTableName = regexp.MustCompile(`^[A-Za-z0-9_]+(\.[A-Za-z0-9_]+)?$`)
+
+// DDLGuard stops schema changes on the primary for the duration of the
+// upgrade and validation window. Implementations must be idempotent.
+type DDLGuard interface {
+	Name() string
+	Acquire(ctx context.Context, primary string, owner string) error
+	Release(ctx context.Context, primary string, owner string) error
+}
+
+// LockTableGuard implements the lock table convention: while a row exists in
+// Table, schema change tooling (gh-ost/pt-osc hooks, migration pipelines) must
+// refuse to run. The guard is advisory; DDLDriftCheck catches DDL that ran
+// anyway. A session-scoped lock such as LOCK INSTANCE FOR BACKUP cannot be
+// used because the window spans several CLI invocations.
+type LockTableGuard struct {
+	Connect Connector
+	Table   string
+}
+
+func (g *LockTableGuard) Name() string { return "lock_table" }
+
+func (g *LockTableGuard) Acquire(ctx context.Context, primary string, owner string) error {
+	q, table, err := g.open(ctx, primary)
+	if err != nil {
+		return err
+	}
+	if _, err := q.ExecContext(ctx, fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (owner VARCHAR(191) NOT NULL PRIMARY KEY, acquired_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP)", table)); err != nil {
+		return err
+	}
+	_, err = q.ExecContext(ctx, fmt.Sprintf("INSERT IGNORE INTO %s (owner) VALUES (?)", table), owner)
+	return err
+}
+
+func (g *LockTableGuard) Release(ctx context.Context, primary string, owner string) error {
+	q, table, err := g.open(ctx, primary)
+	if err != nil {
+		return err
+	}
+	_, err = q.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE owner = ?", table), owner)
+	return err
+}
+
+func (g *LockTableGuard) open(ctx context.Context, primary string) (Querier, string, error) {
+	if g.Connect == nil {
+		return nil, "", fmt.Errorf("connector is required")
+	}
+	table := g.Table
+	if table == "" {
+		table = DefaultDDLLockTable
+	}
+	if !lockTableName.MatchString(table) {
+		return nil, "", fmt.Errorf("invalid lock table name %q", table)
+	}
+	q, err := g.Connect(ctx, primary)
+	if err != nil {
+		return nil, "", err
+	}
+	return q, table, nil
+}
+
+// DDLFreeze acquires a DDLGuard on the primary and records a per-table schema
+// baseline, taken after the guard is in place, for DDLDriftCheck to compare.
+type DDLFreeze struct {
+	Guard   DDLGuard
+	Schema  checks.SchemaInspector
+	State   workflow.State
+	Primary string
+	Owner   string
+	Logger  *log.Logger
+}
+
+// Freeze acquires the guard and records the baseline. Safe to re-run: a held
+// freeze keeps its original baseline.
+func (f *DDLFreeze) Freeze(ctx context.Context) (Summary, []Finding, error) {
+	var summary Summary
+	findings := []Finding{}
+	if err := f.validate(); err != nil {
+		return Summary{Block: 1}, []Finding{failureFinding(err)}, nil
+	}
+	if f.Schema == nil {
+		return Summary{Block: 1}, []Finding{failureFinding(failure.Config("schema inspector is required"))}, nil
+	}
+	meta := map[string]interface{}{"primary": f.Primary, "guard": f.Guard.Name(), "owner": f.Owner}
+	if ok, _ := getBool(f.State, ddlFrozenKey(f.Primary)); ok {
+		findings = append(findings, Finding{Severity: SeverityInfo, Message: "DDL already frozen on primary", Meta: meta})
+		applySummary(&summary, findings)
+		return summary, findings, nil
+	}
+
+	f.logger().Printf("freezing DDL on %s via %s", f.Primary, f.Guard.Name())
+	if err := f.Guard.Acquire(ctx, f.Primary, f.Owner); err != nil {
+		return appendFailure(summary, findings, failure.Act(f.Primary, "failed to freeze DDL via "+f.Guard.Name(), err))
+	}
+	setBool(f.State, ddlFrozenKey(f.Primary), true)
+
+	schema, err := f.Schema.Schema(ctx, f.Primary)
+	if err != nil {
+		return appendFailure(summary, findings, failure.Inspect(f.Primary, "DDL frozen but failed to read schema baseline", err))
+	}
+	workflow.PutArtifact(f.State, ddlBaselineKey(f.Primary), tableFingerprints(schema))
+	meta["tables"] = len(schema.Tables)
+	findings = append(findings, Finding{Severity: SeverityInfo, Message: "DDL frozen on primary; schema baseline recorded", Meta: meta})
+	applySummary(&summary, findings)
+	return summary, findings, nil
+}
+
+// Thaw releases the guard and, unlike Freeze, needs no Schema. The baseline is
+// kept so a drift check can still explain what changed during the window.
+func (f *DDLFreeze) Thaw(ctx context.Context) (Summary, []Finding, error) {
+	var summary Summary
+	findings := []Finding{}
+	if err := f.validate(); err != nil {
+		return Summary{Block: 1}, []Finding{failureFinding(err)}, nil
+	}
+	meta := map[string]interface{}{"primary": f.Primary, "guard": f.Guard.Name(), "owner": f.Owner}
+	if ok, _ := getBool(f.State, ddlFrozenKey(f.Primary)); !ok {
+		findings = append(findings, Finding{Severity: SeverityInfo, Message: "DDL is not frozen on primary", Meta: meta})
+		applySummary(&summary, findings)
+		return summary, findings, nil
+	}
+	f.logger().Printf("thawing DDL on %s via %s", f.Primary, f.Guard.Name())
+	if err := f.Guard.Release(ctx, f.Primary, f.Owner); err != nil {
+		return appendFailure(summary, findings, failure.Act(f.Primary, "failed to release DDL freeze via "+f.Guard.Name(), err))
+	}
+	setBool(f.State, ddlFrozenKey(f.Primary), false)
+	findings = append(findings, Finding{Severity: SeverityInfo, Message: "DDL freeze released on primary", Meta: meta})
+	applySummary(&summary, findings)
+	return summary, findings, nil
+}
+
+func (f *DDLFreeze) validate() error {
+	f.Primary = strings.TrimSpace(f.Primary)
+	switch {
+	case f.Guard == nil:
+		return failure.Config("DDL guard is required")
+	case f.State == nil:
+		return failure.Config("state is required")
+	case f.Primary == "":
+		return failure.Config("primary is required")
+	case strings.TrimSpace(f.Owner) == "":
+		return failure.Config("owner is required")
+	}
+	return nil
+}
+
+func (f *DDLFreeze) logger() *log.Logger {
+	if f.Logger == nil {
+		return log.Default()
+	}
+	return f.Logger
+}
+
+// DDLDriftCheck blocks when the primary's schema changed since DDLFreeze
+// recorded its baseline, since parity results against the replica no longer
+// describe the schema that will be promoted.
+type DDLDriftCheck struct {
+	Schema      checks.SchemaInspector
+	State       workflow.State
+	PrimaryHost string
+}
+
+func (c *DDLDriftCheck) Name() string   { return "ddl_drift" }
+func (c *DDLDriftCheck) ReadOnly() bool { return true }
+
+func (c *DDLDriftCheck) Parameters() map[string]interface{} {
+	return map[string]interface{}{"primary": c.PrimaryHost}
+}
+
+func (c *DDLDriftCheck) Run(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+	if c.Schema == nil {
+		return nil, fmt.Errorf("schema inspector is required")
+	}
+	if c.State == nil {
+		return nil, fmt.Errorf("state is required")
+	}
+	primary := strings.TrimSpace(c.PrimaryHost)
+	if primary == "" {
+		primary = input.PrimaryHost
+	}
+	if primary == "" {
+		return nil, fmt.Errorf("primary is required")
+	}
+
+	var baseline map[string]string
+	if ok, err := workflow.GetArtifact(c.State, ddlBaselineKey(primary), &baseline); !ok || err != nil {
+		return []checks.Finding{{
+			Severity: checks.SeverityWarn,
+			Code:     CodeDDLBaselineMissing,
+			Message:  fmt.Sprintf("no DDL freeze baseline recorded for %q; schema drift cannot be checked", primary),
+			Meta:     map[string]interface{}{"primary": primary},
+		}}, nil
+	}
+
+	schema, err := c.Schema.Schema(ctx, primary)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema: %v", err)
+	}
+	added, removed, changed := diffFingerprints(baseline, tableFingerprints(schema))
+	if len(added)+len(removed)+len(changed) == 0 {
+		return []checks.Finding{{
+			Severity: checks.SeverityInfo,
+			Code:     CodeDDLDriftNone,
+			Message:  fmt.Sprintf("no DDL on %q since the freeze baseline", primary),
+			Meta:     map[string]interface{}{"primary": primary, "table_count": len(baseline)},
+		}}, nil
+	}
+	return []checks.Finding{{
+		Severity: checks.SeverityBlock,
+		Code:     CodeDDLDriftDetected,
+		Message:  fmt.Sprintf("schema on %q changed during the DDL freeze (%d added, %d removed, %d altered tables); re-run schema and data parity", primary, len(added), len(removed), len(changed)),
+		Meta:     map[string]interface{}{"primary": primary, "added": added, "removed": removed, "changed": changed},
+	}}, nil
+}
+
+func tableFingerprints(schema checks.Schema) map[string]string {
+	out := make(map[string]string, len(schema.Tables))
+	for _, t := range schema.Tables {
+		out[t.Name] = checks.SchemaFingerprint(checks.Schema{Tables: []checks.Table{t}})
+	}
+	return out
+}
+
+func diffFingerprints(before, after map[string]string) (added, removed, changed []string) {
+	for name, fp := range after {
+		prev, ok := before[name]
+		switch {
+		case !ok:
+			added = append(added, name)
+		case prev != fp:
+			changed = append(changed, name)
+		}
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	sort.Strings(changed)
+	return added, removed, changed
+}
+
+func ddlFrozenKey(primary string) string   { return fmt.Sprintf("ddl_freeze:%s:frozen", primary) }
+func ddlBaselineKey(primary string) string { return fmt.Sprintf("ddl_freeze:%s:baseline", primary) }
@@ -0,0 +1,127 @@
+package mysql
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"migratorx/internal/checks"
+	"migratorx/internal/workflow"
+)
+
+type fakeDDLGuard struct {
+	acquired int
+	released int
+}
+
+func (g *fakeDDLGuard) Name() string { return "fake" }
+
+func (g *fakeDDLGuard) Acquire(ctx context.Context, primary string, owner string) error {
+	g.acquired++
+	return nil
+}
+
+func (g *fakeDDLGuard) Release(ctx context.Context, primary string, owner string) error {
+	g.released++
+	return nil
+}
+
+type fakeFreezeSchema struct {
+	schema checks.Schema
+}
+
+func (f *fakeFreezeSchema) Schema(ctx context.Context, host string) (checks.Schema, error) {
+	return f.schema, nil
+}
+
+func TestDDLFreeze_IdempotentFreezeAndThaw(t *testing.T) {
+	guard := &fakeDDLGuard{}
+	freeze := &DDLFreeze{
+		Guard:   guard,
+		Schema:  &fakeFreezeSchema{schema: checks.Schema{Tables: []checks.Table{{Name: "t"}}}},
+		State:   workflow.NewMemoryState(),
+		Primary: "db-primary",
+		Owner:   "mig-1",
+	}
+	for i := 0; i < 2; i++ {
+		summary, _, err := freeze.Freeze(context.Background())
+		if err != nil || summary.Block != 0 {
+			t.Fatalf("unexpected freeze result: %+v %v", summary, err)
+		}
+	}
+	if guard.acquired != 1 {
+		t.Fatalf("expected guard acquired once, got %d", guard.acquired)
+	}
+	for i := 0; i < 2; i++ {
+		if _, _, err := freeze.Thaw(context.Background()); err != nil {
+			t.Fatalf("unexpected thaw error: %v", err)
+		}
+	}
+	if guard.released != 1 {
+		t.Fatalf("expected guard released once, got %d", guard.released)
+	}
+}
+
+func TestDDLDriftCheck_BlocksOnSchemaChange(t *testing.T) {
+	state := workflow.NewMemoryState()
+	inspector := &fakeFreezeSchema{schema: checks.Schema{Tables: []checks.Table{
+		{Name: "orders", Columns: []checks.Column{{Name: "id", Type: "int"}}},
+		{Name: "legacy"},
+	}}}
+	freeze := &DDLFreeze{Guard: &fakeDDLGuard{}, Schema: inspector, State: state, Primary: "db-primary", Owner: "mig-1"}
+	if _, _, err := freeze.Freeze(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	check := &DDLDriftCheck{Schema: inspector, State: state, PrimaryHost: "db-primary"}
+	findings, err := check.Run(context.Background(), checks.Input{})
+	if err != nil || len(findings) != 1 || findings[0].Code != CodeDDLDriftNone {
+		t.Fatalf("expected no drift, got %+v %v", findings, err)
+	}
+
+	inspector.schema = checks.Schema{Tables: []checks.Table{
+		{Name: "orders", Columns: []checks.Column{{Name: "id", Type: "bigint"}}},
+		{Name: "audit"},
+	}}
+	findings, err = check.Run(context.Background(), checks.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Severity != checks.SeverityBlock || findings[0].Code != CodeDDLDriftDetected {
+		t.Fatalf("expected drift block, got %+v", findings)
+	}
+	meta := findings[0].Meta
+	if strings.Join(meta["added"].([]string), ",") != "audit" || strings.Join(meta["removed"].([]string), ",") != "legacy" || strings.Join(meta["changed"].([]string), ",") != "orders" {
+		t.Fatalf("unexpected drift meta: %+v", meta)
+	}
+}
+
+func TestDDLDriftCheck_WarnsWithoutBaseline(t *testing.T) {
+	check := &DDLDriftCheck{Schema: &fakeFreezeSchema{}, State: workflow.NewMemoryState(), PrimaryHost: "db-primary"}
+	findings, err := check.Run(context.Background(), checks.Input{})
+	if err != nil || len(findings) != 1 || findings[0].Code != CodeDDLBaselineMissing {
+		t.Fatalf("expected missing baseline warning, got %+v %v", findings, err)
+	}
+}
+
+func TestLockTableGuard_InsertsAndDeletesOwnerRow(t *testing.T) {
+	conn := &fakeQuerier{}
+	guard := &LockTableGuard{Connect: func(ctx context.Context, host string) (Querier, error) { return conn, nil }}
+	if err := guard.Acquire(context.Background(), "db-primary", "mig-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := guard.Release(context.Background(), "db-primary", "mig-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(conn.queries) != 3 ||
+		!strings.HasPrefix(conn.queries[0], "CREATE TABLE IF NOT EXISTS migratorx.ddl_freeze") ||
+		!strings.HasPrefix(conn.queries[1], "INSERT IGNORE INTO migratorx.ddl_freeze") ||
+		!strings.HasPrefix(conn.queries[2], "DELETE FROM migratorx.ddl_freeze") {
+		t.Fatalf("unexpected statements: %v", conn.queries)
+	}
+
+	guard.Table = "x; DROP TABLE y"
+	if err := guard.Acquire(context.Background(), "db-primary", "mig-1"); err == nil {
+		t.Fatalf("expected invalid table name to be rejected")
+	}
+}
@@ -0,0 +1,314 @@
+package mysql
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"migratorx/internal/checks"
+)
+
+// TablespaceTable is an InnoDB table stored outside its own .ibd file.
+// Tablespace is "innodb_system" or the general tablespace name.
+type TablespaceTable struct {
+	Schema     string
+	Name       string
+	Tablespace string
+}
+
+// TablespaceInspector reads tablespace settings and placement on a host.
+type TablespaceInspector interface {
+	FilePerTable(ctx context.Context, host string) (bool, error)
+	SharedTablespaceTables(ctx context.Context, host string) ([]TablespaceTable, error)
+}
+
+// FrmFile is a table definition file in a 5.7 datadir. HasData is false when
+// no data file for the table sits next to it.
+type FrmFile struct {
+	Schema  string
+	Table   string
+	Path    string
+	HasData bool
+}
+
+// DictionaryReadinessCheck looks for blockers of the 5.7 → 8.0 data
+// dictionary conversion: partitioned tables in shared tablespaces (not
+// supported by 8.0), .frm files without a table, and tablespace settings that
+// make the upgrade slower. Tablespaces and Datadir are each optional.
+type DictionaryReadinessCheck struct {
+	Tablespaces TablespaceInspector
+	Datadir     string
+	Host        string
+}
+
+func (c *DictionaryReadinessCheck) Name() string   { return "dictionary_readiness" }
+func (c *DictionaryReadinessCheck) ReadOnly() bool { return true }
+
+func (c *DictionaryReadinessCheck) Parameters() map[string]interface{} {
+	return map[string]interface{}{"host": c.Host, "datadir": c.Datadir}
+}
+
+func (c *DictionaryReadinessCheck) Run(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+	if c.Tablespaces == nil && c.Datadir == "" {
+		return nil, fmt.Errorf("tablespace inspector or datadir is required")
+	}
+	host := strings.TrimSpace(c.Host)
+	if host == "" {
+		host = input.ReplicaHost
+	}
+	if host == "" {
+		return nil, fmt.Errorf("host is required")
+	}
+
+	findings := []checks.Finding{}
+	var shared map[string]bool
+	if c.Tablespaces != nil {
+		tsFindings, tables, err := c.tablespaceFindings(ctx, host)
+		if err != nil {
+			findings = append(findings, c.unknown(host, "tablespaces", err))
+		} else {
+			findings = append(findings, tsFindings...)
+			shared = map[string]bool{}
+			for _, t := range tables {
+				shared[t.Schema+"/"+t.Name] = true
+			}
+		}
+	}
+	if c.Datadir != "" {
+		frms, err := ScanFrmFiles(c.Datadir)
+		if err != nil {
+			findings = append(findings, c.unknown(host, "datadir", err))
+		} else {
+			findings = append(findings, orphanFrmFindings(host, frms, shared)...)
+		}
+	}
+
+	if len(findings) == 0 {
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityInfo,
+			Code:     CodeDictionaryReady,
+			Message:  fmt.Sprintf("no data dictionary upgrade blockers found on %q", host),
+			Meta:     map[string]interface{}{"host": host},
+		})
+	}
+	return findings, nil
+}
+
+func (c *DictionaryReadinessCheck) tablespaceFindings(ctx context.Context, host string) ([]checks.Finding, []TablespaceTable, error) {
+	filePerTable, err := c.Tablespaces.FilePerTable(ctx, host)
+	if err != nil {
+		return nil, nil, err
+	}
+	tables, err := c.Tablespaces.SharedTablespaceTables(ctx, host)
+	if err != nil {
+		return nil, nil, err
+	}
+	findings := []checks.Finding{}
+	if !filePerTable {
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityWarn,
+			Code:     CodeDictionaryFilePerTableOff,
+			Message:  fmt.Sprintf("innodb_file_per_table is OFF on %q; new tables land in the system tablespace, which never shrinks", host),
+			Meta:     map[string]interface{}{"host": host},
+		})
+	}
+
+	byTablespace := map[string][]string{}
+	partitioned := []string{}
+	for _, t := range tables {
+		name := t.Schema + "." + t.Name
+		if isPartitionName(t.Name) {
+			partitioned = append(partitioned, name)
+			continue
+		}
+		byTablespace[t.Tablespace] = append(byTablespace[t.Tablespace], name)
+	}
+	if len(partitioned) > 0 {
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityBlock,
+			Code:     CodeDictionaryPartitionShared,
+			Message:  fmt.Sprintf("%d table partitions on %q are stored in shared tablespaces, which 8.0 does not support", len(partitioned), host),
+			Meta:     map[string]interface{}{"host": host, "partitions": partitioned},
+		})
+	}
+	names := make([]string, 0, len(byTablespace))
+	for ts := range byTablespace {
+		names = append(names, ts)
+	}
+	sort.Strings(names)
+	for _, ts := range names {
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityWarn,
+			Code:     CodeDictionarySharedTablespace,
+			Message:  fmt.Sprintf("%d tables on %q are stored in shared tablespace %q", len(byTablespace[ts]), host, ts),
+			Meta:     map[string]interface{}{"host": host, "tablespace": ts, "tables": byTablespace[ts]},
+		})
+	}
+	return findings, tables, nil
+}
+
+// orphanFrmFindings reports .frm files without data. Tables in a shared
+// tablespace have no .ibd of their own, so without tablespace information
+// (shared == nil) a missing data file is only a WARN.
+func orphanFrmFindings(host string, frms []FrmFile, shared map[string]bool) []checks.Finding {
+	findings := []checks.Finding{}
+	for _, f := range frms {
+		if f.HasData || shared[f.Schema+"/"+f.Table] {
+			continue
+		}
+		severity := checks.SeverityBlock
+		message := fmt.Sprintf("orphaned %s has no table data; the dictionary upgrade on %q will fail on it", f.Path, host)
+		if shared == nil {
+			severity = checks.SeverityWarn
+			message = fmt.Sprintf("%s has no data file; it is orphaned unless the table lives in a shared tablespace", f.Path)
+		}
+		findings = append(findings, checks.Finding{
+			Severity: severity,
+			Code:     CodeDictionaryOrphanFrm,
+			Message:  message,
+			Meta:     map[string]interface{}{"host": host, "schema": f.Schema, "table": f.Table, "path": f.Path},
+		})
+	}
+	return findings
+}
+
+func (c *DictionaryReadinessCheck) unknown(host string, source string, err error) checks.Finding {
+	return checks.Finding{
+		Severity: checks.SeverityWarn,
+		Code:     CodeDictionaryUnknown,
+		Message:  fmt.Sprintf("unable to read %s on %q: %v", source, host, err),
+		Meta:     map[string]interface{}{"host": host, "source": source},
+	}
+}
+
+// isPartitionName reports whether an InnoDB table name is a partition
+// (name#P#p0, or #p# on case-insensitive file systems).
+func isPartitionName(name string) bool {
+	return strings.Contains(name, "#P#") || strings.Contains(name, "#p#")
+}
+
+// frmDataExtensions are data file extensions of engines that keep a .frm.
+var frmDataExtensions = []string{".ibd", ".MYD", ".CSV", ".ARZ", ".MRG", ".par"}
+
+// ScanFrmFiles lists the .frm files under datadir's schema directories and
+// whether each has a data file. View definitions are skipped. The mysql,
+// performance_schema and sys schemas are left to the upgrade itself.
+func ScanFrmFiles(datadir string) ([]FrmFile, error) {
+	schemas, err := os.ReadDir(datadir)
+	if err != nil {
+		return nil, err
+	}
+	frms := []FrmFile{}
+	for _, schema := range schemas {
+		if !schema.IsDir() || schema.Name() == "mysql" || schema.Name() == "performance_schema" || schema.Name() == "sys" {
+			continue
+		}
+		dir := filepath.Join(datadir, schema.Name())
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		files := map[string]bool{}
+		for _, e := range entries {
+			files[e.Name()] = true
+		}
+		for _, e := range entries {
+			if e.IsDir() || filepath.Ext(e.Name()) != ".frm" || isOrphanName(e.Name()) {
+				continue
+			}
+			path := filepath.Join(dir, e.Name())
+			if isViewFrm(path) {
+				continue
+			}
+			table := strings.TrimSuffix(e.Name(), ".frm")
+			frms = append(frms, FrmFile{Schema: schema.Name(), Table: table, Path: path, HasData: hasDataFile(files, table)})
+		}
+	}
+	return frms, nil
+}
+
+func hasDataFile(files map[string]bool, table string) bool {
+	for _, ext := range frmDataExtensions {
+		if files[table+ext] {
+			return true
+		}
+	}
+	for name := range files {
+		if strings.HasPrefix(name, table+"#P#") || strings.HasPrefix(name, table+"#p#") {
+			return true
+		}
+	}
+	return false
+}
+
+// isViewFrm reports whether a .frm holds a view definition (a text file
+// starting with TYPE=VIEW).
+func isViewFrm(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	head := make([]byte, 9)
+	n, _ := f.Read(head)
+	return bytes.Equal(head[:n], []byte("TYPE=VIEW"))
+}
+
+const (
+	filePerTableQuery     = `SELECT @@GLOBAL.innodb_file_per_table`
+	sharedTablespaceQuery = `SELECT t.NAME, COALESCE(ts.NAME, 'innodb_system')
+FROM information_schema.INNODB_SYS_TABLES t
+LEFT JOIN information_schema.INNODB_SYS_TABLESPACES ts ON ts.SPACE = t.SPACE
+WHERE t.NAME LIKE '%/%' AND (t.SPACE = 0 OR ts.SPACE_TYPE = 'General')
+ORDER BY t.NAME`
+)
+
+// InnoDBTablespaceInspector implements TablespaceInspector with the 5.7
+// InnoDB information_schema tables.
+type InnoDBTablespaceInspector struct {
+	Connect Connector
+}
+
+func (i *InnoDBTablespaceInspector) FilePerTable(ctx context.Context, host string) (bool, error) {
+	if i.Connect == nil {
+		return false, fmt.Errorf("tablespace inspector requires a connector")
+	}
+	q, err := i.Connect(ctx, host)
+	if err != nil {
+		return false, err
+	}
+	var on int
+	if err := queryOne(ctx, q, filePerTableQuery, nil, &on); err != nil {
+		return false, fmt.Errorf("failed to read innodb_file_per_table: %w", err)
+	}
+	return on == 1, nil
+}
+
+func (i *InnoDBTablespaceInspector) SharedTablespaceTables(ctx context.Context, host string) ([]TablespaceTable, error) {
+	if i.Connect == nil {
+		return nil, fmt.Errorf("tablespace inspector requires a connector")
+	}
+	q, err := i.Connect(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := q.QueryContext(ctx, sharedTablespaceQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tablespaces: %w", err)
+	}
+	defer rows.Close()
+	tables := []TablespaceTable{}
+	for rows.Next() {
+		var name, tablespace string
+		if err := rows.Scan(&name, &tablespace); err != nil {
+			return nil, err
+		}
+		schema, table, _ := strings.Cut(name, "/")
+		tables = append(tables, TablespaceTable{Schema: schema, Name: table, Tablespace: tablespace})
+	}
+	return tables, rows.Err()
+}
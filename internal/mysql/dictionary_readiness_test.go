@@ -0,0 +1,111 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"migratorx/internal/checks"
+)
+
+type fakeTablespaceInspector struct {
+	filePerTable bool
+	tables       []TablespaceTable
+}
+
+func (f *fakeTablespaceInspector) FilePerTable(ctx context.Context, host string) (bool, error) {
+	return f.filePerTable, nil
+}
+
+func (f *fakeTablespaceInspector) SharedTablespaceTables(ctx context.Context, host string) ([]TablespaceTable, error) {
+	return f.tables, nil
+}
+
+func writeDatadir(t *testing.T, files map[string]string) string {
+	t.Helper()
+	datadir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(datadir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	return datadir
+}
+
+func TestDictionaryReadinessCheck_ReportsBlockers(t *testing.T) {
+	datadir := writeDatadir(t, map[string]string{
+		"shop/orders.frm":          "",
+		"shop/orders.ibd":          "",
+		"shop/events.frm":          "",
+		"shop/events#P#p2025.ibd":  "",
+		"shop/legacy.frm":          "",
+		"shop/stale.frm":           "",
+		"shop/active_orders.frm":   "TYPE=VIEW\nquery=select 1",
+		"mysql/innodb_index_stats": "",
+	})
+	inspector := &fakeTablespaceInspector{tables: []TablespaceTable{
+		{Schema: "shop", Name: "legacy", Tablespace: "innodb_system"},
+		{Schema: "shop", Name: "audit#P#p0", Tablespace: "ts_audit"},
+	}}
+	check := &DictionaryReadinessCheck{Tablespaces: inspector, Datadir: datadir, Host: "mysql-replica-1"}
+	findings, err := check.Run(context.Background(), checks.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := map[string]checks.Finding{}
+	for _, f := range findings {
+		got[f.Code] = f
+	}
+	if len(findings) != 4 {
+		t.Fatalf("expected four findings, got %+v", findings)
+	}
+	if got[CodeDictionaryFilePerTableOff].Severity != checks.SeverityWarn {
+		t.Fatalf("expected file_per_table warning, got %+v", findings)
+	}
+	if f := got[CodeDictionaryPartitionShared]; f.Severity != checks.SeverityBlock || len(f.Meta["partitions"].([]string)) != 1 {
+		t.Fatalf("expected shared-tablespace partition to block, got %+v", findings)
+	}
+	if f := got[CodeDictionarySharedTablespace]; f.Meta["tablespace"] != "innodb_system" {
+		t.Fatalf("expected system tablespace warning, got %+v", findings)
+	}
+	if f := got[CodeDictionaryOrphanFrm]; f.Severity != checks.SeverityBlock || f.Meta["table"] != "stale" {
+		t.Fatalf("expected only stale.frm to be orphaned, got %+v", findings)
+	}
+}
+
+func TestDictionaryReadinessCheck_DatadirOnlyWarns(t *testing.T) {
+	datadir := writeDatadir(t, map[string]string{"shop/stale.frm": ""})
+	check := &DictionaryReadinessCheck{Datadir: datadir}
+	findings, _ := check.Run(context.Background(), checks.Input{ReplicaHost: "mysql-replica-1"})
+	if len(findings) != 1 || findings[0].Code != CodeDictionaryOrphanFrm || findings[0].Severity != checks.SeverityWarn {
+		t.Fatalf("expected WARN without tablespace information, got %+v", findings)
+	}
+
+	check.Datadir = writeDatadir(t, map[string]string{"shop/orders.frm": "", "shop/orders.ibd": ""})
+	findings, _ = check.Run(context.Background(), checks.Input{ReplicaHost: "mysql-replica-1"})
+	if len(findings) != 1 || findings[0].Code != CodeDictionaryReady {
+		t.Fatalf("expected DICTIONARY_READY, got %+v", findings)
+	}
+}
+
+func TestInnoDBTablespaceInspector_ReadsSharedTables(t *testing.T) {
+	db := openFakeDB(t,
+		fakeResponse{match: "innodb_file_per_table", columns: []string{"innodb_file_per_table"}, rows: [][]driver.Value{{int64(1)}}},
+		fakeResponse{match: "INNODB_SYS_TABLESPACES", columns: []string{"NAME", "TABLESPACE"}, rows: [][]driver.Value{{"shop/legacy", "innodb_system"}}},
+	)
+	inspector := &InnoDBTablespaceInspector{Connect: fakeConnector(db)}
+	on, err := inspector.FilePerTable(context.Background(), "mysql-replica-1")
+	if err != nil || !on {
+		t.Fatalf("expected innodb_file_per_table=ON, got %v (%v)", on, err)
+	}
+	tables, err := inspector.SharedTablespaceTables(context.Background(), "mysql-replica-1")
+	if err != nil || len(tables) != 1 || tables[0].Schema != "shop" || tables[0].Name != "legacy" || tables[0].Tablespace != "innodb_system" {
+		t.Fatalf("unexpected tables %+v (%v)", tables, err)
+	}
+}
@@ -0,0 +1,246 @@
+package mysql
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"migratorx/internal/workflow"
+)
+
+// Drainer removes a replica from read traffic and returns it afterwards.
+// Implementations must be idempotent: draining a drained replica is a no-op.
+// A Kubernetes readiness gate, or any other mechanism without a drainer of
+// its own, is driven through CommandDrainer.
+type Drainer interface {
+	Name() string
+	Drain(ctx context.Context, replica string) error
+	Undrain(ctx context.Context, replica string) error
+}
+
+// ProxySQLDrainer sets the replica's weight to 0 in every hostgroup of
+// ProxySQL's mysql_servers through the admin interface, so no new queries
+// are routed to it while in-flight ones finish. The configured weights are
+// kept in State on the first drain and written back on undrain, which may
+// run in a later process. Hostnames maps a replica to its mysql_servers
+// hostname (an IP or FQDN) when the two differ; a replica that is not in
+// mysql_servers is an error rather than a silent no-op.
+type ProxySQLDrainer struct {
+	Admin     Querier
+	State     workflow.State
+	Hostnames map[string]string
+}
+
+func (d *ProxySQLDrainer) Name() string { return "proxysql" }
+
+func (d *ProxySQLDrainer) Drain(ctx context.Context, replica string) error {
+	if err := d.ready(); err != nil {
+		return err
+	}
+	hostname := d.hostname(replica)
+	weights, err := d.weights(ctx, hostname)
+	if err != nil {
+		return err
+	}
+	// A re-run finds the weights already at 0; the ones saved by the
+	// first drain are what undrain must restore.
+	if _, saved, err := d.savedWeights(hostname); err != nil {
+		return err
+	} else if !saved {
+		if err := workflow.PutArtifact(d.State, proxySQLWeightsKey(hostname), weights); err != nil {
+			return err
+		}
+	}
+	if _, err := d.Admin.ExecContext(ctx, "UPDATE mysql_servers SET weight = 0 WHERE hostname = ?", hostname); err != nil {
+		return err
+	}
+	_, err = d.Admin.ExecContext(ctx, "LOAD MYSQL SERVERS TO RUNTIME")
+	return err
+}
+
+func (d *ProxySQLDrainer) Undrain(ctx context.Context, replica string) error {
+	if err := d.ready(); err != nil {
+		return err
+	}
+	hostname := d.hostname(replica)
+	weights, saved, err := d.savedWeights(hostname)
+	if err != nil {
+		return err
+	}
+	if !saved {
+		return fmt.Errorf("no proxysql weights were saved for %s; restore its mysql_servers weights by hand", hostname)
+	}
+	hostgroups := make([]string, 0, len(weights))
+	for hostgroup := range weights {
+		hostgroups = append(hostgroups, hostgroup)
+	}
+	sort.Strings(hostgroups)
+	for _, hostgroup := range hostgroups {
+		if _, err := d.Admin.ExecContext(ctx, "UPDATE mysql_servers SET weight = ? WHERE hostname = ? AND hostgroup_id = ?", weights[hostgroup], hostname, hostgroup); err != nil {
+			return err
+		}
+	}
+	if _, err := d.Admin.ExecContext(ctx, "LOAD MYSQL SERVERS TO RUNTIME"); err != nil {
+		return err
+	}
+	d.State.Set(proxySQLWeightsKey(hostname), "")
+	return nil
+}
+
+func (d *ProxySQLDrainer) ready() error {
+	if d.Admin == nil {
+		return fmt.Errorf("proxysql admin connection is required")
+	}
+	if d.State == nil {
+		return fmt.Errorf("proxysql drain needs state to keep the replica's weights")
+	}
+	return nil
+}
+
+func (d *ProxySQLDrainer) hostname(replica string) string {
+	if hostname := strings.TrimSpace(d.Hostnames[replica]); hostname != "" {
+		return hostname
+	}
+	return replica
+}
+
+// weights reads hostname's weight per hostgroup.
+func (d *ProxySQLDrainer) weights(ctx context.Context, hostname string) (map[string]int64, error) {
+	rows, err := d.Admin.QueryContext(ctx, "SELECT hostgroup_id, weight FROM mysql_servers WHERE hostname = ?", hostname)
+	if err != nil {
+		return nil, err
+	}
+	weights := map[string]int64{}
+	err = scanRows(rows, func(scan func(...interface{}) error) error {
+		var hostgroup string
+		var weight int64
+		if err := scan(&hostgroup, &weight); err != nil {
+			return err
+		}
+		weights[hostgroup] = weight
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(weights) == 0 {
+		return nil, fmt.Errorf("%s is not in proxysql mysql_servers; map the replica to its hostname", hostname)
+	}
+	return weights, nil
+}
+
+func (d *ProxySQLDrainer) savedWeights(hostname string) (map[string]int64, bool, error) {
+	weights := map[string]int64{}
+	ok, err := workflow.GetArtifact(d.State, proxySQLWeightsKey(hostname), &weights)
+	return weights, ok, err
+}
+
+func proxySQLWeightsKey(hostname string) string {
+	return "drain:proxysql:" + hostname + ":weights"
+}
+
+// HAProxyDrainer sets the replica's server state through the HAProxy runtime
+// API. Address is a unix socket path or host:port; Server defaults to the
+// replica name.
+type HAProxyDrainer struct {
+	Address string
+	Backend string
+	Server  string
+	Timeout time.Duration
+}
+
+func (d *HAProxyDrainer) Name() string { return "haproxy" }
+
+func (d *HAProxyDrainer) Drain(ctx context.Context, replica string) error {
+	return d.setState(ctx, replica, "drain")
+}
+
+func (d *HAProxyDrainer) Undrain(ctx context.Context, replica string) error {
+	return d.setState(ctx, replica, "ready")
+}
+
+func (d *HAProxyDrainer) setState(ctx context.Context, replica string, state string) error {
+	if d.Address == "" || d.Backend == "" {
+		return fmt.Errorf("haproxy address and backend are required")
+	}
+	server := d.Server
+	if server == "" {
+		server = replica
+	}
+	timeout := d.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	network := "tcp"
+	if strings.HasPrefix(d.Address, "/") {
+		network = "unix"
+	}
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, network, d.Address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+	if _, err := fmt.Fprintf(conn, "set server %s/%s state %s\n", d.Backend, server, state); err != nil {
+		return err
+	}
+	// The runtime API answers success with an empty line and errors with text.
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if reply = strings.TrimSpace(reply); reply != "" {
+		return fmt.Errorf("haproxy: %s", reply)
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// CommandDrainer runs external commands, e.g. a kubectl patch that flips a
+// readiness gate. "{replica}" in arguments is replaced with the replica name,
+// which is also exported as MIGRATORX_REPLICA.
+type CommandDrainer struct {
+	Label          string
+	DrainCommand   []string
+	UndrainCommand []string
+}
+
+func (d *CommandDrainer) Name() string {
+	if d.Label != "" {
+		return d.Label
+	}
+	return "command"
+}
+
+func (d *CommandDrainer) Drain(ctx context.Context, replica string) error {
+	return runDrainCommand(ctx, d.DrainCommand, replica)
+}
+
+func (d *CommandDrainer) Undrain(ctx context.Context, replica string) error {
+	return runDrainCommand(ctx, d.UndrainCommand, replica)
+}
+
+func runDrainCommand(ctx context.Context, argv []string, replica string) error {
+	if len(argv) == 0 {
+		return fmt.Errorf("drain command is empty")
+	}
+	args := make([]string, len(argv))
+	for i, a := range argv {
+		args[i] = strings.ReplaceAll(a, "{replica}", replica)
+	}
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Env = append(os.Environ(), "MIGRATORX_REPLICA="+replica)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s: %v: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
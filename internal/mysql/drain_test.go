@@ -0,0 +1,118 @@
+package mysql
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"migratorx/internal/workflow"
+)
+
+func TestHAProxyDrainer_SendsRuntimeCommand(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	defer ln.Close()
+	received := make(chan string, 2)
+	go func() {
+		for i := 0; i < 2; i++ {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			line, _ := bufio.NewReader(conn).ReadString('\n')
+			received <- strings.TrimSpace(line)
+			if strings.Contains(line, "ready") {
+				conn.Write([]byte("No such server.\n"))
+			} else {
+				conn.Write([]byte("\n"))
+			}
+			conn.Close()
+		}
+	}()
+
+	d := &HAProxyDrainer{Address: ln.Addr().String(), Backend: "mysql_read"}
+	if err := d.Drain(context.Background(), "mysql-replica-1"); err != nil {
+		t.Fatalf("unexpected drain error: %v", err)
+	}
+	if got := <-received; got != "set server mysql_read/mysql-replica-1 state drain" {
+		t.Fatalf("unexpected runtime command %q", got)
+	}
+	if err := d.Undrain(context.Background(), "mysql-replica-1"); err == nil || !strings.Contains(err.Error(), "No such server") {
+		t.Fatalf("expected runtime API error to surface, got %v", err)
+	}
+}
+
+func TestCommandDrainer_SubstitutesReplica(t *testing.T) {
+	d := &CommandDrainer{
+		DrainCommand:   []string{"sh", "-c", `test "$0" = mysql-replica-1 && test "$MIGRATORX_REPLICA" = mysql-replica-1`, "{replica}"},
+		UndrainCommand: []string{"sh", "-c", "echo not ready >&2; exit 3"},
+	}
+	if err := d.Drain(context.Background(), "mysql-replica-1"); err != nil {
+		t.Fatalf("unexpected drain error: %v", err)
+	}
+	if err := d.Undrain(context.Background(), "mysql-replica-1"); err == nil || !strings.Contains(err.Error(), "not ready") {
+		t.Fatalf("expected command failure with output, got %v", err)
+	}
+}
+
+// recordingQuerier records each statement with its arguments.
+type recordingQuerier struct {
+	Querier
+	calls []string
+}
+
+func (q *recordingQuerier) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	q.calls = append(q.calls, fmt.Sprint(query, args))
+	return q.Querier.QueryContext(ctx, query, args...)
+}
+
+func (q *recordingQuerier) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	q.calls = append(q.calls, fmt.Sprint(query, args))
+	return q.Querier.ExecContext(ctx, query, args...)
+}
+
+func TestProxySQLDrainer_ZeroesAndRestoresWeights(t *testing.T) {
+	db := openFakeDB(t,
+		fakeResponse{match: "SELECT hostgroup_id, weight FROM mysql_servers", columns: []string{"hostgroup_id", "weight"}, rows: [][]driver.Value{{int64(20), int64(100)}, {int64(30), int64(50)}}},
+		fakeResponse{match: "UPDATE mysql_servers"},
+		fakeResponse{match: "LOAD MYSQL SERVERS TO RUNTIME"},
+	)
+	admin := &recordingQuerier{Querier: db}
+	st := workflow.NewMemoryState()
+	d := &ProxySQLDrainer{Admin: admin, State: st, Hostnames: map[string]string{"mysql-replica-1": "10.0.0.12"}}
+	if err := d.Drain(context.Background(), "mysql-replica-1"); err != nil {
+		t.Fatalf("unexpected drain error: %v", err)
+	}
+	if err := d.Undrain(context.Background(), "mysql-replica-1"); err != nil {
+		t.Fatalf("unexpected undrain error: %v", err)
+	}
+	want := []string{
+		"SELECT hostgroup_id, weight FROM mysql_servers WHERE hostname = ?[10.0.0.12]",
+		"UPDATE mysql_servers SET weight = 0 WHERE hostname = ?[10.0.0.12]",
+		"LOAD MYSQL SERVERS TO RUNTIME[]",
+		"UPDATE mysql_servers SET weight = ? WHERE hostname = ? AND hostgroup_id = ?[100 10.0.0.12 20]",
+		"UPDATE mysql_servers SET weight = ? WHERE hostname = ? AND hostgroup_id = ?[50 10.0.0.12 30]",
+		"LOAD MYSQL SERVERS TO RUNTIME[]",
+	}
+	if strings.Join(admin.calls, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected admin statements:\n%s", strings.Join(admin.calls, "\n"))
+	}
+	if err := d.Undrain(context.Background(), "mysql-replica-1"); err == nil {
+		t.Fatalf("expected undrain without saved weights to fail")
+	}
+}
+
+func TestProxySQLDrainer_RejectsUnknownHostname(t *testing.T) {
+	db := openFakeDB(t, fakeResponse{match: "SELECT hostgroup_id, weight FROM mysql_servers", columns: []string{"hostgroup_id", "weight"}})
+	d := &ProxySQLDrainer{Admin: db, State: workflow.NewMemoryState()}
+	if err := d.Drain(context.Background(), "mysql-replica-1"); err == nil || !strings.Contains(err.Error(), "not in proxysql mysql_servers") {
+		t.Fatalf("expected an unknown hostname to fail, got %v", err)
+	}
+}
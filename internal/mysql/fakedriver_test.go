@@ -0,0 +1,116 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeResponse answers queries containing match.
+type fakeResponse struct {
+	match   string
+	columns []string
+	rows    [][]driver.Value
+	err     error
+}
+
+var (
+	fakeDriverOnce sync.Once
+	fakeDriverMu   sync.Mutex
+	fakeDriverDBs  = map[string][]fakeResponse{}
+)
+
+// openFakeDB returns a *sql.DB whose queries are answered from responses in
+// order of first match.
+func openFakeDB(t *testing.T, responses ...fakeResponse) *sql.DB {
+	fakeDriverOnce.Do(func() { sql.Register("migratorx-fake", fakeDriver{}) })
+	fakeDriverMu.Lock()
+	dsn := fmt.Sprintf("%s-%d", t.Name(), len(fakeDriverDBs))
+	fakeDriverDBs[dsn] = responses
+	fakeDriverMu.Unlock()
+	db, err := sql.Open("migratorx-fake", dsn)
+	if err != nil {
+		t.Fatalf("open fake db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(dsn string) (driver.Conn, error) {
+	fakeDriverMu.Lock()
+	defer fakeDriverMu.Unlock()
+	return &fakeConn{responses: fakeDriverDBs[dsn]}, nil
+}
+
+type fakeConn struct {
+	responses []fakeResponse
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{conn: c, query: query}, nil
+}
+
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return nil, fmt.Errorf("transactions not supported") }
+
+func (c *fakeConn) respond(query string) (fakeResponse, error) {
+	for _, r := range c.responses {
+		if strings.Contains(query, r.match) {
+			return r, r.err
+		}
+	}
+	return fakeResponse{}, fmt.Errorf("fake driver: unexpected query %q", query)
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if _, err := s.conn.respond(s.query); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	r, err := s.conn.respond(s.query)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeRows{columns: r.columns, rows: r.rows}, nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+	i       int
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.i >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.i])
+	r.i++
+	return nil
+}
+
+// fakeConnector returns the same database for every host.
+func fakeConnector(db *sql.DB) func(ctx context.Context, host string) (Querier, error) {
+	return func(ctx context.Context, host string) (Querier, error) { return db, nil }
+}
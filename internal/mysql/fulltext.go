@@ -0,0 +1,222 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"migratorx/internal/checks"
+)
+
+// FulltextSettings are the server settings FULLTEXT indexes are tokenized
+// with. Variables maps variable names to their global values; Parsers lists
+// the active full-text parser plugins.
+type FulltextSettings struct {
+	Variables map[string]string
+	Parsers   []string
+}
+
+// FulltextInspector reads a server's full-text settings.
+type FulltextInspector interface {
+	FulltextSettings(ctx context.Context, host string) (FulltextSettings, error)
+}
+
+// fulltextVariable is a setting baked into FULLTEXT indexes when they are
+// built, so indexes built under a different value return different results
+// until they are rebuilt. Engine names the tables it applies to.
+type fulltextVariable struct {
+	Name   string
+	Engine string
+}
+
+var fulltextVariables = []fulltextVariable{
+	{"innodb_ft_min_token_size", "InnoDB"},
+	{"innodb_ft_max_token_size", "InnoDB"},
+	{"innodb_ft_enable_stopword", "InnoDB"},
+	{"innodb_ft_server_stopword_table", "InnoDB"},
+	{"innodb_ft_user_stopword_table", "InnoDB"},
+	{"ngram_token_size", "InnoDB"},
+	{"ft_min_word_len", "MyISAM"},
+	{"ft_max_word_len", "MyISAM"},
+	{"ft_stopword_file", "MyISAM"},
+}
+
+// builtinFulltextParsers ship with both 5.7 and 8.0.
+var builtinFulltextParsers = []string{"ngram", "mecab"}
+
+// FulltextCheck compares the settings FULLTEXT indexes depend on between the
+// primary and the upgraded replica. A replica that tokenizes or filters
+// stopwords differently answers MATCH queries differently after promotion, so
+// each differing setting warns with the tables whose indexes need a rebuild
+// (ALTER TABLE ... FORCE, or OPTIMIZE TABLE with innodb_optimize_fulltext_only)
+// once it is aligned. A parser plugin the replica lacks warns too: tables
+// declared WITH PARSER it cannot be opened there.
+type FulltextCheck struct {
+	Inspector       FulltextInspector
+	SchemaInspector checks.SchemaInspector
+	Primary         string
+	Replica         string
+}
+
+func (c *FulltextCheck) Name() string   { return "fulltext_compatibility" }
+func (c *FulltextCheck) ReadOnly() bool { return true }
+
+func (c *FulltextCheck) Parameters() map[string]interface{} {
+	return map[string]interface{}{"primary": c.Primary, "replica": c.Replica}
+}
+
+func (c *FulltextCheck) Run(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+	if c.Inspector == nil {
+		return nil, fmt.Errorf("fulltext inspector is required")
+	}
+	if c.SchemaInspector == nil {
+		return nil, fmt.Errorf("schema inspector is required")
+	}
+	if c.Primary == "" || c.Replica == "" {
+		return nil, fmt.Errorf("primary and replica hosts are required")
+	}
+	schema, err := c.SchemaInspector.Schema(ctx, c.Primary)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema on %s: %w", c.Primary, err)
+	}
+	byEngine := map[string][]string{}
+	all := []string{}
+	for _, table := range schema.Tables {
+		if !hasFulltextIndex(table) {
+			continue
+		}
+		engine := strings.ToLower(table.Engine)
+		byEngine[engine] = append(byEngine[engine], table.Name)
+		all = append(all, table.Name)
+	}
+	sort.Strings(all)
+	if len(all) == 0 {
+		return []checks.Finding{{
+			Severity: checks.SeverityInfo,
+			Code:     CodeFulltextNone,
+			Message:  fmt.Sprintf("no FULLTEXT indexes on %q", c.Primary),
+			Meta:     map[string]interface{}{"primary": c.Primary, "replica": c.Replica, "table_count": 0},
+		}}, nil
+	}
+
+	primary, err := c.Inspector.FulltextSettings(ctx, c.Primary)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read full-text settings on %s: %w", c.Primary, err)
+	}
+	replica, err := c.Inspector.FulltextSettings(ctx, c.Replica)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read full-text settings on %s: %w", c.Replica, err)
+	}
+
+	findings := []checks.Finding{}
+	for _, v := range fulltextVariables {
+		primaryValue, okPrimary := primary.Variables[v.Name]
+		replicaValue, okReplica := replica.Variables[v.Name]
+		if !okPrimary || !okReplica || primaryValue == replicaValue {
+			continue
+		}
+		tables := append([]string{}, byEngine[strings.ToLower(v.Engine)]...)
+		if len(tables) == 0 {
+			continue
+		}
+		sort.Strings(tables)
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityWarn,
+			Code:     CodeFulltextSettingMismatch,
+			Message:  fmt.Sprintf("%s is %q on primary %q but %q on replica %q; align it and rebuild the FULLTEXT indexes of %d %s tables", v.Name, primaryValue, c.Primary, replicaValue, c.Replica, len(tables), v.Engine),
+			Meta:     map[string]interface{}{"primary": c.Primary, "replica": c.Replica, "variable": v.Name, "primary_value": primaryValue, "replica_value": replicaValue, "tables": tables, "table_count": len(tables)},
+		})
+	}
+	for _, parser := range primary.Parsers {
+		if containsFold(builtinFulltextParsers, parser) || containsFold(replica.Parsers, parser) {
+			continue
+		}
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityWarn,
+			Code:     CodeFulltextParserMissing,
+			Message:  fmt.Sprintf("full-text parser plugin %q is active on primary %q but not on replica %q; tables with FULLTEXT indexes declared WITH PARSER %s cannot be opened there until a build of it for the new version is installed", parser, c.Primary, c.Replica, parser),
+			Meta:     map[string]interface{}{"primary": c.Primary, "replica": c.Replica, "parser": parser, "tables": all, "table_count": len(all)},
+		})
+	}
+	if len(findings) > 0 {
+		return findings, nil
+	}
+	return []checks.Finding{{
+		Severity: checks.SeverityInfo,
+		Code:     CodeFulltextOK,
+		Message:  fmt.Sprintf("full-text settings on replica %q match the primary for %d tables with FULLTEXT indexes", c.Replica, len(all)),
+		Meta:     map[string]interface{}{"primary": c.Primary, "replica": c.Replica, "tables": all, "table_count": len(all)},
+	}}, nil
+}
+
+func hasFulltextIndex(table checks.Table) bool {
+	for _, idx := range table.Indexes {
+		if strings.EqualFold(idx.Type, "FULLTEXT") {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	fulltextVariablesQuery = `SHOW GLOBAL VARIABLES WHERE Variable_name IN (%s)`
+	fulltextParsersQuery   = `SELECT PLUGIN_NAME FROM information_schema.PLUGINS
+WHERE PLUGIN_TYPE = 'FTPARSER' AND PLUGIN_STATUS = 'ACTIVE'
+ORDER BY PLUGIN_NAME`
+)
+
+// ServerFulltextInspector implements FulltextInspector with SHOW GLOBAL
+// VARIABLES and information_schema.PLUGINS. Variables the server does not
+// have (ngram_token_size before 5.7.6) are left out rather than failing.
+type ServerFulltextInspector struct {
+	Connect Connector
+}
+
+func (i *ServerFulltextInspector) FulltextSettings(ctx context.Context, host string) (FulltextSettings, error) {
+	if i.Connect == nil {
+		return FulltextSettings{}, fmt.Errorf("fulltext inspector requires a connector")
+	}
+	q, err := i.Connect(ctx, host)
+	if err != nil {
+		return FulltextSettings{}, err
+	}
+	placeholders := make([]string, len(fulltextVariables))
+	args := make([]interface{}, len(fulltextVariables))
+	for n, v := range fulltextVariables {
+		placeholders[n] = "?"
+		args[n] = v.Name
+	}
+	rows, err := q.QueryContext(ctx, fmt.Sprintf(fulltextVariablesQuery, strings.Join(placeholders, ", ")), args...)
+	if err != nil {
+		return FulltextSettings{}, fmt.Errorf("failed to read full-text variables: %w", err)
+	}
+	settings := FulltextSettings{Variables: map[string]string{}, Parsers: []string{}}
+	err = scanRows(rows, func(scan func(...interface{}) error) error {
+		var name, value string
+		if err := scan(&name, &value); err != nil {
+			return err
+		}
+		settings.Variables[strings.ToLower(name)] = value
+		return nil
+	})
+	if err != nil {
+		return FulltextSettings{}, fmt.Errorf("failed to read full-text variables: %w", err)
+	}
+	rows, err = q.QueryContext(ctx, fulltextParsersQuery)
+	if err != nil {
+		return FulltextSettings{}, fmt.Errorf("failed to read full-text parser plugins: %w", err)
+	}
+	err = scanRows(rows, func(scan func(...interface{}) error) error {
+		var name string
+		if err := scan(&name); err != nil {
+			return err
+		}
+		settings.Parsers = append(settings.Parsers, name)
+		return nil
+	})
+	if err != nil {
+		return FulltextSettings{}, fmt.Errorf("failed to read full-text parser plugins: %w", err)
+	}
+	return settings, nil
+}
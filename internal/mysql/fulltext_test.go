@@ -0,0 +1,94 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+
+	"migratorx/internal/checks"
+)
+
+type fakeFulltextInspector map[string]FulltextSettings
+
+func (f fakeFulltextInspector) FulltextSettings(ctx context.Context, host string) (FulltextSettings, error) {
+	return f[host], nil
+}
+
+func fulltextSchema() checks.Schema {
+	return checks.Schema{Tables: []checks.Table{
+		{Name: "articles", Engine: "InnoDB", Indexes: []checks.Index{{Name: "ft_body", Type: "FULLTEXT", Columns: []string{"body"}}}},
+		{Name: "legacy_notes", Engine: "MyISAM", Indexes: []checks.Index{{Name: "ft_note", Type: "FULLTEXT", Columns: []string{"note"}}}},
+		{Name: "orders", Engine: "InnoDB", Indexes: []checks.Index{{Name: "idx_user", Type: "BTREE", Columns: []string{"user_id"}}}},
+	}}
+}
+
+func TestFulltextCheck_WarnsOnSettingAndParserDifferences(t *testing.T) {
+	inspector := fakeFulltextInspector{
+		"mysql-primary":   {Variables: map[string]string{"innodb_ft_min_token_size": "2", "ft_min_word_len": "4"}, Parsers: []string{"ngram", "custom_cjk"}},
+		"mysql-replica-1": {Variables: map[string]string{"innodb_ft_min_token_size": "3", "ft_min_word_len": "4"}, Parsers: []string{"ngram"}},
+	}
+	check := &FulltextCheck{Inspector: inspector, SchemaInspector: &fakeFreezeSchema{schema: fulltextSchema()}, Primary: "mysql-primary", Replica: "mysql-replica-1"}
+	findings, err := check.Run(context.Background(), checks.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 2 {
+		t.Fatalf("expected a setting and a parser warning, got %+v", findings)
+	}
+	setting := findings[0]
+	if setting.Code != CodeFulltextSettingMismatch || setting.Meta["variable"] != "innodb_ft_min_token_size" {
+		t.Fatalf("unexpected setting finding: %+v", setting)
+	}
+	if !reflect.DeepEqual(setting.Meta["tables"], []string{"articles"}) {
+		t.Fatalf("expected only the InnoDB table to need a rebuild, got %v", setting.Meta["tables"])
+	}
+	parser := findings[1]
+	if parser.Code != CodeFulltextParserMissing || parser.Meta["parser"] != "custom_cjk" || parser.Meta["table_count"] != 2 {
+		t.Fatalf("unexpected parser finding: %+v", parser)
+	}
+	for _, f := range findings {
+		if problems := checks.ValidateMeta(f.Code, f.Meta); len(problems) > 0 {
+			t.Fatalf("invalid meta: %v", problems)
+		}
+	}
+
+	inspector["mysql-replica-1"] = inspector["mysql-primary"]
+	findings, _ = check.Run(context.Background(), checks.Input{})
+	if len(findings) != 1 || findings[0].Code != CodeFulltextOK || findings[0].Meta["table_count"] != 2 {
+		t.Fatalf("expected matching settings, got %+v", findings)
+	}
+}
+
+func TestFulltextCheck_NoFulltextIndexes(t *testing.T) {
+	schema := checks.Schema{Tables: []checks.Table{{Name: "orders", Engine: "InnoDB"}}}
+	check := &FulltextCheck{Inspector: fakeFulltextInspector{}, SchemaInspector: &fakeFreezeSchema{schema: schema}, Primary: "mysql-primary", Replica: "mysql-replica-1"}
+	findings, err := check.Run(context.Background(), checks.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Code != CodeFulltextNone {
+		t.Fatalf("expected no-fulltext info, got %+v", findings)
+	}
+}
+
+func TestServerFulltextInspector_ReadsVariablesAndParsers(t *testing.T) {
+	db := openFakeDB(t,
+		fakeResponse{match: "SHOW GLOBAL VARIABLES", columns: []string{"Variable_name", "Value"}, rows: [][]driver.Value{
+			{"innodb_ft_min_token_size", "3"},
+			{"ngram_token_size", "2"},
+		}},
+		fakeResponse{match: "FTPARSER", columns: []string{"PLUGIN_NAME"}, rows: [][]driver.Value{{"ngram"}}},
+	)
+	inspector := &ServerFulltextInspector{Connect: fakeConnector(db)}
+	settings, err := inspector.FulltextSettings(context.Background(), "mysql-primary")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if settings.Variables["innodb_ft_min_token_size"] != "3" || settings.Variables["ngram_token_size"] != "2" {
+		t.Fatalf("unexpected variables: %+v", settings.Variables)
+	}
+	if !reflect.DeepEqual(settings.Parsers, []string{"ngram"}) {
+		t.Fatalf("unexpected parsers: %v", settings.Parsers)
+	}
+}
@@ -0,0 +1,185 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// DefaultProbeTable is the table used for heartbeat round trips when the plan
+// does not name one.
+const DefaultProbeTable = "migratorx.heartbeat"
+
+// HeartbeatProber performs write-path operations through an application endpoint
+// (proxy or DNS name). Insert reports the server identity that executed the write.
+type HeartbeatProber interface {
+	Insert(ctx context.Context, endpoint string, table string, token string) (string, error)
+	Select(ctx context.Context, endpoint string, table string, token string) (bool, error)
+	Delete(ctx context.Context, endpoint string, table string, token string) error
+}
+
+// HeartbeatProbe verifies after promotion that application traffic reaches the
+// new primary by performing an INSERT/SELECT/DELETE round trip on a probe table.
+type HeartbeatProbe struct {
+	Prober          HeartbeatProber
+	Endpoint        string
+	Table           string
+	ExpectedPrimary string
+	Token           string
+	Logger          *log.Logger
+}
+
+// Run performs the round trip and returns structured findings.
+// - BLOCK (HEARTBEAT_FAILED) when any write-path operation fails
+// - BLOCK (HEARTBEAT_WRONG_PRIMARY) when another host than ExpectedPrimary wrote
+// - BLOCK (HEARTBEAT_ROW_MISSING) when the row cannot be read back
+// - WARN (HEARTBEAT_CLEANUP_FAILED) when the probe row could not be cleaned up
+func (p *HeartbeatProbe) Run(ctx context.Context) (Summary, []Finding, error) {
+	if p.Prober == nil {
+		return Summary{}, nil, fmt.Errorf("heartbeat prober is required")
+	}
+	endpoint := strings.TrimSpace(p.Endpoint)
+	if endpoint == "" {
+		return Summary{}, nil, fmt.Errorf("heartbeat endpoint is required")
+	}
+	if p.Logger == nil {
+		p.Logger = log.Default()
+	}
+	table := p.Table
+	if strings.TrimSpace(table) == "" {
+		table = DefaultProbeTable
+	}
+	token := p.Token
+	if token == "" {
+		token = fmt.Sprintf("migratorx-%d", time.Now().UnixNano())
+	}
+
+	var summary Summary
+	findings := []Finding{}
+	meta := map[string]interface{}{"endpoint": endpoint, "table": table, "token": token}
+
+	p.Logger.Printf("heartbeat insert via %s", endpoint)
+	servedBy, err := p.Prober.Insert(ctx, endpoint, table, token)
+	if err != nil {
+		return appendHeartbeatFailure(summary, findings, fmt.Sprintf("heartbeat insert via %q failed: %v", endpoint, err), meta)
+	}
+	if p.ExpectedPrimary != "" && !sameHost(servedBy, p.ExpectedPrimary) {
+		block := Finding{
+			Severity: SeverityBlock,
+			Code:     CodeHeartbeatWrongPrimary,
+			Message:  fmt.Sprintf("heartbeat write via %q was served by %q, expected new primary %q", endpoint, servedBy, p.ExpectedPrimary),
+			Meta:     withMeta(meta, "served_by", servedBy),
+		}
+		findings = append(findings, block)
+		applySummary(&summary, []Finding{block})
+	}
+
+	found, err := p.Prober.Select(ctx, endpoint, table, token)
+	if err != nil {
+		return appendHeartbeatFailure(summary, findings, fmt.Sprintf("heartbeat select via %q failed: %v", endpoint, err), meta)
+	}
+	if !found {
+		block := Finding{Severity: SeverityBlock, Code: CodeHeartbeatRowMissing, Message: fmt.Sprintf("heartbeat row not readable via %q after insert", endpoint), Meta: meta}
+		findings = append(findings, block)
+		applySummary(&summary, []Finding{block})
+	}
+
+	if err := p.Prober.Delete(ctx, endpoint, table, token); err != nil {
+		warn := Finding{Severity: SeverityWarn, Code: CodeHeartbeatCleanupFailed, Message: fmt.Sprintf("heartbeat cleanup via %q failed: %v", endpoint, err), Meta: meta}
+		findings = append(findings, warn)
+		applySummary(&summary, []Finding{warn})
+	}
+
+	if !hasBlock(findings) {
+		info := Finding{Severity: SeverityInfo, Code: CodeHeartbeatOK, Message: fmt.Sprintf("heartbeat round trip via %q reached %q", endpoint, servedBy), Meta: withMeta(meta, "served_by", servedBy)}
+		findings = append(findings, info)
+		applySummary(&summary, []Finding{info})
+	}
+
+	return summary, findings, nil
+}
+
+func appendHeartbeatFailure(summary Summary, findings []Finding, message string, meta map[string]interface{}) (Summary, []Finding, error) {
+	block := Finding{Severity: SeverityBlock, Code: CodeHeartbeatFailed, Message: message, Meta: meta}
+	applySummary(&summary, []Finding{block})
+	return summary, append(findings, block), nil
+}
+
+// sameHost reports whether the server identity a write reported names host.
+// @@hostname is usually the short machine name while topology hosts may be
+// fully qualified, so a match on the first DNS label is enough.
+func sameHost(servedBy string, host string) bool {
+	if strings.EqualFold(servedBy, host) {
+		return true
+	}
+	short := func(name string) string { return strings.SplitN(name, ".", 2)[0] }
+	return servedBy != "" && strings.EqualFold(short(servedBy), short(host))
+}
+
+func withMeta(meta map[string]interface{}, key string, value interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(meta)+1)
+	for k, v := range meta {
+		out[k] = v
+	}
+	out[key] = value
+	return out
+}
+
+// SQLHeartbeatProber implements HeartbeatProber with SQL through the endpoint.
+// The probe table is created on first use as (token, created_at), and the
+// serving host is the @@hostname reported in the session that wrote the row.
+// Connect must return a writable session.
+type SQLHeartbeatProber struct {
+	Connect Connector
+}
+
+func (p *SQLHeartbeatProber) Insert(ctx context.Context, endpoint string, table string, token string) (string, error) {
+	q, err := p.open(ctx, endpoint, table)
+	if err != nil {
+		return "", err
+	}
+	if _, err := q.ExecContext(ctx, fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (token VARCHAR(191) NOT NULL PRIMARY KEY, created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP)", table)); err != nil {
+		return "", err
+	}
+	if _, err := q.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (token) VALUES (?)", table), token); err != nil {
+		return "", err
+	}
+	var hostname string
+	if err := queryOne(ctx, q, "SELECT @@hostname", nil, &hostname); err != nil {
+		return "", fmt.Errorf("failed to read the serving host: %w", err)
+	}
+	return hostname, nil
+}
+
+func (p *SQLHeartbeatProber) Select(ctx context.Context, endpoint string, table string, token string) (bool, error) {
+	q, err := p.open(ctx, endpoint, table)
+	if err != nil {
+		return false, err
+	}
+	var count int
+	if err := queryOne(ctx, q, fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE token = ?", table), []interface{}{token}, &count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (p *SQLHeartbeatProber) Delete(ctx context.Context, endpoint string, table string, token string) error {
+	q, err := p.open(ctx, endpoint, table)
+	if err != nil {
+		return err
+	}
+	_, err = q.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE token = ?", table), token)
+	return err
+}
+
+func (p *SQLHeartbeatProber) open(ctx context.Context, endpoint string, table string) (Querier, error) {
+	if p.Connect == nil {
+		return nil, fmt.Errorf("connector is required")
+	}
+	if !lockTableName.MatchString(table) {
+		return nil, fmt.Errorf("invalid probe table %q", table)
+	}
+	return p.Connect(ctx, endpoint)
+}
@@ -0,0 +1,120 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+type fakeProber struct {
+	servedBy  string
+	found     bool
+	insertErr error
+	deleteErr error
+	deleted   bool
+}
+
+func (f *fakeProber) Insert(ctx context.Context, endpoint string, table string, token string) (string, error) {
+	return f.servedBy, f.insertErr
+}
+
+func (f *fakeProber) Select(ctx context.Context, endpoint string, table string, token string) (bool, error) {
+	return f.found, nil
+}
+
+func (f *fakeProber) Delete(ctx context.Context, endpoint string, table string, token string) error {
+	f.deleted = true
+	return f.deleteErr
+}
+
+func TestHeartbeatProbe_RoundTripInfo(t *testing.T) {
+	prober := &fakeProber{servedBy: "mysql-replica-1", found: true}
+	probe := &HeartbeatProbe{Prober: prober, Endpoint: "app-db", ExpectedPrimary: "mysql-replica-1", Token: "t"}
+	summary, findings, err := probe.Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Block != 0 || summary.Info != 1 {
+		t.Fatalf("expected single INFO, got %+v %+v", summary, findings)
+	}
+	if !prober.deleted {
+		t.Fatalf("expected probe row cleanup")
+	}
+}
+
+func TestHeartbeatProbe_WrongServerBlocks(t *testing.T) {
+	prober := &fakeProber{servedBy: "mysql-primary", found: true}
+	probe := &HeartbeatProbe{Prober: prober, Endpoint: "app-db", ExpectedPrimary: "mysql-replica-1"}
+	summary, findings, err := probe.Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Block != 1 || findings[0].Code != CodeHeartbeatWrongPrimary {
+		t.Fatalf("expected BLOCK when write reaches old primary, got %+v %+v", summary, findings)
+	}
+}
+
+func TestHeartbeatProbe_InsertErrorBlocks(t *testing.T) {
+	prober := &fakeProber{insertErr: errors.New("read only")}
+	probe := &HeartbeatProbe{Prober: prober, Endpoint: "app-db"}
+	summary, findings, err := probe.Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Block != 1 || findings[len(findings)-1].Code != CodeHeartbeatFailed {
+		t.Fatalf("expected BLOCK on insert failure, got %+v", summary)
+	}
+	if prober.deleted {
+		t.Fatalf("cleanup should not run after failed insert")
+	}
+}
+
+func TestHeartbeatProbe_CleanupFailureWarns(t *testing.T) {
+	prober := &fakeProber{servedBy: "mysql-replica-1", found: true, deleteErr: errors.New("boom")}
+	probe := &HeartbeatProbe{Prober: prober, Endpoint: "app-db", ExpectedPrimary: "mysql-replica-1"}
+	summary, _, err := probe.Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Warn != 1 || summary.Block != 0 {
+		t.Fatalf("expected WARN on cleanup failure, got %+v", summary)
+	}
+}
+
+func TestHeartbeatProbe_MatchesShortHostname(t *testing.T) {
+	prober := &fakeProber{servedBy: "MYSQL-REPLICA-1", found: true}
+	probe := &HeartbeatProbe{Prober: prober, Endpoint: "app-db", ExpectedPrimary: "mysql-replica-1.db.internal", Token: "t"}
+	summary, findings, err := probe.Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Block != 0 || findings[0].Code != CodeHeartbeatOK {
+		t.Fatalf("expected the short hostname to match, got %+v", findings)
+	}
+}
+
+func TestSQLHeartbeatProber_RoundTrip(t *testing.T) {
+	db := openFakeDB(t,
+		fakeResponse{match: "CREATE TABLE IF NOT EXISTS app.heartbeat"},
+		fakeResponse{match: "INSERT INTO app.heartbeat"},
+		fakeResponse{match: "@@hostname", columns: []string{"@@hostname"}, rows: [][]driver.Value{{"mysql-replica-1"}}},
+		fakeResponse{match: "SELECT COUNT(*) FROM app.heartbeat", columns: []string{"COUNT(*)"}, rows: [][]driver.Value{{int64(1)}}},
+		fakeResponse{match: "DELETE FROM app.heartbeat"},
+	)
+	probe := &HeartbeatProbe{Prober: &SQLHeartbeatProber{Connect: fakeConnector(db)}, Endpoint: "app-db", Table: "app.heartbeat", ExpectedPrimary: "mysql-replica-1", Token: "t"}
+	summary, findings, err := probe.Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Block != 0 || summary.Warn != 0 || findings[0].Code != CodeHeartbeatOK || findings[0].Meta["served_by"] != "mysql-replica-1" {
+		t.Fatalf("expected a clean round trip, got %+v %+v", summary, findings)
+	}
+}
+
+func TestSQLHeartbeatProber_RejectsInvalidTable(t *testing.T) {
+	prober := &SQLHeartbeatProber{Connect: fakeConnector(openFakeDB(t))}
+	if _, err := prober.Insert(context.Background(), "app-db", "app.heartbeat; DROP TABLE x", "t"); err == nil {
+		t.Fatalf("expected an invalid table name to be rejected")
+	}
+}
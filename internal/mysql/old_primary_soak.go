@@ -0,0 +1,190 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"migratorx/internal/checks"
+	"migratorx/internal/workflow"
+)
+
+// Old primary soak defaults.
+const (
+	DefaultSoakDuration     = 5 * time.Minute
+	DefaultSoakPollInterval = 10 * time.Second
+)
+
+// BinlogPosition is a host's current binary log coordinate.
+type BinlogPosition struct {
+	File     string
+	Position int64
+}
+
+func (p BinlogPosition) String() string { return fmt.Sprintf("%s:%d", p.File, p.Position) }
+
+// WriteStateInspector reads whether a host accepts writes and where its
+// binary log currently ends.
+type WriteStateInspector interface {
+	ReadOnly(ctx context.Context, host string) (readOnly bool, superReadOnly bool, err error)
+	BinlogPosition(ctx context.Context, host string) (BinlogPosition, error)
+}
+
+// OldPrimarySoakCheck watches the demoted primary for the soak period after
+// promotion. It blocks as soon as the host is writable or its binlog position
+// moves, which means something (usually an application still pointed at the
+// stale endpoint) wrote to it. The first violation is also sent to Notifier.
+type OldPrimarySoakCheck struct {
+	Inspector    WriteStateInspector
+	Host         string
+	Duration     time.Duration
+	PollInterval time.Duration
+	Migration    string
+	Notifier     workflow.Notifier
+}
+
+func (c *OldPrimarySoakCheck) Name() string   { return "old_primary_soak" }
+func (c *OldPrimarySoakCheck) ReadOnly() bool { return true }
+
+func (c *OldPrimarySoakCheck) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"host":          c.Host,
+		"duration":      c.Duration.String(),
+		"poll_interval": c.PollInterval.String(),
+	}
+}
+
+func (c *OldPrimarySoakCheck) Run(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+	if c.Inspector == nil {
+		return nil, fmt.Errorf("write state inspector is required")
+	}
+	host := strings.TrimSpace(c.Host)
+	if host == "" {
+		host = input.PrimaryHost
+	}
+	if host == "" {
+		return nil, fmt.Errorf("old primary host is required")
+	}
+	if c.Duration <= 0 {
+		c.Duration = DefaultSoakDuration
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = DefaultSoakPollInterval
+	}
+
+	baseline, err := c.Inspector.BinlogPosition(ctx, host)
+	if err != nil {
+		return c.unknown(host, err), nil
+	}
+	deadline := time.Now().Add(c.Duration)
+	samples := 0
+	for {
+		readOnly, superReadOnly, err := c.Inspector.ReadOnly(ctx, host)
+		if err != nil {
+			return c.unknown(host, err), nil
+		}
+		position, err := c.Inspector.BinlogPosition(ctx, host)
+		if err != nil {
+			return c.unknown(host, err), nil
+		}
+		samples++
+		meta := map[string]interface{}{
+			"host":            host,
+			"read_only":       readOnly,
+			"super_read_only": superReadOnly,
+			"binlog_baseline": baseline.String(),
+			"binlog_position": position.String(),
+			"samples":         samples,
+		}
+		switch {
+		case !readOnly:
+			return c.violation(ctx, CodeOldPrimaryWritable, fmt.Sprintf("old primary %q is writable (read_only=OFF) during the soak period", host), meta), nil
+		case position != baseline:
+			return c.violation(ctx, CodeOldPrimaryWrites, fmt.Sprintf("old primary %q received writes during the soak period (binlog moved from %s to %s)", host, baseline, position), meta), nil
+		}
+		if !time.Now().Before(deadline) {
+			meta["duration"] = c.Duration.String()
+			findings := []checks.Finding{{
+				Severity: checks.SeverityInfo,
+				Code:     CodeOldPrimarySoakOK,
+				Message:  fmt.Sprintf("old primary %q stayed read-only with no writes for %s", host, c.Duration),
+				Meta:     meta,
+			}}
+			if !superReadOnly {
+				findings = append(findings, checks.Finding{
+					Severity: checks.SeverityWarn,
+					Code:     CodeOldPrimaryNotSuperReadOnly,
+					Message:  fmt.Sprintf("old primary %q has read_only=ON but super_read_only=OFF; privileged accounts can still write", host),
+					Meta:     map[string]interface{}{"host": host},
+				})
+			}
+			return findings, nil
+		}
+		if err := sleepContext(ctx, c.PollInterval); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *OldPrimarySoakCheck) unknown(host string, err error) []checks.Finding {
+	return []checks.Finding{{
+		Severity: checks.SeverityWarn,
+		Code:     CodeOldPrimarySoakUnknown,
+		Message:  fmt.Sprintf("unable to verify old primary %q is read-only: %v", host, err),
+		Meta:     map[string]interface{}{"host": host},
+	}}
+}
+
+func (c *OldPrimarySoakCheck) violation(ctx context.Context, code string, message string, meta map[string]interface{}) []checks.Finding {
+	if c.Notifier != nil {
+		note := workflow.Notification{Migration: c.Migration, Event: workflow.EventOldPrimaryWrite, Message: message, Meta: meta}
+		if err := c.Notifier.Notify(ctx, note); err != nil {
+			meta["notification_error"] = err.Error()
+		}
+	}
+	return []checks.Finding{{Severity: checks.SeverityBlock, Code: code, Message: message, Meta: meta}}
+}
+
+const (
+	writeStateQuery   = `SELECT @@GLOBAL.read_only, @@GLOBAL.super_read_only`
+	binlogStatusQuery = `SHOW MASTER STATUS`
+)
+
+// ServerWriteStateInspector implements WriteStateInspector with global
+// variables and SHOW MASTER STATUS.
+type ServerWriteStateInspector struct {
+	Connect Connector
+}
+
+func (i *ServerWriteStateInspector) ReadOnly(ctx context.Context, host string) (bool, bool, error) {
+	if i.Connect == nil {
+		return false, false, fmt.Errorf("write state inspector requires a connector")
+	}
+	q, err := i.Connect(ctx, host)
+	if err != nil {
+		return false, false, err
+	}
+	var readOnly, superReadOnly int
+	if err := queryOne(ctx, q, writeStateQuery, nil, &readOnly, &superReadOnly); err != nil {
+		return false, false, fmt.Errorf("failed to read read_only: %w", err)
+	}
+	return readOnly == 1, superReadOnly == 1, nil
+}
+
+func (i *ServerWriteStateInspector) BinlogPosition(ctx context.Context, host string) (BinlogPosition, error) {
+	if i.Connect == nil {
+		return BinlogPosition{}, fmt.Errorf("write state inspector requires a connector")
+	}
+	q, err := i.Connect(ctx, host)
+	if err != nil {
+		return BinlogPosition{}, err
+	}
+	var pos BinlogPosition
+	var doDB, ignoreDB, gtids sql.NullString
+	if err := queryOne(ctx, q, binlogStatusQuery, nil, &pos.File, &pos.Position, &doDB, &ignoreDB, &gtids); err != nil {
+		return BinlogPosition{}, fmt.Errorf("failed to read binlog position: %w", err)
+	}
+	return pos, nil
+}
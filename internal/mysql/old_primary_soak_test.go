@@ -0,0 +1,106 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"migratorx/internal/checks"
+	"migratorx/internal/workflow"
+)
+
+type fakeWriteStateInspector struct {
+	readOnly      bool
+	superReadOnly bool
+	positions     []int64
+	reads         int
+}
+
+func (f *fakeWriteStateInspector) ReadOnly(ctx context.Context, host string) (bool, bool, error) {
+	return f.readOnly, f.superReadOnly, nil
+}
+
+func (f *fakeWriteStateInspector) BinlogPosition(ctx context.Context, host string) (BinlogPosition, error) {
+	i := f.reads
+	if i >= len(f.positions) {
+		i = len(f.positions) - 1
+	}
+	f.reads++
+	return BinlogPosition{File: "binlog.000042", Position: f.positions[i]}, nil
+}
+
+type fakeNotifier struct {
+	sent []workflow.Notification
+}
+
+func (f *fakeNotifier) Notify(ctx context.Context, n workflow.Notification) error {
+	f.sent = append(f.sent, n)
+	return nil
+}
+
+func TestOldPrimarySoakCheck_FrozenBinlogPasses(t *testing.T) {
+	inspector := &fakeWriteStateInspector{readOnly: true, superReadOnly: true, positions: []int64{1200}}
+	check := &OldPrimarySoakCheck{Inspector: inspector, Duration: 5 * time.Millisecond, PollInterval: time.Millisecond}
+	findings, err := check.Run(context.Background(), checks.Input{PrimaryHost: "mysql-primary"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Code != CodeOldPrimarySoakOK {
+		t.Fatalf("expected OLD_PRIMARY_SOAK_OK, got %+v", findings)
+	}
+	if samples := findings[0].Meta["samples"].(int); samples < 2 {
+		t.Fatalf("expected repeated samples during the soak, got %d", samples)
+	}
+}
+
+func TestOldPrimarySoakCheck_BinlogMovementBlocksAndNotifies(t *testing.T) {
+	inspector := &fakeWriteStateInspector{readOnly: true, superReadOnly: true, positions: []int64{1200, 1200, 1200, 1850}}
+	notifier := &fakeNotifier{}
+	check := &OldPrimarySoakCheck{Inspector: inspector, Host: "mysql-primary", Duration: time.Minute, PollInterval: time.Millisecond, Migration: "m", Notifier: notifier}
+	findings, err := check.Run(context.Background(), checks.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Code != CodeOldPrimaryWrites || findings[0].Severity != checks.SeverityBlock {
+		t.Fatalf("expected OLD_PRIMARY_WRITES_DETECTED, got %+v", findings)
+	}
+	if findings[0].Meta["binlog_position"] != "binlog.000042:1850" {
+		t.Fatalf("unexpected meta: %+v", findings[0].Meta)
+	}
+	if len(notifier.sent) != 1 || notifier.sent[0].Event != workflow.EventOldPrimaryWrite {
+		t.Fatalf("expected one notification, got %+v", notifier.sent)
+	}
+}
+
+func TestOldPrimarySoakCheck_WritableBlocksAndWeakReadOnlyWarns(t *testing.T) {
+	inspector := &fakeWriteStateInspector{positions: []int64{1200}}
+	check := &OldPrimarySoakCheck{Inspector: inspector, Host: "mysql-primary", Duration: time.Minute, PollInterval: time.Millisecond}
+	findings, _ := check.Run(context.Background(), checks.Input{})
+	if len(findings) != 1 || findings[0].Code != CodeOldPrimaryWritable {
+		t.Fatalf("expected OLD_PRIMARY_WRITABLE, got %+v", findings)
+	}
+
+	inspector = &fakeWriteStateInspector{readOnly: true, positions: []int64{1200}}
+	check = &OldPrimarySoakCheck{Inspector: inspector, Host: "mysql-primary", Duration: time.Millisecond, PollInterval: time.Millisecond}
+	findings, _ = check.Run(context.Background(), checks.Input{})
+	if len(findings) != 2 || findings[1].Code != CodeOldPrimaryNotSuperReadOnly {
+		t.Fatalf("expected super_read_only warning, got %+v", findings)
+	}
+}
+
+func TestServerWriteStateInspector_ReadsFlagsAndPosition(t *testing.T) {
+	db := openFakeDB(t,
+		fakeResponse{match: "super_read_only", columns: []string{"read_only", "super_read_only"}, rows: [][]driver.Value{{int64(1), int64(0)}}},
+		fakeResponse{match: "SHOW MASTER STATUS", columns: []string{"File", "Position", "Binlog_Do_DB", "Binlog_Ignore_DB", "Executed_Gtid_Set"}, rows: [][]driver.Value{{"binlog.000042", int64(1200), "", "", "uuid:1-10"}}},
+	)
+	inspector := &ServerWriteStateInspector{Connect: fakeConnector(db)}
+	readOnly, superReadOnly, err := inspector.ReadOnly(context.Background(), "mysql-primary")
+	if err != nil || !readOnly || superReadOnly {
+		t.Fatalf("unexpected read-only state %v/%v (%v)", readOnly, superReadOnly, err)
+	}
+	pos, err := inspector.BinlogPosition(context.Background(), "mysql-primary")
+	if err != nil || pos.String() != "binlog.000042:1200" {
+		t.Fatalf("unexpected binlog position %s (%v)", pos, err)
+	}
+}
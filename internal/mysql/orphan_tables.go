@@ -0,0 +1,177 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"migratorx/internal/checks"
+)
+
+// OrphanTable is a leftover intermediate table from an interrupted ALTER.
+// Source is "dictionary" for InnoDB dictionary entries and "datadir" for
+// files; Path is set for files.
+type OrphanTable struct {
+	Schema string
+	Name   string
+	Source string
+	Path   string `json:",omitempty"`
+}
+
+// OrphanTableInspector lists #sql- tables left on a host.
+type OrphanTableInspector interface {
+	OrphanTables(ctx context.Context, host string) ([]OrphanTable, error)
+}
+
+// OrphanTableCheck blocks when a host has leftover #sql-* tables. The 8.0
+// in-place upgrade converts every table into the new data dictionary, and
+// orphaned intermediate tables without a matching .frm make it fail midway.
+type OrphanTableCheck struct {
+	Inspector OrphanTableInspector
+	Host      string
+}
+
+func (c *OrphanTableCheck) Name() string   { return "orphan_temp_tables" }
+func (c *OrphanTableCheck) ReadOnly() bool { return true }
+
+func (c *OrphanTableCheck) Parameters() map[string]interface{} {
+	return map[string]interface{}{"host": c.Host}
+}
+
+func (c *OrphanTableCheck) Run(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+	if c.Inspector == nil {
+		return nil, fmt.Errorf("orphan table inspector is required")
+	}
+	host := strings.TrimSpace(c.Host)
+	if host == "" {
+		host = input.ReplicaHost
+	}
+	if host == "" {
+		return nil, fmt.Errorf("host is required")
+	}
+	orphans, err := c.Inspector.OrphanTables(ctx, host)
+	if err != nil {
+		return []checks.Finding{{
+			Severity: checks.SeverityWarn,
+			Code:     CodeOrphanTablesUnknown,
+			Message:  fmt.Sprintf("unable to scan %q for orphaned #sql- tables: %v", host, err),
+			Meta:     map[string]interface{}{"host": host},
+		}}, nil
+	}
+	if len(orphans) == 0 {
+		return []checks.Finding{{
+			Severity: checks.SeverityInfo,
+			Code:     CodeOrphanTablesNone,
+			Message:  fmt.Sprintf("no orphaned #sql- tables on %q", host),
+			Meta:     map[string]interface{}{"host": host},
+		}}, nil
+	}
+	findings := []checks.Finding{}
+	for _, o := range orphans {
+		meta := map[string]interface{}{"host": host, "schema": o.Schema, "table": o.Name, "source": o.Source}
+		where := fmt.Sprintf("%s.%s", o.Schema, o.Name)
+		if o.Path != "" {
+			meta["path"] = o.Path
+			where = o.Path
+		}
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityBlock,
+			Code:     CodeOrphanTablesFound,
+			Message:  fmt.Sprintf("orphaned intermediate table %s on %q (from an interrupted ALTER) will break the in-place upgrade", where, host),
+			Meta:     meta,
+		})
+	}
+	return findings, nil
+}
+
+// isOrphanName reports whether a table or file name is an ALTER intermediate.
+func isOrphanName(name string) bool {
+	return strings.HasPrefix(name, "#sql-") || strings.HasPrefix(name, "#sql2-")
+}
+
+// DatadirOrphanInspector scans a local MySQL datadir for #sql- files. It only
+// works when migratorx runs on the host being checked.
+type DatadirOrphanInspector struct {
+	Datadir string
+}
+
+func (i *DatadirOrphanInspector) OrphanTables(ctx context.Context, host string) ([]OrphanTable, error) {
+	if strings.TrimSpace(i.Datadir) == "" {
+		return nil, fmt.Errorf("datadir is required")
+	}
+	schemas, err := os.ReadDir(i.Datadir)
+	if err != nil {
+		return nil, err
+	}
+	orphans := []OrphanTable{}
+	for _, schema := range schemas {
+		if !schema.IsDir() {
+			continue
+		}
+		files, err := os.ReadDir(filepath.Join(i.Datadir, schema.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			if f.IsDir() || !isOrphanName(f.Name()) {
+				continue
+			}
+			orphans = append(orphans, OrphanTable{
+				Schema: schema.Name(),
+				Name:   strings.TrimSuffix(f.Name(), filepath.Ext(f.Name())),
+				Source: "datadir",
+				Path:   filepath.Join(i.Datadir, schema.Name(), f.Name()),
+			})
+		}
+	}
+	sort.Slice(orphans, func(a, b int) bool { return orphans[a].Path < orphans[b].Path })
+	return orphans, nil
+}
+
+const (
+	orphanTablesQuery57 = `SELECT NAME FROM information_schema.INNODB_SYS_TABLES WHERE NAME LIKE '%#sql%' ORDER BY NAME`
+	orphanTablesQuery80 = `SELECT NAME FROM information_schema.INNODB_TABLES WHERE NAME LIKE '%#sql%' ORDER BY NAME`
+)
+
+// DictionaryOrphanInspector lists #sql- tables registered in the InnoDB
+// dictionary, trying the 5.7 table first and the 8.0 name second.
+type DictionaryOrphanInspector struct {
+	Connect Connector
+}
+
+func (i *DictionaryOrphanInspector) OrphanTables(ctx context.Context, host string) ([]OrphanTable, error) {
+	if i.Connect == nil {
+		return nil, fmt.Errorf("orphan table inspector requires a connector")
+	}
+	q, err := i.Connect(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := q.QueryContext(ctx, orphanTablesQuery57)
+	if err != nil {
+		rows, err = q.QueryContext(ctx, orphanTablesQuery80)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read InnoDB dictionary: %w", err)
+	}
+	defer rows.Close()
+	orphans := []OrphanTable{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		schema, table := name, ""
+		if slash := strings.Index(name, "/"); slash >= 0 {
+			schema, table = name[:slash], name[slash+1:]
+		}
+		if !isOrphanName(table) {
+			continue
+		}
+		orphans = append(orphans, OrphanTable{Schema: schema, Name: table, Source: "dictionary"})
+	}
+	return orphans, rows.Err()
+}
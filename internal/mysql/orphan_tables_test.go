@@ -0,0 +1,67 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"migratorx/internal/checks"
+)
+
+func TestOrphanTableCheck_DatadirOrphansBlock(t *testing.T) {
+	datadir := t.TempDir()
+	for _, f := range []string{"shop/orders.ibd", "shop/#sql-1a2b_3.frm", "shop/#sql-ib1042-123.ibd", "mysql/user.frm"} {
+		path := filepath.Join(datadir, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	check := &OrphanTableCheck{Inspector: &DatadirOrphanInspector{Datadir: datadir}}
+	findings, err := check.Run(context.Background(), checks.Input{ReplicaHost: "mysql-replica-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 2 {
+		t.Fatalf("expected two orphan findings, got %+v", findings)
+	}
+	for _, f := range findings {
+		if f.Code != CodeOrphanTablesFound || f.Severity != checks.SeverityBlock || f.Meta["schema"] != "shop" || f.Meta["path"] == nil {
+			t.Fatalf("unexpected finding %+v", f)
+		}
+	}
+}
+
+func TestOrphanTableCheck_CleanAndUnknown(t *testing.T) {
+	check := &OrphanTableCheck{Inspector: &DatadirOrphanInspector{Datadir: t.TempDir()}, Host: "mysql-replica-1"}
+	findings, _ := check.Run(context.Background(), checks.Input{})
+	if len(findings) != 1 || findings[0].Code != CodeOrphanTablesNone {
+		t.Fatalf("expected ORPHAN_TEMP_TABLES_NONE, got %+v", findings)
+	}
+
+	check.Inspector = &DatadirOrphanInspector{Datadir: filepath.Join(t.TempDir(), "missing")}
+	findings, _ = check.Run(context.Background(), checks.Input{})
+	if len(findings) != 1 || findings[0].Code != CodeOrphanTablesUnknown {
+		t.Fatalf("expected ORPHAN_TEMP_TABLES_UNKNOWN, got %+v", findings)
+	}
+}
+
+func TestDictionaryOrphanInspector_FallsBackTo80Table(t *testing.T) {
+	db := openFakeDB(t,
+		fakeResponse{match: "INNODB_SYS_TABLES", err: errors.New("Unknown table 'INNODB_SYS_TABLES'")},
+		fakeResponse{match: "INNODB_TABLES", columns: []string{"NAME"}, rows: [][]driver.Value{{"shop/#sql-ib1042-123"}, {"shop/orders"}}},
+	)
+	inspector := &DictionaryOrphanInspector{Connect: fakeConnector(db)}
+	orphans, err := inspector.OrphanTables(context.Background(), "mysql-replica-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(orphans) != 1 || orphans[0].Schema != "shop" || orphans[0].Name != "#sql-ib1042-123" || orphans[0].Source != "dictionary" {
+		t.Fatalf("unexpected orphans %+v", orphans)
+	}
+}
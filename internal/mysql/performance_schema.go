@@ -0,0 +1,183 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Connector returns a connection to host for read-only inspection.
+type Connector func(ctx context.Context, host string) (Querier, error)
+
+const (
+	psChannelsQuery = `SELECT c.CHANNEL_NAME, c.SERVICE_STATE, COALESCE(a.SERVICE_STATE, 'OFF'),
+       c.LAST_ERROR_NUMBER, c.LAST_ERROR_MESSAGE, c.RECEIVED_TRANSACTION_SET
+FROM performance_schema.replication_connection_status c
+LEFT JOIN performance_schema.replication_applier_status a ON a.CHANNEL_NAME = c.CHANNEL_NAME
+ORDER BY c.CHANNEL_NAME`
+
+	psApplierErrorsQuery = `SELECT CHANNEL_NAME, LAST_ERROR_NUMBER, LAST_ERROR_MESSAGE
+FROM performance_schema.replication_applier_status_by_worker
+WHERE LAST_ERROR_NUMBER <> 0`
+
+	// psApplierLagQuery needs the 8.0 transaction timestamp columns; on 5.7 it
+	// fails and lag is reported as unknown.
+	psApplierLagQuery = `SELECT CHANNEL_NAME,
+       MAX(IF(APPLYING_TRANSACTION <> '', TIMESTAMPDIFF(MICROSECOND, APPLYING_TRANSACTION_ORIGINAL_COMMIT_TIMESTAMP, NOW(6)), 0))
+FROM performance_schema.replication_applier_status_by_worker
+GROUP BY CHANNEL_NAME`
+
+	psChannelCountQuery = `SELECT COUNT(*) FROM performance_schema.replication_connection_configuration`
+	readOnlyQuery       = `SELECT @@GLOBAL.read_only`
+)
+
+// PerformanceSchemaInspector implements ReplicaInspector from the
+// performance_schema replication tables, reporting per-channel connection and
+// applier state, errors and (on 8.0) applier lag.
+type PerformanceSchemaInspector struct {
+	Connect Connector
+}
+
+// IsPrimary reports whether host has no replication channels and is writable.
+func (i *PerformanceSchemaInspector) IsPrimary(ctx context.Context, host string) (bool, error) {
+	q, err := i.connect(ctx, host)
+	if err != nil {
+		return false, err
+	}
+	var channels int
+	if err := queryOne(ctx, q, psChannelCountQuery, nil, &channels); err != nil {
+		return false, err
+	}
+	if channels > 0 {
+		return false, nil
+	}
+	var readOnly int
+	if err := queryOne(ctx, q, readOnlyQuery, nil, &readOnly); err != nil {
+		return false, err
+	}
+	return readOnly == 0, nil
+}
+
+// ReplicationStatus reads every channel on replica. The thread booleans are
+// true only when all channels are ON.
+func (i *PerformanceSchemaInspector) ReplicationStatus(ctx context.Context, replica string) (ReplicationStatus, error) {
+	q, err := i.connect(ctx, replica)
+	if err != nil {
+		return ReplicationStatus{}, err
+	}
+	channels, err := readChannels(ctx, q)
+	if err != nil {
+		return ReplicationStatus{}, err
+	}
+	if err := applyApplierErrors(ctx, q, channels); err != nil {
+		return ReplicationStatus{}, err
+	}
+	applyApplierLag(ctx, q, channels)
+
+	status := ReplicationStatus{IOThreadRunning: len(channels) > 0, SQLThreadRunning: len(channels) > 0}
+	for _, c := range channels {
+		if c.ConnectionState != "ON" {
+			status.IOThreadRunning = false
+		}
+		if c.ApplierState != "ON" {
+			status.SQLThreadRunning = false
+		}
+		status.Channels = append(status.Channels, *c)
+	}
+	return status, nil
+}
+
+func (i *PerformanceSchemaInspector) connect(ctx context.Context, host string) (Querier, error) {
+	if i.Connect == nil {
+		return nil, fmt.Errorf("performance_schema inspector requires a connector")
+	}
+	return i.Connect(ctx, host)
+}
+
+func readChannels(ctx context.Context, q Querier) ([]*ChannelStatus, error) {
+	rows, err := q.QueryContext(ctx, psChannelsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read replication channels: %w", err)
+	}
+	defer rows.Close()
+	channels := []*ChannelStatus{}
+	for rows.Next() {
+		c := &ChannelStatus{}
+		var lastError, received sql.NullString
+		if err := rows.Scan(&c.Name, &c.ConnectionState, &c.ApplierState, &c.LastErrorNumber, &lastError, &received); err != nil {
+			return nil, err
+		}
+		c.LastError = lastError.String
+		c.ReceivedGTIDSet = received.String
+		channels = append(channels, c)
+	}
+	return channels, rows.Err()
+}
+
+// applyApplierErrors records worker errors on channels whose connection
+// reports none, so applier failures are not hidden behind a healthy receiver.
+func applyApplierErrors(ctx context.Context, q Querier, channels []*ChannelStatus) error {
+	rows, err := q.QueryContext(ctx, psApplierErrorsQuery)
+	if err != nil {
+		return fmt.Errorf("failed to read applier errors: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		var number int
+		var message sql.NullString
+		if err := rows.Scan(&name, &number, &message); err != nil {
+			return err
+		}
+		for _, c := range channels {
+			if c.Name == name && c.LastErrorNumber == 0 {
+				c.LastErrorNumber = number
+				c.LastError = message.String
+			}
+		}
+	}
+	return rows.Err()
+}
+
+// applyApplierLag fills ApplierLag where the server supports it. Failures are
+// expected on 5.7 and leave LagKnown unset.
+func applyApplierLag(ctx context.Context, q Querier, channels []*ChannelStatus) {
+	rows, err := q.QueryContext(ctx, psApplierLagQuery)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		var micros sql.NullInt64
+		if err := rows.Scan(&name, &micros); err != nil {
+			return
+		}
+		for _, c := range channels {
+			if c.Name == name && micros.Valid {
+				c.ApplierLag = time.Duration(micros.Int64) * time.Microsecond
+				c.LagKnown = true
+			}
+		}
+	}
+}
+
+// queryOne scans the first row of query into dest.
+func queryOne(ctx context.Context, q Querier, query string, args []interface{}, dest ...interface{}) error {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return err
+	}
+	return rows.Err()
+}
@@ -0,0 +1,79 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+)
+
+var channelColumns = []string{"CHANNEL_NAME", "SERVICE_STATE", "SERVICE_STATE", "LAST_ERROR_NUMBER", "LAST_ERROR_MESSAGE", "RECEIVED_TRANSACTION_SET"}
+
+func TestPerformanceSchemaInspector_ChannelsAndLag(t *testing.T) {
+	db := openFakeDB(t,
+		fakeResponse{match: "replication_connection_status", columns: channelColumns, rows: [][]driver.Value{
+			{"", "ON", "ON", int64(0), "", "uuid:1-100"},
+			{"analytics", "CONNECTING", "ON", int64(2003), "Can't connect", nil},
+		}},
+		fakeResponse{match: "WHERE LAST_ERROR_NUMBER <> 0", columns: []string{"CHANNEL_NAME", "LAST_ERROR_NUMBER", "LAST_ERROR_MESSAGE"}},
+		fakeResponse{match: "TIMESTAMPDIFF", columns: []string{"CHANNEL_NAME", "LAG"}, rows: [][]driver.Value{
+			{"", int64(2500000)},
+			{"analytics", int64(0)},
+		}},
+	)
+	inspector := &PerformanceSchemaInspector{Connect: fakeConnector(db)}
+	status, err := inspector.ReplicationStatus(context.Background(), "mysql-replica-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status.IOThreadRunning || !status.SQLThreadRunning {
+		t.Fatalf("expected IO not running (one channel connecting) and SQL running, got %+v", status)
+	}
+	if len(status.Channels) != 2 || status.Channels[1].LastErrorNumber != 2003 || status.Channels[0].ReceivedGTIDSet != "uuid:1-100" {
+		t.Fatalf("unexpected channels: %+v", status.Channels)
+	}
+	if lag, ok := status.MaxApplierLag(); !ok || lag != 2500*time.Millisecond {
+		t.Fatalf("expected 2.5s applier lag, got %s (known=%v)", lag, ok)
+	}
+}
+
+func TestPerformanceSchemaInspector_LagUnknownOn57(t *testing.T) {
+	db := openFakeDB(t,
+		fakeResponse{match: "replication_connection_status", columns: channelColumns, rows: [][]driver.Value{
+			{"", "ON", "ON", int64(0), "", ""},
+		}},
+		fakeResponse{match: "WHERE LAST_ERROR_NUMBER <> 0", columns: []string{"CHANNEL_NAME", "LAST_ERROR_NUMBER", "LAST_ERROR_MESSAGE"}, rows: [][]driver.Value{
+			{"", int64(1062), "Duplicate entry"},
+		}},
+		fakeResponse{match: "TIMESTAMPDIFF", err: errors.New("Error 1054: Unknown column 'APPLYING_TRANSACTION'")},
+	)
+	inspector := &PerformanceSchemaInspector{Connect: fakeConnector(db)}
+	status, err := inspector.ReplicationStatus(context.Background(), "mysql-replica-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := status.MaxApplierLag(); ok {
+		t.Fatalf("expected unknown lag on 5.7")
+	}
+	if status.Channels[0].LastErrorNumber != 1062 {
+		t.Fatalf("expected applier error to be surfaced, got %+v", status.Channels[0])
+	}
+
+	findings := detectPartialProgress("mysql-replica-1", status, nil, false)
+	if len(findings) != 1 || findings[0].Severity != SeverityWarn {
+		t.Fatalf("expected channel error WARN, got %+v", findings)
+	}
+}
+
+func TestPerformanceSchemaInspector_IsPrimary(t *testing.T) {
+	db := openFakeDB(t,
+		fakeResponse{match: "replication_connection_configuration", columns: []string{"COUNT"}, rows: [][]driver.Value{{int64(0)}}},
+		fakeResponse{match: "read_only", columns: []string{"read_only"}, rows: [][]driver.Value{{int64(0)}}},
+	)
+	inspector := &PerformanceSchemaInspector{Connect: fakeConnector(db)}
+	primary, err := inspector.IsPrimary(context.Background(), "mysql-primary")
+	if err != nil || !primary {
+		t.Fatalf("expected writable host without channels to be primary, got %v (%v)", primary, err)
+	}
+}
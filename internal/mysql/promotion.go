@@ -0,0 +1,373 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"migratorx/internal/checks"
+	"migratorx/internal/failure"
+	"migratorx/internal/workflow"
+)
+
+// Promotion defaults.
+const (
+	DefaultCatchUpTimeout      = 2 * time.Minute
+	DefaultCatchUpPollInterval = time.Second
+)
+
+// PromotionActions performs the mutating steps of a cutover.
+type PromotionActions interface {
+	// FreezeWrites makes the current primary reject writes (super_read_only).
+	FreezeWrites(ctx context.Context, primary string) error
+	// CaughtUp reports whether replica has applied every transaction the
+	// frozen primary committed.
+	CaughtUp(ctx context.Context, primary string, replica string) (bool, error)
+	// SwitchPrimary detaches replica from primary and makes it writable.
+	SwitchPrimary(ctx context.Context, primary string, replica string) error
+	// UnfreezeWrites re-enables writes on the primary after an aborted cutover.
+	UnfreezeWrites(ctx context.Context, primary string) error
+}
+
+// errCutoverBudget reports that the write freeze outlasted CutoverBudget.
+var errCutoverBudget = errors.New("cutover budget exceeded")
+
+// PromotionOrchestrator splits promotion into Prepare, which freezes writes,
+// verifies catch-up and reports the exact cutover plan, and Execute, which
+// performs the switch. Each phase checkpoints its steps so the operator gets
+// an explicit review point before the irreversible action.
+//
+// CutoverBudget, when set, bounds the time from write freeze to switch. Once
+// it is exceeded, before the switch, the cutover is aborted and writes are
+// re-enabled on the primary, so a stuck catch-up or a slow review cannot
+// silently extend the downtime window.
+type PromotionOrchestrator struct {
+	Actions        PromotionActions
+	State          workflow.State
+	Primary        string
+	Logger         *log.Logger
+	CatchUpTimeout time.Duration
+	PollInterval   time.Duration
+	CutoverBudget  time.Duration
+}
+
+// NewPromotionOrchestrator constructs an orchestrator with defaults.
+func NewPromotionOrchestrator(actions PromotionActions, state workflow.State, primary string, logger *log.Logger) *PromotionOrchestrator {
+	if state == nil {
+		state = workflow.NewMemoryState()
+	}
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &PromotionOrchestrator{Actions: actions, State: state, Primary: primary, Logger: logger, CatchUpTimeout: DefaultCatchUpTimeout, PollInterval: DefaultCatchUpPollInterval}
+}
+
+// CutoverPlan lists the steps Execute will perform for replica.
+func (o *PromotionOrchestrator) CutoverPlan(replica string) []string {
+	return []string{
+		fmt.Sprintf("re-verify %s has applied every transaction from %s", replica, o.Primary),
+		fmt.Sprintf("stop replication on %s and clear its replica configuration", replica),
+		fmt.Sprintf("disable read_only and super_read_only on %s", replica),
+		fmt.Sprintf("leave %s read-only as the rollback target", o.Primary),
+	}
+}
+
+// Promoted reports whether replica is currently promoted: Execute completed
+// and no rollback has run since.
+func (o *PromotionOrchestrator) Promoted(replica string) bool {
+	ok, _ := getBool(o.State, promotedKey(replica))
+	return ok
+}
+
+// Prepare freezes writes on the primary and waits for the replica to catch
+// up. Safe to re-run; a completed prepare is reported without repeating it.
+func (o *PromotionOrchestrator) Prepare(ctx context.Context, replica string) (Summary, []Finding, error) {
+	var summary Summary
+	findings := []Finding{}
+	replica, block := o.validate(replica)
+	if block != nil {
+		return Summary{Block: 1}, []Finding{*block}, nil
+	}
+
+	if ok, _ := getBool(o.State, writesFrozenKey(replica)); !ok {
+		o.Logger.Printf("freezing writes on %s", o.Primary)
+		if err := o.Actions.FreezeWrites(ctx, o.Primary); err != nil {
+			return appendFailure(summary, findings, failure.Act(o.Primary, "failed to freeze writes on primary", err))
+		}
+		setBool(o.State, writesFrozenKey(replica), true)
+		o.resetTimeline(replica)
+		o.mark(replica, "writes_frozen")
+		findings = append(findings, Finding{Severity: SeverityInfo, Message: "writes frozen on primary", Meta: map[string]interface{}{"primary": o.Primary}})
+		applySummary(&summary, []Finding{findings[len(findings)-1]})
+	}
+
+	if err := o.waitCaughtUp(ctx, replica); err != nil {
+		if errors.Is(err, errCutoverBudget) {
+			return o.abort(ctx, replica, summary, findings)
+		}
+		return appendFailure(summary, findings, err)
+	}
+	o.mark(replica, "caught_up")
+	setBool(o.State, preparedKey(replica), true)
+	meta := map[string]interface{}{"primary": o.Primary, "replica": replica, "cutover_plan": o.CutoverPlan(replica)}
+	if o.CutoverBudget > 0 {
+		meta["threshold"] = o.budgetMeta(replica)
+	}
+	findings = append(findings, Finding{
+		Severity: SeverityInfo,
+		Message:  "promotion prepared; review the cutover plan, then run promote execute",
+		Meta:     meta,
+	})
+	applySummary(&summary, []Finding{findings[len(findings)-1]})
+	return summary, findings, nil
+}
+
+// Unprepare clears the prepare checkpoints for replica, so Execute refuses to
+// cut over until Prepare completes again. Callers use it when the promotion
+// gate blocks a re-prepare: the checkpoints of an earlier prepare must not
+// vouch for a cutover the gate now rejects. Writes are not re-enabled; the
+// next Prepare freezes them again with a fresh timeline.
+func (o *PromotionOrchestrator) Unprepare(replica string) {
+	replica = strings.TrimSpace(replica)
+	setBool(o.State, preparedKey(replica), false)
+	setBool(o.State, writesFrozenKey(replica), false)
+}
+
+// Execute performs the switch. It requires a completed Prepare for the same
+// replica and re-verifies catch-up immediately before switching.
+func (o *PromotionOrchestrator) Execute(ctx context.Context, replica string) (Summary, []Finding, error) {
+	var summary Summary
+	findings := []Finding{}
+	replica, block := o.validate(replica)
+	if block != nil {
+		return Summary{Block: 1}, []Finding{*block}, nil
+	}
+	meta := map[string]interface{}{"primary": o.Primary, "replica": replica}
+	if ok, _ := getBool(o.State, promotedKey(replica)); ok {
+		findings = append(findings, Finding{Severity: SeverityInfo, Message: "replica already promoted", Meta: meta})
+		applySummary(&summary, findings)
+		return summary, findings, nil
+	}
+	if ok, _ := getBool(o.State, preparedKey(replica)); !ok {
+		return appendBlock(summary, findings, "promotion is not prepared; run promote prepare first")
+	}
+
+	caughtUp, err := o.Actions.CaughtUp(ctx, o.Primary, replica)
+	if err != nil {
+		return appendFailure(summary, findings, failure.Inspect(replica, "failed to verify replica catch-up", err))
+	}
+	if !caughtUp {
+		return appendBlock(summary, findings, "replica is behind the frozen primary; writes may have leaked, re-run promote prepare")
+	}
+	if o.budgetExceeded(replica) {
+		return o.abort(ctx, replica, summary, findings)
+	}
+
+	o.Logger.Printf("promoting %s", replica)
+	if err := o.Actions.SwitchPrimary(ctx, o.Primary, replica); err != nil {
+		return appendFailure(summary, findings, failure.Act(replica, "failed to switch primary", err))
+	}
+	setBool(o.State, promotedKey(replica), true)
+	// A rollback of an earlier promotion must not make Rollback skip this one.
+	setBool(o.State, rolledBackKey(replica), false)
+	o.mark(replica, "switched")
+	meta["timeline"] = o.timeline(replica)
+	if o.CutoverBudget > 0 {
+		meta["threshold"] = o.budgetMeta(replica)
+	}
+	findings = append(findings, Finding{Severity: SeverityInfo, Message: "replica promoted to primary", Meta: meta})
+	applySummary(&summary, findings)
+	return summary, findings, nil
+}
+
+// Rollback reverses a completed Execute: writes are frozen on the promoted
+// replica and re-enabled on the original primary. Replication is not
+// reattached, and transactions committed on the replica after the switch are
+// not on the original primary; the returned WARN says so. Safe to re-run.
+func (o *PromotionOrchestrator) Rollback(ctx context.Context, replica string) (Summary, []Finding, error) {
+	var summary Summary
+	findings := []Finding{}
+	replica, block := o.validate(replica)
+	if block != nil {
+		return Summary{Block: 1}, []Finding{*block}, nil
+	}
+	meta := map[string]interface{}{"primary": o.Primary, "replica": replica}
+	if ok, _ := getBool(o.State, rolledBackKey(replica)); ok {
+		findings = append(findings, Finding{Severity: SeverityInfo, Message: "promotion already rolled back", Meta: meta})
+		applySummary(&summary, findings)
+		return summary, findings, nil
+	}
+	if ok, _ := getBool(o.State, promotedKey(replica)); !ok {
+		return appendBlock(summary, findings, "replica was not promoted; nothing to roll back")
+	}
+
+	o.Logger.Printf("rolling back promotion of %s", replica)
+	if err := o.Actions.FreezeWrites(ctx, replica); err != nil {
+		return appendFailure(summary, findings, failure.Act(replica, "rollback failed to freeze writes on promoted replica", err))
+	}
+	findings = append(findings, Finding{Severity: SeverityInfo, Message: "writes frozen on promoted replica", Meta: meta})
+	if err := o.Actions.UnfreezeWrites(ctx, o.Primary); err != nil {
+		applySummary(&summary, findings)
+		return appendFailure(summary, findings, failure.Act(o.Primary, "rollback failed to re-enable writes on original primary", err))
+	}
+	setBool(o.State, rolledBackKey(replica), true)
+	setBool(o.State, promotedKey(replica), false)
+	setBool(o.State, preparedKey(replica), false)
+	setBool(o.State, writesFrozenKey(replica), false)
+	o.mark(replica, "rolled_back")
+	findings = append(findings,
+		Finding{Severity: SeverityInfo, Message: "writes re-enabled on original primary", Meta: map[string]interface{}{"primary": o.Primary, "replica": replica, "timeline": o.timeline(replica)}},
+		Finding{Severity: SeverityWarn, Message: "transactions committed on the promoted replica after the switch are not on the original primary; reconcile them and reattach replication before retrying", Meta: meta},
+	)
+	applySummary(&summary, findings)
+	return summary, findings, nil
+}
+
+func (o *PromotionOrchestrator) validate(replica string) (string, *Finding) {
+	replica = strings.TrimSpace(replica)
+	switch {
+	case replica == "":
+		block := failureFinding(failure.Config("replica is required"))
+		return replica, &block
+	case o.Actions == nil:
+		block := failureFinding(failure.Config("promotion actions are required"))
+		return replica, &block
+	case strings.TrimSpace(o.Primary) == "":
+		block := failureFinding(failure.Config("primary is required"))
+		return replica, &block
+	case replica == o.Primary:
+		return replica, &Finding{Severity: SeverityBlock, Message: "refusing to promote the current primary", Meta: map[string]interface{}{"replica": replica}}
+	}
+	return replica, nil
+}
+
+func (o *PromotionOrchestrator) waitCaughtUp(ctx context.Context, replica string) error {
+	timeout := o.CatchUpTimeout
+	if timeout <= 0 {
+		timeout = DefaultCatchUpTimeout
+	}
+	interval := o.PollInterval
+	if interval <= 0 {
+		interval = DefaultCatchUpPollInterval
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		caughtUp, err := o.Actions.CaughtUp(ctx, o.Primary, replica)
+		if err != nil {
+			return failure.Inspect(replica, "failed to verify replica catch-up", err)
+		}
+		if caughtUp {
+			return nil
+		}
+		if o.budgetExceeded(replica) {
+			return errCutoverBudget
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("replica did not catch up with the frozen primary within %s", timeout)
+		}
+		if err := sleepContext(ctx, interval); err != nil {
+			return err
+		}
+	}
+}
+
+// abort re-enables writes on the primary and clears the prepare checkpoints so
+// the next attempt starts with a fresh freeze and budget.
+func (o *PromotionOrchestrator) abort(ctx context.Context, replica string, summary Summary, findings []Finding) (Summary, []Finding, error) {
+	o.Logger.Printf("cutover budget exceeded; re-enabling writes on %s", o.Primary)
+	message := fmt.Sprintf("cutover budget of %s exceeded before the switch; writes re-enabled on %s", o.CutoverBudget, o.Primary)
+	if err := o.Actions.UnfreezeWrites(ctx, o.Primary); err != nil {
+		message = fmt.Sprintf("cutover budget of %s exceeded before the switch and re-enabling writes on %s failed: %v", o.CutoverBudget, o.Primary, err)
+	} else {
+		o.mark(replica, "aborted")
+		setBool(o.State, writesFrozenKey(replica), false)
+		setBool(o.State, preparedKey(replica), false)
+	}
+	block := Finding{
+		Severity: SeverityBlock,
+		Message:  message,
+		Meta:     map[string]interface{}{"primary": o.Primary, "replica": replica, "timeline": o.timeline(replica), "threshold": o.budgetMeta(replica)},
+	}
+	findings = append(findings, block)
+	applySummary(&summary, []Finding{block})
+	return summary, findings, nil
+}
+
+func (o *PromotionOrchestrator) budgetExceeded(replica string) bool {
+	if o.CutoverBudget <= 0 {
+		return false
+	}
+	elapsed, ok := o.sinceFreeze(replica)
+	return ok && elapsed > o.CutoverBudget
+}
+
+func (o *PromotionOrchestrator) budgetMeta(replica string) map[string]interface{} {
+	elapsed, _ := o.sinceFreeze(replica)
+	return checks.DurationThreshold("max_cutover_duration", o.CutoverBudget).Meta(elapsed.Seconds())
+}
+
+func (o *PromotionOrchestrator) sinceFreeze(replica string) (time.Duration, bool) {
+	frozen, ok := o.markedAt(replica, "writes_frozen")
+	if !ok {
+		return 0, false
+	}
+	return time.Since(frozen), true
+}
+
+// cutoverEvents are the timeline events recorded during promotion, in order.
+var cutoverEvents = []string{"writes_frozen", "caught_up", "switched", "aborted", "rolled_back"}
+
+func (o *PromotionOrchestrator) mark(replica string, event string) {
+	o.State.Set(cutoverEventKey(replica, event), time.Now().UTC().Format(time.RFC3339Nano))
+}
+
+// resetTimeline clears events from an earlier, aborted attempt.
+func (o *PromotionOrchestrator) resetTimeline(replica string) {
+	for _, event := range cutoverEvents {
+		o.State.Set(cutoverEventKey(replica, event), "")
+	}
+}
+
+func (o *PromotionOrchestrator) markedAt(replica string, event string) (time.Time, bool) {
+	val, ok := o.State.Get(cutoverEventKey(replica, event))
+	if !ok {
+		return time.Time{}, false
+	}
+	s, _ := val.(string)
+	t, err := time.Parse(time.RFC3339Nano, s)
+	return t, err == nil
+}
+
+// timeline lists recorded events with their time and the offset from the
+// write freeze.
+func (o *PromotionOrchestrator) timeline(replica string) []map[string]interface{} {
+	frozen, hasFreeze := o.markedAt(replica, "writes_frozen")
+	out := []map[string]interface{}{}
+	for _, event := range cutoverEvents {
+		at, ok := o.markedAt(replica, event)
+		if !ok {
+			continue
+		}
+		entry := map[string]interface{}{"event": event, "at": at.Format(time.RFC3339Nano)}
+		if hasFreeze {
+			entry["since_freeze"] = at.Sub(frozen).String()
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+func cutoverEventKey(replica string, event string) string {
+	return fmt.Sprintf("promotion:%s:at:%s", replica, event)
+}
+
+func writesFrozenKey(replica string) string {
+	return fmt.Sprintf("promotion:%s:writes_frozen", replica)
+}
+
+func preparedKey(replica string) string   { return fmt.Sprintf("promotion:%s:prepared", replica) }
+func promotedKey(replica string) string   { return fmt.Sprintf("promotion:%s:promoted", replica) }
+func rolledBackKey(replica string) string { return fmt.Sprintf("promotion:%s:rolled_back", replica) }
@@ -0,0 +1,192 @@
+package mysql
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"migratorx/internal/workflow"
+)
+
+type fakePromotionActions struct {
+	frozen   int
+	unfrozen int
+	switched int
+	caughtUp []bool
+	checks   int
+}
+
+func (f *fakePromotionActions) FreezeWrites(ctx context.Context, primary string) error {
+	f.frozen++
+	return nil
+}
+
+func (f *fakePromotionActions) CaughtUp(ctx context.Context, primary string, replica string) (bool, error) {
+	i := f.checks
+	f.checks++
+	if i >= len(f.caughtUp) {
+		return f.caughtUp[len(f.caughtUp)-1], nil
+	}
+	return f.caughtUp[i], nil
+}
+
+func (f *fakePromotionActions) SwitchPrimary(ctx context.Context, primary string, replica string) error {
+	f.switched++
+	return nil
+}
+
+func (f *fakePromotionActions) UnfreezeWrites(ctx context.Context, primary string) error {
+	f.unfrozen++
+	return nil
+}
+
+func TestPromotionOrchestrator_ExecuteRequiresPrepare(t *testing.T) {
+	actions := &fakePromotionActions{caughtUp: []bool{true}}
+	o := NewPromotionOrchestrator(actions, workflow.NewMemoryState(), "db-primary", nil)
+	summary, findings, err := o.Execute(context.Background(), "db-replica")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Block != 1 || actions.switched != 0 {
+		t.Fatalf("expected execute without prepare to block, got %+v", findings)
+	}
+}
+
+func TestPromotionOrchestrator_PrepareThenExecute(t *testing.T) {
+	actions := &fakePromotionActions{caughtUp: []bool{false, true}}
+	state := workflow.NewMemoryState()
+	o := NewPromotionOrchestrator(actions, state, "db-primary", nil)
+	o.PollInterval = time.Millisecond
+
+	summary, findings, err := o.Prepare(context.Background(), "db-replica")
+	if err != nil || summary.Block != 0 {
+		t.Fatalf("unexpected prepare result: %+v %v", findings, err)
+	}
+	last := findings[len(findings)-1]
+	if plan, ok := last.Meta["cutover_plan"].([]string); !ok || len(plan) == 0 {
+		t.Fatalf("expected cutover plan in prepare output, got %+v", last.Meta)
+	}
+	if _, _, err := o.Prepare(context.Background(), "db-replica"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actions.frozen != 1 {
+		t.Fatalf("expected writes frozen once, got %d", actions.frozen)
+	}
+
+	for i := 0; i < 2; i++ {
+		summary, findings, err = o.Execute(context.Background(), "db-replica")
+		if err != nil || summary.Block != 0 {
+			t.Fatalf("unexpected execute result: %+v %v", findings, err)
+		}
+	}
+	if actions.switched != 1 {
+		t.Fatalf("expected a single switch, got %d", actions.switched)
+	}
+}
+
+func TestPromotionOrchestrator_PrepareBlocksWhenReplicaLags(t *testing.T) {
+	actions := &fakePromotionActions{caughtUp: []bool{false}}
+	o := NewPromotionOrchestrator(actions, workflow.NewMemoryState(), "db-primary", nil)
+	o.CatchUpTimeout = 5 * time.Millisecond
+	o.PollInterval = time.Millisecond
+	summary, _, err := o.Prepare(context.Background(), "db-replica")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Block != 1 {
+		t.Fatalf("expected lagging replica to block prepare")
+	}
+	if _, _, err := o.Execute(context.Background(), "db-replica"); err != nil || actions.switched != 0 {
+		t.Fatalf("expected execute to refuse an unfinished prepare")
+	}
+}
+
+func TestPromotionOrchestrator_RefusesPrimary(t *testing.T) {
+	o := NewPromotionOrchestrator(&fakePromotionActions{caughtUp: []bool{true}}, nil, "db-primary", nil)
+	summary, _, _ := o.Prepare(context.Background(), "db-primary")
+	if summary.Block != 1 {
+		t.Fatalf("expected promoting the primary to block")
+	}
+}
+
+func TestPromotionOrchestrator_AbortsWhenCutoverBudgetExceeded(t *testing.T) {
+	actions := &fakePromotionActions{caughtUp: []bool{true}}
+	state := workflow.NewMemoryState()
+	o := NewPromotionOrchestrator(actions, state, "db-primary", nil)
+	o.CutoverBudget = time.Minute
+	if summary, findings, _ := o.Prepare(context.Background(), "db-replica"); summary.Block != 0 {
+		t.Fatalf("unexpected prepare result: %+v", findings)
+	}
+
+	// The operator took longer than the budget to review the cutover plan.
+	state.Set(cutoverEventKey("db-replica", "writes_frozen"), time.Now().Add(-2*time.Minute).UTC().Format(time.RFC3339Nano))
+	summary, findings, err := o.Execute(context.Background(), "db-replica")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Block != 1 || actions.switched != 0 || actions.unfrozen != 1 {
+		t.Fatalf("expected abort with writes re-enabled, got %+v (switched=%d unfrozen=%d)", findings, actions.switched, actions.unfrozen)
+	}
+	block := findings[len(findings)-1]
+	timeline := block.Meta["timeline"].([]map[string]interface{})
+	if len(timeline) != 3 || timeline[0]["event"] != "writes_frozen" || timeline[2]["event"] != "aborted" {
+		t.Fatalf("unexpected timeline: %+v", timeline)
+	}
+	if margin := block.Meta["threshold"].(map[string]interface{})["margin"].(float64); margin >= 0 {
+		t.Fatalf("expected negative margin, got %v", margin)
+	}
+
+	if _, _, err := o.Execute(context.Background(), "db-replica"); err != nil || actions.switched != 0 {
+		t.Fatalf("expected execute to require a fresh prepare after abort")
+	}
+}
+
+func TestPromotionOrchestrator_BudgetBoundsCatchUpWait(t *testing.T) {
+	actions := &fakePromotionActions{caughtUp: []bool{false}}
+	o := NewPromotionOrchestrator(actions, workflow.NewMemoryState(), "db-primary", nil)
+	o.CutoverBudget = 5 * time.Millisecond
+	o.PollInterval = time.Millisecond
+	summary, findings, _ := o.Prepare(context.Background(), "db-replica")
+	if summary.Block != 1 || actions.unfrozen != 1 || !strings.Contains(findings[len(findings)-1].Message, "cutover budget") {
+		t.Fatalf("expected budget abort during catch-up, got %+v", findings)
+	}
+}
+
+func TestPromotionOrchestrator_RollbackReversesExecute(t *testing.T) {
+	actions := &fakePromotionActions{caughtUp: []bool{true}}
+	o := NewPromotionOrchestrator(actions, workflow.NewMemoryState(), "db-primary", nil)
+	if summary, _, _ := o.Rollback(context.Background(), "db-replica"); summary.Block != 1 {
+		t.Fatalf("expected rollback without promotion to block")
+	}
+	o.Prepare(context.Background(), "db-replica")
+	o.Execute(context.Background(), "db-replica")
+
+	for i := 0; i < 2; i++ {
+		summary, findings, err := o.Rollback(context.Background(), "db-replica")
+		if err != nil || summary.Block != 0 {
+			t.Fatalf("unexpected rollback result: %+v %v", findings, err)
+		}
+	}
+	// One freeze on the primary during prepare, one on the replica during rollback.
+	if actions.frozen != 2 || actions.unfrozen != 1 {
+		t.Fatalf("expected a single rollback, got frozen=%d unfrozen=%d", actions.frozen, actions.unfrozen)
+	}
+}
+
+func TestPromotionOrchestrator_RollsBackAgainAfterRepromotion(t *testing.T) {
+	actions := &fakePromotionActions{caughtUp: []bool{true}}
+	o := NewPromotionOrchestrator(actions, workflow.NewMemoryState(), "db-primary", nil)
+	for attempt := 1; attempt <= 2; attempt++ {
+		o.Prepare(context.Background(), "db-replica")
+		if summary, findings, _ := o.Execute(context.Background(), "db-replica"); summary.Block != 0 || !o.Promoted("db-replica") {
+			t.Fatalf("attempt %d: expected promotion, got %+v", attempt, findings)
+		}
+		if summary, findings, _ := o.Rollback(context.Background(), "db-replica"); summary.Block != 0 || o.Promoted("db-replica") {
+			t.Fatalf("attempt %d: expected rollback, got %+v", attempt, findings)
+		}
+		if actions.unfrozen != attempt {
+			t.Fatalf("attempt %d: expected the primary unfrozen %d times, got %d", attempt, attempt, actions.unfrozen)
+		}
+	}
+}
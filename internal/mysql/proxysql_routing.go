@@ -0,0 +1,337 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"migratorx/internal/checks"
+)
+
+// ProxySQLServer is a row of runtime_mysql_servers.
+type ProxySQLServer struct {
+	Hostgroup int
+	Hostname  string
+	Port      int
+	Status    string
+}
+
+// ProxySQLQueryRule is a row of runtime_mysql_query_rules. MatchDigest and
+// MatchPattern are regular expressions; an empty one matches every query.
+type ProxySQLQueryRule struct {
+	RuleID               int
+	Active               bool
+	Username             string
+	MatchDigest          string
+	MatchPattern         string
+	NegateMatchPattern   bool
+	DestinationHostgroup *int
+}
+
+// ProxySQLReplicationHostgroup is a row of runtime_mysql_replication_hostgroups:
+// ProxySQL moves servers between the pair as their read_only flag changes.
+type ProxySQLReplicationHostgroup struct {
+	WriterHostgroup int
+	ReaderHostgroup int
+}
+
+// ProxySQLUser is a row of runtime_mysql_users; queries no rule routes go to
+// DefaultHostgroup.
+type ProxySQLUser struct {
+	Username         string
+	DefaultHostgroup int
+}
+
+// ProxySQLConfig is the runtime routing configuration of a ProxySQL instance.
+type ProxySQLConfig struct {
+	Servers               []ProxySQLServer
+	QueryRules            []ProxySQLQueryRule
+	ReplicationHostgroups []ProxySQLReplicationHostgroup
+	Users                 []ProxySQLUser
+}
+
+// ProxySQLInspector reads ProxySQL's runtime routing configuration.
+type ProxySQLInspector interface {
+	ProxySQLConfig(ctx context.Context) (ProxySQLConfig, error)
+}
+
+// ProxySQLRoutingCheck verifies that ProxySQL will send writes to the promoted
+// replica after the switch and nowhere else: query rules may only route plain
+// SELECTs to reader hostgroups, every user's default hostgroup must be the
+// writer, and the target replica must be known to ProxySQL and reclassified
+// into the writer hostgroup by mysql_replication_hostgroups when its read_only
+// flag is cleared. The writer hostgroup is the replication hostgroup pair's
+// writer, or else the hostgroup holding the primary.
+type ProxySQLRoutingCheck struct {
+	Inspector ProxySQLInspector
+	Primary   string
+	Target    string
+}
+
+func (c *ProxySQLRoutingCheck) Name() string   { return "proxysql_routing" }
+func (c *ProxySQLRoutingCheck) ReadOnly() bool { return true }
+
+func (c *ProxySQLRoutingCheck) Parameters() map[string]interface{} {
+	return map[string]interface{}{"primary": c.Primary, "target": c.Target}
+}
+
+func (c *ProxySQLRoutingCheck) Run(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+	if c.Inspector == nil {
+		return nil, fmt.Errorf("proxysql inspector is required")
+	}
+	primary := strings.TrimSpace(c.Primary)
+	if primary == "" {
+		primary = input.PrimaryHost
+	}
+	target := strings.TrimSpace(c.Target)
+	if target == "" {
+		target = input.ReplicaHost
+	}
+	if primary == "" || target == "" {
+		return nil, fmt.Errorf("primary and target replica are required")
+	}
+	cfg, err := c.Inspector.ProxySQLConfig(ctx)
+	if err != nil {
+		return []checks.Finding{{
+			Severity: checks.SeverityWarn,
+			Code:     CodeProxySQLRoutingUnknown,
+			Message:  fmt.Sprintf("unable to read ProxySQL routing configuration: %v", err),
+			Meta:     map[string]interface{}{"primary": primary, "target": target},
+		}}, nil
+	}
+
+	writer, pair, ok := writerHostgroup(cfg, primary)
+	if !ok {
+		return []checks.Finding{{
+			Severity: checks.SeverityBlock,
+			Code:     CodeProxySQLWriterUnknown,
+			Message:  fmt.Sprintf("ProxySQL has no replication hostgroups and no hostgroup holding primary %q; cannot tell where writes go", primary),
+			Meta:     map[string]interface{}{"primary": primary, "target": target},
+		}}, nil
+	}
+	meta := func(extra map[string]interface{}) map[string]interface{} {
+		m := map[string]interface{}{"writer_hostgroup": writer, "primary": primary, "target": target}
+		for k, v := range extra {
+			m[k] = v
+		}
+		return m
+	}
+
+	findings := []checks.Finding{}
+	for _, rule := range cfg.QueryRules {
+		if !rule.Active || rule.DestinationHostgroup == nil || *rule.DestinationHostgroup == writer {
+			continue
+		}
+		if readOnlyRule(rule) {
+			continue
+		}
+		pattern := rule.MatchDigest
+		if pattern == "" {
+			pattern = rule.MatchPattern
+		}
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityBlock,
+			Code:     CodeProxySQLWriteRuleToReader,
+			Message:  fmt.Sprintf("query rule %d (%q) can match writes but routes to hostgroup %d instead of writer hostgroup %d", rule.RuleID, pattern, *rule.DestinationHostgroup, writer),
+			Meta:     meta(map[string]interface{}{"rule_id": rule.RuleID, "pattern": pattern, "destination_hostgroup": *rule.DestinationHostgroup, "username": rule.Username}),
+		})
+	}
+	for _, user := range cfg.Users {
+		if user.DefaultHostgroup == writer {
+			continue
+		}
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityBlock,
+			Code:     CodeProxySQLDefaultNotWriter,
+			Message:  fmt.Sprintf("user %q defaults to hostgroup %d; its unrouted writes will not reach writer hostgroup %d", user.Username, user.DefaultHostgroup, writer),
+			Meta:     meta(map[string]interface{}{"username": user.Username, "default_hostgroup": user.DefaultHostgroup}),
+		})
+	}
+
+	targetGroups := hostgroupsOf(cfg, target)
+	switch {
+	case len(targetGroups) == 0:
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityBlock,
+			Code:     CodeProxySQLTargetMissing,
+			Message:  fmt.Sprintf("target replica %q is not in ProxySQL's mysql_servers; it cannot take writes after the switch", target),
+			Meta:     meta(nil),
+		})
+	case pair == nil:
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityBlock,
+			Code:     CodeProxySQLNoReclassification,
+			Message:  fmt.Sprintf("no mysql_replication_hostgroups entry moves %q into writer hostgroup %d when read_only is cleared; writes would keep going to %q", target, writer, primary),
+			Meta:     meta(map[string]interface{}{"target_hostgroups": targetGroups}),
+		})
+	case !containsInt(targetGroups, pair.ReaderHostgroup) && !containsInt(targetGroups, pair.WriterHostgroup):
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityBlock,
+			Code:     CodeProxySQLNoReclassification,
+			Message:  fmt.Sprintf("target replica %q is in hostgroups %v, outside the replication hostgroup pair %d/%d; ProxySQL will not reclassify it as writer", target, targetGroups, pair.WriterHostgroup, pair.ReaderHostgroup),
+			Meta:     meta(map[string]interface{}{"target_hostgroups": targetGroups, "reader_hostgroup": pair.ReaderHostgroup}),
+		})
+	}
+	for _, server := range cfg.Servers {
+		if server.Hostgroup != writer || server.Hostname == primary || server.Hostname == target {
+			continue
+		}
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityBlock,
+			Code:     CodeProxySQLUnexpectedWriter,
+			Message:  fmt.Sprintf("%q is in writer hostgroup %d but is neither the primary nor the target replica", server.Hostname, writer),
+			Meta:     meta(map[string]interface{}{"host": server.Hostname}),
+		})
+	}
+
+	if len(findings) == 0 {
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityInfo,
+			Code:     CodeProxySQLRoutingOK,
+			Message:  fmt.Sprintf("ProxySQL routes writes to hostgroup %d and will reclassify %q as writer", writer, target),
+			Meta:     meta(map[string]interface{}{"rules": len(cfg.QueryRules), "users": len(cfg.Users)}),
+		})
+	}
+	return findings, nil
+}
+
+// writerHostgroup picks the replication hostgroup pair whose hostgroups hold
+// the primary (or the only pair), falling back to the primary's hostgroup.
+func writerHostgroup(cfg ProxySQLConfig, primary string) (int, *ProxySQLReplicationHostgroup, bool) {
+	groups := hostgroupsOf(cfg, primary)
+	for i, pair := range cfg.ReplicationHostgroups {
+		if containsInt(groups, pair.WriterHostgroup) || containsInt(groups, pair.ReaderHostgroup) || len(cfg.ReplicationHostgroups) == 1 {
+			return pair.WriterHostgroup, &cfg.ReplicationHostgroups[i], true
+		}
+	}
+	if len(groups) == 1 {
+		return groups[0], nil, true
+	}
+	return 0, nil, false
+}
+
+func hostgroupsOf(cfg ProxySQLConfig, host string) []int {
+	groups := []int{}
+	for _, server := range cfg.Servers {
+		if server.Hostname == host && !containsInt(groups, server.Hostgroup) {
+			groups = append(groups, server.Hostgroup)
+		}
+	}
+	sort.Ints(groups)
+	return groups
+}
+
+func containsInt(values []int, v int) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	selectRulePattern  = regexp.MustCompile(`(?i)^\^\s*(\\s\*)?select\b`)
+	lockingReadPattern = regexp.MustCompile(`(?i)for\s+update|for\s+share|lock\s+in\s+share\s+mode|\binto\b`)
+)
+
+// readOnlyRule reports whether a rule can only match plain SELECTs: its
+// pattern is anchored to SELECT and does not cover locking reads or
+// SELECT ... INTO. An empty or negated pattern can match writes.
+func readOnlyRule(rule ProxySQLQueryRule) bool {
+	if rule.NegateMatchPattern {
+		return false
+	}
+	pattern := rule.MatchDigest
+	if pattern == "" {
+		pattern = rule.MatchPattern
+	}
+	pattern = strings.TrimSpace(pattern)
+	return selectRulePattern.MatchString(pattern) && !lockingReadPattern.MatchString(pattern)
+}
+
+const (
+	proxySQLServersQuery     = `SELECT hostgroup_id, hostname, port, status FROM runtime_mysql_servers`
+	proxySQLRulesQuery       = `SELECT rule_id, active, COALESCE(username, ''), COALESCE(match_digest, ''), COALESCE(match_pattern, ''), negate_match_pattern, destination_hostgroup FROM runtime_mysql_query_rules`
+	proxySQLReplicationQuery = `SELECT writer_hostgroup, reader_hostgroup FROM runtime_mysql_replication_hostgroups`
+	proxySQLUsersQuery       = `SELECT username, default_hostgroup FROM runtime_mysql_users WHERE frontend = 1`
+)
+
+// ProxySQLAdminInspector reads the runtime_ tables through ProxySQL's admin
+// interface (port 6032 by default).
+type ProxySQLAdminInspector struct {
+	Admin Querier
+}
+
+func (i *ProxySQLAdminInspector) ProxySQLConfig(ctx context.Context) (ProxySQLConfig, error) {
+	var cfg ProxySQLConfig
+	if i.Admin == nil {
+		return cfg, fmt.Errorf("proxysql inspector requires an admin connection")
+	}
+	err := i.scan(ctx, proxySQLServersQuery, func(scan func(...interface{}) error) error {
+		var s ProxySQLServer
+		if err := scan(&s.Hostgroup, &s.Hostname, &s.Port, &s.Status); err != nil {
+			return err
+		}
+		cfg.Servers = append(cfg.Servers, s)
+		return nil
+	})
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read mysql_servers: %w", err)
+	}
+	err = i.scan(ctx, proxySQLRulesQuery, func(scan func(...interface{}) error) error {
+		var r ProxySQLQueryRule
+		var destination *int64
+		if err := scan(&r.RuleID, &r.Active, &r.Username, &r.MatchDigest, &r.MatchPattern, &r.NegateMatchPattern, &destination); err != nil {
+			return err
+		}
+		if destination != nil {
+			hostgroup := int(*destination)
+			r.DestinationHostgroup = &hostgroup
+		}
+		cfg.QueryRules = append(cfg.QueryRules, r)
+		return nil
+	})
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read mysql_query_rules: %w", err)
+	}
+	err = i.scan(ctx, proxySQLReplicationQuery, func(scan func(...interface{}) error) error {
+		var p ProxySQLReplicationHostgroup
+		if err := scan(&p.WriterHostgroup, &p.ReaderHostgroup); err != nil {
+			return err
+		}
+		cfg.ReplicationHostgroups = append(cfg.ReplicationHostgroups, p)
+		return nil
+	})
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read mysql_replication_hostgroups: %w", err)
+	}
+	err = i.scan(ctx, proxySQLUsersQuery, func(scan func(...interface{}) error) error {
+		var u ProxySQLUser
+		if err := scan(&u.Username, &u.DefaultHostgroup); err != nil {
+			return err
+		}
+		cfg.Users = append(cfg.Users, u)
+		return nil
+	})
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read mysql_users: %w", err)
+	}
+	return cfg, nil
+}
+
+func (i *ProxySQLAdminInspector) scan(ctx context.Context, query string, row func(scan func(...interface{}) error) error) error {
+	rows, err := i.Admin.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := row(rows.Scan); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,129 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"migratorx/internal/checks"
+)
+
+type fakeProxySQLInspector struct {
+	cfg ProxySQLConfig
+	err error
+}
+
+func (f *fakeProxySQLInspector) ProxySQLConfig(ctx context.Context) (ProxySQLConfig, error) {
+	return f.cfg, f.err
+}
+
+func hostgroup(id int) *int { return &id }
+
+func healthyProxySQLConfig() ProxySQLConfig {
+	return ProxySQLConfig{
+		Servers: []ProxySQLServer{
+			{Hostgroup: 10, Hostname: "primary", Port: 3306, Status: "ONLINE"},
+			{Hostgroup: 20, Hostname: "replica-1", Port: 3306, Status: "ONLINE"},
+		},
+		QueryRules: []ProxySQLQueryRule{
+			{RuleID: 1, Active: true, MatchDigest: `^SELECT.*FOR UPDATE`, DestinationHostgroup: hostgroup(10)},
+			{RuleID: 2, Active: true, MatchDigest: `^SELECT`, DestinationHostgroup: hostgroup(20)},
+		},
+		ReplicationHostgroups: []ProxySQLReplicationHostgroup{{WriterHostgroup: 10, ReaderHostgroup: 20}},
+		Users:                 []ProxySQLUser{{Username: "orders", DefaultHostgroup: 10}},
+	}
+}
+
+func TestProxySQLRoutingCheck_HealthyConfigPasses(t *testing.T) {
+	check := &ProxySQLRoutingCheck{Inspector: &fakeProxySQLInspector{cfg: healthyProxySQLConfig()}, Primary: "primary", Target: "replica-1"}
+	findings, err := check.Run(context.Background(), checks.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Code != CodeProxySQLRoutingOK || findings[0].Meta["writer_hostgroup"] != 10 {
+		t.Fatalf("expected routing to pass, got %+v", findings)
+	}
+}
+
+func TestProxySQLRoutingCheck_BlocksWritesRoutedToReaders(t *testing.T) {
+	cfg := healthyProxySQLConfig()
+	cfg.QueryRules = append(cfg.QueryRules,
+		ProxySQLQueryRule{RuleID: 3, Active: true, MatchDigest: `^SELECT .* FOR UPDATE`, DestinationHostgroup: hostgroup(20)},
+		ProxySQLQueryRule{RuleID: 4, Active: true, Username: "reports", DestinationHostgroup: hostgroup(20)},
+		ProxySQLQueryRule{RuleID: 5, Active: false, MatchDigest: `^UPDATE`, DestinationHostgroup: hostgroup(20)},
+	)
+	cfg.Users = append(cfg.Users, ProxySQLUser{Username: "reports", DefaultHostgroup: 20})
+	check := &ProxySQLRoutingCheck{Inspector: &fakeProxySQLInspector{cfg: cfg}, Primary: "primary", Target: "replica-1"}
+	findings, err := check.Run(context.Background(), checks.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 3 {
+		t.Fatalf("expected two write rules and a default hostgroup to block, got %+v", findings)
+	}
+	for i, id := range []int{3, 4} {
+		if f := findings[i]; f.Severity != checks.SeverityBlock || f.Code != CodeProxySQLWriteRuleToReader || f.Meta["rule_id"] != id {
+			t.Fatalf("expected rule %d to block, got %+v", id, f)
+		}
+	}
+	if f := findings[2]; f.Code != CodeProxySQLDefaultNotWriter || f.Meta["username"] != "reports" {
+		t.Fatalf("expected the reports user to block, got %+v", f)
+	}
+}
+
+func TestProxySQLRoutingCheck_BlocksTargetNotReclassified(t *testing.T) {
+	cfg := healthyProxySQLConfig()
+	cfg.ReplicationHostgroups = nil
+	cfg.Servers = append(cfg.Servers, ProxySQLServer{Hostgroup: 10, Hostname: "legacy", Port: 3306, Status: "ONLINE"})
+	check := &ProxySQLRoutingCheck{Inspector: &fakeProxySQLInspector{cfg: cfg}, Primary: "primary", Target: "replica-1"}
+	findings, err := check.Run(context.Background(), checks.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 2 || findings[0].Code != CodeProxySQLNoReclassification || findings[1].Code != CodeProxySQLUnexpectedWriter || findings[1].Meta["host"] != "legacy" {
+		t.Fatalf("expected a missing reclassification and an unexpected writer, got %+v", findings)
+	}
+
+	check.Target = "replica-2"
+	findings, _ = check.Run(context.Background(), checks.Input{})
+	if findings[0].Code != CodeProxySQLTargetMissing {
+		t.Fatalf("expected an unknown target to block, got %+v", findings)
+	}
+}
+
+func TestProxySQLRoutingCheck_InspectorErrorWarns(t *testing.T) {
+	check := &ProxySQLRoutingCheck{Inspector: &fakeProxySQLInspector{err: errors.New("access denied")}}
+	findings, err := check.Run(context.Background(), checks.Input{PrimaryHost: "primary", ReplicaHost: "replica-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Severity != checks.SeverityWarn || findings[0].Code != CodeProxySQLRoutingUnknown {
+		t.Fatalf("expected a WARN, got %+v", findings)
+	}
+}
+
+func TestProxySQLAdminInspector_ReadsRuntimeTables(t *testing.T) {
+	db := openFakeDB(t,
+		fakeResponse{match: "runtime_mysql_servers", columns: []string{"hostgroup_id", "hostname", "port", "status"}, rows: [][]driver.Value{{int64(10), "primary", int64(3306), "ONLINE"}}},
+		fakeResponse{match: "runtime_mysql_query_rules", columns: []string{"rule_id", "active", "username", "match_digest", "match_pattern", "negate_match_pattern", "destination_hostgroup"}, rows: [][]driver.Value{
+			{int64(1), int64(1), "", "^SELECT", "", int64(0), int64(20)},
+			{int64(2), int64(1), "", "", "", int64(0), nil},
+		}},
+		fakeResponse{match: "runtime_mysql_replication_hostgroups", columns: []string{"writer_hostgroup", "reader_hostgroup"}, rows: [][]driver.Value{{int64(10), int64(20)}}},
+		fakeResponse{match: "runtime_mysql_users", columns: []string{"username", "default_hostgroup"}, rows: [][]driver.Value{{"orders", int64(10)}}},
+	)
+	cfg, err := (&ProxySQLAdminInspector{Admin: db}).ProxySQLConfig(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Servers) != 1 || len(cfg.QueryRules) != 2 || len(cfg.ReplicationHostgroups) != 1 || len(cfg.Users) != 1 {
+		t.Fatalf("unexpected config %+v", cfg)
+	}
+	if rule := cfg.QueryRules[0]; !rule.Active || rule.DestinationHostgroup == nil || *rule.DestinationHostgroup != 20 {
+		t.Fatalf("unexpected rule %+v", rule)
+	}
+	if cfg.QueryRules[1].DestinationHostgroup != nil {
+		t.Fatalf("expected a NULL destination to stay nil, got %+v", cfg.QueryRules[1])
+	}
+}
@@ -0,0 +1,45 @@
+package mysql
+
+import (
+	"fmt"
+	"time"
+)
+
+// ReadCapacity is the read pool floor an upgrade must leave in service. Each
+// replica serving reads contributes its Weight (default 1); while Peak
+// reports peak traffic (always, when Peak is nil) the serving replicas must
+// add up to at least MinCapacity.
+type ReadCapacity struct {
+	Weights     map[string]float64
+	MinCapacity float64
+	Peak        func(time.Time) bool
+	Now         func() time.Time
+}
+
+// Weight returns replica's share of the read pool.
+func (c *ReadCapacity) Weight(replica string) float64 {
+	if w, ok := c.Weights[replica]; ok {
+		return w
+	}
+	return 1
+}
+
+// Shortfall returns the capacity of serving and, when it is below the floor
+// during peak traffic, why an upgrade must not start; reason is empty
+// otherwise.
+func (c *ReadCapacity) Shortfall(serving []string) (capacity float64, reason string) {
+	for _, replica := range serving {
+		capacity += c.Weight(replica)
+	}
+	if c.MinCapacity <= 0 || capacity >= c.MinCapacity {
+		return capacity, ""
+	}
+	now := time.Now
+	if c.Now != nil {
+		now = c.Now
+	}
+	if c.Peak != nil && !c.Peak(now()) {
+		return capacity, ""
+	}
+	return capacity, fmt.Sprintf("read capacity would drop to %g during peak traffic (floor %g)", capacity, c.MinCapacity)
+}
@@ -0,0 +1,36 @@
+package mysql
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadCapacity_ShortfallOnlyDuringPeak(t *testing.T) {
+	peak := true
+	capacity := &ReadCapacity{
+		Weights:     map[string]float64{"big": 2},
+		MinCapacity: 2.5,
+		Peak:        func(time.Time) bool { return peak },
+	}
+	got, reason := capacity.Shortfall([]string{"big"})
+	if got != 2 || !strings.Contains(reason, "drop to 2") {
+		t.Fatalf("expected a shortfall at capacity 2, got %v %q", got, reason)
+	}
+	if got, reason := capacity.Shortfall([]string{"big", "small"}); got != 3 || reason != "" {
+		t.Fatalf("expected weights 2+1 to meet the floor, got %v %q", got, reason)
+	}
+	peak = false
+	if _, reason := capacity.Shortfall([]string{"big"}); reason != "" {
+		t.Fatalf("expected no shortfall outside peak hours, got %q", reason)
+	}
+}
+
+func TestUpgradeGuard_HoldsBelowReadCapacity(t *testing.T) {
+	guard := UpgradeGuard{Capacity: &ReadCapacity{Weights: map[string]float64{"r2": 0.5}, MinCapacity: 1}}
+	reasons, meta := guard.Degraded(context.Background(), []string{"r2"})
+	if len(reasons) != 1 || meta["read_capacity"] != 0.5 {
+		t.Fatalf("expected the read pool floor to hold the upgrade, got %v %v", reasons, meta)
+	}
+}
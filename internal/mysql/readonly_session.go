@@ -0,0 +1,136 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+
+	"migratorx/internal/checks"
+)
+
+// Querier is the subset of *sql.Conn used by live inspectors. Session settings
+// apply per connection, so read-only sessions must wrap a *sql.Conn rather than
+// a pooled *sql.DB.
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// readOnlyKeywords are the leading statement keywords a read-only session accepts.
+var readOnlyKeywords = []string{"SELECT", "SHOW", "EXPLAIN", "DESCRIBE", "DESC", "WITH"}
+
+// lockingClauses turn an otherwise read-only SELECT into a locking or writing statement.
+var lockingClauses = []string{"FOR UPDATE", "FOR SHARE", "LOCK IN SHARE MODE", "INTO OUTFILE", "INTO DUMPFILE"}
+
+// ReadOnlySession guards a connection used during a read-only phase. The
+// server session is set to READ ONLY and every statement is checked against a
+// keyword allowlist before it is sent. Rejected statements return a
+// *checks.ReadOnlyViolationError, which checks.Runner reports as a BLOCK, and
+// are recorded so callers can audit violations a component swallowed.
+type ReadOnlySession struct {
+	conn    Querier
+	allowed map[string]struct{}
+
+	mu         sync.Mutex
+	violations []string
+}
+
+// NewReadOnlySession marks the session READ ONLY and returns the guard.
+// extraKeywords extends the allowlist (e.g. "CHECKSUM" for CHECKSUM TABLE).
+func NewReadOnlySession(ctx context.Context, conn Querier, extraKeywords ...string) (*ReadOnlySession, error) {
+	if _, err := conn.ExecContext(ctx, "SET SESSION TRANSACTION READ ONLY"); err != nil {
+		return nil, err
+	}
+	allowed := map[string]struct{}{}
+	for _, k := range append(append([]string{}, readOnlyKeywords...), extraKeywords...) {
+		allowed[strings.ToUpper(strings.TrimSpace(k))] = struct{}{}
+	}
+	return &ReadOnlySession{conn: conn, allowed: allowed}, nil
+}
+
+func (s *ReadOnlySession) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if err := s.check(query); err != nil {
+		return nil, err
+	}
+	rows, err := s.conn.QueryContext(ctx, query, args...)
+	return rows, s.serverError(query, err)
+}
+
+func (s *ReadOnlySession) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if err := s.check(query); err != nil {
+		return nil, err
+	}
+	res, err := s.conn.ExecContext(ctx, query, args...)
+	return res, s.serverError(query, err)
+}
+
+// Violations returns the statements rejected so far.
+func (s *ReadOnlySession) Violations() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.violations...)
+}
+
+func (s *ReadOnlySession) check(query string) error {
+	stmt := strings.ToUpper(stripSQLComments(query))
+	if strings.Contains(strings.TrimRight(stmt, "; \t\n"), ";") {
+		return s.reject(query, "multiple statements")
+	}
+	keyword := stmt
+	if i := strings.IndexAny(keyword, " \t\n("); i >= 0 {
+		keyword = keyword[:i]
+	}
+	if _, ok := s.allowed[keyword]; !ok {
+		return s.reject(query, "statement type "+keyword+" is not allowlisted")
+	}
+	normalized := strings.Join(strings.Fields(stmt), " ")
+	for _, clause := range lockingClauses {
+		if strings.Contains(normalized, clause) {
+			return s.reject(query, clause)
+		}
+	}
+	return nil
+}
+
+// serverError converts MySQL's read-only rejection (ER_CANT_EXECUTE_IN_READ_ONLY_TRANSACTION,
+// 1792) into a violation so a write that slipped past the allowlist still blocks.
+func (s *ReadOnlySession) serverError(query string, err error) error {
+	if err != nil && (strings.Contains(err.Error(), "1792") || strings.Contains(err.Error(), "READ ONLY transaction")) {
+		return s.reject(query, "rejected by server")
+	}
+	return err
+}
+
+func (s *ReadOnlySession) reject(query string, reason string) error {
+	s.mu.Lock()
+	s.violations = append(s.violations, query)
+	s.mu.Unlock()
+	return &checks.ReadOnlyViolationError{Statement: query, Reason: reason}
+}
+
+// stripSQLComments removes leading whitespace and comments so the first
+// keyword can be classified.
+func stripSQLComments(query string) string {
+	q := strings.TrimSpace(query)
+	for {
+		switch {
+		case strings.HasPrefix(q, "/*"):
+			end := strings.Index(q, "*/")
+			if end < 0 {
+				return ""
+			}
+			q = strings.TrimSpace(q[end+2:])
+		case strings.HasPrefix(q, "--"), strings.HasPrefix(q, "#"):
+			end := strings.Index(q, "\n")
+			if end < 0 {
+				return ""
+			}
+			q = strings.TrimSpace(q[end+1:])
+		case strings.HasPrefix(q, "("):
+			q = strings.TrimSpace(q[1:])
+		default:
+			return q
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"migratorx/internal/checks"
+)
+
+type fakeQuerier struct {
+	queries []string
+	err     error
+}
+
+func (f *fakeQuerier) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	f.queries = append(f.queries, query)
+	return nil, f.err
+}
+
+func (f *fakeQuerier) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	f.queries = append(f.queries, query)
+	return nil, f.err
+}
+
+func TestReadOnlySession_SetsSessionReadOnly(t *testing.T) {
+	conn := &fakeQuerier{}
+	if _, err := NewReadOnlySession(context.Background(), conn); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(conn.queries) != 1 || conn.queries[0] != "SET SESSION TRANSACTION READ ONLY" {
+		t.Fatalf("expected session to be set read-only, got %v", conn.queries)
+	}
+}
+
+func TestReadOnlySession_AllowsReads(t *testing.T) {
+	session, _ := NewReadOnlySession(context.Background(), &fakeQuerier{})
+	for _, q := range []string{
+		"SELECT 1",
+		"  /* inspector */ select table_name FROM information_schema.tables",
+		"SHOW REPLICA STATUS",
+		"(SELECT 1) UNION (SELECT 2)",
+		"WITH t AS (SELECT 1) SELECT * FROM t;",
+	} {
+		if _, err := session.QueryContext(context.Background(), q); err != nil {
+			t.Fatalf("expected %q to be allowed, got %v", q, err)
+		}
+	}
+	if len(session.Violations()) != 0 {
+		t.Fatalf("expected no violations, got %v", session.Violations())
+	}
+}
+
+func TestReadOnlySession_RejectsWrites(t *testing.T) {
+	conn := &fakeQuerier{}
+	session, _ := NewReadOnlySession(context.Background(), conn)
+	for _, q := range []string{
+		"INSERT INTO t VALUES (1)",
+		"SELECT * FROM t FOR UPDATE",
+		"SELECT * FROM t INTO OUTFILE '/tmp/x'",
+		"SELECT 1; DROP TABLE t",
+		"SET SESSION TRANSACTION READ WRITE",
+	} {
+		_, err := session.ExecContext(context.Background(), q)
+		var violation *checks.ReadOnlyViolationError
+		if !errors.As(err, &violation) {
+			t.Fatalf("expected violation for %q, got %v", q, err)
+		}
+	}
+	if len(conn.queries) != 1 {
+		t.Fatalf("expected rejected statements not to reach the server, got %v", conn.queries)
+	}
+	if len(session.Violations()) != 5 {
+		t.Fatalf("expected 5 recorded violations, got %v", session.Violations())
+	}
+}
+
+func TestReadOnlySession_ServerRejectionIsViolation(t *testing.T) {
+	conn := &fakeQuerier{}
+	session, _ := NewReadOnlySession(context.Background(), conn)
+	conn.err = errors.New("Error 1792 (25006): Cannot execute statement in a READ ONLY transaction.")
+	_, err := session.QueryContext(context.Background(), "SELECT next_id()")
+	var violation *checks.ReadOnlyViolationError
+	if !errors.As(err, &violation) || violation.Reason != "rejected by server" {
+		t.Fatalf("expected server rejection to be a violation, got %v", err)
+	}
+}
+
+func TestRunner_ReportsReadOnlyViolationAsBlock(t *testing.T) {
+	session, _ := NewReadOnlySession(context.Background(), &fakeQuerier{})
+	check := checks.NewReadOnlyCheck("writer", func(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+		_, err := session.ExecContext(ctx, "UPDATE t SET x = 1")
+		return nil, err
+	})
+	summary, results, err := checks.NewRunner([]checks.PreflightCheck{check}, nil).Run(context.Background(), checks.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Block != 1 || results[0].Findings[0].Code != checks.CodeReadOnlyViolation {
+		t.Fatalf("expected READ_ONLY_VIOLATION block, got %+v", results)
+	}
+}
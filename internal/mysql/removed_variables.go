@@ -0,0 +1,362 @@
+package mysql
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"migratorx/internal/checks"
+)
+
+// ConfiguredVariable is a server variable set in an option file or persisted
+// with SET PERSIST. Loose is set for loose- prefixed options, which the server
+// skips with a warning when it does not know them.
+type ConfiguredVariable struct {
+	Name   string
+	Value  string
+	Source string
+	Loose  bool
+}
+
+// ConfigurationInspector lists the variables configured for a host.
+type ConfigurationInspector interface {
+	ConfiguredVariables(ctx context.Context, host string) ([]ConfiguredVariable, error)
+}
+
+// removedVariable is a server variable the named version no longer accepts.
+type removedVariable struct {
+	RemovedIn   string
+	Replacement string
+}
+
+// configVersions lists the versions the configuration catalogs cover, in
+// order.
+var configVersions = []string{"8.0", "8.4"}
+
+// removedVariables maps each version to the variables it removed and what
+// replaces them, if anything. Names use underscores.
+var removedVariables = map[string]map[string]string{
+	"8.0": {
+		"query_cache_limit":                     "",
+		"query_cache_min_res_unit":              "",
+		"query_cache_size":                      "",
+		"query_cache_type":                      "",
+		"query_cache_wlock_invalidate":          "",
+		"innodb_file_format":                    "",
+		"innodb_file_format_check":              "",
+		"innodb_file_format_max":                "",
+		"innodb_large_prefix":                   "",
+		"innodb_support_xa":                     "",
+		"innodb_locks_unsafe_for_binlog":        "",
+		"innodb_stats_sample_pages":             "innodb_stats_transient_sample_pages",
+		"innodb_undo_logs":                      "innodb_rollback_segments",
+		"innodb_checksums":                      "innodb_checksum_algorithm",
+		"ignore_builtin_innodb":                 "",
+		"sync_frm":                              "",
+		"secure_auth":                           "",
+		"old_passwords":                         "",
+		"log_warnings":                          "log_error_verbosity",
+		"tx_isolation":                          "transaction_isolation",
+		"tx_read_only":                          "transaction_read_only",
+		"multi_range_count":                     "",
+		"metadata_locks_cache_size":             "",
+		"metadata_locks_hash_instances":         "",
+		"date_format":                           "",
+		"datetime_format":                       "",
+		"time_format":                           "",
+		"max_tmp_tables":                        "",
+		"log_builtin_as_identified_by_password": "",
+		"temp_pool":                             "",
+		"show_compatibility_56":                 "",
+		"des_key_file":                          "",
+		"ignore_db_dirs":                        "",
+		"bootstrap":                             "--initialize",
+		"partition":                             "",
+		"log_syslog":                            "the log_sink_syseventlog component",
+		"log_syslog_facility":                   "syseventlog.facility",
+		"log_syslog_include_pid":                "syseventlog.include_pid",
+		"log_syslog_tag":                        "syseventlog.tag",
+		"group_replication_allow_local_disjoint_gtids_join": "",
+	},
+	"8.4": {
+		"default_authentication_plugin":          "authentication_policy",
+		"expire_logs_days":                       "binlog_expire_logs_seconds",
+		"master_info_repository":                 "",
+		"relay_log_info_repository":              "",
+		"log_bin_use_v1_row_events":              "",
+		"transaction_write_set_extraction":       "",
+		"binlog_transaction_dependency_tracking": "",
+		"group_replication_ip_whitelist":         "group_replication_ip_allowlist",
+		"keyring_file_data":                      "the component_keyring_file component",
+		"keyring_encrypted_file_data":            "the component_keyring_encrypted_file component",
+		"keyring_encrypted_file_password":        "the component_keyring_encrypted_file component",
+		"skip_host_cache":                        "host_cache_size=0",
+	},
+}
+
+// removedBetween returns the variables removed after source up to and
+// including target. An unknown source counts as older than every version.
+func removedBetween(source string, target string) map[string]removedVariable {
+	removed := map[string]removedVariable{}
+	for _, version := range configVersions {
+		if source != "" && versionAtLeast(source, version) {
+			continue
+		}
+		if !versionAtLeast(target, version) {
+			break
+		}
+		for name, replacement := range removedVariables[version] {
+			removed[name] = removedVariable{RemovedIn: version, Replacement: replacement}
+		}
+	}
+	return removed
+}
+
+// versionAtLeast compares dotted major.minor versions numerically.
+func versionAtLeast(version string, min string) bool {
+	var vMajor, vMinor, mMajor, mMinor int
+	fmt.Sscanf(strings.TrimSpace(version), "%d.%d", &vMajor, &vMinor)
+	fmt.Sscanf(min, "%d.%d", &mMajor, &mMinor)
+	if vMajor != mMajor {
+		return vMajor > mMajor
+	}
+	return vMinor >= mMinor
+}
+
+// RemovedVariablesCheck audits a host's option files and persisted variables
+// for variables the target version removed or renamed. mysqld refuses to
+// start with an unknown option, so each one is a BLOCK; loose- prefixed
+// options only produce a startup warning and are a WARN. Versions default to
+// the plan's source and target.
+type RemovedVariablesCheck struct {
+	Inspector     ConfigurationInspector
+	Host          string
+	SourceVersion string
+	TargetVersion string
+}
+
+func (c *RemovedVariablesCheck) Name() string   { return "removed_variables" }
+func (c *RemovedVariablesCheck) ReadOnly() bool { return true }
+
+func (c *RemovedVariablesCheck) Parameters() map[string]interface{} {
+	return map[string]interface{}{"host": c.Host, "source_version": c.SourceVersion, "target_version": c.TargetVersion}
+}
+
+func (c *RemovedVariablesCheck) Run(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+	findings, _, err := c.RunWithArtifacts(ctx, input)
+	return findings, err
+}
+
+// RunWithArtifacts also returns the configuration rendered for the target
+// version (see RenderConfiguration) as my.cnf, ready to review and install.
+func (c *RemovedVariablesCheck) RunWithArtifacts(ctx context.Context, input checks.Input) ([]checks.Finding, []checks.Artifact, error) {
+	if c.Inspector == nil {
+		return nil, nil, fmt.Errorf("configuration inspector is required")
+	}
+	host := strings.TrimSpace(c.Host)
+	if host == "" {
+		host = input.ReplicaHost
+	}
+	source, target := c.SourceVersion, c.TargetVersion
+	if source == "" {
+		source = input.PlanSourceVersion
+	}
+	if target == "" {
+		target = input.PlanTargetVersion
+	}
+	if host == "" || target == "" {
+		return nil, nil, fmt.Errorf("host and target version are required")
+	}
+
+	variables, err := c.Inspector.ConfiguredVariables(ctx, host)
+	if err != nil {
+		return []checks.Finding{{
+			Severity: checks.SeverityWarn,
+			Code:     CodeConfigAuditUnknown,
+			Message:  fmt.Sprintf("unable to read the configuration of %q: %v", host, err),
+			Meta:     map[string]interface{}{"host": host},
+		}}, nil, nil
+	}
+	removed := removedBetween(source, target)
+	findings := []checks.Finding{}
+	for _, v := range variables {
+		name, entry, ok := lookupRemoved(removed, v.Name)
+		if !ok {
+			continue
+		}
+		meta := map[string]interface{}{"host": host, "variable": name, "source": v.Source, "removed_in": entry.RemovedIn}
+		message := fmt.Sprintf("%s sets %s, which MySQL %s removed", v.Source, name, entry.RemovedIn)
+		if entry.Replacement != "" {
+			meta["replacement"] = entry.Replacement
+			message += fmt.Sprintf("; use %s instead", entry.Replacement)
+		}
+		if v.Loose {
+			findings = append(findings, checks.Finding{
+				Severity: checks.SeverityWarn,
+				Code:     CodeConfigVariableRemovedLoose,
+				Message:  message + " (loose- prefix: the server starts but ignores it)",
+				Meta:     meta,
+			})
+			continue
+		}
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityBlock,
+			Code:     CodeConfigVariableRemoved,
+			Message:  message + "; the server will not start with it",
+			Meta:     meta,
+		})
+	}
+	if len(findings) == 0 {
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityInfo,
+			Code:     CodeConfigAuditOK,
+			Message:  fmt.Sprintf("no variables removed in %s are configured on %q", target, host),
+			Meta:     map[string]interface{}{"host": host, "variables": len(variables)},
+		})
+	}
+	rendered := RenderConfiguration(variables, source, target)
+	return findings, []checks.Artifact{{Name: "my.cnf", ContentType: "text/plain", Data: []byte(rendered.Text)}}, nil
+}
+
+// lookupRemoved matches a variable, or a boolean option written with a skip,
+// enable or disable prefix, against the removed set.
+func lookupRemoved(removed map[string]removedVariable, name string) (string, removedVariable, bool) {
+	name = normalizeVariable(name)
+	if entry, ok := removed[name]; ok {
+		return name, entry, true
+	}
+	for _, prefix := range []string{"skip_", "enable_", "disable_"} {
+		if trimmed := strings.TrimPrefix(name, prefix); trimmed != name {
+			if entry, ok := removed[trimmed]; ok {
+				return trimmed, entry, true
+			}
+		}
+	}
+	return name, removedVariable{}, false
+}
+
+func normalizeVariable(name string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "-", "_")
+}
+
+// OptionFileInspector reads server options from my.cnf files on the local
+// host, following !include and !includedir. Only the [mysqld], [server] and
+// [mysqld-<version>] groups are read.
+type OptionFileInspector struct {
+	Paths []string
+}
+
+func (i *OptionFileInspector) ConfiguredVariables(ctx context.Context, host string) ([]ConfiguredVariable, error) {
+	if len(i.Paths) == 0 {
+		return nil, fmt.Errorf("option file inspector requires at least one path")
+	}
+	variables := []ConfiguredVariable{}
+	seen := map[string]bool{}
+	for _, path := range i.Paths {
+		if err := readOptionFile(path, seen, &variables); err != nil {
+			return nil, err
+		}
+	}
+	return variables, nil
+}
+
+func readOptionFile(path string, seen map[string]bool, variables *[]ConfiguredVariable) error {
+	if seen[path] {
+		return nil
+	}
+	seen[path] = true
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	section := ""
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		switch {
+		case text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, ";"):
+			continue
+		case strings.HasPrefix(text, "!includedir"):
+			dir := includePath(path, strings.TrimSpace(strings.TrimPrefix(text, "!includedir")))
+			matches, err := filepath.Glob(filepath.Join(dir, "*.cnf"))
+			if err != nil {
+				return err
+			}
+			sort.Strings(matches)
+			for _, match := range matches {
+				if err := readOptionFile(match, seen, variables); err != nil {
+					return err
+				}
+			}
+			continue
+		case strings.HasPrefix(text, "!include"):
+			if err := readOptionFile(includePath(path, strings.TrimSpace(strings.TrimPrefix(text, "!include"))), seen, variables); err != nil {
+				return err
+			}
+			continue
+		case strings.HasPrefix(text, "[") && strings.HasSuffix(text, "]"):
+			section = strings.ToLower(strings.TrimSpace(text[1 : len(text)-1]))
+			continue
+		}
+		if section != "mysqld" && section != "server" && !strings.HasPrefix(section, "mysqld-") {
+			continue
+		}
+		name, value := text, ""
+		if eq := strings.Index(text, "="); eq >= 0 {
+			name, value = strings.TrimSpace(text[:eq]), strings.Trim(strings.TrimSpace(text[eq+1:]), `"'`)
+		}
+		name = normalizeVariable(name)
+		loose := strings.HasPrefix(name, "loose_")
+		*variables = append(*variables, ConfiguredVariable{
+			Name:   strings.TrimPrefix(name, "loose_"),
+			Value:  value,
+			Source: fmt.Sprintf("%s:%d [%s]", path, line, section),
+			Loose:  loose,
+		})
+	}
+	return scanner.Err()
+}
+
+func includePath(from string, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(filepath.Dir(from), path)
+}
+
+// PersistedVariablesInspector lists variables set with SET PERSIST (8.0+),
+// which mysqld-auto.cnf replays at startup.
+type PersistedVariablesInspector struct {
+	Connect Connector
+}
+
+func (i *PersistedVariablesInspector) ConfiguredVariables(ctx context.Context, host string) ([]ConfiguredVariable, error) {
+	if i.Connect == nil {
+		return nil, fmt.Errorf("persisted variables inspector requires a connector")
+	}
+	q, err := i.Connect(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := q.QueryContext(ctx, "SELECT VARIABLE_NAME, VARIABLE_VALUE FROM performance_schema.persisted_variables")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read persisted variables: %w", err)
+	}
+	defer rows.Close()
+	variables := []ConfiguredVariable{}
+	for rows.Next() {
+		var v ConfiguredVariable
+		if err := rows.Scan(&v.Name, &v.Value); err != nil {
+			return nil, err
+		}
+		v.Source = "mysqld-auto.cnf (SET PERSIST)"
+		variables = append(variables, v)
+	}
+	return variables, rows.Err()
+}
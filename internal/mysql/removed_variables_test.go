@@ -0,0 +1,101 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"migratorx/internal/checks"
+)
+
+func writeOptionFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func TestRemovedVariablesCheck_AuditsOptionFiles(t *testing.T) {
+	dir := t.TempDir()
+	confd := filepath.Join(dir, "conf.d")
+	if err := os.Mkdir(confd, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	main := filepath.Join(dir, "my.cnf")
+	writeOptionFile(t, main, "[client]\nquery_cache_size = 0\n\n[mysqld]\nquery-cache-type = 0\ntx_isolation = READ-COMMITTED\nloose-innodb_large_prefix = ON\nmax_connections = 500\n!includedir conf.d\n")
+	writeOptionFile(t, filepath.Join(confd, "replication.cnf"), "[mysqld-5.7]\nskip-sync-frm\n[mysqldump]\nquick\n")
+
+	check := &RemovedVariablesCheck{Inspector: &OptionFileInspector{Paths: []string{main}}, Host: "replica-1"}
+	findings, err := check.Run(context.Background(), checks.Input{PlanSourceVersion: "5.7", PlanTargetVersion: "8.0"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 4 {
+		t.Fatalf("expected three removed variables and one loose option, got %+v", findings)
+	}
+	if f := findings[0]; f.Severity != checks.SeverityBlock || f.Meta["variable"] != "query_cache_type" || !strings.HasSuffix(f.Meta["source"].(string), "my.cnf:5 [mysqld]") {
+		t.Fatalf("expected the [mysqld] query cache setting to block, got %+v", f)
+	}
+	if f := findings[1]; f.Meta["replacement"] != "transaction_isolation" {
+		t.Fatalf("expected tx_isolation to suggest its replacement, got %+v", f)
+	}
+	if f := findings[2]; f.Severity != checks.SeverityWarn || f.Code != CodeConfigVariableRemovedLoose {
+		t.Fatalf("expected the loose option to warn, got %+v", f)
+	}
+	if f := findings[3]; f.Meta["variable"] != "sync_frm" {
+		t.Fatalf("expected the included skip-sync-frm to block, got %+v", f)
+	}
+}
+
+func TestRemovedVariablesCheck_OnlyReportsRemovalsAfterSource(t *testing.T) {
+	inspector := &fakeConfigurationInspector{variables: []ConfiguredVariable{
+		{Name: "expire_logs_days", Value: "7", Source: "mysqld-auto.cnf (SET PERSIST)"},
+		{Name: "query_cache_type", Value: "0", Source: "mysqld-auto.cnf (SET PERSIST)"},
+	}}
+	check := &RemovedVariablesCheck{Inspector: inspector, Host: "replica-1", SourceVersion: "8.0", TargetVersion: "8.4"}
+	findings, err := check.Run(context.Background(), checks.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Meta["variable"] != "expire_logs_days" || findings[0].Meta["removed_in"] != "8.4" {
+		t.Fatalf("expected only the 8.4 removal, got %+v", findings)
+	}
+
+	check.TargetVersion = "8.0"
+	if findings, _ = check.Run(context.Background(), checks.Input{}); findings[0].Code != CodeConfigAuditOK {
+		t.Fatalf("expected nothing removed between 8.0 and 8.0, got %+v", findings)
+	}
+}
+
+func TestRemovedVariablesCheck_UnreadableConfigWarns(t *testing.T) {
+	check := &RemovedVariablesCheck{Inspector: &OptionFileInspector{Paths: []string{filepath.Join(t.TempDir(), "missing.cnf")}}, Host: "replica-1", TargetVersion: "8.0"}
+	findings, err := check.Run(context.Background(), checks.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if findings[0].Code != CodeConfigAuditUnknown {
+		t.Fatalf("expected a WARN, got %+v", findings)
+	}
+}
+
+func TestPersistedVariablesInspector(t *testing.T) {
+	db := openFakeDB(t, fakeResponse{match: "persisted_variables", columns: []string{"VARIABLE_NAME", "VARIABLE_VALUE"}, rows: [][]driver.Value{{"default_authentication_plugin", "mysql_native_password"}}})
+	variables, err := (&PersistedVariablesInspector{Connect: fakeConnector(db)}).ConfiguredVariables(context.Background(), "replica-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(variables) != 1 || variables[0].Name != "default_authentication_plugin" || variables[0].Loose {
+		t.Fatalf("unexpected variables %+v", variables)
+	}
+}
+
+type fakeConfigurationInspector struct {
+	variables []ConfiguredVariable
+}
+
+func (f *fakeConfigurationInspector) ConfiguredVariables(ctx context.Context, host string) ([]ConfiguredVariable, error) {
+	return f.variables, nil
+}
@@ -0,0 +1,86 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"migratorx/internal/checks"
+)
+
+// ReplicaLagCheck measures the replica's applier lag against the plan's
+// max_lag threshold. Lag is only known on servers that expose applier
+// timestamps (MySQL 8.0+); otherwise the check warns that it cannot measure.
+// ThresholdName names the plan threshold MaxLag came from in findings
+// (default max_lag), e.g. max_cross_region_lag.
+type ReplicaLagCheck struct {
+	Inspector     ReplicaInspector
+	Replica       string
+	MaxLag        time.Duration
+	ThresholdName string
+}
+
+func (c *ReplicaLagCheck) Name() string   { return "replica_lag" }
+func (c *ReplicaLagCheck) ReadOnly() bool { return true }
+
+func (c *ReplicaLagCheck) Parameters() map[string]interface{} {
+	return map[string]interface{}{"replica": c.Replica, "max_lag": c.MaxLag.String()}
+}
+
+func (c *ReplicaLagCheck) Run(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+	if c.Inspector == nil {
+		return nil, fmt.Errorf("replica inspector is required")
+	}
+	if c.MaxLag <= 0 {
+		return nil, fmt.Errorf("max lag must be positive")
+	}
+	replica := strings.TrimSpace(c.Replica)
+	if replica == "" {
+		replica = input.ReplicaHost
+	}
+	if replica == "" {
+		return nil, fmt.Errorf("replica is required")
+	}
+
+	status, err := c.Inspector.ReplicationStatus(ctx, replica)
+	if err != nil {
+		return []checks.Finding{{
+			Severity: checks.SeverityWarn,
+			Code:     CodeReplicaLagUnknown,
+			Message:  fmt.Sprintf("unable to read replication status on %q: %v", replica, err),
+			Meta:     map[string]interface{}{"replica": replica},
+		}}, nil
+	}
+	lag, ok := status.MaxApplierLag()
+	if !ok {
+		return []checks.Finding{{
+			Severity: checks.SeverityWarn,
+			Code:     CodeReplicaLagUnknown,
+			Message:  fmt.Sprintf("replica %q does not report applier lag; lag cannot be checked against %s", replica, c.MaxLag),
+			Meta:     map[string]interface{}{"replica": replica},
+		}}, nil
+	}
+
+	name := c.ThresholdName
+	if name == "" {
+		name = "max_lag"
+	}
+	threshold := checks.DurationThreshold(name, c.MaxLag)
+	measured := lag.Seconds()
+	meta := map[string]interface{}{"replica": replica, "lag_seconds": measured, "threshold": threshold.Meta(measured)}
+	if threshold.Exceeded(measured) {
+		return []checks.Finding{{
+			Severity: checks.SeverityWarn,
+			Code:     CodeReplicaLagExceeded,
+			Message:  fmt.Sprintf("replica %q lags %.1fs behind the primary (limit %.1fs)", replica, measured, threshold.Limit),
+			Meta:     meta,
+		}}, nil
+	}
+	return []checks.Finding{{
+		Severity: checks.SeverityInfo,
+		Code:     CodeReplicaLagOK,
+		Message:  fmt.Sprintf("replica %q lags %.1fs behind the primary (limit %.1fs)", replica, measured, threshold.Limit),
+		Meta:     meta,
+	}}, nil
+}
@@ -0,0 +1,40 @@
+package mysql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"migratorx/internal/checks"
+)
+
+func TestReplicaLagCheck_ReportsMarginAgainstThreshold(t *testing.T) {
+	inspector := &fakeInspector{status: ReplicationStatus{Channels: []ChannelStatus{
+		{Name: "", ApplierLag: 4 * time.Second, LagKnown: true},
+	}}}
+	check := &ReplicaLagCheck{Inspector: inspector, Replica: "db-replica", MaxLag: 10 * time.Second}
+	findings, err := check.Run(context.Background(), checks.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Code != CodeReplicaLagOK {
+		t.Fatalf("expected lag within threshold, got %+v", findings)
+	}
+	if margin := findings[0].Meta["threshold"].(map[string]interface{})["margin"]; margin != 6.0 {
+		t.Fatalf("expected 6s margin, got %v", margin)
+	}
+
+	inspector.status.Channels[0].ApplierLag = 12 * time.Second
+	findings, _ = check.Run(context.Background(), checks.Input{})
+	if findings[0].Code != CodeReplicaLagExceeded || findings[0].Severity != checks.SeverityWarn {
+		t.Fatalf("expected lag WARN, got %+v", findings)
+	}
+}
+
+func TestReplicaLagCheck_WarnsWhenLagUnknown(t *testing.T) {
+	check := &ReplicaLagCheck{Inspector: &fakeInspector{status: ReplicationStatus{IOThreadRunning: true, SQLThreadRunning: true}}, Replica: "db-replica", MaxLag: time.Second}
+	findings, err := check.Run(context.Background(), checks.Input{})
+	if err != nil || len(findings) != 1 || findings[0].Code != CodeReplicaLagUnknown {
+		t.Fatalf("expected unknown lag WARN, got %+v %v", findings, err)
+	}
+}
@@ -0,0 +1,163 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"migratorx/internal/checks"
+)
+
+// Replica traffic defaults.
+const (
+	DefaultTrafficSampleInterval = 10 * time.Second
+	DefaultMaxReadQPS            = 5.0
+	DefaultMaxClientSessions     = 2
+)
+
+// TrafficInspector reads activity on a host.
+type TrafficInspector interface {
+	// StatusCounter returns a SHOW GLOBAL STATUS counter such as Com_select.
+	StatusCounter(ctx context.Context, host string, name string) (int64, error)
+	// ClientSessions counts active application sessions, excluding idle,
+	// replication and system threads.
+	ClientSessions(ctx context.Context, host string) (int, error)
+}
+
+// ReplicaTrafficCheck warns when the replica about to be upgraded is still
+// serving reads, i.e. it has not been drained from the load balancer before
+// StopReplication takes it out of sync.
+type ReplicaTrafficCheck struct {
+	Inspector         TrafficInspector
+	Replica           string
+	SampleInterval    time.Duration
+	MaxReadQPS        float64
+	MaxClientSessions int
+}
+
+func (c *ReplicaTrafficCheck) Name() string   { return "replica_read_traffic" }
+func (c *ReplicaTrafficCheck) ReadOnly() bool { return true }
+
+func (c *ReplicaTrafficCheck) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"replica":             c.Replica,
+		"sample_interval":     c.SampleInterval.String(),
+		"max_read_qps":        c.MaxReadQPS,
+		"max_client_sessions": c.MaxClientSessions,
+	}
+}
+
+func (c *ReplicaTrafficCheck) Run(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+	if c.Inspector == nil {
+		return nil, fmt.Errorf("traffic inspector is required")
+	}
+	replica := strings.TrimSpace(c.Replica)
+	if replica == "" {
+		replica = input.ReplicaHost
+	}
+	if replica == "" {
+		return nil, fmt.Errorf("replica is required")
+	}
+	if c.SampleInterval <= 0 {
+		c.SampleInterval = DefaultTrafficSampleInterval
+	}
+	if c.MaxReadQPS <= 0 {
+		c.MaxReadQPS = DefaultMaxReadQPS
+	}
+	if c.MaxClientSessions <= 0 {
+		c.MaxClientSessions = DefaultMaxClientSessions
+	}
+
+	qps, sessions, err := c.sample(ctx, replica)
+	if err != nil {
+		return []checks.Finding{{
+			Severity: checks.SeverityWarn,
+			Code:     CodeReplicaTrafficUnavailable,
+			Message:  fmt.Sprintf("unable to sample read traffic on %q: %v", replica, err),
+			Meta:     map[string]interface{}{"replica": replica},
+		}}, nil
+	}
+
+	meta := map[string]interface{}{"replica": replica, "read_qps": qps, "client_sessions": sessions, "sample_interval": c.SampleInterval.String()}
+	if qps > c.MaxReadQPS || sessions > c.MaxClientSessions {
+		return []checks.Finding{{
+			Severity: checks.SeverityWarn,
+			Code:     CodeReplicaServingReads,
+			Message:  fmt.Sprintf("replica %q is serving reads (%.1f SELECT/s, %d active sessions); drain it from the load balancer before stopping replication", replica, qps, sessions),
+			Meta:     meta,
+		}}, nil
+	}
+	return []checks.Finding{{
+		Severity: checks.SeverityInfo,
+		Code:     CodeReplicaTrafficDrained,
+		Message:  fmt.Sprintf("replica %q has no significant read traffic (%.1f SELECT/s, %d active sessions)", replica, qps, sessions),
+		Meta:     meta,
+	}}, nil
+}
+
+func (c *ReplicaTrafficCheck) sample(ctx context.Context, replica string) (float64, int, error) {
+	before, err := c.Inspector.StatusCounter(ctx, replica, "Com_select")
+	if err != nil {
+		return 0, 0, err
+	}
+	start := time.Now()
+	if err := sleepContext(ctx, c.SampleInterval); err != nil {
+		return 0, 0, err
+	}
+	after, err := c.Inspector.StatusCounter(ctx, replica, "Com_select")
+	if err != nil {
+		return 0, 0, err
+	}
+	sessions, err := c.Inspector.ClientSessions(ctx, replica)
+	if err != nil {
+		return 0, 0, err
+	}
+	qps := float64(after-before) / time.Since(start).Seconds()
+	if qps < 0 {
+		qps = 0
+	}
+	return qps, sessions, nil
+}
+
+const clientSessionsQuery = `SELECT COUNT(*) FROM information_schema.PROCESSLIST
+WHERE COMMAND NOT IN ('Sleep', 'Daemon', 'Binlog Dump', 'Binlog Dump GTID', 'Connect')
+  AND USER NOT IN ('system user', 'event_scheduler')
+  AND ID <> CONNECTION_ID()`
+
+// StatusTrafficInspector implements TrafficInspector with SHOW GLOBAL STATUS
+// and information_schema.PROCESSLIST.
+type StatusTrafficInspector struct {
+	Connect Connector
+}
+
+func (i *StatusTrafficInspector) StatusCounter(ctx context.Context, host string, name string) (int64, error) {
+	if i.Connect == nil {
+		return 0, fmt.Errorf("traffic inspector requires a connector")
+	}
+	q, err := i.Connect(ctx, host)
+	if err != nil {
+		return 0, err
+	}
+	var variable string
+	var value int64
+	if err := queryOne(ctx, q, "SHOW GLOBAL STATUS LIKE ?", []interface{}{name}, &variable, &value); err != nil {
+		return 0, fmt.Errorf("failed to read status %s: %w", name, err)
+	}
+	return value, nil
+}
+
+func (i *StatusTrafficInspector) ClientSessions(ctx context.Context, host string) (int, error) {
+	if i.Connect == nil {
+		return 0, fmt.Errorf("traffic inspector requires a connector")
+	}
+	q, err := i.Connect(ctx, host)
+	if err != nil {
+		return 0, err
+	}
+	var sessions int
+	if err := queryOne(ctx, q, clientSessionsQuery, nil, &sessions); err != nil {
+		return 0, fmt.Errorf("failed to count client sessions: %w", err)
+	}
+	return sessions, nil
+}
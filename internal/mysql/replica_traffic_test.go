@@ -0,0 +1,66 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"migratorx/internal/checks"
+)
+
+type fakeTrafficInspector struct {
+	counters []int64
+	sessions int
+	reads    int
+}
+
+func (f *fakeTrafficInspector) StatusCounter(ctx context.Context, host string, name string) (int64, error) {
+	v := f.counters[f.reads]
+	f.reads++
+	return v, nil
+}
+
+func (f *fakeTrafficInspector) ClientSessions(ctx context.Context, host string) (int, error) {
+	return f.sessions, nil
+}
+
+func TestReplicaTrafficCheck_ServingReadsWarns(t *testing.T) {
+	inspector := &fakeTrafficInspector{counters: []int64{1000, 1500}}
+	check := &ReplicaTrafficCheck{Inspector: inspector, Replica: "mysql-replica-1", SampleInterval: 10 * time.Millisecond}
+	findings, err := check.Run(context.Background(), checks.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Code != CodeReplicaServingReads || findings[0].Severity != checks.SeverityWarn {
+		t.Fatalf("expected REPLICA_SERVING_READS warning, got %+v", findings)
+	}
+}
+
+func TestReplicaTrafficCheck_DrainedInfo(t *testing.T) {
+	inspector := &fakeTrafficInspector{counters: []int64{1000, 1000}, sessions: 1}
+	check := &ReplicaTrafficCheck{Inspector: inspector, SampleInterval: time.Millisecond}
+	findings, err := check.Run(context.Background(), checks.Input{ReplicaHost: "mysql-replica-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Code != CodeReplicaTrafficDrained {
+		t.Fatalf("expected REPLICA_TRAFFIC_DRAINED, got %+v", findings)
+	}
+}
+
+func TestStatusTrafficInspector_ReadsCountersAndSessions(t *testing.T) {
+	db := openFakeDB(t,
+		fakeResponse{match: "SHOW GLOBAL STATUS", columns: []string{"Variable_name", "Value"}, rows: [][]driver.Value{{"Com_select", int64(4242)}}},
+		fakeResponse{match: "PROCESSLIST", columns: []string{"COUNT"}, rows: [][]driver.Value{{int64(7)}}},
+	)
+	inspector := &StatusTrafficInspector{Connect: fakeConnector(db)}
+	v, err := inspector.StatusCounter(context.Background(), "mysql-replica-1", "Com_select")
+	if err != nil || v != 4242 {
+		t.Fatalf("expected Com_select=4242, got %d (%v)", v, err)
+	}
+	sessions, err := inspector.ClientSessions(context.Background(), "mysql-replica-1")
+	if err != nil || sessions != 7 {
+		t.Fatalf("expected 7 sessions, got %d (%v)", sessions, err)
+	}
+}
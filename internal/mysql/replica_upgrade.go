@@ -5,10 +5,19 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
+	"migratorx/internal/failure"
 	"migratorx/internal/workflow"
 )
 
+// Replication resume defaults.
+const (
+	DefaultResumeTimeout      = 10 * time.Minute
+	DefaultResumePollInterval = 5 * time.Second
+	DefaultResumeMaxLag       = 10 * time.Second
+)
+
 // Severity indicates the importance of a replica-upgrade finding.
 type Severity int
 
@@ -31,9 +40,11 @@ func (s Severity) String() string {
 	}
 }
 
-// Finding is a structured result from replica upgrade orchestration.
+// Finding is a structured result from replica upgrade orchestration. Code is
+// set by steps that emit stable finding codes.
 type Finding struct {
 	Severity Severity
+	Code     string
 	Message  string
 	Meta     map[string]interface{}
 }
@@ -45,10 +56,42 @@ type Summary struct {
 	Block int
 }
 
-// ReplicationStatus models minimal replication state for partial-progress detection.
+// ReplicationStatus models replication state for partial-progress detection.
+// The thread booleans summarize all channels; Channels carries per-channel
+// detail when the inspector provides it.
 type ReplicationStatus struct {
 	IOThreadRunning  bool
 	SQLThreadRunning bool
+	Channels         []ChannelStatus
+}
+
+// ChannelStatus is the status of one replication channel. ApplierLag is only
+// meaningful when LagKnown is set (MySQL 8.0+).
+type ChannelStatus struct {
+	Name            string
+	ConnectionState string
+	ApplierState    string
+	LastErrorNumber int
+	LastError       string
+	ReceivedGTIDSet string
+	ApplierLag      time.Duration
+	LagKnown        bool
+}
+
+// MaxApplierLag returns the largest known applier lag across channels.
+func (s ReplicationStatus) MaxApplierLag() (time.Duration, bool) {
+	var max time.Duration
+	known := false
+	for _, c := range s.Channels {
+		if !c.LagKnown {
+			continue
+		}
+		known = true
+		if c.ApplierLag > max {
+			max = c.ApplierLag
+		}
+	}
+	return max, known
 }
 
 // ReplicaInspector provides read-only inspection for orchestration decisions.
@@ -65,12 +108,46 @@ type ReplicaActions interface {
 }
 
 // UpgradeOrchestrator coordinates a safe, idempotent replica upgrade.
+// Drainers, when set, take the replica out of read traffic before replication
+// is stopped; the replica stays drained until Undrain is called after validation.
+// Verifier, when set, confirms the replica runs TargetVersion with a completed
+// server upgrade before the upgrade is checkpointed; otherwise a successful
+// RunUpgrade is trusted.
+//
+// After StartReplication the orchestrator polls replication status every
+// ResumePollInterval until both threads run and the replica is catching up
+// (lag within ResumeMaxLag or lower than the previous sample), for at most
+// ResumeTimeout, before checkpointing the resume. ReplicaResumeMaxLag
+// overrides ResumeMaxLag for individual replicas, e.g. cross-region ones.
+//
+// Reconcile adjusts checkpoints that disagree with the observed replication
+// status instead of warning and proceeding on them.
+//
+// Snapshots, when set, records the replica's key variables and schema object
+// counts before and after the upgrade in State and warns about what the
+// upgrade changed.
+//
+// Capacity, when set, is consulted before an upgrade starts with the other
+// replicas of Pool still serving reads according to the checkpoints; a
+// shortfall refuses the upgrade with a BLOCK. Upgrades already under way
+// continue.
 type UpgradeOrchestrator struct {
-	Inspector ReplicaInspector
-	Actions   ReplicaActions
-	State     workflow.State
-	Primary   string
-	Logger    *log.Logger
+	Inspector           ReplicaInspector
+	Actions             ReplicaActions
+	State               workflow.State
+	Primary             string
+	Logger              *log.Logger
+	Drainers            []Drainer
+	Verifier            UpgradeStatusInspector
+	TargetVersion       string
+	ResumeTimeout       time.Duration
+	ResumePollInterval  time.Duration
+	ResumeMaxLag        time.Duration
+	ReplicaResumeMaxLag map[string]time.Duration
+	Reconcile           bool
+	Snapshots           SnapshotInspector
+	Capacity            *ReadCapacity
+	Pool                []string
 }
 
 // NewUpgradeOrchestrator constructs an orchestrator with defaults.
@@ -98,12 +175,12 @@ func (o *UpgradeOrchestrator) Run(ctx context.Context, replica string) (Summary,
 		return Summary{Block: 1}, []Finding{{Severity: SeverityBlock, Message: "replica is required"}}, nil
 	}
 	if o.Inspector == nil || o.Actions == nil {
-		return Summary{}, nil, fmt.Errorf("inspector and actions are required")
+		return Summary{}, nil, failure.Config("inspector and actions are required")
 	}
 
 	isPrimary, err := o.Inspector.IsPrimary(ctx, replica)
 	if err != nil {
-		return Summary{Block: 1}, []Finding{{Severity: SeverityBlock, Message: fmt.Sprintf("failed to determine primary status: %v", err)}}, nil
+		return appendFailure(summary, findings, failure.Inspect(replica, "failed to determine primary status", err))
 	}
 	if isPrimary || (o.Primary != "" && replica == o.Primary) {
 		return Summary{Block: 1}, []Finding{{Severity: SeverityBlock, Message: "refusing to upgrade primary", Meta: map[string]interface{}{"replica": replica}}}, nil
@@ -115,7 +192,7 @@ func (o *UpgradeOrchestrator) Run(ctx context.Context, replica string) (Summary,
 		findings = append(findings, warn)
 		applySummary(&summary, []Finding{warn})
 	} else {
-		findings = append(findings, detectPartialProgress(replica, status, o.State)...)
+		findings = append(findings, detectPartialProgress(replica, status, o.State, o.Reconcile)...)
 		applySummary(&summary, findings)
 	}
 
@@ -123,10 +200,38 @@ func (o *UpgradeOrchestrator) Run(ctx context.Context, replica string) (Summary,
 		return summary, findings, nil
 	}
 
+	if o.Capacity != nil && !o.Started(replica) {
+		serving := []string{}
+		for _, other := range o.Pool {
+			if other != replica && o.inService(other) {
+				serving = append(serving, other)
+			}
+		}
+		if capacity, reason := o.Capacity.Shortfall(serving); reason != "" {
+			block := Finding{Severity: SeverityBlock, Message: fmt.Sprintf("refusing to start the upgrade of %s: %s", replica, reason), Meta: map[string]interface{}{"replica": replica, "serving": serving, "read_capacity": capacity, "min_capacity": o.Capacity.MinCapacity}}
+			findings = append(findings, block)
+			applySummary(&summary, []Finding{block})
+			return summary, findings, nil
+		}
+	}
+
+	for _, d := range o.Drainers {
+		if ok, _ := getBool(o.State, drainedKey(replica, d.Name())); ok {
+			continue
+		}
+		o.Logger.Printf("draining %s via %s", replica, d.Name())
+		if err := d.Drain(ctx, replica); err != nil {
+			return appendFailure(summary, findings, failure.Act(replica, "failed to drain replica via "+d.Name(), err))
+		}
+		setBool(o.State, drainedKey(replica, d.Name()), true)
+		findings = append(findings, Finding{Severity: SeverityInfo, Message: fmt.Sprintf("replica drained via %s", d.Name()), Meta: map[string]interface{}{"replica": replica, "drainer": d.Name()}})
+		applySummary(&summary, []Finding{findings[len(findings)-1]})
+	}
+
 	if ok, _ := getBool(o.State, stoppedKey(replica)); !ok {
 		o.Logger.Printf("stopping replication on %s", replica)
 		if err := o.Actions.StopReplication(ctx, replica); err != nil {
-			return appendBlock(summary, findings, fmt.Sprintf("failed to stop replication: %v", err))
+			return appendFailure(summary, findings, failure.Act(replica, "failed to stop replication", err))
 		}
 		setBool(o.State, stoppedKey(replica), true)
 		findings = append(findings, Finding{Severity: SeverityInfo, Message: "replication stopped", Meta: map[string]interface{}{"replica": replica}})
@@ -137,13 +242,29 @@ func (o *UpgradeOrchestrator) Run(ctx context.Context, replica string) (Summary,
 	}
 
 	if ok, _ := getBool(o.State, upgradedKey(replica)); !ok {
+		if o.Snapshots != nil {
+			if err := o.snapshotBefore(ctx, replica); err != nil {
+				return appendFailure(summary, findings, err)
+			}
+		}
 		o.Logger.Printf("running upgrade on %s", replica)
 		if err := o.Actions.RunUpgrade(ctx, replica); err != nil {
-			return appendBlock(summary, findings, fmt.Sprintf("upgrade failed: %v", err))
+			return appendFailure(summary, findings, failure.Act(replica, "upgrade failed", err))
+		}
+		completed := []Finding{{Severity: SeverityInfo, Message: "upgrade completed", Meta: map[string]interface{}{"replica": replica}}}
+		if o.Verifier != nil {
+			verified, err := o.verifyUpgrade(ctx, replica)
+			if err != nil {
+				return appendFailure(summary, findings, err)
+			}
+			completed = verified
+		}
+		if o.Snapshots != nil {
+			completed = append(completed, o.snapshotAfter(ctx, replica)...)
 		}
 		setBool(o.State, upgradedKey(replica), true)
-		findings = append(findings, Finding{Severity: SeverityInfo, Message: "upgrade completed", Meta: map[string]interface{}{"replica": replica}})
-		applySummary(&summary, []Finding{findings[len(findings)-1]})
+		findings = append(findings, completed...)
+		applySummary(&summary, completed)
 	} else {
 		findings = append(findings, Finding{Severity: SeverityInfo, Message: "upgrade already completed", Meta: map[string]interface{}{"replica": replica}})
 		applySummary(&summary, []Finding{findings[len(findings)-1]})
@@ -152,11 +273,15 @@ func (o *UpgradeOrchestrator) Run(ctx context.Context, replica string) (Summary,
 	if ok, _ := getBool(o.State, resumedKey(replica)); !ok {
 		o.Logger.Printf("starting replication on %s", replica)
 		if err := o.Actions.StartReplication(ctx, replica); err != nil {
-			return appendBlock(summary, findings, fmt.Sprintf("failed to start replication: %v", err))
+			return appendFailure(summary, findings, failure.Act(replica, "failed to start replication", err))
+		}
+		progress, err := o.waitReplicationHealthy(ctx, replica)
+		findings = append(findings, progress...)
+		applySummary(&summary, progress)
+		if err != nil {
+			return appendFailure(summary, findings, err)
 		}
 		setBool(o.State, resumedKey(replica), true)
-		findings = append(findings, Finding{Severity: SeverityInfo, Message: "replication started", Meta: map[string]interface{}{"replica": replica}})
-		applySummary(&summary, []Finding{findings[len(findings)-1]})
 	} else {
 		findings = append(findings, Finding{Severity: SeverityInfo, Message: "replication already started", Meta: map[string]interface{}{"replica": replica}})
 		applySummary(&summary, []Finding{findings[len(findings)-1]})
@@ -165,23 +290,168 @@ func (o *UpgradeOrchestrator) Run(ctx context.Context, replica string) (Summary,
 	return summary, findings, nil
 }
 
-func detectPartialProgress(replica string, status ReplicationStatus, state workflow.State) []Finding {
+// waitReplicationHealthy polls the replica after StartReplication. It returns
+// a progress finding whenever the observed state changes and a final INFO once
+// both threads run and lag is within ResumeMaxLag or trending down. Threads
+// that are still not running at the timeout are an error; a replica whose
+// threads run but whose lag keeps growing is a WARN, since validation and the
+// rolling-upgrade guard check lag again before it serves reads.
+func (o *UpgradeOrchestrator) waitReplicationHealthy(ctx context.Context, replica string) ([]Finding, error) {
+	timeout := o.ResumeTimeout
+	if timeout <= 0 {
+		timeout = DefaultResumeTimeout
+	}
+	interval := o.ResumePollInterval
+	if interval <= 0 {
+		interval = DefaultResumePollInterval
+	}
+	maxLag := o.ResumeMaxLag
+	if limit, ok := o.ReplicaResumeMaxLag[replica]; ok {
+		maxLag = limit
+	}
+	if maxLag <= 0 {
+		maxLag = DefaultResumeMaxLag
+	}
+
+	findings := []Finding{}
+	start := time.Now()
+	deadline := start.Add(timeout)
+	var previousLag time.Duration
+	previousKnown := false
+	lastPhase := ""
+	for {
+		status, err := o.Inspector.ReplicationStatus(ctx, replica)
+		meta := map[string]interface{}{"replica": replica, "elapsed": time.Since(start).Round(time.Second).String()}
+		var phase, progress string
+		switch {
+		case err != nil:
+			phase = "status"
+			progress = fmt.Sprintf("waiting for replication status on %s: %v", replica, err)
+		case !status.IOThreadRunning || !status.SQLThreadRunning:
+			phase = fmt.Sprintf("threads:%t:%t", status.IOThreadRunning, status.SQLThreadRunning)
+			progress = fmt.Sprintf("waiting for replication threads on %s (IO running: %t, SQL running: %t)", replica, status.IOThreadRunning, status.SQLThreadRunning)
+			for _, c := range status.Channels {
+				if c.LastErrorNumber != 0 {
+					progress += fmt.Sprintf("; channel %q error %d: %s", c.Name, c.LastErrorNumber, c.LastError)
+				}
+			}
+		default:
+			lag, known := status.MaxApplierLag()
+			if known {
+				meta["lag"] = lag.String()
+			}
+			if !known || lag <= maxLag || (previousKnown && lag < previousLag) {
+				findings = append(findings, Finding{Severity: SeverityInfo, Message: "replication started", Meta: meta})
+				return findings, nil
+			}
+			phase = "lag"
+			progress = fmt.Sprintf("waiting for lag on %s to trend down (%s behind)", replica, lag)
+			previousLag, previousKnown = lag, true
+		}
+		o.Logger.Print(progress)
+		if phase != lastPhase {
+			findings = append(findings, Finding{Severity: SeverityInfo, Message: progress, Meta: meta})
+			lastPhase = phase
+		}
+
+		if time.Now().After(deadline) {
+			switch phase {
+			case "lag":
+				findings = append(findings, Finding{Severity: SeverityWarn, Message: fmt.Sprintf("replication on %s is running but lag did not trend down within %s", replica, timeout), Meta: meta})
+				return findings, nil
+			case "status":
+				return findings, failure.Inspect(replica, "failed to confirm replication resumed", err)
+			default:
+				return findings, failure.Act(replica, "replication did not resume", fmt.Errorf("threads not running %s after start", timeout))
+			}
+		}
+		if err := sleepContext(ctx, interval); err != nil {
+			return findings, err
+		}
+	}
+}
+
+// Undrain returns the replica to read traffic, reversing drainers in reverse
+// order. Only drainers with a drained checkpoint are undrained, so it is safe
+// to re-run after a crash.
+func (o *UpgradeOrchestrator) Undrain(ctx context.Context, replica string) (Summary, []Finding, error) {
+	var summary Summary
+	findings := []Finding{}
+	replica = strings.TrimSpace(replica)
+	if replica == "" {
+		return Summary{Block: 1}, []Finding{{Severity: SeverityBlock, Message: "replica is required"}}, nil
+	}
+	for i := len(o.Drainers) - 1; i >= 0; i-- {
+		d := o.Drainers[i]
+		if ok, _ := getBool(o.State, drainedKey(replica, d.Name())); !ok {
+			continue
+		}
+		o.Logger.Printf("undraining %s via %s", replica, d.Name())
+		if err := d.Undrain(ctx, replica); err != nil {
+			return appendFailure(summary, findings, failure.Act(replica, "failed to undrain replica via "+d.Name(), err))
+		}
+		setBool(o.State, drainedKey(replica, d.Name()), false)
+		findings = append(findings, Finding{Severity: SeverityInfo, Message: fmt.Sprintf("replica returned to traffic via %s", d.Name()), Meta: map[string]interface{}{"replica": replica, "drainer": d.Name()}})
+		applySummary(&summary, []Finding{findings[len(findings)-1]})
+	}
+	if len(findings) == 0 {
+		findings = append(findings, Finding{Severity: SeverityInfo, Message: "replica is not drained", Meta: map[string]interface{}{"replica": replica}})
+		applySummary(&summary, findings)
+	}
+	return summary, findings, nil
+}
+
+// detectPartialProgress compares the checkpoints with the observed replication
+// threads. By default a mismatch is a WARN and the flow proceeds on the
+// checkpoints; with reconcile the checkpoints are adjusted to the observed
+// state and each adjustment is reported as an INFO, so the next steps act on
+// reality: a stopped replica is recorded as stopped, a resume that was undone
+// is cleared so replication is started again, and running threads are
+// recorded as resumed after the upgrade or cleared as stopped before it.
+func detectPartialProgress(replica string, status ReplicationStatus, state workflow.State, reconcile bool) []Finding {
 	findings := []Finding{}
 
 	stopped, _ := getBool(state, stoppedKey(replica))
+	upgraded, _ := getBool(state, upgradedKey(replica))
 	resumed, _ := getBool(state, resumedKey(replica))
 
 	threadsRunning := status.IOThreadRunning && status.SQLThreadRunning
 	threadsStopped := !status.IOThreadRunning && !status.SQLThreadRunning
 
+	mismatch := func(message string, key string, value bool) {
+		if !reconcile {
+			findings = append(findings, Finding{Severity: SeverityWarn, Message: message, Meta: map[string]interface{}{"replica": replica}})
+			return
+		}
+		setBool(state, key, value)
+		findings = append(findings, Finding{
+			Severity: SeverityInfo,
+			Message:  fmt.Sprintf("reconciled: %s; set %s to %t", message, key, value),
+			Meta:     map[string]interface{}{"replica": replica, "reconciled": key, "value": value, "io_running": status.IOThreadRunning, "sql_running": status.SQLThreadRunning},
+		})
+	}
+
 	if threadsStopped && !stopped {
-		findings = append(findings, Finding{Severity: SeverityWarn, Message: "replication appears stopped but checkpoint is missing", Meta: map[string]interface{}{"replica": replica}})
+		mismatch("replication appears stopped but checkpoint is missing", stoppedKey(replica), true)
 	}
 	if threadsRunning && stopped && !resumed {
-		findings = append(findings, Finding{Severity: SeverityWarn, Message: "checkpoint indicates replication stopped but status is running", Meta: map[string]interface{}{"replica": replica}})
+		if upgraded {
+			mismatch("checkpoint indicates replication stopped but status is running", resumedKey(replica), true)
+		} else {
+			mismatch("checkpoint indicates replication stopped but status is running", stoppedKey(replica), false)
+		}
 	}
 	if threadsStopped && resumed {
-		findings = append(findings, Finding{Severity: SeverityWarn, Message: "checkpoint indicates replication started but status is stopped", Meta: map[string]interface{}{"replica": replica}})
+		mismatch("checkpoint indicates replication started but status is stopped", resumedKey(replica), false)
+	}
+	for _, c := range status.Channels {
+		if c.LastErrorNumber != 0 {
+			findings = append(findings, Finding{
+				Severity: SeverityWarn,
+				Message:  fmt.Sprintf("replication channel %q reports error %d: %s", c.Name, c.LastErrorNumber, c.LastError),
+				Meta:     map[string]interface{}{"replica": replica, "channel": c.Name, "error_number": c.LastErrorNumber},
+			})
+		}
 	}
 
 	return findings
@@ -203,6 +473,27 @@ func appendBlock(summary Summary, findings []Finding, message string) (Summary,
 	return summary, findings, nil
 }
 
+// appendFailure records err as a BLOCK. Typed failures carry their class
+// under "cause" (config, inspector or action) and the affected host under
+// "target", so callers can tell misconfiguration from a failing server.
+func appendFailure(summary Summary, findings []Finding, err error) (Summary, []Finding, error) {
+	block := failureFinding(err)
+	findings = append(findings, block)
+	applySummary(&summary, []Finding{block})
+	return summary, findings, nil
+}
+
+func failureFinding(err error) Finding {
+	f := Finding{Severity: SeverityBlock, Message: err.Error()}
+	if kind := failure.KindOf(err); kind != "" {
+		f.Meta = map[string]interface{}{"cause": string(kind)}
+		if target := failure.Target(err); target != "" {
+			f.Meta["target"] = target
+		}
+	}
+	return f
+}
+
 func applySummary(summary *Summary, findings []Finding) {
 	for _, f := range findings {
 		switch f.Severity {
@@ -216,10 +507,48 @@ func applySummary(summary *Summary, findings []Finding) {
 	}
 }
 
+// Started reports whether replica was drained or stopped for an
+// upgrade that has not resumed yet.
+func (o *UpgradeOrchestrator) Started(replica string) bool {
+	if resumed, _ := getBool(o.State, resumedKey(replica)); resumed {
+		return false
+	}
+	if stopped, _ := getBool(o.State, stoppedKey(replica)); stopped {
+		return true
+	}
+	return o.drained(replica)
+}
+
+// Upgraded reports whether replica's upgrade ran and replication resumed.
+func (o *UpgradeOrchestrator) Upgraded(replica string) bool {
+	upgraded, _ := getBool(o.State, upgradedKey(replica))
+	resumed, _ := getBool(o.State, resumedKey(replica))
+	return upgraded && resumed
+}
+
+// inService reports whether replica serves reads according to the
+// checkpoints: not mid-upgrade and not drained awaiting undrain.
+func (o *UpgradeOrchestrator) inService(replica string) bool {
+	return !o.Started(replica) && !o.drained(replica)
+}
+
+func (o *UpgradeOrchestrator) drained(replica string) bool {
+	for _, d := range o.Drainers {
+		if drained, _ := getBool(o.State, drainedKey(replica, d.Name())); drained {
+			return true
+		}
+	}
+	return false
+}
+
 func stoppedKey(replica string) string  { return fmt.Sprintf("replica_upgrade:%s:stopped", replica) }
 func upgradedKey(replica string) string { return fmt.Sprintf("replica_upgrade:%s:upgraded", replica) }
 func resumedKey(replica string) string  { return fmt.Sprintf("replica_upgrade:%s:resumed", replica) }
 
+func drainedKey(replica string, drainer string) string {
+	return fmt.Sprintf("replica_upgrade:%s:drained:%s", replica, drainer)
+}
+
 func getBool(state workflow.State, key string) (bool, bool) {
 	if state == nil {
 		return false, false
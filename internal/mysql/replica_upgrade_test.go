@@ -3,7 +3,11 @@ package mysql
 import (
 	"context"
 	"errors"
+	"io"
+	"log"
+	"strings"
 	"testing"
+	"time"
 
 	"migratorx/internal/workflow"
 )
@@ -128,6 +132,33 @@ func TestUpgradeOrchestrator_BlockStopsFlow(t *testing.T) {
 	}
 }
 
+func TestUpgradeOrchestrator_RefusesToStartBelowReadCapacity(t *testing.T) {
+	inspector := &fakeInspector{status: ReplicationStatus{IOThreadRunning: true, SQLThreadRunning: true}}
+	actions := &fakeActions{}
+	state := workflow.NewMemoryState()
+	state.Set("replica_upgrade:replica-2:stopped", true)
+
+	o := NewUpgradeOrchestrator(inspector, actions, state, "mysql-primary", nil)
+	o.Capacity = &ReadCapacity{MinCapacity: 2}
+	o.Pool = []string{"replica-1", "replica-2", "replica-3"}
+	summary, findings, err := o.Run(context.Background(), "replica-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Block != 1 || actions.stopCalls != 0 {
+		t.Fatalf("expected the upgrade to be refused before stopping replication, got %+v", findings)
+	}
+	if findings[len(findings)-1].Meta["read_capacity"] != 1.0 {
+		t.Fatalf("expected only replica-3 to count as serving, got %v", findings[len(findings)-1].Meta)
+	}
+
+	state.Set("replica_upgrade:replica-2:resumed", true)
+	summary, _, err = o.Run(context.Background(), "replica-1")
+	if err != nil || summary.Block != 0 || actions.stopCalls != 1 {
+		t.Fatalf("expected the upgrade to start once replica-2 resumed, got %+v %v", summary, err)
+	}
+}
+
 func TestUpgradeOrchestrator_InspectorErrorBlocks(t *testing.T) {
 	inspector := &fakeInspector{err: errors.New("status failure")}
 	actions := &fakeActions{}
@@ -145,3 +176,227 @@ func TestUpgradeOrchestrator_InspectorErrorBlocks(t *testing.T) {
 		t.Fatalf("expected BLOCK finding")
 	}
 }
+
+type fakeDrainer struct {
+	name     string
+	drains   int
+	undrains int
+	err      error
+	log      *[]string
+}
+
+func (f *fakeDrainer) Name() string { return f.name }
+
+func (f *fakeDrainer) Drain(ctx context.Context, replica string) error {
+	f.drains++
+	*f.log = append(*f.log, "drain:"+f.name)
+	return f.err
+}
+
+func (f *fakeDrainer) Undrain(ctx context.Context, replica string) error {
+	f.undrains++
+	*f.log = append(*f.log, "undrain:"+f.name)
+	return nil
+}
+
+func TestUpgradeOrchestrator_DrainsBeforeStopAndUndrainsInReverse(t *testing.T) {
+	var calls []string
+	proxy := &fakeDrainer{name: "proxysql", log: &calls}
+	k8s := &fakeDrainer{name: "k8s", log: &calls}
+	inspector := &fakeInspector{status: ReplicationStatus{IOThreadRunning: true, SQLThreadRunning: true}}
+	actions := &fakeActions{}
+	state := workflow.NewMemoryState()
+
+	o := NewUpgradeOrchestrator(inspector, actions, state, "mysql-primary", nil)
+	o.Drainers = []Drainer{proxy, k8s}
+	if summary, _, _ := o.Run(context.Background(), "mysql-replica-1"); summary.Block != 0 {
+		t.Fatalf("unexpected BLOCK: %+v", summary)
+	}
+
+	// Simulate a crash and re-run: drained checkpoints prevent re-draining.
+	inspector.status = ReplicationStatus{IOThreadRunning: true, SQLThreadRunning: true}
+	o.Run(context.Background(), "mysql-replica-1")
+	if proxy.drains != 1 || k8s.drains != 1 {
+		t.Fatalf("expected each drainer to run once, got proxysql=%d k8s=%d", proxy.drains, k8s.drains)
+	}
+
+	if summary, _, _ := o.Undrain(context.Background(), "mysql-replica-1"); summary.Block != 0 {
+		t.Fatalf("unexpected BLOCK on undrain: %+v", summary)
+	}
+	o.Undrain(context.Background(), "mysql-replica-1")
+	want := []string{"drain:proxysql", "drain:k8s", "undrain:k8s", "undrain:proxysql"}
+	if len(calls) != len(want) {
+		t.Fatalf("expected calls %v, got %v", want, calls)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Fatalf("expected calls %v, got %v", want, calls)
+		}
+	}
+}
+
+func TestUpgradeOrchestrator_DrainFailureBlocksBeforeStop(t *testing.T) {
+	var calls []string
+	drainer := &fakeDrainer{name: "haproxy", err: errors.New("socket refused"), log: &calls}
+	actions := &fakeActions{}
+	o := NewUpgradeOrchestrator(&fakeInspector{status: ReplicationStatus{IOThreadRunning: true, SQLThreadRunning: true}}, actions, workflow.NewMemoryState(), "mysql-primary", nil)
+	o.Drainers = []Drainer{drainer}
+
+	summary, _, _ := o.Run(context.Background(), "mysql-replica-1")
+	if summary.Block != 1 || actions.stopCalls != 0 {
+		t.Fatalf("expected BLOCK before stopping replication, got %+v (stop calls %d)", summary, actions.stopCalls)
+	}
+}
+
+// sequenceInspector reports statuses in order; the last one repeats.
+type sequenceInspector struct {
+	statuses []ReplicationStatus
+	reads    int
+}
+
+func (s *sequenceInspector) IsPrimary(ctx context.Context, host string) (bool, error) {
+	return false, nil
+}
+
+func (s *sequenceInspector) ReplicationStatus(ctx context.Context, replica string) (ReplicationStatus, error) {
+	i := s.reads
+	if i >= len(s.statuses) {
+		i = len(s.statuses) - 1
+	}
+	s.reads++
+	return s.statuses[i], nil
+}
+
+func laggingStatus(lag time.Duration) ReplicationStatus {
+	return ReplicationStatus{IOThreadRunning: true, SQLThreadRunning: true, Channels: []ChannelStatus{{ApplierLag: lag, LagKnown: true}}}
+}
+
+func TestUpgradeOrchestrator_WaitsForReplicationToCatchUp(t *testing.T) {
+	inspector := &sequenceInspector{statuses: []ReplicationStatus{
+		{IOThreadRunning: true, SQLThreadRunning: true},
+		{},
+		{IOThreadRunning: true},
+		laggingStatus(time.Minute),
+		laggingStatus(40 * time.Second),
+	}}
+	state := workflow.NewMemoryState()
+	o := NewUpgradeOrchestrator(inspector, &fakeActions{}, state, "mysql-primary", log.New(io.Discard, "", 0))
+	o.ResumePollInterval = time.Millisecond
+
+	summary, findings, err := o.Run(context.Background(), "replica-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Block != 0 || summary.Warn != 0 {
+		t.Fatalf("expected replication to resume, got %+v", findings)
+	}
+	var progress []string
+	for _, f := range findings {
+		if strings.HasPrefix(f.Message, "waiting for") {
+			progress = append(progress, f.Message)
+		}
+	}
+	if len(progress) != 3 || !strings.Contains(progress[2], "1m0s behind") {
+		t.Fatalf("expected a progress finding per state change, got %v", progress)
+	}
+	if last := findings[len(findings)-1]; last.Message != "replication started" || last.Meta["lag"] != "40s" {
+		t.Fatalf("expected replication to start once lag trends down, got %+v", last)
+	}
+	if ok, _ := getBool(state, resumedKey("replica-1")); !ok {
+		t.Fatalf("expected the resume to be checkpointed")
+	}
+}
+
+func TestUpgradeOrchestrator_BlocksWhenReplicationDoesNotResume(t *testing.T) {
+	inspector := &sequenceInspector{statuses: []ReplicationStatus{
+		{IOThreadRunning: true, SQLThreadRunning: true},
+		{IOThreadRunning: true, Channels: []ChannelStatus{{Name: "", LastErrorNumber: 1062, LastError: "Duplicate entry"}}},
+	}}
+	state := workflow.NewMemoryState()
+	o := NewUpgradeOrchestrator(inspector, &fakeActions{}, state, "mysql-primary", log.New(io.Discard, "", 0))
+	o.ResumePollInterval = time.Millisecond
+	o.ResumeTimeout = 5 * time.Millisecond
+
+	summary, findings, _ := o.Run(context.Background(), "replica-1")
+	last := findings[len(findings)-1]
+	if summary.Block != 1 || last.Meta["cause"] != "action" {
+		t.Fatalf("expected stopped threads to block, got %+v", findings)
+	}
+	if !strings.Contains(findings[len(findings)-2].Message, "error 1062") {
+		t.Fatalf("expected the channel error in the progress finding, got %+v", findings[len(findings)-2])
+	}
+	if ok, _ := getBool(state, resumedKey("replica-1")); ok {
+		t.Fatalf("expected the resume not to be checkpointed")
+	}
+}
+
+func TestUpgradeOrchestrator_WarnsWhenLagKeepsGrowing(t *testing.T) {
+	inspector := &sequenceInspector{statuses: []ReplicationStatus{
+		{IOThreadRunning: true, SQLThreadRunning: true},
+		laggingStatus(time.Minute),
+		laggingStatus(2 * time.Minute),
+	}}
+	state := workflow.NewMemoryState()
+	o := NewUpgradeOrchestrator(inspector, &fakeActions{}, state, "mysql-primary", log.New(io.Discard, "", 0))
+	o.ResumePollInterval = time.Millisecond
+	o.ResumeTimeout = 5 * time.Millisecond
+
+	summary, _, _ := o.Run(context.Background(), "replica-1")
+	if summary.Block != 0 || summary.Warn != 1 {
+		t.Fatalf("expected growing lag to warn, got %+v", summary)
+	}
+	if ok, _ := getBool(state, resumedKey("replica-1")); !ok {
+		t.Fatalf("expected running replication to be checkpointed")
+	}
+}
+
+func TestDetectPartialProgress_ReconcilesCheckpoints(t *testing.T) {
+	running := ReplicationStatus{IOThreadRunning: true, SQLThreadRunning: true}
+	cases := []struct {
+		name   string
+		status ReplicationStatus
+		seed   map[string]bool
+		key    string
+		want   bool
+	}{
+		{"stopped without checkpoint", ReplicationStatus{}, nil, stoppedKey("r1"), true},
+		{"restarted before upgrade", running, map[string]bool{stoppedKey("r1"): true}, stoppedKey("r1"), false},
+		{"started after upgrade", running, map[string]bool{stoppedKey("r1"): true, upgradedKey("r1"): true}, resumedKey("r1"), true},
+		{"stopped after resume", ReplicationStatus{}, map[string]bool{stoppedKey("r1"): true, upgradedKey("r1"): true, resumedKey("r1"): true}, resumedKey("r1"), false},
+	}
+	for _, c := range cases {
+		state := workflow.NewMemoryState()
+		for key, value := range c.seed {
+			state.Set(key, value)
+		}
+		findings := detectPartialProgress("r1", c.status, state, true)
+		if len(findings) != 1 || findings[0].Severity != SeverityInfo || findings[0].Meta["reconciled"] != c.key {
+			t.Fatalf("%s: expected %s to be reconciled, got %+v", c.name, c.key, findings)
+		}
+		if got, _ := getBool(state, c.key); got != c.want {
+			t.Fatalf("%s: expected %s to be %t, got %t", c.name, c.key, c.want, got)
+		}
+	}
+}
+
+func TestUpgradeOrchestrator_ReconcileRestartsUndoneResume(t *testing.T) {
+	inspector := &sequenceInspector{statuses: []ReplicationStatus{{}, {IOThreadRunning: true, SQLThreadRunning: true}}}
+	actions := &fakeActions{}
+	state := workflow.NewMemoryState()
+	state.Set(stoppedKey("replica-1"), true)
+	state.Set(upgradedKey("replica-1"), true)
+	state.Set(resumedKey("replica-1"), true)
+	o := NewUpgradeOrchestrator(inspector, actions, state, "mysql-primary", log.New(io.Discard, "", 0))
+	o.Reconcile = true
+
+	summary, findings, err := o.Run(context.Background(), "replica-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Warn != 0 || !strings.HasPrefix(findings[0].Message, "reconciled:") {
+		t.Fatalf("expected the mismatch to be reconciled, got %+v", findings)
+	}
+	if actions.stopCalls != 0 || actions.upgradeCalls != 0 || actions.startCalls != 1 {
+		t.Fatalf("expected only replication to be started again, got %+v", actions)
+	}
+}
@@ -0,0 +1,280 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"migratorx/internal/checks"
+)
+
+// DefaultCertExpiryWarning is how far ahead ReplicationTLSCheck warns about an
+// expiring server certificate.
+const DefaultCertExpiryWarning = 30 * 24 * time.Hour
+
+// modernTLSVersions are the protocols MySQL 8.0.28+ still accepts; TLSv1 and
+// TLSv1.1 were removed.
+var modernTLSVersions = []string{"TLSv1.2", "TLSv1.3"}
+
+// ChannelTLS is the TLS configuration of one replication channel. SSLAllowed
+// is YES, NO or IGNORED as in performance_schema; an empty TLSVersion means the
+// server default.
+type ChannelTLS struct {
+	Channel          string
+	SSLAllowed       string
+	VerifyServerCert bool
+	TLSVersion       string
+	Cipher           string
+}
+
+// ServerTLS is a server's TLS setup. CertNotAfter is zero when the server has
+// no certificate loaded.
+type ServerTLS struct {
+	TLSVersion   string
+	CertNotAfter time.Time
+}
+
+// ReplicationTLSInspector reads TLS settings of replication channels and servers.
+type ReplicationTLSInspector interface {
+	ChannelTLS(ctx context.Context, replica string) ([]ChannelTLS, error)
+	ServerTLS(ctx context.Context, host string) (ServerTLS, error)
+}
+
+// ReplicationTLSCheck verifies replication channels on the upgraded replica
+// use TLS when RequireTLS is set, and that the TLS versions and certificate
+// still work on 8.0, which dropped TLSv1/TLSv1.1 and is stricter by default.
+type ReplicationTLSCheck struct {
+	Inspector         ReplicationTLSInspector
+	Replica           string
+	RequireTLS        bool
+	CertExpiryWarning time.Duration
+	Now               func() time.Time
+}
+
+func (c *ReplicationTLSCheck) Name() string   { return "replication_tls" }
+func (c *ReplicationTLSCheck) ReadOnly() bool { return true }
+
+func (c *ReplicationTLSCheck) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"replica":     c.Replica,
+		"require_tls": c.RequireTLS,
+	}
+}
+
+func (c *ReplicationTLSCheck) Run(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+	if c.Inspector == nil {
+		return nil, fmt.Errorf("replication TLS inspector is required")
+	}
+	replica := strings.TrimSpace(c.Replica)
+	if replica == "" {
+		replica = input.ReplicaHost
+	}
+	if replica == "" {
+		return nil, fmt.Errorf("replica is required")
+	}
+	if c.CertExpiryWarning <= 0 {
+		c.CertExpiryWarning = DefaultCertExpiryWarning
+	}
+	now := time.Now
+	if c.Now != nil {
+		now = c.Now
+	}
+
+	channels, err := c.Inspector.ChannelTLS(ctx, replica)
+	if err != nil {
+		return c.unknown(replica, err), nil
+	}
+	server, err := c.Inspector.ServerTLS(ctx, replica)
+	if err != nil {
+		return c.unknown(replica, err), nil
+	}
+
+	findings := []checks.Finding{}
+	for _, ch := range channels {
+		meta := map[string]interface{}{"replica": replica, "channel": ch.Channel, "ssl_allowed": ch.SSLAllowed, "tls_version": ch.TLSVersion}
+		if !strings.EqualFold(ch.SSLAllowed, "YES") {
+			if c.RequireTLS {
+				findings = append(findings, checks.Finding{
+					Severity: checks.SeverityBlock,
+					Code:     CodeReplicationTLSDisabled,
+					Message:  fmt.Sprintf("replication channel %q on %q does not use TLS (SSL_ALLOWED=%s); the plan requires it", ch.Channel, replica, ch.SSLAllowed),
+					Meta:     meta,
+				})
+			}
+			continue
+		}
+		if c.RequireTLS && !ch.VerifyServerCert {
+			findings = append(findings, checks.Finding{
+				Severity: checks.SeverityWarn,
+				Code:     CodeReplicationTLSUnverified,
+				Message:  fmt.Sprintf("replication channel %q on %q does not verify the source certificate (SOURCE_SSL_VERIFY_SERVER_CERT=0)", ch.Channel, replica),
+				Meta:     meta,
+			})
+		}
+		findings = append(findings, tlsVersionFindings(fmt.Sprintf("replication channel %q on %q", ch.Channel, replica), ch.TLSVersion, meta)...)
+	}
+
+	serverMeta := map[string]interface{}{"replica": replica, "tls_version": server.TLSVersion}
+	findings = append(findings, tlsVersionFindings(fmt.Sprintf("server %q", replica), server.TLSVersion, serverMeta)...)
+	if !server.CertNotAfter.IsZero() {
+		serverMeta["cert_not_after"] = server.CertNotAfter.UTC().Format(time.RFC3339)
+		remaining := server.CertNotAfter.Sub(now())
+		switch {
+		case remaining <= 0:
+			findings = append(findings, checks.Finding{
+				Severity: checks.SeverityBlock,
+				Code:     CodeReplicationTLSCertExpired,
+				Message:  fmt.Sprintf("TLS certificate on %q expired at %s", replica, server.CertNotAfter.UTC().Format(time.RFC3339)),
+				Meta:     serverMeta,
+			})
+		case remaining < c.CertExpiryWarning:
+			findings = append(findings, checks.Finding{
+				Severity: checks.SeverityWarn,
+				Code:     CodeReplicationTLSCertExpiring,
+				Message:  fmt.Sprintf("TLS certificate on %q expires in %s", replica, remaining.Round(time.Hour)),
+				Meta:     serverMeta,
+			})
+		}
+	} else if c.RequireTLS {
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityBlock,
+			Code:     CodeReplicationTLSDisabled,
+			Message:  fmt.Sprintf("server %q has no TLS certificate loaded; the plan requires TLS", replica),
+			Meta:     serverMeta,
+		})
+	}
+
+	if len(findings) == 0 {
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityInfo,
+			Code:     CodeReplicationTLSOK,
+			Message:  fmt.Sprintf("replication TLS on %q is compatible with 8.0 (%d channels)", replica, len(channels)),
+			Meta:     map[string]interface{}{"replica": replica, "channels": len(channels), "require_tls": c.RequireTLS},
+		})
+	}
+	return findings, nil
+}
+
+func (c *ReplicationTLSCheck) unknown(replica string, err error) []checks.Finding {
+	severity := checks.SeverityWarn
+	if c.RequireTLS {
+		severity = checks.SeverityBlock
+	}
+	return []checks.Finding{{
+		Severity: severity,
+		Code:     CodeReplicationTLSUnknown,
+		Message:  fmt.Sprintf("unable to read replication TLS settings on %q: %v", replica, err),
+		Meta:     map[string]interface{}{"replica": replica},
+	}}
+}
+
+// tlsVersionFindings reports a tls_version list that 8.0 no longer accepts.
+// An empty list uses the server default and is fine.
+func tlsVersionFindings(subject string, versions string, meta map[string]interface{}) []checks.Finding {
+	if strings.TrimSpace(versions) == "" {
+		return nil
+	}
+	modern, legacy := []string{}, []string{}
+	for _, v := range strings.Split(versions, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if containsFold(modernTLSVersions, v) {
+			modern = append(modern, v)
+		} else {
+			legacy = append(legacy, v)
+		}
+	}
+	switch {
+	case len(modern) == 0:
+		return []checks.Finding{{
+			Severity: checks.SeverityBlock,
+			Code:     CodeReplicationTLSVersionUnsupported,
+			Message:  fmt.Sprintf("%s only allows %s; MySQL 8.0.28+ supports TLSv1.2 and TLSv1.3 only", subject, versions),
+			Meta:     meta,
+		}}
+	case len(legacy) > 0:
+		return []checks.Finding{{
+			Severity: checks.SeverityWarn,
+			Code:     CodeReplicationTLSLegacyVersion,
+			Message:  fmt.Sprintf("%s still lists %s, which 8.0 ignores", subject, strings.Join(legacy, ",")),
+			Meta:     meta,
+		}}
+	}
+	return nil
+}
+
+func containsFold(values []string, v string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(candidate, v) {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	psChannelTLSQuery = `SELECT CHANNEL_NAME, SSL_ALLOWED, SSL_VERIFY_SERVER_CERTIFICATE, TLS_VERSION, SSL_CIPHER
+FROM performance_schema.replication_connection_configuration
+ORDER BY CHANNEL_NAME`
+
+	tlsVersionQuery   = `SELECT @@GLOBAL.tls_version`
+	certNotAfterQuery = `SHOW GLOBAL STATUS LIKE 'Ssl_server_not_after'`
+
+	// opensslTimeLayout is how MySQL reports certificate validity dates.
+	opensslTimeLayout = "Jan _2 15:04:05 2006 MST"
+)
+
+// ChannelTLS reads the TLS settings of every replication channel on replica.
+func (i *PerformanceSchemaInspector) ChannelTLS(ctx context.Context, replica string) ([]ChannelTLS, error) {
+	q, err := i.connect(ctx, replica)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := q.QueryContext(ctx, psChannelTLSQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read channel TLS settings: %w", err)
+	}
+	defer rows.Close()
+	channels := []ChannelTLS{}
+	for rows.Next() {
+		var ch ChannelTLS
+		var verify string
+		var version, cipher sql.NullString
+		if err := rows.Scan(&ch.Channel, &ch.SSLAllowed, &verify, &version, &cipher); err != nil {
+			return nil, err
+		}
+		ch.VerifyServerCert = strings.EqualFold(verify, "YES")
+		ch.TLSVersion = version.String
+		ch.Cipher = cipher.String
+		channels = append(channels, ch)
+	}
+	return channels, rows.Err()
+}
+
+// ServerTLS reads tls_version and the expiry of the server certificate.
+func (i *PerformanceSchemaInspector) ServerTLS(ctx context.Context, host string) (ServerTLS, error) {
+	q, err := i.connect(ctx, host)
+	if err != nil {
+		return ServerTLS{}, err
+	}
+	var server ServerTLS
+	if err := queryOne(ctx, q, tlsVersionQuery, nil, &server.TLSVersion); err != nil {
+		return ServerTLS{}, fmt.Errorf("failed to read tls_version: %w", err)
+	}
+	var name, notAfter string
+	if err := queryOne(ctx, q, certNotAfterQuery, nil, &name, &notAfter); err != nil {
+		return ServerTLS{}, fmt.Errorf("failed to read Ssl_server_not_after: %w", err)
+	}
+	if notAfter = strings.TrimSpace(notAfter); notAfter != "" {
+		t, err := time.Parse(opensslTimeLayout, notAfter)
+		if err != nil {
+			return ServerTLS{}, fmt.Errorf("unexpected Ssl_server_not_after %q: %w", notAfter, err)
+		}
+		server.CertNotAfter = t
+	}
+	return server, nil
+}
@@ -0,0 +1,106 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"migratorx/internal/checks"
+)
+
+type fakeTLSInspector struct {
+	channels []ChannelTLS
+	server   ServerTLS
+}
+
+func (f *fakeTLSInspector) ChannelTLS(ctx context.Context, replica string) ([]ChannelTLS, error) {
+	return f.channels, nil
+}
+
+func (f *fakeTLSInspector) ServerTLS(ctx context.Context, host string) (ServerTLS, error) {
+	return f.server, nil
+}
+
+func tlsFindingCodes(findings []checks.Finding) map[string]checks.Severity {
+	codes := map[string]checks.Severity{}
+	for _, f := range findings {
+		codes[f.Code] = f.Severity
+	}
+	return codes
+}
+
+func TestReplicationTLSCheck_CompatibleChannelPasses(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	inspector := &fakeTLSInspector{
+		channels: []ChannelTLS{{Channel: "", SSLAllowed: "YES", VerifyServerCert: true, TLSVersion: "TLSv1.2,TLSv1.3"}},
+		server:   ServerTLS{TLSVersion: "TLSv1.2,TLSv1.3", CertNotAfter: now.Add(365 * 24 * time.Hour)},
+	}
+	check := &ReplicationTLSCheck{Inspector: inspector, RequireTLS: true, Now: func() time.Time { return now }}
+	findings, err := check.Run(context.Background(), checks.Input{ReplicaHost: "mysql-replica-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Code != CodeReplicationTLSOK {
+		t.Fatalf("expected REPLICATION_TLS_OK, got %+v", findings)
+	}
+}
+
+func TestReplicationTLSCheck_ReportsIncompatibilities(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	inspector := &fakeTLSInspector{
+		channels: []ChannelTLS{
+			{Channel: "a", SSLAllowed: "NO"},
+			{Channel: "b", SSLAllowed: "YES", TLSVersion: "TLSv1,TLSv1.1"},
+		},
+		server: ServerTLS{TLSVersion: "TLSv1.1,TLSv1.2", CertNotAfter: now.Add(-time.Hour)},
+	}
+	check := &ReplicationTLSCheck{Inspector: inspector, Replica: "mysql-replica-1", RequireTLS: true, Now: func() time.Time { return now }}
+	findings, err := check.Run(context.Background(), checks.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	codes := tlsFindingCodes(findings)
+	expected := map[string]checks.Severity{
+		CodeReplicationTLSDisabled:           checks.SeverityBlock,
+		CodeReplicationTLSUnverified:         checks.SeverityWarn,
+		CodeReplicationTLSVersionUnsupported: checks.SeverityBlock,
+		CodeReplicationTLSLegacyVersion:      checks.SeverityWarn,
+		CodeReplicationTLSCertExpired:        checks.SeverityBlock,
+	}
+	for code, severity := range expected {
+		if codes[code] != severity {
+			t.Fatalf("expected %s with %s, got %+v", code, severity, findings)
+		}
+	}
+}
+
+func TestReplicationTLSCheck_PlainChannelAllowedWhenNotRequired(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	inspector := &fakeTLSInspector{
+		channels: []ChannelTLS{{Channel: "", SSLAllowed: "NO"}},
+		server:   ServerTLS{CertNotAfter: now.Add(10 * 24 * time.Hour)},
+	}
+	check := &ReplicationTLSCheck{Inspector: inspector, Replica: "mysql-replica-1", Now: func() time.Time { return now }}
+	findings, _ := check.Run(context.Background(), checks.Input{})
+	if len(findings) != 1 || findings[0].Code != CodeReplicationTLSCertExpiring {
+		t.Fatalf("expected only the expiring certificate warning, got %+v", findings)
+	}
+}
+
+func TestPerformanceSchemaInspector_ReadsTLSSettings(t *testing.T) {
+	db := openFakeDB(t,
+		fakeResponse{match: "SSL_ALLOWED", columns: []string{"CHANNEL_NAME", "SSL_ALLOWED", "SSL_VERIFY_SERVER_CERTIFICATE", "TLS_VERSION", "SSL_CIPHER"}, rows: [][]driver.Value{{"", "YES", "NO", "TLSv1.2", nil}}},
+		fakeResponse{match: "tls_version", columns: []string{"tls_version"}, rows: [][]driver.Value{{"TLSv1.2,TLSv1.3"}}},
+		fakeResponse{match: "Ssl_server_not_after", columns: []string{"Variable_name", "Value"}, rows: [][]driver.Value{{"Ssl_server_not_after", "Apr  3 12:00:00 2031 GMT"}}},
+	)
+	inspector := &PerformanceSchemaInspector{Connect: fakeConnector(db)}
+	channels, err := inspector.ChannelTLS(context.Background(), "mysql-replica-1")
+	if err != nil || len(channels) != 1 || channels[0].SSLAllowed != "YES" || channels[0].VerifyServerCert || channels[0].TLSVersion != "TLSv1.2" {
+		t.Fatalf("unexpected channels %+v (%v)", channels, err)
+	}
+	server, err := inspector.ServerTLS(context.Background(), "mysql-replica-1")
+	if err != nil || server.TLSVersion != "TLSv1.2,TLSv1.3" || server.CertNotAfter.Year() != 2031 || server.CertNotAfter.Day() != 3 {
+		t.Fatalf("unexpected server TLS %+v (%v)", server, err)
+	}
+}
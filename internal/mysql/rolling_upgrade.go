@@ -0,0 +1,250 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"migratorx/internal/failure"
+)
+
+// UpgradeGuard decides whether the topology can spare another replica for an
+// upgrade. Thresholds left at zero are not enforced. Load, when set, is read
+// for the primary's Threads_running. ReplicaMaxLag overrides MaxLag for
+// individual replicas, e.g. cross-region ones. Capacity, when set, holds
+// upgrades while the healthy serving replicas fall short of the read pool
+// floor during peak traffic.
+type UpgradeGuard struct {
+	Inspector          ReplicaInspector
+	Load               TrafficInspector
+	Primary            string
+	MaxLag             time.Duration
+	ReplicaMaxLag      map[string]time.Duration
+	MaxThreadsRunning  int
+	MinServingReplicas int
+	Capacity           *ReadCapacity
+}
+
+// Degraded returns why another upgrade must wait, given the replicas expected
+// to keep serving reads while it runs. A replica whose status cannot be read
+// counts as degraded so upgrades never continue blind.
+func (g *UpgradeGuard) Degraded(ctx context.Context, serving []string) ([]string, map[string]interface{}) {
+	reasons := []string{}
+	meta := map[string]interface{}{}
+	if g.Load != nil && g.MaxThreadsRunning > 0 && g.Primary != "" {
+		running, err := g.Load.StatusCounter(ctx, g.Primary, "Threads_running")
+		switch {
+		case err != nil:
+			reasons = append(reasons, fmt.Sprintf("unable to read load on primary %s: %v", g.Primary, err))
+		case running > int64(g.MaxThreadsRunning):
+			reasons = append(reasons, fmt.Sprintf("primary %s has %d threads running (limit %d)", g.Primary, running, g.MaxThreadsRunning))
+		}
+		meta["primary_threads_running"] = running
+	}
+
+	healthy := []string{}
+	lagging := []string{}
+	for _, replica := range serving {
+		maxLag := g.MaxLag
+		if limit, ok := g.ReplicaMaxLag[replica]; ok {
+			maxLag = limit
+		}
+		if g.Inspector == nil || maxLag <= 0 {
+			healthy = append(healthy, replica)
+			continue
+		}
+		status, err := g.Inspector.ReplicationStatus(ctx, replica)
+		if err != nil {
+			reasons = append(reasons, fmt.Sprintf("unable to read replication status on %s: %v", replica, err))
+			continue
+		}
+		if lag, known := status.MaxApplierLag(); known && lag > maxLag {
+			lagging = append(lagging, replica)
+			reasons = append(reasons, fmt.Sprintf("replica %s lags %s (limit %s)", replica, lag, maxLag))
+			continue
+		}
+		healthy = append(healthy, replica)
+	}
+	if len(lagging) > 0 {
+		meta["lagging"] = lagging
+	}
+	meta["serving_replicas"] = len(healthy)
+	if g.MinServingReplicas > 0 && len(healthy) < g.MinServingReplicas {
+		reasons = append(reasons, fmt.Sprintf("only %d healthy replicas would serve reads (minimum %d)", len(healthy), g.MinServingReplicas))
+	}
+	if g.Capacity != nil {
+		capacity, reason := g.Capacity.Shortfall(healthy)
+		meta["read_capacity"] = capacity
+		if reason != "" {
+			reasons = append(reasons, reason)
+		}
+	}
+	return reasons, meta
+}
+
+// RollingUpgrade upgrades several replicas, up to Concurrency at a time
+// (default 1). Before each upgrade starts, Guard is consulted with the
+// replicas that keep serving reads; while it reports degradation no further
+// upgrades start, and in-flight ones are left to finish. A pause longer than
+// MaxPause (when set) or a BLOCK from any replica stops new upgrades with a
+// BLOCK. Replicas already upgraded in an earlier run skip the guard.
+type RollingUpgrade struct {
+	Orchestrator *UpgradeOrchestrator
+	Guard        UpgradeGuard
+	Concurrency  int
+	PollInterval time.Duration
+	MaxPause     time.Duration
+}
+
+// Run upgrades replicas in order and returns the combined findings.
+func (r *RollingUpgrade) Run(ctx context.Context, replicas []string) (Summary, []Finding, error) {
+	if r.Orchestrator == nil {
+		return Summary{Block: 1}, []Finding{failureFinding(failure.Config("upgrade orchestrator is required"))}, nil
+	}
+	concurrency := r.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	poll := r.PollInterval
+	if poll <= 0 {
+		poll = DefaultLagPollInterval
+	}
+	logger := r.Orchestrator.Logger
+	if logger == nil {
+		logger = log.Default()
+	}
+	trimmed := make([]string, 0, len(replicas))
+	for _, replica := range replicas {
+		trimmed = append(trimmed, strings.TrimSpace(replica))
+	}
+	replicas = trimmed
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		summary   Summary
+		findings  = []Finding{}
+		started   = map[string]bool{}
+		inFlight  = map[string]bool{}
+		completed = map[string]bool{}
+		stopped   bool
+	)
+	add := func(batch []Finding) {
+		findings = append(findings, batch...)
+		applySummary(&summary, batch)
+	}
+	serving := func(candidate string) []string {
+		out := []string{}
+		for _, replica := range replicas {
+			if replica == candidate || inFlight[replica] {
+				continue
+			}
+			// Upgraded replicas stay drained until undrained after validation.
+			if completed[replica] && len(r.Orchestrator.Drainers) > 0 {
+				continue
+			}
+			out = append(out, replica)
+		}
+		return out
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var ctxErr error
+launch:
+	for _, replica := range replicas {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			ctxErr = ctx.Err()
+			break launch
+		}
+
+		resumed, _ := getBool(r.Orchestrator.State, resumedKey(replica))
+		pausedAt := time.Time{}
+		for !resumed {
+			mu.Lock()
+			if stopped {
+				mu.Unlock()
+				<-sem
+				break launch
+			}
+			candidates := serving(replica)
+			mu.Unlock()
+
+			reasons, meta := r.Guard.Degraded(ctx, candidates)
+			if len(reasons) == 0 {
+				if !pausedAt.IsZero() {
+					mu.Lock()
+					add([]Finding{{Severity: SeverityInfo, Message: "topology recovered; resuming upgrades", Meta: map[string]interface{}{"replica": replica, "paused": time.Since(pausedAt).Round(time.Second).String()}}})
+					mu.Unlock()
+				}
+				break
+			}
+			meta["replica"] = replica
+			meta["reasons"] = reasons
+			mu.Lock()
+			if pausedAt.IsZero() {
+				pausedAt = time.Now()
+				logger.Printf("pausing upgrades before %s: %s", replica, strings.Join(reasons, "; "))
+				add([]Finding{{Severity: SeverityInfo, Message: fmt.Sprintf("upgrades paused before %s: %s", replica, strings.Join(reasons, "; ")), Meta: meta}})
+			} else if r.MaxPause > 0 && time.Since(pausedAt) >= r.MaxPause {
+				meta["max_pause"] = r.MaxPause.String()
+				add([]Finding{{Severity: SeverityBlock, Message: fmt.Sprintf("upgrades paused longer than %s before %s: %s", r.MaxPause, replica, strings.Join(reasons, "; ")), Meta: meta}})
+				stopped = true
+			}
+			mu.Unlock()
+			if err := sleepContext(ctx, poll); err != nil {
+				ctxErr = err
+				<-sem
+				break launch
+			}
+		}
+
+		mu.Lock()
+		if stopped {
+			mu.Unlock()
+			<-sem
+			break
+		}
+		started[replica] = true
+		inFlight[replica] = true
+		mu.Unlock()
+
+		wg.Add(1)
+		go func(replica string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			_, replicaFindings, err := r.Orchestrator.Run(ctx, replica)
+			if err != nil {
+				replicaFindings = append(replicaFindings, failureFinding(err))
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			delete(inFlight, replica)
+			add(replicaFindings)
+			if hasBlock(replicaFindings) {
+				stopped = true
+				return
+			}
+			completed[replica] = true
+		}(replica)
+	}
+	wg.Wait()
+
+	notStarted := []string{}
+	for _, replica := range replicas {
+		if !started[replica] {
+			notStarted = append(notStarted, replica)
+		}
+	}
+	if len(notStarted) > 0 {
+		add([]Finding{{Severity: SeverityInfo, Message: fmt.Sprintf("upgrades stopped; not started: %s", strings.Join(notStarted, ", ")), Meta: map[string]interface{}{"not_started": notStarted}}})
+	}
+	if ctxErr != nil {
+		return summary, findings, ctxErr
+	}
+	return summary, findings, nil
+}
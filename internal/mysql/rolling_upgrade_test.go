@@ -0,0 +1,110 @@
+package mysql
+
+import (
+	"context"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// lagInspector reports a sequence of applier lags per replica; the last value
+// repeats.
+type lagInspector struct {
+	mu    sync.Mutex
+	lags  map[string][]time.Duration
+	reads map[string]int
+}
+
+func (l *lagInspector) IsPrimary(ctx context.Context, host string) (bool, error) { return false, nil }
+
+func (l *lagInspector) ReplicationStatus(ctx context.Context, replica string) (ReplicationStatus, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lags := l.lags[replica]
+	if len(lags) == 0 {
+		return ReplicationStatus{IOThreadRunning: true, SQLThreadRunning: true}, nil
+	}
+	i := l.reads[replica]
+	if i >= len(lags) {
+		i = len(lags) - 1
+	}
+	l.reads[replica]++
+	return ReplicationStatus{IOThreadRunning: true, SQLThreadRunning: true, Channels: []ChannelStatus{{ApplierLag: lags[i], LagKnown: true}}}, nil
+}
+
+func TestRollingUpgrade_PausesWhileRemainingReplicaLags(t *testing.T) {
+	inspector := &lagInspector{lags: map[string][]time.Duration{"r3": {30 * time.Second, 2 * time.Second}}, reads: map[string]int{}}
+	actions := &fakeActions{}
+	orchestrator := NewUpgradeOrchestrator(&fakeInspector{status: ReplicationStatus{IOThreadRunning: true, SQLThreadRunning: true}}, actions, nil, "p", log.New(io.Discard, "", 0))
+	rolling := &RollingUpgrade{
+		Orchestrator: orchestrator,
+		Guard:        UpgradeGuard{Inspector: inspector, MaxLag: 10 * time.Second},
+		PollInterval: time.Millisecond,
+	}
+
+	summary, findings, err := rolling.Run(context.Background(), []string{"r1", "r2", "r3"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Block != 0 || actions.upgradeCalls != 3 {
+		t.Fatalf("expected every replica to be upgraded after the pause, got %+v (%d upgrades)", summary, actions.upgradeCalls)
+	}
+	var paused, resumed bool
+	for _, f := range findings {
+		paused = paused || strings.Contains(f.Message, "upgrades paused before r1: replica r3 lags 30s")
+		resumed = resumed || strings.Contains(f.Message, "resuming upgrades")
+	}
+	if !paused || !resumed {
+		t.Fatalf("expected a pause and a resume, got %+v", findings)
+	}
+}
+
+func TestRollingUpgrade_BlocksWhenPausedTooLong(t *testing.T) {
+	load := &fakeTrafficInspector{counters: make([]int64, 1000)}
+	for i := range load.counters {
+		load.counters[i] = 80
+	}
+	actions := &fakeActions{}
+	orchestrator := NewUpgradeOrchestrator(&fakeInspector{}, actions, nil, "p", log.New(io.Discard, "", 0))
+	rolling := &RollingUpgrade{
+		Orchestrator: orchestrator,
+		Guard:        UpgradeGuard{Load: load, Primary: "p", MaxThreadsRunning: 50},
+		PollInterval: time.Millisecond,
+		MaxPause:     5 * time.Millisecond,
+	}
+
+	summary, findings, err := rolling.Run(context.Background(), []string{"r1", "r2"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Block != 1 || actions.stopCalls != 0 {
+		t.Fatalf("expected a BLOCK before any replica was touched, got %+v (%d stops)", summary, actions.stopCalls)
+	}
+	last := findings[len(findings)-1]
+	if notStarted, _ := last.Meta["not_started"].([]string); len(notStarted) != 2 {
+		t.Fatalf("expected both replicas to be reported as not started, got %+v", last)
+	}
+}
+
+func TestUpgradeGuard_RequiresServingReplicas(t *testing.T) {
+	guard := UpgradeGuard{MinServingReplicas: 2}
+	reasons, meta := guard.Degraded(context.Background(), []string{"r2"})
+	if len(reasons) != 1 || meta["serving_replicas"] != 1 {
+		t.Fatalf("expected too few serving replicas to degrade, got %v %v", reasons, meta)
+	}
+	if reasons, _ := guard.Degraded(context.Background(), []string{"r2", "r3"}); len(reasons) != 0 {
+		t.Fatalf("expected two serving replicas to be enough, got %v", reasons)
+	}
+}
+
+func TestUpgradeGuard_ReplicaMaxLagOverridesMaxLag(t *testing.T) {
+	inspector := &lagInspector{lags: map[string][]time.Duration{"r2": {20 * time.Second}, "eu1": {20 * time.Second}}, reads: map[string]int{}}
+	guard := UpgradeGuard{Inspector: inspector, MaxLag: 10 * time.Second, ReplicaMaxLag: map[string]time.Duration{"eu1": time.Minute}}
+	reasons, meta := guard.Degraded(context.Background(), []string{"r2", "eu1"})
+	if len(reasons) != 1 || !strings.Contains(reasons[0], "r2") || meta["serving_replicas"] != 1 {
+		t.Fatalf("expected only the in-region replica to lag, got %v %v", reasons, meta)
+	}
+}
@@ -0,0 +1,452 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"migratorx/internal/checks"
+)
+
+// DSNConnector returns a Connector that opens hosts through database/sql with
+// driverName. In dsn, {host} is replaced by the host, e.g.
+// "migratorx:secret@tcp({host}:3306)/". Each host gets one connection, put in
+// a ReadOnlySession and reused for every later call. The driver must be linked
+// into the binary; it is looked up by name.
+func DSNConnector(driverName string, dsn string) Connector {
+	return dsnConnector(driverName, dsn, func(ctx context.Context, conn *sql.Conn) (Querier, error) {
+		return NewReadOnlySession(ctx, conn)
+	})
+}
+
+// AdminDSNConnector is DSNConnector for steps that change servers: the
+// connection is used as is, without a ReadOnlySession.
+func AdminDSNConnector(driverName string, dsn string) Connector {
+	return dsnConnector(driverName, dsn, func(ctx context.Context, conn *sql.Conn) (Querier, error) {
+		return conn, nil
+	})
+}
+
+func dsnConnector(driverName string, dsn string, session func(ctx context.Context, conn *sql.Conn) (Querier, error)) Connector {
+	var mu sync.Mutex
+	sessions := map[string]Querier{}
+	return func(ctx context.Context, host string) (Querier, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if q, ok := sessions[host]; ok {
+			return q, nil
+		}
+		if !driverRegistered(driverName) {
+			return nil, fmt.Errorf("no database/sql driver %q is linked into this build", driverName)
+		}
+		db, err := sql.Open(driverName, strings.ReplaceAll(dsn, "{host}", host))
+		if err != nil {
+			return nil, err
+		}
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		q, err := session(ctx, conn)
+		if err != nil {
+			conn.Close()
+			db.Close()
+			return nil, err
+		}
+		sessions[host] = q
+		return q, nil
+	}
+}
+
+func driverRegistered(name string) bool {
+	for _, d := range sql.Drivers() {
+		if d == name {
+			return true
+		}
+	}
+	return false
+}
+
+// InformationSchemaInspector implements checks.SchemaInspector from
+// information_schema: tables with their engine, columns, primary keys,
+// secondary indexes, foreign keys and partitioning, plus views, stored
+// routines and events; it also reads AUTO_INCREMENT counters. With Database
+// set it reads only that database and names objects without it, like a
+// single-database dump; otherwise it reads every non-system database and
+// names them db.name.
+type InformationSchemaInspector struct {
+	Connect  Connector
+	Database string
+}
+
+const (
+	schemaTablesQuery = `SELECT TABLE_SCHEMA, TABLE_NAME, COALESCE(ENGINE, '')
+FROM information_schema.TABLES
+WHERE TABLE_TYPE = 'BASE TABLE' AND %s
+ORDER BY TABLE_SCHEMA, TABLE_NAME`
+
+	schemaColumnsQuery = `SELECT TABLE_SCHEMA, TABLE_NAME, COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE, COLUMN_DEFAULT,
+       COALESCE(CHARACTER_SET_NAME, ''), COALESCE(COLLATION_NAME, ''), EXTRA, COALESCE(GENERATION_EXPRESSION, '')
+FROM information_schema.COLUMNS
+WHERE %s
+ORDER BY TABLE_SCHEMA, TABLE_NAME, ORDINAL_POSITION`
+
+	// schemaSRIDQuery needs the 8.0 SRS_ID column; on 5.7 it fails and
+	// geometry columns are reported without an SRID.
+	schemaSRIDQuery = `SELECT TABLE_SCHEMA, TABLE_NAME, COLUMN_NAME, SRS_ID
+FROM information_schema.COLUMNS
+WHERE SRS_ID IS NOT NULL AND %s`
+
+	schemaIndexesQuery = `SELECT TABLE_SCHEMA, TABLE_NAME, INDEX_NAME, NON_UNIQUE, INDEX_TYPE, COLUMN_NAME, COALESCE(SUB_PART, 0)
+FROM information_schema.STATISTICS
+WHERE %s
+ORDER BY TABLE_SCHEMA, TABLE_NAME, INDEX_NAME, SEQ_IN_INDEX`
+
+	schemaForeignKeysQuery = `SELECT k.TABLE_SCHEMA, k.TABLE_NAME, k.CONSTRAINT_NAME, k.COLUMN_NAME,
+       k.REFERENCED_TABLE_SCHEMA, k.REFERENCED_TABLE_NAME, k.REFERENCED_COLUMN_NAME, r.DELETE_RULE, r.UPDATE_RULE
+FROM information_schema.KEY_COLUMN_USAGE k
+JOIN information_schema.REFERENTIAL_CONSTRAINTS r
+  ON r.CONSTRAINT_SCHEMA = k.CONSTRAINT_SCHEMA AND r.CONSTRAINT_NAME = k.CONSTRAINT_NAME
+WHERE k.REFERENCED_TABLE_NAME IS NOT NULL AND k.%s
+ORDER BY k.TABLE_SCHEMA, k.TABLE_NAME, k.CONSTRAINT_NAME, k.ORDINAL_POSITION`
+
+	schemaPartitionsQuery = `SELECT TABLE_SCHEMA, TABLE_NAME, PARTITION_NAME, PARTITION_METHOD, COALESCE(PARTITION_EXPRESSION, ''),
+       COALESCE(SUBPARTITION_METHOD, ''), COALESCE(SUBPARTITION_EXPRESSION, ''), COALESCE(PARTITION_DESCRIPTION, '')
+FROM information_schema.PARTITIONS
+WHERE PARTITION_NAME IS NOT NULL AND %s
+ORDER BY TABLE_SCHEMA, TABLE_NAME, PARTITION_ORDINAL_POSITION`
+
+	schemaViewsQuery = `SELECT TABLE_SCHEMA, TABLE_NAME, VIEW_DEFINITION
+FROM information_schema.VIEWS
+WHERE %s
+ORDER BY TABLE_SCHEMA, TABLE_NAME`
+
+	// schemaViewColumnsQuery needs the 8.0.13 VIEW_COLUMN_USAGE table; on
+	// older servers it fails and view references are inferred from the
+	// definitions.
+	schemaViewColumnsQuery = `SELECT VIEW_SCHEMA, VIEW_NAME, TABLE_SCHEMA, TABLE_NAME, COLUMN_NAME
+FROM information_schema.VIEW_COLUMN_USAGE
+WHERE %s
+ORDER BY VIEW_SCHEMA, VIEW_NAME, TABLE_SCHEMA, TABLE_NAME, COLUMN_NAME`
+
+	schemaRoutinesQuery = `SELECT ROUTINE_SCHEMA, ROUTINE_NAME, ROUTINE_TYPE, DEFINER, SECURITY_TYPE, COALESCE(ROUTINE_DEFINITION, '')
+FROM information_schema.ROUTINES
+WHERE %s
+ORDER BY ROUTINE_SCHEMA, ROUTINE_TYPE, ROUTINE_NAME`
+
+	schemaEventsQuery = `SELECT EVENT_SCHEMA, EVENT_NAME, STATUS, DEFINER,
+  CASE WHEN EVENT_TYPE = 'RECURRING' THEN CONCAT('EVERY ', INTERVAL_VALUE, ' ', INTERVAL_FIELD)
+       ELSE CONCAT('AT ', EXECUTE_AT) END,
+  COALESCE(EVENT_DEFINITION, '')
+FROM information_schema.EVENTS
+WHERE %s
+ORDER BY EVENT_SCHEMA, EVENT_NAME`
+)
+
+func (i *InformationSchemaInspector) Schema(ctx context.Context, host string) (checks.Schema, error) {
+	if i.Connect == nil {
+		return checks.Schema{}, fmt.Errorf("schema inspector requires a connector")
+	}
+	q, err := i.Connect(ctx, host)
+	if err != nil {
+		return checks.Schema{}, err
+	}
+	filter, args := i.filter()
+
+	tables := []*checks.Table{}
+	byName := map[string]*checks.Table{}
+	rows, err := q.QueryContext(ctx, fmt.Sprintf(schemaTablesQuery, filter), args...)
+	if err != nil {
+		return checks.Schema{}, fmt.Errorf("failed to list tables: %w", err)
+	}
+	err = scanRows(rows, func(scan func(...interface{}) error) error {
+		var db, name, engine string
+		if err := scan(&db, &name, &engine); err != nil {
+			return err
+		}
+		table := &checks.Table{Name: i.tableName(db, name), Engine: engine}
+		tables = append(tables, table)
+		byName[table.Name] = table
+		return nil
+	})
+	if err != nil {
+		return checks.Schema{}, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	rows, err = q.QueryContext(ctx, fmt.Sprintf(schemaColumnsQuery, filter), args...)
+	if err != nil {
+		return checks.Schema{}, fmt.Errorf("failed to read columns: %w", err)
+	}
+	err = scanRows(rows, func(scan func(...interface{}) error) error {
+		var db, table, name, columnType, nullable, charset, collation, extra, expression string
+		var def sql.NullString
+		if err := scan(&db, &table, &name, &columnType, &nullable, &def, &charset, &collation, &extra, &expression); err != nil {
+			return err
+		}
+		t, ok := byName[i.tableName(db, table)]
+		if !ok {
+			return nil // a view's columns
+		}
+		column := checks.Column{Name: name, Type: NormalizeColumnType(columnType), Nullable: nullable == "YES", Charset: charset, Collation: collation}
+		if def.Valid {
+			column.Default = &def.String
+		}
+		// EXTRA also says DEFAULT_GENERATED for an expression default, which
+		// is not a generated column.
+		for _, kind := range []string{"VIRTUAL", "STORED"} {
+			if strings.Contains(strings.ToUpper(extra), kind+" GENERATED") {
+				column.Generated, column.Expression = kind, expression
+			}
+		}
+		t.Columns = append(t.Columns, column)
+		return nil
+	})
+	if err != nil {
+		return checks.Schema{}, fmt.Errorf("failed to read columns: %w", err)
+	}
+
+	if rows, err := q.QueryContext(ctx, fmt.Sprintf(schemaSRIDQuery, filter), args...); err == nil {
+		_ = scanRows(rows, func(scan func(...interface{}) error) error {
+			var db, table, name string
+			var srid uint32
+			if err := scan(&db, &table, &name, &srid); err != nil {
+				return err
+			}
+			if t, ok := byName[i.tableName(db, table)]; ok {
+				for c := range t.Columns {
+					if t.Columns[c].Name == name {
+						id := srid
+						t.Columns[c].SRID = &id
+					}
+				}
+			}
+			return nil
+		})
+	}
+
+	rows, err = q.QueryContext(ctx, fmt.Sprintf(schemaIndexesQuery, filter), args...)
+	if err != nil {
+		return checks.Schema{}, fmt.Errorf("failed to read indexes: %w", err)
+	}
+	err = scanRows(rows, func(scan func(...interface{}) error) error {
+		var db, table, name, indexType, column string
+		var nonUnique, prefix int
+		if err := scan(&db, &table, &name, &nonUnique, &indexType, &column, &prefix); err != nil {
+			return err
+		}
+		t, ok := byName[i.tableName(db, table)]
+		if !ok {
+			return nil
+		}
+		if name == "PRIMARY" {
+			t.PrimaryKey = append(t.PrimaryKey, column)
+			return nil
+		}
+		if n := len(t.Indexes); n > 0 && t.Indexes[n-1].Name == name {
+			t.Indexes[n-1].Columns = append(t.Indexes[n-1].Columns, column)
+			t.Indexes[n-1].Prefixes = append(t.Indexes[n-1].Prefixes, prefix)
+			return nil
+		}
+		t.Indexes = append(t.Indexes, checks.Index{Name: name, Type: indexType, Unique: nonUnique == 0, Columns: []string{column}, Prefixes: []int{prefix}})
+		return nil
+	})
+	if err != nil {
+		return checks.Schema{}, fmt.Errorf("failed to read indexes: %w", err)
+	}
+	for _, t := range byName {
+		for n := range t.Indexes {
+			t.Indexes[n].Prefixes = prefixesOrNil(t.Indexes[n].Prefixes)
+		}
+	}
+
+	rows, err = q.QueryContext(ctx, fmt.Sprintf(schemaForeignKeysQuery, filter), args...)
+	if err != nil {
+		return checks.Schema{}, fmt.Errorf("failed to read foreign keys: %w", err)
+	}
+	err = scanRows(rows, func(scan func(...interface{}) error) error {
+		var db, table, name, column, refDB, refTable, refColumn, onDelete, onUpdate string
+		if err := scan(&db, &table, &name, &column, &refDB, &refTable, &refColumn, &onDelete, &onUpdate); err != nil {
+			return err
+		}
+		t, ok := byName[i.tableName(db, table)]
+		if !ok {
+			return nil
+		}
+		if n := len(t.ForeignKeys); n > 0 && t.ForeignKeys[n-1].Name == name {
+			t.ForeignKeys[n-1].Columns = append(t.ForeignKeys[n-1].Columns, column)
+			t.ForeignKeys[n-1].ReferencedColumns = append(t.ForeignKeys[n-1].ReferencedColumns, refColumn)
+			return nil
+		}
+		referenced := refDB + "." + refTable
+		if i.Database != "" && refDB == i.Database {
+			referenced = refTable
+		}
+		t.ForeignKeys = append(t.ForeignKeys, checks.ForeignKey{
+			Name:              name,
+			Columns:           []string{column},
+			ReferencedTable:   referenced,
+			ReferencedColumns: []string{refColumn},
+			OnDelete:          onDelete,
+			OnUpdate:          onUpdate,
+		})
+		return nil
+	})
+	if err != nil {
+		return checks.Schema{}, fmt.Errorf("failed to read foreign keys: %w", err)
+	}
+
+	rows, err = q.QueryContext(ctx, fmt.Sprintf(schemaPartitionsQuery, filter), args...)
+	if err != nil {
+		return checks.Schema{}, fmt.Errorf("failed to read partitions: %w", err)
+	}
+	err = scanRows(rows, func(scan func(...interface{}) error) error {
+		var db, table, name, method, expression, subMethod, subExpression, description string
+		if err := scan(&db, &table, &name, &method, &expression, &subMethod, &subExpression, &description); err != nil {
+			return err
+		}
+		t, ok := byName[i.tableName(db, table)]
+		if !ok {
+			return nil
+		}
+		if t.Partitioning == nil {
+			t.Partitioning = &checks.Partitioning{Method: method, Expression: expression, SubpartitionMethod: subMethod, SubpartitionExpression: subExpression}
+		}
+		// Subpartitioned tables have one row per subpartition.
+		if n := len(t.Partitioning.Partitions); n > 0 && t.Partitioning.Partitions[n-1].Name == name {
+			return nil
+		}
+		t.Partitioning.Partitions = append(t.Partitioning.Partitions, checks.Partition{Name: name, Description: description})
+		return nil
+	})
+	if err != nil {
+		return checks.Schema{}, fmt.Errorf("failed to read partitions: %w", err)
+	}
+
+	schema := checks.Schema{Tables: make([]checks.Table, 0, len(tables))}
+	for _, t := range tables {
+		schema.Tables = append(schema.Tables, *t)
+	}
+
+	rows, err = q.QueryContext(ctx, fmt.Sprintf(schemaViewsQuery, filter), args...)
+	if err != nil {
+		return checks.Schema{}, fmt.Errorf("failed to read views: %w", err)
+	}
+	views := map[string]int{}
+	err = scanRows(rows, func(scan func(...interface{}) error) error {
+		var db, name, definition string
+		if err := scan(&db, &name, &definition); err != nil {
+			return err
+		}
+		views[i.tableName(db, name)] = len(schema.Views)
+		schema.Views = append(schema.Views, checks.View{Name: i.tableName(db, name), Definition: definition})
+		return nil
+	})
+	if err != nil {
+		return checks.Schema{}, fmt.Errorf("failed to read views: %w", err)
+	}
+	if len(views) > 0 {
+		viewFilter := strings.Replace(filter, "TABLE_SCHEMA", "VIEW_SCHEMA", 1)
+		if rows, err := q.QueryContext(ctx, fmt.Sprintf(schemaViewColumnsQuery, viewFilter), args...); err == nil {
+			_ = scanRows(rows, func(scan func(...interface{}) error) error {
+				var viewDB, view, db, table, column string
+				if err := scan(&viewDB, &view, &db, &table, &column); err != nil {
+					return err
+				}
+				if v, ok := views[i.tableName(viewDB, view)]; ok {
+					schema.Views[v].References = append(schema.Views[v].References, i.tableName(db, table)+"."+column)
+				}
+				return nil
+			})
+		}
+	}
+
+	rows, err = q.QueryContext(ctx, fmt.Sprintf(schemaRoutinesQuery, strings.Replace(filter, "TABLE_SCHEMA", "ROUTINE_SCHEMA", 1)), args...)
+	if err != nil {
+		return checks.Schema{}, fmt.Errorf("failed to read routines: %w", err)
+	}
+	err = scanRows(rows, func(scan func(...interface{}) error) error {
+		var db string
+		var r checks.Routine
+		if err := scan(&db, &r.Name, &r.Type, &r.Definer, &r.SecurityType, &r.Body); err != nil {
+			return err
+		}
+		r.Name = i.tableName(db, r.Name)
+		schema.Routines = append(schema.Routines, r)
+		return nil
+	})
+	if err != nil {
+		return checks.Schema{}, fmt.Errorf("failed to read routines: %w", err)
+	}
+
+	rows, err = q.QueryContext(ctx, fmt.Sprintf(schemaEventsQuery, strings.Replace(filter, "TABLE_SCHEMA", "EVENT_SCHEMA", 1)), args...)
+	if err != nil {
+		return checks.Schema{}, fmt.Errorf("failed to read events: %w", err)
+	}
+	err = scanRows(rows, func(scan func(...interface{}) error) error {
+		var db string
+		var e checks.Event
+		if err := scan(&db, &e.Name, &e.Status, &e.Definer, &e.Schedule, &e.Body); err != nil {
+			return err
+		}
+		e.Name = i.tableName(db, e.Name)
+		schema.Events = append(schema.Events, e)
+		return nil
+	})
+	if err != nil {
+		return checks.Schema{}, fmt.Errorf("failed to read events: %w", err)
+	}
+	return schema, nil
+}
+
+func (i *InformationSchemaInspector) filter() (string, []interface{}) {
+	if i.Database != "" {
+		return "TABLE_SCHEMA = ?", []interface{}{i.Database}
+	}
+	return "TABLE_SCHEMA NOT IN (" + systemSchemaList + ")", nil
+}
+
+func (i *InformationSchemaInspector) tableName(db string, table string) string {
+	if i.Database != "" {
+		return table
+	}
+	return db + "." + table
+}
+
+// scanRows calls fn for each row and closes rows.
+func scanRows(rows *sql.Rows, fn func(scan func(...interface{}) error) error) error {
+	defer rows.Close()
+	for rows.Next() {
+		if err := fn(rows.Scan); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+var integerDisplayWidth = regexp.MustCompile(`^(tinyint|smallint|mediumint|int|bigint)\(\d+\)`)
+
+// NormalizeColumnType drops the integer display width 5.7 reports (int(11))
+// and 8.0.19 and later do not, so the same column compares equal across
+// versions. tinyint(1) and zerofill columns keep it, as 8.0 does.
+func NormalizeColumnType(columnType string) string {
+	t := strings.ToLower(strings.TrimSpace(columnType))
+	if strings.HasPrefix(t, "tinyint(1)") || strings.Contains(t, "zerofill") {
+		return t
+	}
+	return integerDisplayWidth.ReplaceAllString(t, "$1")
+}
+
+// prefixesOrNil drops prefix lengths from an index without any, so snapshots
+// of unprefixed indexes stay as they were before prefixes were read.
+func prefixesOrNil(prefixes []int) []int {
+	for _, p := range prefixes {
+		if p > 0 {
+			return prefixes
+		}
+	}
+	return nil
+}
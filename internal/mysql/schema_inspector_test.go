@@ -0,0 +1,163 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func schemaResponses() []fakeResponse {
+	return []fakeResponse{
+		{match: "SRS_ID", columns: []string{"TABLE_SCHEMA", "TABLE_NAME", "COLUMN_NAME", "SRS_ID"}, rows: [][]driver.Value{
+			{"shop", "stores", "location", int64(4326)},
+		}},
+		{match: "information_schema.TABLES", columns: []string{"TABLE_SCHEMA", "TABLE_NAME", "ENGINE"}, rows: [][]driver.Value{
+			{"shop", "orders", "InnoDB"},
+			{"shop", "stores", "InnoDB"},
+		}},
+		{match: "information_schema.COLUMNS", columns: []string{"TABLE_SCHEMA", "TABLE_NAME", "COLUMN_NAME", "COLUMN_TYPE", "IS_NULLABLE", "COLUMN_DEFAULT", "CHARACTER_SET_NAME", "COLLATION_NAME", "EXTRA", "GENERATION_EXPRESSION"}, rows: [][]driver.Value{
+			{"shop", "orders", "id", "int(11) unsigned", "NO", nil, "", "", "auto_increment", ""},
+			{"shop", "orders", "status", "varchar(16)", "NO", "new", "utf8mb4", "utf8mb4_0900_ai_ci", "", ""},
+			{"shop", "orders", "paid", "tinyint(1)", "YES", nil, "", "", "VIRTUAL GENERATED", "(`status` = _utf8mb4'paid')"},
+			{"shop", "stores", "id", "int", "NO", nil, "", "", "", ""},
+			{"shop", "stores", "location", "point", "NO", nil, "", "", "", ""},
+			{"shop", "order_view", "id", "int", "NO", nil, "", "", "", ""},
+		}},
+		{match: "information_schema.STATISTICS", columns: []string{"TABLE_SCHEMA", "TABLE_NAME", "INDEX_NAME", "NON_UNIQUE", "INDEX_TYPE", "COLUMN_NAME", "SUB_PART"}, rows: [][]driver.Value{
+			{"shop", "orders", "PRIMARY", int64(0), "BTREE", "id", int64(0)},
+			{"shop", "orders", "idx_status_paid", int64(1), "BTREE", "status", int64(8)},
+			{"shop", "orders", "idx_status_paid", int64(1), "BTREE", "paid", int64(0)},
+			{"shop", "stores", "PRIMARY", int64(0), "BTREE", "id", int64(0)},
+			{"shop", "stores", "sp_location", int64(1), "SPATIAL", "location", int64(0)},
+		}},
+		{match: "REFERENTIAL_CONSTRAINTS", columns: []string{"TABLE_SCHEMA", "TABLE_NAME", "CONSTRAINT_NAME", "COLUMN_NAME", "REFERENCED_TABLE_SCHEMA", "REFERENCED_TABLE_NAME", "REFERENCED_COLUMN_NAME", "DELETE_RULE", "UPDATE_RULE"}, rows: [][]driver.Value{
+			{"shop", "orders", "fk_orders_store", "store_id", "shop", "stores", "id", "CASCADE", "RESTRICT"},
+			{"shop", "orders", "fk_orders_user", "user_id", "accounts", "users", "id", "RESTRICT", "RESTRICT"},
+		}},
+		{match: "VIEW_COLUMN_USAGE", columns: []string{"VIEW_SCHEMA", "VIEW_NAME", "TABLE_SCHEMA", "TABLE_NAME", "COLUMN_NAME"}, rows: [][]driver.Value{
+			{"shop", "order_view", "shop", "orders", "id"},
+		}},
+		{match: "information_schema.VIEWS", columns: []string{"TABLE_SCHEMA", "TABLE_NAME", "VIEW_DEFINITION"}, rows: [][]driver.Value{
+			{"shop", "order_view", "select `shop`.`orders`.`id` AS `id` from `shop`.`orders`"},
+		}},
+		{match: "information_schema.ROUTINES", columns: []string{"ROUTINE_SCHEMA", "ROUTINE_NAME", "ROUTINE_TYPE", "DEFINER", "SECURITY_TYPE", "ROUTINE_DEFINITION"}, rows: [][]driver.Value{
+			{"shop", "close_order", "PROCEDURE", "app@%", "DEFINER", "BEGIN UPDATE orders SET status = 'closed'; END"},
+		}},
+		{match: "information_schema.EVENTS", columns: []string{"EVENT_SCHEMA", "EVENT_NAME", "STATUS", "DEFINER", "SCHEDULE", "EVENT_DEFINITION"}, rows: [][]driver.Value{
+			{"shop", "purge_carts", "SLAVESIDE_DISABLED", "app@%", "EVERY 1 HOUR", "DELETE FROM carts WHERE updated_at < NOW() - INTERVAL 1 DAY"},
+		}},
+		{match: "information_schema.PARTITIONS", columns: []string{"TABLE_SCHEMA", "TABLE_NAME", "PARTITION_NAME", "PARTITION_METHOD", "PARTITION_EXPRESSION", "SUBPARTITION_METHOD", "SUBPARTITION_EXPRESSION", "PARTITION_DESCRIPTION"}, rows: [][]driver.Value{
+			{"shop", "orders", "p0", "RANGE", "`id`", "HASH", "`id`", "1000"},
+			{"shop", "orders", "p0", "RANGE", "`id`", "HASH", "`id`", "1000"},
+			{"shop", "orders", "p1", "RANGE", "`id`", "HASH", "`id`", "MAXVALUE"},
+		}},
+	}
+}
+
+func TestInformationSchemaInspector_BuildsSchema(t *testing.T) {
+	db := openFakeDB(t, schemaResponses()...)
+	inspector := &InformationSchemaInspector{Connect: fakeConnector(db), Database: "shop"}
+	schema, err := inspector.Schema(context.Background(), "db1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(schema.Tables) != 2 || schema.Tables[0].Name != "orders" {
+		t.Fatalf("expected the two base tables by bare name, got %+v", schema.Tables)
+	}
+	orders := schema.Tables[0]
+	if len(orders.Columns) != 3 || orders.Columns[0].Type != "int unsigned" || orders.Columns[2].Type != "tinyint(1)" {
+		t.Fatalf("unexpected columns: %+v", orders.Columns)
+	}
+	if status := orders.Columns[1]; status.Default == nil || *status.Default != "new" || status.Collation != "utf8mb4_0900_ai_ci" || status.Nullable {
+		t.Fatalf("unexpected status column: %+v", status)
+	}
+	if len(orders.PrimaryKey) != 1 || orders.PrimaryKey[0] != "id" {
+		t.Fatalf("unexpected primary key: %+v", orders.PrimaryKey)
+	}
+	if len(orders.Indexes) != 1 || orders.Indexes[0].Unique || strings.Join(orders.Indexes[0].Columns, ",") != "status,paid" {
+		t.Fatalf("unexpected indexes: %+v", orders.Indexes)
+	}
+	if p := orders.Indexes[0].Prefixes; len(p) != 2 || p[0] != 8 || p[1] != 0 {
+		t.Fatalf("expected the status prefix length, got %v", p)
+	}
+	if stores := schema.Tables[1]; stores.Indexes[0].Prefixes != nil {
+		t.Fatalf("expected no prefixes on an unprefixed index, got %v", stores.Indexes[0].Prefixes)
+	}
+	if p := orders.Partitioning; p == nil || p.Method != "RANGE" || p.SubpartitionMethod != "HASH" || len(p.Partitions) != 2 {
+		t.Fatalf("unexpected partitioning: %+v", p)
+	}
+	if fks := orders.ForeignKeys; len(fks) != 2 || fks[0].ReferencedTable != "stores" || fks[0].OnDelete != "CASCADE" || fks[1].ReferencedTable != "accounts.users" {
+		t.Fatalf("unexpected foreign keys: %+v", fks)
+	}
+	if len(schema.Views) != 1 || schema.Views[0].Name != "order_view" || strings.Join(schema.Views[0].References, ",") != "orders.id" {
+		t.Fatalf("unexpected views: %+v", schema.Views)
+	}
+	if len(schema.Routines) != 1 || schema.Routines[0].Name != "close_order" || schema.Routines[0].Definer != "app@%" {
+		t.Fatalf("unexpected routines: %+v", schema.Routines)
+	}
+	if paid := schema.Tables[0].Columns[2]; paid.Generated != "VIRTUAL" || paid.Expression != "(`status` = _utf8mb4'paid')" || schema.Tables[0].Columns[0].Generated != "" {
+		t.Fatalf("expected paid to be a virtual generated column, got %+v", schema.Tables[0].Columns)
+	}
+	if len(schema.Events) != 1 || schema.Events[0].Name != "purge_carts" || schema.Events[0].Enabled() || schema.Events[0].Schedule != "EVERY 1 HOUR" {
+		t.Fatalf("unexpected events: %+v", schema.Events)
+	}
+	stores := schema.Tables[1]
+	if srid := stores.Columns[1].SRID; srid == nil || *srid != 4326 {
+		t.Fatalf("expected location SRID, got %+v", stores.Columns[1])
+	}
+}
+
+func TestInformationSchemaInspector_QualifiesNamesWithoutDatabase(t *testing.T) {
+	responses := schemaResponses()
+	responses[0] = fakeResponse{match: "SRS_ID", err: errors.New("Unknown column 'SRS_ID'")}
+	db := openFakeDB(t, responses...)
+	inspector := &InformationSchemaInspector{Connect: fakeConnector(db)}
+	schema, err := inspector.Schema(context.Background(), "db1")
+	if err != nil {
+		t.Fatalf("expected a 5.7 host without SRS_ID to be read, got %v", err)
+	}
+	if schema.Tables[1].Name != "shop.stores" || schema.Tables[1].Columns[1].SRID != nil || schema.Views[0].Name != "shop.order_view" {
+		t.Fatalf("unexpected tables: %+v", schema.Tables)
+	}
+}
+
+func TestDSNConnector_RequiresRegisteredDriver(t *testing.T) {
+	_, err := DSNConnector("migratorx-missing", "user@tcp({host}:3306)/")(context.Background(), "db1")
+	if err == nil || !strings.Contains(err.Error(), "migratorx-missing") {
+		t.Fatalf("expected missing driver error, got %v", err)
+	}
+}
+
+func TestDSNConnector_ReusesReadOnlySessionPerHost(t *testing.T) {
+	openFakeDB(t)
+	fakeDriverMu.Lock()
+	fakeDriverDBs["db1"] = []fakeResponse{{match: "SET SESSION"}}
+	fakeDriverMu.Unlock()
+	connect := DSNConnector("migratorx-fake", "{host}")
+	first, err := connect(context.Background(), "db1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, _ := connect(context.Background(), "db1")
+	if _, ok := first.(*ReadOnlySession); !ok || first != second {
+		t.Fatalf("expected one read-only session per host, got %T %T", first, second)
+	}
+}
+
+func TestNormalizeColumnType(t *testing.T) {
+	cases := map[string]string{
+		"int(11)":                  "int",
+		"BIGINT(20) unsigned":      "bigint unsigned",
+		"tinyint(1)":               "tinyint(1)",
+		"int(5) unsigned zerofill": "int(5) unsigned zerofill",
+		"varchar(255)":             "varchar(255)",
+		"decimal(10,2)":            "decimal(10,2)",
+	}
+	for in, want := range cases {
+		if got := NormalizeColumnType(in); got != want {
+			t.Fatalf("NormalizeColumnType(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
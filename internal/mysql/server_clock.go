@@ -0,0 +1,35 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// ServerClockInspector implements checks.ClockInspector with
+// UNIX_TIMESTAMP(NOW(6)), which does not depend on the session time_zone.
+type ServerClockInspector struct {
+	Connect Connector
+}
+
+func (i *ServerClockInspector) Clock(ctx context.Context, host string) (time.Time, error) {
+	if i.Connect == nil {
+		return time.Time{}, fmt.Errorf("clock inspector requires a connector")
+	}
+	q, err := i.Connect(ctx, host)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var raw string
+	if err := queryOne(ctx, q, "SELECT UNIX_TIMESTAMP(NOW(6))", nil, &raw); err != nil {
+		return time.Time{}, fmt.Errorf("failed to read server time: %w", err)
+	}
+	seconds, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unexpected server time %q: %w", raw, err)
+	}
+	whole, frac := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(frac*1e9)).UTC(), nil
+}
@@ -0,0 +1,20 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+)
+
+func TestServerClockInspector(t *testing.T) {
+	db := openFakeDB(t, fakeResponse{match: "UNIX_TIMESTAMP", columns: []string{"t"}, rows: [][]driver.Value{{"1714564800.250000"}}})
+	clock, err := (&ServerClockInspector{Connect: fakeConnector(db)}).Clock(context.Background(), "primary")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := time.Date(2024, 5, 1, 12, 0, 0, 250_000_000, time.UTC)
+	if d := clock.Sub(want); d > time.Microsecond || d < -time.Microsecond {
+		t.Fatalf("expected %s, got %s", want, clock)
+	}
+}
@@ -0,0 +1,190 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"migratorx/internal/checks"
+	"migratorx/internal/workflow"
+)
+
+// ServerIdentity is a server's replication identity.
+type ServerIdentity struct {
+	ServerID   uint32
+	ServerUUID string
+}
+
+// ServerIdentityInspector reads a server's server_id and server_uuid.
+type ServerIdentityInspector interface {
+	ServerIdentity(ctx context.Context, host string) (ServerIdentity, error)
+}
+
+// ServerIdentityCheck validates replication identities across the topology:
+// every member needs a unique non-zero server_id and a unique server_uuid
+// (a datadir cloned with its auto.cnf repeats the UUID), hosts must match the
+// identities the plan expects after the upgrade, and the CDC connector's
+// database.server.id must not collide with any member.
+type ServerIdentityCheck struct {
+	Inspector   ServerIdentityInspector
+	Hosts       []string
+	Expected    map[string]workflow.ServerIdentityConfig
+	CDCServerID uint32
+}
+
+func (c *ServerIdentityCheck) Name() string   { return "server_identity" }
+func (c *ServerIdentityCheck) ReadOnly() bool { return true }
+
+func (c *ServerIdentityCheck) Parameters() map[string]interface{} {
+	expected := []string{}
+	for host := range c.Expected {
+		expected = append(expected, host)
+	}
+	sort.Strings(expected)
+	return map[string]interface{}{"hosts": c.Hosts, "expected": expected, "cdc_server_id": c.CDCServerID}
+}
+
+func (c *ServerIdentityCheck) Run(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+	if c.Inspector == nil {
+		return nil, fmt.Errorf("server identity inspector is required")
+	}
+	if len(c.Hosts) == 0 {
+		return nil, fmt.Errorf("at least one host is required")
+	}
+
+	findings := []checks.Finding{}
+	identities := map[string]ServerIdentity{}
+	byID := map[uint32][]string{}
+	byUUID := map[string][]string{}
+	for _, host := range c.Hosts {
+		identity, err := c.Inspector.ServerIdentity(ctx, host)
+		if err != nil {
+			findings = append(findings, checks.Finding{
+				Severity: checks.SeverityWarn,
+				Code:     CodeServerIdentityUnknown,
+				Message:  fmt.Sprintf("unable to read server_id and server_uuid on %q: %v", host, err),
+				Meta:     map[string]interface{}{"host": host},
+			})
+			continue
+		}
+		identities[host] = identity
+		byID[identity.ServerID] = append(byID[identity.ServerID], host)
+		if identity.ServerUUID != "" {
+			uuid := strings.ToLower(identity.ServerUUID)
+			byUUID[uuid] = append(byUUID[uuid], host)
+		}
+		if identity.ServerID == 0 {
+			findings = append(findings, checks.Finding{
+				Severity: checks.SeverityBlock,
+				Code:     CodeServerIDUnset,
+				Message:  fmt.Sprintf("%s has server_id 0; replication refuses to connect", host),
+				Meta:     map[string]interface{}{"host": host},
+			})
+		}
+		findings = append(findings, c.expectationFindings(host, identity)...)
+	}
+
+	for _, id := range sortedServerIDs(byID) {
+		if hosts := byID[id]; id != 0 && len(hosts) > 1 {
+			findings = append(findings, checks.Finding{
+				Severity: checks.SeverityBlock,
+				Code:     CodeServerIDDuplicate,
+				Message:  fmt.Sprintf("server_id %d is shared by %s", id, strings.Join(hosts, ", ")),
+				Meta:     map[string]interface{}{"server_id": id, "hosts": hosts},
+			})
+		}
+	}
+	uuids := make([]string, 0, len(byUUID))
+	for uuid := range byUUID {
+		uuids = append(uuids, uuid)
+	}
+	sort.Strings(uuids)
+	for _, uuid := range uuids {
+		if hosts := byUUID[uuid]; len(hosts) > 1 {
+			findings = append(findings, checks.Finding{
+				Severity: checks.SeverityBlock,
+				Code:     CodeServerUUIDDuplicate,
+				Message:  fmt.Sprintf("server_uuid %s is shared by %s; remove auto.cnf from cloned datadirs so each server generates its own", uuid, strings.Join(hosts, ", ")),
+				Meta:     map[string]interface{}{"server_uuid": uuid, "hosts": hosts},
+			})
+		}
+	}
+	if c.CDCServerID != 0 {
+		if hosts := byID[c.CDCServerID]; len(hosts) > 0 {
+			findings = append(findings, checks.Finding{
+				Severity: checks.SeverityBlock,
+				Code:     CodeCDCServerIDCollision,
+				Message:  fmt.Sprintf("CDC database.server.id %d collides with %s; the connector and the server would evict each other's replication sessions", c.CDCServerID, strings.Join(hosts, ", ")),
+				Meta:     map[string]interface{}{"server_id": c.CDCServerID, "hosts": hosts},
+			})
+		}
+	}
+
+	if len(findings) == 0 {
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityInfo,
+			Code:     CodeServerIdentityOK,
+			Message:  fmt.Sprintf("server_id and server_uuid are unique across %d host(s) and match the plan", len(identities)),
+			Meta:     map[string]interface{}{"hosts": c.Hosts},
+		})
+	}
+	return findings, nil
+}
+
+func (c *ServerIdentityCheck) expectationFindings(host string, identity ServerIdentity) []checks.Finding {
+	expected, ok := c.Expected[host]
+	if !ok {
+		return nil
+	}
+	findings := []checks.Finding{}
+	if expected.ServerID != 0 && expected.ServerID != identity.ServerID {
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityBlock,
+			Code:     CodeServerIdentityChanged,
+			Message:  fmt.Sprintf("%s has server_id %d; the plan expects %d", host, identity.ServerID, expected.ServerID),
+			Meta:     map[string]interface{}{"host": host, "field": "server_id", "actual": identity.ServerID, "expected": expected.ServerID},
+		})
+	}
+	if expected.ServerUUID != "" && !strings.EqualFold(expected.ServerUUID, identity.ServerUUID) {
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityBlock,
+			Code:     CodeServerIdentityChanged,
+			Message:  fmt.Sprintf("%s has server_uuid %s; the plan expects %s", host, identity.ServerUUID, expected.ServerUUID),
+			Meta:     map[string]interface{}{"host": host, "field": "server_uuid", "actual": identity.ServerUUID, "expected": expected.ServerUUID},
+		})
+	}
+	return findings
+}
+
+func sortedServerIDs(byID map[uint32][]string) []uint32 {
+	ids := make([]uint32, 0, len(byID))
+	for id := range byID {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+const serverIdentityQuery = `SELECT @@GLOBAL.server_id, @@GLOBAL.server_uuid`
+
+// ServerIdentityVariablesInspector implements ServerIdentityInspector with
+// global variables.
+type ServerIdentityVariablesInspector struct {
+	Connect Connector
+}
+
+func (i *ServerIdentityVariablesInspector) ServerIdentity(ctx context.Context, host string) (ServerIdentity, error) {
+	if i.Connect == nil {
+		return ServerIdentity{}, fmt.Errorf("server identity inspector requires a connector")
+	}
+	q, err := i.Connect(ctx, host)
+	if err != nil {
+		return ServerIdentity{}, err
+	}
+	var identity ServerIdentity
+	if err := queryOne(ctx, q, serverIdentityQuery, nil, &identity.ServerID, &identity.ServerUUID); err != nil {
+		return ServerIdentity{}, fmt.Errorf("failed to read server identity: %w", err)
+	}
+	return identity, nil
+}
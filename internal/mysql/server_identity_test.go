@@ -0,0 +1,86 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"migratorx/internal/checks"
+	"migratorx/internal/workflow"
+)
+
+type fakeIdentityInspector struct {
+	identities map[string]ServerIdentity
+}
+
+func (f *fakeIdentityInspector) ServerIdentity(ctx context.Context, host string) (ServerIdentity, error) {
+	identity, ok := f.identities[host]
+	if !ok {
+		return ServerIdentity{}, errors.New("connection refused")
+	}
+	return identity, nil
+}
+
+func identityCodes(findings []checks.Finding) map[string]int {
+	codes := map[string]int{}
+	for _, f := range findings {
+		codes[f.Code]++
+	}
+	return codes
+}
+
+func TestServerIdentityCheck_UniqueAndExpected(t *testing.T) {
+	inspector := &fakeIdentityInspector{identities: map[string]ServerIdentity{
+		"mysql-primary":   {ServerID: 1, ServerUUID: "aaaaaaaa-0000-0000-0000-000000000001"},
+		"mysql-replica-1": {ServerID: 2, ServerUUID: "aaaaaaaa-0000-0000-0000-000000000002"},
+	}}
+	check := &ServerIdentityCheck{
+		Inspector:   inspector,
+		Hosts:       []string{"mysql-primary", "mysql-replica-1"},
+		Expected:    map[string]workflow.ServerIdentityConfig{"mysql-replica-1": {ServerID: 2, ServerUUID: "AAAAAAAA-0000-0000-0000-000000000002"}},
+		CDCServerID: 5400,
+	}
+	findings, err := check.Run(context.Background(), checks.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Code != CodeServerIdentityOK {
+		t.Fatalf("expected SERVER_IDENTITY_OK, got %+v", findings)
+	}
+}
+
+func TestServerIdentityCheck_BlocksCollisionsAndUnexpectedChanges(t *testing.T) {
+	inspector := &fakeIdentityInspector{identities: map[string]ServerIdentity{
+		"mysql-primary":   {ServerID: 1, ServerUUID: "aaaaaaaa-0000-0000-0000-000000000001"},
+		"mysql-replica-1": {ServerID: 1, ServerUUID: "aaaaaaaa-0000-0000-0000-000000000001"},
+		"mysql-replica-2": {ServerID: 0, ServerUUID: "aaaaaaaa-0000-0000-0000-000000000003"},
+	}}
+	check := &ServerIdentityCheck{
+		Inspector:   inspector,
+		Hosts:       []string{"mysql-primary", "mysql-replica-1", "mysql-replica-2", "mysql-replica-3"},
+		Expected:    map[string]workflow.ServerIdentityConfig{"mysql-replica-1": {ServerID: 2}},
+		CDCServerID: 1,
+	}
+	findings, err := check.Run(context.Background(), checks.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	codes := identityCodes(findings)
+	for _, code := range []string{CodeServerIDDuplicate, CodeServerUUIDDuplicate, CodeServerIDUnset, CodeServerIdentityChanged, CodeCDCServerIDCollision, CodeServerIdentityUnknown} {
+		if codes[code] != 1 {
+			t.Fatalf("expected one %s, got %+v", code, findings)
+		}
+	}
+}
+
+func TestServerIdentityVariablesInspector_ReadsIdentity(t *testing.T) {
+	db := openFakeDB(t,
+		fakeResponse{match: "server_uuid", columns: []string{"server_id", "server_uuid"}, rows: [][]driver.Value{{int64(102), "aaaaaaaa-0000-0000-0000-000000000002"}}},
+	)
+	inspector := &ServerIdentityVariablesInspector{Connect: fakeConnector(db)}
+	identity, err := inspector.ServerIdentity(context.Background(), "mysql-replica-1")
+	if err != nil || identity.ServerID != 102 || identity.ServerUUID != "aaaaaaaa-0000-0000-0000-000000000002" {
+		t.Fatalf("unexpected identity %+v (%v)", identity, err)
+	}
+}
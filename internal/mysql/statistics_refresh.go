@@ -0,0 +1,310 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"migratorx/internal/failure"
+	"migratorx/internal/workflow"
+)
+
+// DefaultStatisticsMaxAge is how old a table's persistent statistics may be
+// after a rebuild when the plan does not set statistics.max_age.
+const DefaultStatisticsMaxAge = time.Hour
+
+const defaultHistogramBuckets = 100
+
+const (
+	statisticsTablesQuery = `SELECT TABLE_SCHEMA, TABLE_NAME FROM information_schema.TABLES
+WHERE TABLE_TYPE = 'BASE TABLE' AND ENGINE = 'InnoDB'
+  AND TABLE_SCHEMA NOT IN ('mysql', 'sys', 'information_schema', 'performance_schema')
+ORDER BY TABLE_SCHEMA, TABLE_NAME`
+	statisticsAgeQuery = `SELECT database_name, table_name, TIMESTAMPDIFF(SECOND, last_update, NOW()) FROM mysql.innodb_table_stats`
+	histogramsQuery    = `SELECT SCHEMA_NAME, TABLE_NAME, COLUMN_NAME FROM information_schema.COLUMN_STATISTICS`
+)
+
+// StatisticsRefresh rebuilds optimizer statistics on an upgraded replica:
+// the 8.0 optimizer plans from persistent statistics and histograms that are
+// stale or missing right after the upgrade. Every table is analyzed with
+// ANALYZE NO_WRITE_TO_BINLOG so the statements never reach the binlog and
+// leave errant GTIDs on the replica, histograms are built for the configured
+// columns, and the result is verified against mysql.innodb_table_stats and
+// information_schema.COLUMN_STATISTICS. Each table is checkpointed in State,
+// so an interrupted run resumes where it stopped.
+type StatisticsRefresh struct {
+	Connect Connector
+	State   workflow.State
+	Config  workflow.StatisticsConfig
+	Logger  *log.Logger
+}
+
+// analyzeMessage is one row of ANALYZE TABLE output.
+type analyzeMessage struct {
+	Table string
+	Type  string
+	Text  string
+}
+
+// Run analyzes the replica's tables, builds the configured histograms and
+// verifies statistics freshness. Stale statistics and histograms that could
+// not be built are WARNs; a failing ANALYZE TABLE is a BLOCK.
+func (s *StatisticsRefresh) Run(ctx context.Context, replica string) (Summary, []Finding, error) {
+	var summary Summary
+	findings := []Finding{}
+	replica = strings.TrimSpace(replica)
+	switch {
+	case replica == "":
+		return Summary{Block: 1}, []Finding{{Severity: SeverityBlock, Message: "replica is required"}}, nil
+	case s.Connect == nil:
+		return Summary{Block: 1}, []Finding{failureFinding(failure.Config("statistics refresh requires a connector"))}, nil
+	case s.State == nil:
+		return Summary{Block: 1}, []Finding{failureFinding(failure.Config("state is required"))}, nil
+	}
+	add := func(f Finding) {
+		findings = append(findings, f)
+		applySummary(&summary, []Finding{f})
+	}
+
+	q, err := s.Connect(ctx, replica)
+	if err != nil {
+		return appendFailure(summary, findings, failure.Inspect(replica, "failed to connect", err))
+	}
+	tables := s.Config.Tables
+	if len(tables) == 0 {
+		if tables, err = listStatisticsTables(ctx, q); err != nil {
+			return appendFailure(summary, findings, failure.Inspect(replica, "failed to list tables", err))
+		}
+	}
+
+	analyzed, resumed := 0, 0
+	for _, table := range tables {
+		if ok, _ := getBool(s.State, analyzedKey(replica, table)); ok {
+			resumed++
+			continue
+		}
+		s.logger().Printf("analyzing %s on %s", table, replica)
+		messages, err := analyze(ctx, q, fmt.Sprintf("ANALYZE NO_WRITE_TO_BINLOG TABLE %s", quoteTable(table)))
+		if err == nil {
+			err = analyzeError(messages)
+		}
+		if err != nil {
+			return appendFailure(summary, findings, failure.Act(replica, "failed to analyze "+table, err))
+		}
+		setBool(s.State, analyzedKey(replica, table), true)
+		analyzed++
+	}
+	add(Finding{Severity: SeverityInfo, Message: fmt.Sprintf("statistics rebuilt on %d tables", analyzed+resumed), Meta: map[string]interface{}{"replica": replica, "analyzed": analyzed, "already_analyzed": resumed}})
+
+	for _, h := range s.Config.Histograms {
+		if ok, _ := getBool(s.State, histogramKey(replica, h.Table)); ok {
+			continue
+		}
+		buckets := h.Buckets
+		if buckets <= 0 {
+			buckets = defaultHistogramBuckets
+		}
+		columns := make([]string, 0, len(h.Columns))
+		for _, column := range h.Columns {
+			columns = append(columns, quoteIdentifier(column))
+		}
+		s.logger().Printf("building histograms on %s(%s) on %s", h.Table, strings.Join(h.Columns, ", "), replica)
+		messages, err := analyze(ctx, q, fmt.Sprintf("ANALYZE NO_WRITE_TO_BINLOG TABLE %s UPDATE HISTOGRAM ON %s WITH %d BUCKETS", quoteTable(h.Table), strings.Join(columns, ", "), buckets))
+		if err != nil {
+			return appendFailure(summary, findings, failure.Act(replica, "failed to build histograms on "+h.Table, err))
+		}
+		if err := analyzeError(messages); err != nil {
+			// A column covered by a single-part unique index, or of an
+			// unsupported type, cannot get a histogram; the rest of the
+			// rebuild is still useful.
+			add(Finding{Severity: SeverityWarn, Message: fmt.Sprintf("histograms on %s were not built: %v", h.Table, err), Meta: map[string]interface{}{"replica": replica, "table": h.Table, "columns": h.Columns}})
+			continue
+		}
+		setBool(s.State, histogramKey(replica, h.Table), true)
+		add(Finding{Severity: SeverityInfo, Message: fmt.Sprintf("histograms built on %s", h.Table), Meta: map[string]interface{}{"replica": replica, "table": h.Table, "columns": h.Columns, "buckets": buckets}})
+	}
+
+	for _, f := range s.verify(ctx, q, replica, tables) {
+		add(f)
+	}
+	return summary, findings, nil
+}
+
+// verify reports tables whose persistent statistics are missing or older than
+// the allowed age, and configured histograms the server does not have.
+func (s *StatisticsRefresh) verify(ctx context.Context, q Querier, replica string, tables []string) []Finding {
+	maxAge := s.Config.MaxAge
+	if maxAge <= 0 {
+		maxAge = DefaultStatisticsMaxAge
+	}
+	ages, err := statisticsAges(ctx, q)
+	if err != nil {
+		return []Finding{{Severity: SeverityWarn, Message: fmt.Sprintf("unable to verify statistics freshness: %v", err), Meta: map[string]interface{}{"replica": replica}}}
+	}
+	stale := []string{}
+	for _, table := range tables {
+		age, ok := ages[strings.ToLower(table)]
+		if !ok || age > maxAge {
+			stale = append(stale, table)
+		}
+	}
+	findings := []Finding{}
+	if len(stale) > 0 {
+		findings = append(findings, Finding{Severity: SeverityWarn, Message: fmt.Sprintf("%d tables have statistics missing or older than %s: %s", len(stale), maxAge, strings.Join(stale, ", ")), Meta: map[string]interface{}{"replica": replica, "stale": stale, "max_age": maxAge.String()}})
+	}
+
+	if len(s.Config.Histograms) > 0 {
+		present, err := histogramColumns(ctx, q)
+		if err != nil {
+			return append(findings, Finding{Severity: SeverityWarn, Message: fmt.Sprintf("unable to verify histograms: %v", err), Meta: map[string]interface{}{"replica": replica}})
+		}
+		missing := []string{}
+		for _, h := range s.Config.Histograms {
+			// Histograms that failed to build were already reported.
+			if ok, _ := getBool(s.State, histogramKey(replica, h.Table)); !ok {
+				continue
+			}
+			for _, column := range h.Columns {
+				name := h.Table + "." + column
+				if !present[strings.ToLower(name)] {
+					missing = append(missing, name)
+				}
+			}
+		}
+		if len(missing) > 0 {
+			findings = append(findings, Finding{Severity: SeverityWarn, Message: fmt.Sprintf("histograms missing on %s", strings.Join(missing, ", ")), Meta: map[string]interface{}{"replica": replica, "missing_histograms": missing}})
+		}
+	}
+	if len(findings) == 0 {
+		findings = append(findings, Finding{Severity: SeverityInfo, Message: fmt.Sprintf("statistics are fresh on %d tables", len(tables)), Meta: map[string]interface{}{"replica": replica, "max_age": maxAge.String(), "histograms": len(s.Config.Histograms)}})
+	}
+	return findings
+}
+
+func (s *StatisticsRefresh) logger() *log.Logger {
+	if s.Logger == nil {
+		return log.Default()
+	}
+	return s.Logger
+}
+
+func listStatisticsTables(ctx context.Context, q Querier) ([]string, error) {
+	rows, err := q.QueryContext(ctx, statisticsTablesQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	tables := []string{}
+	for rows.Next() {
+		var schema, name string
+		if err := rows.Scan(&schema, &name); err != nil {
+			return nil, err
+		}
+		tables = append(tables, schema+"."+name)
+	}
+	return tables, rows.Err()
+}
+
+// statisticsAges returns the age of each table's persistent statistics, keyed
+// by lower-case db.table. Partitions (t#p#p0) fold into their table with the
+// oldest partition's age.
+func statisticsAges(ctx context.Context, q Querier) (map[string]time.Duration, error) {
+	rows, err := q.QueryContext(ctx, statisticsAgeQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ages := map[string]time.Duration{}
+	for rows.Next() {
+		var schema, name string
+		var seconds int64
+		if err := rows.Scan(&schema, &name, &seconds); err != nil {
+			return nil, err
+		}
+		if i := strings.Index(name, "#"); i >= 0 {
+			name = name[:i]
+		}
+		key := strings.ToLower(schema + "." + name)
+		age := time.Duration(seconds) * time.Second
+		if current, ok := ages[key]; !ok || age > current {
+			ages[key] = age
+		}
+	}
+	return ages, rows.Err()
+}
+
+// histogramColumns returns the lower-case db.table.column names that have a
+// histogram.
+func histogramColumns(ctx context.Context, q Querier) (map[string]bool, error) {
+	rows, err := q.QueryContext(ctx, histogramsQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	present := map[string]bool{}
+	for rows.Next() {
+		var schema, table, column string
+		if err := rows.Scan(&schema, &table, &column); err != nil {
+			return nil, err
+		}
+		present[strings.ToLower(schema+"."+table+"."+column)] = true
+	}
+	return present, rows.Err()
+}
+
+// analyze runs an ANALYZE TABLE statement and returns its result rows.
+func analyze(ctx context.Context, q Querier, statement string) ([]analyzeMessage, error) {
+	rows, err := q.QueryContext(ctx, statement)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	messages := []analyzeMessage{}
+	for rows.Next() {
+		var m analyzeMessage
+		var op string
+		if err := rows.Scan(&m.Table, &op, &m.Type, &m.Text); err != nil {
+			return nil, err
+		}
+		messages = append(messages, m)
+	}
+	return messages, rows.Err()
+}
+
+// analyzeError joins the error rows ANALYZE TABLE reports; the statement
+// itself succeeds even when a table could not be analyzed.
+func analyzeError(messages []analyzeMessage) error {
+	errs := []string{}
+	for _, m := range messages {
+		if strings.EqualFold(m.Type, "error") {
+			errs = append(errs, m.Text)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(errs, "; "))
+}
+
+// quoteTable quotes a db.table name for use in a statement.
+func quoteTable(table string) string {
+	if i := strings.Index(table, "."); i >= 0 {
+		return quoteIdentifier(table[:i]) + "." + quoteIdentifier(table[i+1:])
+	}
+	return quoteIdentifier(table)
+}
+
+func quoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(strings.TrimSpace(name), "`", "``") + "`"
+}
+
+func analyzedKey(replica string, table string) string {
+	return fmt.Sprintf("statistics:%s:analyzed:%s", replica, table)
+}
+
+func histogramKey(replica string, table string) string {
+	return fmt.Sprintf("statistics:%s:histogram:%s", replica, table)
+}
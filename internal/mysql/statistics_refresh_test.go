@@ -0,0 +1,99 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"migratorx/internal/workflow"
+)
+
+var analyzeColumns = []string{"Table", "Op", "Msg_type", "Msg_text"}
+
+func TestStatisticsRefresh_AnalyzesTablesAndBuildsHistograms(t *testing.T) {
+	db := openFakeDB(t,
+		fakeResponse{match: "information_schema.TABLES", columns: []string{"TABLE_SCHEMA", "TABLE_NAME"}, rows: [][]driver.Value{{"shop", "orders"}, {"shop", "events"}}},
+		fakeResponse{match: "UPDATE HISTOGRAM", columns: analyzeColumns, rows: [][]driver.Value{{"shop.orders", "histogram", "status", "Histogram statistics created for column 'status'."}}},
+		fakeResponse{match: "ANALYZE NO_WRITE_TO_BINLOG TABLE", columns: analyzeColumns, rows: [][]driver.Value{{"shop.orders", "analyze", "status", "OK"}}},
+		fakeResponse{match: "innodb_table_stats", columns: []string{"database_name", "table_name", "age"}, rows: [][]driver.Value{
+			{"shop", "orders", int64(5)},
+			{"shop", "events#p#p0", int64(4)},
+			{"shop", "events#p#p1", int64(7200)},
+		}},
+		fakeResponse{match: "COLUMN_STATISTICS", columns: []string{"SCHEMA_NAME", "TABLE_NAME", "COLUMN_NAME"}, rows: [][]driver.Value{{"shop", "orders", "status"}}},
+	)
+	state := workflow.NewMemoryState()
+	refresh := &StatisticsRefresh{
+		Connect: fakeConnector(db),
+		State:   state,
+		Config:  workflow.StatisticsConfig{Histograms: []workflow.HistogramConfig{{Table: "shop.orders", Columns: []string{"status"}}}},
+		Logger:  log.New(io.Discard, "", 0),
+	}
+
+	summary, findings, err := refresh.Run(context.Background(), "replica-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Block != 0 || summary.Warn != 1 {
+		t.Fatalf("expected only the stale partition to warn, got %+v %+v", summary, findings)
+	}
+	if findings[0].Meta["analyzed"] != 2 || findings[1].Meta["buckets"] != defaultHistogramBuckets {
+		t.Fatalf("expected both tables analyzed and a default-size histogram, got %+v", findings)
+	}
+	if stale := findings[2].Meta["stale"].([]string); len(stale) != 1 || stale[0] != "shop.events" {
+		t.Fatalf("expected the partitioned table's oldest partition to count, got %+v", findings[2])
+	}
+
+	_, findings, _ = refresh.Run(context.Background(), "replica-1")
+	if findings[0].Meta["already_analyzed"] != 2 {
+		t.Fatalf("expected a re-run to resume from checkpoints, got %+v", findings[0])
+	}
+}
+
+func TestStatisticsRefresh_HistogramErrorWarns(t *testing.T) {
+	db := openFakeDB(t,
+		fakeResponse{match: "UPDATE HISTOGRAM", columns: analyzeColumns, rows: [][]driver.Value{{"shop.orders", "histogram", "Error", "The column 'id' is covered by a single-part unique index."}}},
+		fakeResponse{match: "ANALYZE NO_WRITE_TO_BINLOG TABLE", columns: analyzeColumns, rows: [][]driver.Value{{"shop.orders", "analyze", "status", "OK"}}},
+		fakeResponse{match: "innodb_table_stats", columns: []string{"database_name", "table_name", "age"}, rows: [][]driver.Value{{"shop", "orders", int64(1)}}},
+		fakeResponse{match: "COLUMN_STATISTICS", columns: []string{"SCHEMA_NAME", "TABLE_NAME", "COLUMN_NAME"}},
+	)
+	refresh := &StatisticsRefresh{
+		Connect: fakeConnector(db),
+		State:   workflow.NewMemoryState(),
+		Config: workflow.StatisticsConfig{
+			Tables:     []string{"shop.orders"},
+			Histograms: []workflow.HistogramConfig{{Table: "shop.orders", Columns: []string{"id"}, Buckets: 16}},
+			MaxAge:     time.Minute,
+		},
+		Logger: log.New(io.Discard, "", 0),
+	}
+	summary, findings, err := refresh.Run(context.Background(), "replica-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Warn != 1 || !strings.Contains(findings[1].Message, "single-part unique index") {
+		t.Fatalf("expected one WARN carrying the server's reason, got %+v", findings)
+	}
+}
+
+func TestStatisticsRefresh_AnalyzeErrorBlocks(t *testing.T) {
+	db := openFakeDB(t, fakeResponse{match: "ANALYZE", columns: analyzeColumns, rows: [][]driver.Value{{"shop.orders", "analyze", "Error", "Table 'shop.orders' doesn't exist"}}})
+	refresh := &StatisticsRefresh{Connect: fakeConnector(db), State: workflow.NewMemoryState(), Config: workflow.StatisticsConfig{Tables: []string{"shop.orders"}}, Logger: log.New(io.Discard, "", 0)}
+	summary, findings, err := refresh.Run(context.Background(), "replica-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Block != 1 || findings[0].Meta["cause"] != "action" {
+		t.Fatalf("expected an action BLOCK, got %+v", findings)
+	}
+}
+
+func TestQuoteTable(t *testing.T) {
+	if got := quoteTable("shop.odd`name"); got != "`shop`.`odd``name`" {
+		t.Fatalf("unexpected quoting %q", got)
+	}
+}
@@ -0,0 +1,160 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"migratorx/internal/workflow"
+)
+
+// DefaultLagPollInterval is how often a paused inspection re-checks replica lag.
+const DefaultLagPollInterval = 5 * time.Second
+
+// Throttle limits how hard live inspections press on a server.
+// QueriesPerSecond and MaxConcurrency of zero mean unlimited. When Lag and
+// MaxLag are set, queries pause while the reported replica lag exceeds MaxLag.
+type Throttle struct {
+	QueriesPerSecond float64
+	MaxConcurrency   int
+	MaxLag           time.Duration
+	Lag              func(ctx context.Context) (time.Duration, error)
+	PollInterval     time.Duration
+	Logger           *log.Logger
+}
+
+// ThrottleFromPlan builds a Throttle from the plan's inspection limits. lag
+// reports the current replica lag; it may be nil when no replica is involved.
+func ThrottleFromPlan(cfg workflow.InspectionConfig, lag func(ctx context.Context) (time.Duration, error), logger *log.Logger) Throttle {
+	return Throttle{
+		QueriesPerSecond: cfg.MaxQPS,
+		MaxConcurrency:   cfg.MaxConcurrency,
+		MaxLag:           cfg.MaxReplicaLag,
+		Lag:              lag,
+		Logger:           logger,
+	}
+}
+
+// ThrottledQuerier applies a Throttle to every statement sent through it.
+// Concurrency is held only while QueryContext/ExecContext run, not while the
+// caller iterates rows.
+type ThrottledQuerier struct {
+	conn     Querier
+	throttle Throttle
+	sem      chan struct{}
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// NewThrottledQuerier wraps conn with the given limits.
+func NewThrottledQuerier(conn Querier, throttle Throttle) *ThrottledQuerier {
+	if throttle.PollInterval <= 0 {
+		throttle.PollInterval = DefaultLagPollInterval
+	}
+	if throttle.Logger == nil {
+		throttle.Logger = log.New(io.Discard, "", 0)
+	}
+	q := &ThrottledQuerier{conn: conn, throttle: throttle}
+	if throttle.MaxConcurrency > 0 {
+		q.sem = make(chan struct{}, throttle.MaxConcurrency)
+	}
+	return q
+}
+
+func (q *ThrottledQuerier) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	release, err := q.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return q.conn.QueryContext(ctx, query, args...)
+}
+
+func (q *ThrottledQuerier) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	release, err := q.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return q.conn.ExecContext(ctx, query, args...)
+}
+
+func (q *ThrottledQuerier) acquire(ctx context.Context) (func(), error) {
+	if err := q.waitForLag(ctx); err != nil {
+		return nil, err
+	}
+	if q.sem != nil {
+		select {
+		case q.sem <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	release := func() {
+		if q.sem != nil {
+			<-q.sem
+		}
+	}
+	if err := q.waitForRate(ctx); err != nil {
+		release()
+		return nil, err
+	}
+	return release, nil
+}
+
+// waitForLag blocks while replica lag is above MaxLag. A lag read failure is
+// returned rather than ignored so scanning never continues blind.
+func (q *ThrottledQuerier) waitForLag(ctx context.Context) error {
+	if q.throttle.Lag == nil || q.throttle.MaxLag <= 0 {
+		return nil
+	}
+	for {
+		lag, err := q.throttle.Lag(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to read replica lag for load guard: %w", err)
+		}
+		if lag <= q.throttle.MaxLag {
+			return nil
+		}
+		q.throttle.Logger.Printf("replica lag %s exceeds %s; pausing inspection", lag, q.throttle.MaxLag)
+		if err := sleepContext(ctx, q.throttle.PollInterval); err != nil {
+			return err
+		}
+	}
+}
+
+// waitForRate spaces statements evenly at QueriesPerSecond.
+func (q *ThrottledQuerier) waitForRate(ctx context.Context) error {
+	if q.throttle.QueriesPerSecond <= 0 {
+		return nil
+	}
+	interval := time.Duration(float64(time.Second) / q.throttle.QueriesPerSecond)
+	q.mu.Lock()
+	now := time.Now()
+	if q.next.Before(now) {
+		q.next = now
+	}
+	wait := q.next.Sub(now)
+	q.next = q.next.Add(interval)
+	q.mu.Unlock()
+	return sleepContext(ctx, wait)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,99 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type slowQuerier struct {
+	active int32
+	peak   int32
+}
+
+func (s *slowQuerier) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	n := atomic.AddInt32(&s.active, 1)
+	for {
+		p := atomic.LoadInt32(&s.peak)
+		if n <= p || atomic.CompareAndSwapInt32(&s.peak, p, n) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	atomic.AddInt32(&s.active, -1)
+	return nil, nil
+}
+
+func (s *slowQuerier) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return nil, nil
+}
+
+func TestThrottledQuerier_LimitsConcurrency(t *testing.T) {
+	conn := &slowQuerier{}
+	q := NewThrottledQuerier(conn, Throttle{MaxConcurrency: 2})
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.QueryContext(context.Background(), "SELECT 1")
+		}()
+	}
+	wg.Wait()
+	if peak := atomic.LoadInt32(&conn.peak); peak > 2 {
+		t.Fatalf("expected at most 2 concurrent queries, saw %d", peak)
+	}
+}
+
+func TestThrottledQuerier_LimitsRate(t *testing.T) {
+	q := NewThrottledQuerier(&fakeQuerier{}, Throttle{QueriesPerSecond: 100})
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if _, err := q.ExecContext(context.Background(), "SELECT 1"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Fatalf("expected 5 queries at 100/s to take at least 40ms, took %s", elapsed)
+	}
+}
+
+func TestThrottledQuerier_PausesWhileLagging(t *testing.T) {
+	lags := []time.Duration{30 * time.Second, 20 * time.Second, time.Second}
+	calls := 0
+	q := NewThrottledQuerier(&fakeQuerier{}, Throttle{
+		MaxLag:       10 * time.Second,
+		PollInterval: time.Millisecond,
+		Lag: func(ctx context.Context) (time.Duration, error) {
+			lag := lags[calls]
+			calls++
+			return lag, nil
+		},
+	})
+	if _, err := q.ExecContext(context.Background(), "SELECT 1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected inspection to wait for lag to recover, lag read %d times", calls)
+	}
+}
+
+func TestThrottledQuerier_LagErrorStopsInspection(t *testing.T) {
+	conn := &fakeQuerier{}
+	q := NewThrottledQuerier(conn, Throttle{
+		MaxLag: time.Second,
+		Lag: func(ctx context.Context) (time.Duration, error) {
+			return 0, errors.New("replica unreachable")
+		},
+	})
+	if _, err := q.QueryContext(context.Background(), "SELECT 1"); err == nil {
+		t.Fatalf("expected lag read failure to stop the query")
+	}
+	if len(conn.queries) != 0 {
+		t.Fatalf("expected no query to reach the server, got %v", conn.queries)
+	}
+}
@@ -0,0 +1,250 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"migratorx/internal/failure"
+	"migratorx/internal/workflow"
+)
+
+// snapshotVariables are the variables whose value the upgrade itself should
+// not change; a change is a WARN.
+var snapshotVariables = []string{
+	"sql_mode",
+	"character_set_server",
+	"collation_server",
+	"time_zone",
+	"lower_case_table_names",
+	"explicit_defaults_for_timestamp",
+	"transaction_isolation",
+	"tx_isolation",
+	"default_authentication_plugin",
+	"innodb_file_per_table",
+	"innodb_autoinc_lock_mode",
+	"max_allowed_packet",
+	"binlog_format",
+	"gtid_mode",
+	"log_bin",
+	"read_only",
+}
+
+// removedSQLModes are sql_mode flags 8.0 no longer accepts; their disappearance
+// after an upgrade is expected.
+var removedSQLModes = map[string]bool{
+	"NO_AUTO_CREATE_USER": true,
+	"DB2":                 true,
+	"MAXDB":               true,
+	"MSSQL":               true,
+	"MYSQL323":            true,
+	"MYSQL40":             true,
+	"ORACLE":              true,
+	"POSTGRESQL":          true,
+	"NO_FIELD_OPTIONS":    true,
+	"NO_KEY_OPTIONS":      true,
+	"NO_TABLE_OPTIONS":    true,
+}
+
+// ServerSnapshot records a server's key variables and the number of schema
+// objects per schema and kind (tables, views, routines, triggers, events).
+type ServerSnapshot struct {
+	Variables map[string]string
+	Objects   map[string]map[string]int
+}
+
+// SnapshotInspector captures a ServerSnapshot of a host.
+type SnapshotInspector interface {
+	ServerSnapshot(ctx context.Context, host string) (ServerSnapshot, error)
+}
+
+// snapshotBefore records the replica's baseline snapshot once, before the
+// upgrade runs.
+func (o *UpgradeOrchestrator) snapshotBefore(ctx context.Context, replica string) error {
+	if _, ok := loadSnapshot(o.State, snapshotKey(replica, "before")); ok {
+		return nil
+	}
+	snapshot, err := o.Snapshots.ServerSnapshot(ctx, replica)
+	if err != nil {
+		return failure.Inspect(replica, "failed to snapshot replica before upgrade", err)
+	}
+	storeSnapshot(o.State, snapshotKey(replica, "before"), snapshot)
+	return nil
+}
+
+// snapshotAfter records the post-upgrade snapshot and diffs it against the
+// baseline. The upgrade has already happened, so failing to take it is a
+// WARN rather than a BLOCK.
+func (o *UpgradeOrchestrator) snapshotAfter(ctx context.Context, replica string) []Finding {
+	before, ok := loadSnapshot(o.State, snapshotKey(replica, "before"))
+	if !ok {
+		return nil
+	}
+	after, err := o.Snapshots.ServerSnapshot(ctx, replica)
+	if err != nil {
+		return []Finding{{Severity: SeverityWarn, Message: fmt.Sprintf("unable to snapshot replica after upgrade: %v", err), Meta: map[string]interface{}{"replica": replica}}}
+	}
+	storeSnapshot(o.State, snapshotKey(replica, "after"), after)
+	findings := diffSnapshots(replica, before, after)
+	if len(findings) == 0 {
+		findings = append(findings, Finding{Severity: SeverityInfo, Message: "upgrade changed no key variables or schema object counts", Meta: map[string]interface{}{"replica": replica, "variables": len(before.Variables), "schemas": len(before.Objects)}})
+	}
+	return findings
+}
+
+func snapshotKey(replica string, phase string) string {
+	return fmt.Sprintf("replica_upgrade:%s:snapshot:%s", replica, phase)
+}
+
+// loadSnapshot reads a snapshot stored in state.
+func loadSnapshot(state workflow.State, key string) (ServerSnapshot, bool) {
+	var snapshot ServerSnapshot
+	ok, err := workflow.GetArtifact(state, key, &snapshot)
+	return snapshot, ok && err == nil
+}
+
+func storeSnapshot(state workflow.State, key string, snapshot ServerSnapshot) {
+	workflow.PutArtifact(state, key, snapshot)
+}
+
+// diffSnapshots reports what the upgrade changed: a WARN per key variable
+// whose value changed and per schema object kind whose count dropped.
+// Variables missing on either side and sql_mode flags the target removed are
+// not reported, since the removed-variable audit covers them.
+func diffSnapshots(replica string, before ServerSnapshot, after ServerSnapshot) []Finding {
+	findings := []Finding{}
+	names := make([]string, 0, len(before.Variables))
+	for name := range before.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		was := before.Variables[name]
+		now, ok := after.Variables[name]
+		if !ok {
+			continue
+		}
+		if name == "sql_mode" {
+			was = normalizeSQLMode(was)
+			now = normalizeSQLMode(now)
+		}
+		if !strings.EqualFold(was, now) {
+			findings = append(findings, Finding{
+				Severity: SeverityWarn,
+				Message:  fmt.Sprintf("upgrade changed %s on %s from %q to %q", name, replica, was, now),
+				Meta:     map[string]interface{}{"replica": replica, "variable": name, "before": was, "after": now},
+			})
+		}
+	}
+
+	schemas := make([]string, 0, len(before.Objects))
+	for schema := range before.Objects {
+		schemas = append(schemas, schema)
+	}
+	sort.Strings(schemas)
+	for _, schema := range schemas {
+		kinds := make([]string, 0, len(before.Objects[schema]))
+		for kind := range before.Objects[schema] {
+			kinds = append(kinds, kind)
+		}
+		sort.Strings(kinds)
+		for _, kind := range kinds {
+			was := before.Objects[schema][kind]
+			now := after.Objects[schema][kind]
+			if now < was {
+				findings = append(findings, Finding{
+					Severity: SeverityWarn,
+					Message:  fmt.Sprintf("upgrade dropped %d %s in %s on %s (%d before, %d after)", was-now, kind, schema, replica, was, now),
+					Meta:     map[string]interface{}{"replica": replica, "schema": schema, "kind": kind, "before": was, "after": now},
+				})
+			}
+		}
+	}
+	return findings
+}
+
+// normalizeSQLMode sorts sql_mode flags and drops those removed in 8.0.
+func normalizeSQLMode(mode string) string {
+	flags := []string{}
+	for _, flag := range strings.Split(mode, ",") {
+		flag = strings.ToUpper(strings.TrimSpace(flag))
+		if flag == "" || removedSQLModes[flag] {
+			continue
+		}
+		flags = append(flags, flag)
+	}
+	sort.Strings(flags)
+	return strings.Join(flags, ",")
+}
+
+// snapshotObjectQueries count schema objects per schema; each returns schema,
+// kind and count.
+var snapshotObjectQueries = []string{
+	"SELECT TABLE_SCHEMA, IF(TABLE_TYPE = 'VIEW', 'views', 'tables'), COUNT(*) FROM information_schema.TABLES WHERE TABLE_SCHEMA NOT IN (" + systemSchemaList + ") GROUP BY 1, 2",
+	"SELECT ROUTINE_SCHEMA, 'routines', COUNT(*) FROM information_schema.ROUTINES WHERE ROUTINE_SCHEMA NOT IN (" + systemSchemaList + ") GROUP BY 1",
+	"SELECT TRIGGER_SCHEMA, 'triggers', COUNT(*) FROM information_schema.TRIGGERS WHERE TRIGGER_SCHEMA NOT IN (" + systemSchemaList + ") GROUP BY 1",
+	"SELECT EVENT_SCHEMA, 'events', COUNT(*) FROM information_schema.EVENTS WHERE EVENT_SCHEMA NOT IN (" + systemSchemaList + ") GROUP BY 1",
+}
+
+const systemSchemaList = "'mysql', 'sys', 'performance_schema', 'information_schema'"
+
+// InformationSchemaSnapshotInspector implements SnapshotInspector with SHOW
+// GLOBAL VARIABLES and information_schema object counts, excluding system
+// schemas.
+type InformationSchemaSnapshotInspector struct {
+	Connect Connector
+}
+
+func (i *InformationSchemaSnapshotInspector) ServerSnapshot(ctx context.Context, host string) (ServerSnapshot, error) {
+	snapshot := ServerSnapshot{Variables: map[string]string{}, Objects: map[string]map[string]int{}}
+	if i.Connect == nil {
+		return snapshot, fmt.Errorf("snapshot inspector requires a connector")
+	}
+	q, err := i.Connect(ctx, host)
+	if err != nil {
+		return snapshot, err
+	}
+	rows, err := q.QueryContext(ctx, "SHOW GLOBAL VARIABLES WHERE Variable_name IN ('"+strings.Join(snapshotVariables, "', '")+"')")
+	if err != nil {
+		return snapshot, fmt.Errorf("failed to read variables: %w", err)
+	}
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			rows.Close()
+			return snapshot, err
+		}
+		snapshot.Variables[name] = value
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return snapshot, err
+	}
+
+	for _, query := range snapshotObjectQueries {
+		rows, err := q.QueryContext(ctx, query)
+		if err != nil {
+			return snapshot, fmt.Errorf("failed to count schema objects: %w", err)
+		}
+		for rows.Next() {
+			var schema, kind string
+			var count int
+			if err := rows.Scan(&schema, &kind, &count); err != nil {
+				rows.Close()
+				return snapshot, err
+			}
+			if snapshot.Objects[schema] == nil {
+				snapshot.Objects[schema] = map[string]int{}
+			}
+			snapshot.Objects[schema][kind] += count
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return snapshot, err
+		}
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,124 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log"
+	"strings"
+	"testing"
+
+	"migratorx/internal/workflow"
+)
+
+// snapshotSequence returns snapshots in order; the last one repeats.
+type snapshotSequence struct {
+	snapshots []ServerSnapshot
+	err       error
+	calls     int
+}
+
+func (s *snapshotSequence) ServerSnapshot(ctx context.Context, host string) (ServerSnapshot, error) {
+	if s.err != nil && s.calls > 0 {
+		return ServerSnapshot{}, s.err
+	}
+	i := s.calls
+	if i >= len(s.snapshots) {
+		i = len(s.snapshots) - 1
+	}
+	s.calls++
+	return s.snapshots[i], nil
+}
+
+func TestUpgradeOrchestrator_DiffsSnapshotsAroundUpgrade(t *testing.T) {
+	before := ServerSnapshot{
+		Variables: map[string]string{"sql_mode": "STRICT_TRANS_TABLES,NO_AUTO_CREATE_USER", "character_set_server": "latin1", "tx_isolation": "REPEATABLE-READ"},
+		Objects:   map[string]map[string]int{"app": {"tables": 12, "routines": 3}, "reports": {"views": 2}},
+	}
+	after := ServerSnapshot{
+		Variables: map[string]string{"sql_mode": "STRICT_TRANS_TABLES", "character_set_server": "utf8mb4", "transaction_isolation": "REPEATABLE-READ"},
+		Objects:   map[string]map[string]int{"app": {"tables": 12, "routines": 2, "events": 1}},
+	}
+	snapshots := &snapshotSequence{snapshots: []ServerSnapshot{before, after}}
+	state := workflow.NewMemoryState()
+	o := NewUpgradeOrchestrator(&fakeInspector{status: ReplicationStatus{IOThreadRunning: true, SQLThreadRunning: true}}, &fakeActions{}, state, "mysql-primary", log.New(io.Discard, "", 0))
+	o.Snapshots = snapshots
+
+	summary, findings, err := o.Run(context.Background(), "replica-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var warnings []string
+	for _, f := range findings {
+		if f.Severity == SeverityWarn {
+			warnings = append(warnings, f.Message)
+		}
+	}
+	if summary.Warn != 3 || len(warnings) != 3 {
+		t.Fatalf("expected a changed charset and two dropped object kinds, got %v", warnings)
+	}
+	if !strings.Contains(warnings[0], "character_set_server") || !strings.Contains(warnings[1], "1 routines in app") || !strings.Contains(warnings[2], "2 views in reports") {
+		t.Fatalf("unexpected warnings: %v", warnings)
+	}
+	if _, ok := loadSnapshot(state, snapshotKey("replica-1", "before")); !ok {
+		t.Fatalf("expected the baseline snapshot in state")
+	}
+	if stored, ok := loadSnapshot(state, snapshotKey("replica-1", "after")); !ok || stored.Variables["character_set_server"] != "utf8mb4" {
+		t.Fatalf("expected the post-upgrade snapshot in state, got %+v", stored)
+	}
+}
+
+func TestUpgradeOrchestrator_KeepsBaselineAcrossRetries(t *testing.T) {
+	baseline := ServerSnapshot{Variables: map[string]string{"time_zone": "SYSTEM"}}
+	state := workflow.NewMemoryState()
+	storeSnapshot(state, snapshotKey("replica-1", "before"), baseline)
+	snapshots := &snapshotSequence{snapshots: []ServerSnapshot{{Variables: map[string]string{"time_zone": "SYSTEM"}}}}
+	o := NewUpgradeOrchestrator(&fakeInspector{status: ReplicationStatus{IOThreadRunning: true, SQLThreadRunning: true}}, &fakeActions{}, state, "mysql-primary", log.New(io.Discard, "", 0))
+	o.Snapshots = snapshots
+
+	summary, findings, _ := o.Run(context.Background(), "replica-1")
+	if snapshots.calls != 1 || summary.Warn != 0 {
+		t.Fatalf("expected only the post-upgrade snapshot to be taken, got %d calls and %+v", snapshots.calls, findings)
+	}
+}
+
+func TestUpgradeOrchestrator_SnapshotFailures(t *testing.T) {
+	o := NewUpgradeOrchestrator(&fakeInspector{status: ReplicationStatus{IOThreadRunning: true, SQLThreadRunning: true}}, &fakeActions{}, workflow.NewMemoryState(), "mysql-primary", log.New(io.Discard, "", 0))
+	o.Snapshots = &snapshotSequence{snapshots: []ServerSnapshot{{}}, err: errors.New("connection refused")}
+	summary, _, _ := o.Run(context.Background(), "replica-1")
+	if summary.Block != 0 || summary.Warn != 1 {
+		t.Fatalf("expected a failed post-upgrade snapshot to warn, got %+v", summary)
+	}
+
+	actions := &fakeActions{}
+	o = NewUpgradeOrchestrator(&fakeInspector{status: ReplicationStatus{IOThreadRunning: true, SQLThreadRunning: true}}, actions, workflow.NewMemoryState(), "mysql-primary", log.New(io.Discard, "", 0))
+	o.Snapshots = &failingSnapshots{}
+	summary, findings, _ := o.Run(context.Background(), "replica-1")
+	if summary.Block != 1 || findings[len(findings)-1].Meta["cause"] != "inspector" || actions.upgradeCalls != 0 {
+		t.Fatalf("expected a failed baseline to block before the upgrade, got %+v", findings)
+	}
+}
+
+type failingSnapshots struct{}
+
+func (failingSnapshots) ServerSnapshot(ctx context.Context, host string) (ServerSnapshot, error) {
+	return ServerSnapshot{}, errors.New("access denied")
+}
+
+func TestInformationSchemaSnapshotInspector(t *testing.T) {
+	db := openFakeDB(t,
+		fakeResponse{match: "SHOW GLOBAL VARIABLES", columns: []string{"Variable_name", "Value"}, rows: [][]driver.Value{{"sql_mode", "STRICT_TRANS_TABLES"}, {"time_zone", "UTC"}}},
+		fakeResponse{match: "information_schema.TABLES", columns: []string{"TABLE_SCHEMA", "kind", "COUNT(*)"}, rows: [][]driver.Value{{"app", "tables", int64(12)}, {"app", "views", int64(1)}}},
+		fakeResponse{match: "information_schema.ROUTINES", columns: []string{"ROUTINE_SCHEMA", "kind", "COUNT(*)"}, rows: [][]driver.Value{{"app", "routines", int64(3)}}},
+		fakeResponse{match: "information_schema.TRIGGERS", columns: []string{"TRIGGER_SCHEMA", "kind", "COUNT(*)"}},
+		fakeResponse{match: "information_schema.EVENTS", columns: []string{"EVENT_SCHEMA", "kind", "COUNT(*)"}},
+	)
+	snapshot, err := (&InformationSchemaSnapshotInspector{Connect: fakeConnector(db)}).ServerSnapshot(context.Background(), "replica-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if snapshot.Variables["time_zone"] != "UTC" || snapshot.Objects["app"]["tables"] != 12 || snapshot.Objects["app"]["routines"] != 3 {
+		t.Fatalf("unexpected snapshot: %+v", snapshot)
+	}
+}
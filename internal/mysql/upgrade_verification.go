@@ -0,0 +1,114 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"migratorx/internal/failure"
+)
+
+// Server upgrade states reported by an UpgradeStatusInspector.
+const (
+	ServerUpgradeCompleted  = "completed"
+	ServerUpgradeIncomplete = "incomplete"
+	ServerUpgradeUnknown    = "unknown"
+)
+
+// UpgradeStatus is what a replica reports after its upgrade: the running
+// version and whether the data dictionary and system tables were upgraded to
+// it (the server's own upgrade step in 8.0.16+, mysql_upgrade before).
+type UpgradeStatus struct {
+	Version       string
+	ServerUpgrade string
+	Detail        string
+}
+
+// UpgradeStatusInspector reads a replica's upgrade status.
+type UpgradeStatusInspector interface {
+	UpgradeStatus(ctx context.Context, replica string) (UpgradeStatus, error)
+}
+
+// verifyUpgrade confirms that RunUpgrade took effect before the upgrade is
+// checkpointed: the replica must report the target version and a completed
+// server upgrade. An unknown server upgrade state is a WARN, since older
+// servers do not expose it.
+func (o *UpgradeOrchestrator) verifyUpgrade(ctx context.Context, replica string) ([]Finding, error) {
+	status, err := o.Verifier.UpgradeStatus(ctx, replica)
+	if err != nil {
+		return nil, failure.Inspect(replica, "failed to verify upgrade", err)
+	}
+	if o.TargetVersion != "" && !versionMatches(status.Version, o.TargetVersion) {
+		return nil, failure.Act(replica, "upgrade did not take effect", fmt.Errorf("replica reports version %q, expected %s", status.Version, o.TargetVersion))
+	}
+	meta := map[string]interface{}{"replica": replica, "version": status.Version, "server_upgrade": status.ServerUpgrade}
+	if status.Detail != "" {
+		meta["detail"] = status.Detail
+	}
+	switch status.ServerUpgrade {
+	case ServerUpgradeCompleted:
+		return []Finding{{Severity: SeverityInfo, Message: fmt.Sprintf("upgrade completed; replica reports MySQL %s", status.Version), Meta: meta}}, nil
+	case ServerUpgradeIncomplete:
+		err := fmt.Errorf("data dictionary and system tables were not upgraded to %s", status.Version)
+		if status.Detail != "" {
+			err = fmt.Errorf("%v: %s", err, status.Detail)
+		}
+		return nil, failure.Act(replica, "server upgrade incomplete", err)
+	default:
+		return []Finding{
+			{Severity: SeverityInfo, Message: fmt.Sprintf("upgrade completed; replica reports MySQL %s", status.Version), Meta: meta},
+			{Severity: SeverityWarn, Message: "unable to confirm the data dictionary and system table upgrade; check the replica's error log", Meta: meta},
+		}, nil
+	}
+}
+
+// versionMatches reports whether a server version string such as
+// "8.0.36-log" is the target version or a release of it.
+func versionMatches(reported string, target string) bool {
+	reported = strings.TrimSpace(reported)
+	target = strings.TrimSpace(target)
+	if reported == target {
+		return true
+	}
+	return strings.HasPrefix(reported, target+".") || strings.HasPrefix(reported, target+"-")
+}
+
+// ErrorLogUpgradeInspector implements UpgradeStatusInspector with VERSION()
+// and the server upgrade messages in performance_schema.error_log (8.0.22+).
+// A server that started without upgrading had nothing to upgrade; servers
+// without the error_log table report ServerUpgradeUnknown.
+type ErrorLogUpgradeInspector struct {
+	Connect Connector
+}
+
+func (i *ErrorLogUpgradeInspector) UpgradeStatus(ctx context.Context, replica string) (UpgradeStatus, error) {
+	var status UpgradeStatus
+	if i.Connect == nil {
+		return status, fmt.Errorf("upgrade status inspector requires a connector")
+	}
+	q, err := i.Connect(ctx, replica)
+	if err != nil {
+		return status, err
+	}
+	if err := queryOne(ctx, q, "SELECT VERSION()", nil, &status.Version); err != nil {
+		return status, fmt.Errorf("failed to read server version: %w", err)
+	}
+	var message string
+	err = queryOne(ctx, q, "SELECT DATA FROM performance_schema.error_log WHERE DATA LIKE 'Server upgrade %' ORDER BY LOGGED DESC LIMIT 1", nil, &message)
+	switch {
+	case err == sql.ErrNoRows:
+		status.ServerUpgrade = ServerUpgradeCompleted
+		status.Detail = "no server upgrade was needed at startup"
+	case err != nil:
+		status.ServerUpgrade = ServerUpgradeUnknown
+		status.Detail = fmt.Sprintf("performance_schema.error_log is not readable: %v", err)
+	case strings.Contains(message, "completed"):
+		status.ServerUpgrade = ServerUpgradeCompleted
+		status.Detail = message
+	default:
+		status.ServerUpgrade = ServerUpgradeIncomplete
+		status.Detail = message
+	}
+	return status, nil
+}
@@ -0,0 +1,152 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log"
+	"testing"
+
+	"migratorx/internal/workflow"
+)
+
+type fakeUpgradeStatusInspector struct {
+	status UpgradeStatus
+	err    error
+}
+
+func (f *fakeUpgradeStatusInspector) UpgradeStatus(ctx context.Context, replica string) (UpgradeStatus, error) {
+	return f.status, f.err
+}
+
+func newVerifiedOrchestrator(actions *fakeActions, state workflow.State, verifier UpgradeStatusInspector) *UpgradeOrchestrator {
+	o := NewUpgradeOrchestrator(&fakeInspector{status: ReplicationStatus{IOThreadRunning: true, SQLThreadRunning: true}}, actions, state, "mysql-primary", log.New(io.Discard, "", 0))
+	o.Verifier = verifier
+	o.TargetVersion = "8.0"
+	return o
+}
+
+func TestUpgradeOrchestrator_VerifiesVersionBeforeCheckpoint(t *testing.T) {
+	actions := &fakeActions{}
+	state := workflow.NewMemoryState()
+	o := newVerifiedOrchestrator(actions, state, &fakeUpgradeStatusInspector{status: UpgradeStatus{Version: "5.7.44-log", ServerUpgrade: ServerUpgradeCompleted}})
+
+	summary, findings, err := o.Run(context.Background(), "replica-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	last := findings[len(findings)-1]
+	if summary.Block != 1 || last.Meta["cause"] != "action" {
+		t.Fatalf("expected the old version to block as an action failure, got %+v", findings)
+	}
+	if ok, _ := getBool(state, upgradedKey("replica-1")); ok {
+		t.Fatalf("expected the upgrade not to be checkpointed")
+	}
+	if actions.startCalls != 0 {
+		t.Fatalf("expected replication to stay stopped")
+	}
+
+	o.Verifier = &fakeUpgradeStatusInspector{status: UpgradeStatus{Version: "8.0.36", ServerUpgrade: ServerUpgradeCompleted}}
+	summary, findings, err = o.Run(context.Background(), "replica-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Block != 0 || actions.upgradeCalls != 2 || actions.startCalls != 1 {
+		t.Fatalf("expected the re-run to upgrade again and resume, got %+v", findings)
+	}
+	if ok, _ := getBool(state, upgradedKey("replica-1")); !ok {
+		t.Fatalf("expected the verified upgrade to be checkpointed")
+	}
+	if f := findings[len(findings)-2]; f.Meta["version"] != "8.0.36" {
+		t.Fatalf("expected the verified version in the upgrade finding, got %+v", f)
+	}
+}
+
+func TestUpgradeOrchestrator_IncompleteServerUpgradeBlocks(t *testing.T) {
+	actions := &fakeActions{}
+	state := workflow.NewMemoryState()
+	o := newVerifiedOrchestrator(actions, state, &fakeUpgradeStatusInspector{status: UpgradeStatus{Version: "8.0.36", ServerUpgrade: ServerUpgradeIncomplete, Detail: "Server upgrade from '50700' to '80036' started."}})
+
+	summary, findings, _ := o.Run(context.Background(), "replica-1")
+	if summary.Block != 1 || findings[len(findings)-1].Meta["cause"] != "action" {
+		t.Fatalf("expected an incomplete server upgrade to block, got %+v", findings)
+	}
+	if ok, _ := getBool(state, upgradedKey("replica-1")); ok {
+		t.Fatalf("expected the upgrade not to be checkpointed")
+	}
+}
+
+func TestUpgradeOrchestrator_UnknownServerUpgradeWarns(t *testing.T) {
+	actions := &fakeActions{}
+	state := workflow.NewMemoryState()
+	o := newVerifiedOrchestrator(actions, state, &fakeUpgradeStatusInspector{status: UpgradeStatus{Version: "8.0.21", ServerUpgrade: ServerUpgradeUnknown}})
+
+	summary, _, _ := o.Run(context.Background(), "replica-1")
+	if summary.Block != 0 || summary.Warn != 1 || actions.startCalls != 1 {
+		t.Fatalf("expected an unknown server upgrade to warn and continue, got %+v", summary)
+	}
+}
+
+func TestUpgradeOrchestrator_VerifierErrorBlocks(t *testing.T) {
+	actions := &fakeActions{}
+	state := workflow.NewMemoryState()
+	o := newVerifiedOrchestrator(actions, state, &fakeUpgradeStatusInspector{err: errors.New("connection refused")})
+
+	summary, findings, _ := o.Run(context.Background(), "replica-1")
+	if summary.Block != 1 || findings[len(findings)-1].Meta["cause"] != "inspector" {
+		t.Fatalf("expected an unreadable replica to block as an inspector failure, got %+v", findings)
+	}
+}
+
+func TestVersionMatches(t *testing.T) {
+	cases := []struct {
+		reported string
+		target   string
+		want     bool
+	}{
+		{"8.0.36", "8.0", true},
+		{"8.0.36-log", "8.0.36", true},
+		{"8.0", "8.0", true},
+		{"8.4.0", "8.0", false},
+		{"8.0.36", "8.0.3", false},
+		{"5.7.44", "8.0", false},
+	}
+	for _, c := range cases {
+		if got := versionMatches(c.reported, c.target); got != c.want {
+			t.Fatalf("versionMatches(%q, %q) = %v, want %v", c.reported, c.target, got, c.want)
+		}
+	}
+}
+
+func TestErrorLogUpgradeInspector(t *testing.T) {
+	db := openFakeDB(t,
+		fakeResponse{match: "VERSION()", columns: []string{"VERSION()"}, rows: [][]driver.Value{{"8.0.36"}}},
+		fakeResponse{match: "error_log", columns: []string{"DATA"}, rows: [][]driver.Value{{"Server upgrade from '50700' to '80036' completed."}}},
+	)
+	status, err := (&ErrorLogUpgradeInspector{Connect: fakeConnector(db)}).UpgradeStatus(context.Background(), "replica-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status.Version != "8.0.36" || status.ServerUpgrade != ServerUpgradeCompleted {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+
+	db = openFakeDB(t,
+		fakeResponse{match: "VERSION()", columns: []string{"VERSION()"}, rows: [][]driver.Value{{"8.0.36"}}},
+		fakeResponse{match: "error_log", columns: []string{"DATA"}, rows: [][]driver.Value{{"Server upgrade from '50700' to '80036' started."}}},
+	)
+	status, _ = (&ErrorLogUpgradeInspector{Connect: fakeConnector(db)}).UpgradeStatus(context.Background(), "replica-1")
+	if status.ServerUpgrade != ServerUpgradeIncomplete {
+		t.Fatalf("expected a started upgrade to be incomplete, got %+v", status)
+	}
+
+	db = openFakeDB(t,
+		fakeResponse{match: "VERSION()", columns: []string{"VERSION()"}, rows: [][]driver.Value{{"8.0.21"}}},
+		fakeResponse{match: "error_log", err: errors.New("Table 'performance_schema.error_log' doesn't exist")},
+	)
+	status, _ = (&ErrorLogUpgradeInspector{Connect: fakeConnector(db)}).UpgradeStatus(context.Background(), "replica-1")
+	if status.ServerUpgrade != ServerUpgradeUnknown {
+		t.Fatalf("expected a missing error_log to be unknown, got %+v", status)
+	}
+}
@@ -0,0 +1,204 @@
+// Package remediation maps finding codes to runbook steps for on-call engineers.
+package remediation
+
+import (
+	"strings"
+
+	"migratorx/internal/cdc"
+	"migratorx/internal/checks"
+	"migratorx/internal/mysql"
+	"migratorx/internal/workflow"
+)
+
+// Catalog maps a finding code to remediation text.
+type Catalog map[string]string
+
+// Default returns the built-in catalog. Informational codes have no entry.
+func Default() Catalog {
+	return Catalog{
+		checks.CodeCheckError:                "Inspect the check's error message; fix connectivity or inputs and re-run. A check error always blocks.",
+		checks.CodeCheckMessageMissing:       "A check emitted a finding without a message; report it as a bug in that check.",
+		checks.CodeCheckMetaInvalid:          "A check emitted a finding whose meta breaks the documented key contract; report it as a bug in that check. The finding itself is still reported.",
+		checks.CodeReadOnlyViolation:         "A check issued a write during a read-only phase; treat it as a bug in that check and do not proceed until it is fixed.",
+		checks.CodeArtifactStoreFailed:       "Check that the directory next to --state is writable and has space; the findings stand but their evidence was not kept.",
+		checks.CodeSchemaTableMissing:        "Create the table on the replica from the primary's DDL (SHOW CREATE TABLE) or rebuild the replica, then re-run validation.",
+		checks.CodeSchemaTableExtra:          "Confirm the extra replica table is intentional; drop it or add it to the primary before promotion.",
+		checks.CodeSchemaPKMissing:           "Add the primary key on the replica to match the primary; row-based replication and CDC rely on it.",
+		checks.CodeSchemaPKExtra:             "Align primary keys: either add the key on the primary or remove it from the replica.",
+		checks.CodeSchemaPKMismatch:          "Rebuild the replica table's primary key to match the primary's column order.",
+		checks.CodeSchemaColumnMissing:       "Add the column on the replica with the primary's definition, or replay the missed DDL.",
+		checks.CodeSchemaColumnExtra:         "Drop the extra replica column or apply the same DDL to the primary.",
+		checks.CodeSchemaColumnType:          "ALTER the replica column to the primary's type; check for implicit conversions introduced by the upgrade.",
+		checks.CodeSchemaColumnNullable:      "ALTER the replica column's NULL/NOT NULL to match the primary.",
+		checks.CodeSchemaColumnDefault:       "Compare defaults; 8.0 renders some defaults differently. Align them or allow the code in promotion.allow_warn_codes.",
+		checks.CodeSchemaColumnCollation:     "Convert the column to the primary's character set and collation, or pin collation_server on the replica.",
+		checks.CodeSchemaColumnGenerated:     "Re-create the replica's generated column with the primary's VIRTUAL/STORED kind and expression (ALTER TABLE ... MODIFY ... AS (...)), and verify the expression returns the same values on 8.0 before promotion.",
+		checks.CodeSchemaPartitionMismatch:   "Align the replica's partitions with the primary (ALTER TABLE ... REORGANIZE/ADD/DROP PARTITION) or replay the missed partition maintenance.",
+		checks.CodeSchemaIndexMissing:        "Recreate the index on the replica from the primary's SHOW CREATE TABLE; a missing unique index lets duplicates in after promotion, a missing secondary index slows the queries that used it.",
+		checks.CodeSchemaIndexExtra:          "Confirm the extra replica index is intentional; drop it or add it to the primary. An extra unique index can reject rows the primary accepts.",
+		checks.CodeSchemaIndexMismatch:       "Rebuild the replica index with the primary's columns, order and uniqueness (DROP INDEX plus ADD INDEX in one ALTER TABLE).",
+		checks.CodeSchemaFKMissing:           "Add the foreign key on the replica with the primary's definition (SHOW CREATE TABLE); check for orphaned rows first, since ADD CONSTRAINT validates existing data.",
+		checks.CodeSchemaFKExtra:             "Drop the extra constraint on the replica or add it to the primary; replicated deletes and updates must be checked the same way on both.",
+		checks.CodeSchemaFKMismatch:          "Recreate the replica's constraint with the primary's columns, referenced table and ON DELETE/ON UPDATE rules (DROP FOREIGN KEY plus ADD CONSTRAINT).",
+		checks.CodeSchemaViewMissing:         "Create the view on the replica from the primary's definition (SHOW CREATE VIEW).",
+		checks.CodeSchemaViewExtra:           "Drop the extra view on the replica or create it on the primary.",
+		checks.CodeSchemaViewMismatch:        "Compare meta.primary and meta.replica; recreate the replica's view (CREATE OR REPLACE VIEW) if the difference is more than 8.0's rewriting.",
+		checks.CodeSchemaViewColumnMissing:   "Restore the missing columns or recreate the view (CREATE OR REPLACE VIEW) without them; the view fails on every query until then.",
+		checks.CodeSchemaRoutineMissing:      "Create the routine on the replica from the primary's definition (SHOW CREATE PROCEDURE/FUNCTION).",
+		checks.CodeSchemaRoutineExtra:        "Drop the extra routine on the replica or create it on the primary.",
+		checks.CodeSchemaRoutineMismatch:     "Compare SHOW CREATE PROCEDURE/FUNCTION on both hosts and recreate the replica's routine from the primary.",
+		checks.CodeSchemaRoutineSecurity:     "Recreate the replica's routine with the primary's DEFINER and SQL SECURITY; callers otherwise run it with different privileges after promotion.",
+		checks.CodeSchemaRoutineIncompatible: "Rewrite the routine without the removed syntax (meta.syntax) on the primary and replica before upgrading.",
+		checks.CodeSchemaEventMissing:        "Create the event on the replica (disabled until cutover) so it keeps running after promotion, or confirm it is no longer needed.",
+		checks.CodeSchemaEventExtra:          "Confirm the extra replica event is intended; drop it if it should not run after promotion.",
+		checks.CodeSchemaEventMismatch:       "Re-create the replica's event from the primary's definition so it runs on the same schedule after promotion.",
+		checks.CodeSchemaEventEnabled:        "ALTER EVENT ... DISABLE on the replica (or set event_scheduler=OFF) and re-enable its events only after promotion, so their work does not run twice.",
+		checks.CodeDataParityMismatch:        "Re-checksum the range after replication catches up; if it still differs, resync those rows (or rebuild the replica) before promotion.",
+		checks.CodeDataParityIncomplete:      "Re-run with the same --run-id to resume the checksum from its last checkpoint.",
+		checks.CodeDataParitySampleDiff:      "Sampled rows differ; run a full checksum (mode: checksum) on the table to locate every affected range.",
+		checks.CodeClockSkew:                 "Sync every host in the topology and the operator host with the same NTP sources; re-run once the clocks agree.",
+		checks.CodeClockUnknown:              "Check connectivity to the host, or compare its clock with the operator host's manually (date -u).",
+		checks.CodeCompatVersionUntuned:      "Compatibility rules target 5.7 to 8.0; review this version pair manually.",
+		checks.CodeCompatSQLMode:             "Remove deprecated modes from sql_mode in my.cnf and the application's session settings before upgrading.",
+		checks.CodeCompatFeature:             "Replace the deprecated feature (see the finding meta) before upgrading; it is removed in the target version.",
+		checks.CodeCompatPKMissing:           "Add a primary key to each listed table; tables without one replicate and stream poorly.",
+		checks.CodeCompatCharset:             "Plan a utf8mb3 to utf8mb4 conversion; the COMPAT_UTF8MB4_* findings give each column's index length impact and candidate ALTER statements.",
+		checks.CodeCompatPartitionEngine:     "Convert the table to InnoDB (ALTER TABLE ... ENGINE=InnoDB) or remove partitioning before upgrading; 8.0 cannot open it otherwise.",
+		checks.CodeCompatSpatialSRID:         "On the upgraded 8.0 replica (5.7 has no SRID attribute), give the column the SRID its data uses: ALTER TABLE ... MODIFY col <type> NOT NULL SRID 4326, or SRID 0 for Cartesian data. The ALTER rebuilds the SPATIAL index. Check first that SELECT DISTINCT ST_SRID(col) returns only that SRID, and that the primary writes no other, or replication stops.",
+		checks.CodeCompatFulltextRebuild:     "Schedule ALTER TABLE ... ENGINE=InnoDB (or drop and re-add the index) after the upgrade and compare search results.",
+		checks.CodeCompatCollation:           "Decide whether to keep the old collation explicitly or adopt utf8mb4_0900_ai_ci; sort and comparison results may change.",
+		checks.CodeCompatUTF8MB4IndexTooLong: "Run the candidate statements in meta.alter_statements in order: rebuild each non-unique index with the shorter prefix first, then convert the column. For a unique index, shorten the column so the key fits (check MAX(CHAR_LENGTH(col)) first) or keep the column utf8mb3. Tables with ROW_FORMAT=COMPACT or REDUNDANT are limited to 767 bytes; convert them to DYNAMIC first.",
+		checks.CodeCompatReservedWord:        "Rename the object (ALTER TABLE ... RENAME COLUMN, RENAME TABLE, or recreate the view or routine) or backtick-quote the name in every application query, view and routine body before the upgraded replica serves traffic; stored routine bodies are not re-quoted by the server.",
+
+		cdc.CodeStatusUnavailable:           "Check Kafka Connect REST reachability (GET /connectors/<name>/status) and credentials.",
+		cdc.CodeConnectorNotRunning:         "Inspect the connector trace in Kafka Connect; fix the cause and resume or restart the connector.",
+		cdc.CodeTaskNotRunning:              "Read the task trace (finding meta), fix the cause, then POST /connectors/<name>/tasks/<id>/restart.",
+		cdc.CodeRestartLoop:                 "Stop restarting the connector; read the last task trace and fix the underlying error first.",
+		cdc.CodeSchemaHistoryUnavailable:    "Check broker connectivity and ACLs for the schema history topic.",
+		cdc.CodeSchemaHistoryMissing:        "Recreate the schema history topic (infinite retention, one partition) and re-snapshot the connector schema.",
+		cdc.CodeSchemaHistoryUnreadable:     "Grant the connector's principal read access to the schema history topic.",
+		cdc.CodeSchemaHistoryCoverageGap:    "Run a schema-only snapshot (snapshot.mode=recovery) so history covers every captured table.",
+		cdc.CodeOffsetsUnavailable:          "Read offsets from the Connect offsets topic and SHOW MASTER STATUS on the primary manually and record them before cutover.",
+		cdc.CodeConnectorUpgradeRequired:    "Upgrade the Debezium MySQL connector on Kafka Connect to the listed release before upgrading MySQL.",
+		cdc.CodeConnectorUpgradeRecommended: "Plan a Debezium connector upgrade to the listed release before the MySQL upgrade.",
+		cdc.CodeConnectorVersionUnknown:     "Save Connect's GET /connector-plugins response and pass it with --cdc-plugins; confirm the Debezium MySQL plugin is installed.",
+		cdc.CodeLatencyExceeded:             "Let the connector catch up (check broker throughput and snapshot activity) before cutover, or raise thresholds.max_cdc_latency deliberately.",
+		cdc.CodeLatencyUnknown:              "Expose the MilliSecondsBehindSource streaming metric to migratorx, or remove thresholds.max_cdc_latency.",
+		cdc.CodeKafkaAccessUnknown:          "Check the admin client's access to the cluster, or list the principal's ACLs and quotas with kafka-acls and kafka-configs manually.",
+		cdc.CodeKafkaACLMissing:             "Grant the listed operation with kafka-acls --add for the connector's principal (PREFIXED for data topics), and remove any matching DENY.",
+		cdc.CodeKafkaQuotaThrottles:         "Raise or remove the principal's quota with kafka-configs --alter for the cutover window, or accept the lag it will cause.",
+		cdc.CodeRestartTimeout:              "Read the task traces in the finding meta and the worker log; fix the cause before restarting again, or re-run cdc restart to keep waiting.",
+		cdc.CodeAutoRemediated:              "Re-run cdc check to confirm the remediation took; it retries up to max_attempts before escalating.",
+		cdc.CodeAutoRemediationExhausted:    "Automatic remediation did not help; follow the runbook for original_code (finding meta) by hand. The attempt count resets once the finding clears.",
+		cdc.CodeMessageSchemaChanged:        "Check each changed field with the downstream consumers (temporal precision, decimal handling); adjust time.precision.mode or decimal.handling.mode, or upgrade the consumers, before promotion.",
+		cdc.CodeMessageSchemaUnknown:        "Make a recent message with its schema available for the table (JSON converter with schemas.enable) and re-run.",
+		cdc.CodeThroughputDegraded:          "Compare the replica's binlog settings (binlog_row_image, binlog_transaction_compression, binlog_row_value_options) with the baseline and check the Connect worker's CPU and max.batch.size/max.queue.size; hold promotion until the connector keeps up.",
+		cdc.CodeThroughputUnknown:           "Provide at least two streaming metric samples (TotalNumberOfEventsSeen, QueueTotalCapacity, QueueRemainingCapacity) taken a few seconds apart without a connector restart in between.",
+
+		mysql.CodeBinlogRetentionTooShort:          "Raise binlog_expire_logs_seconds (expire_logs_days on 5.7) above the maintenance window before pausing replicas or CDC, or shorten the window.",
+		mysql.CodeBinlogRetentionTight:             "Raise binlog_expire_logs_seconds to at least twice the maintenance window for the duration of the upgrade.",
+		mysql.CodeBinlogHistoryShort:               "Check for manual PURGE BINARY LOGS and binlog disk limits; confirm paused consumers' positions are still on disk.",
+		mysql.CodeBinlogRetentionUnknown:           "Provide binlog_expire_logs_seconds and the oldest binlog's start time, or check retention manually.",
+		mysql.CodeServerIdentityChanged:            "Confirm the host kept its auto.cnf and my.cnf server_id; if the change is intended, update server_identity in the plan.",
+		mysql.CodeServerIdentityUnknown:            "Provide server_id and server_uuid for every topology member (SELECT @@server_id, @@server_uuid).",
+		mysql.CodeServerIDUnset:                    "Set a unique non-zero server_id in the host's configuration and restart it.",
+		mysql.CodeServerIDDuplicate:                "Give each topology member a unique server_id before replicating.",
+		mysql.CodeServerUUIDDuplicate:              "Stop the cloned server, delete auto.cnf from its datadir and restart it to generate a new server_uuid.",
+		mysql.CodeCDCServerIDCollision:             "Change the connector's database.server.id (and cdc.server_id in the plan) to a value no MySQL server uses.",
+		mysql.CodeReplicaServingReads:              "Drain the replica from the load balancer (weight 0 / disable) and wait for sessions to finish before stopping replication.",
+		mysql.CodeReplicaTrafficUnavailable:        "Grant PROCESS and read access to performance counters, or confirm the replica is drained manually.",
+		mysql.CodeDDLDriftDetected:                 "Find who ran the DDL (see meta tables), apply the same change to the replica if it is intended, then re-run schema and data parity before promotion.",
+		mysql.CodeDDLBaselineMissing:               "Freeze DDL on the primary before the upgrade window so drift can be detected, or confirm manually that no DDL ran.",
+		mysql.CodeReplicaLagExceeded:               "Wait for the replica to catch up and find the cause of the lag (long transactions, IO saturation) before continuing.",
+		mysql.CodeReplicaLagUnknown:                "Applier lag needs MySQL 8.0 performance_schema timestamps; check lag manually or remove thresholds.max_lag.",
+		mysql.CodeOldPrimaryWritable:               "Set super_read_only=ON on the old primary now, then find out why it became writable.",
+		mysql.CodeOldPrimaryWrites:                 "Find the client still writing to the old primary (processlist, general log) and repoint it; reconcile the stray writes onto the new primary.",
+		mysql.CodeOldPrimaryNotSuperReadOnly:       "Set super_read_only=ON on the old primary so privileged accounts cannot write either.",
+		mysql.CodeReplicationTLSDisabled:           "Configure the channel with SOURCE_SSL=1 (CHANGE REPLICATION SOURCE TO) and make sure the server has a certificate loaded.",
+		mysql.CodeReplicationTLSUnverified:         "Set SOURCE_SSL_VERIFY_SERVER_CERT=1 with a CA that signs the source certificate.",
+		mysql.CodeReplicationTLSVersionUnsupported: "Allow TLSv1.2 or TLSv1.3 in tls_version / SOURCE_TLS_VERSION before upgrading; 8.0.28+ rejects TLSv1 and TLSv1.1.",
+		mysql.CodeReplicationTLSLegacyVersion:      "Remove TLSv1 and TLSv1.1 from tls_version / SOURCE_TLS_VERSION.",
+		mysql.CodeReplicationTLSCertExpired:        "Rotate the server certificate (ALTER INSTANCE RELOAD TLS) before continuing.",
+		mysql.CodeReplicationTLSCertExpiring:       "Schedule a certificate rotation before the cutover window.",
+		mysql.CodeReplicationTLSUnknown:            "Check performance_schema.replication_connection_configuration and Ssl_server_not_after manually.",
+		mysql.CodeClientTLSIncompatible:            "Upgrade the client's connector to one that speaks TLSv1.2+, or keep a compatible tls_version until it is upgraded.",
+		mysql.CodeClientCipherIncompatible:         "Add a cipher the client supports to ssl_cipher (FIPS mode permitting), or upgrade the client's TLS library.",
+		mysql.CodeClientAuthPluginUnsupported:      "Upgrade the connector to one that supports caching_sha2_password, or create the client's account with a plugin it supports.",
+		mysql.CodeConnectedAppUnknown:              "Identify the application and add it to the plan's clients: section with its driver's TLS and auth support, or disconnect it before cutover.",
+		mysql.CodeConnectedAppsUnknown:             "Grant PROCESS and performance_schema read access, or list connected applications from SHOW PROCESSLIST manually.",
+		mysql.CodeProxySQLRoutingUnknown:           "Grant the admin user read access to the runtime_ tables, or review runtime_mysql_query_rules and runtime_mysql_servers manually.",
+		mysql.CodeProxySQLWriterUnknown:            "Configure mysql_replication_hostgroups for the cluster, or put the primary in a single writer hostgroup, then LOAD MYSQL SERVERS TO RUNTIME.",
+		mysql.CodeProxySQLWriteRuleToReader:        "Anchor the rule's match_digest to plain SELECTs (e.g. ^SELECT without FOR UPDATE) or point it at the writer hostgroup, then LOAD MYSQL QUERY RULES TO RUNTIME.",
+		mysql.CodeProxySQLDefaultNotWriter:         "Set the user's default_hostgroup to the writer hostgroup in mysql_users, then LOAD MYSQL USERS TO RUNTIME.",
+		mysql.CodeProxySQLTargetMissing:            "Add the target replica to mysql_servers in the reader hostgroup, then LOAD MYSQL SERVERS TO RUNTIME.",
+		mysql.CodeProxySQLNoReclassification:       "Add a mysql_replication_hostgroups row for the writer/reader pair and place the target replica in the reader hostgroup so ProxySQL moves it when read_only is cleared.",
+		mysql.CodeProxySQLUnexpectedWriter:         "Remove the host from the writer hostgroup or set it OFFLINE_HARD before cutover so writes cannot land on it.",
+		mysql.CodeBufferPoolCold:                   "Run the buffer pool warmup (innodb_buffer_pool_load_now or warmup.queries) or send a share of read traffic to the replica, then re-run the gate.",
+		mysql.CodeBufferPoolHitRatioUnknown:        "Read Innodb_buffer_pool_read_requests and Innodb_buffer_pool_reads twice while the replica serves reads and compare the ratio manually.",
+		mysql.CodeConfigVariableRemoved:            "Remove the variable from the option file (or RESET PERSIST it), setting its replacement from the finding meta if there is one, before starting the upgraded server.",
+		mysql.CodeConfigVariableRemovedLoose:       "Remove the loose- option or switch to its replacement; the upgraded server ignores it and logs a warning at startup.",
+		mysql.CodeConfigAuditUnknown:               "Make the option files readable (or pass --option-file) and check them against the target version's removed variables manually.",
+		mysql.CodeAutoIncrementBehind:              "Re-check once the replica has caught up; if the gap remains, ALTER TABLE ... AUTO_INCREMENT = <primary's value> on the replica before promotion so new rows cannot reuse ids.",
+		mysql.CodeFulltextSettingMismatch:          "Set the variable on the replica to the primary's value (or the other way round, deliberately), restart if it is read-only, then rebuild the listed tables' FULLTEXT indexes with ALTER TABLE ... FORCE.",
+		mysql.CodeFulltextParserMissing:            "Install a build of the parser plugin for the new version on the replica, or rebuild the affected indexes with a built-in parser, before promotion.",
+		mysql.CodeCollationDefaultChanged:          "Set character_set_server and collation_server on the replica to the primary's values in my.cnf, or keep the 8.0 default and make DDL after promotion declare CHARACTER SET and COLLATE explicitly.",
+		mysql.CodeHeartbeatFailed:                  "Check that post_validation.endpoint routes writes to the new primary, that it is writable (read_only off) and that the --admin-dsn user can write the probe table.",
+		mysql.CodeHeartbeatWrongPrimary:            "Repoint the proxy or DNS name behind post_validation.endpoint at the new primary, or roll back; application writes are still going to the old primary.",
+		mysql.CodeHeartbeatRowMissing:              "The endpoint accepted the write but does not read it back; check for read/write splitting that sends reads to a lagging replica.",
+		mysql.CodeHeartbeatCleanupFailed:           "Delete the probe row from post_validation.probe_table manually; it is harmless but accumulates.",
+		mysql.CodeHeartbeatSkipped:                 "Pass --admin-dsn to validate primary so the write path through post_validation.endpoint is probed.",
+		mysql.CodeCollationJoinAtRisk:              "Convert the listed columns (and the columns they join with) to one collation deliberately before new tables join them, or create new tables with the same explicit collation.",
+		mysql.CodeClientCompatUnknown:              "Compare tls_version, ssl_cipher and default_authentication_plugin with the client list manually.",
+		mysql.CodeOrphanTablesFound:                "Drop the orphaned table (DROP TABLE `#mysql50##sql-...`) or, for a dictionary entry without files, recreate a matching .frm and drop it; see the MySQL manual on orphan intermediate tables.",
+		mysql.CodeOrphanTablesUnknown:              "Check information_schema.INNODB_SYS_TABLES and the datadir for #sql- entries manually.",
+		mysql.CodeDictionaryFilePerTableOff:        "Enable innodb_file_per_table and rebuild large tables (ALTER TABLE ... ENGINE=InnoDB) so they get their own .ibd.",
+		mysql.CodeDictionarySharedTablespace:       "Optional: move the tables to file-per-table (ALTER TABLE ... TABLESPACE=innodb_file_per_table) before the upgrade.",
+		mysql.CodeDictionaryPartitionShared:        "Move the partitioned tables out of shared tablespaces (ALTER TABLE ... TABLESPACE=innodb_file_per_table) before upgrading; 8.0 rejects them.",
+		mysql.CodeDictionaryOrphanFrm:              "Remove the orphaned .frm (after confirming no table uses it) or restore the table's data file before upgrading.",
+		mysql.CodeDictionaryUnknown:                "Run mysqlcheck --check-upgrade or util.checkForServerUpgrade() to check dictionary readiness manually.",
+		mysql.CodeOldPrimarySoakUnknown:            "Verify read_only and SHOW MASTER STATUS on the old primary manually until the soak period ends.",
+
+		workflow.CodeTrendGrowing:                   "Compare the latest preflight with earlier runs and resolve the new WARN/BLOCK findings before they accumulate.",
+		workflow.CodeTrendBlockRegression:           "A recent change introduced a new blocker; review plan and schema changes since the previous run.",
+		workflow.CodeEnvironmentPrerequisiteMissing: "Run the plan to completion (through validate primary) in the required environment first.",
+		workflow.CodeEnvironmentPrerequisiteStale:   "Re-run the current plan to completion in the required environment; the recorded run used a different plan.",
+		workflow.CodeTrafficPeakAhead:               "Re-run the step after the peak ends (meta.resume_after), or outside traffic_calendar.lead_time of the next one.",
+		workflow.CodeTrafficCalendarUnavailable:     "Check that traffic_calendar.source is reachable and valid JSON; until then, confirm no traffic peak is due before continuing.",
+		workflow.CodeHostResolutionChanged:          "Confirm which server the member now reaches (e.g. compare server_uuid). If the DNS flip was unintended, fix DNS or set topology.aliases to the intended address; if it was planned, start a new run.",
+		workflow.CodeHostResolutionFailed:           "Check DNS for the member's address, or set topology.aliases to an address that resolves.",
+		workflow.CodePromotionDecisionRejected:      "Review the decision service's reason (meta.reason), resolve it, then re-run promote prepare.",
+		workflow.CodePromotionDecisionUnavailable:   "Check that promotion.decision.url is reachable, answers within the timeout and signs with the secret in MIGRATORX_DECISION_SECRET.",
+		workflow.CodePromotionConfirmationRequired:  "Re-run promote with --confirm set to the required phrase.",
+		workflow.CodePromotionChecksMissing:         "Provide inputs for every required check (schema, CDC) or remove the step from the plan.",
+		workflow.CodePromotionCheckSilent:           "A required check produced nothing; make sure it was not skipped with --skip-check/--only-check.",
+		workflow.CodePromotionBlocked:               "Resolve every BLOCK and non-allowlisted WARN above, then re-run promote.",
+		workflow.CodePromotionResultsStale:          "Re-run promote prepare to refresh the gate results, then execute within promotion.freshness_window.",
+		workflow.CodePromotionWarnBudgetExceeded:    "Resolve WARN findings until the count is within thresholds.max_warn_count; allowlisted WARNs count too.",
+	}
+}
+
+// With returns a copy of c with overrides applied. An empty override removes
+// the entry.
+func (c Catalog) With(overrides map[string]string) Catalog {
+	out := Catalog{}
+	for code, text := range c {
+		out[code] = text
+	}
+	for code, text := range overrides {
+		code = strings.TrimSpace(code)
+		if strings.TrimSpace(text) == "" {
+			delete(out, code)
+			continue
+		}
+		out[code] = text
+	}
+	return out
+}
+
+// Lookup returns the remediation for code, or "" when none is known.
+func (c Catalog) Lookup(code string) string {
+	if code == "" {
+		return ""
+	}
+	return c[code]
+}
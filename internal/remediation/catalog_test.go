@@ -0,0 +1,38 @@
+package remediation
+
+import (
+	"testing"
+
+	"migratorx/internal/cdc"
+	"migratorx/internal/checks"
+)
+
+func TestDefaultCoversBlockingCodes(t *testing.T) {
+	c := Default()
+	for _, code := range []string{checks.CodeCheckError, checks.CodeSchemaTableMissing, cdc.CodeTaskNotRunning} {
+		if c.Lookup(code) == "" {
+			t.Fatalf("expected remediation for %s", code)
+		}
+	}
+	if c.Lookup(checks.CodeSchemaParityOK) != "" || c.Lookup("") != "" {
+		t.Fatalf("expected no remediation for informational or empty codes")
+	}
+}
+
+func TestWithOverrides(t *testing.T) {
+	base := Default()
+	c := base.With(map[string]string{
+		cdc.CodeTaskNotRunning:   "page the data platform on-call",
+		checks.CodeCompatFeature: "",
+		"CUSTOM_CODE":            "see runbook",
+	})
+	if c.Lookup(cdc.CodeTaskNotRunning) != "page the data platform on-call" || c.Lookup("CUSTOM_CODE") != "see runbook" {
+		t.Fatalf("expected overrides to apply")
+	}
+	if c.Lookup(checks.CodeCompatFeature) != "" {
+		t.Fatalf("expected empty override to remove entry")
+	}
+	if base.Lookup(cdc.CodeTaskNotRunning) == "page the data platform on-call" {
+		t.Fatalf("expected base catalog to be unchanged")
+	}
+}
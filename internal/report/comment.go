@@ -0,0 +1,225 @@
+// Package report publishes run summaries to code review systems so plan and
+// schema changes reviewed there show the live readiness status inline, and
+// stores rendered reports in output sinks such as an evidence bucket.
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Providers a Target can point at.
+const (
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+)
+
+// Target is a pull request (GitHub) or merge request (GitLab) to comment on.
+type Target struct {
+	URL      string
+	Provider string
+	APIBase  string
+	Project  string
+	Number   int
+}
+
+// ParseTarget resolves a PR/MR web URL into its provider and API location.
+// github.com maps to api.github.com; other GitHub hosts are treated as GitHub
+// Enterprise (/api/v3) and GitLab hosts use /api/v4.
+func ParseTarget(raw string) (Target, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return Target{}, fmt.Errorf("review URL %q must be an http(s) pull or merge request URL", raw)
+	}
+	path := strings.Trim(u.Path, "/")
+	origin := u.Scheme + "://" + u.Host
+	if i := strings.Index(path, "/-/merge_requests/"); i > 0 {
+		number, err := parseNumber(path[i+len("/-/merge_requests/"):])
+		if err != nil {
+			return Target{}, fmt.Errorf("review URL %q: %w", raw, err)
+		}
+		return Target{URL: raw, Provider: ProviderGitLab, APIBase: origin + "/api/v4", Project: path[:i], Number: number}, nil
+	}
+	parts := strings.Split(path, "/")
+	if len(parts) >= 4 && parts[2] == "pull" {
+		number, err := parseNumber(parts[3])
+		if err != nil {
+			return Target{}, fmt.Errorf("review URL %q: %w", raw, err)
+		}
+		api := origin + "/api/v3"
+		if u.Host == "github.com" {
+			api = "https://api.github.com"
+		}
+		return Target{URL: raw, Provider: ProviderGitHub, APIBase: api, Project: parts[0] + "/" + parts[1], Number: number}, nil
+	}
+	return Target{}, fmt.Errorf("review URL %q is neither a GitHub pull request nor a GitLab merge request", raw)
+}
+
+func parseNumber(s string) (int, error) {
+	s = strings.SplitN(s, "/", 2)[0]
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid pull/merge request number %q", s)
+	}
+	return n, nil
+}
+
+// CommentClient creates or updates a single comment per marker on a Target.
+// The marker is embedded in the comment as an HTML comment, so re-runs edit
+// the same comment instead of adding new ones.
+type CommentClient struct {
+	HTTP  *http.Client
+	Token string
+}
+
+type remoteComment struct {
+	ID   int64  `json:"id"`
+	Body string `json:"body"`
+}
+
+// Upsert posts body on target, replacing the existing comment that carries
+// marker. It reports whether an existing comment was updated.
+func (c *CommentClient) Upsert(ctx context.Context, target Target, marker string, body string) (bool, error) {
+	if c.Token == "" {
+		return false, fmt.Errorf("no %s token configured", target.Provider)
+	}
+	body = "<!-- " + marker + " -->\n" + body
+	base := c.commentsURL(target)
+	payload := map[string]string{"body": body}
+	// A busy review has more comments than one page holds, so pages are
+	// fetched in turn, following the Link header, until the marked comment
+	// turns up.
+	next := base + "?per_page=100"
+	for page := 0; next != ""; page++ {
+		if page == maxCommentPages {
+			return false, fmt.Errorf("%s has more than %d pages of comments", target.URL, maxCommentPages)
+		}
+		var existing []remoteComment
+		header, err := c.request(ctx, target, http.MethodGet, next, nil, &existing)
+		if err != nil {
+			return false, err
+		}
+		for _, comment := range existing {
+			if strings.Contains(comment.Body, "<!-- "+marker+" -->") {
+				return true, c.do(ctx, target, c.updateMethod(target), c.commentURL(target, comment.ID), payload, nil)
+			}
+		}
+		next = nextPage(header)
+		// The token is sent with every page, so a next link must not
+		// lead it off the API's origin.
+		if next != "" && !sameOrigin(next, target.APIBase) {
+			return false, fmt.Errorf("%s: next page %q is not on %s", target.URL, next, target.APIBase)
+		}
+	}
+	return false, c.do(ctx, target, http.MethodPost, base, payload, nil)
+}
+
+// maxCommentPages bounds the comment listing, so a server that keeps
+// linking to a next page cannot stall the run.
+const maxCommentPages = 50
+
+// sameOrigin reports whether raw has the scheme and host of base.
+func sameOrigin(raw string, base string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	b, err := url.Parse(base)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Scheme, b.Scheme) && strings.EqualFold(u.Host, b.Host)
+}
+
+// nextPage returns the rel="next" URL of an RFC 8288 Link header, as both
+// GitHub and GitLab send on paginated lists, or "" on the last page.
+func nextPage(header http.Header) string {
+	for _, value := range header.Values("Link") {
+		for _, link := range strings.Split(value, ",") {
+			parts := strings.Split(link, ";")
+			target := strings.TrimSpace(parts[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, param := range parts[1:] {
+				if strings.ReplaceAll(strings.TrimSpace(param), " ", "") == `rel="next"` {
+					return target[1 : len(target)-1]
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func (c *CommentClient) commentsURL(target Target) string {
+	if target.Provider == ProviderGitLab {
+		return fmt.Sprintf("%s/projects/%s/merge_requests/%d/notes", target.APIBase, url.PathEscape(target.Project), target.Number)
+	}
+	return fmt.Sprintf("%s/repos/%s/issues/%d/comments", target.APIBase, target.Project, target.Number)
+}
+
+func (c *CommentClient) commentURL(target Target, id int64) string {
+	if target.Provider == ProviderGitLab {
+		return fmt.Sprintf("%s/%d", c.commentsURL(target), id)
+	}
+	return fmt.Sprintf("%s/repos/%s/issues/comments/%d", target.APIBase, target.Project, id)
+}
+
+func (c *CommentClient) updateMethod(target Target) string {
+	if target.Provider == ProviderGitLab {
+		return http.MethodPut
+	}
+	return http.MethodPatch
+}
+
+func (c *CommentClient) do(ctx context.Context, target Target, method string, endpoint string, payload interface{}, dest interface{}) error {
+	_, err := c.request(ctx, target, method, endpoint, payload, dest)
+	return err
+}
+
+// request is do that also returns the response headers, for pagination.
+func (c *CommentClient) request(ctx context.Context, target Target, method string, endpoint string, payload interface{}, dest interface{}) (http.Header, error) {
+	var reader io.Reader
+	if payload != nil {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if target.Provider == ProviderGitLab {
+		req.Header.Set("PRIVATE-TOKEN", c.Token)
+	} else {
+		req.Header.Set("Accept", "application/vnd.github+json")
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("%s %s: %s: %s", method, endpoint, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if dest == nil {
+		return resp.Header, nil
+	}
+	return resp.Header, json.NewDecoder(resp.Body).Decode(dest)
+}
@@ -0,0 +1,180 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseTarget(t *testing.T) {
+	cases := []struct {
+		url      string
+		provider string
+		api      string
+		project  string
+		number   int
+	}{
+		{"https://github.com/acme/db/pull/42", ProviderGitHub, "https://api.github.com", "acme/db", 42},
+		{"https://git.example.com/acme/db/pull/7/files", ProviderGitHub, "https://git.example.com/api/v3", "acme/db", 7},
+		{"https://gitlab.com/acme/infra/db/-/merge_requests/3", ProviderGitLab, "https://gitlab.com/api/v4", "acme/infra/db", 3},
+	}
+	for _, tc := range cases {
+		target, err := ParseTarget(tc.url)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.url, err)
+		}
+		if target.Provider != tc.provider || target.APIBase != tc.api || target.Project != tc.project || target.Number != tc.number {
+			t.Fatalf("%s: unexpected target %+v", tc.url, target)
+		}
+	}
+	for _, bad := range []string{"", "github.com/acme/db/pull/1", "https://github.com/acme/db/issues/1", "https://github.com/acme/db/pull/x"} {
+		if _, err := ParseTarget(bad); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+// fakeReviewServer stores comments in memory and serves the GitHub and GitLab
+// comment endpoints used by CommentClient.
+type fakeReviewServer struct {
+	comments map[int64]string
+	nextID   int64
+	auth     []string
+}
+
+func (s *fakeReviewServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.auth = append(s.auth, r.Header.Get("Authorization")+r.Header.Get("PRIVATE-TOKEN"))
+	switch r.Method {
+	case http.MethodGet:
+		list := []remoteComment{}
+		for id, body := range s.comments {
+			list = append(list, remoteComment{ID: id, Body: body})
+		}
+		_ = json.NewEncoder(w).Encode(list)
+	case http.MethodPost, http.MethodPatch, http.MethodPut:
+		var payload map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		if r.Method == http.MethodPost {
+			s.nextID++
+			s.comments[s.nextID] = payload["body"]
+		} else {
+			for id := range s.comments {
+				if strings.HasSuffix(r.URL.Path, "/"+strconv.FormatInt(id, 10)) {
+					s.comments[id] = payload["body"]
+				}
+			}
+		}
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+func TestCommentClient_UpsertUpdatesMarkedComment(t *testing.T) {
+	for _, path := range []string{"/acme/db/pull/1", "/acme/db/-/merge_requests/1"} {
+		fake := &fakeReviewServer{comments: map[int64]string{}}
+		server := httptest.NewServer(fake)
+		target, err := ParseTarget(server.URL + path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		client := &CommentClient{HTTP: server.Client(), Token: "secret"}
+
+		if updated, err := client.Upsert(context.Background(), target, "migratorx:m1", "first"); err != nil || updated {
+			t.Fatalf("%s: expected a new comment, got updated=%v err=%v", path, updated, err)
+		}
+		if updated, err := client.Upsert(context.Background(), target, "migratorx:m1", "second"); err != nil || !updated {
+			t.Fatalf("%s: expected the comment to be updated, got updated=%v err=%v", path, updated, err)
+		}
+		if _, err := client.Upsert(context.Background(), target, "migratorx:m2", "other"); err != nil {
+			t.Fatalf("%s: unexpected error: %v", path, err)
+		}
+		server.Close()
+
+		if len(fake.comments) != 2 || !strings.Contains(fake.comments[1], "second") {
+			t.Fatalf("%s: unexpected comments %v", path, fake.comments)
+		}
+		for _, auth := range fake.auth {
+			if !strings.Contains(auth, "secret") {
+				t.Fatalf("%s: expected token on every request, got %q", path, auth)
+			}
+		}
+	}
+}
+
+func TestCommentClient_UpsertFollowsLinkPagination(t *testing.T) {
+	var requests []string
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+		switch {
+		case r.Method == http.MethodGet && r.URL.Query().Get("page") == "":
+			w.Header().Set("Link", fmt.Sprintf(`<%s%s?per_page=100&page=2>; rel="next", <%s%s?per_page=100&page=2>; rel="last"`, server.URL, r.URL.Path, server.URL, r.URL.Path))
+			_ = json.NewEncoder(w).Encode([]remoteComment{{ID: 1, Body: "LGTM"}})
+		case r.Method == http.MethodGet:
+			w.Header().Set("Link", fmt.Sprintf(`<%s%s?per_page=100&page=1>; rel="prev"`, server.URL, r.URL.Path))
+			_ = json.NewEncoder(w).Encode([]remoteComment{{ID: 7, Body: "<!-- migratorx:m1 -->\nfirst"}})
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+	target, err := ParseTarget(server.URL + "/acme/db/pull/1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client := &CommentClient{HTTP: server.Client(), Token: "secret"}
+
+	if updated, err := client.Upsert(context.Background(), target, "migratorx:m1", "second"); err != nil || !updated {
+		t.Fatalf("expected the comment on page 2 to be updated, got updated=%v err=%v", updated, err)
+	}
+	want := []string{
+		"GET /api/v3/repos/acme/db/issues/1/comments?per_page=100",
+		"GET /api/v3/repos/acme/db/issues/1/comments?per_page=100&page=2",
+		"PATCH /api/v3/repos/acme/db/issues/comments/7",
+	}
+	if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected requests:\n%s", strings.Join(requests, "\n"))
+	}
+}
+
+func TestCommentClient_UpsertRejectsCrossOriginNextPage(t *testing.T) {
+	var leaked []string
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		leaked = append(leaked, r.Header.Get("Authorization"))
+		_ = json.NewEncoder(w).Encode([]remoteComment{})
+	}))
+	defer other.Close()
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+		w.Header().Set("Link", fmt.Sprintf(`<%s%s?page=2>; rel="next"`, other.URL, r.URL.Path))
+		_ = json.NewEncoder(w).Encode([]remoteComment{{ID: 1, Body: "LGTM"}})
+	}))
+	defer server.Close()
+	target, err := ParseTarget(server.URL + "/acme/db/pull/1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client := &CommentClient{HTTP: server.Client(), Token: "secret"}
+
+	if _, err := client.Upsert(context.Background(), target, "migratorx:m1", "body"); err == nil || !strings.Contains(err.Error(), "next page") {
+		t.Fatalf("expected a cross-origin next page to be rejected, got %v", err)
+	}
+	if len(leaked) != 0 {
+		t.Fatalf("expected no request to the other origin, got %d", len(leaked))
+	}
+	if len(requests) != 1 {
+		t.Fatalf("expected only the first page to be requested and no comment posted, got %v", requests)
+	}
+}
+
+func TestCommentClient_RequiresToken(t *testing.T) {
+	target, _ := ParseTarget("https://github.com/acme/db/pull/1")
+	if _, err := (&CommentClient{}).Upsert(context.Background(), target, "m", "body"); err == nil {
+		t.Fatalf("expected missing token to fail")
+	}
+}
@@ -0,0 +1,261 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Sink stores a rendered report. Put returns where the report landed.
+type Sink interface {
+	Put(ctx context.Context, name string, contentType string, body []byte) (string, error)
+}
+
+// SinkOptions configures the sinks ParseSink may return.
+type SinkOptions struct {
+	HTTP *http.Client
+	S3   S3Config
+}
+
+// ParseSink resolves an --output destination: an s3://bucket/key URL, an
+// http(s) endpoint, or a file path. A file path or S3 key ending in "/" (or
+// naming an existing directory) is a location reports are named under.
+func ParseSink(destination string, options SinkOptions) (Sink, error) {
+	destination = strings.TrimSpace(destination)
+	switch {
+	case destination == "":
+		return nil, fmt.Errorf("output destination is empty")
+	case strings.HasPrefix(destination, "s3://"):
+		u, err := url.Parse(destination)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("output destination %q must be s3://bucket/key", destination)
+		}
+		return &S3Sink{Bucket: u.Host, Key: strings.TrimPrefix(u.Path, "/"), Config: options.S3, HTTP: options.HTTP}, nil
+	case strings.HasPrefix(destination, "http://") || strings.HasPrefix(destination, "https://"):
+		return &HTTPSink{URL: destination, HTTP: options.HTTP}, nil
+	default:
+		return &FileSink{Path: strings.TrimPrefix(destination, "file://")}, nil
+	}
+}
+
+// ReportName names a report by run ID and step, e.g.
+// "default/promote-prepare-20261018T011825Z.json". A zero at leaves the
+// timestamp out, so re-runs replace the same report.
+func ReportName(runID string, step string, ext string, at time.Time) string {
+	step = strings.Join(strings.Fields(step), "-")
+	if step == "" {
+		step = "run"
+	}
+	if !at.IsZero() {
+		step += "-" + at.UTC().Format("20060102T150405Z")
+	}
+	return runID + "/" + step + "." + ext
+}
+
+// FileSink writes reports to Path, or under it when Path is a directory.
+type FileSink struct {
+	Path string
+}
+
+func (s *FileSink) Put(ctx context.Context, name string, contentType string, body []byte) (string, error) {
+	path := s.Path
+	if info, err := os.Stat(path); strings.HasSuffix(path, "/") || (err == nil && info.IsDir()) {
+		path = filepath.Join(path, filepath.FromSlash(name))
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, body, 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// HTTPSink POSTs reports to URL, naming each in the X-Migratorx-Report
+// header so the receiver can file it.
+type HTTPSink struct {
+	URL  string
+	HTTP *http.Client
+}
+
+func (s *HTTPSink) Put(ctx context.Context, name string, contentType string, body []byte) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-Migratorx-Report", name)
+	if err := send(s.HTTP, req); err != nil {
+		return "", err
+	}
+	return s.URL, nil
+}
+
+// S3Config holds the region, credentials and optional endpoint (for
+// S3-compatible stores, addressed path-style) used to sign S3 uploads.
+type S3Config struct {
+	Region          string
+	Endpoint        string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// S3Sink uploads reports to Bucket at Key, or under Key when it is empty or
+// ends in "/", with a SigV4-signed PUT.
+type S3Sink struct {
+	Bucket string
+	Key    string
+	Config S3Config
+	HTTP   *http.Client
+	Now    func() time.Time
+}
+
+func (s *S3Sink) Put(ctx context.Context, name string, contentType string, body []byte) (string, error) {
+	if s.Config.AccessKeyID == "" || s.Config.SecretAccessKey == "" {
+		return "", fmt.Errorf("no AWS credentials configured for s3://%s", s.Bucket)
+	}
+	key := s.Key
+	if key == "" || strings.HasSuffix(key, "/") {
+		key += name
+	}
+	location := "s3://" + s.Bucket + "/" + key
+	endpoint := s.objectURL(key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", contentType)
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	signV4(req, body, s.Config, now())
+	if err := send(s.HTTP, req); err != nil {
+		return "", fmt.Errorf("upload %s: %w", location, err)
+	}
+	return location, nil
+}
+
+// Endpoint returns the URL uploads go to: the object, or the prefix reports
+// are named under.
+func (s *S3Sink) Endpoint() string {
+	return s.objectURL(s.Key)
+}
+
+func (s *S3Sink) region() string {
+	if s.Config.Region != "" {
+		return s.Config.Region
+	}
+	return "us-east-1"
+}
+
+func (s *S3Sink) objectURL(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = uriEncode(segment)
+	}
+	path := strings.Join(segments, "/")
+	if s.Config.Endpoint != "" {
+		return strings.TrimSuffix(s.Config.Endpoint, "/") + "/" + uriEncode(s.Bucket) + "/" + path
+	}
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.Bucket, s.region(), path)
+}
+
+// signV4 signs req for S3 with AWS Signature Version 4, covering the host
+// and every header already set on req.
+func signV4(req *http.Request, body []byte, config S3Config, at time.Time) {
+	region := config.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	at = at.UTC()
+	amzDate := at.Format("20060102T150405Z")
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if config.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", config.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	canonicalRequest := strings.Join([]string{
+		req.Method, req.URL.EscapedPath(), req.URL.RawQuery,
+		canonicalHeaders.String(), signedHeaders, payloadHash,
+	}, "\n")
+
+	scope := at.Format("20060102") + "/" + region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+	key := []byte("AWS4" + config.SecretAccessKey)
+	for _, part := range []string{at.Format("20060102"), region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		config.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// uriEncode percent-encodes everything but the characters SigV4 leaves
+// unreserved.
+func uriEncode(s string) string {
+	var b strings.Builder
+	for _, c := range []byte(s) {
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') || strings.IndexByte("-_.~", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func send(client *http.Client, req *http.Request) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s: %s: %s", req.Method, req.URL.Redacted(), resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
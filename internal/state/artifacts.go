@@ -0,0 +1,56 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ArtifactDir returns where a run's artifacts live: next to the state file,
+// one directory per migration and run ID.
+func ArtifactDir(statePath string, migration string, runID string) string {
+	if strings.TrimSpace(runID) == "" {
+		runID = DefaultRunID
+	}
+	return filepath.Join(statePath+".artifacts", migration, runID)
+}
+
+// DirArtifactStore writes artifacts to Dir/<check>/<name>. It satisfies
+// checks.ArtifactStore.
+type DirArtifactStore struct {
+	Dir string
+}
+
+// Put writes an artifact, replacing one of the same name from an earlier
+// attempt of the run, and returns its path.
+func (s *DirArtifactStore) Put(check string, name string, data []byte) (string, error) {
+	for _, part := range []string{check, name} {
+		if part == "" || part == "." || part == ".." || strings.ContainsAny(part, `/\`) {
+			return "", fmt.Errorf("invalid artifact path component %q", part)
+		}
+	}
+	dir := filepath.Join(s.Dir, check)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, name)
+	tmp, err := os.CreateTemp(dir, "."+name+".*")
+	if err != nil {
+		return "", err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return path, nil
+}
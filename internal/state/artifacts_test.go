@@ -0,0 +1,27 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDirArtifactStore_WritesUnderRunDirectory(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	store := &DirArtifactStore{Dir: ArtifactDir(statePath, "m@staging", "")}
+	for _, content := range []string{"first", "second"} {
+		path, err := store.Put("schema_parity", "primary-schema.json", []byte(content))
+		if err != nil {
+			t.Fatalf("put: %v", err)
+		}
+		if want := filepath.Join(statePath+".artifacts", "m@staging", DefaultRunID, "schema_parity", "primary-schema.json"); path != want {
+			t.Fatalf("expected %s, got %s", want, path)
+		}
+		if b, _ := os.ReadFile(path); string(b) != content {
+			t.Fatalf("expected %q, got %q", content, b)
+		}
+	}
+	if _, err := store.Put("schema_parity", "../escape", nil); err == nil {
+		t.Fatalf("expected path traversal to be rejected")
+	}
+}
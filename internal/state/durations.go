@@ -0,0 +1,64 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// MaxStepDurations bounds the durations kept per step; older ones are dropped.
+const MaxStepDurations = 50
+
+// durationsKey is stored outside any run scope so durations span runs.
+func durationsKey(migration string) string {
+	return fmt.Sprintf("migration:%s:durations", migration)
+}
+
+// StepDurations records how long each step of a migration took in earlier
+// runs, in the backend shared by all runs.
+type StepDurations struct {
+	Backend   Backend
+	Migration string
+}
+
+// StepDurations returns step's recorded durations, oldest first.
+func (s StepDurations) StepDurations(step string) []time.Duration {
+	nanos := s.load()[step]
+	durations := make([]time.Duration, 0, len(nanos))
+	for _, n := range nanos {
+		durations = append(durations, time.Duration(n))
+	}
+	return durations
+}
+
+// RecordStepDuration appends d to step's durations, keeping the newest
+// MaxStepDurations.
+func (s StepDurations) RecordStepDuration(step string, d time.Duration) {
+	all := s.load()
+	nanos := append(all[step], int64(d))
+	if len(nanos) > MaxStepDurations {
+		nanos = nanos[len(nanos)-MaxStepDurations:]
+	}
+	all[step] = nanos
+	s.Backend.Set(durationsKey(s.Migration), all)
+}
+
+// load reads the durations in nanoseconds per step. A value that does not
+// decode is treated as empty.
+func (s StepDurations) load() map[string][]int64 {
+	all := map[string][]int64{}
+	v, ok := s.Backend.Get(durationsKey(s.Migration))
+	if !ok {
+		return all
+	}
+	// Values read back from a file backend are generic JSON; round-trip them
+	// into the typed form.
+	b, err := json.Marshal(v)
+	if err != nil {
+		return all
+	}
+	if err := json.Unmarshal(b, &all); err != nil {
+		return map[string][]int64{}
+	}
+	return all
+}
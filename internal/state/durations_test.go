@@ -0,0 +1,46 @@
+package state
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStepDurations_RoundTripsThroughFileState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	fs, err := NewFileState(path)
+	if err != nil {
+		t.Fatalf("new state: %v", err)
+	}
+	history := StepDurations{Backend: fs, Migration: "m"}
+	history.RecordStepDuration("upgrade replica", 90*time.Second)
+	history.RecordStepDuration("upgrade replica", 2*time.Hour)
+	history.RecordStepDuration("promote prepare", time.Minute)
+
+	reloaded, err := NewFileState(path)
+	if err != nil {
+		t.Fatalf("reload state: %v", err)
+	}
+	durations := StepDurations{Backend: reloaded, Migration: "m"}.StepDurations("upgrade replica")
+	if len(durations) != 2 || durations[0] != 90*time.Second || durations[1] != 2*time.Hour {
+		t.Fatalf("unexpected durations %v", durations)
+	}
+	if len(StepDurations{Backend: reloaded, Migration: "other"}.StepDurations("upgrade replica")) != 0 {
+		t.Fatalf("expected durations to be kept per migration")
+	}
+}
+
+func TestStepDurations_KeepsNewest(t *testing.T) {
+	fs, err := NewFileState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("new state: %v", err)
+	}
+	history := StepDurations{Backend: fs, Migration: "m"}
+	for i := 0; i < MaxStepDurations+5; i++ {
+		history.RecordStepDuration("s", time.Duration(i)*time.Second)
+	}
+	durations := history.StepDurations("s")
+	if len(durations) != MaxStepDurations || durations[0] != 5*time.Second {
+		t.Fatalf("expected the newest %d durations, got %d starting at %s", MaxStepDurations, len(durations), durations[0])
+	}
+}
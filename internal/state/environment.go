@@ -0,0 +1,45 @@
+package state
+
+import "fmt"
+
+// EnvironmentRun records a fully successful run of a plan in one environment,
+// i.e. post-validation passed after the run's promotion.
+type EnvironmentRun struct {
+	RunID       string
+	PlanHash    string
+	CompletedAt string
+}
+
+// environmentRunKey is stored outside any run scope so a later run, possibly
+// in another environment, can find it.
+func environmentRunKey(migration string, environment string) string {
+	return fmt.Sprintf("migration:%s:environment:%s:succeeded", migration, environment)
+}
+
+// RecordEnvironmentRun stores run as the latest successful run of migration in
+// environment.
+func RecordEnvironmentRun(backend Backend, migration string, environment string, run EnvironmentRun) {
+	backend.Set(environmentRunKey(migration, environment), map[string]interface{}{
+		"run_id":       run.RunID,
+		"plan_hash":    run.PlanHash,
+		"completed_at": run.CompletedAt,
+	})
+}
+
+// LastEnvironmentRun returns the latest successful run of migration in
+// environment, if one was recorded.
+func LastEnvironmentRun(backend Backend, migration string, environment string) (EnvironmentRun, bool) {
+	v, ok := backend.Get(environmentRunKey(migration, environment))
+	if !ok {
+		return EnvironmentRun{}, false
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return EnvironmentRun{}, false
+	}
+	run := EnvironmentRun{}
+	run.RunID, _ = m["run_id"].(string)
+	run.PlanHash, _ = m["plan_hash"].(string)
+	run.CompletedAt, _ = m["completed_at"].(string)
+	return run, true
+}
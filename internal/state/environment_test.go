@@ -0,0 +1,30 @@
+package state
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestEnvironmentRun_RoundTripsThroughFileState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	fs, err := NewFileState(path)
+	if err != nil {
+		t.Fatalf("new state: %v", err)
+	}
+	if _, ok := LastEnvironmentRun(fs, "m", "staging"); ok {
+		t.Fatalf("expected no recorded run")
+	}
+	RecordEnvironmentRun(fs, "m", "staging", EnvironmentRun{RunID: "r1", PlanHash: "abc", CompletedAt: "2026-10-17T10:00:00Z"})
+
+	reloaded, err := NewFileState(path)
+	if err != nil {
+		t.Fatalf("reload state: %v", err)
+	}
+	run, ok := LastEnvironmentRun(reloaded, "m", "staging")
+	if !ok || run.RunID != "r1" || run.PlanHash != "abc" || run.CompletedAt != "2026-10-17T10:00:00Z" {
+		t.Fatalf("unexpected run %+v (%v)", run, ok)
+	}
+	if _, ok := LastEnvironmentRun(reloaded, "m", "production"); ok {
+		t.Fatalf("expected environments to be recorded separately")
+	}
+}
@@ -5,14 +5,21 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 )
 
 // FileState persists checkpoints and values to a JSON file.
+// Writes re-read the file first and replace it atomically, so separate
+// processes working on different keys (e.g. scoped migrations) do not drop
+// each other's values. A lock file next to the state file serialises those
+// writes across processes on every platform, Windows included. A write that
+// cannot take the lock or replace the file is dropped and reported by Err.
 type FileState struct {
 	path string
 	mu   sync.Mutex
 	data map[string]interface{}
+	err  error
 }
 
 // NewFileState loads or creates state at the given path.
@@ -37,15 +44,57 @@ func (s *FileState) Get(key string) (interface{}, bool) {
 func (s *FileState) Set(key string, value interface{}) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.data[key] = value
-	_ = s.persist()
+	s.record(s.update(func() { s.data[key] = value }))
+}
+
+// Path returns the state file's location.
+func (s *FileState) Path() string {
+	return s.path
+}
+
+// Keys returns every stored key in sorted order.
+func (s *FileState) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_ = s.reload()
+	keys := make([]string, 0, len(s.data))
+	for k := range s.data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Delete removes keys and persists the file once.
+func (s *FileState) Delete(keys ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.record(s.update(func() {
+		for _, k := range keys {
+			delete(s.data, k)
+		}
+	}))
 }
 
 func (s *FileState) MarkCompleted(stepName string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.data[completedKey(stepName)] = true
-	_ = s.persist()
+	s.record(s.update(func() { s.data[completedKey(stepName)] = true }))
+}
+
+// Err returns the first write that failed since the state was opened. Set,
+// Delete and MarkCompleted satisfy workflow.State and cannot return it, so
+// callers check Err once the run is over.
+func (s *FileState) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+func (s *FileState) record(err error) {
+	if err != nil && s.err == nil {
+		s.err = fmt.Errorf("write state file %s: %w", s.path, err)
+	}
 }
 
 func (s *FileState) IsCompleted(stepName string) bool {
@@ -59,7 +108,32 @@ func (s *FileState) IsCompleted(stepName string) bool {
 	return ok && b
 }
 
+// update applies change between re-reading and replacing the file, holding
+// the cross-process lock. Nothing is written when the lock cannot be taken:
+// an unlocked write could replace the file with a copy missing another
+// process's keys.
+func (s *FileState) update(change func()) error {
+	unlock, err := acquireLock(s.path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := s.reload(); err != nil {
+		return err
+	}
+	change()
+	return s.persist()
+}
+
 func (s *FileState) load() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	unlock, err := acquireLock(s.path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 	if _, err := os.Stat(s.path); err != nil {
 		if os.IsNotExist(err) {
 			return s.persist()
@@ -76,6 +150,27 @@ func (s *FileState) load() error {
 	return json.Unmarshal(b, &s.data)
 }
 
+// reload replaces memory with the current file contents before a write, so
+// keys another process deleted are not written back.
+func (s *FileState) reload() error {
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if len(b) == 0 {
+		return nil
+	}
+	onDisk := map[string]interface{}{}
+	if err := json.Unmarshal(b, &onDisk); err != nil {
+		return err
+	}
+	s.data = onDisk
+	return nil
+}
+
 func (s *FileState) persist() error {
 	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
 		return err
@@ -84,7 +179,27 @@ func (s *FileState) persist() error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(s.path, b, 0o644)
+	// A unique name in the same directory keeps the rename on one
+	// filesystem and never collides with a leftover from a crashed writer.
+	f, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	_, err = f.Write(b)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp, 0o644)
+	}
+	if err == nil {
+		err = replaceFile(tmp, s.path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+	}
+	return err
 }
 
 func completedKey(step string) string {
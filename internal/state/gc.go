@@ -0,0 +1,145 @@
+package state
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// runCompletedKey marks a run as finished, relative to its Scope.
+const runCompletedKey = "run:completed_at"
+
+// MarkRunCompleted records that the scoped run finished at completedAt
+// (RFC 3339). Completed runs are what GC archives.
+func MarkRunCompleted(scope Backend, completedAt string) {
+	scope.Set(runCompletedKey, completedAt)
+}
+
+// RunCompleted returns when the scoped run was marked completed.
+func RunCompleted(scope Backend) (string, bool) {
+	v, ok := scope.Get(runCompletedKey)
+	if !ok {
+		return "", false
+	}
+	completedAt, ok := v.(string)
+	return completedAt, ok && completedAt != ""
+}
+
+// Collectable is a Backend that can enumerate and remove keys.
+type Collectable interface {
+	Backend
+	Keys() []string
+	Delete(keys ...string)
+}
+
+// RunInfo describes one migration run's keys in a backend.
+type RunInfo struct {
+	Migration   string
+	RunID       string
+	CompletedAt string
+	Keys        []string
+	// Err is set by GC when the run's keys could not be archived; the run
+	// is then left in place.
+	Err error
+}
+
+// Runs lists the runs with scoped keys in backend, ordered by migration and
+// run ID.
+func Runs(backend Collectable) []RunInfo {
+	byPrefix := map[string]*RunInfo{}
+	for _, key := range backend.Keys() {
+		migration, runID, ok := parseScopedKey(key)
+		if !ok {
+			continue
+		}
+		prefix := ScopePrefix(migration, runID)
+		run, ok := byPrefix[prefix]
+		if !ok {
+			run = &RunInfo{Migration: migration, RunID: runID}
+			byPrefix[prefix] = run
+		}
+		run.Keys = append(run.Keys, key)
+		if key == prefix+runCompletedKey {
+			v, _ := backend.Get(key)
+			run.CompletedAt, _ = v.(string)
+		}
+	}
+	runs := make([]RunInfo, 0, len(byPrefix))
+	for _, run := range byPrefix {
+		sort.Strings(run.Keys)
+		runs = append(runs, *run)
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		if runs[i].Migration != runs[j].Migration {
+			return runs[i].Migration < runs[j].Migration
+		}
+		return runs[i].RunID < runs[j].RunID
+	})
+	return runs
+}
+
+// Collect returns the runs of migration ("" for every migration) completed at
+// least olderThan before now. Incomplete runs are never collectable.
+func Collect(backend Collectable, migration string, olderThan time.Duration, now time.Time) []RunInfo {
+	collectable := []RunInfo{}
+	for _, run := range Runs(backend) {
+		if run.CompletedAt == "" || (migration != "" && run.Migration != migration) {
+			continue
+		}
+		completedAt, err := time.Parse(time.RFC3339, run.CompletedAt)
+		if err != nil || now.Sub(completedAt) < olderThan {
+			continue
+		}
+		collectable = append(collectable, run)
+	}
+	return collectable
+}
+
+// GC removes the checkpoints of the runs Collect returns, so a later migration
+// reusing the run ID (for example the default one) starts clean instead of
+// skipping actions recorded months ago. Each removed key and its value are
+// copied to archive first when archive is non-nil; a run whose copy fails
+// keeps its checkpoints and is returned with Err set. It returns the
+// collected runs.
+func GC(backend Collectable, archive Backend, migration string, olderThan time.Duration, now time.Time) []RunInfo {
+	collected := Collect(backend, migration, olderThan, now)
+	for i, run := range collected {
+		if archive != nil {
+			for _, key := range run.Keys {
+				if v, ok := backend.Get(key); ok {
+					archive.Set(key, v)
+				}
+			}
+			// Set cannot fail, so a backend that can reports its
+			// dropped writes through Err, as FileState does.
+			if a, ok := archive.(interface{ Err() error }); ok {
+				if err := a.Err(); err != nil {
+					collected[i].Err = fmt.Errorf("failed to archive run %q: %w", run.RunID, err)
+					continue
+				}
+			}
+		}
+		backend.Delete(run.Keys...)
+	}
+	return collected
+}
+
+// parseScopedKey splits a key written through a Scope into its migration and
+// run ID.
+func parseScopedKey(key string) (string, string, bool) {
+	rest := strings.TrimPrefix(key, "migration:")
+	if rest == key {
+		return "", "", false
+	}
+	i := strings.Index(rest, ":run:")
+	if i <= 0 {
+		return "", "", false
+	}
+	migration, rest := rest[:i], rest[i+len(":run:"):]
+	j := strings.Index(rest, ":")
+	if j <= 0 {
+		return "", "", false
+	}
+	return migration, rest[:j], true
+}
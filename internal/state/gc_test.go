@@ -0,0 +1,96 @@
+package state
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGC_ArchivesOnlyOldCompletedRuns(t *testing.T) {
+	temp := t.TempDir()
+	fs, err := NewFileState(filepath.Join(temp, "state.json"))
+	if err != nil {
+		t.Fatalf("new state: %v", err)
+	}
+	archive, err := NewFileState(filepath.Join(temp, "state.json.archive.json"))
+	if err != nil {
+		t.Fatalf("new archive: %v", err)
+	}
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	old, _ := NewScope(fs, "m@prod", "")
+	old.Set("replica_upgrade:r1:stopped", true)
+	MarkRunCompleted(old, now.Add(-60*24*time.Hour).Format(time.RFC3339))
+	recent, _ := NewScope(fs, "m@prod", "r2")
+	recent.Set("replica_upgrade:r1:stopped", true)
+	MarkRunCompleted(recent, now.Add(-time.Hour).Format(time.RFC3339))
+	running, _ := NewScope(fs, "m@staging", "")
+	running.Set("replica_upgrade:r1:stopped", true)
+	RecordEnvironmentRun(fs, "m", "prod", EnvironmentRun{RunID: DefaultRunID})
+
+	if runs := Runs(fs); len(runs) != 3 || runs[0].RunID != DefaultRunID || runs[0].CompletedAt == "" {
+		t.Fatalf("unexpected runs %+v", runs)
+	}
+	if other := Collect(fs, "other", 30*24*time.Hour, now); len(other) != 0 {
+		t.Fatalf("expected no runs for another migration, got %+v", other)
+	}
+	collected := GC(fs, archive, "", 30*24*time.Hour, now)
+	if len(collected) != 1 || collected[0].Migration != "m@prod" || collected[0].RunID != DefaultRunID {
+		t.Fatalf("expected only the old completed run to be collected, got %+v", collected)
+	}
+
+	reloaded, _ := NewFileState(filepath.Join(temp, "state.json"))
+	scope, _ := NewScope(reloaded, "m@prod", "")
+	if _, ok := scope.Get("replica_upgrade:r1:stopped"); ok {
+		t.Fatalf("expected the old run's checkpoints to be removed")
+	}
+	if _, ok := RunCompleted(scope); ok {
+		t.Fatalf("expected the completion marker to be removed")
+	}
+	if _, ok := LastEnvironmentRun(reloaded, "m", "prod"); !ok {
+		t.Fatalf("expected unscoped records to be kept")
+	}
+	if v, ok := archive.Get(ScopePrefix("m@prod", DefaultRunID) + "replica_upgrade:r1:stopped"); !ok || v != true {
+		t.Fatalf("expected the checkpoint in the archive, got %v", v)
+	}
+	if len(Runs(reloaded)) != 2 {
+		t.Fatalf("expected the recent and running runs to remain")
+	}
+}
+
+func TestGC_KeepsRunWhenArchiveWriteFails(t *testing.T) {
+	defer setLockTiming(50*time.Millisecond, time.Hour)()
+	temp := t.TempDir()
+	fs, err := NewFileState(filepath.Join(temp, "state.json"))
+	if err != nil {
+		t.Fatalf("new state: %v", err)
+	}
+	archivePath := filepath.Join(temp, "state.json.archive.json")
+	archive, err := NewFileState(archivePath)
+	if err != nil {
+		t.Fatalf("new archive: %v", err)
+	}
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	old, _ := NewScope(fs, "m@prod", "")
+	old.Set("replica_upgrade:r1:stopped", true)
+	MarkRunCompleted(old, now.Add(-60*24*time.Hour).Format(time.RFC3339))
+
+	unlock, err := acquireLock(archivePath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	collected := GC(fs, archive, "", 30*24*time.Hour, now)
+	unlock()
+
+	if len(collected) != 1 || collected[0].Err == nil {
+		t.Fatalf("expected the run to be reported as not archived, got %+v", collected)
+	}
+	reloaded, _ := NewFileState(filepath.Join(temp, "state.json"))
+	scope, _ := NewScope(reloaded, "m@prod", "")
+	if _, ok := scope.Get("replica_upgrade:r1:stopped"); !ok {
+		t.Fatalf("expected the run's checkpoints to survive a failed archive")
+	}
+	if _, ok := RunCompleted(scope); !ok {
+		t.Fatalf("expected the completion marker to survive a failed archive")
+	}
+}
@@ -0,0 +1,108 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+)
+
+// Lock timing. Writers hold the lock only while they re-read and replace the
+// state file, so a lock older than staleLockAge belongs to a process that
+// died mid-write and is taken over.
+var (
+	lockTimeout  = 10 * time.Second
+	lockRetry    = 20 * time.Millisecond
+	staleLockAge = 30 * time.Second
+)
+
+// acquireLock takes the cross-process write lock for the state file at path
+// by creating path+".lock" exclusively. O_EXCL creation is atomic on every
+// platform, including Windows where there is no flock, so one mechanism
+// serves all of them. The returned func releases the lock.
+func acquireLock(path string) (func(), error) {
+	lock := path + ".lock"
+	token := fmt.Sprintf("%d %d\n", os.Getpid(), time.Now().UnixNano())
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			_, err = f.WriteString(token)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				_ = os.Remove(lock)
+				return nil, err
+			}
+			return func() { releaseLock(lock, token) }, nil
+		}
+		if !lockBusy(err) {
+			return nil, err
+		}
+		if info, statErr := os.Stat(lock); statErr == nil && time.Since(info.ModTime()) > staleLockAge {
+			if takeOverStaleLock(lock, info) {
+				continue
+			}
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("state file %s is locked by another process; remove %s if no migratorx command is running", path, lock)
+		}
+		time.Sleep(lockRetry)
+	}
+}
+
+// takeOverStaleLock removes the stale lock described by stale and reports
+// whether the lock is free to retry. Every waiter sees the same stale lock;
+// lock+".takeover" lets one of them at a time re-check and remove it, so a
+// waiter that stat'ed the stale lock never removes the fresh one another
+// waiter created in its place.
+func takeOverStaleLock(lock string, stale os.FileInfo) bool {
+	guard := lock + ".takeover"
+	f, err := os.OpenFile(guard, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		// The guard is held for a stat and a remove; one this old was
+		// left by a waiter that died in between.
+		if info, statErr := os.Stat(guard); statErr == nil && time.Since(info.ModTime()) > staleLockAge {
+			_ = os.Remove(guard)
+		}
+		return false
+	}
+	f.Close()
+	defer os.Remove(guard)
+	current, err := os.Stat(lock)
+	if err != nil {
+		return os.IsNotExist(err)
+	}
+	if !os.SameFile(current, stale) || !current.ModTime().Equal(stale.ModTime()) {
+		return false
+	}
+	return os.Remove(lock) == nil
+}
+
+// releaseLock removes the lock only while it still holds token, so a holder
+// that overran staleLockAge and was taken over does not release the new
+// holder's lock.
+func releaseLock(lock string, token string) {
+	if b, err := os.ReadFile(lock); err == nil && string(b) == token {
+		_ = os.Remove(lock)
+	}
+}
+
+// lockBusy reports whether creating the lock file failed because another
+// process holds it. Windows refuses to create a file whose previous holder is
+// still deleting it with "access denied" rather than "exists".
+func lockBusy(err error) bool {
+	return os.IsExist(err) || (runtime.GOOS == "windows" && os.IsPermission(err))
+}
+
+// replaceFile renames tmp over path. On Windows the rename fails while
+// another process has path open for reading, so it is retried briefly.
+func replaceFile(tmp string, path string) error {
+	err := os.Rename(tmp, path)
+	for attempt := 0; err != nil && runtime.GOOS == "windows" && attempt < 10; attempt++ {
+		time.Sleep(lockRetry)
+		err = os.Rename(tmp, path)
+	}
+	return err
+}
@@ -0,0 +1,218 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFileState_ConcurrentWritersKeepEveryKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	// Separate FileState values share no mutex, like separate processes.
+	writers := make([]*FileState, 4)
+	for i := range writers {
+		fs, err := NewFileState(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		writers[i] = fs
+	}
+	var wg sync.WaitGroup
+	for i, fs := range writers {
+		wg.Add(1)
+		go func(i int, fs *FileState) {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				fs.Set(fmt.Sprintf("w%d:%d", i, j), true)
+			}
+		}(i, fs)
+	}
+	wg.Wait()
+
+	reader, err := NewFileState(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if keys := reader.Keys(); len(keys) != 40 {
+		t.Fatalf("expected 40 keys after concurrent writes, got %d: %v", len(keys), keys)
+	}
+	if _, err := os.Stat(path + ".lock"); !os.IsNotExist(err) {
+		t.Fatalf("expected lock file to be released, got %v", err)
+	}
+}
+
+func TestAcquireLock_TimesOutWhileHeld(t *testing.T) {
+	defer setLockTiming(50*time.Millisecond, time.Hour)()
+	path := filepath.Join(t.TempDir(), "state.json")
+	unlock, err := acquireLock(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer unlock()
+
+	_, err = acquireLock(path)
+	if err == nil || !strings.Contains(err.Error(), "locked by another process") {
+		t.Fatalf("expected lock timeout, got %v", err)
+	}
+}
+
+func TestAcquireLock_TakesOverStaleLock(t *testing.T) {
+	defer setLockTiming(time.Second, time.Minute)()
+	path := filepath.Join(t.TempDir(), "state.json")
+	lock := path + ".lock"
+	if err := os.WriteFile(lock, []byte("12345\n"), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(lock, old, old); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	unlock, err := acquireLock(path)
+	if err != nil {
+		t.Fatalf("expected stale lock to be taken over, got %v", err)
+	}
+	unlock()
+}
+
+func TestAcquireLock_TakeoverKeepsLockRecreatedSinceStat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	lock := path + ".lock"
+	if err := os.WriteFile(lock, []byte("12345\n"), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stale, err := os.Stat(lock)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Another waiter took the stale lock over and a writer now holds a
+	// fresh one; the takeover must not remove it.
+	if err := os.Remove(lock); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	unlock, err := acquireLock(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer unlock()
+
+	if takeOverStaleLock(lock, stale) {
+		t.Fatalf("expected takeover of a recreated lock to be refused")
+	}
+	if _, err := os.Stat(lock); err != nil {
+		t.Fatalf("expected the fresh lock to survive, got %v", err)
+	}
+	if _, err := os.Stat(lock + ".takeover"); !os.IsNotExist(err) {
+		t.Fatalf("expected takeover guard to be released, got %v", err)
+	}
+}
+
+func TestAcquireLock_ReleaseKeepsLockTakenOver(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	lock := path + ".lock"
+	unlock, err := acquireLock(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := os.WriteFile(lock, []byte("67890 1\n"), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	unlock()
+	if _, err := os.Stat(lock); err != nil {
+		t.Fatalf("expected the new holder's lock to survive release, got %v", err)
+	}
+}
+
+func TestFileState_SetDropsWriteWhenLockHeld(t *testing.T) {
+	defer setLockTiming(50*time.Millisecond, time.Hour)()
+	path := filepath.Join(t.TempDir(), "state.json")
+	fs, err := NewFileState(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	unlock, err := acquireLock(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fs.Set("k", "v")
+	unlock()
+
+	if err := fs.Err(); err == nil || !strings.Contains(err.Error(), "locked by another process") {
+		t.Fatalf("expected lock error from Err, got %v", err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(string(b), `"k"`) {
+		t.Fatalf("expected no unlocked write, got %s", b)
+	}
+}
+
+func TestFileState_WriteKeepsKeysDeletedElsewhereDeleted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	a, err := NewFileState(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	a.Set("old", true)
+	b, err := NewFileState(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b.Delete("old")
+	a.Set("new", true)
+
+	if _, ok := a.Get("old"); ok {
+		t.Fatalf("expected deleted key to stay deleted in memory")
+	}
+	reader, err := NewFileState(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if keys := reader.Keys(); len(keys) != 1 || keys[0] != "new" {
+		t.Fatalf("expected only the new key on disk, got %v", keys)
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, e := range entries {
+		if strings.HasSuffix(e.Name(), ".tmp") {
+			t.Fatalf("expected no temp files left behind, found %s", e.Name())
+		}
+	}
+	if a.Err() != nil || b.Err() != nil {
+		t.Fatalf("unexpected write errors: %v, %v", a.Err(), b.Err())
+	}
+}
+
+func TestScope_ReportsBackendWriteFailure(t *testing.T) {
+	defer setLockTiming(50*time.Millisecond, time.Hour)()
+	path := filepath.Join(t.TempDir(), "state.json")
+	fs, err := NewFileState(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	scope, _ := NewScope(fs, "cluster_a", "")
+	unlock, err := acquireLock(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	scope.Set("artifact:promotion_candidate", "mysql-replica-2")
+	unlock()
+
+	if err := scope.Err(); err == nil {
+		t.Fatalf("expected the scope to report the backend's failed write")
+	}
+}
+
+func setLockTiming(timeout, stale time.Duration) func() {
+	prevTimeout, prevStale := lockTimeout, staleLockAge
+	lockTimeout, staleLockAge = timeout, stale
+	return func() { lockTimeout, staleLockAge = prevTimeout, prevStale }
+}
@@ -0,0 +1,115 @@
+package state
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultRunID is used when no run ID is supplied.
+const DefaultRunID = "default"
+
+// ownerKey records the migration that first used a state backend.
+const ownerKey = "migratorx:owner"
+
+// Backend is the key/value storage a Scope wraps. FileState and
+// workflow.MemoryState both satisfy it.
+type Backend interface {
+	Get(key string) (interface{}, bool)
+	Set(key string, value interface{})
+}
+
+// Scope namespaces every key by migration name and run ID so one backend can
+// hold several migrations (e.g. different clusters) without key collisions.
+// Scope satisfies workflow.State.
+type Scope struct {
+	backend Backend
+	prefix  string
+}
+
+// NewScope returns a Scope for a migration and run. An empty run ID uses DefaultRunID.
+func NewScope(backend Backend, migration string, runID string) (*Scope, error) {
+	if backend == nil {
+		return nil, fmt.Errorf("state backend is required")
+	}
+	migration = strings.TrimSpace(migration)
+	if migration == "" {
+		return nil, fmt.Errorf("migration name is required to scope state")
+	}
+	runID = strings.TrimSpace(runID)
+	if runID == "" {
+		runID = DefaultRunID
+	}
+	return &Scope{backend: backend, prefix: ScopePrefix(migration, runID)}, nil
+}
+
+// ScopePrefix returns the key prefix used for a migration and run.
+func ScopePrefix(migration string, runID string) string {
+	return fmt.Sprintf("migration:%s:run:%s:", migration, runID)
+}
+
+// Prefix returns the scope's key prefix.
+func (s *Scope) Prefix() string { return s.prefix }
+
+// Backend returns the unscoped backend, for records shared across runs.
+func (s *Scope) Backend() Backend { return s.backend }
+
+// Err reports the backend's first failed write, for backends that can fail.
+func (s *Scope) Err() error {
+	if b, ok := s.backend.(interface{ Err() error }); ok {
+		return b.Err()
+	}
+	return nil
+}
+
+func (s *Scope) Get(key string) (interface{}, bool) {
+	return s.backend.Get(s.prefix + key)
+}
+
+func (s *Scope) Set(key string, value interface{}) {
+	s.backend.Set(s.prefix+key, value)
+}
+
+func (s *Scope) MarkCompleted(stepName string) {
+	s.backend.Set(s.prefix+completedKey(stepName), true)
+}
+
+func (s *Scope) IsCompleted(stepName string) bool {
+	v, ok := s.backend.Get(s.prefix + completedKey(stepName))
+	if !ok {
+		return false
+	}
+	b, ok := v.(bool)
+	return ok && b
+}
+
+// ClaimOwner records migration as the backend's owner if none is set and
+// returns the recorded owner. A different owner means the state was created by
+// another plan; callers should surface that rather than silently proceed.
+func ClaimOwner(backend Backend, migration string) string {
+	if v, ok := backend.Get(ownerKey); ok {
+		if owner, ok := v.(string); ok && owner != "" {
+			return owner
+		}
+	}
+	backend.Set(ownerKey, migration)
+	return migration
+}
+
+// planHashKey stores the hash of the plan a run started with.
+const planHashKey = "plan_hash"
+
+// PinPlan records hash as the run's plan hash on first use. When a different
+// hash is already pinned it returns that hash and changed=true; the new hash
+// replaces it only if accept is set.
+func PinPlan(st Backend, hash string, accept bool) (string, bool) {
+	if v, ok := st.Get(planHashKey); ok {
+		if pinned, ok := v.(string); ok && pinned != "" && pinned != hash {
+			if accept {
+				st.Set(planHashKey, hash)
+			}
+			return pinned, true
+		}
+	}
+	st.Set(planHashKey, hash)
+	return hash, false
+}
@@ -0,0 +1,92 @@
+package state
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestScope_IsolatesMigrationsAndRuns(t *testing.T) {
+	fs, err := NewFileState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	a, _ := NewScope(fs, "cluster_a", "")
+	b, _ := NewScope(fs, "cluster_b", "")
+	a2, _ := NewScope(fs, "cluster_a", "run-2")
+
+	a.Set("k", "a")
+	b.Set("k", "b")
+	a.MarkCompleted("preflight")
+
+	if v, _ := a.Get("k"); v != "a" {
+		t.Fatalf("expected scoped value a, got %v", v)
+	}
+	if v, _ := b.Get("k"); v != "b" {
+		t.Fatalf("expected scoped value b, got %v", v)
+	}
+	if _, ok := a2.Get("k"); ok {
+		t.Fatalf("expected a different run to not see the value")
+	}
+	if !a.IsCompleted("preflight") || b.IsCompleted("preflight") {
+		t.Fatalf("completion must be scoped")
+	}
+}
+
+func TestClaimOwner_ReportsDifferentPlan(t *testing.T) {
+	fs, err := NewFileState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if owner := ClaimOwner(fs, "cluster_a"); owner != "cluster_a" {
+		t.Fatalf("expected first claim to succeed, got %q", owner)
+	}
+	if owner := ClaimOwner(fs, "cluster_b"); owner != "cluster_a" {
+		t.Fatalf("expected existing owner cluster_a, got %q", owner)
+	}
+}
+
+func TestFileState_MergesConcurrentWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	first, err := NewFileState(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := NewFileState(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	first.Set("a", "1")
+	second.Set("b", "2")
+
+	reloaded, err := NewFileState(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := reloaded.Get("a"); !ok {
+		t.Fatalf("expected value from first writer to survive")
+	}
+	if _, ok := reloaded.Get("b"); !ok {
+		t.Fatalf("expected value from second writer")
+	}
+}
+
+func TestPinPlan_DetectsChangedPlan(t *testing.T) {
+	fs, err := NewFileState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	scope, _ := NewScope(fs, "cluster_a", "")
+
+	if _, changed := PinPlan(scope, "h1", false); changed {
+		t.Fatalf("first pin must not report a change")
+	}
+	if pinned, changed := PinPlan(scope, "h2", false); !changed || pinned != "h1" {
+		t.Fatalf("expected change against h1, got %q changed=%v", pinned, changed)
+	}
+	if pinned, changed := PinPlan(scope, "h2", true); !changed || pinned != "h1" {
+		t.Fatalf("expected accepted change to still be reported, got %q changed=%v", pinned, changed)
+	}
+	if _, changed := PinPlan(scope, "h2", false); changed {
+		t.Fatalf("expected h2 to be pinned after acceptance")
+	}
+}
@@ -0,0 +1,72 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MaxTrendPoints bounds the trend kept per migration; older points are dropped.
+const MaxTrendPoints = 200
+
+// CheckCounts is the number of findings one check produced per severity.
+type CheckCounts struct {
+	Info  int `json:"info"`
+	Warn  int `json:"warn"`
+	Block int `json:"block"`
+}
+
+// TrendPoint is one preflight run's finding counts per check, plus the BLOCK
+// codes it produced so regressions can be told apart from known blockers.
+type TrendPoint struct {
+	RunID      string                 `json:"run_id"`
+	RecordedAt string                 `json:"recorded_at"`
+	PlanHash   string                 `json:"plan_hash,omitempty"`
+	Checks     map[string]CheckCounts `json:"checks"`
+	BlockCodes []string               `json:"block_codes,omitempty"`
+}
+
+// Totals sums the point's counts across checks.
+func (p TrendPoint) Totals() CheckCounts {
+	var total CheckCounts
+	for _, c := range p.Checks {
+		total.Info += c.Info
+		total.Warn += c.Warn
+		total.Block += c.Block
+	}
+	return total
+}
+
+// trendKey is stored outside any run scope so the trend spans runs.
+func trendKey(migration string) string {
+	return fmt.Sprintf("migration:%s:trend", migration)
+}
+
+// AppendTrend adds point to migration's trend, keeping the newest
+// MaxTrendPoints points.
+func AppendTrend(backend Backend, migration string, point TrendPoint) {
+	points := append(Trend(backend, migration), point)
+	if len(points) > MaxTrendPoints {
+		points = points[len(points)-MaxTrendPoints:]
+	}
+	backend.Set(trendKey(migration), points)
+}
+
+// Trend returns migration's recorded points, oldest first. A value that does
+// not decode as a trend is treated as empty.
+func Trend(backend Backend, migration string) []TrendPoint {
+	v, ok := backend.Get(trendKey(migration))
+	if !ok {
+		return nil
+	}
+	// Values read back from a file backend are generic JSON; round-trip them
+	// into the typed form.
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var points []TrendPoint
+	if err := json.Unmarshal(b, &points); err != nil {
+		return nil
+	}
+	return points
+}
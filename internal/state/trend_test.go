@@ -0,0 +1,46 @@
+package state
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestTrend_RoundTripsThroughFileState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	fs, err := NewFileState(path)
+	if err != nil {
+		t.Fatalf("new state: %v", err)
+	}
+	AppendTrend(fs, "m", TrendPoint{RunID: "r1", Checks: map[string]CheckCounts{"schema_parity": {Info: 1, Block: 2}}, BlockCodes: []string{"SCHEMA_COLUMN_MISSING"}})
+	AppendTrend(fs, "m", TrendPoint{RunID: "r2", Checks: map[string]CheckCounts{"schema_parity": {Warn: 1}}})
+
+	reloaded, err := NewFileState(path)
+	if err != nil {
+		t.Fatalf("reload state: %v", err)
+	}
+	points := Trend(reloaded, "m")
+	if len(points) != 2 || points[0].RunID != "r1" || points[1].RunID != "r2" {
+		t.Fatalf("unexpected points %+v", points)
+	}
+	if points[0].Totals() != (CheckCounts{Info: 1, Block: 2}) || points[0].BlockCodes[0] != "SCHEMA_COLUMN_MISSING" {
+		t.Fatalf("unexpected first point %+v", points[0])
+	}
+	if len(Trend(reloaded, "other")) != 0 {
+		t.Fatalf("expected trends to be kept per migration")
+	}
+}
+
+func TestAppendTrend_KeepsNewestPoints(t *testing.T) {
+	fs, err := NewFileState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("new state: %v", err)
+	}
+	for i := 0; i < MaxTrendPoints+5; i++ {
+		AppendTrend(fs, "m", TrendPoint{RunID: fmt.Sprintf("r%d", i)})
+	}
+	points := Trend(fs, "m")
+	if len(points) != MaxTrendPoints || points[0].RunID != "r5" {
+		t.Fatalf("expected the oldest points to be dropped, got %d starting at %s", len(points), points[0].RunID)
+	}
+}
@@ -0,0 +1,126 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Artifacts are outputs one step stores in State for later steps, possibly in
+// a later process, to consume: the promotion candidate prepare selected, the
+// GTID set captured at the gate, server snapshots, schema baselines. Each key
+// holds one Go type. Values are stored JSON-encoded as strings so they read
+// back as that type after a round trip through a persisted state file, where
+// a struct would otherwise come back as a generic map.
+
+// Artifact keys shared between commands. Per-host artifacts append the host.
+const (
+	ArtifactPromotionCandidate = "artifact:promotion_candidate"
+	ArtifactCDCOffsets         = "promotion:cdc_offsets"
+	artifactGTIDSetPrefix      = "artifact:gtid_set:"
+)
+
+// WriteErrState is a State whose writes can fail, such as a file-backed one.
+// Set cannot return the failure, so Err reports the first write that did.
+type WriteErrState interface {
+	State
+	Err() error
+}
+
+// PutArtifact stores value under key. For a WriteErrState it reports a
+// failed write, so a step never proceeds on an artifact that was not saved.
+func PutArtifact(st State, key string, value interface{}) error {
+	if st == nil {
+		return nil
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode artifact %q: %w", key, err)
+	}
+	st.Set(key, string(raw))
+	if ws, ok := st.(WriteErrState); ok {
+		if err := ws.Err(); err != nil {
+			return fmt.Errorf("failed to store artifact %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// GetArtifact decodes the artifact under key into out and reports whether it
+// was present. Values stored before artifacts were encoded (maps, or plain
+// values a MemoryState kept as-is) are decoded too. An empty string is a
+// cleared artifact. An artifact that does not decode into out is an error
+// rather than a silent zero value.
+func GetArtifact(st State, key string, out interface{}) (bool, error) {
+	if st == nil {
+		return false, nil
+	}
+	raw, ok := st.Get(key)
+	if !ok || raw == nil {
+		return false, nil
+	}
+	encoded, isString := raw.(string)
+	if !isString {
+		b, err := json.Marshal(raw)
+		if err != nil {
+			return false, fmt.Errorf("failed to decode artifact %q: %w", key, err)
+		}
+		encoded = string(b)
+	}
+	if encoded == "" {
+		return false, nil
+	}
+	if err := json.Unmarshal([]byte(encoded), out); err != nil {
+		return false, fmt.Errorf("failed to decode artifact %q: %w", key, err)
+	}
+	return true, nil
+}
+
+// SetPromotionCandidate records the replica promote prepare selected, so
+// promote execute cuts over the replica the gate actually checked.
+func SetPromotionCandidate(st State, replica string) error {
+	return PutArtifact(st, ArtifactPromotionCandidate, replica)
+}
+
+// PromotionCandidate returns the replica recorded by SetPromotionCandidate.
+func PromotionCandidate(st State) (string, bool) {
+	var replica string
+	ok, err := GetArtifact(st, ArtifactPromotionCandidate, &replica)
+	return replica, ok && err == nil && replica != ""
+}
+
+// SetCapturedGTIDSet records the GTID set captured for host, e.g. the
+// primary's gtid_executed when the promotion gate ran.
+func SetCapturedGTIDSet(st State, host string, gtidSet string) error {
+	return PutArtifact(st, artifactGTIDSetPrefix+host, gtidSet)
+}
+
+// CapturedGTIDSet returns the GTID set recorded for host.
+func CapturedGTIDSet(st State, host string) (string, bool) {
+	var gtidSet string
+	ok, err := GetArtifact(st, artifactGTIDSetPrefix+host, &gtidSet)
+	return gtidSet, ok && err == nil
+}
+
+// CDCOffsets are the connector offsets and primary coordinates captured when
+// the promotion gate ran. The JSON names match the CDC offset snapshot
+// finding's meta, which is stored as-is.
+type CDCOffsets struct {
+	Connector         string    `json:"connector"`
+	CapturedAt        time.Time `json:"captured_at"`
+	ConnectorBinlog   string    `json:"connector_binlog"`
+	ConnectorPosition int64     `json:"connector_position"`
+	ConnectorGTIDSet  string    `json:"connector_gtid_set"`
+	PrimaryHost       string    `json:"primary_host"`
+	PrimaryBinlog     string    `json:"primary_binlog"`
+	PrimaryPosition   int64     `json:"primary_position"`
+	PrimaryGTIDSet    string    `json:"primary_gtid_set"`
+	CaughtUp          bool      `json:"connector_caught_up"`
+}
+
+// CapturedCDCOffsets returns the offsets recorded at the promotion gate.
+func CapturedCDCOffsets(st State) (CDCOffsets, bool) {
+	var offsets CDCOffsets
+	ok, err := GetArtifact(st, ArtifactCDCOffsets, &offsets)
+	return offsets, ok && err == nil
+}
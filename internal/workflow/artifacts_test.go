@@ -0,0 +1,100 @@
+package workflow
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+// persistedState round-trips every value through JSON, as a state file does.
+type persistedState struct {
+	*MemoryState
+}
+
+func (p persistedState) Set(key string, value interface{}) {
+	raw, _ := json.Marshal(value)
+	var decoded interface{}
+	_ = json.Unmarshal(raw, &decoded)
+	p.MemoryState.Set(key, decoded)
+}
+
+func TestArtifacts_SurvivePersistedState(t *testing.T) {
+	st := persistedState{NewMemoryState()}
+	type baseline struct {
+		Tables map[string]string
+		Count  int
+	}
+	if err := PutArtifact(st, "ddl_freeze:primary:baseline", baseline{Tables: map[string]string{"orders": "abc"}, Count: 1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got baseline
+	if ok, err := GetArtifact(st, "ddl_freeze:primary:baseline", &got); !ok || err != nil {
+		t.Fatalf("expected artifact, got ok=%v err=%v", ok, err)
+	}
+	if got.Count != 1 || got.Tables["orders"] != "abc" {
+		t.Fatalf("unexpected artifact: %+v", got)
+	}
+
+	SetPromotionCandidate(st, "mysql-replica-2")
+	SetCapturedGTIDSet(st, "mysql-primary", "uuid:1-100")
+	if replica, ok := PromotionCandidate(st); !ok || replica != "mysql-replica-2" {
+		t.Fatalf("unexpected candidate: %q %v", replica, ok)
+	}
+	if gtidSet, ok := CapturedGTIDSet(st, "mysql-primary"); !ok || gtidSet != "uuid:1-100" {
+		t.Fatalf("unexpected GTID set: %q %v", gtidSet, ok)
+	}
+	if _, ok := CapturedGTIDSet(st, "mysql-replica-1"); ok {
+		t.Fatal("expected no GTID set for another host")
+	}
+}
+
+func TestArtifacts_DecodeLegacyAndRejectMismatchedValues(t *testing.T) {
+	st := NewMemoryState()
+	// Offsets stored as the raw finding meta before artifacts were encoded.
+	st.Set(ArtifactCDCOffsets, map[string]interface{}{
+		"connector":           "mysql-prod",
+		"captured_at":         "2026-10-18T09:00:00Z",
+		"primary_gtid_set":    "uuid:1-100",
+		"primary_position":    int64(154),
+		"connector_caught_up": true,
+	})
+	offsets, ok := CapturedCDCOffsets(st)
+	if !ok || offsets.Connector != "mysql-prod" || offsets.PrimaryPosition != 154 || !offsets.CaughtUp {
+		t.Fatalf("unexpected offsets: %+v %v", offsets, ok)
+	}
+	if !offsets.CapturedAt.Equal(time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected capture time: %v", offsets.CapturedAt)
+	}
+
+	st.Set(ArtifactPromotionCandidate, 42)
+	var replica string
+	if ok, err := GetArtifact(st, ArtifactPromotionCandidate, &replica); ok || err == nil {
+		t.Fatalf("expected a mismatched artifact to fail, got ok=%v err=%v", ok, err)
+	}
+	st.Set(ArtifactPromotionCandidate, "")
+	if _, ok := PromotionCandidate(st); ok {
+		t.Fatal("expected a cleared artifact to read as missing")
+	}
+}
+
+// failingState drops every write, like a state file whose lock is held.
+type failingState struct {
+	*MemoryState
+}
+
+func (failingState) Set(key string, value interface{}) {}
+func (failingState) Err() error                        { return errors.New("state file is locked") }
+
+func TestArtifacts_ReportFailedWrites(t *testing.T) {
+	st := failingState{NewMemoryState()}
+	if err := SetPromotionCandidate(st, "mysql-replica-2"); err == nil {
+		t.Fatal("expected a failed write to be reported")
+	}
+	if err := SetCapturedGTIDSet(st, "mysql-primary", "uuid:1-100"); err == nil {
+		t.Fatal("expected a failed write to be reported")
+	}
+	if _, ok := PromotionCandidate(st); ok {
+		t.Fatal("expected no candidate after a failed write")
+	}
+}
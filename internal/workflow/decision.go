@@ -0,0 +1,161 @@
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"migratorx/internal/checks"
+)
+
+// Finding codes emitted for external promotion decisions.
+const (
+	CodePromotionDecisionApproved    = "PROMOTION_DECISION_APPROVED"
+	CodePromotionDecisionRejected    = "PROMOTION_DECISION_REJECTED"
+	CodePromotionDecisionUnavailable = "PROMOTION_DECISION_UNAVAILABLE"
+)
+
+// DefaultDecisionTimeout bounds the wait for a decision service that sets no
+// timeout.
+const DefaultDecisionTimeout = 30 * time.Second
+
+// DecisionConfig names an external decision service promotion must be
+// approved by, e.g. an organization's risk engine.
+type DecisionConfig struct {
+	URL     string        `yaml:"url"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// DecisionRequest is POSTed to the decision service. Challenge is a fresh
+// random nonce the response must echo and sign.
+type DecisionRequest struct {
+	Migration string           `json:"migration"`
+	Replica   string           `json:"replica"`
+	Challenge string           `json:"challenge"`
+	Summary   checks.Summary   `json:"summary"`
+	Findings  []checks.Finding `json:"findings"`
+}
+
+// DecisionResponse is the service's answer. Decision is "approved" or
+// anything else to reject; Signature is the hex HMAC-SHA256, keyed with the
+// shared secret, of Challenge + ":" + Decision.
+type DecisionResponse struct {
+	Decision  string `json:"decision"`
+	Reason    string `json:"reason"`
+	Challenge string `json:"challenge"`
+	Signature string `json:"signature"`
+}
+
+// SignDecision returns the signature a decision service sends for decision
+// in answer to challenge.
+func SignDecision(secret []byte, challenge, decision string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(challenge + ":" + decision))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// DecisionService asks an external service whether promotion may proceed.
+// Only a signed "approved" answer to this request's challenge, received
+// within Timeout (default DefaultDecisionTimeout), approves; anything else
+// blocks.
+type DecisionService struct {
+	URL     string
+	Secret  []byte
+	Timeout time.Duration
+	Client  *http.Client
+}
+
+// Decide posts the gate's findings and returns a finding with the decision.
+func (s *DecisionService) Decide(ctx context.Context, request DecisionRequest) checks.Finding {
+	meta := map[string]interface{}{"url": s.URL}
+	decision, err := s.ask(ctx, &request)
+	if err != nil {
+		return checks.Finding{
+			Severity: checks.SeverityBlock,
+			Code:     CodePromotionDecisionUnavailable,
+			Message:  fmt.Sprintf("no valid promotion decision from %s: %v", s.URL, err),
+			Meta:     meta,
+		}
+	}
+	meta["decision"] = decision.Decision
+	if decision.Reason != "" {
+		meta["reason"] = decision.Reason
+	}
+	if decision.Decision != "approved" {
+		return checks.Finding{
+			Severity: checks.SeverityBlock,
+			Code:     CodePromotionDecisionRejected,
+			Message:  fmt.Sprintf("decision service %s did not approve promotion: %s %s", s.URL, decision.Decision, decision.Reason),
+			Meta:     meta,
+		}
+	}
+	return checks.Finding{
+		Severity: checks.SeverityInfo,
+		Code:     CodePromotionDecisionApproved,
+		Message:  fmt.Sprintf("decision service %s approved promotion", s.URL),
+		Meta:     meta,
+	}
+}
+
+func (s *DecisionService) ask(ctx context.Context, request *DecisionRequest) (DecisionResponse, error) {
+	if len(s.Secret) == 0 {
+		return DecisionResponse{}, fmt.Errorf("no shared secret to verify the response with")
+	}
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return DecisionResponse{}, err
+	}
+	request.Challenge = hex.EncodeToString(nonce)
+	body, err := json.Marshal(request)
+	if err != nil {
+		return DecisionResponse{}, err
+	}
+
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = DefaultDecisionTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return DecisionResponse{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return DecisionResponse{}, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return DecisionResponse{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return DecisionResponse{}, fmt.Errorf("POST returned %s", resp.Status)
+	}
+	var decision DecisionResponse
+	if err := json.Unmarshal(data, &decision); err != nil {
+		return DecisionResponse{}, fmt.Errorf("decode response: %w", err)
+	}
+	if decision.Challenge != request.Challenge {
+		return DecisionResponse{}, fmt.Errorf("response answers a different challenge")
+	}
+	want := SignDecision(s.Secret, decision.Challenge, decision.Decision)
+	if !hmac.Equal([]byte(want), []byte(decision.Signature)) {
+		return DecisionResponse{}, fmt.Errorf("response signature does not verify")
+	}
+	return decision, nil
+}
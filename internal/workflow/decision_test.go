@@ -0,0 +1,100 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"migratorx/internal/checks"
+)
+
+func decisionServer(t *testing.T, secret, decision string, tamper func(*DecisionResponse)) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request DecisionRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		response := DecisionResponse{Decision: decision, Reason: "risk score 12", Challenge: request.Challenge, Signature: SignDecision([]byte(secret), request.Challenge, decision)}
+		if tamper != nil {
+			tamper(&response)
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+}
+
+func TestDecisionService_RequiresSignedApproval(t *testing.T) {
+	cases := []struct {
+		name     string
+		secret   string
+		decision string
+		tamper   func(*DecisionResponse)
+		code     string
+	}{
+		{"approved", "s3cret", "approved", nil, CodePromotionDecisionApproved},
+		{"rejected", "s3cret", "rejected", nil, CodePromotionDecisionRejected},
+		{"wrong secret", "other", "approved", nil, CodePromotionDecisionUnavailable},
+		{"replayed challenge", "s3cret", "approved", func(r *DecisionResponse) { r.Challenge = "old" }, CodePromotionDecisionUnavailable},
+		{"upgraded decision", "s3cret", "rejected", func(r *DecisionResponse) { r.Decision = "approved" }, CodePromotionDecisionUnavailable},
+	}
+	for _, c := range cases {
+		server := decisionServer(t, c.secret, c.decision, c.tamper)
+		service := &DecisionService{URL: server.URL, Secret: []byte("s3cret")}
+		finding := service.Decide(context.Background(), DecisionRequest{Migration: "m"})
+		server.Close()
+		if finding.Code != c.code {
+			t.Fatalf("%s: expected %s, got %+v", c.name, c.code, finding)
+		}
+	}
+}
+
+func TestDecisionService_TimesOut(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+	service := &DecisionService{URL: server.URL, Secret: []byte("s3cret"), Timeout: 50 * time.Millisecond}
+	finding := service.Decide(context.Background(), DecisionRequest{})
+	if finding.Code != CodePromotionDecisionUnavailable || !strings.Contains(finding.Message, "deadline") {
+		t.Fatalf("expected a timeout to block, got %+v", finding)
+	}
+}
+
+func TestPromotionGate_AsksDecisionServiceOnlyWhenClean(t *testing.T) {
+	var asked []DecisionRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request DecisionRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		asked = append(asked, request)
+		json.NewEncoder(w).Encode(DecisionResponse{Decision: "rejected", Challenge: request.Challenge, Signature: SignDecision([]byte("s3cret"), request.Challenge, "rejected")})
+	}))
+	defer server.Close()
+
+	severity := checks.SeverityInfo
+	cdc := checks.NewReadOnlyCheck("cdc_debezium_health", func(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+		return []checks.Finding{{Severity: severity, Message: "cdc"}}, nil
+	})
+	schema := checks.NewReadOnlyCheck("schema_parity", func(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+		return []checks.Finding{{Severity: checks.SeverityInfo, Message: "schema ok"}}, nil
+	})
+	gate := &PromotionGate{ConfirmationPhrase: "PROMOTE", Checks: []checks.PreflightCheck{cdc, schema}, Migration: "m", Decision: &DecisionService{URL: server.URL, Secret: []byte("s3cret")}}
+
+	summary, findings, err := gate.Run(context.Background(), checks.Input{ReplicaHost: "r1"}, "PROMOTE")
+	if err != nil || summary.Block == 0 || findings[len(findings)-1].Code != CodePromotionBlocked {
+		t.Fatalf("expected the rejection to block promotion, got %+v (%v)", findings, err)
+	}
+	if len(asked) != 1 || asked[0].Replica != "r1" || asked[0].Migration != "m" || len(asked[0].Findings) != 2 || asked[0].Challenge == "" {
+		t.Fatalf("expected the findings to be posted with a challenge, got %+v", asked)
+	}
+
+	severity = checks.SeverityBlock
+	if _, _, err := gate.Run(context.Background(), checks.Input{}, "PROMOTE"); err != nil || len(asked) != 1 {
+		t.Fatalf("expected a blocked gate not to ask the decision service, got %d requests (%v)", len(asked), err)
+	}
+}
@@ -0,0 +1,159 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Duration anomaly defaults.
+const (
+	DefaultDurationAnomalyFactor = 2.0
+	DefaultDurationMinSamples    = 5
+)
+
+// DurationHistory stores how long steps took in earlier runs.
+type DurationHistory interface {
+	StepDurations(step string) []time.Duration
+	RecordStepDuration(step string, d time.Duration)
+}
+
+// DurationMonitor gives early warning that a step is stuck: when a step is
+// still running Factor times past the p95 of its recorded durations, it sends
+// an EventStepDurationAnomaly notification. Steps with fewer than MinSamples
+// recorded durations are not watched. Finished steps are recorded in History.
+// OnEvent plugs the monitor into Runner.OnEvent; Start and Finish serve
+// callers that run steps themselves.
+type DurationMonitor struct {
+	Migration  string
+	History    DurationHistory
+	Notifier   Notifier
+	Factor     float64
+	MinSamples int
+	Logger     *log.Logger
+
+	mu      sync.Mutex
+	running map[string]*watchedStep
+}
+
+type watchedStep struct {
+	started   time.Time
+	limit     time.Duration
+	timer     *time.Timer
+	anomalous bool
+}
+
+// Start begins timing step and arms the anomaly notification.
+func (m *DurationMonitor) Start(ctx context.Context, step string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.running == nil {
+		m.running = map[string]*watchedStep{}
+	}
+	if w, ok := m.running[step]; ok && w.timer != nil {
+		w.timer.Stop()
+	}
+	w := &watchedStep{started: time.Now()}
+	m.running[step] = w
+
+	durations := m.History.StepDurations(step)
+	minSamples := m.MinSamples
+	if minSamples <= 0 {
+		minSamples = DefaultDurationMinSamples
+	}
+	if len(durations) < minSamples {
+		return
+	}
+	factor := m.Factor
+	if factor <= 0 {
+		factor = DefaultDurationAnomalyFactor
+	}
+	p95 := percentile(durations, 0.95)
+	w.limit = time.Duration(float64(p95) * factor)
+	w.timer = time.AfterFunc(w.limit, func() {
+		m.mu.Lock()
+		if m.running[step] != w {
+			m.mu.Unlock()
+			return
+		}
+		w.anomalous = true
+		m.mu.Unlock()
+		m.notify(ctx, step, time.Since(w.started), p95, factor, len(durations))
+	})
+}
+
+// Finish stops timing step, records its duration and reports whether it ran
+// past its limit, even when it finished before the notification went out.
+// Blocked steps are not recorded, so a failure does not skew the history.
+func (m *DurationMonitor) Finish(step string, completed bool) (time.Duration, bool) {
+	m.mu.Lock()
+	w, ok := m.running[step]
+	delete(m.running, step)
+	m.mu.Unlock()
+	if !ok {
+		return 0, false
+	}
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	elapsed := time.Since(w.started)
+	if completed {
+		m.History.RecordStepDuration(step, elapsed)
+	}
+	// The timer may have fired just before it was stopped.
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return elapsed, w.anomalous || (w.timer != nil && elapsed >= w.limit)
+}
+
+// OnEvent starts and finishes steps from Runner events.
+func (m *DurationMonitor) OnEvent(e Event) {
+	switch e.Type {
+	case EventStepStarted:
+		m.Start(context.Background(), e.Step)
+	case EventStepCompleted:
+		m.Finish(e.Step, true)
+	case EventStepBlocked:
+		m.Finish(e.Step, false)
+	}
+}
+
+func (m *DurationMonitor) notify(ctx context.Context, step string, elapsed time.Duration, p95 time.Duration, factor float64, samples int) {
+	note := Notification{
+		Migration: m.Migration,
+		Event:     EventStepDurationAnomaly,
+		Message:   fmt.Sprintf("step %q has been running for %s, more than %.1fx its p95 of %s over %d runs", step, elapsed.Round(time.Second), factor, p95.Round(time.Second), samples),
+		Meta:      map[string]interface{}{"step": step, "elapsed": elapsed.String(), "p95": p95.String(), "factor": factor, "samples": samples},
+	}
+	if m.Notifier == nil {
+		return
+	}
+	if err := m.Notifier.Notify(ctx, note); err != nil {
+		m.logger().Printf("notification %s failed: %v", note.Event, err)
+	}
+}
+
+func (m *DurationMonitor) logger() *log.Logger {
+	if m.Logger == nil {
+		return log.Default()
+	}
+	return m.Logger
+}
+
+// percentile returns the nearest-rank percentile p (0-1] of durations.
+func percentile(durations []time.Duration, p float64) time.Duration {
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
@@ -0,0 +1,94 @@
+package workflow
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memoryDurations struct {
+	mu        sync.Mutex
+	durations map[string][]time.Duration
+}
+
+func (m *memoryDurations) StepDurations(step string) []time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]time.Duration(nil), m.durations[step]...)
+}
+
+func (m *memoryDurations) RecordStepDuration(step string, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.durations == nil {
+		m.durations = map[string][]time.Duration{}
+	}
+	m.durations[step] = append(m.durations[step], d)
+}
+
+type recordingNotifier struct {
+	notes chan Notification
+}
+
+func (r *recordingNotifier) Notify(ctx context.Context, n Notification) error {
+	r.notes <- n
+	return nil
+}
+
+func TestDurationMonitor_NotifiesWhileStepRunsPastP95(t *testing.T) {
+	history := &memoryDurations{durations: map[string][]time.Duration{
+		"upgrade_replica": {time.Millisecond, time.Millisecond, 2 * time.Millisecond, time.Millisecond, 5 * time.Millisecond},
+	}}
+	notifier := &recordingNotifier{notes: make(chan Notification, 1)}
+	monitor := &DurationMonitor{Migration: "m", History: history, Notifier: notifier, Factor: 2}
+
+	monitor.OnEvent(Event{Type: EventStepStarted, Step: "upgrade_replica"})
+	select {
+	case note := <-notifier.notes:
+		if note.Event != EventStepDurationAnomaly || note.Meta["step"] != "upgrade_replica" || note.Meta["samples"] != 5 {
+			t.Fatalf("unexpected notification: %+v", note)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected an anomaly notification while the step was running")
+	}
+
+	_, anomalous := monitor.Finish("upgrade_replica", true)
+	if !anomalous {
+		t.Fatalf("expected the step to be reported as anomalous")
+	}
+	if got := history.StepDurations("upgrade_replica"); len(got) != 6 {
+		t.Fatalf("expected the finished step to be recorded, got %v", got)
+	}
+}
+
+func TestDurationMonitor_WaitsForEnoughSamples(t *testing.T) {
+	history := &memoryDurations{durations: map[string][]time.Duration{"promote": {time.Nanosecond}}}
+	notifier := &recordingNotifier{notes: make(chan Notification, 1)}
+	monitor := &DurationMonitor{Migration: "m", History: history, Notifier: notifier}
+
+	monitor.Start(context.Background(), "promote")
+	time.Sleep(5 * time.Millisecond)
+	if _, anomalous := monitor.Finish("promote", false); anomalous {
+		t.Fatalf("expected no anomaly with a single sample")
+	}
+	if len(notifier.notes) != 0 {
+		t.Fatalf("expected no notification")
+	}
+	if got := history.StepDurations("promote"); len(got) != 1 {
+		t.Fatalf("expected a blocked step not to be recorded, got %v", got)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	durations := []time.Duration{}
+	for i := 20; i >= 1; i-- {
+		durations = append(durations, time.Duration(i)*time.Second)
+	}
+	if got := percentile(durations, 0.95); got != 19*time.Second {
+		t.Fatalf("expected nearest-rank p95 of 19s, got %s", got)
+	}
+	if got := percentile([]time.Duration{time.Second}, 0.95); got != time.Second {
+		t.Fatalf("expected a single sample to be its own p95, got %s", got)
+	}
+}
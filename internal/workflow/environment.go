@@ -0,0 +1,192 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"migratorx/internal/checks"
+	"migratorx/internal/state"
+)
+
+// Finding codes emitted by the environment gate.
+const (
+	CodeEnvironmentPrerequisiteOK      = "ENVIRONMENT_PREREQUISITE_OK"
+	CodeEnvironmentPrerequisiteMissing = "ENVIRONMENT_PREREQUISITE_MISSING"
+	CodeEnvironmentPrerequisiteStale   = "ENVIRONMENT_PREREQUISITE_STALE"
+)
+
+// EnvironmentConfig is one deployment target of a plan, e.g. staging or
+// production. Topology and CDC replace the plan's top-level sections when the
+// environment is selected. Requires names an environment that must have a
+// fully successful recorded run of the same plan before mutating commands may
+// run here.
+type EnvironmentConfig struct {
+	Name     string     `yaml:"name"`
+	Topology Topology   `yaml:"topology"`
+	CDC      *CDCConfig `yaml:"cdc"`
+	Requires string     `yaml:"requires"`
+}
+
+// Environment returns the named environment.
+func (p MigrationPlan) Environment(name string) (EnvironmentConfig, bool) {
+	for _, e := range p.Environments {
+		if e.Name == name {
+			return e, true
+		}
+	}
+	return EnvironmentConfig{}, false
+}
+
+// ForEnvironment returns the plan as seen from one environment. Plans without
+// environments are returned unchanged when name is empty; plans with
+// environments require one to be selected.
+func (p MigrationPlan) ForEnvironment(name string) (MigrationPlan, error) {
+	name = strings.TrimSpace(name)
+	if len(p.Environments) == 0 {
+		if name != "" {
+			return p, fmt.Errorf("plan declares no environments; remove --env %q", name)
+		}
+		return p, nil
+	}
+	if name == "" {
+		return p, fmt.Errorf("plan declares environments; select one with --env (%s)", strings.Join(p.environmentNames(), ", "))
+	}
+	env, ok := p.Environment(name)
+	if !ok {
+		return p, fmt.Errorf("environment %q is not declared (expected one of %s)", name, strings.Join(p.environmentNames(), ", "))
+	}
+	p.Topology = env.Topology
+	if env.CDC != nil {
+		p.CDC = *env.CDC
+	}
+	p.SelectedEnvironment = env.Name
+	return p, nil
+}
+
+// StateName is the migration name state is scoped by. Each environment keeps
+// separate checkpoints so a staging run never satisfies production steps.
+func (p MigrationPlan) StateName() string {
+	if p.SelectedEnvironment == "" {
+		return p.Migration
+	}
+	return p.Migration + "@" + p.SelectedEnvironment
+}
+
+func (p MigrationPlan) environmentNames() []string {
+	names := []string{}
+	for _, e := range p.Environments {
+		names = append(names, e.Name)
+	}
+	return names
+}
+
+func (p MigrationPlan) validateEnvironments() []string {
+	problems := []string{}
+	seen := map[string]bool{}
+	for i, e := range p.Environments {
+		name := strings.TrimSpace(e.Name)
+		switch {
+		case name == "":
+			problems = append(problems, fmt.Sprintf("environments[%d].name is required", i))
+		case seen[name]:
+			problems = append(problems, fmt.Sprintf("environments[%d].name %q is duplicated", i, name))
+		}
+		seen[name] = true
+		if strings.TrimSpace(e.Topology.Primary) == "" || len(e.Topology.Replicas) == 0 {
+			problems = append(problems, fmt.Sprintf("environments[%d].topology needs a primary and at least one replica", i))
+		}
+		problems = append(problems, e.Topology.validateRegions(fmt.Sprintf("environments[%d].topology", i))...)
+		problems = append(problems, e.Topology.validateAliases(fmt.Sprintf("environments[%d].topology", i))...)
+	}
+	for i, e := range p.Environments {
+		if e.Requires == "" {
+			continue
+		}
+		if e.Requires == e.Name || !seen[e.Requires] {
+			problems = append(problems, fmt.Sprintf("environments[%d].requires=%q must name another declared environment", i, e.Requires))
+		}
+	}
+	for _, e := range p.Environments {
+		if p.requiresCycle(e.Name) {
+			problems = append(problems, fmt.Sprintf("environment %q is part of a requires cycle", e.Name))
+			break
+		}
+	}
+	return problems
+}
+
+func (p MigrationPlan) requiresCycle(start string) bool {
+	visited := map[string]bool{}
+	name := start
+	for {
+		env, ok := p.Environment(name)
+		if !ok || env.Requires == "" {
+			return false
+		}
+		if visited[env.Requires] || env.Requires == start {
+			return true
+		}
+		visited[env.Requires] = true
+		name = env.Requires
+	}
+}
+
+// EnvironmentGate blocks mutating commands in Environment until Requires has
+// a fully successful recorded run of the same plan in the state backend.
+type EnvironmentGate struct {
+	Backend     state.Backend
+	Migration   string
+	Environment string
+	Requires    string
+	PlanHash    string
+}
+
+func (g *EnvironmentGate) Name() string   { return "environment_gate" }
+func (g *EnvironmentGate) ReadOnly() bool { return true }
+
+func (g *EnvironmentGate) Parameters() map[string]interface{} {
+	return map[string]interface{}{"environment": g.Environment, "requires": g.Requires}
+}
+
+func (g *EnvironmentGate) Run(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+	if g.Backend == nil {
+		return nil, fmt.Errorf("state backend is required")
+	}
+	meta := map[string]interface{}{"environment": g.Environment, "requires": g.Requires}
+	if g.Requires == "" {
+		return []checks.Finding{{
+			Severity: checks.SeverityInfo,
+			Code:     CodeEnvironmentPrerequisiteOK,
+			Message:  fmt.Sprintf("environment %q has no prerequisite environment", g.Environment),
+			Meta:     meta,
+		}}, nil
+	}
+	run, ok := state.LastEnvironmentRun(g.Backend, g.Migration, g.Requires)
+	if !ok {
+		return []checks.Finding{{
+			Severity: checks.SeverityBlock,
+			Code:     CodeEnvironmentPrerequisiteMissing,
+			Message:  fmt.Sprintf("environment %q requires a fully successful run in %q first; none is recorded", g.Environment, g.Requires),
+			Meta:     meta,
+		}}, nil
+	}
+	meta["run_id"] = run.RunID
+	meta["completed_at"] = run.CompletedAt
+	if g.PlanHash != "" && run.PlanHash != g.PlanHash {
+		meta["recorded_plan_hash"] = run.PlanHash
+		meta["plan_hash"] = g.PlanHash
+		return []checks.Finding{{
+			Severity: checks.SeverityBlock,
+			Code:     CodeEnvironmentPrerequisiteStale,
+			Message:  fmt.Sprintf("the successful %q run %s used a different plan; re-run the current plan in %q first", g.Requires, run.RunID, g.Requires),
+			Meta:     meta,
+		}}, nil
+	}
+	return []checks.Finding{{
+		Severity: checks.SeverityInfo,
+		Code:     CodeEnvironmentPrerequisiteOK,
+		Message:  fmt.Sprintf("plan completed successfully in %q (run %s)", g.Requires, run.RunID),
+		Meta:     meta,
+	}}, nil
+}
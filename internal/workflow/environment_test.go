@@ -0,0 +1,75 @@
+package workflow
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"migratorx/internal/checks"
+	"migratorx/internal/state"
+)
+
+func environmentPlanYAML() string {
+	return "" +
+		"migration: m\nsource_version: 5.7\ntarget_version: 8.0\n" +
+		"cdc:\n  type: debezium\n  connector: c\n" +
+		"steps: [preflight]\n" +
+		"environments:\n" +
+		"  - name: staging\n    topology:\n      primary: stg-primary\n      replicas: [stg-replica]\n" +
+		"    cdc:\n      type: debezium\n      connector: stg\n" +
+		"  - name: production\n    requires: staging\n    topology:\n      primary: prod-primary\n      replicas: [prod-replica]\n"
+}
+
+func TestLoadPlan_EnvironmentsSelectTopology(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "migration.yaml")
+	if err := os.WriteFile(path, []byte(environmentPlanYAML()), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	plan, err := LoadPlan(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := plan.ForEnvironment(""); err == nil || !strings.Contains(err.Error(), "--env") {
+		t.Fatalf("expected an environment to be required, got %v", err)
+	}
+	staging, err := plan.ForEnvironment("staging")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if staging.Topology.Primary != "stg-primary" || staging.CDC.Connector != "stg" || staging.StateName() != "m@staging" {
+		t.Fatalf("unexpected staging plan: %+v", staging)
+	}
+	production, _ := plan.ForEnvironment("production")
+	if production.Topology.Primary != "prod-primary" || production.CDC.Connector != "c" {
+		t.Fatalf("unexpected production plan: %+v", production)
+	}
+
+	plan.Environments[0].Requires = "production"
+	if err := plan.Validate(); err == nil || !strings.Contains(err.Error(), "requires cycle") {
+		t.Fatalf("expected requires cycle to be rejected, got %v", err)
+	}
+}
+
+func TestEnvironmentGate_RequiresRecordedRunOfSamePlan(t *testing.T) {
+	backend := NewMemoryState()
+	gate := &EnvironmentGate{Backend: backend, Migration: "m", Environment: "production", Requires: "staging", PlanHash: "h2"}
+
+	findings, err := gate.Run(context.Background(), checks.Input{})
+	if err != nil || len(findings) != 1 || findings[0].Code != CodeEnvironmentPrerequisiteMissing {
+		t.Fatalf("expected missing prerequisite, got %+v (%v)", findings, err)
+	}
+
+	state.RecordEnvironmentRun(backend, "m", "staging", state.EnvironmentRun{RunID: "r1", PlanHash: "h1"})
+	findings, _ = gate.Run(context.Background(), checks.Input{})
+	if len(findings) != 1 || findings[0].Code != CodeEnvironmentPrerequisiteStale || findings[0].Severity != checks.SeverityBlock {
+		t.Fatalf("expected stale prerequisite, got %+v", findings)
+	}
+
+	state.RecordEnvironmentRun(backend, "m", "staging", state.EnvironmentRun{RunID: "r2", PlanHash: "h2"})
+	findings, _ = gate.Run(context.Background(), checks.Input{})
+	if len(findings) != 1 || findings[0].Code != CodeEnvironmentPrerequisiteOK || findings[0].Meta["run_id"] != "r2" {
+		t.Fatalf("expected prerequisite satisfied, got %+v", findings)
+	}
+}
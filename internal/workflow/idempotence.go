@@ -0,0 +1,128 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+)
+
+// ActionLog records the changes steps make to target systems. Recorded
+// environments (simulated actions, fakes in tests) append to it so the
+// IdempotenceHarness can see what a step did. A nil log records nothing.
+type ActionLog struct {
+	mu      sync.Mutex
+	actions []string
+}
+
+// Record appends an action, e.g. "stop_replication mysql-replica-1".
+func (l *ActionLog) Record(action string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.actions = append(l.actions, action)
+}
+
+// Len returns the number of actions recorded so far.
+func (l *ActionLog) Len() int {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.actions)
+}
+
+// Since returns the actions recorded after the first n.
+func (l *ActionLog) Since(n int) []string {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if n >= len(l.actions) {
+		return nil
+	}
+	return append([]string{}, l.actions[n:]...)
+}
+
+// IdempotenceHarness enforces the contract the Runner only asserts through
+// Step.Idempotent: each step is run twice in a row against the same State and
+// a recorded environment whose actions go to Actions. The second run must
+// perform no actions and produce equivalent findings: the same number of WARN
+// and BLOCK findings. INFO findings narrate progress and may shrink, e.g. to
+// one saying a checkpoint was found. A read-only step must perform no actions
+// at all. Steps run in order and share State, as under the Runner, but
+// completed steps are not skipped: the point is to rerun them, as a resumed
+// run would after a crash before the step was marked completed.
+//
+// The harness is a developer and testing mode. Never point it at real
+// systems: every step, mutating ones included, runs twice.
+type IdempotenceHarness struct {
+	Steps   []Step
+	State   State
+	Actions *ActionLog
+	Logger  *log.Logger
+}
+
+// Run verifies each step and returns one finding per step: INFO when it
+// holds to the contract, BLOCK with the offending actions or findings when it
+// does not. Meta carries "step" and "idempotent". A returned error means the
+// context was canceled.
+func (h *IdempotenceHarness) Run(ctx context.Context) ([]Finding, error) {
+	if h.State == nil {
+		h.State = NewMemoryState()
+	}
+	if h.Logger == nil {
+		h.Logger = log.Default()
+	}
+	findings := []Finding{}
+	for _, step := range h.Steps {
+		if err := ctx.Err(); err != nil {
+			return findings, err
+		}
+		h.Logger.Printf("verifying idempotence of step: %s", step.Name())
+		mark := h.Actions.Len()
+		first, firstErr := step.Run(ctx, h.State)
+		firstActions := h.Actions.Since(mark)
+		mark = h.Actions.Len()
+		second, secondErr := step.Run(ctx, h.State)
+		secondActions := h.Actions.Since(mark)
+
+		meta := map[string]interface{}{"step": step.Name(), "first_run_actions": len(firstActions), "second_run_actions": len(secondActions)}
+		problems := []string{}
+		if !step.Mutates() && len(firstActions) > 0 {
+			problems = append(problems, fmt.Sprintf("read-only step performed %d actions: %s", len(firstActions), strings.Join(firstActions, ", ")))
+		}
+		if len(secondActions) > 0 {
+			problems = append(problems, fmt.Sprintf("second run performed %d actions: %s", len(secondActions), strings.Join(secondActions, ", ")))
+			meta["actions"] = secondActions
+		}
+		if (firstErr == nil) != (secondErr == nil) {
+			problems = append(problems, fmt.Sprintf("first run error %v, second run error %v", firstErr, secondErr))
+		}
+		for _, sv := range []Severity{SeverityBlock, SeverityWarn} {
+			if a, b := countSeverity(first.Findings, sv), countSeverity(second.Findings, sv); a != b {
+				problems = append(problems, fmt.Sprintf("%d %s findings on the first run, %d on the second", a, sv, b))
+			}
+		}
+		meta["idempotent"] = len(problems) == 0
+		if len(problems) > 0 {
+			findings = append(findings, Finding{
+				Severity: SeverityBlock,
+				Message:  fmt.Sprintf("step %q is not idempotent: %s", step.Name(), strings.Join(problems, "; ")),
+				Meta:     meta,
+			})
+			continue
+		}
+		findings = append(findings, Finding{
+			Severity: SeverityInfo,
+			Message:  fmt.Sprintf("step %q is idempotent: its second run performed no actions and matched the first run's findings", step.Name()),
+			Meta:     meta,
+		})
+	}
+	return findings, nil
+}
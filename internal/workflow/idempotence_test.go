@@ -0,0 +1,65 @@
+package workflow
+
+import (
+	"context"
+	"io"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestIdempotenceHarness_PassesCheckpointedStep(t *testing.T) {
+	actions := &ActionLog{}
+	step := NewMutatingStep("stop_replication", func(ctx context.Context, st State) (StepResult, error) {
+		if done, _ := st.Get("stopped"); done == true {
+			return StepResult{Findings: []Finding{{Severity: SeverityInfo, Message: "already stopped"}}}, nil
+		}
+		actions.Record("stop_replication mysql-replica-1")
+		st.Set("stopped", true)
+		return StepResult{Findings: []Finding{{Severity: SeverityInfo, Message: "replication stopped"}, {Severity: SeverityInfo, Message: "checkpoint saved"}}}, nil
+	})
+	harness := &IdempotenceHarness{Steps: []Step{step}, Actions: actions, Logger: log.New(io.Discard, "", 0)}
+	findings, err := harness.Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Severity != SeverityInfo || findings[0].Meta["idempotent"] != true || findings[0].Meta["first_run_actions"] != 1 {
+		t.Fatalf("expected the checkpointed step to pass, got %+v", findings)
+	}
+}
+
+func TestIdempotenceHarness_BlocksRepeatedActionsAndChangedFindings(t *testing.T) {
+	actions := &ActionLog{}
+	runs := 0
+	repeats := NewMutatingStep("upgrade", func(ctx context.Context, st State) (StepResult, error) {
+		actions.Record("run_upgrade mysql-replica-1")
+		return StepResult{}, nil
+	})
+	drifts := NewReadOnlyStep("check", func(ctx context.Context, st State) (StepResult, error) {
+		runs++
+		if runs > 1 {
+			return StepResult{Findings: []Finding{{Severity: SeverityWarn, Message: "changed"}}}, nil
+		}
+		return StepResult{}, nil
+	})
+	sneaky := NewReadOnlyStep("inspect", func(ctx context.Context, st State) (StepResult, error) {
+		if _, ok := st.Get("touched"); !ok {
+			actions.Record("set_global mysql-replica-1")
+			st.Set("touched", true)
+		}
+		return StepResult{}, nil
+	})
+	harness := &IdempotenceHarness{Steps: []Step{repeats, drifts, sneaky}, Actions: actions, Logger: log.New(io.Discard, "", 0)}
+	findings, err := harness.Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 3 {
+		t.Fatalf("expected a finding per step, got %+v", findings)
+	}
+	for i, want := range []string{"second run performed 1 actions: run_upgrade mysql-replica-1", "0 WARN findings on the first run, 1 on the second", "read-only step performed 1 actions"} {
+		if findings[i].Severity != SeverityBlock || findings[i].Meta["idempotent"] != false || !strings.Contains(findings[i].Message, want) {
+			t.Fatalf("finding %d: expected BLOCK mentioning %q, got %+v", i, want, findings[i])
+		}
+	}
+}
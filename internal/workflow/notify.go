@@ -0,0 +1,26 @@
+package workflow
+
+import "context"
+
+// Notification events.
+const (
+	EventAutoRollback    = "auto_rollback"
+	EventOldPrimaryWrite = "old_primary_write"
+	// EventStepDurationAnomaly is sent while a step runs well past its
+	// historical p95.
+	EventStepDurationAnomaly = "step_duration_anomaly"
+)
+
+// Notification tells on-call channels that the workflow acted without a
+// human, e.g. an automatic rollback after a failed post-validation.
+type Notification struct {
+	Migration string                 `json:"migration"`
+	Event     string                 `json:"event"`
+	Message   string                 `json:"message"`
+	Meta      map[string]interface{} `json:"meta,omitempty"`
+}
+
+// Notifier delivers notifications.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
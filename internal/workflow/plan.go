@@ -2,7 +2,11 @@ package workflow
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"time"
+
+	"migratorx/internal/checks"
 )
 
 // SupportedSteps defines the canonical step order for migration plans.
@@ -15,26 +19,499 @@ var SupportedSteps = []string{
 	"post_validation",
 }
 
+// StepChecks maps plan steps to the check names that must gate promotion when
+// the step is part of the plan.
+var StepChecks = map[string][]string{
+	"validate_replica": {"schema_parity"},
+	"cdc_check":        {"cdc_debezium_health"},
+}
+
 // MigrationPlan models the declarative migration plan (Section 5).
+// Remediation overrides the built-in remediation text per finding code.
 type MigrationPlan struct {
-	Migration     string    `yaml:"migration"`
-	SourceVersion string    `yaml:"source_version"`
-	TargetVersion string    `yaml:"target_version"`
-	Topology      Topology  `yaml:"topology"`
-	CDC           CDCConfig `yaml:"cdc"`
-	Steps         []string  `yaml:"steps"`
+	Migration       string                          `yaml:"migration"`
+	SourceVersion   string                          `yaml:"source_version"`
+	TargetVersion   string                          `yaml:"target_version"`
+	Topology        Topology                        `yaml:"topology"`
+	CDC             CDCConfig                       `yaml:"cdc"`
+	Steps           []string                        `yaml:"steps"`
+	PostValidation  PostValidationConfig            `yaml:"post_validation"`
+	Promotion       PromotionConfig                 `yaml:"promotion"`
+	Access          AccessConfig                    `yaml:"access"`
+	Remediation     map[string]string               `yaml:"remediation"`
+	Inspection      InspectionConfig                `yaml:"inspection"`
+	DataParity      DataParityConfig                `yaml:"data_parity"`
+	DDLFreeze       DDLFreezeConfig                 `yaml:"ddl_freeze"`
+	BinlogRetention BinlogRetentionConfig           `yaml:"binlog_retention"`
+	Drain           []DrainConfig                   `yaml:"drain"`
+	Thresholds      ThresholdsConfig                `yaml:"thresholds"`
+	Notifications   NotificationsConfig             `yaml:"notifications"`
+	Replication     ReplicationConfig               `yaml:"replication"`
+	Clients         []ClientConfig                  `yaml:"clients"`
+	ServerIdentity  map[string]ServerIdentityConfig `yaml:"server_identity"`
+	Environments    []EnvironmentConfig             `yaml:"environments"`
+	Statistics      StatisticsConfig                `yaml:"statistics"`
+	Warmup          WarmupConfig                    `yaml:"warmup"`
+	ReadPool        ReadPoolConfig                  `yaml:"read_pool"`
+	TrafficCalendar TrafficCalendarConfig           `yaml:"traffic_calendar"`
+
+	// SelectedEnvironment is set by ForEnvironment.
+	SelectedEnvironment string `yaml:"-"`
 }
 
-// Topology models primary/replica relationships.
+// Topology models primary/replica relationships. Regions labels hosts with
+// the region they run in; a replica whose region differs from the primary's
+// is cross-region. Cross-region replicas lag by design, so they are held to
+// thresholds.max_cross_region_lag, and are only promoted when listed in
+// Promotable. Aliases maps members to the address they are dialed at (a DNS
+// name or IP) when it is not the member name itself; findings and state keep
+// using member names. With ResolveDNS, every command that opens the run's
+// state resolves each member and warns when it no longer resolves to the IPs
+// pinned at run start (see ResolutionGate).
 type Topology struct {
-	Primary  string   `yaml:"primary"`
-	Replicas []string `yaml:"replicas"`
+	Primary    string            `yaml:"primary"`
+	Replicas   []string          `yaml:"replicas"`
+	Regions    map[string]string `yaml:"regions"`
+	Promotable []string          `yaml:"promotable"`
+	Aliases    map[string]string `yaml:"aliases"`
+	ResolveDNS bool              `yaml:"resolve_dns"`
+}
+
+// Members returns the primary and replicas, once each.
+func (t Topology) Members() []string {
+	members := []string{}
+	seen := map[string]bool{}
+	for _, host := range append([]string{t.Primary}, t.Replicas...) {
+		if host == "" || seen[host] {
+			continue
+		}
+		seen[host] = true
+		members = append(members, host)
+	}
+	return members
+}
+
+// Address returns the address host is dialed at: its alias, or host itself.
+func (t Topology) Address(host string) string {
+	if alias := strings.TrimSpace(t.Aliases[host]); alias != "" {
+		return alias
+	}
+	return host
+}
+
+// Region returns the region of host, or "" when it is not labeled.
+func (t Topology) Region(host string) string {
+	return t.Regions[host]
+}
+
+// CrossRegion reports whether host is labeled with another region than the
+// primary. Unlabeled hosts are never cross-region.
+func (t Topology) CrossRegion(host string) bool {
+	region, primary := t.Region(host), t.Region(t.Primary)
+	return region != "" && primary != "" && region != primary
+}
+
+// PromotionCandidates returns the replicas that may be promoted, in plan
+// order: those in the primary's region, plus cross-region replicas tagged in
+// Promotable.
+func (t Topology) PromotionCandidates() []string {
+	tagged := map[string]bool{}
+	for _, host := range t.Promotable {
+		tagged[host] = true
+	}
+	candidates := []string{}
+	for _, replica := range t.Replicas {
+		if !t.CrossRegion(replica) || tagged[replica] {
+			candidates = append(candidates, replica)
+		}
+	}
+	return candidates
+}
+
+// validateRegions checks that region labels and promotion tags name topology
+// members; prefix locates the topology in the plan.
+func (t Topology) validateRegions(prefix string) []string {
+	problems := []string{}
+	members := map[string]bool{t.Primary: true}
+	replicas := map[string]bool{}
+	for _, r := range t.Replicas {
+		members[r] = true
+		replicas[r] = true
+	}
+	hosts := make([]string, 0, len(t.Regions))
+	for host := range t.Regions {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	for _, host := range hosts {
+		switch {
+		case !members[host]:
+			problems = append(problems, fmt.Sprintf("%s.regions names %q, which is not in the topology", prefix, host))
+		case strings.TrimSpace(t.Regions[host]) == "":
+			problems = append(problems, fmt.Sprintf("%s.regions[%q] is empty", prefix, host))
+		}
+	}
+	for i, host := range t.Promotable {
+		if !replicas[host] {
+			problems = append(problems, fmt.Sprintf("%s.promotable[%d]=%q is not a replica", prefix, i, host))
+		}
+	}
+	return problems
+}
+
+// validateAliases checks that aliases name topology members and give each an
+// address; prefix locates the topology in the plan.
+func (t Topology) validateAliases(prefix string) []string {
+	problems := []string{}
+	members := map[string]bool{}
+	for _, host := range t.Members() {
+		members[host] = true
+	}
+	hosts := make([]string, 0, len(t.Aliases))
+	for host := range t.Aliases {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	for _, host := range hosts {
+		switch {
+		case !members[host]:
+			problems = append(problems, fmt.Sprintf("%s.aliases names %q, which is not in the topology", prefix, host))
+		case strings.TrimSpace(t.Aliases[host]) == "":
+			problems = append(problems, fmt.Sprintf("%s.aliases[%q] is empty", prefix, host))
+		}
+	}
+	return problems
 }
 
 // CDCConfig models CDC settings.
+// ServerID is the connector's database.server.id, which must not collide with
+// any topology member. ConnectWorkers are the REST URLs of the Kafka Connect
+// workers running the connector. Principal is the connector's Kafka principal
+// (e.g. User:debezium); TopicPrefix and SchemaHistoryTopic name the topics it
+// needs access to, and PeakByteRate is the produce rate in bytes per second
+// expected during the cutover window. AutoRemediation maps CDC finding codes
+// to automatic remediations; SignalCommand is the argv that triggers an
+// incremental snapshot, with {connector} and {tables} substituted. KeyTables
+// are the tables whose CDC message schemas are compared across the upgrade.
+// MaxThroughputDrop is the share of the pre-upgrade event rate the connector
+// may lose during the soak (default 0.25).
 type CDCConfig struct {
-	Type      string `yaml:"type"`
-	Connector string `yaml:"connector"`
+	Type               string                 `yaml:"type"`
+	Connector          string                 `yaml:"connector"`
+	ServerID           uint32                 `yaml:"server_id"`
+	ConnectWorkers     []string               `yaml:"connect_workers"`
+	Principal          string                 `yaml:"principal"`
+	TopicPrefix        string                 `yaml:"topic_prefix"`
+	SchemaHistoryTopic string                 `yaml:"schema_history_topic"`
+	PeakByteRate       int64                  `yaml:"peak_byte_rate"`
+	SignalCommand      []string               `yaml:"signal_command"`
+	AutoRemediation    []CDCRemediationPolicy `yaml:"auto_remediation"`
+	KeyTables          []string               `yaml:"key_tables"`
+	MaxThroughputDrop  float64                `yaml:"max_throughput_drop"`
+}
+
+// CDCRemediationPolicy applies Action to findings with Code, at most
+// MaxAttempts times (default 3) before escalating to BLOCK. For
+// restart_task, RetryableTraces limits restarts to failed tasks whose trace
+// contains one of the substrings; without it every failed task is restarted.
+type CDCRemediationPolicy struct {
+	Code            string   `yaml:"code"`
+	Action          string   `yaml:"action"`
+	MaxAttempts     int      `yaml:"max_attempts"`
+	RetryableTraces []string `yaml:"retryable_traces"`
+}
+
+// CDC remediation actions.
+const (
+	RemediationRestartTask         = "restart_task"
+	RemediationIncrementalSnapshot = "incremental_snapshot"
+)
+
+// PostValidationConfig models post-promotion verification settings.
+// Endpoint is the application's connection endpoint (proxy or DNS name) used for
+// the heartbeat write-path probe. OnBlock selects what happens when
+// post-validation blocks: halt (default) or auto_rollback. SoakDuration, when
+// set, is how long the demoted primary is watched for writes, polling every
+// SoakInterval (default 10s).
+type PostValidationConfig struct {
+	Endpoint     string        `yaml:"endpoint"`
+	ProbeTable   string        `yaml:"probe_table"`
+	OnBlock      string        `yaml:"on_block"`
+	SoakDuration time.Duration `yaml:"soak_duration"`
+	SoakInterval time.Duration `yaml:"soak_interval"`
+}
+
+// Post-validation on_block policies.
+const (
+	OnBlockHalt         = "halt"
+	OnBlockAutoRollback = "auto_rollback"
+)
+
+// ReplicationConfig holds requirements on the replication channels.
+type ReplicationConfig struct {
+	RequireTLS bool `yaml:"require_tls"`
+}
+
+// NotificationsConfig configures where workflow notifications go. Command
+// receives each notification as JSON on stdin.
+type NotificationsConfig struct {
+	Command         []string              `yaml:"command"`
+	DurationAnomaly DurationAnomalyConfig `yaml:"duration_anomaly"`
+}
+
+// DurationAnomalyConfig tunes the step duration anomaly notification: it is
+// sent when a step is still running Factor times past the p95 of its last
+// runs, once at least MinSamples runs have been recorded.
+type DurationAnomalyConfig struct {
+	Factor     float64 `yaml:"factor"`
+	MinSamples int     `yaml:"min_samples"`
+}
+
+// PromotionConfig models promotion gate policy. AllowWarnCodes lists WARN
+// finding codes that do not block promotion; by default every WARN blocks.
+// RequiredChecks names additional (custom) checks the gate must see.
+// FreshnessWindow, when set, is how old a required gate result may be when
+// promotion executes. Decision, when its URL is set, must approve promotion.
+type PromotionConfig struct {
+	AllowWarnCodes  []string       `yaml:"allow_warn_codes"`
+	RequiredChecks  []string       `yaml:"required_checks"`
+	FreshnessWindow time.Duration  `yaml:"freshness_window"`
+	Decision        DecisionConfig `yaml:"decision"`
+}
+
+// InspectionConfig limits the load live inspections put on servers. Zero
+// values mean unlimited; MaxReplicaLag pauses scanning while a replica lags
+// further behind than the threshold.
+type InspectionConfig struct {
+	MaxQPS         float64       `yaml:"max_qps"`
+	MaxConcurrency int           `yaml:"max_concurrency"`
+	MaxReplicaLag  time.Duration `yaml:"max_replica_lag"`
+}
+
+// ThresholdsConfig holds SLO limits that checks measure against. Zero
+// durations and a nil MaxWarnCount leave the threshold unset.
+// MaintenanceWindow is how long replicas and CDC may be paused during the
+// upgrade; binlog retention must outlast it. MaxPrimaryThreadsRunning and
+// MinServingReplicas hold back further replica upgrades while the primary is
+// busy or too few healthy replicas would be left serving reads.
+// MaxClockSkew is how far apart the topology's clocks may drift.
+// MaxCrossRegionLag replaces MaxLag for cross-region replicas.
+type ThresholdsConfig struct {
+	MaxLag                   time.Duration `yaml:"max_lag"`
+	MaxCDCLatency            time.Duration `yaml:"max_cdc_latency"`
+	MaxWarnCount             *int          `yaml:"max_warn_count"`
+	MaxCutoverDuration       time.Duration `yaml:"max_cutover_duration"`
+	MaintenanceWindow        time.Duration `yaml:"maintenance_window"`
+	MaxPrimaryThreadsRunning int           `yaml:"max_primary_threads_running"`
+	MinServingReplicas       int           `yaml:"min_serving_replicas"`
+	MaxClockSkew             time.Duration `yaml:"max_clock_skew"`
+	MaxCrossRegionLag        time.Duration `yaml:"max_cross_region_lag"`
+}
+
+// Lag returns the replica lag threshold, if set.
+func (t ThresholdsConfig) Lag() (checks.Threshold, bool) {
+	return checks.DurationThreshold("max_lag", t.MaxLag), t.MaxLag > 0
+}
+
+// CDCLatency returns the CDC latency threshold, if set.
+func (t ThresholdsConfig) CDCLatency() (checks.Threshold, bool) {
+	return checks.DurationThreshold("max_cdc_latency", t.MaxCDCLatency), t.MaxCDCLatency > 0
+}
+
+// WarnCount returns the WARN budget, if set.
+func (t ThresholdsConfig) WarnCount() (checks.Threshold, bool) {
+	if t.MaxWarnCount == nil {
+		return checks.Threshold{}, false
+	}
+	return checks.Threshold{Name: "max_warn_count", Limit: float64(*t.MaxWarnCount), Unit: "findings"}, true
+}
+
+// CutoverDuration returns the cutover duration budget, if set.
+func (t ThresholdsConfig) CutoverDuration() (checks.Threshold, bool) {
+	return checks.DurationThreshold("max_cutover_duration", t.MaxCutoverDuration), t.MaxCutoverDuration > 0
+}
+
+// DataParityConfig lists the tables compared by the data parity check.
+type DataParityConfig struct {
+	Tables []DataParityTable `yaml:"tables"`
+}
+
+// DataParityTable selects the comparison mode for one table: "checksum"
+// (default, full chunked checksum) or "sample" (random-row sampling).
+type DataParityTable struct {
+	Name       string  `yaml:"name"`
+	Mode       string  `yaml:"mode"`
+	SampleSize int     `yaml:"sample_size"`
+	Confidence float64 `yaml:"confidence"`
+}
+
+// TableNames returns the configured table names in plan order.
+func (c DataParityConfig) TableNames() []string {
+	names := make([]string, 0, len(c.Tables))
+	for _, t := range c.Tables {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+// Options returns per-table options for checks.DataParityCheck.
+func (c DataParityConfig) Options() map[string]checks.TableParityOptions {
+	opts := make(map[string]checks.TableParityOptions, len(c.Tables))
+	for _, t := range c.Tables {
+		mode := t.Mode
+		if mode == "" {
+			mode = checks.ParityModeChecksum
+		}
+		opts[t.Name] = checks.TableParityOptions{Mode: mode, SampleSize: t.SampleSize, Confidence: t.Confidence}
+	}
+	return opts
+}
+
+// DDLFreezeConfig freezes schema changes on the primary from the first replica
+// upgrade until promotion through a lock table (default
+// migratorx.ddl_freeze) that schema change tooling checks.
+type DDLFreezeConfig struct {
+	Enabled   bool   `yaml:"enabled"`
+	LockTable string `yaml:"lock_table"`
+}
+
+// BinlogRetentionConfig raises the primary's binlog expiry to Margin (default
+// 2) times thresholds.maintenance_window for the upgrade when Extend is set,
+// and restores it once post-validation completes or rolls back.
+type BinlogRetentionConfig struct {
+	Extend bool `yaml:"extend"`
+	Margin int  `yaml:"margin"`
+}
+
+// StatisticsConfig controls the optimizer statistics rebuild after a replica
+// upgrade. Tables (schema-qualified) defaults to every InnoDB base table;
+// Histograms lists columns that get a histogram. MaxAge is how old a table's
+// persistent statistics may be once the rebuild finishes (default 1h).
+type StatisticsConfig struct {
+	Tables     []string          `yaml:"tables"`
+	Histograms []HistogramConfig `yaml:"histograms"`
+	MaxAge     time.Duration     `yaml:"max_age"`
+}
+
+// HistogramConfig requests a histogram on Columns of Table with Buckets
+// buckets (default 100, at most 1024).
+type HistogramConfig struct {
+	Table   string   `yaml:"table"`
+	Columns []string `yaml:"columns"`
+	Buckets int      `yaml:"buckets"`
+}
+
+// WarmupConfig controls the buffer pool warmup of the promotion candidate,
+// which promote prepare runs before the gate when Enabled is set. Queries,
+// when set, replace innodb_buffer_pool_load_now and run in a
+// read-only session; Timeout bounds the warmup (default 30m). MinHitRatio
+// (between 0 and 1) is the buffer pool hit ratio the candidate must reach
+// before cutover, measured over SampleInterval (default 10s).
+type WarmupConfig struct {
+	Enabled        bool          `yaml:"enabled"`
+	Queries        []string      `yaml:"queries"`
+	Timeout        time.Duration `yaml:"timeout"`
+	MinHitRatio    float64       `yaml:"min_hit_ratio"`
+	SampleInterval time.Duration `yaml:"sample_interval"`
+}
+
+// DrainConfig declares how a replica is taken out of read traffic before its
+// upgrade. Type "haproxy" uses the runtime API at Address for Backend (Server
+// defaults to the replica name); type "proxysql" sets the replica's weight
+// to 0 through the admin interface at Address (host:port); type "command"
+// runs DrainCommand and UndrainCommand, with "{replica}" substituted, e.g. to
+// flip a Kubernetes readiness gate.
+type DrainConfig struct {
+	Type           string   `yaml:"type"`
+	Name           string   `yaml:"name"`
+	Address        string   `yaml:"address"`
+	Backend        string   `yaml:"backend"`
+	Server         string   `yaml:"server"`
+	DrainCommand   []string `yaml:"drain_command"`
+	UndrainCommand []string `yaml:"undrain_command"`
+}
+
+// ClientConfig describes what an application's MySQL connector supports. Empty
+// lists mean the client accepts whatever the server offers. Users, Hosts and
+// Programs are glob patterns identifying the application's connections (user,
+// client host, program_name attribute) for the connected applications
+// inventory.
+type ClientConfig struct {
+	Name        string   `yaml:"name"`
+	Driver      string   `yaml:"driver"`
+	TLSVersions []string `yaml:"tls_versions"`
+	Ciphers     []string `yaml:"ciphers"`
+	AuthPlugins []string `yaml:"auth_plugins"`
+	Users       []string `yaml:"users"`
+	Hosts       []string `yaml:"hosts"`
+	Programs    []string `yaml:"programs"`
+}
+
+// ServerIdentityConfig is the server_id and server_uuid a host is expected to
+// have after the upgrade. Unset fields are not checked; set them to the new
+// values when the upgrade changes a host's identity on purpose.
+type ServerIdentityConfig struct {
+	ServerID   uint32 `yaml:"server_id"`
+	ServerUUID string `yaml:"server_uuid"`
+}
+
+// AccessConfig enables role-based authorization. When TokensFile is set every
+// command must present an identity token holding the command's role; a
+// relative path is resolved against the plan file's directory.
+type AccessConfig struct {
+	TokensFile string `yaml:"tokens_file"`
+}
+
+// LagLimit returns the replica lag threshold for host: max_cross_region_lag
+// for a cross-region replica when set, max_lag otherwise. Zero means unset.
+func (p MigrationPlan) LagLimit(host string) time.Duration {
+	if p.Topology.CrossRegion(host) && p.Thresholds.MaxCrossRegionLag > 0 {
+		return p.Thresholds.MaxCrossRegionLag
+	}
+	return p.Thresholds.MaxLag
+}
+
+// CrossRegionLagLimits returns max_cross_region_lag for each cross-region
+// replica, or nil when it is unset, for components that take per-replica lag
+// overrides.
+func (p MigrationPlan) CrossRegionLagLimits() map[string]time.Duration {
+	if p.Thresholds.MaxCrossRegionLag <= 0 {
+		return nil
+	}
+	limits := map[string]time.Duration{}
+	for _, replica := range p.Topology.Replicas {
+		if p.Topology.CrossRegion(replica) {
+			limits[replica] = p.Thresholds.MaxCrossRegionLag
+		}
+	}
+	return limits
+}
+
+// RequiredCheckNames derives the checks promotion requires from the plan's
+// steps (see StepChecks) plus promotion.required_checks, in a stable order.
+func (p MigrationPlan) RequiredCheckNames() []string {
+	seen := map[string]struct{}{}
+	names := []string{}
+	add := func(name string) {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return
+		}
+		if _, ok := seen[name]; ok {
+			return
+		}
+		seen[name] = struct{}{}
+		names = append(names, name)
+	}
+	for _, step := range p.Steps {
+		for _, name := range StepChecks[strings.TrimSpace(step)] {
+			add(name)
+		}
+	}
+	for _, name := range p.Promotion.RequiredChecks {
+		add(name)
+	}
+	return names
 }
 
 // Validate enforces required fields, supported step names, and valid step ordering.
@@ -51,17 +528,24 @@ func (p MigrationPlan) Validate() error {
 		problems = append(problems, "target_version is required")
 	}
 
-	if strings.TrimSpace(p.Topology.Primary) == "" {
-		problems = append(problems, "topology.primary is required")
-	}
-	if len(p.Topology.Replicas) == 0 {
-		problems = append(problems, "topology.replicas must include at least one replica")
+	// With environments, each environment carries its own topology.
+	if len(p.Environments) > 0 {
+		problems = append(problems, p.validateEnvironments()...)
 	} else {
-		for i, r := range p.Topology.Replicas {
-			if strings.TrimSpace(r) == "" {
-				problems = append(problems, fmt.Sprintf("topology.replicas[%d] is empty", i))
+		if strings.TrimSpace(p.Topology.Primary) == "" {
+			problems = append(problems, "topology.primary is required")
+		}
+		if len(p.Topology.Replicas) == 0 {
+			problems = append(problems, "topology.replicas must include at least one replica")
+		} else {
+			for i, r := range p.Topology.Replicas {
+				if strings.TrimSpace(r) == "" {
+					problems = append(problems, fmt.Sprintf("topology.replicas[%d] is empty", i))
+				}
 			}
 		}
+		problems = append(problems, p.Topology.validateRegions("topology")...)
+		problems = append(problems, p.Topology.validateAliases("topology")...)
 	}
 
 	if strings.TrimSpace(p.CDC.Type) == "" {
@@ -71,6 +555,222 @@ func (p MigrationPlan) Validate() error {
 		problems = append(problems, "cdc.connector is required")
 	}
 
+	if p.PostValidation.ProbeTable != "" && strings.Count(p.PostValidation.ProbeTable, ".") != 1 {
+		problems = append(problems, "post_validation.probe_table must be schema-qualified (db.table)")
+	}
+	if p.DDLFreeze.LockTable != "" && strings.Count(p.DDLFreeze.LockTable, ".") != 1 {
+		problems = append(problems, "ddl_freeze.lock_table must be schema-qualified (db.table)")
+	}
+	if p.BinlogRetention.Extend && p.Thresholds.MaintenanceWindow <= 0 {
+		problems = append(problems, "binlog_retention.extend requires thresholds.maintenance_window")
+	}
+	if p.BinlogRetention.Margin < 0 {
+		problems = append(problems, "binlog_retention.margin must not be negative")
+	}
+	if p.PostValidation.SoakDuration < 0 || p.PostValidation.SoakInterval < 0 {
+		problems = append(problems, "post_validation.soak_duration and soak_interval must not be negative")
+	}
+	switch p.PostValidation.OnBlock {
+	case "", OnBlockHalt, OnBlockAutoRollback:
+	default:
+		problems = append(problems, fmt.Sprintf("post_validation.on_block=%q is not supported (expected halt or auto_rollback)", p.PostValidation.OnBlock))
+	}
+
+	for i, code := range p.Promotion.AllowWarnCodes {
+		if strings.TrimSpace(code) == "" {
+			problems = append(problems, fmt.Sprintf("promotion.allow_warn_codes[%d] is empty", i))
+		}
+	}
+	for i, name := range p.Promotion.RequiredChecks {
+		if strings.TrimSpace(name) == "" {
+			problems = append(problems, fmt.Sprintf("promotion.required_checks[%d] is empty", i))
+		}
+	}
+
+	if p.Promotion.FreshnessWindow < 0 {
+		problems = append(problems, "promotion.freshness_window must not be negative")
+	}
+	if d := p.Promotion.Decision; d.URL != "" && !strings.HasPrefix(d.URL, "http://") && !strings.HasPrefix(d.URL, "https://") {
+		problems = append(problems, fmt.Sprintf("promotion.decision.url=%q must be an http(s) URL", d.URL))
+	}
+	if p.Promotion.Decision.Timeout < 0 {
+		problems = append(problems, "promotion.decision.timeout must not be negative")
+	}
+
+	if p.Inspection.MaxQPS < 0 {
+		problems = append(problems, "inspection.max_qps must not be negative")
+	}
+	if p.Inspection.MaxConcurrency < 0 {
+		problems = append(problems, "inspection.max_concurrency must not be negative")
+	}
+	if p.Inspection.MaxReplicaLag < 0 {
+		problems = append(problems, "inspection.max_replica_lag must not be negative")
+	}
+
+	if p.Notifications.DurationAnomaly.Factor != 0 && p.Notifications.DurationAnomaly.Factor < 1 {
+		problems = append(problems, "notifications.duration_anomaly.factor must be at least 1")
+	}
+	if p.Notifications.DurationAnomaly.MinSamples < 0 {
+		problems = append(problems, "notifications.duration_anomaly.min_samples must not be negative")
+	}
+
+	if p.Thresholds.MaxLag < 0 {
+		problems = append(problems, "thresholds.max_lag must not be negative")
+	}
+	if p.Thresholds.MaxCDCLatency < 0 {
+		problems = append(problems, "thresholds.max_cdc_latency must not be negative")
+	}
+	if p.Thresholds.MaxWarnCount != nil && *p.Thresholds.MaxWarnCount < 0 {
+		problems = append(problems, "thresholds.max_warn_count must not be negative")
+	}
+	if p.Thresholds.MaxCutoverDuration < 0 {
+		problems = append(problems, "thresholds.max_cutover_duration must not be negative")
+	}
+	if p.Thresholds.MaintenanceWindow < 0 {
+		problems = append(problems, "thresholds.maintenance_window must not be negative")
+	}
+	if p.Thresholds.MaxPrimaryThreadsRunning < 0 {
+		problems = append(problems, "thresholds.max_primary_threads_running must not be negative")
+	}
+	if p.Thresholds.MinServingReplicas < 0 {
+		problems = append(problems, "thresholds.min_serving_replicas must not be negative")
+	}
+	if p.Thresholds.MaxClockSkew < 0 {
+		problems = append(problems, "thresholds.max_clock_skew must not be negative")
+	}
+	if p.Thresholds.MaxCrossRegionLag < 0 {
+		problems = append(problems, "thresholds.max_cross_region_lag must not be negative")
+	}
+	problems = append(problems, p.ReadPool.validate()...)
+	problems = append(problems, p.TrafficCalendar.validate()...)
+	if p.CDC.PeakByteRate < 0 {
+		problems = append(problems, "cdc.peak_byte_rate must not be negative")
+	}
+	for i, w := range p.CDC.ConnectWorkers {
+		if strings.TrimSpace(w) == "" {
+			problems = append(problems, fmt.Sprintf("cdc.connect_workers[%d] is empty", i))
+		}
+	}
+	if p.CDC.MaxThroughputDrop < 0 || p.CDC.MaxThroughputDrop >= 1 {
+		problems = append(problems, "cdc.max_throughput_drop must be at least 0 and below 1")
+	}
+	for i, t := range p.CDC.KeyTables {
+		if !strings.Contains(t, ".") {
+			problems = append(problems, fmt.Sprintf("cdc.key_tables[%d]=%q must be schema-qualified (db.table)", i, t))
+		}
+	}
+	for i, r := range p.CDC.AutoRemediation {
+		if strings.TrimSpace(r.Code) == "" {
+			problems = append(problems, fmt.Sprintf("cdc.auto_remediation[%d].code is required", i))
+		}
+		switch r.Action {
+		case RemediationRestartTask:
+		case RemediationIncrementalSnapshot:
+			if len(p.CDC.SignalCommand) == 0 {
+				problems = append(problems, fmt.Sprintf("cdc.auto_remediation[%d] needs cdc.signal_command for incremental_snapshot", i))
+			}
+		default:
+			problems = append(problems, fmt.Sprintf("cdc.auto_remediation[%d].action=%q is not supported (expected restart_task or incremental_snapshot)", i, r.Action))
+		}
+		if r.MaxAttempts < 0 {
+			problems = append(problems, fmt.Sprintf("cdc.auto_remediation[%d].max_attempts must not be negative", i))
+		}
+	}
+
+	for i, t := range p.DataParity.Tables {
+		if strings.TrimSpace(t.Name) == "" {
+			problems = append(problems, fmt.Sprintf("data_parity.tables[%d].name is required", i))
+		}
+		switch t.Mode {
+		case "", checks.ParityModeChecksum, checks.ParityModeSample:
+		default:
+			problems = append(problems, fmt.Sprintf("data_parity.tables[%d].mode=%q is not supported (expected checksum or sample)", i, t.Mode))
+		}
+		if t.SampleSize < 0 {
+			problems = append(problems, fmt.Sprintf("data_parity.tables[%d].sample_size must not be negative", i))
+		}
+		if t.Confidence != 0 && (t.Confidence <= 0 || t.Confidence >= 1) {
+			problems = append(problems, fmt.Sprintf("data_parity.tables[%d].confidence must be between 0 and 1", i))
+		}
+	}
+
+	for i, d := range p.Drain {
+		switch d.Type {
+		case "haproxy":
+			if strings.TrimSpace(d.Address) == "" || strings.TrimSpace(d.Backend) == "" {
+				problems = append(problems, fmt.Sprintf("drain[%d]: haproxy requires address and backend", i))
+			}
+		case "proxysql":
+			if strings.TrimSpace(d.Address) == "" {
+				problems = append(problems, fmt.Sprintf("drain[%d]: proxysql requires address", i))
+			}
+		case "command":
+			if len(d.DrainCommand) == 0 || len(d.UndrainCommand) == 0 {
+				problems = append(problems, fmt.Sprintf("drain[%d]: command requires drain_command and undrain_command", i))
+			}
+		default:
+			problems = append(problems, fmt.Sprintf("drain[%d].type=%q is not supported (expected haproxy, proxysql or command)", i, d.Type))
+		}
+	}
+
+	for i, table := range p.Statistics.Tables {
+		if strings.Count(table, ".") != 1 {
+			problems = append(problems, fmt.Sprintf("statistics.tables[%d] must be schema-qualified (db.table)", i))
+		}
+	}
+	for i, h := range p.Statistics.Histograms {
+		if strings.Count(h.Table, ".") != 1 {
+			problems = append(problems, fmt.Sprintf("statistics.histograms[%d].table must be schema-qualified (db.table)", i))
+		}
+		if len(h.Columns) == 0 {
+			problems = append(problems, fmt.Sprintf("statistics.histograms[%d].columns must include at least one column", i))
+		}
+		if h.Buckets < 0 || h.Buckets > 1024 {
+			problems = append(problems, fmt.Sprintf("statistics.histograms[%d].buckets must be between 1 and 1024", i))
+		}
+	}
+	if p.Statistics.MaxAge < 0 {
+		problems = append(problems, "statistics.max_age must not be negative")
+	}
+
+	for i, query := range p.Warmup.Queries {
+		if strings.TrimSpace(query) == "" {
+			problems = append(problems, fmt.Sprintf("warmup.queries[%d] is empty", i))
+		}
+	}
+	if p.Warmup.Timeout < 0 {
+		problems = append(problems, "warmup.timeout must not be negative")
+	}
+	if p.Warmup.MinHitRatio < 0 || p.Warmup.MinHitRatio >= 1 {
+		problems = append(problems, "warmup.min_hit_ratio must be at least 0 and below 1")
+	}
+	if p.Warmup.SampleInterval < 0 {
+		problems = append(problems, "warmup.sample_interval must not be negative")
+	}
+
+	for i, c := range p.Clients {
+		if strings.TrimSpace(c.Name) == "" {
+			problems = append(problems, fmt.Sprintf("clients[%d].name is required", i))
+		}
+	}
+
+	identityHosts := make([]string, 0, len(p.ServerIdentity))
+	for host := range p.ServerIdentity {
+		identityHosts = append(identityHosts, host)
+	}
+	sort.Strings(identityHosts)
+	for _, host := range identityHosts {
+		if identity := p.ServerIdentity[host]; identity.ServerID == 0 && strings.TrimSpace(identity.ServerUUID) == "" {
+			problems = append(problems, fmt.Sprintf("server_identity.%s must set server_id or server_uuid", host))
+		}
+	}
+
+	for code := range p.Remediation {
+		if strings.TrimSpace(code) == "" {
+			problems = append(problems, "remediation keys must be non-empty finding codes")
+		}
+	}
+
 	if len(p.Steps) == 0 {
 		problems = append(problems, "steps must include at least one step")
 	} else {
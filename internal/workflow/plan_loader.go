@@ -1,27 +1,40 @@
 package workflow
 
 import (
-	"fmt"
+	"crypto/sha256"
+	"encoding/hex"
 	"os"
 
 	"gopkg.in/yaml.v3"
+
+	"migratorx/internal/failure"
 )
 
 // LoadPlan reads a YAML migration plan from disk and validates it.
 func LoadPlan(path string) (MigrationPlan, error) {
 	var plan MigrationPlan
 	if path == "" {
-		return plan, fmt.Errorf("plan path is required")
+		return plan, failure.Config("plan path is required")
 	}
 	b, err := os.ReadFile(path)
 	if err != nil {
-		return plan, err
+		return plan, &failure.ConfigError{Err: err}
 	}
 	if err := yaml.Unmarshal(b, &plan); err != nil {
-		return plan, err
+		return plan, &failure.ConfigError{Err: err}
 	}
 	if err := plan.Validate(); err != nil {
-		return plan, err
+		return plan, &failure.ConfigError{Err: err}
 	}
 	return plan, nil
-}
\ No newline at end of file
+}
+
+// HashPlanFile returns the hex-encoded SHA-256 of the plan file contents.
+func HashPlanFile(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -1,6 +1,12 @@
 package workflow
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
 
 func TestMigrationPlanValidate_Success(t *testing.T) {
 	plan := MigrationPlan{
@@ -96,3 +102,562 @@ func TestMigrationPlanValidate_DuplicateStep(t *testing.T) {
 		t.Fatalf("expected validation error for duplicate step")
 	}
 }
+
+func TestMigrationPlanValidate_ProbeTableMustBeQualified(t *testing.T) {
+	plan := MigrationPlan{
+		Migration:     "mysql_57_to_80",
+		SourceVersion: "5.7",
+		TargetVersion: "8.0",
+		Topology: Topology{
+			Primary:  "mysql-primary",
+			Replicas: []string{"mysql-replica-1"},
+		},
+		CDC: CDCConfig{
+			Type:      "debezium",
+			Connector: "mysql-prod",
+		},
+		Steps:          []string{"preflight", "post_validation"},
+		PostValidation: PostValidationConfig{Endpoint: "app-db", ProbeTable: "heartbeat"},
+	}
+
+	err := plan.Validate()
+	if err == nil {
+		t.Fatalf("expected validation error for unqualified probe table")
+	}
+}
+
+func TestMigrationPlanRequiredCheckNames_DerivedFromSteps(t *testing.T) {
+	plan := MigrationPlan{
+		Steps:     []string{"preflight", "validate_replica", "cdc_check", "promote"},
+		Promotion: PromotionConfig{RequiredChecks: []string{"custom_check", "schema_parity"}},
+	}
+	got := plan.RequiredCheckNames()
+	want := []string{"schema_parity", "cdc_debezium_health", "custom_check"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestLoadPlan_InspectionLimits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "migration.yaml")
+	content := "" +
+		"migration: m\nsource_version: 5.7\ntarget_version: 8.0\n" +
+		"topology:\n  primary: p\n  replicas: [r1]\n" +
+		"cdc:\n  type: debezium\n  connector: c\n" +
+		"steps: [preflight]\n" +
+		"inspection:\n  max_qps: 50\n  max_concurrency: 2\n  max_replica_lag: 30s\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	plan, err := LoadPlan(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan.Inspection.MaxQPS != 50 || plan.Inspection.MaxConcurrency != 2 || plan.Inspection.MaxReplicaLag != 30*time.Second {
+		t.Fatalf("unexpected inspection config: %+v", plan.Inspection)
+	}
+}
+
+func TestLoadPlan_Thresholds(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "migration.yaml")
+	content := "" +
+		"migration: m\nsource_version: 5.7\ntarget_version: 8.0\n" +
+		"topology:\n  primary: p\n  replicas: [r1]\n" +
+		"cdc:\n  type: debezium\n  connector: c\n" +
+		"steps: [preflight]\n" +
+		"thresholds:\n  max_lag: 10s\n  max_cdc_latency: 5s\n  max_warn_count: 0\n  max_cutover_duration: 2m\n  maintenance_window: 4h\n  max_primary_threads_running: 64\n  min_serving_replicas: 1\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	plan, err := LoadPlan(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lag, ok := plan.Thresholds.Lag(); !ok || lag.Limit != 10 {
+		t.Fatalf("unexpected lag threshold: %+v", lag)
+	}
+	if warn, ok := plan.Thresholds.WarnCount(); !ok || warn.Limit != 0 {
+		t.Fatalf("expected an explicit zero WARN budget, got %+v %v", warn, ok)
+	}
+	if cutover, ok := plan.Thresholds.CutoverDuration(); !ok || cutover.Limit != 120 {
+		t.Fatalf("unexpected cutover threshold: %+v", cutover)
+	}
+	if plan.Thresholds.MaintenanceWindow != 4*time.Hour {
+		t.Fatalf("unexpected maintenance window: %s", plan.Thresholds.MaintenanceWindow)
+	}
+	if plan.Thresholds.MaxPrimaryThreadsRunning != 64 || plan.Thresholds.MinServingReplicas != 1 {
+		t.Fatalf("unexpected upgrade guard thresholds: %+v", plan.Thresholds)
+	}
+
+	plan.Thresholds.MaxLag = -time.Second
+	if err := plan.Validate(); err == nil || !strings.Contains(err.Error(), "thresholds.max_lag") {
+		t.Fatalf("expected negative lag to be rejected, got %v", err)
+	}
+	plan.Thresholds.MaxLag = 0
+	plan.Promotion.FreshnessWindow = -time.Minute
+	if err := plan.Validate(); err == nil || !strings.Contains(err.Error(), "promotion.freshness_window") {
+		t.Fatalf("expected a negative freshness window to be rejected, got %v", err)
+	}
+}
+
+func TestLoadPlan_PostValidationOnBlock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "migration.yaml")
+	content := "" +
+		"migration: m\nsource_version: 5.7\ntarget_version: 8.0\n" +
+		"topology:\n  primary: p\n  replicas: [r1]\n" +
+		"cdc:\n  type: debezium\n  connector: c\n" +
+		"steps: [post_validation]\n" +
+		"post_validation:\n  on_block: auto_rollback\n" +
+		"notifications:\n  command: [notify-oncall, --team, dba]\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	plan, err := LoadPlan(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan.PostValidation.OnBlock != OnBlockAutoRollback || len(plan.Notifications.Command) != 3 {
+		t.Fatalf("unexpected plan: %+v %+v", plan.PostValidation, plan.Notifications)
+	}
+
+	plan.PostValidation.OnBlock = "retry"
+	if err := plan.Validate(); err == nil || !strings.Contains(err.Error(), "post_validation.on_block") {
+		t.Fatalf("expected unknown on_block to be rejected, got %v", err)
+	}
+}
+
+func TestLoadPlan_ServerIdentity(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "migration.yaml")
+	content := "" +
+		"migration: m\nsource_version: 5.7\ntarget_version: 8.0\n" +
+		"topology:\n  primary: p\n  replicas: [r1]\n" +
+		"cdc:\n  type: debezium\n  connector: c\n  server_id: 5400\n" +
+		"steps: [preflight]\n" +
+		"server_identity:\n  r1:\n    server_id: 102\n    server_uuid: 3e11fa47-71ca-11e1-9e33-c80aa9429562\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	plan, err := LoadPlan(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan.CDC.ServerID != 5400 || plan.ServerIdentity["r1"].ServerID != 102 {
+		t.Fatalf("unexpected plan: %+v %+v", plan.CDC, plan.ServerIdentity)
+	}
+
+	plan.ServerIdentity["p"] = ServerIdentityConfig{}
+	if err := plan.Validate(); err == nil || !strings.Contains(err.Error(), "server_identity.p") {
+		t.Fatalf("expected empty identity to be rejected, got %v", err)
+	}
+}
+
+func TestMigrationPlan_ClientsRequireName(t *testing.T) {
+	plan := MigrationPlan{
+		Migration:     "m",
+		SourceVersion: "5.7",
+		TargetVersion: "8.0",
+		Topology:      Topology{Primary: "p", Replicas: []string{"r1"}},
+		CDC:           CDCConfig{Type: "debezium", Connector: "c"},
+		Steps:         []string{"preflight"},
+		Clients:       []ClientConfig{{Name: "billing", AuthPlugins: []string{"mysql_native_password"}}, {Driver: "pymysql"}},
+	}
+	if err := plan.Validate(); err == nil || !strings.Contains(err.Error(), "clients[1].name") {
+		t.Fatalf("expected unnamed client to be rejected, got %v", err)
+	}
+}
+
+func TestMigrationPlanDataParity_OptionsAndValidation(t *testing.T) {
+	plan := MigrationPlan{
+		Migration:     "mysql_57_to_80",
+		SourceVersion: "5.7",
+		TargetVersion: "8.0",
+		Topology:      Topology{Primary: "mysql-primary", Replicas: []string{"mysql-replica-1"}},
+		CDC:           CDCConfig{Type: "debezium", Connector: "mysql-prod"},
+		Steps:         []string{"preflight"},
+		DataParity: DataParityConfig{Tables: []DataParityTable{
+			{Name: "users"},
+			{Name: "events", Mode: "sample", SampleSize: 500, Confidence: 0.99},
+		}},
+	}
+	if err := plan.Validate(); err != nil {
+		t.Fatalf("expected valid plan, got %v", err)
+	}
+	opts := plan.DataParity.Options()
+	if opts["users"].Mode != "checksum" || opts["events"].Mode != "sample" || opts["events"].SampleSize != 500 {
+		t.Fatalf("unexpected options: %+v", opts)
+	}
+
+	plan.DataParity.Tables[1].Confidence = 1.5
+	plan.DataParity.Tables[0].Mode = "fast"
+	if err := plan.Validate(); err == nil {
+		t.Fatalf("expected validation errors for bad mode and confidence")
+	}
+}
+
+func TestLoadPlan_Statistics(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "migration.yaml")
+	content := "" +
+		"migration: m\nsource_version: 5.7\ntarget_version: 8.0\n" +
+		"topology:\n  primary: p\n  replicas: [r1]\n" +
+		"cdc:\n  type: debezium\n  connector: c\n" +
+		"steps: [preflight]\n" +
+		"statistics:\n  max_age: 30m\n  histograms:\n    - table: shop.orders\n      columns: [status, region]\n      buckets: 64\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	plan, err := LoadPlan(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan.Statistics.MaxAge != 30*time.Minute || len(plan.Statistics.Histograms) != 1 || plan.Statistics.Histograms[0].Buckets != 64 {
+		t.Fatalf("unexpected statistics config: %+v", plan.Statistics)
+	}
+
+	plan.Statistics.Histograms = append(plan.Statistics.Histograms, HistogramConfig{Table: "orders", Buckets: 2048})
+	err = plan.Validate()
+	if err == nil || !strings.Contains(err.Error(), "statistics.histograms[1].table") || !strings.Contains(err.Error(), "statistics.histograms[1].columns") || !strings.Contains(err.Error(), "statistics.histograms[1].buckets") {
+		t.Fatalf("expected the bad histogram to be rejected, got %v", err)
+	}
+}
+
+func TestMigrationPlan_WarmupValidation(t *testing.T) {
+	plan := MigrationPlan{
+		Migration:     "m",
+		SourceVersion: "5.7",
+		TargetVersion: "8.0",
+		Topology:      Topology{Primary: "p", Replicas: []string{"r1"}},
+		CDC:           CDCConfig{Type: "debezium", Connector: "c"},
+		Steps:         []string{"preflight"},
+		Warmup:        WarmupConfig{Queries: []string{"SELECT COUNT(*) FROM shop.orders"}, MinHitRatio: 0.99},
+	}
+	if err := plan.Validate(); err != nil {
+		t.Fatalf("expected valid plan, got %v", err)
+	}
+	plan.Warmup.MinHitRatio = 1
+	plan.Warmup.Queries = append(plan.Warmup.Queries, " ")
+	err := plan.Validate()
+	if err == nil || !strings.Contains(err.Error(), "warmup.min_hit_ratio") || !strings.Contains(err.Error(), "warmup.queries[1]") {
+		t.Fatalf("expected the hit ratio and empty query to be rejected, got %v", err)
+	}
+}
+
+func TestLoadPlan_DurationAnomaly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "migration.yaml")
+	content := "" +
+		"migration: m\nsource_version: 5.7\ntarget_version: 8.0\n" +
+		"topology:\n  primary: p\n  replicas: [r1]\n" +
+		"cdc:\n  type: debezium\n  connector: c\n" +
+		"steps: [preflight]\n" +
+		"notifications:\n  command: [notify-oncall]\n  duration_anomaly:\n    factor: 3\n    min_samples: 10\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	plan, err := LoadPlan(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan.Notifications.DurationAnomaly.Factor != 3 || plan.Notifications.DurationAnomaly.MinSamples != 10 {
+		t.Fatalf("unexpected duration anomaly config: %+v", plan.Notifications.DurationAnomaly)
+	}
+
+	plan.Notifications.DurationAnomaly.Factor = 0.5
+	if err := plan.Validate(); err == nil || !strings.Contains(err.Error(), "notifications.duration_anomaly.factor") {
+		t.Fatalf("expected a factor below 1 to be rejected, got %v", err)
+	}
+}
+
+func TestLoadPlan_ClockSkewAndConnectWorkers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "migration.yaml")
+	content := "" +
+		"migration: m\nsource_version: 5.7\ntarget_version: 8.0\n" +
+		"topology:\n  primary: p\n  replicas: [r1]\n" +
+		"cdc:\n  type: debezium\n  connector: c\n  connect_workers: [\"http://connect-1:8083\"]\n" +
+		"steps: [preflight]\n" +
+		"thresholds:\n  max_clock_skew: 500ms\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	plan, err := LoadPlan(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan.Thresholds.MaxClockSkew != 500*time.Millisecond || len(plan.CDC.ConnectWorkers) != 1 {
+		t.Fatalf("unexpected plan: %+v %+v", plan.Thresholds, plan.CDC)
+	}
+
+	plan.CDC.ConnectWorkers = append(plan.CDC.ConnectWorkers, " ")
+	if err := plan.Validate(); err == nil || !strings.Contains(err.Error(), "cdc.connect_workers[1]") {
+		t.Fatalf("expected an empty worker to be rejected, got %v", err)
+	}
+}
+
+func TestMigrationPlanValidate_PeakByteRateMustNotBeNegative(t *testing.T) {
+	plan := MigrationPlan{
+		Migration:     "m",
+		SourceVersion: "5.7",
+		TargetVersion: "8.0",
+		Topology:      Topology{Primary: "p", Replicas: []string{"r1"}},
+		CDC:           CDCConfig{Type: "debezium", Connector: "c", Principal: "User:debezium", PeakByteRate: -1},
+		Steps:         []string{"preflight"},
+	}
+	if err := plan.Validate(); err == nil || !strings.Contains(err.Error(), "cdc.peak_byte_rate") {
+		t.Fatalf("expected a negative peak byte rate to be rejected, got %v", err)
+	}
+}
+
+func TestMigrationPlanValidate_AutoRemediation(t *testing.T) {
+	plan := MigrationPlan{
+		Migration:     "m",
+		SourceVersion: "5.7",
+		TargetVersion: "8.0",
+		Topology:      Topology{Primary: "p", Replicas: []string{"r1"}},
+		CDC: CDCConfig{Type: "debezium", Connector: "c", AutoRemediation: []CDCRemediationPolicy{
+			{Code: "CDC_TASK_NOT_RUNNING", Action: RemediationRestartTask, MaxAttempts: 3},
+			{Code: "CDC_SCHEMA_HISTORY_COVERAGE_GAP", Action: RemediationIncrementalSnapshot},
+			{Code: "CDC_RESTART_LOOP", Action: "reset_offsets"},
+		}},
+		Steps: []string{"preflight"},
+	}
+	err := plan.Validate()
+	if err == nil || !strings.Contains(err.Error(), "needs cdc.signal_command") || !strings.Contains(err.Error(), `action="reset_offsets"`) {
+		t.Fatalf("expected snapshot without signal command and unknown action to be rejected, got %v", err)
+	}
+	plan.CDC.SignalCommand = []string{"signal", "{tables}"}
+	plan.CDC.AutoRemediation = plan.CDC.AutoRemediation[:2]
+	if err := plan.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestMigrationPlanValidate_KeyTablesMustBeQualified(t *testing.T) {
+	plan := MigrationPlan{
+		Migration:     "m",
+		SourceVersion: "5.7",
+		TargetVersion: "8.0",
+		Topology:      Topology{Primary: "p", Replicas: []string{"r1"}},
+		CDC:           CDCConfig{Type: "debezium", Connector: "c", KeyTables: []string{"shop.orders", "items"}},
+		Steps:         []string{"preflight"},
+	}
+	if err := plan.Validate(); err == nil || !strings.Contains(err.Error(), `cdc.key_tables[1]="items"`) {
+		t.Fatalf("expected an unqualified key table to be rejected, got %v", err)
+	}
+}
+
+func TestMigrationPlanValidate_DDLFreezeLockTableMustBeQualified(t *testing.T) {
+	plan := MigrationPlan{
+		Migration:     "m",
+		SourceVersion: "5.7",
+		TargetVersion: "8.0",
+		Topology:      Topology{Primary: "p", Replicas: []string{"r1"}},
+		CDC:           CDCConfig{Type: "debezium", Connector: "c"},
+		DDLFreeze:     DDLFreezeConfig{Enabled: true, LockTable: "ddl_freeze"},
+		Steps:         []string{"preflight"},
+	}
+	if err := plan.Validate(); err == nil || !strings.Contains(err.Error(), "ddl_freeze.lock_table") {
+		t.Fatalf("expected an unqualified lock table to be rejected, got %v", err)
+	}
+	plan.DDLFreeze.LockTable = "ops.ddl_freeze"
+	if err := plan.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestMigrationPlanValidate_BinlogRetentionExtensionNeedsWindow(t *testing.T) {
+	plan := MigrationPlan{
+		Migration:       "m",
+		SourceVersion:   "5.7",
+		TargetVersion:   "8.0",
+		Topology:        Topology{Primary: "p", Replicas: []string{"r1"}},
+		CDC:             CDCConfig{Type: "debezium", Connector: "c"},
+		BinlogRetention: BinlogRetentionConfig{Extend: true, Margin: -1},
+		Steps:           []string{"preflight"},
+	}
+	err := plan.Validate()
+	for _, want := range []string{"binlog_retention.extend requires thresholds.maintenance_window", "binlog_retention.margin"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %s to be rejected, got %v", want, err)
+		}
+	}
+}
+
+func TestMigrationPlanValidate_SoakMustNotBeNegative(t *testing.T) {
+	plan := MigrationPlan{
+		Migration:      "m",
+		SourceVersion:  "5.7",
+		TargetVersion:  "8.0",
+		Topology:       Topology{Primary: "p", Replicas: []string{"r1"}},
+		CDC:            CDCConfig{Type: "debezium", Connector: "c"},
+		PostValidation: PostValidationConfig{SoakDuration: time.Minute, SoakInterval: -time.Second},
+		Steps:          []string{"preflight"},
+	}
+	if err := plan.Validate(); err == nil || !strings.Contains(err.Error(), "post_validation.soak_duration and soak_interval") {
+		t.Fatalf("expected a negative soak interval to be rejected, got %v", err)
+	}
+}
+
+func TestMigrationPlanValidate_MaxThroughputDropIsAShare(t *testing.T) {
+	plan := MigrationPlan{
+		Migration:     "m",
+		SourceVersion: "5.7",
+		TargetVersion: "8.0",
+		Topology:      Topology{Primary: "p", Replicas: []string{"r1"}},
+		CDC:           CDCConfig{Type: "debezium", Connector: "c", MaxThroughputDrop: 25},
+		Steps:         []string{"preflight"},
+	}
+	if err := plan.Validate(); err == nil || !strings.Contains(err.Error(), "cdc.max_throughput_drop") {
+		t.Fatalf("expected a percentage to be rejected, got %v", err)
+	}
+}
+
+func TestReadPoolConfig_InPeak(t *testing.T) {
+	pool := ReadPoolConfig{
+		Timezone: "America/New_York",
+		PeakHours: []PeakWindow{
+			{Days: []string{"mon", "tue", "wed", "thu", "fri"}, Start: "09:00", End: "17:00"},
+			{Days: []string{"fri"}, Start: "22:00", End: "02:00"},
+		},
+	}
+	cases := []struct {
+		at   string
+		peak bool
+	}{
+		{"2026-10-19T14:00:00Z", true},  // Monday 10:00 in New York
+		{"2026-10-19T12:00:00Z", false}, // Monday 08:00
+		{"2026-10-18T14:00:00Z", false}, // Sunday
+		{"2026-10-24T05:00:00Z", true},  // Saturday 01:00, Friday's late window
+		{"2026-10-25T05:00:00Z", false}, // Sunday 01:00
+	}
+	for _, c := range cases {
+		at, _ := time.Parse(time.RFC3339, c.at)
+		if got := pool.InPeak(at); got != c.peak {
+			t.Fatalf("InPeak(%s) = %v, want %v", c.at, got, c.peak)
+		}
+	}
+	if !(ReadPoolConfig{}).InPeak(time.Now()) {
+		t.Fatalf("expected every time to be peak without peak_hours")
+	}
+}
+
+func TestMigrationPlanValidate_ReadPool(t *testing.T) {
+	plan := MigrationPlan{
+		Migration:     "m",
+		SourceVersion: "5.7",
+		TargetVersion: "8.0",
+		Topology:      Topology{Primary: "p", Replicas: []string{"r1"}},
+		CDC:           CDCConfig{Type: "debezium", Connector: "c"},
+		Steps:         []string{"preflight"},
+		ReadPool: ReadPoolConfig{
+			Weights:   map[string]float64{"r1": -1},
+			Timezone:  "Mars/Olympus",
+			PeakHours: []PeakWindow{{Days: []string{"monday"}, Start: "9am", End: "17:00"}},
+		},
+	}
+	err := plan.Validate()
+	for _, want := range []string{`read_pool.weights["r1"]`, "read_pool.timezone", "read_pool.peak_hours[0].start", `read_pool.peak_hours[0].days has "monday"`} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %s to be rejected, got %v", want, err)
+		}
+	}
+}
+
+func TestMigrationPlanValidate_TrafficCalendar(t *testing.T) {
+	plan := MigrationPlan{
+		Migration:       "m",
+		SourceVersion:   "5.7",
+		TargetVersion:   "8.0",
+		Topology:        Topology{Primary: "p", Replicas: []string{"r1"}},
+		CDC:             CDCConfig{Type: "debezium", Connector: "c"},
+		Steps:           []string{"preflight"},
+		TrafficCalendar: TrafficCalendarConfig{LeadTime: -time.Minute, OnPeak: "wait"},
+	}
+	err := plan.Validate()
+	for _, want := range []string{"traffic_calendar.lead_time", `traffic_calendar.on_peak="wait"`, "traffic_calendar.source is required"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %s to be rejected, got %v", want, err)
+		}
+	}
+}
+
+func TestLoadPlan_RegionsAndCrossRegionLag(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "migration.yaml")
+	content := "" +
+		"migration: m\nsource_version: 5.7\ntarget_version: 8.0\n" +
+		"topology:\n  primary: p\n  replicas: [eu1, r1, eu2]\n" +
+		"  regions: {p: us-east-1, r1: us-east-1, eu1: eu-west-1, eu2: eu-west-1}\n  promotable: [eu2]\n" +
+		"cdc:\n  type: debezium\n  connector: c\n" +
+		"steps: [preflight]\n" +
+		"thresholds:\n  max_lag: 5s\n  max_cross_region_lag: 1m\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	plan, err := LoadPlan(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan.LagLimit("r1") != 5*time.Second || plan.LagLimit("eu1") != time.Minute {
+		t.Fatalf("unexpected lag limits: %s %s", plan.LagLimit("r1"), plan.LagLimit("eu1"))
+	}
+	if got := plan.Topology.PromotionCandidates(); len(got) != 2 || got[0] != "r1" || got[1] != "eu2" {
+		t.Fatalf("expected the in-region and tagged replicas, got %v", got)
+	}
+	if limits := plan.CrossRegionLagLimits(); len(limits) != 2 || limits["eu2"] != time.Minute {
+		t.Fatalf("unexpected cross-region limits: %v", limits)
+	}
+
+	plan.Topology.Regions["db9"] = "us-east-1"
+	plan.Topology.Promotable = []string{"p"}
+	err = plan.Validate()
+	if err == nil || !strings.Contains(err.Error(), `topology.regions names "db9"`) || !strings.Contains(err.Error(), `topology.promotable[0]="p"`) {
+		t.Fatalf("expected unknown hosts to be rejected, got %v", err)
+	}
+}
+
+func TestLoadPlan_TopologyAliases(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "migration.yaml")
+	content := "" +
+		"migration: m\nsource_version: 5.7\ntarget_version: 8.0\n" +
+		"topology:\n  primary: p\n  replicas: [r1]\n  aliases: {p: db-a.prod.internal}\n  resolve_dns: true\n" +
+		"cdc:\n  type: debezium\n  connector: c\n" +
+		"steps: [preflight]\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	plan, err := LoadPlan(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !plan.Topology.ResolveDNS || plan.Topology.Address("p") != "db-a.prod.internal" || plan.Topology.Address("r1") != "r1" {
+		t.Fatalf("unexpected addresses: %+v", plan.Topology)
+	}
+
+	plan.Topology.Aliases["db9"] = "10.0.0.9"
+	plan.Topology.Aliases["r1"] = " "
+	err = plan.Validate()
+	if err == nil || !strings.Contains(err.Error(), `topology.aliases names "db9"`) || !strings.Contains(err.Error(), `topology.aliases["r1"] is empty`) {
+		t.Fatalf("expected bad aliases to be rejected, got %v", err)
+	}
+}
+
+func TestMigrationPlanValidate_ProxySQLDrainNeedsAddress(t *testing.T) {
+	plan := MigrationPlan{
+		Migration:     "m",
+		SourceVersion: "5.7",
+		TargetVersion: "8.0",
+		Topology:      Topology{Primary: "p", Replicas: []string{"r1"}},
+		CDC:           CDCConfig{Type: "debezium", Connector: "c"},
+		Drain:         []DrainConfig{{Type: "proxysql"}},
+		Steps:         []string{"preflight"},
+	}
+	if err := plan.Validate(); err == nil || !strings.Contains(err.Error(), "drain[0]: proxysql requires address") {
+		t.Fatalf("expected a proxysql drain without address to be rejected, got %v", err)
+	}
+	plan.Drain[0].Address = "proxysql-1:6032"
+	if err := plan.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -4,45 +4,75 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
+	"time"
 
 	"migratorx/internal/checks"
+	"migratorx/internal/failure"
+)
+
+// Finding codes emitted by the promotion gate.
+const (
+	CodePromotionConfirmationRequired = "PROMOTION_CONFIRMATION_REQUIRED"
+	CodePromotionChecksMissing        = "PROMOTION_CHECKS_MISSING"
+	CodePromotionCheckSilent          = "PROMOTION_CHECK_SILENT"
+	CodePromotionBlocked              = "PROMOTION_BLOCKED"
+	CodePromotionWarnBudgetOK         = "PROMOTION_WARN_BUDGET_OK"
+	CodePromotionWarnBudgetExceeded   = "PROMOTION_WARN_BUDGET_EXCEEDED"
+	CodePromotionResultsFresh         = "PROMOTION_RESULTS_FRESH"
+	CodePromotionResultsStale         = "PROMOTION_RESULTS_STALE"
 )
 
 // PromotionGate enforces explicit confirmation and re-validates CDC/schema checks.
+// RequiredCheckNames is normally derived from the plan (MigrationPlan.RequiredCheckNames);
+// each required check must be present and must produce at least one finding.
+// AllowedWarnCodes lists WARN finding codes accepted by policy; any other WARN
+// still blocks promotion. MaxWarnCount, when set, caps the total number of
+// WARN findings, allowlisted or not. When State is set and the gate passes,
+// the time each check result was produced is recorded there so a later
+// cutover can verify it with GateFreshness. FreshnessWindow, when set, blocks if a required check result
+// is older than the window by the time the gate finishes. Decision, when set,
+// is asked to approve a promotion that passed every other rule; Migration
+// names the plan in its request.
 type PromotionGate struct {
 	Checks             []checks.PreflightCheck
 	RequiredCheckNames []string
+	AllowedWarnCodes   []string
+	MaxWarnCount       *int
+	FreshnessWindow    time.Duration
 	ConfirmationPhrase string
+	State              State
+	Decision           *DecisionService
+	Migration          string
+	Now                func() time.Time
 	Logger             *log.Logger
 }
 
-// Run validates confirmation, re-runs checks, and blocks on WARN/BLOCK.
+// Run validates confirmation, re-runs checks, and blocks on BLOCK or on WARN
+// findings whose code is not allowlisted.
 func (g *PromotionGate) Run(ctx context.Context, input checks.Input, confirmation string) (checks.Summary, []checks.Finding, error) {
 	if g.Logger == nil {
 		g.Logger = log.Default()
 	}
 	if strings.TrimSpace(g.ConfirmationPhrase) == "" {
-		return checks.Summary{}, nil, fmt.Errorf("confirmation phrase is required")
+		return checks.Summary{}, nil, failure.Config("confirmation phrase is required")
 	}
-	if confirmation != g.ConfirmationPhrase {
-		block := checks.Finding{
-			Severity: checks.SeverityBlock,
-			Message:  "promotion requires explicit confirmation",
-			Meta:     map[string]interface{}{"required": g.ConfirmationPhrase},
-		}
-		return checks.Summary{Block: 1}, []checks.Finding{block}, nil
+	if block := RequireConfirmation(g.ConfirmationPhrase, confirmation); block != nil {
+		return checks.Summary{Block: 1}, []checks.Finding{*block}, nil
 	}
 
-	required := g.RequiredCheckNames
-	if len(required) == 0 {
-		required = []string{"cdc_debezium_health", "schema_parity"}
+	required := requiredChecks(g.RequiredCheckNames)
+	now := time.Now
+	if g.Now != nil {
+		now = g.Now
 	}
 
 	missing := missingChecks(required, g.Checks)
 	if len(missing) > 0 {
 		block := checks.Finding{
 			Severity: checks.SeverityBlock,
+			Code:     CodePromotionChecksMissing,
 			Message:  fmt.Sprintf("promotion requires checks: %s", strings.Join(missing, ", ")),
 			Meta:     map[string]interface{}{"missing": missing},
 		}
@@ -50,25 +80,196 @@ func (g *PromotionGate) Run(ctx context.Context, input checks.Input, confirmatio
 	}
 
 	runner := checks.NewRunner(g.Checks, g.Logger)
+	runner.Now = now
 	summary, results, err := runner.Run(ctx, input)
 	if err != nil {
 		return checks.Summary{}, nil, err
 	}
+	checkedAt := map[string]time.Time{}
+	for _, r := range results {
+		if r.CheckedAt.IsZero() {
+			continue
+		}
+		checkedAt[r.CheckName] = r.CheckedAt
+	}
 
 	findings := flattenResults(results)
-	if summary.Warn > 0 || summary.Block > 0 {
+	for _, name := range silentChecks(required, results) {
 		block := checks.Finding{
 			Severity: checks.SeverityBlock,
-			Message:  fmt.Sprintf("promotion blocked due to WARN/BLOCK findings (WARN=%d, BLOCK=%d)", summary.Warn, summary.Block),
-			Meta:     map[string]interface{}{"warn": summary.Warn, "block": summary.Block},
+			Code:     CodePromotionCheckSilent,
+			Message:  fmt.Sprintf("required check %q produced no findings; it may have been skipped", name),
+			Meta:     map[string]interface{}{"check": name},
 		}
 		findings = append(findings, block)
 		summary.Block++
 	}
-
+	if g.MaxWarnCount != nil {
+		budget := warnBudgetFinding(*g.MaxWarnCount, summary.Warn)
+		findings = append(findings, budget)
+		if budget.Severity == checks.SeverityBlock {
+			summary.Block++
+		} else {
+			summary.Info++
+		}
+	}
+	if g.FreshnessWindow > 0 {
+		freshness := freshnessFinding(checkedAt, required, g.FreshnessWindow, now())
+		findings = append(findings, freshness)
+		if freshness.Severity == checks.SeverityBlock {
+			summary.Block++
+		} else {
+			summary.Info++
+		}
+	}
+	blockingWarn, allowedWarn := applyWarnPolicy(findings, g.AllowedWarnCodes)
+	if g.Decision != nil && blockingWarn == 0 && summary.Block == 0 {
+		decision := g.Decision.Decide(ctx, DecisionRequest{Migration: g.Migration, Replica: input.ReplicaHost, Summary: summary, Findings: findings})
+		findings = append(findings, decision)
+		if decision.Severity == checks.SeverityBlock {
+			summary.Block++
+		} else {
+			summary.Info++
+		}
+	}
+	if blockingWarn > 0 || summary.Block > 0 {
+		block := checks.Finding{
+			Severity: checks.SeverityBlock,
+			Code:     CodePromotionBlocked,
+			Message:  fmt.Sprintf("promotion blocked due to WARN/BLOCK findings (WARN=%d, BLOCK=%d)", blockingWarn, summary.Block),
+			Meta:     map[string]interface{}{"warn": blockingWarn, "block": summary.Block, "allowed_warn": allowedWarn},
+		}
+		findings = append(findings, block)
+		summary.Block++
+		return summary, findings, nil
+	}
+	// Only a passing gate vouches for its results: stamps from a blocked run
+	// would let a later cutover pass GateFreshness on results that failed.
+	if g.State != nil {
+		for name, t := range checkedAt {
+			g.State.Set(gateCheckedAtKey(name), t.UTC().Format(time.RFC3339Nano))
+		}
+	}
 	return summary, findings, nil
 }
 
+func warnBudgetFinding(max int, warns int) checks.Finding {
+	threshold := checks.Threshold{Name: "max_warn_count", Limit: float64(max), Unit: "findings"}
+	meta := map[string]interface{}{"threshold": threshold.Meta(float64(warns))}
+	if threshold.Exceeded(float64(warns)) {
+		return checks.Finding{
+			Severity: checks.SeverityBlock,
+			Code:     CodePromotionWarnBudgetExceeded,
+			Message:  fmt.Sprintf("promotion gate produced %d WARN findings (limit %d)", warns, max),
+			Meta:     meta,
+		}
+	}
+	return checks.Finding{
+		Severity: checks.SeverityInfo,
+		Code:     CodePromotionWarnBudgetOK,
+		Message:  fmt.Sprintf("promotion gate produced %d WARN findings (limit %d)", warns, max),
+		Meta:     meta,
+	}
+}
+
+// GateFreshness checks the gate results recorded in st by PromotionGate
+// against window: a required check whose result is missing or older than
+// window yields a BLOCK, so cutover cannot rely on results from hours earlier.
+// Otherwise it returns an INFO finding.
+func GateFreshness(st State, required []string, window time.Duration, now time.Time) checks.Finding {
+	checkedAt := map[string]time.Time{}
+	for _, name := range requiredChecks(required) {
+		raw, _ := st.Get(gateCheckedAtKey(name))
+		encoded, _ := raw.(string)
+		if t, err := time.Parse(time.RFC3339Nano, encoded); err == nil {
+			checkedAt[name] = t
+		}
+	}
+	return freshnessFinding(checkedAt, required, window, now)
+}
+
+func freshnessFinding(checkedAt map[string]time.Time, required []string, window time.Duration, now time.Time) checks.Finding {
+	stale := []string{}
+	ages := map[string]string{}
+	for _, name := range requiredChecks(required) {
+		t, ok := checkedAt[name]
+		if !ok {
+			stale = append(stale, name)
+			ages[name] = "never"
+			continue
+		}
+		age := now.Sub(t).Round(time.Second)
+		ages[name] = age.String()
+		if age > window {
+			stale = append(stale, name)
+		}
+	}
+	sort.Strings(stale)
+	meta := map[string]interface{}{"freshness_window": window.String(), "age": ages}
+	if len(stale) > 0 {
+		meta["stale"] = stale
+		return checks.Finding{
+			Severity: checks.SeverityBlock,
+			Code:     CodePromotionResultsStale,
+			Message:  fmt.Sprintf("gate results older than %s: %s", window, strings.Join(stale, ", ")),
+			Meta:     meta,
+		}
+	}
+	return checks.Finding{
+		Severity: checks.SeverityInfo,
+		Code:     CodePromotionResultsFresh,
+		Message:  fmt.Sprintf("gate results are within %s", window),
+		Meta:     meta,
+	}
+}
+
+// requiredChecks defaults to the CDC and schema checks every promotion needs.
+func requiredChecks(names []string) []string {
+	if len(names) == 0 {
+		return []string{"cdc_debezium_health", "schema_parity"}
+	}
+	return names
+}
+
+func gateCheckedAtKey(check string) string {
+	return fmt.Sprintf("promotion_gate:%s:checked_at", check)
+}
+
+// RequireConfirmation returns a BLOCK finding unless confirmation matches phrase.
+func RequireConfirmation(phrase string, confirmation string) *checks.Finding {
+	if confirmation == phrase {
+		return nil
+	}
+	return &checks.Finding{
+		Severity: checks.SeverityBlock,
+		Code:     CodePromotionConfirmationRequired,
+		Message:  "promotion requires explicit confirmation",
+		Meta:     map[string]interface{}{"required": phrase},
+	}
+}
+
+// applyWarnPolicy marks WARN findings whose code is allowlisted and returns the
+// number of WARNs that still block and the number accepted by policy.
+func applyWarnPolicy(findings []checks.Finding, allowedCodes []string) (int, int) {
+	allowed := map[string]struct{}{}
+	for _, code := range allowedCodes {
+		allowed[strings.TrimSpace(code)] = struct{}{}
+	}
+	blocking, accepted := 0, 0
+	for _, f := range findings {
+		if f.Severity != checks.SeverityWarn {
+			continue
+		}
+		if _, ok := allowed[f.Code]; ok && f.Code != "" {
+			f.Meta["allowed_by_policy"] = true
+			accepted++
+			continue
+		}
+		blocking++
+	}
+	return blocking, accepted
+}
+
 func missingChecks(required []string, checksList []checks.PreflightCheck) []string {
 	seen := map[string]struct{}{}
 	for _, c := range checksList {
@@ -83,6 +284,21 @@ func missingChecks(required []string, checksList []checks.PreflightCheck) []stri
 	return missing
 }
 
+// silentChecks returns required checks whose results contain no findings.
+func silentChecks(required []string, results []checks.Result) []string {
+	produced := map[string]int{}
+	for _, r := range results {
+		produced[r.CheckName] += len(r.Findings)
+	}
+	silent := []string{}
+	for _, name := range required {
+		if produced[name] == 0 {
+			silent = append(silent, name)
+		}
+	}
+	return silent
+}
+
 func flattenResults(results []checks.Result) []checks.Finding {
 	findings := []checks.Finding{}
 	for _, r := range results {
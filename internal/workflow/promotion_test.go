@@ -3,6 +3,7 @@ package workflow
 import (
 	"context"
 	"testing"
+	"time"
 
 	"migratorx/internal/checks"
 )
@@ -67,3 +68,149 @@ func TestPromotionGate_InfoAllowsPromotion(t *testing.T) {
 		t.Fatalf("expected no BLOCK for INFO-only findings")
 	}
 }
+
+func TestPromotionGate_AllowlistedWarnCodeDoesNotBlock(t *testing.T) {
+	cdc := checks.NewReadOnlyCheck("cdc_debezium_health", func(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+		return []checks.Finding{{Severity: checks.SeverityInfo, Message: "cdc ok"}}, nil
+	})
+	schema := checks.NewReadOnlyCheck("schema_parity", func(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+		return []checks.Finding{{Severity: checks.SeverityWarn, Code: checks.CodeSchemaColumnDefault, Message: "default differs", Meta: map[string]interface{}{"table": "t", "column": "a"}}}, nil
+	})
+
+	gate := &PromotionGate{ConfirmationPhrase: "PROMOTE", Checks: []checks.PreflightCheck{cdc, schema}, AllowedWarnCodes: []string{checks.CodeSchemaColumnDefault}}
+	summary, findings, err := gate.Run(context.Background(), checks.Input{}, "PROMOTE")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Block != 0 {
+		t.Fatalf("expected allowlisted WARN not to block, got %+v", summary)
+	}
+	if findings[1].Meta["allowed_by_policy"] != true {
+		t.Fatalf("expected allowlisted WARN to be marked, got %+v", findings[1].Meta)
+	}
+}
+
+func TestPromotionGate_WarnBudgetCapsAllowlistedWarns(t *testing.T) {
+	cdc := checks.NewReadOnlyCheck("cdc_debezium_health", func(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+		return []checks.Finding{{Severity: checks.SeverityInfo, Message: "cdc ok"}}, nil
+	})
+	schema := checks.NewReadOnlyCheck("schema_parity", func(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+		return []checks.Finding{
+			{Severity: checks.SeverityWarn, Code: checks.CodeSchemaColumnDefault, Message: "default differs on a", Meta: map[string]interface{}{"table": "t", "column": "a"}},
+			{Severity: checks.SeverityWarn, Code: checks.CodeSchemaColumnDefault, Message: "default differs on b", Meta: map[string]interface{}{"table": "t", "column": "b"}},
+		}, nil
+	})
+
+	max := 1
+	gate := &PromotionGate{ConfirmationPhrase: "PROMOTE", Checks: []checks.PreflightCheck{cdc, schema}, AllowedWarnCodes: []string{checks.CodeSchemaColumnDefault}, MaxWarnCount: &max}
+	summary, findings, err := gate.Run(context.Background(), checks.Input{}, "PROMOTE")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Block == 0 {
+		t.Fatalf("expected WARN budget to block, got %+v", findings)
+	}
+	var budget *checks.Finding
+	for i := range findings {
+		if findings[i].Code == CodePromotionWarnBudgetExceeded {
+			budget = &findings[i]
+		}
+	}
+	if budget == nil || budget.Meta["threshold"].(map[string]interface{})["measured"] != 2.0 {
+		t.Fatalf("expected budget finding with measured WARN count, got %+v", findings)
+	}
+}
+
+func TestPromotionGate_UnlistedWarnCodeStillBlocks(t *testing.T) {
+	cdc := checks.NewReadOnlyCheck("cdc_debezium_health", func(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+		return []checks.Finding{{Severity: checks.SeverityWarn, Code: "CDC_LAG", Message: "cdc lag"}}, nil
+	})
+	schema := checks.NewReadOnlyCheck("schema_parity", func(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+		return []checks.Finding{{Severity: checks.SeverityWarn, Code: checks.CodeSchemaColumnDefault, Message: "default differs", Meta: map[string]interface{}{"table": "t", "column": "a"}}}, nil
+	})
+
+	gate := &PromotionGate{ConfirmationPhrase: "PROMOTE", Checks: []checks.PreflightCheck{cdc, schema}, AllowedWarnCodes: []string{checks.CodeSchemaColumnDefault}}
+	summary, _, err := gate.Run(context.Background(), checks.Input{}, "PROMOTE")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Block != 1 {
+		t.Fatalf("expected unlisted WARN to block, got %+v", summary)
+	}
+}
+
+func TestPromotionGate_SilentRequiredCheckBlocks(t *testing.T) {
+	cdc := checks.NewReadOnlyCheck("cdc_debezium_health", func(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+		return []checks.Finding{{Severity: checks.SeverityInfo, Message: "cdc ok"}}, nil
+	})
+	schema := checks.NewReadOnlyCheck("schema_parity", func(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+		return nil, nil
+	})
+
+	gate := &PromotionGate{ConfirmationPhrase: "PROMOTE", Checks: []checks.PreflightCheck{cdc, schema}, RequiredCheckNames: []string{"cdc_debezium_health", "schema_parity"}}
+	summary, findings, err := gate.Run(context.Background(), checks.Input{}, "PROMOTE")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Block == 0 {
+		t.Fatalf("expected BLOCK for silent required check")
+	}
+	if findings[len(findings)-2].Code != CodePromotionCheckSilent {
+		t.Fatalf("expected silent check finding, got %+v", findings)
+	}
+}
+
+func TestPromotionGate_RecordsResultTimesAndEnforcesFreshness(t *testing.T) {
+	cdc := checks.NewReadOnlyCheck("cdc_debezium_health", func(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+		return []checks.Finding{{Severity: checks.SeverityInfo, Message: "cdc ok"}}, nil
+	})
+	schema := checks.NewReadOnlyCheck("schema_parity", func(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+		return []checks.Finding{{Severity: checks.SeverityInfo, Message: "schema ok"}}, nil
+	})
+	checkedAt := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	st := NewMemoryState()
+
+	gate := &PromotionGate{ConfirmationPhrase: "PROMOTE", Checks: []checks.PreflightCheck{cdc, schema}, FreshnessWindow: 30 * time.Minute, State: st, Now: func() time.Time { return checkedAt }}
+	summary, findings, err := gate.Run(context.Background(), checks.Input{}, "PROMOTE")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Block != 0 || findings[len(findings)-1].Code != CodePromotionResultsFresh {
+		t.Fatalf("expected fresh results to pass the gate, got %+v", findings)
+	}
+
+	if f := GateFreshness(st, nil, 30*time.Minute, checkedAt.Add(10*time.Minute)); f.Severity != checks.SeverityInfo {
+		t.Fatalf("expected results within the window to be fresh, got %+v", f)
+	}
+	f := GateFreshness(st, nil, 30*time.Minute, checkedAt.Add(15*time.Hour))
+	if f.Severity != checks.SeverityBlock || f.Code != CodePromotionResultsStale {
+		t.Fatalf("expected stale results to block, got %+v", f)
+	}
+	if stale := f.Meta["stale"].([]string); len(stale) != 2 {
+		t.Fatalf("expected both required checks to be stale, got %v", stale)
+	}
+	if f := GateFreshness(st, []string{"data_parity"}, 30*time.Minute, checkedAt); f.Severity != checks.SeverityBlock || f.Meta["age"].(map[string]string)["data_parity"] != "never" {
+		t.Fatalf("expected a required check without a recorded result to block, got %+v", f)
+	}
+}
+
+func TestPromotionGate_BlockedRunRecordsNoResultTimes(t *testing.T) {
+	cdc := checks.NewReadOnlyCheck("cdc_debezium_health", func(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+		return []checks.Finding{{Severity: checks.SeverityInfo, Message: "cdc ok"}}, nil
+	})
+	schema := checks.NewReadOnlyCheck("schema_parity", func(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+		return []checks.Finding{{Severity: checks.SeverityBlock, Message: "schema drift"}}, nil
+	})
+	st := NewMemoryState()
+	gate := &PromotionGate{ConfirmationPhrase: "PROMOTE", Checks: []checks.PreflightCheck{cdc, schema}, State: st}
+	summary, _, err := gate.Run(context.Background(), checks.Input{}, "PROMOTE")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Block == 0 {
+		t.Fatal("expected the gate to block")
+	}
+	if f := GateFreshness(st, nil, time.Hour, time.Now()); f.Severity != checks.SeverityBlock {
+		t.Fatalf("expected no recorded results after a blocked gate, got %+v", f)
+	}
+}
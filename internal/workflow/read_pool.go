@@ -0,0 +1,126 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ReadPoolConfig sizes the read pool that replica upgrades must leave in
+// service. Weights give each replica's share of read traffic (default 1).
+// While PeakHours apply, evaluated in Timezone (default UTC), an upgrade only
+// starts if the replicas still serving reads weigh at least MinCapacity.
+// Without PeakHours the floor applies at all times.
+type ReadPoolConfig struct {
+	Weights     map[string]float64 `yaml:"weights"`
+	MinCapacity float64            `yaml:"min_capacity"`
+	PeakHours   []PeakWindow       `yaml:"peak_hours"`
+	Timezone    string             `yaml:"timezone"`
+}
+
+// PeakWindow is a daily window from Start to End ("HH:MM") on Days (mon to
+// sun, default every day). A window whose End is not after its Start runs
+// past midnight into the next day.
+type PeakWindow struct {
+	Days  []string `yaml:"days" json:"days"`
+	Start string   `yaml:"start" json:"start"`
+	End   string   `yaml:"end" json:"end"`
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// InPeak reports whether t falls in one of the peak windows; with none
+// configured every time is peak. An unknown Timezone falls back to UTC;
+// Validate rejects it.
+func (c ReadPoolConfig) InPeak(t time.Time) bool {
+	if len(c.PeakHours) == 0 {
+		return true
+	}
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	t = t.In(loc)
+	minute := t.Hour()*60 + t.Minute()
+	for _, w := range c.PeakHours {
+		start, err1 := clockMinute(w.Start)
+		end, err2 := clockMinute(w.End)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		if end > start {
+			if minute >= start && minute < end && w.onDay(t.Weekday()) {
+				return true
+			}
+			continue
+		}
+		// Past midnight: the early part belongs to the previous day's window.
+		if minute >= start && w.onDay(t.Weekday()) {
+			return true
+		}
+		if minute < end && w.onDay((t.Weekday()+6)%7) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w PeakWindow) onDay(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, d := range w.Days {
+		if weekdays[strings.ToLower(strings.TrimSpace(d))] == day {
+			return true
+		}
+	}
+	return false
+}
+
+func clockMinute(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("%q is not HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func (c ReadPoolConfig) validate() []string {
+	problems := []string{}
+	if c.MinCapacity < 0 {
+		problems = append(problems, "read_pool.min_capacity must not be negative")
+	}
+	for host, w := range c.Weights {
+		if w < 0 {
+			problems = append(problems, fmt.Sprintf("read_pool.weights[%q] must not be negative", host))
+		}
+	}
+	return append(problems, validatePeakWindows("read_pool.peak_hours", "read_pool.timezone", c.PeakHours, c.Timezone)...)
+}
+
+// validatePeakWindows checks windows and timezone, naming them field and
+// tzField in problems.
+func validatePeakWindows(field, tzField string, windows []PeakWindow, timezone string) []string {
+	problems := []string{}
+	if timezone != "" {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			problems = append(problems, fmt.Sprintf("%s=%q is not a known time zone", tzField, timezone))
+		}
+	}
+	for i, w := range windows {
+		for _, part := range []struct{ name, value string }{{"start", w.Start}, {"end", w.End}} {
+			if _, err := clockMinute(part.value); err != nil {
+				problems = append(problems, fmt.Sprintf("%s[%d].%s: %v", field, i, part.name, err))
+			}
+		}
+		for _, d := range w.Days {
+			if _, ok := weekdays[strings.ToLower(strings.TrimSpace(d))]; !ok {
+				problems = append(problems, fmt.Sprintf("%s[%d].days has %q (expected mon to sun)", field, i, d))
+			}
+		}
+	}
+	return problems
+}
@@ -0,0 +1,137 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"migratorx/internal/checks"
+)
+
+// Finding codes emitted by the resolution gate.
+const (
+	CodeHostResolved          = "HOST_RESOLVED"
+	CodeHostResolutionChanged = "HOST_RESOLUTION_CHANGED"
+	CodeHostResolutionFailed  = "HOST_RESOLUTION_FAILED"
+)
+
+const artifactResolutionPrefix = "artifact:resolution:"
+
+// HostResolver resolves an address to its IPs. *net.Resolver implements it.
+type HostResolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// HostResolution is the address a topology member was reached at and the IPs
+// it resolved to when the run first resolved it.
+type HostResolution struct {
+	Address    string    `json:"address"`
+	IPs        []string  `json:"ips"`
+	ResolvedAt time.Time `json:"resolved_at"`
+}
+
+// PinnedResolution returns the resolution recorded for host in this run.
+func PinnedResolution(st State, host string) (HostResolution, bool) {
+	var resolution HostResolution
+	ok, err := GetArtifact(st, artifactResolutionPrefix+host, &resolution)
+	return resolution, ok && err == nil
+}
+
+// ResolutionGate resolves every topology member's address (its alias, or the
+// member name) and pins the IPs in State the first time a run resolves it.
+// Later commands of the run compare against the pin: a DNS flip mid-run would
+// otherwise silently point inspections and actions at another server, so a
+// member now resolving to other IPs warns. The pin is kept, so every later
+// command warns too until the member resolves as it did at run start again,
+// is aliased to the intended address, or a new run is started. A failed
+// lookup warns and leaves the pin alone.
+type ResolutionGate struct {
+	Topology Topology
+	Resolver HostResolver
+	State    State
+	Now      func() time.Time
+}
+
+func (g *ResolutionGate) Name() string   { return "host_resolution" }
+func (g *ResolutionGate) ReadOnly() bool { return true }
+
+func (g *ResolutionGate) Parameters() map[string]interface{} {
+	return map[string]interface{}{"hosts": len(g.Topology.Members()), "aliases": len(g.Topology.Aliases)}
+}
+
+func (g *ResolutionGate) Run(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+	if g.Resolver == nil {
+		return nil, fmt.Errorf("host resolver is required")
+	}
+	if g.State == nil {
+		return nil, fmt.Errorf("state is required")
+	}
+	now := time.Now
+	if g.Now != nil {
+		now = g.Now
+	}
+	findings := []checks.Finding{}
+	for _, host := range g.Topology.Members() {
+		address := g.Topology.Address(host)
+		meta := map[string]interface{}{"host": host, "address": address}
+		ips, err := g.Resolver.LookupHost(ctx, address)
+		if err != nil {
+			meta["error"] = err.Error()
+			findings = append(findings, checks.Finding{
+				Severity: checks.SeverityWarn,
+				Code:     CodeHostResolutionFailed,
+				Message:  fmt.Sprintf("failed to resolve %q (%s): %v", host, address, err),
+				Meta:     meta,
+			})
+			continue
+		}
+		ips = append([]string{}, ips...)
+		sort.Strings(ips)
+		meta["ips"] = ips
+		pinned, ok := PinnedResolution(g.State, host)
+		if !ok {
+			if err := PutArtifact(g.State, artifactResolutionPrefix+host, HostResolution{Address: address, IPs: ips, ResolvedAt: now().UTC()}); err != nil {
+				return nil, err
+			}
+			findings = append(findings, checks.Finding{
+				Severity: checks.SeverityInfo,
+				Code:     CodeHostResolved,
+				Message:  fmt.Sprintf("%q (%s) resolves to %s; pinned for this run", host, address, strings.Join(ips, ", ")),
+				Meta:     meta,
+			})
+			continue
+		}
+		meta["recorded_ips"] = pinned.IPs
+		meta["recorded_at"] = pinned.ResolvedAt.Format(time.RFC3339)
+		if sameStrings(pinned.IPs, ips) {
+			findings = append(findings, checks.Finding{
+				Severity: checks.SeverityInfo,
+				Code:     CodeHostResolved,
+				Message:  fmt.Sprintf("%q (%s) still resolves to %s", host, address, strings.Join(ips, ", ")),
+				Meta:     meta,
+			})
+			continue
+		}
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityWarn,
+			Code:     CodeHostResolutionChanged,
+			Message:  fmt.Sprintf("%q (%s) resolved to %s when the run started but now resolves to %s; inspections and actions may reach another server", host, address, strings.Join(pinned.IPs, ", "), strings.Join(ips, ", ")),
+			Meta:     meta,
+		})
+	}
+	return findings, nil
+}
+
+func sameStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
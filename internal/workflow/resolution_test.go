@@ -0,0 +1,59 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"migratorx/internal/checks"
+)
+
+type fakeResolver map[string][]string
+
+func (f fakeResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	ips, ok := f[host]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	return ips, nil
+}
+
+func TestResolutionGate_PinsAndWarnsOnChange(t *testing.T) {
+	st := persistedState{NewMemoryState()}
+	resolver := fakeResolver{"db-a.prod.internal": {"10.0.0.2", "10.0.0.1"}, "r1": {"10.0.1.1"}}
+	gate := &ResolutionGate{
+		Topology: Topology{Primary: "p", Replicas: []string{"r1"}, Aliases: map[string]string{"p": "db-a.prod.internal"}},
+		Resolver: resolver,
+		State:    st,
+		Now:      func() time.Time { return time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC) },
+	}
+	findings, err := gate.Run(context.Background(), checks.Input{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 2 || findings[0].Code != CodeHostResolved || findings[0].Meta["address"] != "db-a.prod.internal" {
+		t.Fatalf("expected both members pinned, got %+v", findings)
+	}
+	pinned, ok := PinnedResolution(st, "p")
+	if !ok || !reflect.DeepEqual(pinned.IPs, []string{"10.0.0.1", "10.0.0.2"}) {
+		t.Fatalf("unexpected pin: %+v %v", pinned, ok)
+	}
+
+	resolver["db-a.prod.internal"] = []string{"10.0.9.9"}
+	delete(resolver, "r1")
+	findings, _ = gate.Run(context.Background(), checks.Input{})
+	if len(findings) != 2 || findings[0].Severity != checks.SeverityWarn || findings[0].Code != CodeHostResolutionChanged {
+		t.Fatalf("expected the flipped primary to warn, got %+v", findings)
+	}
+	if !reflect.DeepEqual(findings[0].Meta["recorded_ips"], []string{"10.0.0.1", "10.0.0.2"}) || findings[0].Meta["recorded_at"] != "2026-10-18T09:00:00Z" {
+		t.Fatalf("unexpected change meta: %v", findings[0].Meta)
+	}
+	if findings[1].Code != CodeHostResolutionFailed {
+		t.Fatalf("expected the failed lookup to warn, got %+v", findings[1])
+	}
+	if pinned, _ := PinnedResolution(st, "p"); !reflect.DeepEqual(pinned.IPs, []string{"10.0.0.1", "10.0.0.2"}) {
+		t.Fatalf("expected the run-start pin to be kept, got %v", pinned.IPs)
+	}
+}
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"log"
 	"sync"
+	"time"
+
+	"migratorx/internal/failure"
 )
 
 // Severity indicates the importance of a finding produced by a Step.
@@ -36,9 +39,24 @@ type Finding struct {
 	Meta     map[string]interface{}
 }
 
-// StepResult contains findings produced by a Step.
+// StepStatus is how far a step got in the Runner's latest run.
+type StepStatus string
+
+const (
+	StepRunning   StepStatus = "running"
+	StepSkipped   StepStatus = "skipped"
+	StepCompleted StepStatus = "completed"
+	StepBlocked   StepStatus = "blocked"
+)
+
+// StepResult contains findings produced by a Step. Steps only return
+// Findings; the Runner fills in Status, StartedAt and Duration. Skipped steps
+// have no timing.
 type StepResult struct {
-	Findings []Finding
+	Findings  []Finding
+	Status    StepStatus
+	StartedAt time.Time
+	Duration  time.Duration
 }
 
 // State is a minimal interface for tracking checkpoints and sharing lightweight data
@@ -65,6 +83,26 @@ type Step interface {
 	Mutates() bool
 }
 
+// EventType identifies a progress event emitted by the Runner.
+type EventType string
+
+const (
+	EventStepStarted   EventType = "step_started"
+	EventStepSkipped   EventType = "step_skipped"
+	EventFinding       EventType = "finding"
+	EventStepCompleted EventType = "step_completed"
+	EventStepBlocked   EventType = "step_blocked"
+)
+
+// Event is a progress notification for live consumers (log streams, progress
+// UIs). Finding is set only for EventFinding.
+type Event struct {
+	Type    EventType
+	Step    string
+	Finding *Finding
+	Time    time.Time
+}
+
 // MemoryState is a simple in-memory State useful for local runs and tests.
 type MemoryState struct {
 	mu        sync.RWMutex
@@ -111,15 +149,28 @@ func (m *MemoryState) IsCompleted(stepName string) bool {
 //   - Skips steps already marked completed in State.
 //   - Enforces AllowMutations: if false and a step reports Mutates()==true,
 //     the Runner records a BLOCK finding and halts.
-//   - Aggregates findings. Any BLOCK finding halts further steps.
+//   - Aggregates findings. Any BLOCK finding halts further steps, unless
+//     ContinueOnBlock is set: then the remaining read-only steps still run so
+//     the report covers every risk, while mutating steps after a BLOCK are
+//     skipped. Blocked steps are never marked completed.
 //   - WARN findings are recorded but do not stop the run.
 //   - INFO findings are recorded.
+//   - OnEvent, when set, receives step and finding events as they happen.
+//
+// A Runner may be reused: Run calls are serialized, each starts from empty
+// results, and Results may be read from other goroutines while a run is under
+// way, e.g. by a progress UI.
 type Runner struct {
-	Steps          []Step
-	State          State
-	AllowMutations bool
-	Logger         *log.Logger
-	results        map[string]StepResult
+	Steps           []Step
+	State           State
+	AllowMutations  bool
+	ContinueOnBlock bool
+	Logger          *log.Logger
+	OnEvent         func(Event)
+
+	run     sync.Mutex
+	mu      sync.Mutex
+	results map[string]StepResult
 }
 
 // NewRunner constructs a Runner. If state is nil, a new in-memory state is used.
@@ -142,17 +193,23 @@ type Summary struct {
 
 // Run executes the plan sequentially and returns a Summary and any execution error.
 // A returned non-nil error indicates an internal failure (invalid plan or runner
-// configuration). Step-level failures are represented as BLOCK findings and will
-// stop execution but do not surface as runner errors.
+// configuration) and is a *failure.ConfigError unless the context was canceled.
+// Step-level failures are represented as BLOCK findings and will stop execution
+// but do not surface as runner errors.
 func (r *Runner) Run(ctx context.Context) (Summary, error) {
+	r.run.Lock()
+	defer r.run.Unlock()
+	r.Reset()
+
 	// Validate idempotence
 	for _, s := range r.Steps {
 		if !s.Idempotent() {
-			return Summary{}, fmt.Errorf("step %q is not idempotent; all steps must be idempotent", s.Name())
+			return Summary{}, failure.Config("step %q is not idempotent; all steps must be idempotent", s.Name())
 		}
 	}
 
 	summary := Summary{}
+	blockedAny := false
 
 	for _, step := range r.Steps {
 		select {
@@ -164,29 +221,52 @@ func (r *Runner) Run(ctx context.Context) (Summary, error) {
 
 		if r.State.IsCompleted(step.Name()) {
 			r.Logger.Printf("skipping completed step: %s", step.Name())
+			r.setResult(step.Name(), StepResult{Status: StepSkipped})
+			r.emit(Event{Type: EventStepSkipped, Step: step.Name()})
+			continue
+		}
+		if blockedAny && step.Mutates() {
+			r.Logger.Printf("skipping mutating step %s after an earlier BLOCK", step.Name())
+			r.setResult(step.Name(), StepResult{Status: StepSkipped})
+			r.emit(Event{Type: EventStepSkipped, Step: step.Name()})
 			continue
 		}
 
 		if step.Mutates() && !r.AllowMutations {
 			// Record a BLOCK finding and halt — protecting against implicit mutations
 			f := Finding{Severity: SeverityBlock, Message: "mutating step blocked by Runner configuration", Meta: map[string]interface{}{"step": step.Name()}}
-			r.results[step.Name()] = StepResult{Findings: []Finding{f}}
+			r.setResult(step.Name(), StepResult{Findings: []Finding{f}, Status: StepBlocked})
 			r.Logger.Printf("BLOCK: step %s mutates but Runner.AllowMutations is false", step.Name())
+			r.emit(Event{Type: EventFinding, Step: step.Name(), Finding: &f})
+			r.emit(Event{Type: EventStepBlocked, Step: step.Name()})
 			summary.Block++
+			if r.ContinueOnBlock {
+				blockedAny = true
+				continue
+			}
 			return summary, nil
 		}
 
 		r.Logger.Printf("running step: %s", step.Name())
+		started := time.Now()
+		r.setResult(step.Name(), StepResult{Status: StepRunning, StartedAt: started})
+		r.emit(Event{Type: EventStepStarted, Step: step.Name()})
 		res, err := step.Run(ctx, r.State)
+		res.StartedAt, res.Duration = started, time.Since(started)
 		if err != nil {
 			// Treat an execution error as a BLOCK: surface as finding and stop.
 			f := Finding{Severity: SeverityBlock, Message: fmt.Sprintf("step error: %v", err), Meta: map[string]interface{}{"step": step.Name()}}
+			if kind := failure.KindOf(err); kind != "" {
+				f.Meta["cause"] = string(kind)
+			}
 			res.Findings = append(res.Findings, f)
 		}
 
 		// Aggregate findings
 		blocked := false
-		for _, f := range res.Findings {
+		for i := range res.Findings {
+			f := res.Findings[i]
+			r.emit(Event{Type: EventFinding, Step: step.Name(), Finding: &f})
 			switch f.Severity {
 			case SeverityInfo:
 				summary.Info++
@@ -198,22 +278,39 @@ func (r *Runner) Run(ctx context.Context) (Summary, error) {
 			}
 		}
 
-		r.results[step.Name()] = res
-
 		if blocked {
+			res.Status = StepBlocked
+			r.setResult(step.Name(), res)
+			r.emit(Event{Type: EventStepBlocked, Step: step.Name()})
+			if r.ContinueOnBlock {
+				r.Logger.Printf("BLOCK encountered in step %s; continuing with read-only steps", step.Name())
+				blockedAny = true
+				continue
+			}
 			r.Logger.Printf("BLOCK encountered in step %s; halting plan execution", step.Name())
 			return summary, nil
 		}
 
 		// mark completed only if no BLOCK findings
 		r.State.MarkCompleted(step.Name())
+		res.Status = StepCompleted
+		r.setResult(step.Name(), res)
 		toLog := fmt.Sprintf("completed step: %s (INFO=%d WARN=%d BLOCK=%d)", step.Name(), countSeverity(res.Findings, SeverityInfo), countSeverity(res.Findings, SeverityWarn), countSeverity(res.Findings, SeverityBlock))
 		r.Logger.Println(toLog)
+		r.emit(Event{Type: EventStepCompleted, Step: step.Name()})
 	}
 
 	return summary, nil
 }
 
+func (r *Runner) emit(e Event) {
+	if r.OnEvent == nil {
+		return
+	}
+	e.Time = time.Now()
+	r.OnEvent(e)
+}
+
 func countSeverity(findings []Finding, sv Severity) int {
 	c := 0
 	for _, f := range findings {
@@ -224,8 +321,29 @@ func countSeverity(findings []Finding, sv Severity) int {
 	return c
 }
 
-// Results returns a copy of step results after a run.
+func (r *Runner) setResult(step string, res StepResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.results == nil {
+		r.results = make(map[string]StepResult)
+	}
+	r.results[step] = res
+}
+
+// Reset clears the results of the previous run. Run resets on its own; call
+// Reset to drop results without starting another run.
+func (r *Runner) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.results = make(map[string]StepResult)
+}
+
+// Results returns a copy of the step results of the latest run, including
+// the step still running when called during a run. Steps the run did not
+// reach are absent.
 func (r *Runner) Results() map[string]StepResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	out := make(map[string]StepResult, len(r.results))
 	for k, v := range r.results {
 		out[k] = v
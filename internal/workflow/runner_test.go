@@ -6,6 +6,7 @@ import (
 	"log"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestRun_WarnDoesNotStop(t *testing.T) {
@@ -64,6 +65,41 @@ func TestRun_MutatingStepBlocked(t *testing.T) {
 	}
 }
 
+func TestRun_ContinueOnBlockRunsRemainingReadOnlySteps(t *testing.T) {
+	state := NewMemoryState()
+	ran := []string{}
+	steps := []Step{
+		NewReadOnlyStep("preflight", func(ctx context.Context, st State) (StepResult, error) {
+			ran = append(ran, "preflight")
+			return StepResult{Findings: []Finding{{Severity: SeverityBlock, Message: "schema drift"}}}, nil
+		}),
+		NewMutatingStep("upgrade_replica", func(ctx context.Context, st State) (StepResult, error) {
+			ran = append(ran, "upgrade_replica")
+			return StepResult{}, nil
+		}),
+		NewReadOnlyStep("cdc_check", func(ctx context.Context, st State) (StepResult, error) {
+			ran = append(ran, "cdc_check")
+			return StepResult{Findings: []Finding{{Severity: SeverityWarn, Message: "lagging"}}}, nil
+		}),
+	}
+
+	runner := NewRunner(steps, state, true, log.New(io.Discard, "", 0))
+	runner.ContinueOnBlock = true
+	summary, err := runner.Run(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected runner error: %v", err)
+	}
+	if strings.Join(ran, ",") != "preflight,cdc_check" {
+		t.Fatalf("expected read-only steps to run and the mutating step to be skipped, ran %v", ran)
+	}
+	if summary.Block != 1 || summary.Warn != 1 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if state.IsCompleted("preflight") || state.IsCompleted("upgrade_replica") || !state.IsCompleted("cdc_check") {
+		t.Fatalf("expected only the unblocked step to be marked completed")
+	}
+}
+
 type badStep struct{}
 
 func (b *badStep) Name() string                                          { return "bad" }
@@ -104,3 +140,118 @@ func TestRun_SkipsCompletedSteps(t *testing.T) {
 		t.Fatalf("expected both steps to be completed (skipped and run)")
 	}
 }
+
+func TestRun_EmitsProgressEvents(t *testing.T) {
+	state := NewMemoryState()
+	state.MarkCompleted("preflight")
+	steps := []Step{
+		NewReadOnlyStep("preflight", func(ctx context.Context, st State) (StepResult, error) {
+			return StepResult{}, nil
+		}),
+		NewReadOnlyStep("validate", func(ctx context.Context, st State) (StepResult, error) {
+			return StepResult{Findings: []Finding{{Severity: SeverityBlock, Message: "stop"}}}, nil
+		}),
+	}
+
+	var got []EventType
+	runner := NewRunner(steps, state, false, log.New(io.Discard, "", 0))
+	runner.OnEvent = func(e Event) { got = append(got, e.Type) }
+	if _, err := runner.Run(context.Background()); err != nil {
+		t.Fatalf("unexpected runner error: %v", err)
+	}
+
+	want := []EventType{EventStepSkipped, EventStepStarted, EventFinding, EventStepBlocked}
+	if len(got) != len(want) {
+		t.Fatalf("expected events %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected events %v, got %v", want, got)
+		}
+	}
+}
+
+func TestRun_ResultsReportStatusAndTiming(t *testing.T) {
+	state := NewMemoryState()
+	state.MarkCompleted("preflight")
+	steps := []Step{
+		NewReadOnlyStep("preflight", func(ctx context.Context, st State) (StepResult, error) {
+			return StepResult{}, nil
+		}),
+		NewReadOnlyStep("validate", func(ctx context.Context, st State) (StepResult, error) {
+			return StepResult{Findings: []Finding{{Severity: SeverityInfo, Message: "ok"}}}, nil
+		}),
+		NewReadOnlyStep("cdc_check", func(ctx context.Context, st State) (StepResult, error) {
+			return StepResult{Findings: []Finding{{Severity: SeverityBlock, Message: "connector failed"}}}, nil
+		}),
+		NewReadOnlyStep("post_validation", func(ctx context.Context, st State) (StepResult, error) {
+			return StepResult{}, nil
+		}),
+	}
+
+	runner := NewRunner(steps, state, false, log.New(io.Discard, "", 0))
+	if _, err := runner.Run(context.Background()); err != nil {
+		t.Fatalf("unexpected runner error: %v", err)
+	}
+	results := runner.Results()
+	want := map[string]StepStatus{"preflight": StepSkipped, "validate": StepCompleted, "cdc_check": StepBlocked}
+	if len(results) != len(want) {
+		t.Fatalf("expected results for %v, got %+v", want, results)
+	}
+	for name, status := range want {
+		if results[name].Status != status {
+			t.Fatalf("expected %s to be %s, got %+v", name, status, results[name])
+		}
+	}
+	if !results["preflight"].StartedAt.IsZero() {
+		t.Fatalf("expected a skipped step to have no timing, got %+v", results["preflight"])
+	}
+	if results["validate"].StartedAt.IsZero() || results["validate"].Duration < 0 {
+		t.Fatalf("expected timing for a step that ran, got %+v", results["validate"])
+	}
+}
+
+func TestRun_ResultsReadableDuringRunAndResetBetweenRuns(t *testing.T) {
+	release := make(chan struct{})
+	steps := []Step{
+		NewReadOnlyStep("preflight", func(ctx context.Context, st State) (StepResult, error) {
+			<-release
+			return StepResult{}, nil
+		}),
+	}
+	runner := NewRunner(steps, nil, false, log.New(io.Discard, "", 0))
+	done := make(chan error)
+	go func() {
+		_, err := runner.Run(context.Background())
+		done <- err
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for runner.Results()["preflight"].Status != StepRunning {
+		if time.Now().After(deadline) {
+			close(release)
+			t.Fatalf("expected preflight to be reported running, got %+v", runner.Results())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected runner error: %v", err)
+	}
+	if got := runner.Results()["preflight"].Status; got != StepCompleted {
+		t.Fatalf("expected preflight completed, got %s", got)
+	}
+
+	// The re-run finds the checkpoint and reports only what it did.
+	if _, err := runner.Run(context.Background()); err != nil {
+		t.Fatalf("unexpected runner error: %v", err)
+	}
+	if got := runner.Results()["preflight"]; got.Status != StepSkipped || !got.StartedAt.IsZero() {
+		t.Fatalf("expected the re-run to report preflight skipped, got %+v", got)
+	}
+
+	runner.Reset()
+	if len(runner.Results()) != 0 {
+		t.Fatalf("expected Reset to clear results, got %+v", runner.Results())
+	}
+}
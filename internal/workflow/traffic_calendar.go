@@ -0,0 +1,223 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"migratorx/internal/checks"
+)
+
+// Finding codes emitted by the traffic calendar gate.
+const (
+	CodeTrafficCalendarClear       = "TRAFFIC_CALENDAR_CLEAR"
+	CodeTrafficPeakAhead           = "TRAFFIC_PEAK_AHEAD"
+	CodeTrafficCalendarUnavailable = "TRAFFIC_CALENDAR_UNAVAILABLE"
+)
+
+// DefaultTrafficLeadTime is how long before a known peak mutating steps stop
+// starting when the plan sets no lead_time.
+const DefaultTrafficLeadTime = time.Hour
+
+// TrafficCalendarConfig points at a calendar of known traffic peaks. Source
+// is an http(s) URL or a file path. Mutating steps that would start within
+// LeadTime (default DefaultTrafficLeadTime) of a peak, or during one, are
+// deferred, or blocked when OnPeak is "block".
+type TrafficCalendarConfig struct {
+	Source   string        `yaml:"source"`
+	LeadTime time.Duration `yaml:"lead_time"`
+	OnPeak   string        `yaml:"on_peak"`
+}
+
+// Blocks reports whether a peak blocks mutating steps instead of deferring
+// them.
+func (c TrafficCalendarConfig) Blocks() bool { return c.OnPeak == "block" }
+
+func (c TrafficCalendarConfig) validate() []string {
+	problems := []string{}
+	if c.LeadTime < 0 {
+		problems = append(problems, "traffic_calendar.lead_time must not be negative")
+	}
+	switch c.OnPeak {
+	case "", "defer", "block":
+	default:
+		problems = append(problems, fmt.Sprintf("traffic_calendar.on_peak=%q is not defer or block", c.OnPeak))
+	}
+	if c.Source == "" && (c.LeadTime != 0 || c.OnPeak != "") {
+		problems = append(problems, "traffic_calendar.source is required")
+	}
+	return problems
+}
+
+// TrafficCalendar lists known traffic peaks: one-off Peaks such as a sale,
+// and Weekly windows in the read_pool.peak_hours format, evaluated in
+// Timezone (default UTC).
+type TrafficCalendar struct {
+	Peaks    []TrafficPeak `json:"peaks"`
+	Weekly   []PeakWindow  `json:"weekly"`
+	Timezone string        `json:"timezone"`
+}
+
+// TrafficPeak is one known peak from Start to End.
+type TrafficPeak struct {
+	Name  string    `json:"name"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// next returns the first peak under way at now or starting within lead.
+func (c TrafficCalendar) next(now time.Time, lead time.Duration) (TrafficPeak, bool) {
+	var found TrafficPeak
+	ok := false
+	for _, p := range c.Peaks {
+		if !p.End.After(now) || p.Start.After(now.Add(lead)) {
+			continue
+		}
+		if !ok || p.Start.Before(found.Start) {
+			found, ok = p, true
+		}
+	}
+	if len(c.Weekly) == 0 {
+		return found, ok
+	}
+	// Weekly windows are minute-aligned, so stepping by minutes finds the
+	// first one that overlaps [now, now+lead].
+	weekly := ReadPoolConfig{PeakHours: c.Weekly, Timezone: c.Timezone}
+	for at := now.Truncate(time.Minute); !at.After(now.Add(lead)); at = at.Add(time.Minute) {
+		if ok && !at.Before(found.Start) {
+			break
+		}
+		if !weekly.InPeak(at) {
+			continue
+		}
+		end := at
+		for end.Sub(at) < 7*24*time.Hour && weekly.InPeak(end) {
+			end = end.Add(time.Minute)
+		}
+		return TrafficPeak{Name: "weekly peak", Start: at, End: end}, true
+	}
+	return found, ok
+}
+
+// CalendarSource reads a TrafficCalendar from Location, an http(s) URL
+// fetched with Client or a file path.
+type CalendarSource struct {
+	Location string
+	Client   *http.Client
+}
+
+// Calendar fetches and decodes the calendar.
+func (s *CalendarSource) Calendar(ctx context.Context) (TrafficCalendar, error) {
+	var data []byte
+	if strings.HasPrefix(s.Location, "http://") || strings.HasPrefix(s.Location, "https://") {
+		client := s.Client
+		if client == nil {
+			client = http.DefaultClient
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.Location, nil)
+		if err != nil {
+			return TrafficCalendar{}, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return TrafficCalendar{}, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return TrafficCalendar{}, fmt.Errorf("GET %s: %s", s.Location, resp.Status)
+		}
+		if data, err = io.ReadAll(resp.Body); err != nil {
+			return TrafficCalendar{}, err
+		}
+	} else {
+		var err error
+		if data, err = os.ReadFile(s.Location); err != nil {
+			return TrafficCalendar{}, err
+		}
+	}
+	var calendar TrafficCalendar
+	if err := json.Unmarshal(data, &calendar); err != nil {
+		return TrafficCalendar{}, fmt.Errorf("decode traffic calendar %s: %w", s.Location, err)
+	}
+	if problems := validatePeakWindows("weekly", "timezone", calendar.Weekly, calendar.Timezone); len(problems) > 0 {
+		return TrafficCalendar{}, fmt.Errorf("traffic calendar %s: %s", s.Location, strings.Join(problems, "; "))
+	}
+	return calendar, nil
+}
+
+// TrafficCalendarGate holds a mutating step that would start within LeadTime
+// of a known traffic peak, or during one: a WARN defers it, or with Block a
+// BLOCK stops it. A calendar that cannot be read yields a WARN and does not
+// hold the step.
+type TrafficCalendarGate struct {
+	Source   *CalendarSource
+	LeadTime time.Duration
+	Block    bool
+	Now      func() time.Time
+}
+
+func (g *TrafficCalendarGate) Name() string   { return "traffic_calendar" }
+func (g *TrafficCalendarGate) ReadOnly() bool { return true }
+
+func (g *TrafficCalendarGate) Parameters() map[string]interface{} {
+	return map[string]interface{}{"lead_time": g.leadTime().String(), "block": g.Block}
+}
+
+func (g *TrafficCalendarGate) leadTime() time.Duration {
+	if g.LeadTime > 0 {
+		return g.LeadTime
+	}
+	return DefaultTrafficLeadTime
+}
+
+func (g *TrafficCalendarGate) Run(ctx context.Context, input checks.Input) ([]checks.Finding, error) {
+	if g.Source == nil {
+		return nil, fmt.Errorf("traffic calendar source is required")
+	}
+	meta := map[string]interface{}{"calendar": g.Source.Location, "lead_time": g.leadTime().String()}
+	calendar, err := g.Source.Calendar(ctx)
+	if err != nil {
+		return []checks.Finding{{
+			Severity: checks.SeverityWarn,
+			Code:     CodeTrafficCalendarUnavailable,
+			Message:  fmt.Sprintf("unable to read the traffic calendar: %v", err),
+			Meta:     meta,
+		}}, nil
+	}
+	now := time.Now
+	if g.Now != nil {
+		now = g.Now
+	}
+	at := now()
+	peak, ok := calendar.next(at, g.leadTime())
+	if !ok {
+		return []checks.Finding{{
+			Severity: checks.SeverityInfo,
+			Code:     CodeTrafficCalendarClear,
+			Message:  fmt.Sprintf("no traffic peak is under way or due within %s", g.leadTime()),
+			Meta:     meta,
+		}}, nil
+	}
+	meta["peak"] = peak.Name
+	meta["peak_start"] = peak.Start.UTC().Format(time.RFC3339)
+	meta["resume_after"] = peak.End.UTC().Format(time.RFC3339)
+	when := fmt.Sprintf("starts at %s", meta["peak_start"])
+	if !peak.Start.After(at) {
+		when = "is under way"
+	}
+	severity, verb := checks.SeverityWarn, "deferring"
+	if g.Block {
+		severity, verb = checks.SeverityBlock, "blocking"
+	}
+	return []checks.Finding{{
+		Severity: severity,
+		Code:     CodeTrafficPeakAhead,
+		Message:  fmt.Sprintf("%s: traffic peak %q %s (lead time %s); retry after %s", verb, peak.Name, when, g.leadTime(), meta["resume_after"]),
+		Meta:     meta,
+	}}, nil
+}
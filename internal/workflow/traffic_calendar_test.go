@@ -0,0 +1,73 @@
+package workflow
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"migratorx/internal/checks"
+)
+
+func TestTrafficCalendarGate_DefersBeforeKnownPeak(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calendar.json")
+	content := `{"peaks": [{"name": "black-friday", "start": "2026-11-27T05:00:00Z", "end": "2026-11-28T05:00:00Z"}]}`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	now := time.Date(2026, 11, 27, 4, 30, 0, 0, time.UTC)
+	gate := &TrafficCalendarGate{Source: &CalendarSource{Location: path}, Now: func() time.Time { return now }}
+
+	findings, err := gate.Run(context.Background(), checks.Input{})
+	if err != nil || len(findings) != 1 || findings[0].Code != CodeTrafficPeakAhead || findings[0].Severity != checks.SeverityWarn {
+		t.Fatalf("expected the step to be deferred, got %+v (%v)", findings, err)
+	}
+	if findings[0].Meta["resume_after"] != "2026-11-28T05:00:00Z" {
+		t.Fatalf("expected resume_after at the end of the peak, got %v", findings[0].Meta)
+	}
+
+	gate.Block = true
+	if findings, _ := gate.Run(context.Background(), checks.Input{}); findings[0].Severity != checks.SeverityBlock {
+		t.Fatalf("expected on_peak block to block, got %+v", findings)
+	}
+
+	now = now.Add(-2 * time.Hour)
+	if findings, _ := gate.Run(context.Background(), checks.Input{}); findings[0].Code != CodeTrafficCalendarClear {
+		t.Fatalf("expected a peak beyond the lead time to be clear, got %+v", findings)
+	}
+}
+
+func TestTrafficCalendarGate_WeeklyWindowsOverHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"timezone": "UTC", "weekly": [{"days": ["mon"], "start": "09:00", "end": "17:00"}]}`))
+	}))
+	defer server.Close()
+
+	now := time.Date(2026, 10, 19, 8, 15, 0, 0, time.UTC) // Monday
+	gate := &TrafficCalendarGate{Source: &CalendarSource{Location: server.URL}, Now: func() time.Time { return now }}
+	findings, err := gate.Run(context.Background(), checks.Input{})
+	if err != nil || findings[0].Code != CodeTrafficPeakAhead || findings[0].Meta["peak_start"] != "2026-10-19T09:00:00Z" || findings[0].Meta["resume_after"] != "2026-10-19T17:00:00Z" {
+		t.Fatalf("expected the Monday window to be found, got %+v (%v)", findings, err)
+	}
+
+	now = now.Add(24 * time.Hour)
+	if findings, _ := gate.Run(context.Background(), checks.Input{}); findings[0].Code != CodeTrafficCalendarClear {
+		t.Fatalf("expected Tuesday to be clear, got %+v", findings)
+	}
+}
+
+func TestTrafficCalendarGate_UnreadableCalendarWarns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calendar.json")
+	if err := os.WriteFile(path, []byte(`{"weekly": [{"start": "9am", "end": "17:00"}]}`), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	gate := &TrafficCalendarGate{Source: &CalendarSource{Location: path}, Block: true}
+	findings, err := gate.Run(context.Background(), checks.Input{})
+	if err != nil || findings[0].Code != CodeTrafficCalendarUnavailable || findings[0].Severity != checks.SeverityWarn || !strings.Contains(findings[0].Message, "weekly[0].start") {
+		t.Fatalf("expected an invalid calendar to warn, got %+v (%v)", findings, err)
+	}
+}
@@ -0,0 +1,146 @@
+package workflow
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"migratorx/internal/checks"
+	"migratorx/internal/state"
+)
+
+// Finding codes emitted by trend analysis.
+const (
+	CodeTrendInsufficientData = "TREND_INSUFFICIENT_DATA"
+	CodeTrendShrinking        = "TREND_SHRINKING"
+	CodeTrendSteady           = "TREND_STEADY"
+	CodeTrendGrowing          = "TREND_GROWING"
+	CodeTrendCheck            = "TREND_CHECK"
+	CodeTrendBlockRegression  = "TREND_BLOCK_REGRESSION"
+)
+
+// Trend directions reported in finding meta.
+const (
+	TrendShrinking = "shrinking"
+	TrendSteady    = "steady"
+	TrendGrowing   = "growing"
+)
+
+// NewTrendPoint condenses preflight results into a trend point.
+func NewTrendPoint(runID string, planHash string, recordedAt string, results []checks.Result) state.TrendPoint {
+	point := state.TrendPoint{RunID: runID, RecordedAt: recordedAt, PlanHash: planHash, Checks: map[string]state.CheckCounts{}}
+	codes := map[string]bool{}
+	for _, r := range results {
+		counts := point.Checks[r.CheckName]
+		for _, f := range r.Findings {
+			switch f.Severity {
+			case checks.SeverityInfo:
+				counts.Info++
+			case checks.SeverityWarn:
+				counts.Warn++
+			case checks.SeverityBlock:
+				counts.Block++
+				if f.Code != "" {
+					codes[f.Code] = true
+				}
+			}
+		}
+		point.Checks[r.CheckName] = counts
+	}
+	point.BlockCodes = sortedKeys(codes)
+	return point
+}
+
+// trendDirection compares two counts, BLOCKs first and then WARNs.
+func trendDirection(from state.CheckCounts, to state.CheckCounts) string {
+	switch {
+	case to.Block > from.Block, to.Block == from.Block && to.Warn > from.Warn:
+		return TrendGrowing
+	case to.Block < from.Block, to.Warn < from.Warn:
+		return TrendShrinking
+	default:
+		return TrendSteady
+	}
+}
+
+// AnalyzeTrend reports whether risk is shrinking or growing from the first to
+// the latest point, per check and overall. BLOCK codes in the latest run that
+// no earlier run produced are regressions and reported as BLOCK findings.
+func AnalyzeTrend(points []state.TrendPoint) []checks.Finding {
+	if len(points) < 2 {
+		return []checks.Finding{{
+			Severity: checks.SeverityInfo,
+			Code:     CodeTrendInsufficientData,
+			Message:  fmt.Sprintf("%d recorded run(s); at least 2 are needed for a trend", len(points)),
+			Meta:     map[string]interface{}{"runs": len(points)},
+		}}
+	}
+	first, last := points[0], points[len(points)-1]
+	findings := []checks.Finding{}
+
+	seen := map[string]bool{}
+	for _, p := range points[:len(points)-1] {
+		for _, code := range p.BlockCodes {
+			seen[code] = true
+		}
+	}
+	regressions := []string{}
+	for _, code := range last.BlockCodes {
+		if !seen[code] {
+			regressions = append(regressions, code)
+		}
+	}
+	if len(regressions) > 0 {
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityBlock,
+			Code:     CodeTrendBlockRegression,
+			Message:  fmt.Sprintf("regression: run %s produced BLOCK codes no earlier run had: %s", last.RunID, strings.Join(regressions, ", ")),
+			Meta:     map[string]interface{}{"run_id": last.RunID, "codes": regressions},
+		})
+	}
+
+	names := map[string]bool{}
+	for name := range first.Checks {
+		names[name] = true
+	}
+	for name := range last.Checks {
+		names[name] = true
+	}
+	for _, name := range sortedKeys(names) {
+		from, to := first.Checks[name], last.Checks[name]
+		findings = append(findings, checks.Finding{
+			Severity: checks.SeverityInfo,
+			Code:     CodeTrendCheck,
+			Message:  fmt.Sprintf("%s: %d BLOCK / %d WARN -> %d BLOCK / %d WARN (%s)", name, from.Block, from.Warn, to.Block, to.Warn, trendDirection(from, to)),
+			Meta:     map[string]interface{}{"check": name, "first": from, "latest": to, "direction": trendDirection(from, to)},
+		})
+	}
+
+	from, to := first.Totals(), last.Totals()
+	direction := trendDirection(from, to)
+	overall := checks.Finding{
+		Severity: checks.SeverityInfo,
+		Code:     CodeTrendSteady,
+		Message:  fmt.Sprintf("risk is steady over %d runs: %d BLOCK / %d WARN", len(points), to.Block, to.Warn),
+		Meta:     map[string]interface{}{"runs": len(points), "first_run": first.RunID, "latest_run": last.RunID, "first": from, "latest": to, "direction": direction},
+	}
+	switch direction {
+	case TrendShrinking:
+		overall.Code = CodeTrendShrinking
+		overall.Message = fmt.Sprintf("risk is shrinking over %d runs: %d BLOCK / %d WARN -> %d BLOCK / %d WARN", len(points), from.Block, from.Warn, to.Block, to.Warn)
+	case TrendGrowing:
+		overall.Severity = checks.SeverityWarn
+		overall.Code = CodeTrendGrowing
+		overall.Message = fmt.Sprintf("risk is growing over %d runs: %d BLOCK / %d WARN -> %d BLOCK / %d WARN", len(points), from.Block, from.Warn, to.Block, to.Warn)
+	}
+	return append(findings, overall)
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
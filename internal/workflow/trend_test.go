@@ -0,0 +1,58 @@
+package workflow
+
+import (
+	"testing"
+
+	"migratorx/internal/checks"
+	"migratorx/internal/state"
+)
+
+func TestNewTrendPoint_CountsPerCheck(t *testing.T) {
+	point := NewTrendPoint("r1", "hash", "2026-10-17T10:00:00Z", []checks.Result{
+		{CheckName: "schema_parity", Findings: []checks.Finding{
+			{Severity: checks.SeverityBlock, Code: "SCHEMA_COLUMN_MISSING"},
+			{Severity: checks.SeverityWarn, Code: "SCHEMA_CHARSET_MISMATCH"},
+		}},
+		{CheckName: "debezium_health", Findings: []checks.Finding{{Severity: checks.SeverityInfo}}},
+	})
+	if point.Checks["schema_parity"] != (state.CheckCounts{Warn: 1, Block: 1}) || point.Checks["debezium_health"] != (state.CheckCounts{Info: 1}) {
+		t.Fatalf("unexpected counts %+v", point.Checks)
+	}
+	if len(point.BlockCodes) != 1 || point.BlockCodes[0] != "SCHEMA_COLUMN_MISSING" {
+		t.Fatalf("unexpected block codes %v", point.BlockCodes)
+	}
+}
+
+func TestAnalyzeTrend(t *testing.T) {
+	if findings := AnalyzeTrend([]state.TrendPoint{{RunID: "r1"}}); len(findings) != 1 || findings[0].Code != CodeTrendInsufficientData {
+		t.Fatalf("expected insufficient data, got %+v", findings)
+	}
+
+	shrinking := AnalyzeTrend([]state.TrendPoint{
+		{RunID: "r1", Checks: map[string]state.CheckCounts{"schema_parity": {Block: 2}}, BlockCodes: []string{"SCHEMA_COLUMN_MISSING"}},
+		{RunID: "r2", Checks: map[string]state.CheckCounts{"schema_parity": {Block: 1}}, BlockCodes: []string{"SCHEMA_COLUMN_MISSING"}},
+	})
+	if last := shrinking[len(shrinking)-1]; last.Code != CodeTrendShrinking || last.Severity != checks.SeverityInfo {
+		t.Fatalf("expected shrinking trend, got %+v", shrinking)
+	}
+	for _, f := range shrinking {
+		if f.Code == CodeTrendBlockRegression {
+			t.Fatalf("known BLOCK code must not be a regression: %+v", f)
+		}
+	}
+
+	growing := AnalyzeTrend([]state.TrendPoint{
+		{RunID: "r1", Checks: map[string]state.CheckCounts{"schema_parity": {Warn: 1}}},
+		{RunID: "r2", Checks: map[string]state.CheckCounts{"schema_parity": {Warn: 1}}, BlockCodes: []string{}},
+		{RunID: "r3", Checks: map[string]state.CheckCounts{"schema_parity": {Warn: 1}, "replica_lag": {Block: 1}}, BlockCodes: []string{"REPLICA_LAG_HIGH"}},
+	})
+	if growing[0].Code != CodeTrendBlockRegression || growing[0].Severity != checks.SeverityBlock {
+		t.Fatalf("expected the regression first, got %+v", growing)
+	}
+	if last := growing[len(growing)-1]; last.Code != CodeTrendGrowing || last.Severity != checks.SeverityWarn {
+		t.Fatalf("expected growing trend, got %+v", last)
+	}
+	if len(growing) != 4 {
+		t.Fatalf("expected a finding per check, got %+v", growing)
+	}
+}